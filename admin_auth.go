@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"chat-bot/internal/adminauth"
+	"chat-bot/internal/config"
+	"chat-bot/internal/services"
+)
+
+var adminAuth *adminauth.Authenticator
+
+// setupAdminAuth configura a verificação dos tokens do Firebase e, fora do
+// Cloud Run, os tokens de teste assinados com ADMIN_DEV_SECRET
+func setupAdminAuth(cfg *config.Config, firestoreService *services.FirestoreService) {
+	adminAuth = &adminauth.Authenticator{Claim: cfg.AdminClaim}
+	switch {
+	case firestoreService != nil:
+		adminAuth.Firebase = firestoreService.GetAuth()
+	case cfg.FirebaseProjectID != "":
+		// Com SQLite ou arquivo local, o Firebase continua verificando os administradores
+		authClient, err := services.InitializeAuth(cfg)
+		if err != nil {
+			log.Printf("⚠️  Firebase Auth indisponível para a área administrativa: %v", err)
+		} else {
+			adminAuth.Firebase = authClient
+		}
+	}
+	if cfg.AdminDevSecret != "" {
+		if err := adminAuth.EnableDevTokens(cfg.AdminDevSecret); err != nil {
+			log.Printf("⚠️  ADMIN_DEV_SECRET ignorado: %v", err)
+		} else {
+			log.Println("⚠️  Área administrativa aceitando tokens de teste (ADMIN_DEV_SECRET)")
+		}
+	}
+	if !adminAuth.Enabled() {
+		log.Println("⚠️  Autenticação administrativa não configurada: rotas administrativas indisponíveis (configure o Firebase ou ADMIN_DEV_SECRET)")
+	}
+}
+
+// requireAdmin protege uma rota administrativa: exige "Authorization: Bearer <token>"
+// de um usuário com a claim de administrador
+func requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !adminAuth.Enabled() {
+			writeJSONError(w, http.StatusServiceUnavailable, "autenticação administrativa não configurada")
+			return
+		}
+
+		identity, err := adminAuth.Verify(r.Context(), adminauth.BearerToken(r))
+		switch {
+		case errors.Is(err, adminauth.ErrForbidden):
+			log.Printf("⚠️  acesso administrativo negado para %s em %s %s", identity.UID, r.Method, r.URL.Path)
+			writeJSONError(w, http.StatusForbidden, err.Error())
+			return
+		case err != nil:
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			writeJSONError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		next(w, r.WithContext(adminauth.WithIdentity(r.Context(), identity)))
+	}
+}
+
+// handleAdminMe devolve o administrador autenticado; o painel usa para validar o login
+func handleAdminMe(w http.ResponseWriter, r *http.Request) {
+	identity, _ := adminauth.FromContext(r.Context())
+	json.NewEncoder(w).Encode(identity)
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"chat-bot/internal/textnorm"
+)
+
+const (
+	maxToolRounds   = 3
+	toolCallTimeout = 45 * time.Second
+)
+
+// chatTool é uma ferramenta de dados oficiais que o Gemini pode chamar durante o chat
+type chatTool struct {
+	Declaration GeminiFunctionDeclaration
+	// Keywords (sem acentos) indicam quando a ferramenta é relevante para a pergunta
+	Keywords []string
+	Handler  func(ctx context.Context, args map[string]interface{}) (interface{}, error)
+}
+
+// chatTools lista as ferramentas disponíveis para o chat
+var chatTools = []*chatTool{
+	compareTool,
+	expensesTool,
+	electionCandidatesTool,
+	electionResultsTool,
+	legislationTool,
+	douTool,
+}
+
+// selectChatTools escolhe as ferramentas relevantes para a pergunta.
+// Com ferramentas, a busca na web é desativada: o Gemini não combina as duas.
+func selectChatTools(message string) []*chatTool {
+	folded := " " + strings.Join(textnorm.Tokens(message), " ") + " "
+
+	var selected []*chatTool
+	for _, tool := range chatTools {
+		for _, keyword := range tool.Keywords {
+			if strings.Contains(folded, keyword) {
+				selected = append(selected, tool)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// callGeminiWithTools conversa com o Gemini executando as chamadas de ferramenta
+// até que o modelo produza uma resposta em texto.
+func callGeminiWithTools(ctx context.Context, contents []GeminiContent, tools []*chatTool) (*GeminiResponse, error) {
+	declarations := make([]GeminiFunctionDeclaration, 0, len(tools))
+	byName := make(map[string]*chatTool, len(tools))
+	for _, tool := range tools {
+		declarations = append(declarations, tool.Declaration)
+		byName[tool.Declaration.Name] = tool
+	}
+
+	for round := 0; ; round++ {
+		geminiReq := GeminiRequest{
+			Contents: contents,
+			GenerationConfig: &GeminiGenerationConfig{
+				Temperature: 0.4,
+				TopK:        40,
+				TopP:        0.95,
+			},
+		}
+		if round < maxToolRounds {
+			geminiReq.Tools = []GeminiTool{{FunctionDeclarations: declarations}}
+		}
+
+		geminiResp, err := sendGeminiRequest(geminiReq)
+		if err != nil {
+			return nil, err
+		}
+		if len(geminiResp.Candidates) == 0 {
+			return geminiResp, nil
+		}
+
+		modelContent := geminiResp.Candidates[0].Content
+		var responses []GeminiPart
+		for _, part := range modelContent.Parts {
+			if part.FunctionCall == nil {
+				continue
+			}
+			responses = append(responses, GeminiPart{
+				FunctionResponse: runChatTool(ctx, byName[part.FunctionCall.Name], part.FunctionCall),
+			})
+		}
+
+		if len(responses) == 0 {
+			return geminiResp, nil
+		}
+
+		modelContent.Role = "model"
+		contents = append(contents, modelContent, GeminiContent{Role: "user", Parts: responses})
+	}
+}
+
+func runChatTool(ctx context.Context, tool *chatTool, call *GeminiFunctionCall) *GeminiFunctionResponse {
+	response := &GeminiFunctionResponse{Name: call.Name}
+	if tool == nil {
+		response.Response = map[string]interface{}{"error": fmt.Sprintf("ferramenta desconhecida: %s", call.Name)}
+		return response
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, toolCallTimeout)
+	defer cancel()
+
+	log.Printf("[FERRAMENTA] %s %v", call.Name, call.Args)
+	result, err := tool.Handler(ctx, call.Args)
+	if err != nil {
+		log.Printf("[FERRAMENTA] %s falhou: %v", call.Name, err)
+		response.Response = map[string]interface{}{"error": err.Error()}
+		return response
+	}
+
+	// O Gemini espera um objeto JSON; convertemos o resultado tipado para mapa
+	payload, err := json.Marshal(result)
+	if err != nil {
+		response.Response = map[string]interface{}{"error": err.Error()}
+		return response
+	}
+	var asMap map[string]interface{}
+	if err := json.Unmarshal(payload, &asMap); err != nil {
+		asMap = map[string]interface{}{"resultado": json.RawMessage(payload)}
+	}
+	response.Response = asMap
+	return response
+}
+
+// stringArgs lê um argumento que pode vir como string ou lista de strings
+func stringArgs(args map[string]interface{}, key string) []string {
+	switch v := args[key].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+				values = append(values, strings.TrimSpace(s))
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// stringArg lê um argumento de texto opcional
+func stringArg(args map[string]interface{}, key string) string {
+	if v, ok := args[key].(string); ok {
+		return strings.TrimSpace(v)
+	}
+	return ""
+}
+
+// intArg lê um argumento numérico opcional (o Gemini envia números como float64)
+func intArg(args map[string]interface{}, key string) int {
+	switch v := args[key].(type) {
+	case float64:
+		return int(v)
+	case string:
+		n, _ := strconv.Atoi(strings.TrimSpace(v))
+		return n
+	}
+	return 0
+}
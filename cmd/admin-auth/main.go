@@ -0,0 +1,102 @@
+// Comando admin-auth administra o acesso à área administrativa: concede ou
+// revoga a custom claim de administrador de um usuário do Firebase e gera
+// tokens de teste para o desenvolvimento local.
+//
+// Uso:
+//
+//	go run ./cmd/admin-auth grant -email gestor@exemplo.com
+//	go run ./cmd/admin-auth revoke -email gestor@exemplo.com
+//	go run ./cmd/admin-auth token -email dev@local -ttl 12h
+//
+// grant e revoke usam as credenciais do Firebase do env.yaml; o usuário precisa
+// entrar de novo para que o novo token traga a claim. token usa ADMIN_DEV_SECRET.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"chat-bot/internal/adminauth"
+	"chat-bot/internal/config"
+	"chat-bot/internal/services"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "uso: admin-auth grant|revoke|token -email <email> [-ttl 12h]")
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	command := os.Args[1]
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	email := flags.String("email", "", "e-mail do usuário")
+	ttl := flags.Duration("ttl", 12*time.Hour, "validade do token de teste")
+	flags.Parse(os.Args[2:])
+	if *email == "" {
+		usage()
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("erro ao carregar configurações: %v", err)
+	}
+	claim := cfg.AdminClaim
+	if claim == "" {
+		claim = adminauth.DefaultClaim
+	}
+
+	switch command {
+	case "grant", "revoke":
+		setClaim(cfg, *email, claim, command == "grant")
+	case "token":
+		token, err := adminauth.SignDevToken([]byte(cfg.AdminDevSecret), adminauth.Identity{Email: *email, Admin: true}, *ttl)
+		if err != nil {
+			log.Fatalf("configure ADMIN_DEV_SECRET: %v", err)
+		}
+		fmt.Println(token)
+	default:
+		usage()
+	}
+}
+
+// setClaim altera a claim de administrador preservando as demais custom claims
+func setClaim(cfg *config.Config, email, claim string, admin bool) {
+	ctx := context.Background()
+	firestoreService, err := services.InitializeFirestore(cfg)
+	if err != nil {
+		log.Fatalf("não foi possível conectar ao Firebase: %v", err)
+	}
+	defer firestoreService.Close()
+	client := firestoreService.GetAuth()
+
+	user, err := client.GetUserByEmail(ctx, email)
+	if err != nil {
+		log.Fatalf("usuário %s não encontrado no Firebase Authentication: %v", email, err)
+	}
+	claims := map[string]interface{}{}
+	for key, value := range user.CustomClaims {
+		claims[key] = value
+	}
+	if admin {
+		claims[claim] = true
+	} else {
+		delete(claims, claim)
+	}
+	if err := client.SetCustomUserClaims(ctx, user.UID, claims); err != nil {
+		log.Fatalf("não foi possível atualizar as claims de %s: %v", email, err)
+	}
+	if !admin {
+		// Encerra as sessões abertas para que o acesso caia imediatamente
+		if err := client.RevokeRefreshTokens(ctx, user.UID); err != nil {
+			log.Printf("⚠️  não foi possível revogar as sessões de %s: %v", email, err)
+		}
+	}
+	log.Printf("✅ claim %q de %s (%s): %v", claim, email, user.UID, admin)
+}
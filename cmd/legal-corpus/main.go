@@ -0,0 +1,94 @@
+// Comando legal-corpus baixa do Planalto a Constituição e as leis usadas pela
+// busca textual (/api/legal/search) e as grava em JSON no diretório do corpus.
+//
+// Uso:
+//
+//	go run ./cmd/legal-corpus
+//	go run ./cmd/legal-corpus -normas "Lei 14.133/2021; LC 101/2000; CLT"
+//
+// Reinicie o servidor depois de atualizar o corpus.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"chat-bot/internal/legislacao"
+)
+
+// defaultNormas é o corpus inicial: a Constituição e leis frequentes nas perguntas
+var defaultNormas = []string{
+	"Constituição Federal",
+	"Lei 9.504/1997",
+	"Lei 14.133/2021",
+	"LC 101/2000",
+	"LC 135/2010",
+	"Lei 12.527/2011",
+	"Lei 13.709/2018",
+}
+
+func main() {
+	normas := flag.String("normas", strings.Join(defaultNormas, "; "), "normas separadas por ponto e vírgula")
+	dir := flag.String("dir", "data/legal", "diretório do corpus")
+	flag.Parse()
+
+	if err := os.MkdirAll(*dir, 0o755); err != nil {
+		log.Fatalf("não foi possível criar %s: %v", *dir, err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client := legislacao.NewClient()
+	failed := 0
+	for _, raw := range strings.Split(*normas, ";") {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		refs := legislacao.ParseReferences(raw)
+		if len(refs) == 0 {
+			log.Printf("⚠️  norma não reconhecida: %q", raw)
+			failed++
+			continue
+		}
+		ref := refs[0]
+		ref.Artigo = ""
+
+		law, err := client.Fetch(ctx, ref)
+		if err != nil {
+			if ctx.Err() != nil {
+				log.Fatalf("interrompido: %v", ctx.Err())
+			}
+			log.Printf("⚠️  %s: %v", ref.Label(), err)
+			failed++
+			continue
+		}
+		path := filepath.Join(*dir, law.Key+".json")
+		if err := writeJSON(path, law); err != nil {
+			log.Fatalf("não foi possível gravar %s: %v", path, err)
+		}
+		log.Printf("✅ %s: %d artigos em %s", law.Titulo, len(law.Artigos), path)
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func writeJSON(path string, value interface{}) error {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	tempPath := path + ".tmp"
+	if err := os.WriteFile(tempPath, payload, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, path)
+}
@@ -0,0 +1,64 @@
+// Comando tse-import carrega candidaturas, bens declarados e votação por zona
+// do Portal de Dados Abertos do TSE para o armazenamento local usado pela API.
+//
+// Uso:
+//
+//	go run ./cmd/tse-import -ano 2024 -uf SP,RJ
+//	go run ./cmd/tse-import -ano 2022 -uf MG -conjuntos candidatos,bens
+//
+// Os arquivos zip são baixados uma vez para -cache; para usar arquivos já
+// baixados, coloque-os nesse diretório com o nome original (ex.: consulta_cand_2024.zip).
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+
+	"chat-bot/internal/tse"
+)
+
+func main() {
+	ano := flag.Int("ano", 0, "ano da eleição (ex.: 2024)")
+	ufs := flag.String("uf", "", "UFs separadas por vírgula (ex.: SP,RJ; BR para candidaturas a presidente)")
+	conjuntos := flag.String("conjuntos", strings.Join(tse.Kinds, ","), "conjuntos a importar: "+strings.Join(tse.Kinds, ", "))
+	dir := flag.String("dir", "data/tse", "diretório do armazenamento local")
+	cache := flag.String("cache", "data/tse/downloads", "diretório dos arquivos zip baixados")
+	flag.Parse()
+
+	if *ano == 0 || strings.TrimSpace(*ufs) == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	store, err := tse.NewStore(*dir)
+	if err != nil {
+		log.Fatalf("não foi possível carregar os dados do TSE: %v", err)
+	}
+	importer := &tse.Importer{Store: store, CacheDir: *cache}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	kinds := splitList(*conjuntos)
+	for _, uf := range splitList(*ufs) {
+		result, err := importer.Import(ctx, *ano, uf, kinds)
+		if err != nil {
+			log.Fatalf("falha ao importar %d/%s: %v", *ano, strings.ToUpper(uf), err)
+		}
+		log.Printf("✅ %d/%s: %d candidaturas, %d bens, %d resultados por zona", result.Ano, result.UF, result.Candidates, result.Assets, result.Results)
+	}
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, strings.ToLower(item))
+		}
+	}
+	return items
+}
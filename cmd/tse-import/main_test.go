@@ -0,0 +1,23 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitList(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{value: "SP,RJ", want: []string{"sp", "rj"}},
+		{value: " sp , ,MG ", want: []string{"sp", "mg"}},
+		{value: "candidatos,bens", want: []string{"candidatos", "bens"}},
+		{value: "", want: nil},
+	}
+	for _, tt := range tests {
+		if got := splitList(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitList(%q) = %v, esperava %v", tt.value, got, tt.want)
+		}
+	}
+}
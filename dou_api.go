@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"chat-bot/internal/config"
+	"chat-bot/internal/dou"
+)
+
+const (
+	douFilePath      = "data/dou.json"
+	douCacheDir      = "data/dou/downloads"
+	douInboxDir      = "data/dou/entrada"
+	douIngestTimeout = 15 * time.Minute
+	defaultDOUActs   = 20
+	maxDOUActs       = 200
+	maxToolDOUActs   = 10
+	douExcerptChars  = 600
+)
+
+var (
+	douStore    *dou.Store
+	douImporter *dou.Importer
+	// brasilia define o dia da edição do DOU
+	brasilia = time.FixedZone("BRT", -3*60*60)
+)
+
+// setupDOU prepara o armazenamento e a importação dos atos do DOU
+func setupDOU(cfg *config.Config) error {
+	var err error
+	douStore, err = dou.NewStore(douFilePath)
+	if err != nil {
+		return err
+	}
+
+	var sections []string
+	for _, secao := range strings.Split(cfg.DOUSections, ",") {
+		if secao = strings.TrimSpace(secao); secao != "" {
+			sections = append(sections, secao)
+		}
+	}
+	douImporter = &dou.Importer{
+		Store:    douStore,
+		Sections: sections,
+		CacheDir: douCacheDir,
+		InboxDir: douInboxDir,
+		Email:    cfg.InlabsEmail,
+		Password: cfg.InlabsPassword,
+	}
+	return nil
+}
+
+// ingestDOU importa a edição do dia e alerta as listas com palavras-chave
+// encontradas; os atos só são gravados depois que os alertas foram registrados
+func ingestDOU(ctx context.Context) (dou.ImportResult, error) {
+	return douImporter.Import(ctx, time.Now().In(brasilia), func(ctx context.Context, acts []dou.Act) error {
+		_, err := watchlistDispatcher.ProcessActs(ctx, acts)
+		return err
+	})
+}
+
+// parseDOUDate aceita "hoje", "ontem", AAAA-MM-DD e DD/MM/AAAA
+func parseDOUDate(raw string) (string, error) {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	now := time.Now().In(brasilia)
+	switch raw {
+	case "":
+		return "", nil
+	case "hoje":
+		return now.Format("2006-01-02"), nil
+	case "ontem":
+		return now.AddDate(0, 0, -1).Format("2006-01-02"), nil
+	}
+	for _, layout := range []string{"2006-01-02", "02/01/2006"} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t.Format("2006-01-02"), nil
+		}
+	}
+	return "", fmt.Errorf("data inválida %q (use AAAA-MM-DD)", raw)
+}
+
+// DOUResponse é a resposta da busca de atos do DOU
+type DOUResponse struct {
+	Acts       []dou.Act `json:"acts"`
+	Total      int       `json:"total"`
+	LatestDate string    `json:"latestDate"`
+	LastIngest time.Time `json:"lastIngest"`
+}
+
+func handleDOUSearch(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := dou.Filter{
+		Secao: strings.TrimSpace(q.Get("section")),
+		Orgao: strings.TrimSpace(q.Get("organ")),
+		Tipo:  strings.TrimSpace(q.Get("type")),
+	}
+	if keyword := strings.TrimSpace(q.Get("q")); keyword != "" {
+		filter.Keywords = []string{keyword}
+	}
+
+	var err error
+	if date := q.Get("date"); date != "" {
+		filter.From, err = parseDOUDate(date)
+		filter.To = filter.From
+	} else if filter.From, err = parseDOUDate(q.Get("from")); err == nil {
+		filter.To, err = parseDOUDate(q.Get("to"))
+	}
+	if err == nil {
+		filter.Limit, err = intParam(q, "limit", 1, maxDOUActs)
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultDOUActs
+	}
+
+	acts := douStore.Search(filter)
+	json.NewEncoder(w).Encode(DOUResponse{
+		Acts:       acts,
+		Total:      len(acts),
+		LatestDate: douStore.LatestDate(),
+		LastIngest: douStore.LastIngest(),
+	})
+}
+
+// douTool responde "o que saiu no DOU hoje sobre X" com os atos importados
+var douTool = &chatTool{
+	Declaration: GeminiFunctionDeclaration{
+		Name:        "buscar_dou",
+		Description: "Busca atos publicados no Diário Oficial da União (decretos, portarias, nomeações, exonerações, resoluções) importados localmente, filtrando por palavra-chave, órgão, tipo de ato e data.",
+		Parameters: &GeminiSchema{
+			Type: "OBJECT",
+			Properties: map[string]*GeminiSchema{
+				"termo": {Type: "STRING", Description: "Palavra ou expressão procurada no ato, ex.: \"vacina\", \"Fulano de Tal\""},
+				"orgao": {Type: "STRING", Description: "Órgão (ou parte do nome), ex.: \"Ministério da Saúde\""},
+				"tipo":  {Type: "STRING", Description: "Tipo de ato, ex.: \"Decreto\", \"Portaria\""},
+				"data":  {Type: "STRING", Description: "\"hoje\", \"ontem\" ou AAAA-MM-DD; se omitida, a edição mais recente importada"},
+				"secao": {Type: "STRING", Description: "DO1 (atos normativos), DO2 (pessoal) ou DO3 (contratos)"},
+			},
+		},
+	},
+	Keywords: []string{" dou ", "diario oficial", "nomead", "nomeac", "exonera", "portaria", "saiu no"},
+	Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		if douStore.Size() == 0 {
+			return nil, errors.New("nenhum ato do DOU foi importado; oriente o usuário a consultar https://www.in.gov.br/leiturajornal")
+		}
+		filter := dou.Filter{
+			Orgao: stringArg(args, "orgao"),
+			Tipo:  stringArg(args, "tipo"),
+			Secao: stringArg(args, "secao"),
+			Limit: maxToolDOUActs,
+		}
+		if termo := stringArg(args, "termo"); termo != "" {
+			filter.Keywords = []string{termo}
+		}
+		date, err := parseDOUDate(stringArg(args, "data"))
+		if err != nil {
+			return nil, err
+		}
+		if date == "" {
+			date = douStore.LatestDate()
+		}
+		filter.From, filter.To = date, date
+
+		acts := douStore.Search(filter)
+		items := make([]map[string]string, 0, len(acts))
+		for _, act := range acts {
+			items = append(items, map[string]string{
+				"ato":    act.Title(),
+				"tipo":   act.Tipo,
+				"orgao":  act.Orgao,
+				"secao":  act.Secao,
+				"data":   act.Data,
+				"ementa": act.Ementa,
+				"trecho": act.Excerpt(douExcerptChars),
+				"url":    act.URL,
+			})
+		}
+		result := map[string]interface{}{
+			"data":  date,
+			"atos":  items,
+			"fonte": "Diário Oficial da União - Imprensa Nacional (https://www.in.gov.br/)",
+		}
+		if date != douStore.LatestDate() {
+			result["ultimaEdicaoImportada"] = douStore.LatestDate()
+		}
+		return result, nil
+	},
+}
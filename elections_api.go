@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"chat-bot/internal/registry"
+	"chat-bot/internal/scoring"
+	"chat-bot/internal/tse"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	tseDataDir          = "data/tse"
+	maxElectionResults  = 500
+	defaultElectionRows = 50
+	maxToolCandidates   = 10
+	maxToolRanking      = 15
+)
+
+var tseStore *tse.Store
+
+func intParam(q url.Values, key string, min, max int) (int, error) {
+	raw := strings.TrimSpace(q.Get(key))
+	if raw == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("%s deve estar entre %d e %d", key, min, max)
+	}
+	return n, nil
+}
+
+// parseElectionParams lê os filtros comuns: year, uf, office, municipality e limit
+func parseElectionParams(q url.Values) (ano int, uf string, limit int, err error) {
+	if ano, err = intParam(q, "year", 1994, 2100); err != nil {
+		return
+	}
+	uf = strings.ToUpper(strings.TrimSpace(q.Get("uf")))
+	if uf != "" && len(uf) != 2 {
+		err = errors.New("uf deve ter duas letras")
+		return
+	}
+	if limit, err = intParam(q, "limit", 1, maxElectionResults); err != nil {
+		return
+	}
+	if limit == 0 {
+		limit = defaultElectionRows
+	}
+	return
+}
+
+// ElectionCandidatesResponse é a resposta da busca de candidaturas
+type ElectionCandidatesResponse struct {
+	Candidates []tse.CandidateSummary `json:"candidates"`
+	Total      int                    `json:"total"`
+	Ano        int                    `json:"ano"`
+}
+
+// ElectionCandidateResponse é uma candidatura com os bens declarados
+type ElectionCandidateResponse struct {
+	Candidate tse.Candidate        `json:"candidate"`
+	Assets    []tse.Asset          `json:"assets"`
+	TotalBens float64              `json:"totalBens"`
+	Votes     []tse.CandidateVotes `json:"votes"`
+}
+
+// ElectionResultsResponse traz a votação por zona e o total por candidato
+type ElectionResultsResponse struct {
+	Ranking []tse.CandidateVotes `json:"ranking"`
+	Zones   []tse.ZoneResult     `json:"zones"`
+	Ano     int                  `json:"ano"`
+}
+
+func handleElectionDatasets(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]interface{}{"datasets": tseStore.Datasets()})
+}
+
+func handleElectionCandidates(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	ano, uf, limit, err := parseElectionParams(q)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	query := tse.CandidateQuery{
+		Nome:      strings.TrimSpace(q.Get("name")),
+		Cargo:     strings.TrimSpace(q.Get("office")),
+		Municipio: strings.TrimSpace(q.Get("municipality")),
+		UF:        uf,
+		Ano:       ano,
+		Eleitos:   q.Get("elected") == "true",
+		Limit:     limit,
+	}
+	if query.Nome == "" && query.Cargo == "" && query.Municipio == "" {
+		writeJSONError(w, http.StatusBadRequest, "informe name, office ou municipality")
+		return
+	}
+	if query.Ano == 0 {
+		query.Ano = tseStore.LatestYear(uf)
+	}
+
+	found := tseStore.SearchCandidates(query)
+	json.NewEncoder(w).Encode(ElectionCandidatesResponse{Candidates: found, Total: len(found), Ano: query.Ano})
+}
+
+func handleElectionCandidate(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	candidate, assets, ok := tseStore.Candidate(id)
+	if !ok {
+		writeJSONError(w, http.StatusNotFound, "candidatura não encontrada")
+		return
+	}
+
+	resp := ElectionCandidateResponse{
+		Candidate: candidate,
+		Assets:    assets,
+		Votes:     tseStore.Ranking(tse.ResultQuery{Ano: candidate.Ano, UF: candidate.UF, CandidateID: id}),
+	}
+	if resp.Assets == nil {
+		resp.Assets = []tse.Asset{}
+	}
+	for _, a := range assets {
+		resp.TotalBens += a.Valor
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func handleElectionResults(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	ano, uf, limit, err := parseElectionParams(q)
+	if err == nil && uf == "" {
+		err = errors.New("informe a uf")
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	query := tse.ResultQuery{
+		Ano:         ano,
+		UF:          uf,
+		Municipio:   strings.TrimSpace(q.Get("municipality")),
+		Cargo:       strings.TrimSpace(q.Get("office")),
+		CandidateID: strings.TrimSpace(q.Get("candidate")),
+	}
+	if query.Zona, err = intParam(q, "zone", 1, 9999); err == nil {
+		query.Turno, err = intParam(q, "round", 1, 2)
+	}
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if query.Ano == 0 {
+		query.Ano = tseStore.LatestYear(uf)
+	}
+
+	ranking := tseStore.Ranking(query)
+	query.Limit = limit
+	resp := ElectionResultsResponse{Ranking: ranking, Zones: tseStore.Results(query), Ano: query.Ano}
+	if resp.Zones == nil {
+		resp.Zones = []tse.ZoneResult{}
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// applyElectoralPerformance preenche o eixo Popularidade com a votação do
+// parlamentar na última eleição importada do TSE
+func applyElectoralPerformance(input *scoring.Input, id string) (tse.Performance, bool) {
+	politician, ok := politicianRegistry.Get(id)
+	if !ok {
+		return tse.Performance{}, false
+	}
+	cargo := "DEPUTADO FEDERAL"
+	if politician.Casa == registry.CasaSenado {
+		cargo = "SENADOR"
+	}
+	perf, ok := tseStore.FindPerformance(cargo, politician.UF, politician.NomeParlamentar, politician.NomeCivil)
+	if !ok {
+		return tse.Performance{}, false
+	}
+	input.ElectoralVotes = perf.Votos
+	input.ElectoralQuotient = perf.Quociente
+	return perf, true
+}
+
+var electionKeywords = []string{"eleic", "eleit", "candidat", "tse", "patrimonio", "bens declarados", "prefeit", "vereador", "zona eleitoral", "segundo turno", "primeiro turno"}
+
+// electionCandidatesTool consulta candidaturas e bens declarados importados do TSE
+var electionCandidatesTool = &chatTool{
+	Declaration: GeminiFunctionDeclaration{
+		Name:        "buscar_candidatos_tse",
+		Description: "Busca candidaturas nos dados abertos do TSE importados localmente: situação, resultado, partido, votos e total de bens declarados. Com um único resultado, inclui a lista de bens.",
+		Parameters: &GeminiSchema{
+			Type: "OBJECT",
+			Properties: map[string]*GeminiSchema{
+				"nome":      {Type: "STRING", Description: "Nome civil ou de urna do candidato"},
+				"cargo":     {Type: "STRING", Description: "Cargo disputado, ex.: \"Prefeito\", \"Vereador\", \"Deputado Federal\""},
+				"municipio": {Type: "STRING", Description: "Município (eleições municipais)"},
+				"uf":        {Type: "STRING", Description: "Sigla da UF, ex.: \"SP\""},
+				"ano":       {Type: "INTEGER", Description: "Ano da eleição; se omitido, a mais recente importada"},
+				"eleitos":   {Type: "BOOLEAN", Description: "Somente eleitos"},
+			},
+		},
+	},
+	Keywords: electionKeywords,
+	Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		if tseStore.Empty() {
+			return nil, fmt.Errorf("nenhum dado do TSE foi importado; oriente o usuário a consultar https://dadosabertos.tse.jus.br/")
+		}
+		query := tse.CandidateQuery{
+			Nome:      stringArg(args, "nome"),
+			Cargo:     stringArg(args, "cargo"),
+			Municipio: stringArg(args, "municipio"),
+			UF:        strings.ToUpper(stringArg(args, "uf")),
+			Ano:       intArg(args, "ano"),
+			Limit:     maxToolCandidates,
+		}
+		query.Eleitos, _ = args["eleitos"].(bool)
+		if query.Nome == "" && query.Cargo == "" && query.Municipio == "" {
+			return nil, fmt.Errorf("informe nome, cargo ou município")
+		}
+
+		found := tseStore.SearchCandidates(query)
+		result := map[string]interface{}{
+			"candidatos": found,
+			"fonte":      "TSE - Portal de Dados Abertos (https://dadosabertos.tse.jus.br/)",
+		}
+		if len(found) == 1 {
+			_, assets, _ := tseStore.Candidate(found[0].ID)
+			result["bens"] = assets
+		}
+		return result, nil
+	},
+}
+
+// electionResultsTool soma a votação por candidato a partir dos resultados por zona
+var electionResultsTool = &chatTool{
+	Declaration: GeminiFunctionDeclaration{
+		Name:        "resultado_eleicao_tse",
+		Description: "Resultado de uma eleição nos dados abertos do TSE importados localmente: votos nominais, percentual e situação de cada candidato, somados por zona eleitoral.",
+		Parameters: &GeminiSchema{
+			Type: "OBJECT",
+			Properties: map[string]*GeminiSchema{
+				"uf":        {Type: "STRING", Description: "Sigla da UF, ex.: \"SP\""},
+				"cargo":     {Type: "STRING", Description: "Cargo, ex.: \"Prefeito\""},
+				"municipio": {Type: "STRING", Description: "Município"},
+				"zona":      {Type: "INTEGER", Description: "Número da zona eleitoral"},
+				"turno":     {Type: "INTEGER", Description: "1 ou 2"},
+				"ano":       {Type: "INTEGER", Description: "Ano da eleição; se omitido, a mais recente importada"},
+			},
+			Required: []string{"uf", "cargo"},
+		},
+	},
+	Keywords: electionKeywords,
+	Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		if tseStore.Empty() {
+			return nil, fmt.Errorf("nenhum dado do TSE foi importado; oriente o usuário a consultar https://dadosabertos.tse.jus.br/")
+		}
+		query := tse.ResultQuery{
+			UF:        strings.ToUpper(stringArg(args, "uf")),
+			Cargo:     stringArg(args, "cargo"),
+			Municipio: stringArg(args, "municipio"),
+			Zona:      intArg(args, "zona"),
+			Turno:     intArg(args, "turno"),
+			Ano:       intArg(args, "ano"),
+			Limit:     maxToolRanking,
+		}
+		if query.Ano == 0 {
+			query.Ano = tseStore.LatestYear(query.UF)
+		}
+		ranking := tseStore.Ranking(query)
+		if len(ranking) == 0 {
+			return nil, fmt.Errorf("sem resultados importados para %s %s %d", query.Cargo, query.UF, query.Ano)
+		}
+		return map[string]interface{}{
+			"ano":     query.Ano,
+			"ranking": ranking,
+			"fonte":   "TSE - Portal de Dados Abertos (https://dadosabertos.tse.jus.br/)",
+		}, nil
+	},
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"chat-bot/internal/camara"
+	"chat-bot/internal/ceap"
+	"chat-bot/internal/registry"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	ceapFilePath      = "data/ceap.json"
+	ceapIngestTimeout = 30 * time.Minute
+	ceapPerRun        = 120
+	ceapMaxAge        = 72 * time.Hour
+	// expensesFetchTimeout limita a consulta direta à Câmara de um deputado ainda não ingerido
+	expensesFetchTimeout    = 60 * time.Second
+	defaultExpenseSuppliers = 10
+	maxExpenseSuppliers     = 50
+	firstCEAPYear           = 2008
+)
+
+var (
+	ceapStore    *ceap.Store
+	ceapIngester *ceap.Ingester
+)
+
+var (
+	errExpensesSenado   = errors.New("as despesas da CEAP são da Câmara; para senadores, consulte https://www6g.senado.leg.br/transparencia/sen/")
+	errExpensesNotFound = errors.New("nenhuma despesa da CEAP encontrada")
+)
+
+// ceapDeputados lista os deputados em exercício do registro para a ingestão
+func ceapDeputados() []ceap.Deputado {
+	var deputados []ceap.Deputado
+	for _, p := range politicianRegistry.All() {
+		if p.Casa != registry.CasaCamara || !p.EmExercicio {
+			continue
+		}
+		externalID, err := strconv.Atoi(p.ExternalID)
+		if err != nil {
+			continue
+		}
+		deputados = append(deputados, ceap.Deputado{
+			ID:         p.ID,
+			ExternalID: externalID,
+			Nome:       p.NomeParlamentar,
+			Partido:    p.Partido,
+			UF:         p.UF,
+		})
+	}
+	return deputados
+}
+
+// ingestExpenses atualiza as despesas dos deputados mais desatualizados
+func ingestExpenses(ctx context.Context) (ceap.IngestResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, ceapIngestTimeout)
+	defer cancel()
+
+	deputados := ceapDeputados()
+	if len(deputados) == 0 {
+		return ceap.IngestResult{}, errors.New("registro de parlamentares vazio; execute a tarefa parlamentares")
+	}
+	return ceapIngester.Run(ctx, deputados)
+}
+
+// expensesReport devolve a análise das despesas do deputado. Deputados ainda
+// não ingeridos (ou sem o ano pedido) são buscados na hora na Câmara, que só
+// é consultada para o ano corrente e o anterior; as despesas buscadas só são
+// guardadas para deputados do registro.
+func expensesReport(ctx context.Context, id string, ano, suppliers int) (ceap.Report, error) {
+	casa, externalID, err := parsePoliticianID(id)
+	if err != nil {
+		return ceap.Report{}, err
+	}
+	if casa != registry.CasaCamara {
+		return ceap.Report{}, errExpensesSenado
+	}
+	id = registry.MakeID(casa, externalID)
+
+	if report, ok := ceapStore.Report(id, ano, suppliers); ok {
+		return report, nil
+	}
+	if ano != 0 && ano < time.Now().Year()-1 {
+		return ceap.Report{}, fmt.Errorf("%w em %d para %s", errExpensesNotFound, ano, id)
+	}
+
+	numericID, _ := strconv.Atoi(externalID)
+	deputado := ceap.Deputado{ID: id, ExternalID: numericID}
+	p, registered := politicianRegistry.Get(id)
+	if registered {
+		deputado.Nome, deputado.Partido, deputado.UF = p.NomeParlamentar, p.Partido, p.UF
+	} else {
+		detalhe, err := camaraClient.GetDeputado(ctx, numericID)
+		if err != nil {
+			return ceap.Report{}, err
+		}
+		status := detalhe.UltimoStatus
+		deputado.Nome, deputado.Partido, deputado.UF = status.Nome, status.SiglaPartido, status.SiglaUF
+	}
+
+	record, err := ceapIngester.Fetch(ctx, deputado, time.Now())
+	if err != nil {
+		return ceap.Report{}, err
+	}
+
+	// Só os deputados do registro são guardados: um identificador qualquer
+	// pedido por um visitante não pode mudar as medianas do estado
+	var report ceap.Report
+	var ok bool
+	if registered {
+		if err := ceapStore.Upsert([]ceap.Record{record}, time.Time{}); err != nil {
+			log.Printf("⚠️  não foi possível guardar as despesas de %s: %v", id, err)
+		}
+		report, ok = ceapStore.Report(id, ano, suppliers)
+	} else {
+		report, ok = ceapStore.ReportFor(record, ano, suppliers)
+	}
+	if !ok {
+		return ceap.Report{}, fmt.Errorf("%w para %s", errExpensesNotFound, deputado.Nome)
+	}
+	return report, nil
+}
+
+// ExpensesResponse é a análise das despesas com o método e a fonte dos dados
+type ExpensesResponse struct {
+	ceap.Report
+	Method  string   `json:"method"`
+	Sources []Source `json:"sources"`
+}
+
+func handlePoliticianExpenses(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	ano, err := intParam(q, "year", firstCEAPYear, time.Now().Year())
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	suppliers, err := intParam(q, "suppliers", 1, maxExpenseSuppliers)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if suppliers == 0 {
+		suppliers = defaultExpenseSuppliers
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), expensesFetchTimeout)
+	defer cancel()
+
+	id := mux.Vars(r)["id"]
+	report, err := expensesReport(ctx, id, ano, suppliers)
+	if err != nil {
+		switch {
+		case errors.Is(err, errInvalidPoliticianID):
+			writeJSONError(w, http.StatusBadRequest, "use identificadores no formato camara-<id>")
+		case errors.Is(err, errExpensesSenado):
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, errExpensesNotFound):
+			writeJSONError(w, http.StatusNotFound, err.Error())
+		default:
+			log.Printf("erro ao consultar despesas de %s: %v", id, err)
+			writeJSONError(w, http.StatusBadGateway, "não foi possível consultar as despesas no momento")
+		}
+		return
+	}
+
+	json.NewEncoder(w).Encode(expensesResponse(report))
+}
+
+func expensesResponse(report ceap.Report) ExpensesResponse {
+	_, externalID, _ := parsePoliticianID(report.ID)
+	return ExpensesResponse{
+		Report: report,
+		Method: fmt.Sprintf("Valores líquidos da CEAP por mês, categoria e fornecedor. Outliers: total do ano, categoria ou mês ao menos %.0fx acima da mediana dos demais deputados do mesmo estado (mínimo de %d deputados com dados).", ceap.OutlierRatio, ceap.MinPeers),
+		Sources: []Source{
+			{Nome: "Dados Abertos - Câmara", URL: fmt.Sprintf("%s/deputados/%s/despesas", camara.BaseURL, externalID), Desc: "Cota para o Exercício da Atividade Parlamentar"},
+		},
+	}
+}
+
+// expensesTool responde perguntas sobre os gastos da cota parlamentar de um deputado
+var expensesTool = &chatTool{
+	Declaration: GeminiFunctionDeclaration{
+		Name:        "consultar_despesas_deputado",
+		Description: "Consulta os gastos da cota parlamentar (CEAP) de um deputado federal: total do ano, totais mensais, categorias, principais fornecedores (CNPJ) e gastos muito acima da mediana dos deputados do mesmo estado.",
+		Parameters: &GeminiSchema{
+			Type: "OBJECT",
+			Properties: map[string]*GeminiSchema{
+				"deputado": {Type: "STRING", Description: "Nome (ex.: \"Arthur Lira\") ou identificador (ex.: \"camara-160541\") do deputado"},
+				"ano":      {Type: "INTEGER", Description: "Ano das despesas; se omitido, o mais recente"},
+			},
+			Required: []string{"deputado"},
+		},
+	},
+	Keywords: []string{"despesa", "gast", "ceap", "cota parlamentar", "verba", "reembols", "fornecedor"},
+	Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		id, err := resolvePoliticianRef(stringArg(args, "deputado"))
+		if err != nil {
+			return nil, err
+		}
+		ano := intArg(args, "ano")
+		if ano != 0 && (ano < firstCEAPYear || ano > time.Now().Year()) {
+			return nil, fmt.Errorf("ano inválido: %d", ano)
+		}
+		report, err := expensesReport(ctx, id, ano, defaultExpenseSuppliers)
+		if err != nil {
+			return nil, err
+		}
+		return expensesResponse(report), nil
+	},
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"chat-bot/internal/feeds"
+	"chat-bot/internal/proposicoes"
+	"chat-bot/internal/registry"
+	"chat-bot/internal/votes"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	maxFeedEntries = 50
+	feedIDPrefix   = "urn:politicianinsight:"
+)
+
+// publicBaseURL é a origem pública configurada em PUBLIC_BASE_URL, sem barra final
+var publicBaseURL string
+
+// setPublicBaseURL valida e guarda a origem pública configurada
+func setPublicBaseURL(raw string) error {
+	raw = strings.TrimSuffix(strings.TrimSpace(raw), "/")
+	if raw == "" {
+		return nil
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" || parsed.RawQuery != "" || parsed.Fragment != "" {
+		return fmt.Errorf("PUBLIC_BASE_URL inválida: %q (use algo como https://agoraai.exemplo.com)", raw)
+	}
+	publicBaseURL = raw
+	return nil
+}
+
+// feedBaseURL retorna a origem pública configurada ou, sem ela, monta a origem
+// a partir da requisição (Cloud Run usa X-Forwarded-Proto)
+func feedBaseURL(r *http.Request) string {
+	if publicBaseURL != "" {
+		return publicBaseURL
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = strings.TrimSpace(strings.Split(proto, ",")[0])
+	}
+	return scheme + "://" + r.Host
+}
+
+// writeFeed envia o feed com ETag e Last-Modified; http.ServeContent responde
+// 304 para If-None-Match e If-Modified-Since
+func writeFeed(w http.ResponseWriter, r *http.Request, feed *feeds.Feed, fallback time.Time) {
+	body, err := feed.Render(maxFeedEntries, fallback)
+	if err != nil {
+		log.Printf("[FEEDS] erro ao gerar %s: %v", r.URL.Path, err)
+		http.Error(w, "erro ao gerar o feed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", feeds.ContentType)
+	w.Header().Set("ETag", feeds.ETag(body))
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	http.ServeContent(w, r, "", feed.LastModified(), bytes.NewReader(body))
+}
+
+func voteSummary(v votes.Vote) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s, %s. Resultado: %s (Sim %d, Não %d, Abstenção %d).", v.Chamber, v.Date, v.Stage, v.Support, v.Against, v.Abstention)
+	if v.GovernmentOrientation != "" {
+		fmt.Fprintf(&b, " Orientação do governo: %s.", v.GovernmentOrientation)
+	}
+	if v.Ementa != "" {
+		fmt.Fprintf(&b, "\n\n%s", v.Ementa)
+	}
+	return b.String()
+}
+
+func voteTime(v votes.Vote) time.Time {
+	if t, ok := feeds.ParseTime(v.Date); ok {
+		return t
+	}
+	return v.IngestedAt
+}
+
+// handleBillFeed publica a tramitação de uma proposição já acompanhada; as demais respondem 404
+func handleBillFeed(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	sigla := strings.ToUpper(vars["sigla"])
+	numero, _ := strconv.Atoi(vars["numero"])
+	ano, _ := strconv.Atoi(vars["ano"])
+	label := fmt.Sprintf("%s %d/%d", sigla, numero, ano)
+
+	casa := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("casa")))
+	if casa != "" && casa != proposicoes.CasaCamara && casa != proposicoes.CasaSenado {
+		http.Error(w, "casa deve ser 'camara' ou 'senado'", http.StatusBadRequest)
+		return
+	}
+
+	// Só proposições já acompanhadas (pela listagem ou por listas) têm feed: uma
+	// requisição anônima não deve consultar as APIs nem passar a ser sincronizada
+	var found []proposicoes.Proposicao
+	for _, p := range proposicaoStore.FindByLabel(sigla, numero, ano) {
+		if casa == "" || p.Casa == casa {
+			found = append(found, p)
+		}
+	}
+	if len(found) == 0 {
+		http.Error(w, "proposição não acompanhada: "+label, http.StatusNotFound)
+		return
+	}
+
+	base := feedBaseURL(r)
+	feed := feeds.New(feedIDPrefix+"bills:"+strings.ToLower(vars["sigla"]+"-"+vars["numero"]+"-"+vars["ano"]),
+		label+" – tramitação", base+r.URL.Path, found[0].URL)
+
+	var fallback time.Time
+	for _, p := range found {
+		if feed.Subtitle == "" {
+			feed.Subtitle = p.Ementa
+		}
+		if p.SeenAt.After(fallback) {
+			fallback = p.SeenAt
+		}
+
+		casaNome := "Câmara"
+		if p.Casa == proposicoes.CasaSenado {
+			casaNome = "Senado"
+		}
+		for _, t := range p.Tramitacoes {
+			updated, ok := feeds.ParseTime(t.Data)
+			if !ok {
+				continue
+			}
+			title := t.Descricao
+			if t.Orgao != "" {
+				title = t.Orgao + ": " + title
+			}
+			summary := t.Descricao
+			if t.Despacho != "" && t.Despacho != t.Descricao {
+				summary += "\n\n" + t.Despacho
+			}
+			if t.Situacao != "" {
+				summary += "\n\nSituação: " + t.Situacao
+			}
+			link := t.URL
+			if link == "" {
+				link = p.URL
+			}
+			feed.Add(fmt.Sprintf("%sbills:%s:%d", feedIDPrefix, p.ID, t.Sequencia), title, link, summary, updated, p.TramitacoesSyncedAt, casaNome)
+		}
+	}
+
+	writeFeed(w, r, feed, fallback)
+}
+
+// handleVotesFeed publica as votações nominais mais recentes (filtros opcionais chamber e topic)
+func handleVotesFeed(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := votes.Filter{Topic: strings.TrimSpace(query.Get("topic")), Limit: maxFeedEntries}
+	if raw := query.Get("chamber"); raw != "" {
+		if filter.Chamber = votes.NormalizeChamber(raw); filter.Chamber == "" {
+			http.Error(w, "chamber deve ser 'camara' ou 'senado'", http.StatusBadRequest)
+			return
+		}
+	}
+
+	base := feedBaseURL(r)
+	feed := feeds.New(feedIDPrefix+"votes", "Votações nominais – Câmara e Senado", base+r.URL.Path, base+"/")
+	feed.Subtitle = "Resultados das votações nominais do plenário"
+	for _, v := range voteStore.Query(filter) {
+		feed.Add(feedIDPrefix+"votes:"+v.ID, v.Title+" – "+v.Stage, v.SourceURL, voteSummary(v), voteTime(v), v.IngestedAt, v.Chamber, v.Topic)
+	}
+
+	writeFeed(w, r, feed, voteStore.LastIngest())
+}
+
+// handleDeputadoFeed publica os votos nominais de um deputado
+func handleDeputadoFeed(w http.ResponseWriter, r *http.Request) {
+	id := registry.MakeID(registry.CasaCamara, mux.Vars(r)["id"])
+
+	items := voteStore.Query(votes.Filter{PoliticianID: id, Limit: maxFeedEntries, IncludeVotes: true})
+	politician, known := politicianRegistry.Get(id)
+	if !known && len(items) == 0 {
+		http.Error(w, "deputado não encontrado", http.StatusNotFound)
+		return
+	}
+
+	name := politician.NomeParlamentar
+	if name == "" {
+		for _, nv := range items[0].Votes {
+			if nv.PoliticianID == id {
+				name = nv.Nome
+				break
+			}
+		}
+	}
+	title := "Votos de " + name
+	if politician.Partido != "" && politician.UF != "" {
+		title += fmt.Sprintf(" (%s-%s)", politician.Partido, politician.UF)
+	}
+
+	profileURL := ""
+	if known {
+		profileURL = "https://www.camara.leg.br/deputados/" + politician.ExternalID
+	}
+	feed := feeds.New(feedIDPrefix+"deputados:"+id, title, feedBaseURL(r)+r.URL.Path, profileURL)
+	for _, v := range items {
+		voto := ""
+		for _, nv := range v.Votes {
+			if nv.PoliticianID == id {
+				voto = nv.Voto
+				break
+			}
+		}
+		summary := fmt.Sprintf("%s votou %s.\n\n%s", name, voto, voteSummary(v))
+		feed.Add(feedIDPrefix+"deputados:"+id+":"+v.ID, fmt.Sprintf("%s: %s", voto, v.Title), v.SourceURL, summary, voteTime(v), v.IngestedAt, v.Topic)
+	}
+
+	writeFeed(w, r, feed, voteStore.LastIngest())
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"chat-bot/internal/votes"
+)
+
+func TestVotesFeedConditionalRequests(t *testing.T) {
+	store, err := votes.NewStore(filepath.Join(t.TempDir(), "votes.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := voteStore
+	voteStore = store
+	defer func() { voteStore = previous }()
+
+	firstIngest := time.Date(2026, 10, 15, 20, 0, 0, 0, time.UTC)
+	if err := store.Upsert([]votes.Vote{{ID: "camara-1", Title: "PL 1/2026", Chamber: "Câmara", Date: "2026-10-15", IngestedAt: firstIngest}}, firstIngest); err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/feeds/votes.atom", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		rec := httptest.NewRecorder()
+		handleVotesFeed(rec, req)
+		return rec
+	}
+
+	first := get("", "")
+	etag, lastModified := first.Header().Get("ETag"), first.Header().Get("Last-Modified")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("primeira leitura: status %d, ETag %q", first.Code, etag)
+	}
+	if want := firstIngest.Format(http.TimeFormat); lastModified != want {
+		t.Errorf("Last-Modified = %q, esperava a ingestão %q", lastModified, want)
+	}
+	if rec := get("If-None-Match", etag); rec.Code != http.StatusNotModified {
+		t.Errorf("If-None-Match igual: status %d, esperava 304", rec.Code)
+	}
+	if rec := get("If-Modified-Since", lastModified); rec.Code != http.StatusNotModified {
+		t.Errorf("If-Modified-Since igual: status %d, esperava 304", rec.Code)
+	}
+
+	// Outra votação do mesmo dia, ingerida depois: a data da fonte não muda,
+	// mas o feed mudou e não pode responder 304
+	laterIngest := firstIngest.Add(3 * time.Hour)
+	if err := store.Upsert([]votes.Vote{{ID: "camara-2", Title: "PL 2/2026", Chamber: "Câmara", Date: "2026-10-15", IngestedAt: laterIngest}}, laterIngest); err != nil {
+		t.Fatal(err)
+	}
+	if rec := get("If-Modified-Since", lastModified); rec.Code != http.StatusOK {
+		t.Errorf("If-Modified-Since após nova ingestão: status %d, esperava 200", rec.Code)
+	}
+	rec := get("If-None-Match", etag)
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+		t.Errorf("If-None-Match após nova ingestão: status %d, ETag %q", rec.Code, rec.Header().Get("ETag"))
+	}
+	if got, want := rec.Header().Get("Last-Modified"), laterIngest.Format(http.TimeFormat); got != want {
+		t.Errorf("Last-Modified = %q, esperava %q", got, want)
+	}
+}
@@ -4,6 +4,7 @@ import Chat from './components/Chat';
 import Sidebar from './components/Sidebar';
 import AdminLogin from './components/AdminLogin';
 import NPSAdmin from './components/NPSAdmin';
+import { getAdminToken } from './adminSession';
 import './App.css';
 
 function ChatPage() {
@@ -61,9 +62,8 @@ function ChatPage() {
 
 // Protected Route para admin
 function ProtectedAdminRoute({ children }) {
-  const isAuthenticated = sessionStorage.getItem('nps_admin_authenticated') === 'true';
-  
-  if (!isAuthenticated) {
+  // O token é validado pelo servidor a cada chamada; aqui só se confere se há sessão
+  if (!getAdminToken()) {
     return <Navigate to="/admin/login" replace />;
   }
   
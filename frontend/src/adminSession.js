@@ -0,0 +1,57 @@
+// Sessão da área administrativa: guarda o token de ID (Firebase ou token de
+// teste local) e o envia no cabeçalho Authorization das rotas protegidas.
+
+const TOKEN_KEY = 'nps_admin_token';
+const FIREBASE_API_KEY = import.meta.env.VITE_FIREBASE_API_KEY;
+
+export const firebaseLoginEnabled = Boolean(FIREBASE_API_KEY);
+
+export const getAdminToken = () => sessionStorage.getItem(TOKEN_KEY) || '';
+
+export const clearAdminToken = () => sessionStorage.removeItem(TOKEN_KEY);
+
+export class AdminAuthError extends Error {}
+
+// adminFetch chama uma rota administrativa; 401/403 encerram a sessão
+export const adminFetch = async (url, options = {}) => {
+  const response = await fetch(url, {
+    ...options,
+    headers: { ...(options.headers || {}), Authorization: `Bearer ${getAdminToken()}` }
+  });
+  if (response.status === 401 || response.status === 403) {
+    clearAdminToken();
+    const data = await response.json().catch(() => ({}));
+    throw new AdminAuthError(data.error || 'Sessão expirada. Entre novamente.');
+  }
+  return response;
+};
+
+// signInWithPassword troca e-mail e senha por um token de ID do Firebase (API REST do Identity Toolkit)
+const signInWithPassword = async (email, password) => {
+  const response = await fetch(
+    `https://identitytoolkit.googleapis.com/v1/accounts:signInWithPassword?key=${FIREBASE_API_KEY}`,
+    {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ email, password, returnSecureToken: true })
+    }
+  );
+  const data = await response.json().catch(() => ({}));
+  if (!response.ok || !data.idToken) {
+    throw new AdminAuthError('E-mail ou senha incorretos.');
+  }
+  return data.idToken;
+};
+
+// adminLogin valida o token no servidor (exige a permissão de administrador) e abre a sessão
+export const adminLogin = async ({ email, password, devToken }) => {
+  const token = devToken ? devToken.trim() : await signInWithPassword(email.trim(), password);
+  sessionStorage.setItem(TOKEN_KEY, token);
+  const response = await adminFetch('/api/admin/me');
+  if (!response.ok) {
+    clearAdminToken();
+    const data = await response.json().catch(() => ({}));
+    throw new AdminAuthError(data.error || 'Não foi possível validar o acesso.');
+  }
+  return response.json();
+};
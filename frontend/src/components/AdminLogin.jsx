@@ -1,31 +1,29 @@
 import { useState } from 'react';
 import { useNavigate } from 'react-router-dom';
+import { adminLogin, firebaseLoginEnabled } from '../adminSession';
 import './AdminLogin.css';
 
-const ADMIN_PASSWORD = 'agoraai-admin-2024';
-
 const AdminLogin = () => {
+  const [email, setEmail] = useState('');
   const [password, setPassword] = useState('');
+  const [devToken, setDevToken] = useState('');
   const [error, setError] = useState('');
   const [isSubmitting, setIsSubmitting] = useState(false);
   const navigate = useNavigate();
 
-  const handleSubmit = (e) => {
+  const handleSubmit = async (e) => {
     e.preventDefault();
     setError('');
     setIsSubmitting(true);
 
-    // Simula um pequeno delay para melhor UX
-    setTimeout(() => {
-      if (password.trim() === ADMIN_PASSWORD) {
-        // Salva autenticação no sessionStorage
-        sessionStorage.setItem('nps_admin_authenticated', 'true');
-        navigate('/admin/nps');
-      } else {
-        setError('Senha incorreta. Tente novamente.');
-        setIsSubmitting(false);
-      }
-    }, 300);
+    try {
+      // O servidor confere o token e a permissão de administrador
+      await adminLogin(firebaseLoginEnabled ? { email, password } : { devToken });
+      navigate('/admin/nps');
+    } catch (err) {
+      setError(err.message || 'Não foi possível entrar. Tente novamente.');
+      setIsSubmitting(false);
+    }
   };
 
   return (
@@ -37,17 +35,47 @@ const AdminLogin = () => {
         </div>
 
         <form onSubmit={handleSubmit} className="admin-login-form">
-          <label htmlFor="admin-password">Digite a senha administrativa</label>
-          <input
-            id="admin-password"
-            type="password"
-            value={password}
-            onChange={(e) => setPassword(e.target.value)}
-            placeholder="Senha da equipe"
-            autoFocus
-            disabled={isSubmitting}
-            required
-          />
+          {firebaseLoginEnabled ? (
+            <>
+              <label htmlFor="admin-email">E-mail</label>
+              <input
+                id="admin-email"
+                type="email"
+                value={email}
+                onChange={(e) => setEmail(e.target.value)}
+                placeholder="gestor@exemplo.com"
+                autoComplete="username"
+                autoFocus
+                disabled={isSubmitting}
+                required
+              />
+              <label htmlFor="admin-password">Senha</label>
+              <input
+                id="admin-password"
+                type="password"
+                value={password}
+                onChange={(e) => setPassword(e.target.value)}
+                placeholder="Senha da conta"
+                autoComplete="current-password"
+                disabled={isSubmitting}
+                required
+              />
+            </>
+          ) : (
+            <>
+              <label htmlFor="admin-token">Token de acesso (desenvolvimento local)</label>
+              <input
+                id="admin-token"
+                type="password"
+                value={devToken}
+                onChange={(e) => setDevToken(e.target.value)}
+                placeholder="Gerado com go run ./cmd/admin-auth token"
+                autoFocus
+                disabled={isSubmitting}
+                required
+              />
+            </>
+          )}
 
           {error && (
             <p className="admin-login-error" role="alert">
@@ -60,13 +88,13 @@ const AdminLogin = () => {
           </button>
 
           <p className="admin-login-hint">
-            Somente gestores autenticados podem consultar os resultados agregados.
+            Somente gestores com permissão de administrador podem consultar os resultados agregados.
           </p>
         </form>
 
         <div className="admin-login-footer">
-          <button 
-            type="button" 
+          <button
+            type="button"
             className="admin-login-back"
             onClick={() => navigate('/')}
           >
@@ -79,5 +107,3 @@ const AdminLogin = () => {
 };
 
 export default AdminLogin;
-
-
@@ -235,6 +235,24 @@ const getNpsConfig = () => {
   return { targetQuestion: null, hasAppeared: false, hasSubmitted: false };
 };
 
+// Identificador anônimo da sessão, renovado a cada aba; vai junto com a resposta do NPS
+const CHAT_SESSION_ID_KEY = 'agoraai-session-id';
+
+const getSessionId = () => {
+  try {
+    let sessionId = sessionStorage.getItem(CHAT_SESSION_ID_KEY);
+    if (!sessionId) {
+      sessionId = typeof crypto !== 'undefined' && crypto.randomUUID
+        ? crypto.randomUUID()
+        : `${Date.now().toString(36)}-${Math.random().toString(36).slice(2)}`;
+      sessionStorage.setItem(CHAT_SESSION_ID_KEY, sessionId);
+    }
+    return sessionId;
+  } catch (error) {
+    return '';
+  }
+};
+
 const saveNpsConfig = (config) => {
   try {
     localStorage.setItem(NPS_CONFIG_KEY, JSON.stringify(config));
@@ -252,6 +270,8 @@ const Chat = ({ currentChatId, setCurrentChatId }) => {
   const [displayedSuggestions, setDisplayedSuggestions] = useState(() => pickRandomSuggestions());
   const [showNpsWithDelay, setShowNpsWithDelay] = useState(false);
   const [npsConfig, setNpsConfig] = useState(() => getNpsConfig());
+  // Contexto da conversa no momento em que a pesquisa aparece
+  const [npsContext, setNpsContext] = useState(null);
   const [windowHeight, setWindowHeight] = useState(() => window.innerHeight);
   const [messagesTotalHeight, setMessagesTotalHeight] = useState(0);
 
@@ -638,6 +658,13 @@ const Chat = ({ currentChatId, setCurrentChatId }) => {
         
         if (shouldShowNps) {
           npsTimerRef.current = setTimeout(() => {
+            setNpsContext({
+              sessionId: getSessionId(),
+              conversationId: chatId,
+              model: data.model,
+              promptVersion: data.promptVersion,
+              questionCount: currentUserMessageCount
+            });
             setShowNpsWithDelay(true);
             // Marca como aparecido
             const updatedConfig = {
@@ -999,11 +1026,15 @@ const Chat = ({ currentChatId, setCurrentChatId }) => {
       return; // Não gera gráfico se não identificou um político válido
     }
 
-    // Gera o gráfico após um pequeno delay para melhor UX
-    setTimeout(() => {
+    // Busca o perfil calculado pelo backend a partir de dados oficiais
+    fetchHexagonalData(detectedPolitician).then((profile) => {
+      if (!profile) {
+        return; // Não exibe gráfico sem dados confiáveis
+      }
+
       const analysisData = {
-        politician: detectedPolitician,
-        data: generateHexagonalData(detectedPolitician)
+        politician: profile.politician,
+        data: profile.data
       };
       const analysisMessage = {
         role: 'assistant',
@@ -1023,67 +1054,46 @@ const Chat = ({ currentChatId, setCurrentChatId }) => {
         }
         return newMessages;
       });
-    }, 1500);
+    });
   };
 
-  const generateHexagonalData = (politician) => {
-    // Mesmos 6 pontos para todos os políticos (baseado no README)
-    const basePoints = [
-      { label: 'Experiência', value: 95 },
-      { label: 'Popularidade', value: 85 },
-      { label: 'Transparência', value: 60 },
-      { label: 'Internacional', value: 70 },
-      { label: 'Gestão', value: 40 },
-      { label: 'Coalizão', value: 50 }
-    ];
-
-    // Ajustar valores baseado no político conhecido
-    const politicianAdjustments = {
-      'Lula': [0, 0, 0, 0, 0, 0], // Valores base já são do Lula
-      'Bolsonaro': [-10, 5, -5, -10, 5, -5],
-      'Ciro Gomes': [-5, -10, 10, 5, 10, -5],
-      'Marina Silva': [-15, -15, 15, 10, 5, 5],
-      'Aécio Neves': [-20, -20, -10, -5, -5, 0],
-      'Michel Temer': [-5, -25, -5, 0, -5, 10],
-      'Dilma Rousseff': [5, -15, 5, 5, 5, -10],
-      'João Doria': [-25, -20, 5, -15, 0, -10],
-      'Fernando Haddad': [-15, -20, 5, -10, -5, -5],
-      'Guilherme Boulos': [-20, -15, 10, -20, -10, -15],
-      'Marcelo Freixo': [-15, -10, 15, -15, -5, -10]
-    };
+  // Resolve o nome no registro de parlamentares e busca o perfil oficial
+  // (/score); nomes ambíguos ou fora do registro ficam sem gráfico
+  const fetchHexagonalData = async (politician) => {
+    try {
+      const searchResponse = await fetch(`/api/politicians/search?q=${encodeURIComponent(politician)}&limit=2`);
+      if (!searchResponse.ok) {
+        return null;
+      }
+      const search = await searchResponse.json();
+      if (!Array.isArray(search.candidates) || search.candidates.length === 0 || search.ambiguous) {
+        return null;
+      }
+      const match = search.candidates[0].politician;
 
-    // Para políticos desconhecidos, gera valores baseados em hash do nome
-    // Isso garante que o mesmo político sempre receba os mesmos valores
-    let adjustments = politicianAdjustments[politician];
-    
-    if (!adjustments) {
-      // Gera ajustes pseudoaleatórios baseados no nome (sempre os mesmos para o mesmo nome)
-      const nameHash = politician.split('').reduce((acc, char) => acc + char.charCodeAt(0), 0);
-      adjustments = [
-        (nameHash % 30) - 15,      // -15 a 15
-        ((nameHash * 2) % 30) - 15,
-        ((nameHash * 3) % 30) - 15,
-        ((nameHash * 4) % 30) - 15,
-        ((nameHash * 5) % 30) - 15,
-        ((nameHash * 6) % 30) - 15
-      ];
-    }
+      const response = await fetch(`/api/politicians/${encodeURIComponent(match.id)}/score`);
+      if (!response.ok) {
+        return null;
+      }
 
-    // Função para determinar categoria baseada no valor
-    const getCategory = (value) => {
-      if (value >= 70) return 'forte';
-      if (value >= 50) return 'medio';
-      return 'fraco';
-    };
+      const score = await response.json();
+      if (!Array.isArray(score.axes) || score.axes.length === 0) {
+        return null;
+      }
 
-    return basePoints.map((point, index) => {
-      const adjustedValue = Math.max(0, Math.min(100, point.value + adjustments[index]));
       return {
-        label: point.label,
-        value: adjustedValue,
-        category: getCategory(adjustedValue)
+        politician: match.nomeParlamentar || politician,
+        data: score.axes.map((axis) => ({
+          label: axis.label,
+          value: axis.available ? axis.value : 0,
+          available: axis.available,
+          category: axis.category,
+          explicacao: axis.explicacao
+        }))
       };
-    });
+    } catch (error) {
+      return null;
+    }
   };
 
   const formatMessage = (text) => {
@@ -1239,7 +1249,7 @@ const Chat = ({ currentChatId, setCurrentChatId }) => {
           {showNpsSurvey && (
             <div className="nps-wrapper">
               <Suspense fallback={<div className="lazy-fallback" aria-hidden="true">Carregando pesquisa...</div>}>
-                <NPSSurvey />
+                <NPSSurvey context={npsContext} />
               </Suspense>
             </div>
           )}
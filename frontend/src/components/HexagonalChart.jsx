@@ -159,7 +159,8 @@ const HexagonalChart = ({ data, politicianName }) => {
       // Valor
       ctx.fillStyle = textColor;
       ctx.font = '12px sans-serif';
-      ctx.fillText(`${item.value}%`, labelX, labelY + 8);
+      // Eixos sem dados oficiais suficientes aparecem como "n/d"
+      ctx.fillText(item.available === false ? 'n/d' : `${item.value}%`, labelX, labelY + 8);
     });
   }, [data, isDarkMode]);
 
@@ -1,29 +1,101 @@
+import { useEffect, useMemo, useState } from 'react';
 import VotingDashboard from './VotingDashboard';
 import IssueDashboard from './IssueDashboard';
 import './InsightsOverview.css';
 
-const highlightMetrics = [
-  {
-    label: 'Projetos monitorados',
-    value: '128',
-    detail: '+12 nesta semana',
-    tone: 'positive'
-  },
-  {
-    label: 'Índice de transparência',
-    value: '92%',
-    detail: 'baseado em fontes oficiais',
-    tone: 'neutral'
-  },
-  {
-    label: 'Alertas ativos',
-    value: '9',
-    detail: 'novas pautas em votação',
-    tone: 'warning'
+const formatDelta = (delta, formatter) => {
+  if (typeof delta !== 'number') return null;
+  if (delta === 0) return 'sem variação na semana';
+  const sign = delta > 0 ? '+' : '−';
+  return `${sign}${formatter(Math.abs(delta))} nesta semana`;
+};
+
+const toneFor = (delta) => {
+  if (typeof delta !== 'number' || delta === 0) return 'neutral';
+  return delta > 0 ? 'positive' : 'warning';
+};
+
+const formatPercentPoints = (value) => `${Math.round(value * 100)} p.p.`;
+
+const buildMetrics = (summary) => {
+  if (!summary) {
+    return [
+      { label: 'Projetos monitorados', value: '—', detail: 'carregando...', tone: 'neutral' },
+      { label: 'Índice de transparência', value: '—', detail: 'carregando...', tone: 'neutral' },
+      { label: 'Alertas ativos', value: '—', detail: 'carregando...', tone: 'neutral' }
+    ];
+  }
+
+  const { billsTracked, transparencyIndex, activeAlerts } = summary;
+
+  return [
+    {
+      label: 'Projetos monitorados',
+      value: billsTracked.available ? billsTracked.value.toLocaleString('pt-BR') : '—',
+      detail: billsTracked.available
+        ? formatDelta(billsTracked.delta, (value) => value.toLocaleString('pt-BR')) ?? 'proposições acompanhadas'
+        : 'sincronização ainda não realizada',
+      tone: billsTracked.available ? toneFor(billsTracked.delta) : 'neutral'
+    },
+    {
+      label: 'Índice de transparência',
+      value: transparencyIndex.available ? `${Math.round(transparencyIndex.value * 100)}%` : '—',
+      detail: transparencyIndex.available
+        ? formatDelta(transparencyIndex.delta, formatPercentPoints) ?? 'respostas com fontes oficiais'
+        : 'sem respostas nos últimos 30 dias',
+      tone: transparencyIndex.available ? toneFor(transparencyIndex.delta) : 'neutral'
+    },
+    {
+      label: 'Alertas ativos',
+      value: activeAlerts.available ? activeAlerts.value.toLocaleString('pt-BR') : '—',
+      detail: activeAlerts.available
+        ? formatDelta(activeAlerts.delta, (value) => value.toLocaleString('pt-BR')) ?? 'nas listas de acompanhamento'
+        : 'nenhuma lista de acompanhamento',
+      tone: activeAlerts.available && activeAlerts.value > 0 ? 'warning' : 'neutral'
+    }
+  ];
+};
+
+const formatUpdatedAt = (updatedAt) => {
+  if (!updatedAt) return null;
+  const date = new Date(updatedAt);
+  // Dados ingeridos nas últimas 36 horas contam como atualização diária
+  if (Date.now() - date.getTime() < 36 * 60 * 60 * 1000) {
+    return 'Atualizado diariamente';
   }
-];
+  return `Atualizado em ${new Intl.DateTimeFormat('pt-BR', { day: '2-digit', month: 'short', year: 'numeric' }).format(date)}`;
+};
 
 const InsightsOverview = ({ showHeader }) => {
+  const [summary, setSummary] = useState(null);
+
+  useEffect(() => {
+    let cancelled = false;
+
+    const fetchSummary = async () => {
+      try {
+        const response = await fetch('/api/insights/summary');
+        if (!response.ok) {
+          throw new Error('Falha ao buscar métricas');
+        }
+        const data = await response.json();
+        if (!cancelled) {
+          setSummary(data);
+        }
+      } catch (error) {
+        // Mantém os indicadores vazios quando o backend não responde
+      }
+    };
+
+    fetchSummary();
+    return () => {
+      cancelled = true;
+    };
+  }, []);
+
+  const highlightMetrics = useMemo(() => buildMetrics(summary), [summary]);
+  const updatedLabel = formatUpdatedAt(summary?.updatedAt);
+
   return (
     <section className={`insights-overview ${showHeader ? 'with-header' : ''}`} aria-label="Painel de insights políticos">
       <div className="insights-hero">
@@ -33,7 +105,7 @@ const InsightsOverview = ({ showHeader }) => {
             Acompanhe votações decisivas, temas mais quentes e métricas consolidadas em um painel objetivo para decisões rápidas.
           </p>
           <div className="insights-badges">
-            <span className="insight-badge">Atualizado diariamente</span>
+            {updatedLabel && <span className="insight-badge">{updatedLabel}</span>}
             <span className="insight-badge">Fontes oficiais + curadoria</span>
           </div>
         </div>
@@ -1,17 +1,63 @@
-import { useState, useEffect, useCallback, useMemo } from 'react';
+import { useState, useEffect, useCallback } from 'react';
 import { useNavigate } from 'react-router-dom';
+import { AdminAuthError, adminFetch, clearAdminToken, getAdminToken } from '../adminSession';
 import './NPSAdmin.css';
 
+const PERIOD_OPTIONS = [
+  { days: 30, label: 'Últimos 30 dias' },
+  { days: 90, label: 'Últimos 90 dias' },
+  { days: 365, label: 'Último ano' }
+];
+
+const REASON_SEGMENTS = [
+  { value: 'todos', label: 'Todos' },
+  { value: 'promotor', label: 'Promotores' },
+  { value: 'neutro', label: 'Neutros' },
+  { value: 'detrator', label: 'Detratores' }
+];
+
+// Agrupamentos do NPS pelo contexto da conversa em que a pesquisa apareceu
+const CONTEXT_SEGMENTS = [
+  { value: 'promptVersion', label: 'Versão do prompt' },
+  { value: 'model', label: 'Modelo' },
+  { value: 'questionCount', label: 'Perguntas feitas' }
+];
+
+const PAGE_SIZE = 50;
+
+const EXPORT_FORMATS = [
+  { value: 'csv', label: 'CSV' },
+  { value: 'xlsx', label: 'Excel' },
+  { value: 'jsonl', label: 'JSONL' }
+];
+
+const EMPTY_FILTERS = { classification: '', hasFeedback: '', reason: '', status: '', from: '', to: '' };
+
+// statsFrom devolve a data inicial (AAAA-MM-DD) do período, incluindo o dia de hoje
+const statsFrom = (days) => {
+  const date = new Date();
+  date.setDate(date.getDate() - (days - 1));
+  const pad = (value) => String(value).padStart(2, '0');
+  return `${date.getFullYear()}-${pad(date.getMonth() + 1)}-${pad(date.getDate())}`;
+};
+
 const NPSAdmin = () => {
   const navigate = useNavigate();
   const [storedResults, setStoredResults] = useState([]);
   const [resultsLoading, setResultsLoading] = useState(true);
   const [resultsError, setResultsError] = useState('');
+  const [stats, setStats] = useState(null);
+  const [statsDays, setStatsDays] = useState(30);
+  const [reasonSegment, setReasonSegment] = useState('todos');
+  const [contextSegment, setContextSegment] = useState('promptVersion');
+  const [filters, setFilters] = useState(EMPTY_FILTERS);
+  const [nextCursor, setNextCursor] = useState('');
+  const [exporting, setExporting] = useState('');
+  const [exportError, setExportError] = useState('');
 
   // Verifica autenticação
   useEffect(() => {
-    const isAuthenticated = sessionStorage.getItem('nps_admin_authenticated') === 'true';
-    if (!isAuthenticated) {
+    if (!getAdminToken()) {
       navigate('/admin/login');
     }
   }, [navigate]);
@@ -46,138 +92,129 @@ const NPSAdmin = () => {
     }
 
     return {
+      id: typeof entry.id === 'string' ? entry.id : null,
       score,
       classification,
       reasons,
       feedback: trimmedFeedback,
-      submittedAt
+      submittedAt,
+      flagged: entry.status === 'flagged',
+      flagReasons: Array.isArray(entry.flagReasons) ? entry.flagReasons : []
     };
   }, []);
 
-  const fetchResults = useCallback(async () => {
-    setResultsLoading(true);
-    setResultsError('');
-
+  // Os indicadores vêm calculados do servidor para o período escolhido
+  const fetchStats = useCallback(async () => {
     try {
-      const response = await fetch('/api/nps/responses');
+      const response = await adminFetch(
+        `/api/nps/stats?from=${statsFrom(statsDays)}&segment=${contextSegment}`
+      );
       if (!response.ok) {
-        throw new Error('Falha ao buscar respostas NPS');
+        throw new Error('Falha ao buscar estatísticas NPS');
       }
-
-      const data = await response.json();
-      const entries = Array.isArray(data?.responses) ? data.responses : [];
-      const normalized = entries
-        .map((entry) => normalizeResult(entry))
-        .filter((entry) => entry !== null)
-        .sort((a, b) => {
-          if (!a.submittedAt || !b.submittedAt) return 0;
-          return new Date(b.submittedAt).getTime() - new Date(a.submittedAt).getTime();
-        });
-
-      setStoredResults(normalized);
+      setStats(await response.json());
     } catch (error) {
-      setResultsError('Não foi possível carregar as respostas salvas. Tente novamente mais tarde.');
-    } finally {
-      setResultsLoading(false);
+      if (error instanceof AdminAuthError) {
+        navigate('/admin/login');
+        return;
+      }
+      setStats(null);
     }
-  }, [normalizeResult]);
+  }, [contextSegment, navigate, statsDays]);
 
-  useEffect(() => {
-    fetchResults();
-  }, [fetchResults]);
+  // A lista é paginada pelo servidor; cursor vazio recomeça da resposta mais recente
+  const fetchResults = useCallback(
+    async (cursor = '') => {
+      setResultsLoading(true);
+      setResultsError('');
 
-  const aggregatedMetrics = useMemo(() => {
-    if (!storedResults.length) {
-      return {
-        total: 0,
-        promoters: 0,
-        passives: 0,
-        detractors: 0,
-        nps: null,
-        average: null,
-        reasonFrequency: [],
-        entries: []
-      };
-    }
+      const params = new URLSearchParams({ limit: String(PAGE_SIZE) });
+      for (const [key, value] of Object.entries(filters)) {
+        if (value) params.set(key, value);
+      }
+      if (cursor) params.set('cursor', cursor);
 
-    const tally = storedResults.reduce(
-      (accumulator, entry) => {
-        const { score } = entry;
-        if (typeof score !== 'number') {
-          return accumulator;
+      try {
+        const response = await adminFetch(`/api/nps/responses?${params}`);
+        if (!response.ok) {
+          throw new Error('Falha ao buscar respostas NPS');
         }
 
-        accumulator.total += 1;
-        accumulator.sum += score;
+        const data = await response.json();
+        const entries = Array.isArray(data?.responses) ? data.responses : [];
+        const normalized = entries.map((entry) => normalizeResult(entry)).filter((entry) => entry !== null);
 
-        if (score >= 9) {
-          accumulator.promoters += 1;
-        } else if (score <= 6) {
-          accumulator.detractors += 1;
-        } else {
-          accumulator.passives += 1;
+        setStoredResults((previous) => (cursor ? [...previous, ...normalized] : normalized));
+        setNextCursor(data?.nextCursor || '');
+      } catch (error) {
+        if (error instanceof AdminAuthError) {
+          navigate('/admin/login');
+          return;
         }
+        setResultsError('Não foi possível carregar as respostas salvas. Tente novamente mais tarde.');
+      } finally {
+        setResultsLoading(false);
+      }
+    },
+    [filters, normalizeResult, navigate]
+  );
 
-        if (Array.isArray(entry.reasons)) {
-          for (const reason of entry.reasons) {
-            if (typeof reason !== 'string') continue;
-            accumulator.reasonMap.set(reason, (accumulator.reasonMap.get(reason) ?? 0) + 1);
-          }
-        }
+  useEffect(() => {
+    fetchStats();
+  }, [fetchStats]);
 
-        return accumulator;
-      },
-      {
-        total: 0,
-        promoters: 0,
-        passives: 0,
-        detractors: 0,
-        sum: 0,
-        reasonMap: new Map()
-      }
-    );
-
-    if (!tally.total) {
-      return {
-        total: 0,
-        promoters: 0,
-        passives: 0,
-        detractors: 0,
-        nps: null,
-        average: null,
-        reasonFrequency: [],
-        entries: []
-      };
-    }
+  useEffect(() => {
+    fetchResults();
+  }, [fetchResults]);
 
-    const npsScore = Math.round(((tally.promoters - tally.detractors) / tally.total) * 100);
-    const averageScore = Math.round((tally.sum / tally.total) * 10) / 10;
+  const handleRefresh = () => {
+    fetchStats();
+    fetchResults();
+  };
 
-    const reasonFrequency = Array.from(tally.reasonMap.entries())
-      .map(([reason, count]) => ({ reason, count }))
-      .sort((a, b) => b.count - a.count);
+  // Exporta as respostas com os filtros atuais; o servidor registra a exportação na auditoria
+  const handleExport = async (format) => {
+    setExportError('');
+    setExporting(format);
+    const params = new URLSearchParams({ format });
+    for (const [key, value] of Object.entries(filters)) {
+      if (value) params.set(key, value);
+    }
+
+    try {
+      const response = await adminFetch(`/api/nps/export?${params}`);
+      if (!response.ok) {
+        throw new Error('Falha ao exportar respostas NPS');
+      }
+      const blob = await response.blob();
+      const disposition = response.headers.get('Content-Disposition') || '';
+      const filename = disposition.match(/filename="([^"]+)"/)?.[1] || `nps-respostas.${format}`;
+      const url = URL.createObjectURL(blob);
+      const link = document.createElement('a');
+      link.href = url;
+      link.download = filename;
+      link.click();
+      URL.revokeObjectURL(url);
+    } catch (error) {
+      if (error instanceof AdminAuthError) {
+        navigate('/admin/login');
+        return;
+      }
+      setExportError('Não foi possível exportar as respostas. Tente novamente mais tarde.');
+    } finally {
+      setExporting('');
+    }
+  };
 
-    const sortedEntries = [...storedResults].sort((a, b) => {
-      if (!a.submittedAt && !b.submittedAt) return 0;
-      if (!a.submittedAt) return 1;
-      if (!b.submittedAt) return -1;
-      return new Date(b.submittedAt).getTime() - new Date(a.submittedAt).getTime();
-    });
+  const updateFilter = (key, value) => {
+    setFilters((previous) => ({ ...previous, [key]: value }));
+  };
 
-    return {
-      total: tally.total,
-      promoters: tally.promoters,
-      passives: tally.passives,
-      detractors: tally.detractors,
-      nps: npsScore,
-      average: averageScore,
-      reasonFrequency,
-      entries: sortedEntries
-    };
-  }, [storedResults]);
+  const reasonFrequency = stats?.topReasons?.[reasonSegment] ?? [];
+  const histogramMax = Math.max(1, ...(stats?.histogram ?? []));
 
   const handleLogout = () => {
-    sessionStorage.removeItem('nps_admin_authenticated');
+    clearAdminToken();
     navigate('/admin/login');
   };
 
@@ -190,7 +227,7 @@ const NPSAdmin = () => {
             <p>Resultados agregados da pesquisa de satisfação</p>
           </div>
           <div className="nps-admin-actions">
-            <button type="button" className="nps-admin-refresh" onClick={fetchResults}>
+            <button type="button" className="nps-admin-refresh" onClick={handleRefresh}>
               Atualizar
             </button>
             <button type="button" className="nps-admin-logout" onClick={handleLogout}>
@@ -203,40 +240,87 @@ const NPSAdmin = () => {
         </header>
 
         <div className="nps-admin-content">
+          <div className="nps-admin-period">
+            <label htmlFor="nps-admin-period">Período</label>
+            <select
+              id="nps-admin-period"
+              value={statsDays}
+              onChange={(event) => setStatsDays(Number(event.target.value))}
+            >
+              {PERIOD_OPTIONS.map((option) => (
+                <option key={option.days} value={option.days}>
+                  {option.label}
+                </option>
+              ))}
+            </select>
+          </div>
+
           <div className="nps-admin-summary">
             <div className="nps-admin-tile">
               <span>Total de respostas</span>
-              <strong>{aggregatedMetrics.total}</strong>
+              <strong>{stats?.total ?? 0}</strong>
+              {stats?.flagged > 0 && <small>{stats.flagged} suspeitas fora das contagens</small>}
             </div>
             <div className="nps-admin-tile">
               <span>NPS consolidado</span>
-              <strong>{aggregatedMetrics.nps !== null ? `${aggregatedMetrics.nps}` : '—'}</strong>
+              <strong>{stats?.nps != null ? `${Math.round(stats.nps)}` : '—'}</strong>
             </div>
             <div className="nps-admin-tile">
               <span>Média das notas</span>
-              <strong>{aggregatedMetrics.average !== null ? aggregatedMetrics.average.toFixed(1) : '—'}</strong>
+              <strong>{stats?.average != null ? stats.average.toFixed(1) : '—'}</strong>
             </div>
             <div className="nps-admin-tile trio">
               <div>
                 <span>Promotores</span>
-                <strong>{aggregatedMetrics.promoters}</strong>
+                <strong>{stats?.promoters ?? 0}</strong>
               </div>
               <div>
                 <span>Neutros</span>
-                <strong>{aggregatedMetrics.passives}</strong>
+                <strong>{stats?.passives ?? 0}</strong>
               </div>
               <div>
                 <span>Detratores</span>
-                <strong>{aggregatedMetrics.detractors}</strong>
+                <strong>{stats?.detractors ?? 0}</strong>
               </div>
             </div>
           </div>
 
+          <div className="nps-admin-section">
+            <h4>Distribuição das notas</h4>
+            {stats?.total ? (
+              <ul className="nps-admin-histogram">
+                {stats.histogram.map((count, score) => (
+                  <li key={score}>
+                    <span>{score}</span>
+                    <div className="nps-admin-histogram-bar">
+                      <div style={{ width: `${(count / histogramMax) * 100}%` }} />
+                    </div>
+                    <strong>{count}</strong>
+                  </li>
+                ))}
+              </ul>
+            ) : (
+              <p className="nps-admin-empty">Nenhuma resposta no período selecionado.</p>
+            )}
+          </div>
+
           <div className="nps-admin-section">
             <h4>Motivos mais citados</h4>
-            {aggregatedMetrics.reasonFrequency.length ? (
+            <div className="nps-admin-segments">
+              {REASON_SEGMENTS.map((segment) => (
+                <button
+                  key={segment.value}
+                  type="button"
+                  className={segment.value === reasonSegment ? 'active' : ''}
+                  onClick={() => setReasonSegment(segment.value)}
+                >
+                  {segment.label}
+                </button>
+              ))}
+            </div>
+            {reasonFrequency.length ? (
               <ul className="nps-admin-reasons">
-                {aggregatedMetrics.reasonFrequency.map((item) => (
+                {reasonFrequency.map((item) => (
                   <li key={item.reason}>
                     <span>{item.reason}</span>
                     <strong>{item.count}</strong>
@@ -248,15 +332,113 @@ const NPSAdmin = () => {
             )}
           </div>
 
+          <div className="nps-admin-section">
+            <h4>NPS por contexto da conversa</h4>
+            <div className="nps-admin-segments">
+              {CONTEXT_SEGMENTS.map((segment) => (
+                <button
+                  key={segment.value}
+                  type="button"
+                  className={segment.value === contextSegment ? 'active' : ''}
+                  onClick={() => setContextSegment(segment.value)}
+                >
+                  {segment.label}
+                </button>
+              ))}
+            </div>
+            {stats?.segments?.length ? (
+              <ul className="nps-admin-reasons">
+                {stats.segments.map((segment) => (
+                  <li key={segment.value}>
+                    <span>
+                      {segment.value} · {segment.total} {segment.total === 1 ? 'resposta' : 'respostas'}
+                    </span>
+                    <strong>{segment.nps != null ? `NPS ${Math.round(segment.nps)}` : '—'}</strong>
+                  </li>
+                ))}
+              </ul>
+            ) : (
+              <p className="nps-admin-empty">Nenhuma resposta no período selecionado.</p>
+            )}
+          </div>
+
           <div className="nps-admin-section">
             <h4>Respostas registradas</h4>
-            {resultsLoading ? (
+            <div className="nps-admin-filters">
+              <select
+                aria-label="Classificação"
+                value={filters.classification}
+                onChange={(event) => updateFilter('classification', event.target.value)}
+              >
+                <option value="">Todas as classificações</option>
+                <option value="promotor">Promotores</option>
+                <option value="neutro">Neutros</option>
+                <option value="detrator">Detratores</option>
+              </select>
+              <select
+                aria-label="Comentário"
+                value={filters.hasFeedback}
+                onChange={(event) => updateFilter('hasFeedback', event.target.value)}
+              >
+                <option value="">Com ou sem comentário</option>
+                <option value="true">Com comentário</option>
+                <option value="false">Sem comentário</option>
+              </select>
+              <select
+                aria-label="Situação"
+                value={filters.status}
+                onChange={(event) => updateFilter('status', event.target.value)}
+              >
+                <option value="">Todas as respostas</option>
+                <option value="ok">Válidas</option>
+                <option value="flagged">Suspeitas</option>
+              </select>
+              <select
+                aria-label="Motivo"
+                value={filters.reason}
+                onChange={(event) => updateFilter('reason', event.target.value)}
+              >
+                <option value="">Todos os motivos</option>
+                {(stats?.topReasons?.todos ?? []).map((item) => (
+                  <option key={item.reason} value={item.reason}>
+                    {item.reason}
+                  </option>
+                ))}
+              </select>
+              <input
+                type="date"
+                aria-label="De"
+                value={filters.from}
+                onChange={(event) => updateFilter('from', event.target.value)}
+              />
+              <input
+                type="date"
+                aria-label="Até"
+                value={filters.to}
+                onChange={(event) => updateFilter('to', event.target.value)}
+              />
+            </div>
+            <div className="nps-admin-export">
+              <span>Exportar respostas filtradas:</span>
+              {EXPORT_FORMATS.map((format) => (
+                <button
+                  key={format.value}
+                  type="button"
+                  onClick={() => handleExport(format.value)}
+                  disabled={Boolean(exporting)}
+                >
+                  {exporting === format.value ? 'Exportando…' : format.label}
+                </button>
+              ))}
+            </div>
+            {exportError && <p className="nps-admin-empty">{exportError}</p>}
+            {resultsLoading && !storedResults.length ? (
               <p className="nps-admin-empty">Carregando respostas salvas…</p>
             ) : resultsError ? (
               <p className="nps-admin-empty">{resultsError}</p>
-            ) : aggregatedMetrics.entries.length ? (
+            ) : storedResults.length ? (
               <ul className="nps-admin-list">
-                {aggregatedMetrics.entries.map((entry, index) => {
+                {storedResults.map((entry, index) => {
                   const formatted = entry.submittedAt
                     ? new Intl.DateTimeFormat('pt-BR', {
                         day: '2-digit',
@@ -266,10 +448,15 @@ const NPSAdmin = () => {
                       }).format(new Date(entry.submittedAt))
                     : 'Data indisponível';
                   return (
-                    <li key={`${entry.submittedAt ?? 'sem-data'}-${index}`}>
+                    <li key={entry.id ?? `${entry.submittedAt ?? 'sem-data'}-${index}`}>
                       <div className="nps-admin-list-header">
                         <span className={`tag ${entry.classification ?? 'indefinido'}`}>{entry.classification ?? '—'}</span>
                         <strong>Nota {entry.score}</strong>
+                        {entry.flagged && (
+                          <span className="tag suspeita" title={entry.flagReasons.join(', ')}>
+                            suspeita
+                          </span>
+                        )}
                         <span>{formatted}</span>
                       </div>
                       {entry.reasons?.length > 0 && (
@@ -281,7 +468,17 @@ const NPSAdmin = () => {
                 })}
               </ul>
             ) : (
-              <p className="nps-admin-empty">Nenhuma resposta registrada até o momento.</p>
+              <p className="nps-admin-empty">Nenhuma resposta encontrada com esses filtros.</p>
+            )}
+            {nextCursor && !resultsError && (
+              <button
+                type="button"
+                className="nps-admin-more"
+                onClick={() => fetchResults(nextCursor)}
+                disabled={resultsLoading}
+              >
+                {resultsLoading ? 'Carregando…' : 'Carregar mais respostas'}
+              </button>
             )}
           </div>
 
@@ -67,6 +67,25 @@ const placeholderByClassification = {
   promotor: 'Compartilhe histórias de uso ou resultados que possamos amplificar.'
 };
 
+const NPS_CLIENT_ID_KEY = 'agoraai-client-id';
+
+// getClientId devolve o identificador aleatório deste navegador; o servidor o
+// usa (com hash) para limitar envios repetidos
+const getClientId = () => {
+  try {
+    let clientId = localStorage.getItem(NPS_CLIENT_ID_KEY);
+    if (!clientId) {
+      clientId = typeof crypto !== 'undefined' && crypto.randomUUID
+        ? crypto.randomUUID()
+        : `${Date.now().toString(36)}-${Math.random().toString(36).slice(2)}`;
+      localStorage.setItem(NPS_CLIENT_ID_KEY, clientId);
+    }
+    return clientId;
+  } catch (error) {
+    return '';
+  }
+};
+
 const scheduleLocalStorageWrite = (key, value) => {
   if (typeof window === 'undefined') {
     return () => {};
@@ -99,7 +118,7 @@ const scheduleLocalStorageWrite = (key, value) => {
   return () => window.clearTimeout(timeoutHandle);
 };
 
-const NPSSurvey = () => {
+const NPSSurvey = ({ context = null }) => {
   const [hydrated, setHydrated] = useState(false);
   const [selectedScore, setSelectedScore] = useState(null);
   const [selectedReasons, setSelectedReasons] = useState([]);
@@ -111,6 +130,23 @@ const NPSSurvey = () => {
   const [copied, setCopied] = useState(false);
   const [copyError, setCopyError] = useState('');
   const [isSubmitting, setIsSubmitting] = useState(false);
+  const [surveyToken, setSurveyToken] = useState('');
+
+  // Pede ao servidor o token assinado da pesquisa assim que ela é exibida
+  useEffect(() => {
+    let cancelled = false;
+    fetch('/api/nps/token', { headers: { 'X-Client-ID': getClientId() } })
+      .then((response) => (response.ok ? response.json() : null))
+      .then((data) => {
+        if (!cancelled && data?.token) {
+          setSurveyToken(data.token);
+        }
+      })
+      .catch(() => {});
+    return () => {
+      cancelled = true;
+    };
+  }, []);
 
   useEffect(() => {
     const loadStoredSurvey = () => {
@@ -305,7 +341,13 @@ const NPSSurvey = () => {
       classification,
       reasons: selectedReasons,
       feedback: feedback.trim(),
-      submittedAt: timestamp
+      submittedAt: timestamp,
+      ...(surveyToken ? { surveyToken } : {}),
+      ...(context?.sessionId ? { sessionId: context.sessionId } : {}),
+      ...(context?.conversationId ? { conversationId: context.conversationId } : {}),
+      ...(context?.model ? { model: context.model } : {}),
+      ...(context?.promptVersion ? { promptVersion: context.promptVersion } : {}),
+      ...(context?.questionCount ? { questionCount: context.questionCount } : {})
     };
 
     setIsSubmitting(true);
@@ -314,11 +356,16 @@ const NPSSurvey = () => {
       const response = await fetch('/api/nps/responses', {
         method: 'POST',
         headers: {
-          'Content-Type': 'application/json'
+          'Content-Type': 'application/json',
+          'X-Client-ID': getClientId()
         },
         body: JSON.stringify(payload)
       });
 
+      if (response.status === 429) {
+        setError('Recebemos muitas respostas deste dispositivo. Tente novamente mais tarde.');
+        return;
+      }
       if (!response.ok) {
         throw new Error('Falha ao registrar resposta');
       }
@@ -1,104 +1,10 @@
-import { useMemo, useState } from 'react';
+import { useEffect, useMemo, useState } from 'react';
 import './VotingDashboard.css';
 
-const votesDataset = [
-  {
-    id: 'plp-68-2024-camara',
-    title: 'Regulamentação do IBS e CBS (PLP 68/2024)',
-    chamber: 'Câmara',
-    topic: 'Reforma Tributária',
-    governmentAlignment: 0.74,
-    support: 336,
-    against: 142,
-    abstention: 2,
-    stage: 'Aprovado',
-    date: '2024-07-10',
-    highlight: 'Texto-base do IVA dual aprovado; proposta segue para análise do Senado.',
-    source: 'Câmara dos Deputados',
-    sourceUrl: 'https://www.camara.leg.br/noticias/1085428-camara-aprova-regulamentacao-do-ibs-e-da-cbs/'
-  },
-  {
-    id: 'plp-108-2024-camara',
-    title: 'Fundo de Desenvolvimento Regional (PLP 108/2024)',
-    chamber: 'Câmara',
-    topic: 'Reforma Tributária',
-    governmentAlignment: 0.78,
-    support: 382,
-    against: 118,
-    abstention: 3,
-    stage: 'Aprovado',
-    date: '2024-07-11',
-    highlight: 'Complementa a reforma com cashback do IBS para famílias de baixa renda.',
-    source: 'Câmara dos Deputados',
-    sourceUrl: 'https://www.camara.leg.br/noticias/1085667-camara-aprova-fundo-de-desenvolvimento-regional-da-reforma-tributaria/'
-  },
-  {
-    id: 'plp-68-2024-senado',
-    title: 'Regulamentação do IBS (PLP 68/2024) – Senado',
-    chamber: 'Senado',
-    topic: 'Reforma Tributária',
-    governmentAlignment: 0.69,
-    support: 65,
-    against: 13,
-    abstention: 0,
-    stage: 'Aprovado',
-    date: '2024-09-11',
-    highlight: 'Senado mantém texto-base e envia projeto de volta à Câmara.',
-    source: 'Senado Federal',
-    sourceUrl: 'https://www12.senado.leg.br/noticias/materias/2024/09/11/senado-aprova-plp-68-2024' 
-  },
-  {
-    id: 'veto-38-2023-camara',
-    title: 'Veto 38/2023 – Desoneração da folha',
-    chamber: 'Câmara',
-    topic: 'Economia',
-    governmentAlignment: 0.32,
-    support: 378,
-    against: 78,
-    abstention: 1,
-    stage: 'Veto derrubado',
-    date: '2024-05-28',
-    highlight: 'Deputados derrubam veto e restabelecem desoneração para 17 setores.',
-    source: 'Câmara dos Deputados',
-    sourceUrl: 'https://www.camara.leg.br/noticias/1082567-congresso-derruba-veto-a-desoneracao-da-folha/'
-  },
-  {
-    id: 'veto-38-2023-senado',
-    title: 'Veto 38/2023 – Desoneração da folha (Senado)',
-    chamber: 'Senado',
-    topic: 'Economia',
-    governmentAlignment: 0.35,
-    support: 60,
-    against: 13,
-    abstention: 0,
-    stage: 'Veto derrubado',
-    date: '2024-05-28',
-    highlight: 'Senadores confirmam derrubada do veto e texto é promulgado pelo Congresso.',
-    source: 'Senado Federal',
-    sourceUrl: 'https://www12.senado.leg.br/noticias/materias/2024/05/28/congresso-derruba-veto-e-restabelece-desoneracao-da-folha' 
-  },
-  {
-    id: 'pl-2338-2023-senado',
-    title: 'Marco Legal da Inteligência Artificial (PL 2338/2023)',
-    chamber: 'Senado',
-    topic: 'Inovação',
-    governmentAlignment: 0.81,
-    support: 49,
-    against: 8,
-    abstention: 3,
-    stage: 'Aprovado',
-    date: '2024-04-23',
-    highlight: 'Marco de IA define obrigações para sistemas de alto risco e segue para a Câmara.',
-    source: 'Senado Federal',
-    sourceUrl: 'https://www12.senado.leg.br/noticias/materias/2024/04/23/senado-aprova-marco-legal-da-inteligencia-artificial' 
-  }
-];
-
 const chambers = [
   { id: 'todos', label: 'Todas as casas' },
   { id: 'Câmara', label: 'Câmara' },
-  { id: 'Senado', label: 'Senado' },
-  { id: 'Congresso', label: 'Sessões conjuntas' }
+  { id: 'Senado', label: 'Senado' }
 ];
 
 const formatDate = (dateStr) => {
@@ -112,11 +18,44 @@ const formatDate = (dateStr) => {
 
 const VotingDashboard = () => {
   const [selectedChamber, setSelectedChamber] = useState('todos');
+  const [votesDataset, setVotesDataset] = useState([]);
+  const [loading, setLoading] = useState(true);
+  const [loadError, setLoadError] = useState('');
+
+  useEffect(() => {
+    let cancelled = false;
+
+    const fetchVotes = async () => {
+      try {
+        const response = await fetch('/api/votes?limit=200');
+        if (!response.ok) {
+          throw new Error('Falha ao buscar votações');
+        }
+        const data = await response.json();
+        if (!cancelled) {
+          setVotesDataset(Array.isArray(data?.votes) ? data.votes : []);
+        }
+      } catch (error) {
+        if (!cancelled) {
+          setLoadError('Não foi possível carregar as votações. Tente novamente mais tarde.');
+        }
+      } finally {
+        if (!cancelled) {
+          setLoading(false);
+        }
+      }
+    };
+
+    fetchVotes();
+    return () => {
+      cancelled = true;
+    };
+  }, []);
 
   const topicOptions = useMemo(() => {
     const uniqueTopics = Array.from(new Set(votesDataset.map((vote) => vote.topic))).sort();
     return [{ id: 'todos', label: 'Todos os temas' }, ...uniqueTopics.map((topic) => ({ id: topic, label: topic }))];
-  }, []);
+  }, [votesDataset]);
 
   const [selectedTopic, setSelectedTopic] = useState('todos');
 
@@ -126,12 +65,16 @@ const VotingDashboard = () => {
       const matchesTopic = selectedTopic === 'todos' || vote.topic === selectedTopic;
       return matchesChamber && matchesTopic;
     });
-  }, [selectedChamber, selectedTopic]);
+  }, [votesDataset, selectedChamber, selectedTopic]);
 
   const summary = useMemo(() => {
     const totalVotes = filteredVotes.length;
     const approved = filteredVotes.filter((vote) => vote.stage === 'Aprovado' || vote.stage === 'Sancionado').length;
-    const avgAlignment = filteredVotes.reduce((acc, vote) => acc + vote.governmentAlignment, 0) / (totalVotes || 1);
+    // Votações sem orientação do governo não entram na média de alinhamento
+    const aligned = filteredVotes.filter((vote) => typeof vote.governmentAlignment === 'number');
+    const avgAlignment = aligned.length
+      ? aligned.reduce((acc, vote) => acc + vote.governmentAlignment, 0) / aligned.length
+      : null;
 
     return {
       totalVotes,
@@ -222,7 +165,9 @@ const VotingDashboard = () => {
         </div>
         <div className="summary-card" role="listitem">
           <span className="summary-label">Alinhamento médio</span>
-          <strong className="summary-value">{Math.round(summary.avgAlignment * 100)}%</strong>
+          <strong className="summary-value">
+            {summary.avgAlignment === null ? '—' : `${Math.round(summary.avgAlignment * 100)}%`}
+          </strong>
           <span className="summary-detail">Base governista x oposição</span>
         </div>
       </div>
@@ -247,15 +192,18 @@ const VotingDashboard = () => {
       </div>
 
       <ul className="vote-list">
-        {filteredVotes.length === 0 && (
+        {loading && <li className="vote-empty">Carregando votações...</li>}
+        {!loading && loadError && <li className="vote-empty">{loadError}</li>}
+        {!loading && !loadError && filteredVotes.length === 0 && (
           <li className="vote-empty">Nenhuma votação encontrada para os filtros selecionados.</li>
         )}
         {filteredVotes.map((vote) => {
-          const total = vote.support + vote.against + vote.abstention;
+          const total = vote.support + vote.against + vote.abstention || 1;
           const supportPercent = Math.round((vote.support / total) * 100);
           const againstPercent = Math.round((vote.against / total) * 100);
-          const abstentionPercent = 100 - supportPercent - againstPercent;
-          const alignmentPercent = Math.round(vote.governmentAlignment * 100);
+          const abstentionPercent = vote.support + vote.against + vote.abstention ? 100 - supportPercent - againstPercent : 0;
+          const hasAlignment = typeof vote.governmentAlignment === 'number';
+          const alignmentPercent = hasAlignment ? Math.round(vote.governmentAlignment * 100) : 0;
 
           return (
             <li key={vote.id} className="vote-item">
@@ -292,13 +240,15 @@ const VotingDashboard = () => {
                 <span>Abstenções {abstentionPercent}%</span>
               </div>
 
-              <div className="vote-alignment">
-                <span className="metric-label">Alinhamento do governo</span>
-                <div className="alignment-track" role="img" aria-label={`Alinhamento do governo: ${alignmentPercent}%`}>
-                  <span className="alignment-fill" style={{ width: `${alignmentPercent}%` }} />
+              {hasAlignment && (
+                <div className="vote-alignment">
+                  <span className="metric-label">Alinhamento do governo</span>
+                  <div className="alignment-track" role="img" aria-label={`Alinhamento do governo: ${alignmentPercent}%`}>
+                    <span className="alignment-fill" style={{ width: `${alignmentPercent}%` }} />
+                  </div>
+                  <strong>{alignmentPercent}%</strong>
                 </div>
-                <strong>{alignmentPercent}%</strong>
-              </div>
+              )}
             </li>
           );
         })}
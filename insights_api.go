@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"chat-bot/internal/insights"
+)
+
+const (
+	insightsFilePath       = "data/insights.json"
+	transparencyWindowDays = 30
+	// insightsFlushInterval é a frequência com que as contagens de respostas
+	// são gravadas; o que ficar pendente é gravado no encerramento do servidor
+	insightsFlushInterval = 30 * time.Second
+
+	// Coleção do Firestore com os totais diários de todas as instâncias, lida
+	// a cada insightsFollowInterval
+	insightsCollection     = "insights_dias"
+	insightsFollowInterval = 5 * time.Minute
+)
+
+var insightsStore *insights.Store
+
+// activeAlertCount informa o número de alertas ativos das listas de acompanhamento.
+// Fica nil enquanto não houver listas configuradas.
+var activeAlertCount func() int
+
+// recordChatAnswer contabiliza a resposta para o índice de transparência
+func recordChatAnswer(reply string) {
+	if insightsStore == nil {
+		return
+	}
+	insightsStore.RecordAnswer(time.Now(), insights.HasOfficialCitation(reply))
+}
+
+// InsightMetric é uma métrica do painel com a variação em relação à semana anterior
+type InsightMetric struct {
+	Value     float64  `json:"value"`
+	Previous  *float64 `json:"previous,omitempty"`
+	Delta     *float64 `json:"delta,omitempty"`
+	Available bool     `json:"available"`
+}
+
+type TransparencyMetric struct {
+	InsightMetric
+	Answers int `json:"answers"`
+	Cited   int `json:"cited"`
+}
+
+type InsightsSummary struct {
+	BillsTracked      InsightMetric      `json:"billsTracked"`
+	TransparencyIndex TransparencyMetric `json:"transparencyIndex"`
+	ActiveAlerts      InsightMetric      `json:"activeAlerts"`
+	UpdatedAt         *time.Time         `json:"updatedAt,omitempty"`
+	GeneratedAt       time.Time          `json:"generatedAt"`
+}
+
+func newMetric(value float64, previous *float64) InsightMetric {
+	metric := InsightMetric{Value: value, Previous: previous, Available: true}
+	if previous != nil {
+		delta := math.Round((value-*previous)*1000) / 1000
+		metric.Delta = &delta
+	}
+	return metric
+}
+
+// transparencyIndex calcula a parcela de respostas com fonte oficial nos 30 dias até a data
+func transparencyIndex(until time.Time) (insights.AnswerCounts, *float64) {
+	totals := insightsStore.AnswerTotals(until.AddDate(0, 0, -(transparencyWindowDays-1)), until)
+	if totals.Answers == 0 {
+		return totals, nil
+	}
+	index := math.Round(float64(totals.Cited)/float64(totals.Answers)*1000) / 1000
+	return totals, &index
+}
+
+// buildInsightsSummary compara os valores atuais com os de sete dias atrás
+func buildInsightsSummary(now time.Time) InsightsSummary {
+	weekAgo := now.AddDate(0, 0, -7)
+	summary := InsightsSummary{GeneratedAt: now.UTC()}
+
+	// Proposições acompanhadas: as da sincronização periódica e as seguidas por listas
+	if proposicaoStore != nil {
+		var previous *float64
+		if snapshot, ok := insightsStore.SnapshotOn(weekAgo); ok && snapshot.BillsTracked != nil {
+			value := float64(*snapshot.BillsTracked)
+			previous = &value
+		}
+		summary.BillsTracked = newMetric(float64(proposicaoStore.Size()), previous)
+	}
+
+	totals, index := transparencyIndex(now)
+	summary.TransparencyIndex.Answers = totals.Answers
+	summary.TransparencyIndex.Cited = totals.Cited
+	if index != nil {
+		_, previous := transparencyIndex(weekAgo)
+		summary.TransparencyIndex.InsightMetric = newMetric(*index, previous)
+	}
+
+	if activeAlertCount != nil {
+		var previous *float64
+		if snapshot, ok := insightsStore.SnapshotOn(weekAgo); ok && snapshot.ActiveAlerts != nil {
+			value := float64(*snapshot.ActiveAlerts)
+			previous = &value
+		}
+		summary.ActiveAlerts = newMetric(float64(activeAlertCount()), previous)
+	}
+
+	last := voteStore.LastIngest()
+	if proposicaoStore != nil && proposicaoStore.LastListSync().After(last) {
+		last = proposicaoStore.LastListSync()
+	}
+	if !last.IsZero() {
+		summary.UpdatedAt = &last
+	}
+	return summary
+}
+
+// saveInsightsSnapshot grava os valores do dia para o cálculo das variações semanais
+func saveInsightsSnapshot() {
+	summary := buildInsightsSummary(time.Now())
+	var snapshot insights.Snapshot
+	if summary.BillsTracked.Available {
+		value := int(summary.BillsTracked.Value)
+		snapshot.BillsTracked = &value
+	}
+	if summary.TransparencyIndex.Available {
+		value := summary.TransparencyIndex.Value
+		snapshot.TransparencyIndex = &value
+	}
+	if summary.ActiveAlerts.Available {
+		value := int(summary.ActiveAlerts.Value)
+		snapshot.ActiveAlerts = &value
+	}
+	if err := insightsStore.SaveSnapshot(time.Now(), snapshot); err != nil {
+		log.Printf("⚠️  Erro ao salvar instantâneo das métricas: %v", err)
+	}
+}
+
+func handleInsightsSummary(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(buildInsightsSummary(time.Now()))
+}
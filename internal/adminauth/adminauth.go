@@ -0,0 +1,123 @@
+// Package adminauth autentica os usuários pelo token de ID do Firebase ou, em
+// desenvolvimento local, por tokens de teste assinados com um segredo
+// compartilhado; as rotas administrativas exigem a claim de administrador.
+package adminauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"firebase.google.com/go/v4/auth"
+)
+
+// DefaultClaim é a custom claim que marca um usuário do Firebase como administrador
+const DefaultClaim = "admin"
+
+var (
+	// ErrMissingToken indica que a requisição não trouxe o cabeçalho Authorization
+	ErrMissingToken = errors.New("token de acesso ausente")
+	// ErrInvalidToken indica token malformado, expirado ou com assinatura inválida
+	ErrInvalidToken = errors.New("token de acesso inválido ou expirado")
+	// ErrForbidden indica um usuário autenticado sem a permissão de administrador
+	ErrForbidden = errors.New("usuário sem permissão de administrador")
+)
+
+// Identity é o usuário autenticado
+type Identity struct {
+	UID   string `json:"uid"`
+	Email string `json:"email,omitempty"`
+	Admin bool   `json:"admin"`
+	// Source é "firebase" ou "dev"
+	Source string `json:"source"`
+}
+
+// Authenticator verifica tokens do Firebase e, se configurado, tokens de teste
+type Authenticator struct {
+	Firebase *auth.Client
+	// Claim é a custom claim de administrador (padrão DefaultClaim)
+	Claim string
+	// DevSecret habilita os tokens de teste; deixe vazio em produção
+	DevSecret []byte
+}
+
+// Enabled informa se há alguma forma de autenticação configurada
+func (a *Authenticator) Enabled() bool {
+	return a != nil && (a.Firebase != nil || len(a.DevSecret) > 0)
+}
+
+func (a *Authenticator) claim() string {
+	if a.Claim != "" {
+		return a.Claim
+	}
+	return DefaultClaim
+}
+
+// BearerToken lê o token do cabeçalho "Authorization: Bearer <token>"
+func BearerToken(r *http.Request) string {
+	scheme, token, found := strings.Cut(strings.TrimSpace(r.Header.Get("Authorization")), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// Verify valida o token e devolve a identidade. Usuários sem a claim de
+// administrador são devolvidos junto com ErrForbidden.
+func (a *Authenticator) Verify(ctx context.Context, token string) (Identity, error) {
+	identity, err := a.VerifyUser(ctx, token)
+	if err != nil {
+		return Identity{}, err
+	}
+	if !identity.Admin {
+		return identity, ErrForbidden
+	}
+	return identity, nil
+}
+
+// VerifyUser valida o token de qualquer usuário autenticado, administrador ou não
+func (a *Authenticator) VerifyUser(ctx context.Context, token string) (Identity, error) {
+	if token == "" {
+		return Identity{}, ErrMissingToken
+	}
+
+	switch {
+	case strings.HasPrefix(token, devTokenPrefix) && len(a.DevSecret) > 0:
+		return verifyDevToken(a.DevSecret, token)
+	case a.Firebase != nil:
+		verified, err := a.Firebase.VerifyIDTokenAndCheckRevoked(ctx, token)
+		if err != nil {
+			return Identity{}, ErrInvalidToken
+		}
+		identity := Identity{UID: verified.UID, Admin: hasClaim(verified.Claims, a.claim()), Source: "firebase"}
+		if email, ok := verified.Claims["email"].(string); ok {
+			identity.Email = email
+		}
+		return identity, nil
+	default:
+		return Identity{}, ErrInvalidToken
+	}
+}
+
+// hasClaim aceita {"admin": true} ou {"role": "admin"}
+func hasClaim(claims map[string]interface{}, claim string) bool {
+	if value, ok := claims[claim].(bool); ok && value {
+		return true
+	}
+	role, _ := claims["role"].(string)
+	return role == claim
+}
+
+type contextKey struct{}
+
+// WithIdentity guarda a identidade no contexto da requisição
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, contextKey{}, identity)
+}
+
+// FromContext devolve a identidade guardada pelo middleware de administração
+func FromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(contextKey{}).(Identity)
+	return identity, ok
+}
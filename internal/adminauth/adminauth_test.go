@@ -0,0 +1,118 @@
+package adminauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDevTokens(t *testing.T) {
+	secret := []byte("segredo-local")
+	auth := &Authenticator{DevSecret: secret}
+
+	admin, err := SignDevToken(secret, Identity{Email: "admin@exemplo.com", Admin: true}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, _ := SignDevToken(secret, Identity{UID: "uid-1"}, time.Hour)
+	expired, _ := SignDevToken(secret, Identity{UID: "uid-1", Admin: true}, -time.Minute)
+	otherSecret, _ := SignDevToken([]byte("outro"), Identity{UID: "uid-1", Admin: true}, time.Hour)
+	encoded, signature, _ := strings.Cut(strings.TrimPrefix(user, devTokenPrefix), ".")
+	tampered := devTokenPrefix + encoded + "x." + signature
+
+	tests := []struct {
+		name       string
+		token      string
+		verify     error
+		verifyUser error
+		uid        string
+	}{
+		{"administrador", admin, nil, nil, "admin@exemplo.com"},
+		{"usuário comum", user, ErrForbidden, nil, "uid-1"},
+		{"ausente", "", ErrMissingToken, ErrMissingToken, ""},
+		{"expirado", expired, ErrInvalidToken, ErrInvalidToken, ""},
+		{"outro segredo", otherSecret, ErrInvalidToken, ErrInvalidToken, ""},
+		{"dados alterados", tampered, ErrInvalidToken, ErrInvalidToken, ""},
+		{"sem assinatura", devTokenPrefix + encoded, ErrInvalidToken, ErrInvalidToken, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := auth.VerifyUser(context.Background(), tt.token)
+			if !errors.Is(err, tt.verifyUser) {
+				t.Fatalf("VerifyUser: esperava %v, obteve %v", tt.verifyUser, err)
+			}
+			if identity.UID != tt.uid || (err == nil && identity.Source != "dev") {
+				t.Errorf("VerifyUser: identidade inesperada %+v", identity)
+			}
+			if _, err := auth.Verify(context.Background(), tt.token); !errors.Is(err, tt.verify) {
+				t.Errorf("Verify: esperava %v, obteve %v", tt.verify, err)
+			}
+		})
+	}
+
+	// Sem segredo configurado, nem os tokens de teste válidos são aceitos
+	if _, err := (&Authenticator{}).VerifyUser(context.Background(), admin); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("token de teste aceito sem segredo: %v", err)
+	}
+	if _, err := SignDevToken(nil, Identity{UID: "uid-1"}, time.Hour); err == nil {
+		t.Errorf("esperava erro ao assinar sem segredo")
+	}
+}
+
+func TestEnableDevTokens(t *testing.T) {
+	t.Setenv("K_SERVICE", "")
+	auth := &Authenticator{}
+	if err := auth.EnableDevTokens("segredo-local"); err != nil || !auth.Enabled() {
+		t.Fatalf("tokens de teste deveriam ser aceitos localmente: %v", err)
+	}
+	if err := (&Authenticator{}).EnableDevTokens(""); err == nil {
+		t.Errorf("esperava erro com o segredo vazio")
+	}
+
+	t.Setenv("K_SERVICE", "chat-bot")
+	auth = &Authenticator{}
+	if err := auth.EnableDevTokens("segredo-local"); !errors.Is(err, ErrDevTokensOnCloudRun) {
+		t.Fatalf("esperava ErrDevTokensOnCloudRun, obteve %v", err)
+	}
+	if auth.Enabled() || len(auth.DevSecret) > 0 {
+		t.Errorf("o segredo não deveria ser guardado no Cloud Run")
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	tests := map[string]string{
+		"Bearer abc":   "abc",
+		"bearer  abc ": "abc",
+		"Basic abc":    "",
+		"abc":          "",
+		"":             "",
+	}
+	for header, want := range tests {
+		r, _ := http.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", header)
+		if got := BearerToken(r); got != want {
+			t.Errorf("BearerToken(%q) = %q, esperava %q", header, got, want)
+		}
+	}
+}
+
+func TestHasClaim(t *testing.T) {
+	tests := []struct {
+		claims map[string]interface{}
+		want   bool
+	}{
+		{map[string]interface{}{"admin": true}, true},
+		{map[string]interface{}{"role": "admin"}, true},
+		{map[string]interface{}{"admin": false}, false},
+		{map[string]interface{}{"admin": "true"}, false},
+		{map[string]interface{}{"role": "editor"}, false},
+	}
+	for _, tt := range tests {
+		if got := hasClaim(tt.claims, DefaultClaim); got != tt.want {
+			t.Errorf("hasClaim(%v) = %v, esperava %v", tt.claims, got, tt.want)
+		}
+	}
+}
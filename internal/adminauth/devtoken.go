@@ -0,0 +1,87 @@
+package adminauth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"time"
+)
+
+// devTokenPrefix distingue os tokens de teste dos tokens do Firebase
+const devTokenPrefix = "dev."
+
+// ErrDevTokensOnCloudRun indica a tentativa de aceitar tokens de teste no Cloud Run
+var ErrDevTokensOnCloudRun = errors.New("tokens de teste só são aceitos localmente, fora do Cloud Run")
+
+// EnableDevTokens passa a aceitar tokens de teste assinados com o segredo. No
+// Cloud Run (variável K_SERVICE definida) o segredo é recusado, para que uma
+// configuração esquecida não abra a área administrativa em produção.
+func (a *Authenticator) EnableDevTokens(secret string) error {
+	if os.Getenv("K_SERVICE") != "" {
+		return ErrDevTokensOnCloudRun
+	}
+	if secret == "" {
+		return errors.New("segredo dos tokens de teste não configurado")
+	}
+	a.DevSecret = []byte(secret)
+	return nil
+}
+
+type devClaims struct {
+	Subject   string `json:"sub"`
+	Email     string `json:"email,omitempty"`
+	Admin     bool   `json:"admin"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+func sign(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// SignDevToken gera um token de teste ("dev.<dados>.<assinatura>", HMAC-SHA256)
+// aceito pelo Authenticator configurado com o mesmo segredo
+func SignDevToken(secret []byte, identity Identity, ttl time.Duration) (string, error) {
+	if len(secret) == 0 {
+		return "", errors.New("segredo dos tokens de teste não configurado")
+	}
+	subject := identity.UID
+	if subject == "" {
+		subject = identity.Email
+	}
+	payload, err := json.Marshal(devClaims{
+		Subject:   subject,
+		Email:     identity.Email,
+		Admin:     identity.Admin,
+		ExpiresAt: time.Now().Add(ttl).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return devTokenPrefix + encoded + "." + sign(secret, encoded), nil
+}
+
+func verifyDevToken(secret []byte, token string) (Identity, error) {
+	encoded, signature, found := strings.Cut(strings.TrimPrefix(token, devTokenPrefix), ".")
+	if !found || !hmac.Equal([]byte(signature), []byte(sign(secret, encoded))) {
+		return Identity{}, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Identity{}, ErrInvalidToken
+	}
+	var claims devClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Subject == "" {
+		return Identity{}, ErrInvalidToken
+	}
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return Identity{}, ErrInvalidToken
+	}
+	return Identity{UID: claims.Subject, Email: claims.Email, Admin: claims.Admin, Source: "dev"}, nil
+}
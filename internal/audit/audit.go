@@ -0,0 +1,158 @@
+// Package audit registra as ações administrativas sensíveis, como a
+// exportação de respostas da pesquisa de satisfação.
+package audit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// MaxEntries limita os registros guardados; os mais antigos são descartados
+const MaxEntries = 10000
+
+// Entry é uma ação registrada
+type Entry struct {
+	ID     string `json:"id"`
+	Action string `json:"action"`
+	// ActorID e ActorEmail identificam o administrador; Source é "firebase" ou "dev"
+	ActorID    string            `json:"actorId"`
+	ActorEmail string            `json:"actorEmail,omitempty"`
+	Source     string            `json:"source,omitempty"`
+	RemoteAddr string            `json:"remoteAddr,omitempty"`
+	Details    map[string]string `json:"details,omitempty"`
+	// Count é o número de registros afetados pela ação
+	Count int       `json:"count"`
+	Error string    `json:"error,omitempty"`
+	At    time.Time `json:"at"`
+	// FinishedAt marca o fim de uma ação registrada antes de começar; nil enquanto ela não termina
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// Log guarda o registro de auditoria. Record grava a ação ou, com um ID já
+// registrado, substitui o registro anterior.
+type Log interface {
+	Record(ctx context.Context, e Entry) (Entry, error)
+	Recent(ctx context.Context, action string, limit int) ([]Entry, error)
+}
+
+// prepare preenche ID e data quando ausentes
+func prepare(e Entry) Entry {
+	if e.ID == "" {
+		e.ID = newID()
+	}
+	if e.At.IsZero() {
+		e.At = time.Now().UTC()
+	}
+	return e
+}
+
+// Store guarda o registro de auditoria em um arquivo JSON local
+type Store struct {
+	filePath string
+	entries  []Entry
+	mutex    sync.RWMutex
+}
+
+// NewStore cria o armazenamento e carrega o arquivo local, se existir
+func NewStore(filePath string) (*Store, error) {
+	s := &Store{filePath: filePath}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) load() error {
+	file, err := os.Open(s.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	if err := json.NewDecoder(file).Decode(&s.entries); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+func (s *Store) saveLocked() error {
+	if dir := filepath.Dir(s.filePath); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	payload, err := json.Marshal(s.entries)
+	if err != nil {
+		return err
+	}
+
+	tempPath := s.filePath + ".tmp"
+	if err := os.WriteFile(tempPath, payload, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, s.filePath)
+}
+
+func newID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return time.Now().UTC().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(buf)
+}
+
+// Record grava uma ação, preenchendo ID e data quando ausentes
+func (s *Store) Record(ctx context.Context, e Entry) (Entry, error) {
+	e = prepare(e)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	previous := append([]Entry(nil), s.entries...)
+	replaced := false
+	for i := range s.entries {
+		if s.entries[i].ID == e.ID {
+			s.entries[i] = e
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		s.entries = append(s.entries, e)
+	}
+	if len(s.entries) > MaxEntries {
+		s.entries = append([]Entry(nil), s.entries[len(s.entries)-MaxEntries:]...)
+	}
+	if err := s.saveLocked(); err != nil {
+		// O registro só vale se chegou ao disco
+		s.entries = previous
+		return e, err
+	}
+	return e, nil
+}
+
+// Recent devolve os registros mais recentes primeiro, opcionalmente de uma só ação
+func (s *Store) Recent(ctx context.Context, action string, limit int) ([]Entry, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	out := []Entry{}
+	for i := len(s.entries) - 1; i >= 0 && len(out) < limit; i-- {
+		if action == "" || s.entries[i].Action == action {
+			out = append(out, s.entries[i])
+		}
+	}
+	return out, nil
+}
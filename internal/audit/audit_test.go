@@ -0,0 +1,119 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"chat-bot/internal/sqlitedb"
+)
+
+// testLog confere gravação, substituição pelo ID, filtro e limite de uma implementação
+func testLog(t *testing.T, log Log) {
+	ctx := context.Background()
+	base := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	var recorded []Entry
+	for i, action := range []string{"nps.export", "outra", "nps.export"} {
+		entry, err := log.Record(ctx, Entry{Action: action, ActorID: "uid-1", At: base.Add(time.Duration(i) * time.Second)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entry.ID == "" {
+			t.Errorf("o ID deveria ser preenchido: %+v", entry)
+		}
+		recorded = append(recorded, entry)
+	}
+	if entry, err := log.Record(ctx, Entry{Action: "sem-data"}); err != nil || entry.At.IsZero() {
+		t.Fatalf("a data deveria ser preenchida: %+v, %v", entry, err)
+	}
+
+	// Concluir uma ação registrada antes substitui o registro
+	finished := base.Add(time.Minute)
+	done := recorded[0]
+	done.Count, done.FinishedAt, done.Details = 42, &finished, map[string]string{"format": "csv"}
+	if _, err := log.Record(ctx, done); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := log.Recent(ctx, "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 4 || all[0].Action != "sem-data" || all[1].ID != recorded[2].ID || all[3].ID != recorded[0].ID {
+		t.Fatalf("registros inesperados: %+v", all)
+	}
+	if got := all[3]; got.Count != 42 || got.FinishedAt == nil || !got.FinishedAt.Equal(finished) || got.Details["format"] != "csv" || !got.At.Equal(base) {
+		t.Errorf("registro concluído não foi substituído: %+v", got)
+	}
+	if exports, err := log.Recent(ctx, "nps.export", 10); err != nil || len(exports) != 2 {
+		t.Errorf("esperava 2 exportações, obteve %d (%v)", len(exports), err)
+	}
+	if limited, err := log.Recent(ctx, "", 1); err != nil || len(limited) != 1 || limited[0].ID != all[0].ID {
+		t.Errorf("limite não respeitado: %+v", limited)
+	}
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auditoria", "audit-log.json")
+	store, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testLog(t, store)
+
+	reloaded, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if all, _ := reloaded.Recent(context.Background(), "", 10); len(all) != 4 {
+		t.Errorf("registros depois de recarregar: %d", len(all))
+	}
+}
+
+func TestSQLiteLog(t *testing.T) {
+	db, err := sqlitedb.Open(filepath.Join(t.TempDir(), "agoraai.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	log, err := NewSQLiteLog(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testLog(t, log)
+}
+
+func TestRecordFailureIsNotKept(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "auditoria")
+	store, err := NewStore(filepath.Join(dir, "audit-log.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// O diretório do log vira um arquivo: a gravação falha
+	if err := os.WriteFile(dir, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Record(context.Background(), Entry{Action: "nps.export"}); err == nil {
+		t.Fatal("esperava erro ao gravar")
+	}
+	if all, _ := store.Recent(context.Background(), "", 10); len(all) != 0 {
+		t.Errorf("um registro não gravado não deveria aparecer: %+v", all)
+	}
+}
+
+func TestRecordKeepsMaxEntries(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "audit-log.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.entries = make([]Entry, MaxEntries)
+	store.entries[0].ID = "mais-antigo"
+	if _, err := store.Record(context.Background(), Entry{ID: "novo", Action: "nps.export"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(store.entries) != MaxEntries || store.entries[0].ID == "mais-antigo" || store.entries[MaxEntries-1].ID != "novo" {
+		t.Errorf("o registro mais antigo deveria ser descartado: %d registros", len(store.entries))
+	}
+}
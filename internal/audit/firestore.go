@@ -0,0 +1,89 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+// FirestoreLog guarda um registro por documento, com o ID do registro como ID
+// do documento, para que todas as instâncias vejam as mesmas ações
+type FirestoreLog struct {
+	client     *firestore.Client
+	collection string
+}
+
+type firestoreEntry struct {
+	Action     string            `firestore:"action"`
+	ActorID    string            `firestore:"actorId"`
+	ActorEmail string            `firestore:"actorEmail"`
+	Source     string            `firestore:"source"`
+	RemoteAddr string            `firestore:"remoteAddr"`
+	Details    map[string]string `firestore:"details"`
+	Count      int               `firestore:"count"`
+	Error      string            `firestore:"error"`
+	At         time.Time         `firestore:"at"`
+	FinishedAt *time.Time        `firestore:"finishedAt"`
+}
+
+// NewFirestoreLog usa a coleção informada
+func NewFirestoreLog(client *firestore.Client, collection string) *FirestoreLog {
+	return &FirestoreLog{client: client, collection: collection}
+}
+
+func (f *FirestoreLog) Record(ctx context.Context, e Entry) (Entry, error) {
+	e = prepare(e)
+	_, err := f.client.Collection(f.collection).Doc(e.ID).Set(ctx, firestoreEntry{
+		Action:     e.Action,
+		ActorID:    e.ActorID,
+		ActorEmail: e.ActorEmail,
+		Source:     e.Source,
+		RemoteAddr: e.RemoteAddr,
+		Details:    e.Details,
+		Count:      e.Count,
+		Error:      e.Error,
+		At:         e.At,
+		FinishedAt: e.FinishedAt,
+	})
+	return e, err
+}
+
+// Recent usa o índice composto de action e at (firestore.indexes.json) quando filtra por ação
+func (f *FirestoreLog) Recent(ctx context.Context, action string, limit int) ([]Entry, error) {
+	query := f.client.Collection(f.collection).Query
+	if action != "" {
+		query = query.Where("action", "==", action)
+	}
+	iter := query.OrderBy("at", firestore.Desc).Limit(limit).Documents(ctx)
+	defer iter.Stop()
+
+	out := []Entry{}
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		var data firestoreEntry
+		if err := doc.DataTo(&data); err != nil {
+			return nil, err
+		}
+		out = append(out, Entry{
+			ID:         doc.Ref.ID,
+			Action:     data.Action,
+			ActorID:    data.ActorID,
+			ActorEmail: data.ActorEmail,
+			Source:     data.Source,
+			RemoteAddr: data.RemoteAddr,
+			Details:    data.Details,
+			Count:      data.Count,
+			Error:      data.Error,
+			At:         data.At,
+			FinishedAt: data.FinishedAt,
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"chat-bot/internal/sqlitedb"
+)
+
+// sqliteTimeLayout grava as datas em UTC com largura fixa, para que a ordem
+// como texto seja a ordem do tempo
+const sqliteTimeLayout = "2006-01-02T15:04:05.000000000Z"
+
+// sqliteMigrations é o esquema do registro de auditoria no SQLite; datas em
+// texto UTC (sqliteTimeLayout) e detalhes em um objeto JSON
+var sqliteMigrations = []sqlitedb.Migration{
+	{
+		Version: 1,
+		Name:    "cria audit_log",
+		SQL: `
+CREATE TABLE audit_log (
+	id          TEXT PRIMARY KEY,
+	action      TEXT    NOT NULL,
+	actor_id    TEXT    NOT NULL DEFAULT '',
+	actor_email TEXT    NOT NULL DEFAULT '',
+	source      TEXT    NOT NULL DEFAULT '',
+	remote_addr TEXT    NOT NULL DEFAULT '',
+	details     TEXT    NOT NULL DEFAULT '{}',
+	count       INTEGER NOT NULL DEFAULT 0,
+	error       TEXT    NOT NULL DEFAULT '',
+	at          TEXT    NOT NULL,
+	finished_at TEXT    NOT NULL DEFAULT ''
+);
+CREATE INDEX audit_log_at ON audit_log (at DESC);
+CREATE INDEX audit_log_action ON audit_log (action, at DESC);
+`,
+	},
+}
+
+// SQLiteLog guarda o registro de auditoria no banco SQLite da instalação
+type SQLiteLog struct {
+	db *sql.DB
+}
+
+// NewSQLiteLog aplica as migrações pendentes no banco já aberto
+func NewSQLiteLog(ctx context.Context, db *sql.DB) (*SQLiteLog, error) {
+	if err := sqlitedb.Migrate(ctx, db, "audit", sqliteMigrations); err != nil {
+		return nil, err
+	}
+	return &SQLiteLog{db: db}, nil
+}
+
+func (s *SQLiteLog) Record(ctx context.Context, e Entry) (Entry, error) {
+	e = prepare(e)
+	details, err := json.Marshal(e.Details)
+	if err != nil {
+		return e, err
+	}
+	finishedAt := ""
+	if e.FinishedAt != nil {
+		finishedAt = e.FinishedAt.UTC().Format(sqliteTimeLayout)
+	}
+	_, err = s.db.ExecContext(ctx, `INSERT OR REPLACE INTO audit_log
+		(id, action, actor_id, actor_email, source, remote_addr, details, count, error, at, finished_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.ID, e.Action, e.ActorID, e.ActorEmail, e.Source, e.RemoteAddr, string(details), e.Count, e.Error,
+		e.At.UTC().Format(sqliteTimeLayout), finishedAt)
+	return e, err
+}
+
+func (s *SQLiteLog) Recent(ctx context.Context, action string, limit int) ([]Entry, error) {
+	query := `SELECT id, action, actor_id, actor_email, source, remote_addr, details, count, error, at, finished_at FROM audit_log`
+	args := []interface{}{}
+	if action != "" {
+		query += ` WHERE action = ?`
+		args = append(args, action)
+	}
+	query += ` ORDER BY at DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []Entry{}
+	for rows.Next() {
+		var e Entry
+		var details, at, finishedAt string
+		if err := rows.Scan(&e.ID, &e.Action, &e.ActorID, &e.ActorEmail, &e.Source, &e.RemoteAddr, &details, &e.Count, &e.Error, &at, &finishedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(details), &e.Details); err != nil {
+			return nil, err
+		}
+		if e.At, err = time.Parse(sqliteTimeLayout, at); err != nil {
+			return nil, err
+		}
+		if finishedAt != "" {
+			parsed, err := time.Parse(sqliteTimeLayout, finishedAt)
+			if err != nil {
+				return nil, err
+			}
+			e.FinishedAt = &parsed
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
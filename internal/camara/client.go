@@ -0,0 +1,426 @@
+package camara
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BaseURL é o endereço da API de Dados Abertos da Câmara dos Deputados
+const BaseURL = "https://dadosabertos.camara.leg.br/api/v2"
+
+// maxPages é uma trava contra links "next" que nunca terminam: a paginação
+// segue até a API não devolver mais páginas e, se passar disso, falha em vez
+// de devolver uma lista cortada
+const maxPages = 1000
+
+// ErrTooManyPages indica que a listagem passou de maxPages páginas
+var ErrTooManyPages = errors.New("câmara: listagem com páginas demais")
+
+// Client acessa a API de Dados Abertos da Câmara
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient cria um cliente com timeout padrão
+func NewClient() *Client {
+	return &Client{
+		baseURL:    BaseURL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// NewClientWithBaseURL cria um cliente para outro endereço da API, como um
+// servidor de testes
+func NewClientWithBaseURL(baseURL string, httpClient *http.Client) *Client {
+	return &Client{baseURL: strings.TrimSuffix(baseURL, "/"), httpClient: httpClient}
+}
+
+type link struct {
+	Rel  string `json:"rel"`
+	Href string `json:"href"`
+}
+
+type envelope struct {
+	Dados json.RawMessage `json:"dados"`
+	Links []link          `json:"links"`
+}
+
+func (c *Client) fetch(ctx context.Context, rawURL string) (*envelope, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("câmara: status %d em %s: %s", resp.StatusCode, rawURL, strings.TrimSpace(string(body)))
+	}
+
+	var env envelope
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return nil, fmt.Errorf("câmara: erro ao decodificar %s: %w", rawURL, err)
+	}
+	return &env, nil
+}
+
+func (c *Client) buildURL(path string, query url.Values) string {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+// get busca um único recurso e decodifica o campo "dados"
+func (c *Client) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	env, err := c.fetch(ctx, c.buildURL(path, query))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(env.Dados, out)
+}
+
+// getAll percorre as páginas seguindo o link "next" e acumula os itens; com
+// limit 0 lê até a última página
+func getAll[T any](ctx context.Context, c *Client, path string, query url.Values, limit int) ([]T, error) {
+	var items []T
+	if limit > 0 && limit < 100 {
+		query.Set("itens", strconv.Itoa(limit))
+	}
+	next := c.buildURL(path, query)
+
+	for page := 0; next != ""; page++ {
+		if page >= maxPages {
+			return items, fmt.Errorf("%w: %s", ErrTooManyPages, path)
+		}
+		env, err := c.fetch(ctx, next)
+		if err != nil {
+			return items, err
+		}
+
+		var batch []T
+		if err := json.Unmarshal(env.Dados, &batch); err != nil {
+			return items, fmt.Errorf("câmara: erro ao decodificar página de %s: %w", path, err)
+		}
+		items = append(items, batch...)
+
+		if limit > 0 && len(items) >= limit {
+			return items[:limit], nil
+		}
+
+		next = ""
+		for _, l := range env.Links {
+			if l.Rel == "next" {
+				next = l.Href
+				break
+			}
+		}
+	}
+
+	return items, nil
+}
+
+// Deputado é o resumo retornado pela listagem de deputados
+type Deputado struct {
+	ID            int    `json:"id"`
+	Nome          string `json:"nome"`
+	SiglaPartido  string `json:"siglaPartido"`
+	SiglaUF       string `json:"siglaUf"`
+	IDLegislatura int    `json:"idLegislatura"`
+	URLFoto       string `json:"urlFoto"`
+	Email         string `json:"email"`
+}
+
+// DeputadoDetalhe traz os dados cadastrais de um deputado
+type DeputadoDetalhe struct {
+	ID           int    `json:"id"`
+	NomeCivil    string `json:"nomeCivil"`
+	UltimoStatus struct {
+		Nome              string `json:"nome"`
+		NomeEleitoral     string `json:"nomeEleitoral"`
+		SiglaPartido      string `json:"siglaPartido"`
+		SiglaUF           string `json:"siglaUf"`
+		IDLegislatura     int    `json:"idLegislatura"`
+		URLFoto           string `json:"urlFoto"`
+		Situacao          string `json:"situacao"`
+		CondicaoEleitoral string `json:"condicaoEleitoral"`
+	} `json:"ultimoStatus"`
+}
+
+// HistoricoItem é uma mudança de status do deputado (partido, legislatura, situação)
+type HistoricoItem struct {
+	Nome              string `json:"nome"`
+	SiglaPartido      string `json:"siglaPartido"`
+	SiglaUF           string `json:"siglaUf"`
+	IDLegislatura     int    `json:"idLegislatura"`
+	DataHora          string `json:"dataHora"`
+	Situacao          string `json:"situacao"`
+	CondicaoEleitoral string `json:"condicaoEleitoral"`
+	DescricaoStatus   string `json:"descricaoStatus"`
+}
+
+// MandatoExterno é um cargo eletivo exercido fora da Câmara
+type MandatoExterno struct {
+	AnoInicio           string `json:"anoInicio"`
+	AnoFim              string `json:"anoFim"`
+	Cargo               string `json:"cargo"`
+	SiglaUF             string `json:"siglaUf"`
+	Municipio           string `json:"municipio"`
+	SiglaPartidoEleicao string `json:"siglaPartidoEleicao"`
+}
+
+// OrgaoMembro é a participação do deputado em um órgão (comissão, grupo, etc.)
+type OrgaoMembro struct {
+	IDOrgao    int    `json:"idOrgao"`
+	SiglaOrgao string `json:"siglaOrgao"`
+	NomeOrgao  string `json:"nomeOrgao"`
+	Titulo     string `json:"titulo"`
+	DataInicio string `json:"dataInicio"`
+	DataFim    string `json:"dataFim"`
+}
+
+// Proposicao é o resumo de uma proposição legislativa
+type Proposicao struct {
+	ID        int    `json:"id"`
+	SiglaTipo string `json:"siglaTipo"`
+	Numero    int    `json:"numero"`
+	Ano       int    `json:"ano"`
+	Ementa    string `json:"ementa"`
+}
+
+// Despesa é um lançamento da Cota para o Exercício da Atividade Parlamentar (CEAP)
+type Despesa struct {
+	Ano               int     `json:"ano"`
+	Mes               int     `json:"mes"`
+	TipoDespesa       string  `json:"tipoDespesa"`
+	CodDocumento      int     `json:"codDocumento"`
+	DataDocumento     string  `json:"dataDocumento"`
+	ValorDocumento    float64 `json:"valorDocumento"`
+	ValorLiquido      float64 `json:"valorLiquido"`
+	URLDocumento      string  `json:"urlDocumento"`
+	NomeFornecedor    string  `json:"nomeFornecedor"`
+	CNPJCPFFornecedor string  `json:"cnpjCpfFornecedor"`
+}
+
+// Votacao é o resumo de uma votação registrada na Câmara
+type Votacao struct {
+	ID               string `json:"id"`
+	Data             string `json:"data"`
+	DataHoraRegistro string `json:"dataHoraRegistro"`
+	SiglaOrgao       string `json:"siglaOrgao"`
+	ProposicaoObjeto string `json:"proposicaoObjeto"`
+	Descricao        string `json:"descricao"`
+	Aprovacao        *int   `json:"aprovacao"`
+}
+
+// Voto é o voto nominal de um deputado em uma votação
+type Voto struct {
+	TipoVoto         string `json:"tipoVoto"`
+	DataRegistroVoto string `json:"dataRegistroVoto"`
+	Deputado         struct {
+		ID           int    `json:"id"`
+		Nome         string `json:"nome"`
+		SiglaPartido string `json:"siglaPartido"`
+		SiglaUF      string `json:"siglaUf"`
+	} `json:"deputado_"`
+}
+
+// Orientacao é a orientação de voto de um partido, bloco ou liderança
+type Orientacao struct {
+	OrientacaoVoto    string `json:"orientacaoVoto"`
+	CodTipoLideranca  string `json:"codTipoLideranca"`
+	SiglaPartidoBloco string `json:"siglaPartidoBloco"`
+}
+
+// ListDeputados retorna os deputados em exercício
+func (c *Client) ListDeputados(ctx context.Context, limit int) ([]Deputado, error) {
+	query := url.Values{"itens": {"100"}, "ordem": {"ASC"}, "ordenarPor": {"nome"}}
+	return getAll[Deputado](ctx, c, "/deputados", query, limit)
+}
+
+// GetDeputado retorna os dados cadastrais de um deputado
+func (c *Client) GetDeputado(ctx context.Context, id int) (*DeputadoDetalhe, error) {
+	var detalhe DeputadoDetalhe
+	if err := c.get(ctx, fmt.Sprintf("/deputados/%d", id), nil, &detalhe); err != nil {
+		return nil, err
+	}
+	return &detalhe, nil
+}
+
+// Historico retorna as mudanças de status do deputado em todas as legislaturas
+func (c *Client) Historico(ctx context.Context, id int) ([]HistoricoItem, error) {
+	var items []HistoricoItem
+	err := c.get(ctx, fmt.Sprintf("/deputados/%d/historico", id), nil, &items)
+	return items, err
+}
+
+// MandatosExternos retorna cargos eletivos exercidos fora da Câmara
+func (c *Client) MandatosExternos(ctx context.Context, id int) ([]MandatoExterno, error) {
+	var items []MandatoExterno
+	err := c.get(ctx, fmt.Sprintf("/deputados/%d/mandatosExternos", id), nil, &items)
+	return items, err
+}
+
+// Orgaos retorna os órgãos dos quais o deputado é ou foi membro
+func (c *Client) Orgaos(ctx context.Context, id int) ([]OrgaoMembro, error) {
+	query := url.Values{"itens": {"100"}}
+	return getAll[OrgaoMembro](ctx, c, fmt.Sprintf("/deputados/%d/orgaos", id), query, 0)
+}
+
+// ProposicoesQuery filtra a busca de proposições
+type ProposicoesQuery struct {
+	AutorID     int
+	SiglaTipo   []string
+	Numero      int
+	Ano         int
+	CodSituacao int
+	// DataInicio e DataFim filtram pela tramitação; sem datas nem ano a API
+	// considera apenas os últimos 30 dias.
+	DataInicio string
+	DataFim    string
+	// DataApresentacaoInicio e DataApresentacaoFim filtram pela apresentação
+	DataApresentacaoInicio string
+	DataApresentacaoFim    string
+}
+
+// CodSituacaoNormaJuridica é a situação "Transformado em Norma Jurídica"
+const CodSituacaoNormaJuridica = 1140
+
+// ListProposicoes busca proposições com os filtros informados
+func (c *Client) ListProposicoes(ctx context.Context, q ProposicoesQuery, limit int) ([]Proposicao, error) {
+	query := url.Values{"itens": {"100"}, "ordem": {"DESC"}, "ordenarPor": {"id"}}
+	if q.AutorID > 0 {
+		query.Set("idDeputadoAutor", strconv.Itoa(q.AutorID))
+	}
+	if len(q.SiglaTipo) > 0 {
+		query.Set("siglaTipo", strings.Join(q.SiglaTipo, ","))
+	}
+	if q.Numero > 0 {
+		query.Set("numero", strconv.Itoa(q.Numero))
+	}
+	if q.Ano > 0 {
+		query.Set("ano", strconv.Itoa(q.Ano))
+	}
+	if q.CodSituacao > 0 {
+		query.Set("codSituacao", strconv.Itoa(q.CodSituacao))
+	}
+	if q.DataInicio != "" {
+		query.Set("dataInicio", q.DataInicio)
+	}
+	if q.DataFim != "" {
+		query.Set("dataFim", q.DataFim)
+	}
+	if q.DataApresentacaoInicio != "" {
+		query.Set("dataApresentacaoInicio", q.DataApresentacaoInicio)
+	}
+	if q.DataApresentacaoFim != "" {
+		query.Set("dataApresentacaoFim", q.DataApresentacaoFim)
+	}
+	return getAll[Proposicao](ctx, c, "/proposicoes", query, limit)
+}
+
+// Despesas retorna os lançamentos da CEAP de um deputado no ano informado
+func (c *Client) Despesas(ctx context.Context, id, ano int) ([]Despesa, error) {
+	query := url.Values{"ano": {strconv.Itoa(ano)}, "itens": {"100"}, "ordem": {"ASC"}, "ordenarPor": {"mes"}}
+	return getAll[Despesa](ctx, c, fmt.Sprintf("/deputados/%d/despesas", id), query, 0)
+}
+
+// ListVotacoes retorna as votações de um órgão no intervalo de datas (formato AAAA-MM-DD)
+func (c *Client) ListVotacoes(ctx context.Context, idOrgao int, dataInicio, dataFim string, limit int) ([]Votacao, error) {
+	query := url.Values{
+		"dataInicio": {dataInicio},
+		"dataFim":    {dataFim},
+		"itens":      {"100"},
+		"ordem":      {"DESC"},
+		"ordenarPor": {"dataHoraRegistro"},
+	}
+	if idOrgao > 0 {
+		query.Set("idOrgao", strconv.Itoa(idOrgao))
+	}
+	return getAll[Votacao](ctx, c, "/votacoes", query, limit)
+}
+
+// Votos retorna os votos nominais de uma votação
+func (c *Client) Votos(ctx context.Context, votacaoID string) ([]Voto, error) {
+	var items []Voto
+	err := c.get(ctx, "/votacoes/"+url.PathEscape(votacaoID)+"/votos", nil, &items)
+	return items, err
+}
+
+// Orientacoes retorna as orientações de bancada de uma votação
+func (c *Client) Orientacoes(ctx context.Context, votacaoID string) ([]Orientacao, error) {
+	var items []Orientacao
+	err := c.get(ctx, "/votacoes/"+url.PathEscape(votacaoID)+"/orientacoes", nil, &items)
+	return items, err
+}
+
+// IDOrgaoPlenario é o identificador do Plenário da Câmara
+const IDOrgaoPlenario = 180
+
+// ProposicaoAfetada é uma proposição votada ou afetada por uma votação
+type ProposicaoAfetada struct {
+	ID        int    `json:"id"`
+	SiglaTipo string `json:"siglaTipo"`
+	Numero    int    `json:"numero"`
+	Ano       int    `json:"ano"`
+	Ementa    string `json:"ementa"`
+}
+
+// VotacaoDetalhe traz as proposições relacionadas a uma votação
+type VotacaoDetalhe struct {
+	ID                  string              `json:"id"`
+	Descricao           string              `json:"descricao"`
+	Aprovacao           *int                `json:"aprovacao"`
+	ProposicoesAfetadas []ProposicaoAfetada `json:"proposicoesAfetadas"`
+	ObjetosPossiveis    []ProposicaoAfetada `json:"objetosPossiveis"`
+}
+
+// GetVotacao retorna os detalhes de uma votação
+func (c *Client) GetVotacao(ctx context.Context, votacaoID string) (*VotacaoDetalhe, error) {
+	var detalhe VotacaoDetalhe
+	if err := c.get(ctx, "/votacoes/"+url.PathEscape(votacaoID), nil, &detalhe); err != nil {
+		return nil, err
+	}
+	return &detalhe, nil
+}
+
+// Tramitacao é um evento da tramitação de uma proposição
+type Tramitacao struct {
+	DataHora            string `json:"dataHora"`
+	Sequencia           int    `json:"sequencia"`
+	SiglaOrgao          string `json:"siglaOrgao"`
+	Regime              string `json:"regime"`
+	DescricaoTramitacao string `json:"descricaoTramitacao"`
+	CodTipoTramitacao   string `json:"codTipoTramitacao"`
+	DescricaoSituacao   string `json:"descricaoSituacao"`
+	CodSituacao         *int   `json:"codSituacao"`
+	Despacho            string `json:"despacho"`
+	URL                 string `json:"url"`
+}
+
+// Tramitacoes retorna a tramitação de uma proposição, opcionalmente a partir de uma data (AAAA-MM-DD)
+func (c *Client) Tramitacoes(ctx context.Context, id int, dataInicio string) ([]Tramitacao, error) {
+	query := url.Values{}
+	if dataInicio != "" {
+		query.Set("dataInicio", dataInicio)
+	}
+	var items []Tramitacao
+	err := c.get(ctx, fmt.Sprintf("/proposicoes/%d/tramitacoes", id), query, &items)
+	return items, err
+}
@@ -0,0 +1,58 @@
+package camara
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// pagedServer serve total itens em páginas de pageSize, com o link "next"
+// enquanto houver mais
+func pagedServer(t *testing.T, total, pageSize int) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("pagina"))
+		if page == 0 {
+			page = 1
+		}
+		var env struct {
+			Dados []Proposicao `json:"dados"`
+			Links []link       `json:"links"`
+		}
+		for i := (page - 1) * pageSize; i < page*pageSize && i < total; i++ {
+			env.Dados = append(env.Dados, Proposicao{ID: i + 1})
+		}
+		if page*pageSize < total {
+			env.Links = append(env.Links, link{Rel: "next", Href: fmt.Sprintf("%s%s?pagina=%d", server.URL, r.URL.Path, page+1)})
+		}
+		json.NewEncoder(w).Encode(env)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGetAllReadsUntilLastPage(t *testing.T) {
+	server := pagedServer(t, 2350, 100)
+	c := &Client{baseURL: server.URL, httpClient: server.Client()}
+
+	items, err := c.ListProposicoes(context.Background(), ProposicoesQuery{AutorID: 1}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 2350 || items[len(items)-1].ID != 2350 {
+		t.Fatalf("esperava 2350 proposições, obteve %d", len(items))
+	}
+
+	limited, err := c.ListProposicoes(context.Background(), ProposicoesQuery{AutorID: 1}, 150)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(limited) != 150 {
+		t.Fatalf("esperava 150 proposições com limite, obteve %d", len(limited))
+	}
+}
@@ -0,0 +1,188 @@
+package ceap
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// OutlierRatio é quantas vezes acima da mediana do estado um valor precisa estar
+	OutlierRatio = 2.0
+	// MinPeers é o mínimo de deputados do estado para calcular a mediana
+	MinPeers = 3
+	// minOutlierGap ignora diferenças pequenas em valores baixos
+	minOutlierGap = 1000.0
+)
+
+// Escopos de comparação com a mediana do estado
+const (
+	ScopeTotal    = "total"
+	ScopeCategory = "categoria"
+	ScopeMonth    = "mes"
+)
+
+// Outlier é um gasto muito acima da mediana dos deputados do mesmo estado
+type Outlier struct {
+	Escopo string `json:"escopo"`
+	// Chave é a categoria ou o mês (AAAA-MM); vazia para o total do ano
+	Chave     string  `json:"chave,omitempty"`
+	Valor     float64 `json:"valor"`
+	MedianaUF float64 `json:"medianaUf"`
+	Razao     float64 `json:"razao"`
+	Pares     int     `json:"pares"`
+}
+
+// Report é a análise das despesas de um deputado em um ano
+type Report struct {
+	ID              string     `json:"id"`
+	Nome            string     `json:"nome"`
+	Partido         string     `json:"partido"`
+	UF              string     `json:"uf"`
+	Ano             int        `json:"ano"`
+	AnosDisponiveis []int      `json:"anosDisponiveis"`
+	Total           float64    `json:"total"`
+	Documentos      int        `json:"documentos"`
+	Meses           []Month    `json:"meses"`
+	Categorias      []Category `json:"categorias"`
+	Fornecedores    []Supplier `json:"fornecedores"`
+	// MedianaUF e PosicaoUF comparam o total do ano com os deputados do estado
+	// (posição 1 é o maior gasto); ficam vazias com menos de MinPeers deputados
+	MedianaUF   float64   `json:"medianaTotalUf,omitempty"`
+	PosicaoUF   int       `json:"posicaoUf,omitempty"`
+	DeputadosUF int       `json:"deputadosUf"`
+	Outliers    []Outlier `json:"outliers"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid]
+	}
+	return (sorted[mid-1] + sorted[mid]) / 2
+}
+
+// compare devolve o outlier quando o valor supera OutlierRatio vezes a mediana
+func compare(scope, key string, value float64, peers []float64) (Outlier, bool) {
+	if len(peers) < MinPeers {
+		return Outlier{}, false
+	}
+	m := median(peers)
+	if m <= 0 || value < m*OutlierRatio || value-m < minOutlierGap {
+		return Outlier{}, false
+	}
+	return Outlier{
+		Escopo:    scope,
+		Chave:     key,
+		Valor:     value,
+		MedianaUF: round2(m),
+		Razao:     round2(value / m),
+		Pares:     len(peers),
+	}, true
+}
+
+// Report analisa as despesas do deputado no ano informado (0 para o mais
+// recente) e as compara com a mediana dos deputados do mesmo estado.
+// topSuppliers limita os fornecedores devolvidos.
+func (s *Store) Report(id string, ano, topSuppliers int) (Report, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	record, ok := s.records[id]
+	if !ok {
+		return Report{}, false
+	}
+	return s.reportLocked(record, ano, topSuppliers)
+}
+
+// ReportFor analisa um registro que não está guardado, comparando-o com os
+// deputados do estado que estão. Serve aos deputados fora do registro, cujas
+// despesas não devem entrar nas medianas.
+func (s *Store) ReportFor(record Record, ano, topSuppliers int) (Report, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.reportLocked(record, ano, topSuppliers)
+}
+
+func (s *Store) reportLocked(record Record, ano, topSuppliers int) (Report, bool) {
+	if ano == 0 {
+		ano = record.LatestYear()
+	}
+	year, ok := record.Anos[ano]
+	if !ok {
+		return Report{}, false
+	}
+
+	report := Report{
+		ID:           record.ID,
+		Nome:         record.Nome,
+		Partido:      record.Partido,
+		UF:           record.UF,
+		Ano:          ano,
+		Total:        year.Total,
+		Documentos:   year.Documentos,
+		Meses:        year.Meses,
+		Categorias:   year.Categorias,
+		Fornecedores: year.Fornecedores,
+		Outliers:     []Outlier{},
+		UpdatedAt:    year.FetchedAt,
+	}
+	for disponivel := range record.Anos {
+		report.AnosDisponiveis = append(report.AnosDisponiveis, disponivel)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(report.AnosDisponiveis)))
+	if topSuppliers > 0 && len(report.Fornecedores) > topSuppliers {
+		report.Fornecedores = report.Fornecedores[:topSuppliers]
+	}
+
+	peers := s.peersLocked(record.ID, record.UF, ano)
+	report.DeputadosUF = len(peers) + 1
+	if len(peers) < MinPeers {
+		return report, true
+	}
+
+	totals := make([]float64, 0, len(peers))
+	report.PosicaoUF = 1
+	for _, peer := range peers {
+		totals = append(totals, peer.Total)
+		if peer.Total > year.Total {
+			report.PosicaoUF++
+		}
+	}
+	report.MedianaUF = round2(median(totals))
+	if outlier, ok := compare(ScopeTotal, "", year.Total, totals); ok {
+		report.Outliers = append(report.Outliers, outlier)
+	}
+
+	// Categorias: deputados do estado sem gasto na categoria contam como zero
+	for _, category := range year.Categorias {
+		values := make([]float64, 0, len(peers))
+		for _, peer := range peers {
+			values = append(values, peer.category(category.Categoria))
+		}
+		if outlier, ok := compare(ScopeCategory, category.Categoria, category.Total, values); ok {
+			report.Outliers = append(report.Outliers, outlier)
+		}
+	}
+
+	// Meses: só entram os deputados que já prestaram contas do mês
+	for _, month := range year.Meses {
+		var values []float64
+		for _, peer := range peers {
+			if m, ok := peer.month(month.Mes); ok {
+				values = append(values, m.Total)
+			}
+		}
+		if outlier, ok := compare(ScopeMonth, month.Mes, month.Total, values); ok {
+			report.Outliers = append(report.Outliers, outlier)
+		}
+	}
+
+	sort.SliceStable(report.Outliers, func(i, j int) bool { return report.Outliers[i].Razao > report.Outliers[j].Razao })
+	return report, true
+}
@@ -0,0 +1,195 @@
+// Package ceap consolida os lançamentos da Cota para o Exercício da Atividade
+// Parlamentar (CEAP) dos deputados: totais mensais, categorias, fornecedores e
+// comparação com a mediana dos deputados do mesmo estado.
+package ceap
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"chat-bot/internal/camara"
+)
+
+// maxStoredSuppliers limita os fornecedores guardados por deputado e ano
+const maxStoredSuppliers = 50
+
+// Month é o total gasto em um mês, com a divisão por categoria
+type Month struct {
+	Mes        string             `json:"mes"`
+	Total      float64            `json:"total"`
+	Documentos int                `json:"documentos"`
+	Categorias map[string]float64 `json:"categorias"`
+}
+
+// Category é o total gasto em um tipo de despesa
+type Category struct {
+	Categoria  string  `json:"categoria"`
+	Total      float64 `json:"total"`
+	Documentos int     `json:"documentos"`
+}
+
+// Supplier é o total pago a um fornecedor (CNPJ ou CPF)
+type Supplier struct {
+	CNPJCPF    string  `json:"cnpjCpf,omitempty"`
+	Nome       string  `json:"nome"`
+	Total      float64 `json:"total"`
+	Documentos int     `json:"documentos"`
+}
+
+// Year consolida as despesas de um deputado em um ano
+type Year struct {
+	Ano          int        `json:"ano"`
+	Total        float64    `json:"total"`
+	Documentos   int        `json:"documentos"`
+	Meses        []Month    `json:"meses"`
+	Categorias   []Category `json:"categorias"`
+	Fornecedores []Supplier `json:"fornecedores"`
+	FetchedAt    time.Time  `json:"fetchedAt"`
+}
+
+// Record guarda as despesas consolidadas de um deputado
+type Record struct {
+	ID        string       `json:"id"`
+	Nome      string       `json:"nome"`
+	Partido   string       `json:"partido"`
+	UF        string       `json:"uf"`
+	Anos      map[int]Year `json:"anos"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// onlyDigits remove a pontuação do CNPJ/CPF
+func onlyDigits(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Aggregate consolida os lançamentos de um ano. O valor considerado é o
+// líquido, que já desconta glosas e o que foi devolvido.
+func Aggregate(ano int, despesas []camara.Despesa, fetchedAt time.Time) Year {
+	year := Year{Ano: ano, FetchedAt: fetchedAt}
+	months := map[int]*Month{}
+	categories := map[string]*Category{}
+	suppliers := map[string]*Supplier{}
+
+	for _, d := range despesas {
+		if d.Ano != ano || d.Mes < 1 || d.Mes > 12 {
+			continue
+		}
+		valor := d.ValorLiquido
+		categoria := strings.TrimSpace(d.TipoDespesa)
+		year.Total += valor
+		year.Documentos++
+
+		month, ok := months[d.Mes]
+		if !ok {
+			month = &Month{
+				Mes:        time.Date(ano, time.Month(d.Mes), 1, 0, 0, 0, 0, time.UTC).Format("2006-01"),
+				Categorias: map[string]float64{},
+			}
+			months[d.Mes] = month
+		}
+		month.Total += valor
+		month.Documentos++
+		month.Categorias[categoria] += valor
+
+		category, ok := categories[categoria]
+		if !ok {
+			category = &Category{Categoria: categoria}
+			categories[categoria] = category
+		}
+		category.Total += valor
+		category.Documentos++
+
+		// Passagens emitidas pela própria Câmara não têm CNPJ; agrupa pelo nome
+		doc := onlyDigits(d.CNPJCPFFornecedor)
+		key := doc
+		if key == "" {
+			key = strings.ToUpper(strings.TrimSpace(d.NomeFornecedor))
+		}
+		supplier, ok := suppliers[key]
+		if !ok {
+			supplier = &Supplier{CNPJCPF: doc, Nome: strings.TrimSpace(d.NomeFornecedor)}
+			suppliers[key] = supplier
+		}
+		supplier.Total += valor
+		supplier.Documentos++
+	}
+
+	year.Total = round2(year.Total)
+	for _, month := range months {
+		month.Total = round2(month.Total)
+		for categoria, valor := range month.Categorias {
+			month.Categorias[categoria] = round2(valor)
+		}
+		year.Meses = append(year.Meses, *month)
+	}
+	sort.Slice(year.Meses, func(i, j int) bool { return year.Meses[i].Mes < year.Meses[j].Mes })
+
+	for _, category := range categories {
+		category.Total = round2(category.Total)
+		year.Categorias = append(year.Categorias, *category)
+	}
+	sort.Slice(year.Categorias, func(i, j int) bool {
+		if year.Categorias[i].Total != year.Categorias[j].Total {
+			return year.Categorias[i].Total > year.Categorias[j].Total
+		}
+		return year.Categorias[i].Categoria < year.Categorias[j].Categoria
+	})
+
+	for _, supplier := range suppliers {
+		supplier.Total = round2(supplier.Total)
+		year.Fornecedores = append(year.Fornecedores, *supplier)
+	}
+	sort.Slice(year.Fornecedores, func(i, j int) bool {
+		if year.Fornecedores[i].Total != year.Fornecedores[j].Total {
+			return year.Fornecedores[i].Total > year.Fornecedores[j].Total
+		}
+		return year.Fornecedores[i].Nome < year.Fornecedores[j].Nome
+	})
+	if len(year.Fornecedores) > maxStoredSuppliers {
+		year.Fornecedores = year.Fornecedores[:maxStoredSuppliers]
+	}
+	return year
+}
+
+// month devolve o total do mês (AAAA-MM) e se o deputado tem lançamentos nele
+func (y Year) month(mes string) (Month, bool) {
+	for _, m := range y.Meses {
+		if m.Mes == mes {
+			return m, true
+		}
+	}
+	return Month{}, false
+}
+
+// category devolve o total gasto na categoria no ano
+func (y Year) category(categoria string) float64 {
+	for _, c := range y.Categorias {
+		if c.Categoria == categoria {
+			return c.Total
+		}
+	}
+	return 0
+}
+
+// LatestYear devolve o ano mais recente com despesas consolidadas
+func (r Record) LatestYear() int {
+	latest := 0
+	for ano := range r.Anos {
+		if ano > latest {
+			latest = ano
+		}
+	}
+	return latest
+}
@@ -0,0 +1,158 @@
+package ceap
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"chat-bot/internal/camara"
+)
+
+func TestAggregate(t *testing.T) {
+	fetchedAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	despesas := []camara.Despesa{
+		{Ano: 2026, Mes: 1, TipoDespesa: "COMBUSTÍVEIS", ValorDocumento: 120, ValorLiquido: 100.105, CNPJCPFFornecedor: "12.345.678/0001-90", NomeFornecedor: "Posto A"},
+		{Ano: 2026, Mes: 1, TipoDespesa: "COMBUSTÍVEIS", ValorLiquido: 50, CNPJCPFFornecedor: "12345678000190", NomeFornecedor: "POSTO A LTDA"},
+		{Ano: 2026, Mes: 3, TipoDespesa: " PASSAGEM AÉREA ", ValorLiquido: 900, NomeFornecedor: "Cia Aérea"},
+		{Ano: 2026, Mes: 3, TipoDespesa: "PASSAGEM AÉREA", ValorLiquido: 300, NomeFornecedor: " cia aérea"},
+		{Ano: 2025, Mes: 12, TipoDespesa: "COMBUSTÍVEIS", ValorLiquido: 999},
+		{Ano: 2026, Mes: 0, TipoDespesa: "COMBUSTÍVEIS", ValorLiquido: 999},
+	}
+	year := Aggregate(2026, despesas, fetchedAt)
+
+	if year.Total != 1350.11 || year.Documentos != 4 {
+		t.Errorf("total %.2f em %d documentos, esperava 1350.11 em 4", year.Total, year.Documentos)
+	}
+	if len(year.Meses) != 2 || year.Meses[0].Mes != "2026-01" || year.Meses[1].Mes != "2026-03" {
+		t.Fatalf("meses inesperados: %+v", year.Meses)
+	}
+	if year.Meses[1].Categorias["PASSAGEM AÉREA"] != 1200 {
+		t.Errorf("categorias de março: %+v", year.Meses[1].Categorias)
+	}
+	if len(year.Categorias) != 2 || year.Categorias[0].Categoria != "PASSAGEM AÉREA" {
+		t.Errorf("categorias fora de ordem: %+v", year.Categorias)
+	}
+	// O CNPJ é agrupado sem pontuação; sem CNPJ, o nome sem diferenciar maiúsculas
+	if len(year.Fornecedores) != 2 || year.Fornecedores[0].Total != 1200 {
+		t.Fatalf("fornecedores inesperados: %+v", year.Fornecedores)
+	}
+	if posto := year.Fornecedores[1]; posto.CNPJCPF != "12345678000190" || posto.Documentos != 2 || posto.Total != 150.11 {
+		t.Errorf("fornecedor com CNPJ não agrupado: %+v", posto)
+	}
+}
+
+func TestYearsToFetch(t *testing.T) {
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	closed := Record{Anos: map[int]Year{2025: {FetchedAt: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)}}}
+	open := Record{Anos: map[int]Year{2025: {FetchedAt: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)}}}
+
+	tests := []struct {
+		name   string
+		record Record
+		want   int
+	}{
+		{"sem dados", Record{}, 2},
+		{"ano anterior buscado depois do prazo", closed, 1},
+		{"ano anterior buscado antes do prazo", open, 2},
+	}
+	for _, tt := range tests {
+		if got := yearsToFetch(tt.record, now); len(got) != tt.want || got[0] != 2026 {
+			t.Errorf("%s: anos %v, esperava %d começando por 2026", tt.name, got, tt.want)
+		}
+	}
+}
+
+func testRecord(id, uf string, total float64) Record {
+	return Record{
+		ID: id, Nome: id, UF: uf,
+		Anos: map[int]Year{2026: {
+			Ano: 2026, Total: total, Documentos: 1,
+			Meses:      []Month{{Mes: "2026-01", Total: total, Documentos: 1}},
+			Categorias: []Category{{Categoria: "DIVULGAÇÃO", Total: total, Documentos: 1}},
+		}},
+	}
+}
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := NewStore(filepath.Join(t.TempDir(), "ceap.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestReportOutliers(t *testing.T) {
+	store := newTestStore(t)
+	records := []Record{
+		testRecord("camara-1", "SP", 10000),
+		testRecord("camara-2", "SP", 12000),
+		testRecord("camara-3", "SP", 11000),
+		testRecord("camara-4", "SP", 40000),
+		testRecord("camara-5", "RJ", 90000),
+	}
+	if err := store.Upsert(records, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	report, ok := store.Report("camara-4", 0, 10)
+	if !ok {
+		t.Fatal("relatório não encontrado")
+	}
+	if report.Ano != 2026 || report.DeputadosUF != 4 || report.MedianaUF != 11000 || report.PosicaoUF != 1 {
+		t.Errorf("comparação com o estado inesperada: %+v", report)
+	}
+	if len(report.Outliers) != 3 {
+		t.Fatalf("esperava total, categoria e mês como outliers: %+v", report.Outliers)
+	}
+
+	// Com menos de MinPeers deputados no estado não há comparação
+	if report, _ := store.Report("camara-5", 0, 10); report.MedianaUF != 0 || len(report.Outliers) != 0 {
+		t.Errorf("comparação sem pares suficientes: %+v", report)
+	}
+	if _, ok := store.Report("camara-1", 2020, 10); ok {
+		t.Errorf("ano sem dados deveria ficar sem relatório")
+	}
+}
+
+func TestReportForDoesNotStore(t *testing.T) {
+	store := newTestStore(t)
+	peers := []Record{testRecord("camara-1", "SP", 10000), testRecord("camara-2", "SP", 12000), testRecord("camara-3", "SP", 11000)}
+	if err := store.Upsert(peers, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	outsider := testRecord("camara-99", "SP", 500000)
+	report, ok := store.ReportFor(outsider, 2026, 10)
+	if !ok || report.MedianaUF != 11000 || len(report.Outliers) == 0 {
+		t.Fatalf("relatório do deputado fora do registro inesperado: %+v", report)
+	}
+	if store.Size() != 3 {
+		t.Errorf("o registro avulso foi guardado: %d deputados", store.Size())
+	}
+	// Com o registro avulso o estado teria pares suficientes para a comparação
+	if report, _ := store.Report("camara-1", 2026, 10); report.DeputadosUF != 3 || report.MedianaUF != 0 {
+		t.Errorf("o registro avulso mudou a mediana do estado: %+v", report)
+	}
+}
+
+func TestUpsertKeepsOtherYears(t *testing.T) {
+	store := newTestStore(t)
+	old := testRecord("camara-1", "SP", 1000)
+	old.Anos[2025] = Year{Ano: 2025, Total: 2000}
+	if err := store.Upsert([]Record{old}, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Upsert([]Record{testRecord("camara-1", "SP", 3000)}, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := NewStore(store.filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	record, _ := reloaded.Get("camara-1")
+	if record.Anos[2025].Total != 2000 || record.Anos[2026].Total != 3000 {
+		t.Errorf("anos inesperados depois de gravar: %+v", record.Anos)
+	}
+}
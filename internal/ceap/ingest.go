@@ -0,0 +1,126 @@
+package ceap
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"chat-bot/internal/camara"
+)
+
+// closingDelay é o prazo que os deputados têm para apresentar as notas; até ele
+// passar, as despesas do ano anterior ainda podem mudar
+const closingDelay = 90 * 24 * time.Hour
+
+// Deputado identifica o deputado a ser ingerido
+type Deputado struct {
+	// ID no formato da API ("camara-204554")
+	ID         string
+	ExternalID int
+	Nome       string
+	Partido    string
+	UF         string
+}
+
+// Ingester busca as despesas da CEAP na API da Câmara e grava no Store
+type Ingester struct {
+	Camara *camara.Client
+	Store  *Store
+	// PerRun limita os deputados atualizados por execução
+	PerRun int
+	// MaxAge é a idade a partir da qual as despesas de um deputado são atualizadas
+	MaxAge time.Duration
+}
+
+// IngestResult resume uma execução da ingestão
+type IngestResult struct {
+	Deputados int      `json:"deputados"`
+	Pendentes int      `json:"pendentes"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// yearsToFetch devolve o ano corrente e, enquanto o prazo de prestação de
+// contas não tiver passado (ou se ainda não houver dados), o anterior
+func yearsToFetch(record Record, now time.Time) []int {
+	current := now.Year()
+	years := []int{current}
+	previous, ok := record.Anos[current-1]
+	closedAt := time.Date(current, time.January, 1, 0, 0, 0, 0, time.UTC).Add(closingDelay)
+	if !ok || previous.FetchedAt.Before(closedAt) {
+		years = append(years, current-1)
+	}
+	return years
+}
+
+// Fetch busca e consolida as despesas de um deputado, sem gravar
+func (in *Ingester) Fetch(ctx context.Context, d Deputado, now time.Time) (Record, error) {
+	existing, _ := in.Store.Get(d.ID)
+	record := Record{
+		ID:        d.ID,
+		Nome:      d.Nome,
+		Partido:   d.Partido,
+		UF:        strings.ToUpper(d.UF),
+		Anos:      map[int]Year{},
+		UpdatedAt: now.UTC(),
+	}
+	for _, ano := range yearsToFetch(existing, now) {
+		despesas, err := in.Camara.Despesas(ctx, d.ExternalID, ano)
+		if err != nil {
+			return record, fmt.Errorf("despesas de %d: %w", ano, err)
+		}
+		record.Anos[ano] = Aggregate(ano, despesas, now.UTC())
+	}
+	return record, nil
+}
+
+// Run atualiza as despesas dos deputados mais desatualizados, até PerRun por execução
+func (in *Ingester) Run(ctx context.Context, deputados []Deputado) (IngestResult, error) {
+	var result IngestResult
+	now := time.Now()
+
+	var stale []Deputado
+	updatedAt := map[string]time.Time{}
+	for _, d := range deputados {
+		record, ok := in.Store.Get(d.ID)
+		if ok && now.Sub(record.UpdatedAt) < in.MaxAge {
+			continue
+		}
+		updatedAt[d.ID] = record.UpdatedAt
+		stale = append(stale, d)
+	}
+	// Os que nunca foram ingeridos (data zero) vêm primeiro
+	sort.SliceStable(stale, func(i, j int) bool { return updatedAt[stale[i].ID].Before(updatedAt[stale[j].ID]) })
+	if in.PerRun > 0 && len(stale) > in.PerRun {
+		result.Pendentes = len(stale) - in.PerRun
+		stale = stale[:in.PerRun]
+	}
+
+	var records []Record
+	for _, d := range stale {
+		if ctx.Err() != nil {
+			result.Pendentes += len(stale) - len(records) - len(result.Errors)
+			break
+		}
+		record, err := in.Fetch(ctx, d, now)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", d.ID, err))
+			continue
+		}
+		records = append(records, record)
+	}
+	result.Deputados = len(records)
+
+	// Sem nenhum deputado e com erros, não marca a ingestão como concluída
+	if len(records) == 0 && len(result.Errors) > 0 {
+		return result, fmt.Errorf("falha ao ingerir despesas: %s", strings.Join(result.Errors, "; "))
+	}
+	if err := in.Store.Upsert(records, now.UTC()); err != nil {
+		return result, err
+	}
+
+	log.Printf("[CEAP] despesas de %d deputados atualizadas (%d pendentes, %d erros)", result.Deputados, result.Pendentes, len(result.Errors))
+	return result, nil
+}
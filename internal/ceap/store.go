@@ -0,0 +1,163 @@
+package ceap
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Store guarda as despesas consolidadas em um arquivo JSON local
+type Store struct {
+	filePath   string
+	records    map[string]Record
+	lastIngest time.Time
+	mutex      sync.RWMutex
+}
+
+type storeFile struct {
+	LastIngest time.Time `json:"lastIngest"`
+	Records    []Record  `json:"records"`
+}
+
+// NewStore cria o armazenamento e carrega o arquivo local, se existir
+func NewStore(filePath string) (*Store, error) {
+	s := &Store{filePath: filePath, records: map[string]Record{}}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) load() error {
+	file, err := os.Open(s.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+	return s.decode(file)
+}
+
+func (s *Store) decode(reader io.Reader) error {
+	var data storeFile
+	if err := json.NewDecoder(reader).Decode(&data); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+
+	for _, record := range data.Records {
+		s.records[record.ID] = record
+	}
+	s.lastIngest = data.LastIngest
+	return nil
+}
+
+func (s *Store) saveLocked() error {
+	if dir := filepath.Dir(s.filePath); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	records := make([]Record, 0, len(s.records))
+	for _, record := range s.records {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].ID < records[j].ID })
+
+	payload, err := json.Marshal(storeFile{LastIngest: s.lastIngest, Records: records})
+	if err != nil {
+		return err
+	}
+
+	tempPath := s.filePath + ".tmp"
+	if err := os.WriteFile(tempPath, payload, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, s.filePath)
+}
+
+// Restore substitui o conteúdo pelo arquivo publicado por outra instância
+// (a líder do agendador) e o grava no arquivo local
+func (s *Store) Restore(payload []byte) error {
+	fresh := &Store{records: map[string]Record{}}
+	if err := fresh.decode(bytes.NewReader(payload)); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.records, s.lastIngest = fresh.records, fresh.lastIngest
+	return s.saveLocked()
+}
+
+// Get devolve as despesas consolidadas de um deputado
+func (s *Store) Get(id string) (Record, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	record, ok := s.records[id]
+	return record, ok
+}
+
+// Upsert grava os registros, mantendo os anos já guardados que não foram
+// atualizados. Com ingestedAt não nulo, marca uma execução da ingestão.
+func (s *Store) Upsert(records []Record, ingestedAt time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, record := range records {
+		if record.Anos == nil {
+			record.Anos = map[int]Year{}
+		}
+		if existing, ok := s.records[record.ID]; ok {
+			for ano, year := range existing.Anos {
+				if _, updated := record.Anos[ano]; !updated {
+					record.Anos[ano] = year
+				}
+			}
+		}
+		s.records[record.ID] = record
+	}
+	if !ingestedAt.IsZero() {
+		s.lastIngest = ingestedAt
+	}
+	return s.saveLocked()
+}
+
+// LastIngest devolve quando a ingestão foi executada pela última vez
+func (s *Store) LastIngest() time.Time {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.lastIngest
+}
+
+// Size devolve o número de deputados com despesas guardadas
+func (s *Store) Size() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.records)
+}
+
+// peersLocked devolve o ano informado dos demais deputados do estado
+func (s *Store) peersLocked(id, uf string, ano int) []Year {
+	var peers []Year
+	for _, record := range s.records {
+		if record.ID == id || record.UF != uf {
+			continue
+		}
+		if year, ok := record.Anos[ano]; ok && year.Documentos > 0 {
+			peers = append(peers, year)
+		}
+	}
+	return peers
+}
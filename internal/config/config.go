@@ -27,6 +27,49 @@ type Config struct {
 	FirebaseClientX509CertURL       string `yaml:"FIREBASE_CLIENT_X509_CERT_URL"`
 	FirestoreCollection             string `yaml:"FIRESTORE_COLLECTION"`
 
+	// Dados legislativos
+	SenadoGovernmentLeader string `yaml:"SENADO_LIDER_GOVERNO"`
+
+	// Notificações das listas de acompanhamento
+	SMTPHost               string `yaml:"SMTP_HOST"`
+	SMTPPort               string `yaml:"SMTP_PORT"`
+	SMTPUsername           string `yaml:"SMTP_USERNAME"`
+	SMTPPassword           string `yaml:"SMTP_PASSWORD"`
+	SMTPFrom               string `yaml:"SMTP_FROM"`
+	WatchlistWebhookSecret string `yaml:"WATCHLIST_WEBHOOK_SECRET"`
+
+	// Origem pública do servidor (ex.: https://agoraai.exemplo.com), usada nos
+	// links de confirmação de e-mail e nos feeds
+	PublicBaseURL string `yaml:"PUBLIC_BASE_URL"`
+
+	// Busca na legislação: diretório do corpus e, opcionalmente, arquivos separados por vírgula
+	LegalCorpusDir   string `yaml:"LEGAL_CORPUS_DIR"`
+	LegalCorpusFiles string `yaml:"LEGAL_CORPUS_FILES"`
+
+	// Diário Oficial da União: credenciais do INLABS e seções importadas (ex.: DO1,DO2)
+	InlabsEmail    string `yaml:"INLABS_EMAIL"`
+	InlabsPassword string `yaml:"INLABS_PASSWORD"`
+	DOUSections    string `yaml:"DOU_SECOES"`
+
+	// Área administrativa: custom claim do Firebase e segredo dos tokens de teste (só local)
+	AdminClaim     string `yaml:"ADMIN_CLAIM"`
+	AdminDevSecret string `yaml:"ADMIN_DEV_SECRET"`
+
+	// Pesquisa NPS: segredo dos tokens assinados e, com "true", recusa de envios sem token
+	NPSTokenSecret  string `yaml:"NPS_TOKEN_SECRET"`
+	NPSRequireToken string `yaml:"NPS_REQUIRE_TOKEN"`
+
+	// TrustedProxy com "true" confia no último endereço de X-Forwarded-For como IP de origem
+	TrustedProxy string `yaml:"TRUSTED_PROXY"`
+
+	// Armazenamento: "firestore", "sqlite" ou "file"; vazio usa o Firestore quando
+	// configurado e o arquivo local nos demais casos. SQLitePath é o banco do modo sqlite.
+	StorageBackend string `yaml:"STORAGE_BACKEND"`
+	SQLitePath     string `yaml:"SQLITE_PATH"`
+	// NPSOutboxDir é o diretório persistente da fila de respostas que o Firestore
+	// ainda não aceitou; vazio desliga a fila
+	NPSOutboxDir string `yaml:"NPS_OUTBOX_DIR"`
+
 	// Compatibilidade com variáveis antigas
 	FirestoreProjectID string `yaml:"FIRESTORE_PROJECT_ID"`
 }
@@ -39,6 +82,8 @@ func Load() (*Config, error) {
 		FirebaseTokenURI:                "https://oauth2.googleapis.com/token",
 		FirebaseAuthProviderX509CertURL: "https://www.googleapis.com/oauth2/v1/certs",
 		FirestoreCollection:             "nps_responses",
+		LegalCorpusDir:                  "data/legal",
+		SQLitePath:                      "data/agoraai.db",
 	}
 
 	// Tentar ler env.yaml (tentar múltiplos caminhos)
@@ -76,6 +121,27 @@ func Load() (*Config, error) {
 		cfg.FirebaseAuthProviderX509CertURL = os.Getenv("FIREBASE_AUTH_PROVIDER_X509_CERT_URL")
 		cfg.FirebaseClientX509CertURL = os.Getenv("FIREBASE_CLIENT_X509_CERT_URL")
 		cfg.FirestoreCollection = os.Getenv("FIRESTORE_COLLECTION")
+		cfg.SenadoGovernmentLeader = os.Getenv("SENADO_LIDER_GOVERNO")
+		cfg.SMTPHost = os.Getenv("SMTP_HOST")
+		cfg.SMTPPort = os.Getenv("SMTP_PORT")
+		cfg.SMTPUsername = os.Getenv("SMTP_USERNAME")
+		cfg.SMTPPassword = os.Getenv("SMTP_PASSWORD")
+		cfg.SMTPFrom = os.Getenv("SMTP_FROM")
+		cfg.WatchlistWebhookSecret = os.Getenv("WATCHLIST_WEBHOOK_SECRET")
+		cfg.PublicBaseURL = os.Getenv("PUBLIC_BASE_URL")
+		cfg.LegalCorpusDir = os.Getenv("LEGAL_CORPUS_DIR")
+		cfg.LegalCorpusFiles = os.Getenv("LEGAL_CORPUS_FILES")
+		cfg.InlabsEmail = os.Getenv("INLABS_EMAIL")
+		cfg.InlabsPassword = os.Getenv("INLABS_PASSWORD")
+		cfg.DOUSections = os.Getenv("DOU_SECOES")
+		cfg.AdminClaim = os.Getenv("ADMIN_CLAIM")
+		cfg.AdminDevSecret = os.Getenv("ADMIN_DEV_SECRET")
+		cfg.NPSTokenSecret = os.Getenv("NPS_TOKEN_SECRET")
+		cfg.NPSRequireToken = os.Getenv("NPS_REQUIRE_TOKEN")
+		cfg.TrustedProxy = os.Getenv("TRUSTED_PROXY")
+		cfg.StorageBackend = os.Getenv("STORAGE_BACKEND")
+		cfg.SQLitePath = os.Getenv("SQLITE_PATH")
+		cfg.NPSOutboxDir = os.Getenv("NPS_OUTBOX_DIR")
 
 		// Valores padrão se não estiverem definidos
 		if cfg.Port == "" {
@@ -93,6 +159,12 @@ func Load() (*Config, error) {
 		if cfg.FirestoreCollection == "" {
 			cfg.FirestoreCollection = "nps_responses"
 		}
+		if cfg.LegalCorpusDir == "" {
+			cfg.LegalCorpusDir = "data/legal"
+		}
+		if cfg.SQLitePath == "" {
+			cfg.SQLitePath = "data/agoraai.db"
+		}
 	}
 
 	// Compatibilidade: se FIREBASE_PROJECT_ID não estiver definido, usar FIRESTORE_PROJECT_ID
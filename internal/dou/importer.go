@@ -0,0 +1,291 @@
+package dou
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// InlabsURL é o endereço do INLABS, que publica os pacotes diários do DOU para usuários cadastrados
+const InlabsURL = "https://inlabs.in.gov.br"
+
+// DefaultSections são as seções importadas por padrão: atos normativos (DO1) e
+// atos de pessoal, como nomeações e exonerações (DO2). A seção 3 traz contratos e editais.
+var DefaultSections = []string{"DO1", "DO2"}
+
+// ErrNotPublished indica que o pacote da seção ainda não foi publicado no dia
+var ErrNotPublished = errors.New("pacote do DOU ainda não publicado")
+
+// Importer lê os pacotes diários do DOU: baixa do INLABS quando há credenciais
+// e processa os arquivos deixados no diretório de entrada
+type Importer struct {
+	Store    *Store
+	Sections []string
+	// CacheDir guarda os pacotes já importados (um zip por seção, edição e dia)
+	CacheDir string
+	// InboxDir recebe arquivos .zip, .xml ou .json colocados manualmente; os
+	// arquivos processados são movidos para InboxDir/processados
+	InboxDir   string
+	Email      string
+	Password   string
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// ImportResult resume uma importação
+type ImportResult struct {
+	Files []string `json:"arquivos"`
+	Read  int      `json:"lidos"`
+	// Added são os atos novos, usados para os alertas das listas
+	Added []Act `json:"-"`
+}
+
+// Import baixa as seções do dia, processa o diretório de entrada e grava os
+// atos. Os atos novos são passados a notify antes de serem gravados: se notify
+// falhar, nada é gravado nem marcado como importado, e a próxima execução lê os
+// mesmos pacotes e notifica os mesmos atos de novo.
+func (im *Importer) Import(ctx context.Context, day time.Time, notify func(context.Context, []Act) error) (ImportResult, error) {
+	var result ImportResult
+	var files []string
+
+	var downloaded []download
+	if im.Email != "" && im.Password != "" {
+		var err error
+		downloaded, err = im.downloadDay(ctx, day)
+		if err != nil {
+			return result, err
+		}
+		for _, d := range downloaded {
+			files = append(files, d.pending)
+		}
+	}
+	inbox, err := im.inboxFiles()
+	if err != nil {
+		return result, err
+	}
+	files = append(files, inbox...)
+
+	var acts []Act
+	for _, path := range files {
+		parsed, err := ParseFile(path)
+		if err != nil {
+			return result, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		acts = append(acts, im.filterSections(parsed)...)
+		result.Files = append(result.Files, filepath.Base(path))
+	}
+	result.Read = len(acts)
+
+	result.Added = im.Store.Unseen(acts)
+	if notify != nil && len(result.Added) > 0 {
+		if err := notify(ctx, result.Added); err != nil {
+			return result, fmt.Errorf("falha ao notificar atos do DOU: %w", err)
+		}
+	}
+	if _, err := im.Store.Add(acts, time.Now().UTC()); err != nil {
+		return result, err
+	}
+
+	// Só agora os pacotes contam como importados
+	for _, d := range downloaded {
+		if err := os.Rename(d.pending, d.cached); err != nil {
+			log.Printf("⚠️  [DOU] não foi possível guardar %s: %v", filepath.Base(d.cached), err)
+		}
+	}
+	for _, path := range inbox {
+		im.archive(path)
+	}
+	if len(files) > 0 {
+		log.Printf("[DOU] %d arquivo(s), %d atos lidos, %d novos", len(files), result.Read, len(result.Added))
+	}
+	return result, nil
+}
+
+func (im *Importer) sections() []string {
+	if len(im.Sections) == 0 {
+		return DefaultSections
+	}
+	return im.Sections
+}
+
+// filterSections mantém as seções configuradas, incluindo as edições extras ("DO1E")
+func (im *Importer) filterSections(acts []Act) []Act {
+	kept := acts[:0]
+	for _, act := range acts {
+		for _, secao := range im.sections() {
+			if strings.HasPrefix(act.Secao, normalizeSecao(secao)) {
+				kept = append(kept, act)
+				break
+			}
+		}
+	}
+	return kept
+}
+
+func (im *Importer) inboxFiles() ([]string, error) {
+	if im.InboxDir == "" {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(im.InboxDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".zip", ".xml", ".json":
+			if !entry.IsDir() {
+				files = append(files, filepath.Join(im.InboxDir, entry.Name()))
+			}
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func (im *Importer) archive(path string) {
+	dir := filepath.Join(im.InboxDir, "processados")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.Printf("⚠️  [DOU] não foi possível arquivar %s: %v", filepath.Base(path), err)
+		return
+	}
+	if err := os.Rename(path, filepath.Join(dir, filepath.Base(path))); err != nil {
+		log.Printf("⚠️  [DOU] não foi possível arquivar %s: %v", filepath.Base(path), err)
+	}
+}
+
+func (im *Importer) baseURL() string {
+	if im.BaseURL != "" {
+		return strings.TrimSuffix(im.BaseURL, "/")
+	}
+	return InlabsURL
+}
+
+// login autentica no INLABS; a sessão fica no cookie inlabs_session_cookie
+func (im *Importer) login(ctx context.Context) (*http.Client, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: 5 * time.Minute, Jar: jar}
+	if im.HTTPClient != nil {
+		client.Timeout = im.HTTPClient.Timeout
+		client.Transport = im.HTTPClient.Transport
+	}
+
+	form := url.Values{"email": {im.Email}, "password": {im.Password}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, im.baseURL()+"/logar.php", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	base, _ := url.Parse(im.baseURL())
+	for _, cookie := range jar.Cookies(base) {
+		if cookie.Name == "inlabs_session_cookie" {
+			return client, nil
+		}
+	}
+	return nil, fmt.Errorf("login no INLABS recusado (status %d)", resp.StatusCode)
+}
+
+// download é um pacote baixado: fica em CacheDir/pendentes até os atos serem
+// gravados e então vai para CacheDir, o que marca a edição do dia como importada
+type download struct {
+	pending string
+	cached  string
+}
+
+// downloadDay baixa os pacotes das seções do dia, e das edições extras ("DO1E"),
+// que ainda não foram importados
+func (im *Importer) downloadDay(ctx context.Context, day time.Time) ([]download, error) {
+	date := day.Format("2006-01-02")
+	var client *http.Client
+	var files []download
+	for _, secao := range im.sections() {
+		secao = normalizeSecao(secao)
+		for _, edicao := range []string{secao, secao + "E"} {
+			fileName := fmt.Sprintf("%s-%s.zip", date, edicao)
+			cached := filepath.Join(im.CacheDir, fileName)
+			if info, err := os.Stat(cached); err == nil && info.Size() > 0 {
+				// Pacote já importado em uma execução anterior
+				continue
+			}
+			if client == nil {
+				var err error
+				if client, err = im.login(ctx); err != nil {
+					return nil, err
+				}
+			}
+			pending := filepath.Join(im.CacheDir, "pendentes", fileName)
+			err := im.download(ctx, client, fmt.Sprintf("%s/index.php?p=%s&dl=%s", im.baseURL(), date, fileName), pending)
+			if errors.Is(err, ErrNotPublished) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, download{pending: pending, cached: cached})
+		}
+	}
+	return files, nil
+}
+
+func (im *Importer) download(ctx context.Context, client *http.Client, rawURL, localPath string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	log.Printf("[DOU] baixando %s", filepath.Base(localPath))
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotPublished
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("INLABS retornou status %d para %s", resp.StatusCode, filepath.Base(localPath))
+	}
+	// Sem pacote no dia (fim de semana, feriado), o INLABS devolve uma página HTML
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		return ErrNotPublished
+	}
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0o755); err != nil {
+		return err
+	}
+	tempPath := localPath + ".tmp"
+	file, err := os.Create(tempPath)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		file.Close()
+		os.Remove(tempPath)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, localPath)
+}
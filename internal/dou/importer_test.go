@@ -0,0 +1,116 @@
+package dou
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeInlabs publica o pacote DO1 do dia com o ato de testdata; as demais
+// edições respondem com a página HTML de "sem publicação"
+func fakeInlabs(t *testing.T, requested *[]string) *httptest.Server {
+	t.Helper()
+	xml, err := os.ReadFile(filepath.Join("testdata", "515_20261016_23456789.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("515_20261016_23456789.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(xml)
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/logar.php":
+			http.SetCookie(w, &http.Cookie{Name: "inlabs_session_cookie", Value: "sessao", Path: "/"})
+		case "/index.php":
+			name := r.URL.Query().Get("dl")
+			*requested = append(*requested, name)
+			if name != "2026-10-16-DO1.zip" {
+				w.Header().Set("Content-Type", "text/html")
+				w.Write([]byte("<html>sem publicação</html>"))
+				return
+			}
+			w.Header().Set("Content-Type", "application/zip")
+			w.Write(buf.Bytes())
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestImportNotifiesBeforeSaving(t *testing.T) {
+	var requested []string
+	server := fakeInlabs(t, &requested)
+	defer server.Close()
+
+	dir := t.TempDir()
+	store, err := NewStore(filepath.Join(dir, "dou.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	im := &Importer{
+		Store:      store,
+		Sections:   []string{"DO1"},
+		CacheDir:   filepath.Join(dir, "cache"),
+		Email:      "equipe@exemplo.com",
+		Password:   "senha",
+		BaseURL:    server.URL,
+		HTTPClient: server.Client(),
+	}
+	day := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	cached := filepath.Join(im.CacheDir, "2026-10-16-DO1.zip")
+
+	// Com a notificação falhando, nada é gravado nem marcado como importado
+	failing := func(ctx context.Context, acts []Act) error { return errors.New("listas indisponíveis") }
+	if _, err := im.Import(context.Background(), day, failing); err == nil {
+		t.Fatal("esperava erro quando a notificação falha")
+	}
+	if store.Size() != 0 {
+		t.Errorf("atos gravados apesar da falha: %d", store.Size())
+	}
+	if _, err := os.Stat(cached); err == nil {
+		t.Errorf("pacote marcado como importado apesar da falha")
+	}
+
+	notified := 0
+	notify := func(ctx context.Context, acts []Act) error {
+		notified += len(acts)
+		return nil
+	}
+	result, err := im.Import(context.Background(), day, notify)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if notified != 1 || len(result.Added) != 1 || store.Size() != 1 {
+		t.Fatalf("esperava 1 ato notificado e gravado: notificados=%d novos=%d gravados=%d", notified, len(result.Added), store.Size())
+	}
+	if _, err := os.Stat(cached); err != nil {
+		t.Errorf("pacote não guardado depois da importação: %v", err)
+	}
+
+	// O pacote guardado não é baixado de novo, mas a edição extra continua sendo procurada
+	requested = nil
+	if _, err := im.Import(context.Background(), day, notify); err != nil {
+		t.Fatal(err)
+	}
+	if len(requested) != 1 || requested[0] != "2026-10-16-DO1E.zip" {
+		t.Errorf("downloads inesperados na nova execução: %v", requested)
+	}
+	if notified != 1 {
+		t.Errorf("ato notificado de novo: %d", notified)
+	}
+}
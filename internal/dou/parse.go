@@ -0,0 +1,324 @@
+// Package dou lê os atos publicados no Diário Oficial da União a partir dos
+// arquivos diários por seção (XML do INLABS ou JSON da leitura do jornal),
+// guarda os atos e permite filtrá-los por órgão, tipo e palavra-chave.
+package dou
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// Act é um ato publicado no DOU
+type Act struct {
+	ID         string `json:"id"`
+	Secao      string `json:"secao"`
+	Edicao     string `json:"edicao,omitempty"`
+	Data       string `json:"data"`
+	Tipo       string `json:"tipo"`
+	Orgao      string `json:"orgao"`
+	Identifica string `json:"identifica"`
+	Ementa     string `json:"ementa,omitempty"`
+	Texto      string `json:"texto"`
+	Pagina     string `json:"pagina,omitempty"`
+	URL        string `json:"url,omitempty"`
+}
+
+// Title devolve a identificação do ato ou, sem ela, o tipo e o órgão
+func (a Act) Title() string {
+	if a.Identifica != "" {
+		return a.Identifica
+	}
+	return strings.TrimSpace(a.Tipo + " - " + a.Orgao)
+}
+
+// Summary devolve a ementa ou o início do texto
+func (a Act) Summary(maxLen int) string {
+	if a.Ementa != "" {
+		return a.Ementa
+	}
+	return a.Excerpt(maxLen)
+}
+
+// Excerpt devolve o início do texto com no máximo maxLen caracteres
+func (a Act) Excerpt(maxLen int) string {
+	runes := []rune(a.Texto)
+	if len(runes) > maxLen {
+		return strings.TrimSpace(string(runes[:maxLen])) + "..."
+	}
+	return a.Texto
+}
+
+// inlabsArticle é o formato dos arquivos XML do INLABS (um ato por <article>)
+type inlabsArticle struct {
+	ID            string `xml:"id,attr"`
+	IDMateria     string `xml:"idMateria,attr"`
+	Name          string `xml:"name,attr"`
+	PubName       string `xml:"pubName,attr"`
+	ArtType       string `xml:"artType,attr"`
+	PubDate       string `xml:"pubDate,attr"`
+	ArtCategory   string `xml:"artCategory,attr"`
+	NumberPage    string `xml:"numberPage,attr"`
+	PDFPage       string `xml:"pdfPage,attr"`
+	EditionNumber string `xml:"editionNumber,attr"`
+	Body          struct {
+		Identifica string `xml:"Identifica"`
+		Ementa     string `xml:"Ementa"`
+		Titulo     string `xml:"Titulo"`
+		SubTitulo  string `xml:"SubTitulo"`
+		Texto      string `xml:"Texto"`
+	} `xml:"body"`
+}
+
+// leituraItem é o formato do JSON da leitura do jornal (in.gov.br/leiturajornal)
+type leituraItem struct {
+	PubName       string `json:"pubName"`
+	URLTitle      string `json:"urlTitle"`
+	NumberPage    string `json:"numberPage"`
+	Title         string `json:"title"`
+	Titulo        string `json:"titulo"`
+	PubDate       string `json:"pubDate"`
+	Content       string `json:"content"`
+	EditionNumber string `json:"editionNumber"`
+	ArtType       string `json:"artType"`
+	HierarchyStr  string `json:"hierarchyStr"`
+}
+
+// ParseXML lê um arquivo XML do INLABS; o arquivo pode ter um ou mais <article>
+func ParseXML(r io.Reader) ([]Act, error) {
+	decoder := xml.NewDecoder(r)
+	// Arquivos mais antigos declaram ISO-8859-1
+	decoder.CharsetReader = charset.NewReaderLabel
+
+	var acts []Act
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "article" {
+			continue
+		}
+		var article inlabsArticle
+		if err := decoder.DecodeElement(&article, &start); err != nil {
+			return nil, err
+		}
+		acts = append(acts, article.act())
+	}
+	if len(acts) == 0 {
+		return nil, fmt.Errorf("nenhum ato encontrado no XML")
+	}
+	return acts, nil
+}
+
+func (a inlabsArticle) act() Act {
+	act := Act{
+		Secao:      normalizeSecao(a.PubName),
+		Edicao:     strings.TrimSpace(a.EditionNumber),
+		Data:       normalizeDate(a.PubDate),
+		Tipo:       strings.TrimSpace(a.ArtType),
+		Orgao:      strings.TrimSpace(a.ArtCategory),
+		Identifica: cleanText(a.Body.Identifica),
+		Ementa:     cleanText(a.Body.Ementa),
+		Texto:      htmlText(a.Body.Texto),
+		Pagina:     strings.TrimSpace(a.NumberPage),
+		URL:        strings.TrimSpace(a.PDFPage),
+	}
+	if act.Identifica == "" {
+		act.Identifica = cleanText(a.Body.Titulo)
+	}
+	id := a.IDMateria
+	if id == "" {
+		id = a.ID
+	}
+	act.ID = actID(act, id)
+	return act
+}
+
+// ParseJSON lê o JSON da leitura do jornal: um objeto com "jsonArray" ou a lista de atos
+func ParseJSON(r io.Reader) ([]Act, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []leituraItem
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &items)
+	} else {
+		var wrapper struct {
+			JSONArray []leituraItem `json:"jsonArray"`
+		}
+		err = json.Unmarshal(trimmed, &wrapper)
+		items = wrapper.JSONArray
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("nenhum ato encontrado no JSON")
+	}
+
+	acts := make([]Act, 0, len(items))
+	for _, item := range items {
+		act := Act{
+			Secao:      normalizeSecao(item.PubName),
+			Edicao:     strings.TrimSpace(item.EditionNumber),
+			Data:       normalizeDate(item.PubDate),
+			Tipo:       strings.TrimSpace(item.ArtType),
+			Orgao:      strings.TrimSpace(item.HierarchyStr),
+			Identifica: cleanText(item.Title),
+			Texto:      htmlText(item.Content),
+			Pagina:     strings.TrimSpace(item.NumberPage),
+		}
+		if act.Identifica == "" {
+			act.Identifica = cleanText(item.Titulo)
+		}
+		if item.URLTitle != "" {
+			act.URL = "https://www.in.gov.br/web/dou/-/" + item.URLTitle
+		}
+		act.ID = actID(act, item.URLTitle)
+		acts = append(acts, act)
+	}
+	return acts, nil
+}
+
+// ParseFile lê um arquivo .xml, .json ou .zip (pacote diário do INLABS)
+func ParseFile(path string) ([]Act, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".zip":
+		return parseZip(path)
+	case ".xml", ".json":
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return parseEntry(filepath.Base(path), file)
+	}
+	return nil, fmt.Errorf("formato não suportado: %s", filepath.Base(path))
+}
+
+func parseEntry(name string, r io.Reader) ([]Act, error) {
+	if strings.EqualFold(filepath.Ext(name), ".json") {
+		return ParseJSON(r)
+	}
+	return ParseXML(r)
+}
+
+func parseZip(path string) ([]Act, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	var acts []Act
+	for _, file := range archive.File {
+		ext := strings.ToLower(filepath.Ext(file.Name))
+		if ext != ".xml" && ext != ".json" {
+			// O pacote também traz as imagens dos atos
+			continue
+		}
+		content, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := parseEntry(file.Name, content)
+		content.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file.Name, err)
+		}
+		acts = append(acts, parsed...)
+	}
+	if len(acts) == 0 {
+		return nil, fmt.Errorf("nenhum ato encontrado em %s", filepath.Base(path))
+	}
+	return acts, nil
+}
+
+// normalizeSecao converte "DO1", "do1E" e "1" em "DO1"/"DO1E"
+func normalizeSecao(raw string) string {
+	secao := strings.ToUpper(strings.TrimSpace(raw))
+	if secao != "" && !strings.HasPrefix(secao, "DO") {
+		secao = "DO" + secao
+	}
+	return secao
+}
+
+// normalizeDate converte "18/10/2026" para "2026-10-18"
+func normalizeDate(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if t, err := time.Parse("02/01/2006", raw); err == nil {
+		return t.Format("2006-01-02")
+	}
+	if t, err := time.Parse("2006-01-02", raw); err == nil {
+		return t.Format("2006-01-02")
+	}
+	return raw
+}
+
+// actID usa o identificador da publicação; sem ele, um hash do conteúdo
+func actID(act Act, source string) string {
+	source = strings.TrimSpace(source)
+	if source == "" {
+		sum := sha1.Sum([]byte(act.Data + "|" + act.Secao + "|" + act.Identifica + "|" + act.Texto))
+		source = hex.EncodeToString(sum[:8])
+	}
+	return act.Data + "-" + source
+}
+
+func cleanText(s string) string {
+	return strings.Join(strings.Fields(htmlText(s)), " ")
+}
+
+// blockTags encerram um parágrafo do texto do ato
+var blockTags = map[string]bool{"p": true, "br": true, "div": true, "tr": true, "li": true, "table": true}
+
+// htmlText converte o HTML do ato em texto, um parágrafo por linha
+func htmlText(s string) string {
+	if !strings.ContainsRune(s, '<') {
+		return strings.Join(strings.Fields(html.UnescapeString(s)), " ")
+	}
+
+	tokenizer := html.NewTokenizer(strings.NewReader(s))
+	var paragraphs []string
+	var current strings.Builder
+	flush := func() {
+		if line := strings.Join(strings.Fields(current.String()), " "); line != "" {
+			paragraphs = append(paragraphs, line)
+		}
+		current.Reset()
+	}
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			flush()
+			return strings.Join(paragraphs, "\n")
+		case html.TextToken:
+			current.Write(tokenizer.Text())
+			current.WriteByte(' ')
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			name, _ := tokenizer.TagName()
+			if blockTags[string(name)] {
+				flush()
+			}
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package dou
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseXML(t *testing.T) {
+	acts, err := ParseFile(filepath.Join("testdata", "515_20261016_23456789.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(acts) != 1 {
+		t.Fatalf("esperava 1 ato, obteve %d", len(acts))
+	}
+	act := acts[0]
+	checks := map[string][2]string{
+		"ID":         {act.ID, "2026-10-16-61234567"},
+		"Secao":      {act.Secao, "DO1"},
+		"Data":       {act.Data, "2026-10-16"},
+		"Tipo":       {act.Tipo, "Decreto"},
+		"Orgao":      {act.Orgao, "Atos do Poder Executivo"},
+		"Identifica": {act.Identifica, "DECRETO Nº 12.701, DE 15 DE OUTUBRO DE 2026"},
+		"Edicao":     {act.Edicao, "198"},
+		"Pagina":     {act.Pagina, "1"},
+	}
+	for field, got := range checks {
+		if got[0] != got[1] {
+			t.Errorf("%s = %q, esperava %q", field, got[0], got[1])
+		}
+	}
+	if !strings.HasPrefix(act.Ementa, "Regulamenta o Programa Nacional de Imunizações") {
+		t.Errorf("ementa inesperada: %q", act.Ementa)
+	}
+	// Um parágrafo por linha, sem as tags e com &nbsp; convertido
+	if !strings.Contains(act.Texto, "\nArt. 1º Fica instituída a campanha nacional de vacinação contra a gripe.\n") {
+		t.Errorf("texto inesperado: %q", act.Texto)
+	}
+	if strings.Contains(act.Texto, "<p") {
+		t.Errorf("texto ainda contém HTML: %q", act.Texto)
+	}
+}
+
+func TestParseXMLLatin1(t *testing.T) {
+	acts, err := ParseFile(filepath.Join("testdata", "529_20261016_23456790.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	act := acts[0]
+	if act.Secao != "DO2" || act.Orgao != "Ministério da Saúde/Gabinete do Ministro" {
+		t.Errorf("seção/órgão inesperados: %q %q", act.Secao, act.Orgao)
+	}
+	if act.Ementa != "" || !strings.Contains(act.Texto, "Nomear MARIA DA SILVA") {
+		t.Errorf("texto inesperado: %q", act.Texto)
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	acts, err := ParseFile(filepath.Join("testdata", "leiturajornal_do1_2026-10-16.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(acts) != 2 {
+		t.Fatalf("esperava 2 atos, obteve %d", len(acts))
+	}
+	if acts[0].Tipo != "Resolução" || acts[0].Orgao != "Ministério da Fazenda/Conselho Monetário Nacional" {
+		t.Errorf("ato inesperado: %+v", acts[0])
+	}
+	if acts[0].URL != "https://www.in.gov.br/web/dou/-/resolucao-n-5-de-14-de-outubro-de-2026-612345" {
+		t.Errorf("URL inesperada: %q", acts[0].URL)
+	}
+	if acts[1].Secao != "DO1E" || acts[1].Edicao != "198-A" {
+		t.Errorf("edição extra inesperada: %q %q", acts[1].Secao, acts[1].Edicao)
+	}
+	if acts[0].ID == acts[1].ID {
+		t.Errorf("IDs repetidos: %q", acts[0].ID)
+	}
+}
+
+func TestParseZip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "2026-10-16-DO1.zip")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := zip.NewWriter(file)
+	for _, name := range []string{"515_20261016_23456789.xml", "529_20261016_23456790.xml"} {
+		content, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		entry, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entry.Write(content)
+	}
+	// Imagens do pacote são ignoradas
+	image, _ := archive.Create("23456789.jpg")
+	image.Write([]byte{0xff, 0xd8})
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	acts, err := ParseFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(acts) != 2 {
+		t.Fatalf("esperava 2 atos, obteve %d", len(acts))
+	}
+}
+
+func TestFilter(t *testing.T) {
+	var acts []Act
+	for _, name := range []string{"515_20261016_23456789.xml", "529_20261016_23456790.xml", "leiturajornal_do1_2026-10-16.json"} {
+		parsed, err := ParseFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		acts = append(acts, parsed...)
+	}
+
+	store, err := NewStore(filepath.Join(t.TempDir(), "dou.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ingestedAt := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	added, err := store.Add(acts, ingestedAt)
+	if err != nil || len(added) != 4 {
+		t.Fatalf("Add: %d novos, erro %v", len(added), err)
+	}
+	// Reimportar o mesmo pacote não gera atos novos
+	if added, _ := store.Add(acts, ingestedAt); len(added) != 0 {
+		t.Errorf("reimportação gerou %d atos novos", len(added))
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   int
+	}{
+		{"palavra sem acento", Filter{Keywords: []string{"vacinacao"}}, 2},
+		{"palavra inteira", Filter{Keywords: []string{"vacina"}}, 0},
+		{"expressão", Filter{Keywords: []string{"maria da silva"}}, 1},
+		{"órgão por trecho", Filter{Orgao: "saude"}, 1},
+		{"tipo", Filter{Tipo: "decreto"}, 1},
+		{"seção", Filter{Secao: "2"}, 1},
+		{"data", Filter{From: "2026-10-17"}, 0},
+		{"todas as palavras", Filter{Keywords: []string{"vacinação", "gripe"}}, 1},
+		{"limite", Filter{Limit: 3}, 3},
+	}
+	for _, tt := range tests {
+		if got := store.Search(tt.filter); len(got) != tt.want {
+			t.Errorf("%s: %d atos, esperava %d", tt.name, len(got), tt.want)
+		}
+	}
+
+	reloaded, err := NewStore(store.filePath)
+	if err != nil || reloaded.Size() != 4 || reloaded.LatestDate() != "2026-10-16" {
+		t.Errorf("recarga: %d atos, data %q, erro %v", reloaded.Size(), reloaded.LatestDate(), err)
+	}
+}
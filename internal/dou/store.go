@@ -0,0 +1,276 @@
+package dou
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"chat-bot/internal/textnorm"
+)
+
+// retention define por quanto tempo os atos ficam guardados
+const retention = 90 * 24 * time.Hour
+
+// Filter seleciona atos por data, seção, órgão, tipo e palavras-chave
+type Filter struct {
+	// From e To no formato AAAA-MM-DD, inclusivos
+	From  string
+	To    string
+	Secao string
+	// Orgao e Tipo comparam sem acentos e aceitam trechos ("saude" encontra "Ministério da Saúde")
+	Orgao string
+	Tipo  string
+	// Keywords: o ato precisa conter todas as palavras (ou expressões) informadas
+	Keywords []string
+	Limit    int
+}
+
+func contains(haystack, needle string) bool {
+	needle = strings.TrimSpace(needle)
+	return needle == "" || strings.Contains(textnorm.Fold(haystack), textnorm.Fold(needle))
+}
+
+// searchText é o texto do ato normalizado para a busca por palavras inteiras
+func (a Act) searchText() string {
+	return " " + strings.Join(textnorm.Tokens(a.Identifica+" "+a.Ementa+" "+a.Orgao+" "+a.Texto), " ") + " "
+}
+
+func hasKeyword(text, keyword string) bool {
+	keyword = strings.Join(textnorm.Tokens(keyword), " ")
+	return keyword != "" && strings.Contains(text, " "+keyword+" ")
+}
+
+// MatchingKeywords devolve as palavras-chave (ou expressões) encontradas na
+// identificação, ementa ou texto do ato, sem diferenciar acentos e maiúsculas
+func (a Act) MatchingKeywords(keywords []string) []string {
+	if len(keywords) == 0 {
+		return nil
+	}
+	text := a.searchText()
+	var found []string
+	for _, keyword := range keywords {
+		if hasKeyword(text, keyword) {
+			found = append(found, keyword)
+		}
+	}
+	return found
+}
+
+// Matches aplica o filtro ao ato
+func (f Filter) Matches(a Act) bool {
+	if f.From != "" && a.Data < f.From {
+		return false
+	}
+	if f.To != "" && a.Data > f.To {
+		return false
+	}
+	if f.Secao != "" && !strings.EqualFold(a.Secao, normalizeSecao(f.Secao)) {
+		return false
+	}
+	if !contains(a.Orgao, f.Orgao) || !contains(a.Tipo, f.Tipo) {
+		return false
+	}
+	return len(a.MatchingKeywords(f.Keywords)) == len(f.Keywords)
+}
+
+// Store guarda os atos em um arquivo JSON local
+type Store struct {
+	filePath   string
+	acts       map[string]Act
+	lastIngest time.Time
+	mutex      sync.RWMutex
+}
+
+type storeFile struct {
+	LastIngest time.Time `json:"lastIngest"`
+	Acts       []Act     `json:"acts"`
+}
+
+// NewStore cria o armazenamento e carrega o arquivo local, se existir
+func NewStore(filePath string) (*Store, error) {
+	s := &Store{filePath: filePath, acts: map[string]Act{}}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) load() error {
+	file, err := os.Open(s.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+	return s.decode(file)
+}
+
+func (s *Store) decode(reader io.Reader) error {
+	var data storeFile
+	if err := json.NewDecoder(reader).Decode(&data); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+
+	for _, act := range data.Acts {
+		s.acts[act.ID] = act
+	}
+	s.lastIngest = data.LastIngest
+	return nil
+}
+
+func (s *Store) saveLocked() error {
+	if dir := filepath.Dir(s.filePath); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	data := storeFile{LastIngest: s.lastIngest, Acts: s.sortedLocked()}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	tempPath := s.filePath + ".tmp"
+	if err := os.WriteFile(tempPath, payload, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, s.filePath)
+}
+
+// Restore substitui o conteúdo pelo arquivo publicado por outra instância
+// (a líder do agendador) e o grava no arquivo local
+func (s *Store) Restore(payload []byte) error {
+	fresh := &Store{acts: map[string]Act{}}
+	if err := fresh.decode(bytes.NewReader(payload)); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.acts, s.lastIngest = fresh.acts, fresh.lastIngest
+	return s.saveLocked()
+}
+
+// sortedLocked ordena do ato mais recente para o mais antigo
+func (s *Store) sortedLocked() []Act {
+	items := make([]Act, 0, len(s.acts))
+	for _, act := range s.acts {
+		items = append(items, act)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Data != items[j].Data {
+			return items[i].Data > items[j].Data
+		}
+		return items[i].ID < items[j].ID
+	})
+	return items
+}
+
+// Unseen retorna os atos que ainda não estão guardados, sem repetições
+func (s *Store) Unseen(acts []Act) []Act {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var unseen []Act
+	seen := map[string]bool{}
+	for _, act := range acts {
+		if _, ok := s.acts[act.ID]; ok || seen[act.ID] {
+			continue
+		}
+		seen[act.ID] = true
+		unseen = append(unseen, act)
+	}
+	return unseen
+}
+
+// Add grava os atos e retorna apenas os que ainda não estavam guardados, para
+// que cada ato gere alertas uma única vez
+func (s *Store) Add(acts []Act, ingestedAt time.Time) ([]Act, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var added []Act
+	for _, act := range acts {
+		if _, ok := s.acts[act.ID]; ok {
+			continue
+		}
+		s.acts[act.ID] = act
+		added = append(added, act)
+	}
+
+	cutoff := ingestedAt.Add(-retention).Format("2006-01-02")
+	for id, act := range s.acts {
+		if act.Data < cutoff {
+			delete(s.acts, id)
+		}
+	}
+	s.lastIngest = ingestedAt
+	return added, s.saveLocked()
+}
+
+// Search devolve os atos que atendem ao filtro, do mais recente para o mais antigo
+func (s *Store) Search(f Filter) []Act {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	result := []Act{}
+	for _, act := range s.sortedLocked() {
+		if !f.Matches(act) {
+			continue
+		}
+		result = append(result, act)
+		if f.Limit > 0 && len(result) == f.Limit {
+			break
+		}
+	}
+	return result
+}
+
+// Get devolve o ato pelo ID
+func (s *Store) Get(id string) (Act, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	act, ok := s.acts[id]
+	return act, ok
+}
+
+// LatestDate devolve a data da edição mais recente guardada
+func (s *Store) LatestDate() string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	latest := ""
+	for _, act := range s.acts {
+		if act.Data > latest {
+			latest = act.Data
+		}
+	}
+	return latest
+}
+
+// LastIngest devolve quando os atos foram importados pela última vez
+func (s *Store) LastIngest() time.Time {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.lastIngest
+}
+
+// Size devolve o número de atos guardados
+func (s *Store) Size() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.acts)
+}
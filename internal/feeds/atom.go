@@ -0,0 +1,179 @@
+// Package feeds gera feeds Atom (RFC 4287) a partir dos dados legislativos ingeridos.
+package feeds
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ContentType é o tipo MIME dos feeds Atom
+const ContentType = "application/atom+xml; charset=utf-8"
+
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+// brasilia é o fuso das datas publicadas pelas APIs (sem horário de verão desde 2019)
+var brasilia = time.FixedZone("BRT", -3*60*60)
+
+// Feed é um feed Atom
+type Feed struct {
+	XMLName  xml.Name `xml:"feed"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	ID       string   `xml:"id"`
+	Title    string   `xml:"title"`
+	Subtitle string   `xml:"subtitle,omitempty"`
+	Updated  string   `xml:"updated"`
+	Author   Person   `xml:"author"`
+	Links    []Link   `xml:"link"`
+	Entries  []Entry  `xml:"entry"`
+
+	updated time.Time
+}
+
+// Person identifica o autor do feed
+type Person struct {
+	Name string `xml:"name"`
+	URI  string `xml:"uri,omitempty"`
+}
+
+// Link é um link Atom
+type Link struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+// Text é um elemento de texto com tipo
+type Text struct {
+	Type string `xml:"type,attr,omitempty"`
+	Body string `xml:",chardata"`
+}
+
+// Entry é um item do feed
+type Entry struct {
+	ID         string     `xml:"id"`
+	Title      string     `xml:"title"`
+	Updated    string     `xml:"updated"`
+	Links      []Link     `xml:"link,omitempty"`
+	Categories []Category `xml:"category,omitempty"`
+	Summary    *Text      `xml:"summary,omitempty"`
+
+	updated time.Time
+	seen    time.Time
+}
+
+// Category classifica um item
+type Category struct {
+	Term string `xml:"term,attr"`
+}
+
+// New cria um feed vazio. O ID deve ser estável entre as gerações do feed.
+func New(id, title, selfURL, alternateURL string) *Feed {
+	f := &Feed{
+		Xmlns:  atomNamespace,
+		ID:     id,
+		Title:  title,
+		Author: Person{Name: "PoliticianInsight"},
+		Links:  []Link{{Href: selfURL, Rel: "self", Type: "application/atom+xml"}},
+	}
+	if alternateURL != "" {
+		f.Links = append(f.Links, Link{Href: alternateURL, Rel: "alternate", Type: "text/html"})
+	}
+	return f
+}
+
+// Add inclui um item no feed. updated é a data do item na fonte, que ordena o
+// feed; seen é quando ele foi ingerido. As datas das APIs costumam não ter
+// horário, então um item novo pode chegar com data igual ou anterior à do
+// item mais recente, e seen é o que faz o Last-Modified avançar.
+func (f *Feed) Add(id, title, link, summary string, updated, seen time.Time, categories ...string) {
+	entry := Entry{
+		ID:      id,
+		Title:   title,
+		Updated: updated.UTC().Format(time.RFC3339),
+		updated: updated,
+		seen:    seen,
+	}
+	if link != "" {
+		entry.Links = []Link{{Href: link, Rel: "alternate"}}
+	}
+	if summary = strings.TrimSpace(summary); summary != "" {
+		entry.Summary = &Text{Type: "text", Body: summary}
+	}
+	for _, c := range categories {
+		if c != "" {
+			entry.Categories = append(entry.Categories, Category{Term: c})
+		}
+	}
+	f.Entries = append(f.Entries, entry)
+}
+
+// LastModified retorna a data do feed calculada por Render
+func (f *Feed) LastModified() time.Time {
+	return f.updated
+}
+
+// Render ordena os itens do mais recente para o mais antigo, mantém até limit
+// itens e gera o XML. A data do feed é a maior entre as datas e os horários de
+// ingestão dos itens mantidos ou, sem itens, fallback; assim o conteúdo só
+// muda quando os dados mudam.
+func (f *Feed) Render(limit int, fallback time.Time) ([]byte, error) {
+	sort.SliceStable(f.Entries, func(i, j int) bool {
+		if !f.Entries[i].updated.Equal(f.Entries[j].updated) {
+			return f.Entries[i].updated.After(f.Entries[j].updated)
+		}
+		return f.Entries[i].ID < f.Entries[j].ID
+	})
+	if limit > 0 && len(f.Entries) > limit {
+		f.Entries = f.Entries[:limit]
+	}
+
+	f.updated = fallback
+	if len(f.Entries) > 0 {
+		f.updated = time.Time{}
+		for _, e := range f.Entries {
+			for _, t := range []time.Time{e.updated, e.seen} {
+				if t.After(f.updated) {
+					f.updated = t
+				}
+			}
+		}
+	}
+	f.updated = f.updated.UTC().Truncate(time.Second)
+	f.Updated = f.updated.Format(time.RFC3339)
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(f); err != nil {
+		return nil, err
+	}
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// ETag calcula a validação forte do conteúdo gerado
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// ParseTime interpreta as datas das APIs da Câmara e do Senado ("2024-10-10",
+// "2024-10-10T14:30" ou com segundos e fuso), no horário de Brasília
+func ParseTime(value string) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, true
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, brasilia); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
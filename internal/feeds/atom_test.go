@@ -0,0 +1,103 @@
+package feeds
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderOrdersAndLimits(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 0, 0, 0, 0, brasilia) }
+	f := New("urn:teste", "Teste", "https://exemplo/feed", "")
+	f.Add("b", "B", "", "", day(10), time.Time{})
+	f.Add("c", "C", "", "", day(12), time.Time{})
+	f.Add("a", "A", "", "", day(10), time.Time{})
+
+	if _, err := f.Render(2, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, e := range f.Entries {
+		ids = append(ids, e.ID)
+	}
+	if got := strings.Join(ids, ","); got != "c,a" {
+		t.Errorf("itens = %s, esperava c,a", got)
+	}
+}
+
+func TestRenderLastModified(t *testing.T) {
+	votedOn := time.Date(2026, 10, 15, 0, 0, 0, 0, brasilia)
+	firstIngest := time.Date(2026, 10, 15, 20, 0, 0, 0, time.UTC)
+	laterIngest := time.Date(2026, 10, 16, 9, 30, 0, 500, time.UTC)
+	fallback := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		setup func(f *Feed)
+		want  time.Time
+	}{
+		{name: "sem itens usa o fallback", setup: func(*Feed) {}, want: fallback},
+		{name: "sem ingestão usa a data do item", setup: func(f *Feed) {
+			f.Add("a", "A", "", "", votedOn, time.Time{})
+		}, want: votedOn},
+		{name: "ingestão posterior à data", setup: func(f *Feed) {
+			f.Add("a", "A", "", "", votedOn, firstIngest)
+		}, want: firstIngest},
+		{name: "item novo com a mesma data avança", setup: func(f *Feed) {
+			f.Add("a", "A", "", "", votedOn, firstIngest)
+			f.Add("b", "B", "", "", votedOn, laterIngest)
+		}, want: laterIngest.Truncate(time.Second)},
+		{name: "item novo com data anterior avança", setup: func(f *Feed) {
+			f.Add("a", "A", "", "", votedOn, firstIngest)
+			f.Add("b", "B", "", "", votedOn.AddDate(0, 0, -3), laterIngest)
+		}, want: laterIngest.Truncate(time.Second)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := New("urn:teste", "Teste", "https://exemplo/feed", "")
+			tt.setup(f)
+			body, err := f.Render(10, fallback)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := f.LastModified(); !got.Equal(tt.want) {
+				t.Errorf("LastModified() = %v, esperava %v", got, tt.want)
+			}
+			if !strings.Contains(string(body), "<updated>"+tt.want.UTC().Format(time.RFC3339)+"</updated>") {
+				t.Errorf("o <updated> do feed não acompanha o Last-Modified:\n%s", body)
+			}
+		})
+	}
+}
+
+func TestETag(t *testing.T) {
+	a, b := ETag([]byte("feed")), ETag([]byte("feed"))
+	if a != b {
+		t.Errorf("ETag instável: %s != %s", a, b)
+	}
+	if !strings.HasPrefix(a, `"`) || !strings.HasSuffix(a, `"`) {
+		t.Errorf("ETag forte deve vir entre aspas: %s", a)
+	}
+	if ETag([]byte("feed2")) == a {
+		t.Error("conteúdos diferentes deveriam ter ETags diferentes")
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Time
+		ok    bool
+	}{
+		{value: "2024-10-10", want: time.Date(2024, 10, 10, 3, 0, 0, 0, time.UTC), ok: true},
+		{value: "2024-10-10T14:30", want: time.Date(2024, 10, 10, 17, 30, 0, 0, time.UTC), ok: true},
+		{value: "2024-10-10T14:30:00-03:00", want: time.Date(2024, 10, 10, 17, 30, 0, 0, time.UTC), ok: true},
+		{value: "10/10/2024"},
+	}
+	for _, tt := range tests {
+		got, ok := ParseTime(tt.value)
+		if ok != tt.ok || (ok && !got.Equal(tt.want)) {
+			t.Errorf("ParseTime(%q) = %v, %v; esperava %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
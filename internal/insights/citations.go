@@ -0,0 +1,37 @@
+package insights
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var urlPattern = regexp.MustCompile(`https?://[^\s)\]>"']+`)
+
+// officialSuffixes são os domínios considerados fontes oficiais
+var officialSuffixes = []string{".gov.br", ".leg.br", ".jus.br", ".mp.br", ".def.br"}
+
+// IsOfficialURL informa se o endereço pertence a um domínio oficial brasileiro
+func IsOfficialURL(raw string) bool {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(parsed.Hostname())
+	for _, suffix := range officialSuffixes {
+		if host == strings.TrimPrefix(suffix, ".") || strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasOfficialCitation informa se a resposta traz pelo menos um link para fonte oficial
+func HasOfficialCitation(text string) bool {
+	for _, match := range urlPattern.FindAllString(text, -1) {
+		if IsOfficialURL(strings.TrimRight(match, ".,;:")) {
+			return true
+		}
+	}
+	return false
+}
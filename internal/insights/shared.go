@@ -0,0 +1,170 @@
+package insights
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+// sharedWriteTimeout limita cada gravação no armazenamento compartilhado
+const sharedWriteTimeout = 30 * time.Second
+
+// followDays é quantos dias Refresh lê: a janela do índice de transparência
+// e o instantâneo da semana anterior cabem nela
+const followDays = 31
+
+// Shared guarda as contagens e os instantâneos fora da instância. Cada
+// instância soma nele as respostas que contou; o painel lê os totais de todas.
+type Shared interface {
+	// Add soma as contagens ao total de cada dia
+	Add(ctx context.Context, counts map[string]AnswerCounts) error
+	// PutSnapshot grava (ou substitui) o instantâneo do dia
+	PutSnapshot(ctx context.Context, day string, snapshot Snapshot) error
+	// Load retorna os totais e os instantâneos a partir do dia since (AAAA-MM-DD)
+	Load(ctx context.Context, since string) (map[string]AnswerCounts, map[string]Snapshot, error)
+}
+
+// FirestoreShared guarda um documento por dia, com os totais somados via
+// incremento atômico e o instantâneo em JSON
+type FirestoreShared struct {
+	client     *firestore.Client
+	collection string
+}
+
+type sharedDay struct {
+	Day      string `firestore:"day"`
+	Answers  int    `firestore:"answers"`
+	Cited    int    `firestore:"cited"`
+	Snapshot string `firestore:"snapshot"`
+}
+
+// NewFirestoreShared usa a coleção informada
+func NewFirestoreShared(client *firestore.Client, collection string) *FirestoreShared {
+	return &FirestoreShared{client: client, collection: collection}
+}
+
+func (f *FirestoreShared) Add(ctx context.Context, counts map[string]AnswerCounts) error {
+	// Em uma transação, para que uma falha não deixe parte dos dias somada e a
+	// nova tentativa não conte duas vezes
+	return f.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		for day, c := range counts {
+			err := tx.Set(f.client.Collection(f.collection).Doc(day), map[string]interface{}{
+				"day":     day,
+				"answers": firestore.Increment(c.Answers),
+				"cited":   firestore.Increment(c.Cited),
+			}, firestore.MergeAll)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (f *FirestoreShared) PutSnapshot(ctx context.Context, day string, snapshot Snapshot) error {
+	payload, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	_, err = f.client.Collection(f.collection).Doc(day).Set(ctx, map[string]interface{}{
+		"day":      day,
+		"snapshot": string(payload),
+	}, firestore.MergeAll)
+	return err
+}
+
+func (f *FirestoreShared) Load(ctx context.Context, since string) (map[string]AnswerCounts, map[string]Snapshot, error) {
+	iter := f.client.Collection(f.collection).Where("day", ">=", since).Documents(ctx)
+	defer iter.Stop()
+
+	answers := map[string]AnswerCounts{}
+	snapshots := map[string]Snapshot{}
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		var data sharedDay
+		if err := doc.DataTo(&data); err != nil {
+			return nil, nil, err
+		}
+		answers[data.Day] = AnswerCounts{Answers: data.Answers, Cited: data.Cited}
+		if data.Snapshot == "" {
+			continue
+		}
+		var snapshot Snapshot
+		if err := json.Unmarshal([]byte(data.Snapshot), &snapshot); err != nil {
+			log.Printf("⚠️  [INSIGHTS] instantâneo compartilhado de %s ignorado: %v", data.Day, err)
+			continue
+		}
+		snapshots[data.Day] = snapshot
+	}
+	return answers, snapshots, nil
+}
+
+// SetShared liga o armazenamento compartilhado; as contagens passam a ser
+// somadas nele por Flush e Follow passa a trazer os totais de todas as instâncias
+func (s *Store) SetShared(shared Shared) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.shared = shared
+}
+
+// Refresh substitui os totais dos últimos dias pelos do armazenamento
+// compartilhado, somando as contagens locais ainda não enviadas
+func (s *Store) Refresh(ctx context.Context, now time.Time) error {
+	s.mutex.RLock()
+	shared := s.shared
+	s.mutex.RUnlock()
+	if shared == nil {
+		return nil
+	}
+
+	since := now.UTC().AddDate(0, 0, -followDays).Format(dayLayout)
+	answers, snapshots, err := shared.Load(ctx, since)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for day, counts := range answers {
+		pending := s.pending[day]
+		counts.Answers += pending.Answers
+		counts.Cited += pending.Cited
+		s.answers[day] = counts
+	}
+	for day, snapshot := range snapshots {
+		s.snapshots[day] = snapshot
+	}
+	s.pruneLocked(now)
+	return s.saveLocked()
+}
+
+// Follow executa Refresh periodicamente até o contexto ser cancelado
+func (s *Store) Follow(ctx context.Context, interval time.Duration) {
+	refresh := func() {
+		if err := s.Refresh(ctx, time.Now()); err != nil {
+			log.Printf("⚠️  [INSIGHTS] falha ao ler o armazenamento compartilhado: %v", err)
+		}
+	}
+	refresh()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			refresh()
+		}
+	}
+}
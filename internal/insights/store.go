@@ -0,0 +1,264 @@
+// Package insights guarda os contadores e os instantâneos diários usados nas
+// métricas do painel de insights.
+package insights
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	dayLayout     = "2006-01-02"
+	retentionDays = 120
+)
+
+// AnswerCounts conta as respostas do chat em um dia
+type AnswerCounts struct {
+	Answers int `json:"answers"`
+	Cited   int `json:"cited"`
+}
+
+// Snapshot registra o valor das métricas ao fim de um dia
+type Snapshot struct {
+	// BillsTracked conta as proposições acompanhadas (sincronizadas ou
+	// seguidas por listas)
+	BillsTracked      *int      `json:"billsTracked,omitempty"`
+	TransparencyIndex *float64  `json:"transparencyIndex,omitempty"`
+	ActiveAlerts      *int      `json:"activeAlerts,omitempty"`
+	TakenAt           time.Time `json:"takenAt"`
+}
+
+// Store guarda contadores e instantâneos diários em um arquivo JSON local.
+// RecordAnswer só altera a memória; o arquivo é regravado por Flush, chamado
+// periodicamente por Run e no encerramento do servidor. Com um armazenamento
+// compartilhado (SetShared), Flush também soma nele as contagens da instância.
+type Store struct {
+	filePath  string
+	answers   map[string]AnswerCounts
+	snapshots map[string]Snapshot
+	// dirty indica contagens ainda não gravadas no arquivo
+	dirty  bool
+	shared Shared
+	// pending são as contagens ainda não somadas no armazenamento compartilhado
+	pending map[string]AnswerCounts
+	mutex   sync.RWMutex
+}
+
+type storeFile struct {
+	Answers   map[string]AnswerCounts `json:"answers"`
+	Snapshots map[string]Snapshot     `json:"snapshots"`
+}
+
+// NewStore cria o armazenamento e carrega o arquivo local, se existir
+func NewStore(filePath string) (*Store, error) {
+	s := &Store{
+		filePath:  filePath,
+		answers:   map[string]AnswerCounts{},
+		snapshots: map[string]Snapshot{},
+		pending:   map[string]AnswerCounts{},
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) load() error {
+	file, err := os.Open(s.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	var data storeFile
+	if err := json.NewDecoder(file).Decode(&data); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+	if data.Answers != nil {
+		s.answers = data.Answers
+	}
+	if data.Snapshots != nil {
+		s.snapshots = data.Snapshots
+	}
+	return nil
+}
+
+func (s *Store) saveLocked() error {
+	if dir := filepath.Dir(s.filePath); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	payload, err := json.Marshal(storeFile{Answers: s.answers, Snapshots: s.snapshots})
+	if err != nil {
+		return err
+	}
+
+	tempPath := s.filePath + ".tmp"
+	if err := os.WriteFile(tempPath, payload, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, s.filePath)
+}
+
+func (s *Store) pruneLocked(now time.Time) {
+	cutoff := now.UTC().AddDate(0, 0, -retentionDays).Format(dayLayout)
+	for day := range s.answers {
+		if day < cutoff {
+			delete(s.answers, day)
+		}
+	}
+	for day := range s.snapshots {
+		if day < cutoff {
+			delete(s.snapshots, day)
+		}
+	}
+}
+
+// RecordAnswer contabiliza uma resposta do chat e se ela citou fonte oficial
+func (s *Store) RecordAnswer(at time.Time, cited bool) {
+	day := at.UTC().Format(dayLayout)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.answers[day] = addAnswer(s.answers[day], cited)
+	if s.shared != nil {
+		s.pending[day] = addAnswer(s.pending[day], cited)
+	}
+	s.pruneLocked(at)
+	s.dirty = true
+}
+
+func addAnswer(counts AnswerCounts, cited bool) AnswerCounts {
+	counts.Answers++
+	if cited {
+		counts.Cited++
+	}
+	return counts
+}
+
+// Flush soma as contagens pendentes no armazenamento compartilhado, se houver,
+// e grava o arquivo se houver contagens novas desde a última gravação. Se a
+// soma falhar, as contagens continuam pendentes para a próxima chamada.
+func (s *Store) Flush() error {
+	s.mutex.Lock()
+	if !s.dirty {
+		s.mutex.Unlock()
+		return nil
+	}
+	shared, pending := s.shared, s.pending
+	s.pending = map[string]AnswerCounts{}
+	s.mutex.Unlock()
+
+	if shared != nil && len(pending) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), sharedWriteTimeout)
+		err := shared.Add(ctx, pending)
+		cancel()
+		if err != nil {
+			s.mutex.Lock()
+			for day, counts := range pending {
+				current := s.pending[day]
+				current.Answers += counts.Answers
+				current.Cited += counts.Cited
+				s.pending[day] = current
+			}
+			s.mutex.Unlock()
+			return err
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.saveLocked(); err != nil {
+		return err
+	}
+	// Respostas contadas durante a soma ficam para a próxima chamada
+	s.dirty = len(s.pending) > 0
+	return nil
+}
+
+// Run grava as contagens pendentes a cada interval até ctx ser cancelado
+func (s *Store) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Flush(); err != nil {
+				log.Printf("⚠️  Erro ao gravar as métricas do painel: %v", err)
+			}
+		}
+	}
+}
+
+// AnswerTotals soma as respostas entre as datas informadas (inclusive)
+func (s *Store) AnswerTotals(from, to time.Time) AnswerCounts {
+	start, end := from.UTC().Format(dayLayout), to.UTC().Format(dayLayout)
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var total AnswerCounts
+	for day, counts := range s.answers {
+		if day < start || day > end {
+			continue
+		}
+		total.Answers += counts.Answers
+		total.Cited += counts.Cited
+	}
+	return total
+}
+
+// SaveSnapshot grava (ou substitui) o instantâneo do dia
+func (s *Store) SaveSnapshot(at time.Time, snapshot Snapshot) error {
+	snapshot.TakenAt = at.UTC()
+	day := at.UTC().Format(dayLayout)
+
+	s.mutex.RLock()
+	shared := s.shared
+	s.mutex.RUnlock()
+	if shared != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), sharedWriteTimeout)
+		err := shared.PutSnapshot(ctx, day, snapshot)
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.snapshots[day] = snapshot
+	s.pruneLocked(at)
+	if err := s.saveLocked(); err != nil {
+		return err
+	}
+	s.dirty = len(s.pending) > 0
+	return nil
+}
+
+// SnapshotOn retorna o instantâneo do dia informado, se existir
+func (s *Store) SnapshotOn(day time.Time) (Snapshot, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	snapshot, ok := s.snapshots[day.UTC().Format(dayLayout)]
+	return snapshot, ok
+}
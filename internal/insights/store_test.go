@@ -0,0 +1,141 @@
+package insights
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHasOfficialCitation(t *testing.T) {
+	tests := map[string]bool{
+		"Veja https://www.camara.leg.br/proposicoesWeb/fichadetramitacao?idProposicao=1.": true,
+		"Fonte: (https://www.planalto.gov.br/ccivil_03/leis/l8666.htm)":                   true,
+		"Segundo https://exemplo.com.br/gov.br/noticia":                                   false,
+		"Sem links na resposta": false,
+	}
+	for text, want := range tests {
+		if got := HasOfficialCitation(text); got != want {
+			t.Errorf("HasOfficialCitation(%q) = %v, esperava %v", text, got, want)
+		}
+	}
+}
+
+func TestRecordAnswerIsWrittenOnFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "insights.json")
+	store, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	store.RecordAnswer(day, true)
+	store.RecordAnswer(day, false)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("RecordAnswer não deveria gravar o arquivo")
+	}
+	if err := store.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := NewStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := reloaded.AnswerTotals(day, day); got.Answers != 2 || got.Cited != 1 {
+		t.Errorf("totais inesperados depois de recarregar: %+v", got)
+	}
+}
+
+// memoryShared simula o armazenamento compartilhado entre instâncias
+type memoryShared struct {
+	answers   map[string]AnswerCounts
+	snapshots map[string]Snapshot
+	fail      bool
+}
+
+func (m *memoryShared) Add(ctx context.Context, counts map[string]AnswerCounts) error {
+	if m.fail {
+		return errors.New("indisponível")
+	}
+	for day, c := range counts {
+		total := m.answers[day]
+		total.Answers += c.Answers
+		total.Cited += c.Cited
+		m.answers[day] = total
+	}
+	return nil
+}
+
+func (m *memoryShared) PutSnapshot(ctx context.Context, day string, snapshot Snapshot) error {
+	m.snapshots[day] = snapshot
+	return nil
+}
+
+func (m *memoryShared) Load(ctx context.Context, since string) (map[string]AnswerCounts, map[string]Snapshot, error) {
+	answers := map[string]AnswerCounts{}
+	for day, c := range m.answers {
+		if day >= since {
+			answers[day] = c
+		}
+	}
+	snapshots := map[string]Snapshot{}
+	for day, snapshot := range m.snapshots {
+		if day >= since {
+			snapshots[day] = snapshot
+		}
+	}
+	return answers, snapshots, nil
+}
+
+func TestSharedCountsAcrossInstances(t *testing.T) {
+	shared := &memoryShared{answers: map[string]AnswerCounts{}, snapshots: map[string]Snapshot{}}
+	day := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	var stores []*Store
+	for i := 0; i < 2; i++ {
+		store, err := NewStore(filepath.Join(t.TempDir(), "insights.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		store.SetShared(shared)
+		stores = append(stores, store)
+	}
+
+	stores[0].RecordAnswer(day, true)
+	stores[1].RecordAnswer(day, false)
+	stores[1].RecordAnswer(day, true)
+
+	// Uma falha mantém as contagens pendentes para a próxima tentativa
+	shared.fail = true
+	if err := stores[1].Flush(); err == nil {
+		t.Fatal("esperava erro do armazenamento compartilhado")
+	}
+	shared.fail = false
+	for _, store := range stores {
+		if err := store.Flush(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := shared.answers[day.Format(dayLayout)]; got.Answers != 3 || got.Cited != 2 {
+		t.Fatalf("totais compartilhados = %+v, esperava 3 respostas e 2 citadas", got)
+	}
+
+	value := 7
+	if err := stores[1].SaveSnapshot(day, Snapshot{BillsTracked: &value}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Uma resposta ainda não enviada entra no total lido do compartilhado
+	stores[0].RecordAnswer(day, false)
+	if err := stores[0].Refresh(context.Background(), day); err != nil {
+		t.Fatal(err)
+	}
+	if got := stores[0].AnswerTotals(day, day); got.Answers != 4 || got.Cited != 2 {
+		t.Errorf("totais da instância = %+v, esperava 4 respostas e 2 citadas", got)
+	}
+	if snapshot, ok := stores[0].SnapshotOn(day); !ok || snapshot.BillsTracked == nil || *snapshot.BillsTracked != 7 {
+		t.Errorf("instantâneo de outra instância não foi lido: %+v", snapshot)
+	}
+}
@@ -0,0 +1,294 @@
+// Package issues calcula os assuntos em alta combinando perguntas anônimas do chat
+// com o volume de votações nominais no plenário. O cálculo é determinístico:
+// as mesmas entradas produzem sempre o mesmo ranking.
+package issues
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"chat-bot/internal/registry"
+	"chat-bot/internal/textnorm"
+	"chat-bot/internal/votes"
+)
+
+// Tendências exibidas no painel de assuntos
+const (
+	TrendUp      = "up"
+	TrendDown    = "down"
+	TrendStable  = "stable"
+	TrendWarning = "warning"
+)
+
+// Sentimentos exibidos no painel de assuntos
+const (
+	SentimentPositive = "positivo"
+	SentimentNeutral  = "neutro"
+	SentimentNegative = "negativo"
+)
+
+const (
+	// voteWeight é quanto uma votação nominal pesa em relação a uma pergunta do chat
+	voteWeight = 5
+	// trendThreshold é a variação mínima entre janelas para indicar alta ou queda
+	trendThreshold = 0.2
+	// contestedMargin é a diferença máxima entre Sim e Não para uma votação ser considerada disputada
+	contestedMargin = 0.1
+	// sentimentThreshold é o saldo mínimo (positivas - negativas) / perguntas para sair do neutro
+	sentimentThreshold = 0.15
+	maxRelatedVotes    = 3
+	maxKeyPlayers      = 3
+)
+
+// Issue é um assunto em alta no formato usado pelo painel
+type Issue struct {
+	ID             string   `json:"id"`
+	Title          string   `json:"title"`
+	Mentions       int      `json:"mentions"`
+	Votes          int      `json:"votes"`
+	Volume         int      `json:"volume"`
+	PreviousVolume int      `json:"previousVolume"`
+	Intensity      int      `json:"intensity"`
+	Sentiment      string   `json:"sentiment"`
+	Trend          string   `json:"trend"`
+	Contested      bool     `json:"contested"`
+	RelatedVotes   []string `json:"relatedVotes"`
+	RelatedVoteIDs []string `json:"relatedVoteIds"`
+	KeyPlayers     []string `json:"keyPlayers"`
+	Description    string   `json:"description"`
+	LastActivity   string   `json:"lastActivity,omitempty"`
+}
+
+// Input reúne os dados da janela atual e da janela imediatamente anterior
+type Input struct {
+	Current       map[string]TopicActivity
+	Previous      map[string]TopicActivity
+	CurrentVotes  []votes.Vote
+	PreviousVotes []votes.Vote
+	// Politician busca um parlamentar no registro; pode ser nil
+	Politician func(id string) (registry.Politician, bool)
+	Limit      int
+}
+
+// Slug gera o identificador estável de um tema
+func Slug(topic string) string {
+	return strings.Join(textnorm.Tokens(topic), "-")
+}
+
+func groupByTopic(items []votes.Vote) map[string][]votes.Vote {
+	grouped := map[string][]votes.Vote{}
+	for _, v := range items {
+		grouped[v.Topic] = append(grouped[v.Topic], v)
+	}
+	return grouped
+}
+
+func isContested(v votes.Vote) bool {
+	if v.Stage == votes.StageRejeitado || v.Stage == votes.StageVetoDerrubado {
+		return true
+	}
+	decisive := v.Support + v.Against
+	if decisive == 0 {
+		return false
+	}
+	return math.Abs(float64(v.Support-v.Against))/float64(decisive) < contestedMargin
+}
+
+func trendFor(current, previous int, contested bool) string {
+	if contested {
+		return TrendWarning
+	}
+	if previous == 0 {
+		if current > 0 {
+			return TrendUp
+		}
+		return TrendStable
+	}
+	change := float64(current-previous) / float64(previous)
+	switch {
+	case change >= trendThreshold:
+		return TrendUp
+	case change <= -trendThreshold:
+		return TrendDown
+	}
+	return TrendStable
+}
+
+func sentimentFor(activity TopicActivity) string {
+	if activity.Mentions == 0 {
+		return SentimentNeutral
+	}
+	balance := float64(activity.Positive-activity.Negative) / float64(activity.Mentions)
+	switch {
+	case balance >= sentimentThreshold:
+		return SentimentPositive
+	case balance <= -sentimentThreshold:
+		return SentimentNegative
+	}
+	return SentimentNeutral
+}
+
+func relatedVotes(items []votes.Vote) (labels, ids []string) {
+	sorted := append([]votes.Vote(nil), items...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Date != sorted[j].Date {
+			return sorted[i].Date > sorted[j].Date
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	labels, ids = []string{}, []string{}
+	seen := map[string]bool{}
+	for _, v := range sorted {
+		label := v.Proposicao
+		if label == "" {
+			label = v.Title
+		}
+		if label == "" || seen[label] {
+			continue
+		}
+		seen[label] = true
+		labels = append(labels, label)
+		ids = append(ids, v.ID)
+		if len(labels) == maxRelatedVotes {
+			break
+		}
+	}
+	return labels, ids
+}
+
+// formatPlayer descreve o parlamentar como "Dep. Nome (PARTIDO-UF)"
+func formatPlayer(p registry.Politician) string {
+	title := "Dep."
+	if p.Casa == registry.CasaSenado {
+		title = "Sen."
+	}
+	if p.Partido == "" || p.UF == "" {
+		return fmt.Sprintf("%s %s", title, p.NomeParlamentar)
+	}
+	return fmt.Sprintf("%s %s (%s-%s)", title, p.NomeParlamentar, p.Partido, p.UF)
+}
+
+func keyPlayers(activity TopicActivity, lookup func(id string) (registry.Politician, bool)) []string {
+	players := []string{}
+	if lookup == nil {
+		return players
+	}
+
+	ids := make([]string, 0, len(activity.Politicians))
+	for id := range activity.Politicians {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := activity.Politicians[ids[i]], activity.Politicians[ids[j]]
+		if a != b {
+			return a > b
+		}
+		return ids[i] < ids[j]
+	})
+
+	for _, id := range ids {
+		p, ok := lookup(id)
+		if !ok {
+			continue
+		}
+		players = append(players, formatPlayer(p))
+		if len(players) == maxKeyPlayers {
+			break
+		}
+	}
+	return players
+}
+
+func describe(mentions, voteCount int) string {
+	perguntas := "perguntas"
+	if mentions == 1 {
+		perguntas = "pergunta"
+	}
+	votacoes := "votações nominais"
+	if voteCount == 1 {
+		votacoes = "votação nominal"
+	}
+	return fmt.Sprintf("%d %s no chat e %d %s no plenário no período.", mentions, perguntas, voteCount, votacoes)
+}
+
+// Compute monta o ranking de assuntos. Temas sem classificação ("Outros") e sem
+// atividade na janela atual ficam de fora. A intensidade é relativa ao tema de
+// maior volume, que recebe 100.
+func Compute(in Input) []Issue {
+	currentVotes := groupByTopic(in.CurrentVotes)
+	previousVotes := groupByTopic(in.PreviousVotes)
+
+	topics := map[string]bool{}
+	for topic := range in.Current {
+		topics[topic] = true
+	}
+	for topic := range currentVotes {
+		topics[topic] = true
+	}
+
+	result := []Issue{}
+	for topic := range topics {
+		if topic == "" || topic == votes.TopicOutros {
+			continue
+		}
+
+		activity := in.Current[topic]
+		topicVotes := currentVotes[topic]
+		volume := activity.Mentions + voteWeight*len(topicVotes)
+		if volume == 0 {
+			continue
+		}
+		previousVolume := in.Previous[topic].Mentions + voteWeight*len(previousVotes[topic])
+
+		contested := false
+		lastActivity := ""
+		for _, v := range topicVotes {
+			if isContested(v) {
+				contested = true
+			}
+			if v.Date > lastActivity {
+				lastActivity = v.Date
+			}
+		}
+
+		labels, ids := relatedVotes(topicVotes)
+		result = append(result, Issue{
+			ID:             Slug(topic),
+			Title:          topic,
+			Mentions:       activity.Mentions,
+			Votes:          len(topicVotes),
+			Volume:         volume,
+			PreviousVolume: previousVolume,
+			Sentiment:      sentimentFor(activity),
+			Trend:          trendFor(volume, previousVolume, contested),
+			Contested:      contested,
+			RelatedVotes:   labels,
+			RelatedVoteIDs: ids,
+			KeyPlayers:     keyPlayers(activity, in.Politician),
+			Description:    describe(activity.Mentions, len(topicVotes)),
+			LastActivity:   lastActivity,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Volume != result[j].Volume {
+			return result[i].Volume > result[j].Volume
+		}
+		return result[i].ID < result[j].ID
+	})
+
+	if len(result) > 0 {
+		maxVolume := float64(result[0].Volume)
+		for i := range result {
+			result[i].Intensity = int(math.Round(float64(result[i].Volume) / maxVolume * 100))
+		}
+	}
+
+	if in.Limit > 0 && len(result) > in.Limit {
+		result = result[:in.Limit]
+	}
+	return result
+}
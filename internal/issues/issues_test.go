@@ -0,0 +1,136 @@
+package issues
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"chat-bot/internal/registry"
+	"chat-bot/internal/votes"
+)
+
+type fixture struct {
+	Current       map[string]TopicActivity `json:"current"`
+	Previous      map[string]TopicActivity `json:"previous"`
+	CurrentVotes  []votes.Vote             `json:"currentVotes"`
+	PreviousVotes []votes.Vote             `json:"previousVotes"`
+	Politicians   []registry.Politician    `json:"politicians"`
+}
+
+func loadFixture(t *testing.T, name string) Input {
+	t.Helper()
+	payload, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f fixture
+	if err := json.Unmarshal(payload, &f); err != nil {
+		t.Fatal(err)
+	}
+	byID := map[string]registry.Politician{}
+	for _, p := range f.Politicians {
+		byID[p.ID] = p
+	}
+	return Input{
+		Current:       f.Current,
+		Previous:      f.Previous,
+		CurrentVotes:  f.CurrentVotes,
+		PreviousVotes: f.PreviousVotes,
+		Politician: func(id string) (registry.Politician, bool) {
+			p, ok := byID[id]
+			return p, ok
+		},
+	}
+}
+
+func TestComputeFixture(t *testing.T) {
+	result := Compute(loadFixture(t, "window.json"))
+	if len(result) != 2 {
+		t.Fatalf("esperava 2 assuntos (Outros fica de fora), obteve %d: %+v", len(result), result)
+	}
+
+	saude := result[0]
+	want := Issue{
+		ID:             "saude",
+		Title:          "Saúde",
+		Mentions:       10,
+		Votes:          2,
+		Volume:         20,
+		PreviousVolume: 4,
+		Intensity:      100,
+		Sentiment:      SentimentPositive,
+		Trend:          TrendWarning,
+		Contested:      true,
+		RelatedVotes:   []string{"PL 2/2026", "PL 1/2026"},
+		RelatedVoteIDs: []string{"camara-b", "camara-a"},
+		KeyPlayers:     []string{"Dep. Bruno (PL-RJ)", "Dep. Ana (PT-SP)"},
+		Description:    "10 perguntas no chat e 2 votações nominais no plenário no período.",
+		LastActivity:   "2026-10-12",
+	}
+	if !reflect.DeepEqual(saude, want) {
+		t.Errorf("Saúde:\n obtido   %+v\n esperado %+v", saude, want)
+	}
+
+	economia := result[1]
+	if economia.Volume != 8 || economia.PreviousVolume != 25 || economia.Intensity != 40 {
+		t.Errorf("volumes de Economia inesperados: %+v", economia)
+	}
+	if economia.Trend != TrendDown || economia.Sentiment != SentimentNegative || economia.Contested {
+		t.Errorf("tendência/sentimento de Economia inesperados: %+v", economia)
+	}
+
+	if again := Compute(loadFixture(t, "window.json")); !reflect.DeepEqual(result, again) {
+		t.Errorf("o ranking mudou entre execuções com as mesmas entradas")
+	}
+}
+
+func TestComputeLimit(t *testing.T) {
+	input := loadFixture(t, "window.json")
+	input.Limit = 1
+	if result := Compute(input); len(result) != 1 || result[0].ID != "saude" {
+		t.Errorf("limite não aplicado: %+v", result)
+	}
+}
+
+func TestSentiment(t *testing.T) {
+	tests := map[string]int{
+		"O projeto foi aprovado, um avanço para a saúde": 1,
+		"Que escândalo de corrupção":                     -1,
+		"O resultado não foi bom":                        -1,
+		"Quando será a votação?":                         0,
+	}
+	for text, want := range tests {
+		if got := Sentiment(text); got != want {
+			t.Errorf("Sentiment(%q) = %d, esperava %d", text, got, want)
+		}
+	}
+}
+
+func TestMentionStoreFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mentions.json")
+	store, err := NewMentionStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	store.Record(day, "Saúde", 1, []string{"camara-1"})
+	store.Record(day, "Saúde", -1, nil)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("Record não deveria gravar o arquivo")
+	}
+	if err := store.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := NewMentionStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := reloaded.Aggregate(day, day)["Saúde"]
+	if got.Mentions != 2 || got.Positive != 1 || got.Negative != 1 || got.Politicians["camara-1"] != 1 {
+		t.Errorf("contagens inesperadas depois de recarregar: %+v", got)
+	}
+}
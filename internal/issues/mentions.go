@@ -0,0 +1,194 @@
+package issues
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// retentionDays limita o histórico guardado (cobre a janela de 12 meses e a anterior)
+const retentionDays = 800
+
+const dayLayout = "2006-01-02"
+
+// TopicActivity agrega as perguntas do chat sobre um tema. Nenhum texto ou
+// identificador de usuário é guardado, apenas contagens.
+type TopicActivity struct {
+	Mentions    int            `json:"mentions"`
+	Positive    int            `json:"positive"`
+	Negative    int            `json:"negative"`
+	Politicians map[string]int `json:"politicians,omitempty"`
+}
+
+func (a *TopicActivity) add(other TopicActivity) {
+	a.Mentions += other.Mentions
+	a.Positive += other.Positive
+	a.Negative += other.Negative
+	for id, count := range other.Politicians {
+		if a.Politicians == nil {
+			a.Politicians = map[string]int{}
+		}
+		a.Politicians[id] += count
+	}
+}
+
+// MentionStore guarda contagens diárias de perguntas por tema em um arquivo JSON
+// local. Record só altera a memória; o arquivo é regravado por Flush, chamado
+// periodicamente por Run e no encerramento do servidor.
+type MentionStore struct {
+	filePath string
+	days     map[string]map[string]*TopicActivity
+	// dirty indica contagens ainda não gravadas no arquivo
+	dirty bool
+	mutex sync.RWMutex
+}
+
+// NewMentionStore cria o armazenamento e carrega o arquivo local, se existir
+func NewMentionStore(filePath string) (*MentionStore, error) {
+	s := &MentionStore{filePath: filePath, days: map[string]map[string]*TopicActivity{}}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *MentionStore) load() error {
+	file, err := os.Open(s.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	var data map[string]map[string]*TopicActivity
+	if err := json.NewDecoder(file).Decode(&data); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+	if data != nil {
+		s.days = data
+	}
+	return nil
+}
+
+func (s *MentionStore) saveLocked() error {
+	if dir := filepath.Dir(s.filePath); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	payload, err := json.Marshal(s.days)
+	if err != nil {
+		return err
+	}
+
+	tempPath := s.filePath + ".tmp"
+	if err := os.WriteFile(tempPath, payload, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, s.filePath)
+}
+
+// Record contabiliza uma pergunta sobre o tema. sentiment é -1, 0 ou 1.
+func (s *MentionStore) Record(at time.Time, topic string, sentiment int, politicianIDs []string) {
+	day := at.UTC().Format(dayLayout)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	topics, ok := s.days[day]
+	if !ok {
+		topics = map[string]*TopicActivity{}
+		s.days[day] = topics
+	}
+	activity, ok := topics[topic]
+	if !ok {
+		activity = &TopicActivity{}
+		topics[topic] = activity
+	}
+
+	activity.Mentions++
+	switch {
+	case sentiment > 0:
+		activity.Positive++
+	case sentiment < 0:
+		activity.Negative++
+	}
+	for _, id := range politicianIDs {
+		if activity.Politicians == nil {
+			activity.Politicians = map[string]int{}
+		}
+		activity.Politicians[id]++
+	}
+
+	cutoff := at.UTC().AddDate(0, 0, -retentionDays).Format(dayLayout)
+	for d := range s.days {
+		if d < cutoff {
+			delete(s.days, d)
+		}
+	}
+	s.dirty = true
+}
+
+// Flush grava o arquivo se houver contagens novas desde a última gravação
+func (s *MentionStore) Flush() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.dirty {
+		return nil
+	}
+	if err := s.saveLocked(); err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
+}
+
+// Run grava as contagens pendentes a cada interval até ctx ser cancelado
+func (s *MentionStore) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Flush(); err != nil {
+				log.Printf("⚠️  Erro ao gravar as menções por tema: %v", err)
+			}
+		}
+	}
+}
+
+// Aggregate soma a atividade por tema entre as datas informadas (inclusive)
+func (s *MentionStore) Aggregate(from, to time.Time) map[string]TopicActivity {
+	start, end := from.UTC().Format(dayLayout), to.UTC().Format(dayLayout)
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	result := map[string]TopicActivity{}
+	for day, topics := range s.days {
+		if day < start || day > end {
+			continue
+		}
+		for topic, activity := range topics {
+			total := result[topic]
+			total.add(*activity)
+			result[topic] = total
+		}
+	}
+	return result
+}
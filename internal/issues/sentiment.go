@@ -0,0 +1,56 @@
+package issues
+
+import (
+	"strings"
+
+	"chat-bot/internal/textnorm"
+)
+
+// Prefixos (sem acentos) usados na classificação de sentimento das perguntas
+var (
+	positiveTerms = []string{"aprov", "avanc", "benefic", "bom", "boa", "melhor", "conquist", "apoi", "sucesso", "positiv", "acert", "ganho"}
+	negativeTerms = []string{"ruim", "pior", "corrup", "escandal", "crise", "fraude", "prejuiz", "retrocess", "absurd", "golpe", "injust", "critic", "polemic", "rejeit", "desvio"}
+	negations     = map[string]struct{}{"nao": {}, "nem": {}, "nunca": {}, "sem": {}}
+)
+
+func hasTermPrefix(token string, terms []string) bool {
+	for _, term := range terms {
+		if strings.HasPrefix(token, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// Sentiment classifica o tom de uma pergunta em -1 (negativo), 0 (neutro) ou 1 (positivo)
+// contando termos de um léxico simples. Uma negação até duas palavras antes inverte o termo.
+func Sentiment(text string) int {
+	tokens := textnorm.Tokens(text)
+	balance := 0
+	for i, token := range tokens {
+		weight := 0
+		switch {
+		case hasTermPrefix(token, positiveTerms):
+			weight = 1
+		case hasTermPrefix(token, negativeTerms):
+			weight = -1
+		default:
+			continue
+		}
+		for j := i - 1; j >= 0 && j >= i-2; j-- {
+			if _, negated := negations[tokens[j]]; negated {
+				weight = -weight
+				break
+			}
+		}
+		balance += weight
+	}
+
+	switch {
+	case balance > 0:
+		return 1
+	case balance < 0:
+		return -1
+	}
+	return 0
+}
@@ -0,0 +1,260 @@
+// Package legalsearch mantém um índice BM25 em memória sobre o texto da
+// Constituição e de leis guardadas localmente, com um documento por artigo,
+// para que o chat responda com o texto oficial em vez da memória do modelo.
+package legalsearch
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"chat-bot/internal/legislacao"
+	"chat-bot/internal/textnorm"
+)
+
+// Parâmetros usuais do BM25
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// Os termos sintéticos começam com "§", que o tokenizador nunca produz
+const (
+	artigoTermPrefix = "§art:"
+	normaTermPrefix  = "§norma:"
+)
+
+var constituicaoKey = legislacao.Reference{Tipo: legislacao.TipoConstituicao}.Key()
+
+// Chunk é um artigo indexado
+type Chunk struct {
+	ID       string `json:"id"`
+	LawKey   string `json:"lawKey"`
+	Norma    string `json:"norma"`
+	Titulo   string `json:"titulo"`
+	Artigo   string `json:"artigo"`
+	Secao    string `json:"secao,omitempty"`
+	Texto    string `json:"texto"`
+	Citation string `json:"citation"`
+	URL      string `json:"url,omitempty"`
+}
+
+// Hit é um artigo encontrado com a pontuação BM25
+type Hit struct {
+	Chunk
+	Score float64 `json:"score"`
+}
+
+// LawInfo descreve uma norma carregada no índice
+type LawInfo struct {
+	Key     string `json:"key"`
+	Norma   string `json:"norma"`
+	Titulo  string `json:"titulo"`
+	Artigos int    `json:"artigos"`
+}
+
+type posting struct {
+	doc int
+	tf  int
+}
+
+// Index é um índice BM25 somente leitura; é montado uma vez e pode ser
+// consultado em paralelo
+type Index struct {
+	chunks   []Chunk
+	lengths  []int
+	avgLen   float64
+	postings map[string][]posting
+	laws     []LawInfo
+}
+
+// Query é uma busca no índice
+type Query struct {
+	Text string
+	// LawKey restringe a busca a uma norma ("constituicao-1988")
+	LawKey string
+	Limit  int
+}
+
+// analyze converte o texto nos termos indexados: sem acentos, sem stopwords e
+// reduzidos ao radical
+func analyze(text string) []string {
+	tokens := textnorm.Tokens(text)
+	terms := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		// Números ("14", "133", "5º") só atrapalham: os artigos e normas citados
+		// viram termos sintéticos em queryTerms
+		if stopwords[token] || len(token) < 2 || startsWithDigit(token) {
+			continue
+		}
+		terms = append(terms, Stem(token))
+	}
+	return terms
+}
+
+func startsWithDigit(s string) bool {
+	return s[0] >= '0' && s[0] <= '9'
+}
+
+// artigoKey normaliza o número do artigo para o termo sintético ("5º" -> "5", "1º-A" -> "1-a")
+func artigoKey(numero string) string {
+	return strings.ToLower(strings.ReplaceAll(numero, "º", ""))
+}
+
+// Build monta o índice com um documento por artigo de cada norma
+func Build(laws []legislacao.Law) *Index {
+	idx := &Index{postings: map[string][]posting{}}
+	total := 0
+	for _, law := range laws {
+		norma := normaLabel(law)
+		info := LawInfo{Key: law.Key, Norma: norma, Titulo: law.Titulo}
+		for _, artigo := range law.Artigos {
+			chunk := Chunk{
+				ID:       law.Key + "#art-" + artigoKey(artigo.Numero),
+				LawKey:   law.Key,
+				Norma:    norma,
+				Titulo:   law.Titulo,
+				Artigo:   artigo.Numero,
+				Secao:    artigo.Secao,
+				Texto:    artigo.Text(),
+				Citation: citation(law, norma, artigo.Numero),
+				URL:      law.URL,
+			}
+			terms := analyze(chunk.Secao + " " + chunk.Texto)
+			doc := len(idx.chunks)
+			idx.chunks = append(idx.chunks, chunk)
+			idx.lengths = append(idx.lengths, len(terms))
+			total += len(terms)
+
+			tf := map[string]int{}
+			for _, term := range terms {
+				tf[term]++
+			}
+			tf[artigoTermPrefix+artigoKey(artigo.Numero)]++
+			tf[normaTermPrefix+law.Key]++
+			for term, n := range tf {
+				idx.postings[term] = append(idx.postings[term], posting{doc: doc, tf: n})
+			}
+			info.Artigos++
+		}
+		idx.laws = append(idx.laws, info)
+	}
+	if len(idx.chunks) > 0 {
+		idx.avgLen = float64(total) / float64(len(idx.chunks))
+	}
+	return idx
+}
+
+// normaLabel é o nome curto da norma ("Lei nº 14.133/2021", "Constituição Federal")
+func normaLabel(law legislacao.Law) string {
+	if law.Tipo != "" {
+		return legislacao.Reference{Tipo: law.Tipo, Numero: law.Numero, Ano: law.Ano}.Label()
+	}
+	return law.Titulo
+}
+
+// citation segue a forma usual de citação: "art. 5º da Constituição Federal"
+func citation(law legislacao.Law, norma, artigo string) string {
+	prefix := "art. "
+	if strings.HasPrefix(artigo, "ADCT-") {
+		artigo = strings.TrimPrefix(artigo, "ADCT-")
+		prefix = "ADCT, art. "
+	}
+	if law.Tipo == legislacao.TipoConstituicao {
+		return prefix + artigo + " da " + norma
+	}
+	return norma + ", " + prefix + artigo
+}
+
+// Size devolve o número de artigos indexados
+func (idx *Index) Size() int {
+	return len(idx.chunks)
+}
+
+// Laws lista as normas carregadas
+func (idx *Index) Laws() []LawInfo {
+	return idx.laws
+}
+
+var artigoQueryPattern = regexp.MustCompile(`(?i)\bart(?:igo)?\.?\s*(\d{1,4})\s*[º°o]?(?:\s*-\s*([a-z])\b)?`)
+
+// queryTerms separa os termos da pergunta dos termos sintéticos dos artigos e
+// normas citados, que pesam como termos raros e puxam o artigo exato para o topo
+func queryTerms(text string) (terms, synthetic []string) {
+	terms = analyze(text)
+	for _, m := range artigoQueryPattern.FindAllStringSubmatch(text, -1) {
+		key := m[1]
+		if m[2] != "" {
+			key += "-" + strings.ToLower(m[2])
+		}
+		synthetic = append(synthetic, artigoTermPrefix+key)
+	}
+	refs := legislacao.ParseReferences(text)
+	for _, ref := range refs {
+		synthetic = append(synthetic, normaTermPrefix+ref.Key())
+	}
+	// "o que diz o art. 5º?" sem indicar a norma costuma se referir à Constituição
+	if len(refs) == 0 && len(synthetic) > 0 {
+		synthetic = append(synthetic, normaTermPrefix+constituicaoKey)
+	}
+	return terms, synthetic
+}
+
+// Search devolve os artigos mais relevantes pela pontuação BM25
+func (idx *Index) Search(q Query) []Hit {
+	if len(idx.chunks) == 0 {
+		return nil
+	}
+	n := float64(len(idx.chunks))
+	scores := map[int]float64{}
+	seen := map[string]bool{}
+	score := func(term string, lengthNorm bool) {
+		if seen[term] {
+			return
+		}
+		seen[term] = true
+		postings := idx.postings[term]
+		if len(postings) == 0 {
+			return
+		}
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for _, p := range postings {
+			tf := float64(p.tf)
+			norm := 1.0
+			if lengthNorm {
+				norm = 1 - b + b*float64(idx.lengths[p.doc])/idx.avgLen
+			}
+			scores[p.doc] += idf * tf * (k1 + 1) / (tf + k1*norm)
+		}
+	}
+
+	terms, synthetic := queryTerms(q.Text)
+	for _, term := range terms {
+		score(term, true)
+	}
+	// Artigo e norma citados não dependem do tamanho do artigo
+	for _, term := range synthetic {
+		score(term, false)
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for doc, score := range scores {
+		chunk := idx.chunks[doc]
+		if q.LawKey != "" && chunk.LawKey != q.LawKey {
+			continue
+		}
+		hits = append(hits, Hit{Chunk: chunk, Score: math.Round(score*1000) / 1000})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	if q.Limit > 0 && len(hits) > q.Limit {
+		hits = hits[:q.Limit]
+	}
+	return hits
+}
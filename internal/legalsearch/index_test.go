@@ -0,0 +1,141 @@
+package legalsearch
+
+import (
+	"math"
+	"testing"
+
+	"chat-bot/internal/legislacao"
+)
+
+func TestStem(t *testing.T) {
+	tests := map[string]string{
+		"licitacoes":       "licit",
+		"licitacao":        "licit",
+		"iguais":           "igual",
+		"igualdade":        "igual",
+		"mulheres":         "mulh",
+		"mulher":           "mulh",
+		"responsabilidade": "respons",
+		"responsavel":      "respons",
+		"possibilidade":    "poss",
+		"possivel":         "poss",
+		"direitos":         "direit",
+		"direito":          "direit",
+		"casa":             "cas",
+		"lei":              "lei",
+		"art5":             "art5",
+	}
+	for word, want := range tests {
+		if got := Stem(word); got != want {
+			t.Errorf("Stem(%q) = %q, esperava %q", word, got, want)
+		}
+	}
+}
+
+func TestAnalyzeDropsStopwordsAndNumbers(t *testing.T) {
+	got := analyze("O que diz a Lei 14.133 sobre as licitações?")
+	if len(got) != 1 || got[0] != "licit" {
+		t.Errorf("analyze = %v, esperava [licit]", got)
+	}
+}
+
+func testLaws() []legislacao.Law {
+	return []legislacao.Law{
+		{
+			Key:    "constituicao-1988",
+			Tipo:   legislacao.TipoConstituicao,
+			Titulo: "CONSTITUIÇÃO DA REPÚBLICA FEDERATIVA DO BRASIL DE 1988",
+			Artigos: []legislacao.Artigo{
+				{Numero: "1º", Caput: "A República Federativa do Brasil tem como fundamentos a soberania e a cidadania."},
+				{Numero: "5º", Caput: "Todos são iguais perante a lei, sem distinção de qualquer natureza.", Incisos: []legislacao.Inciso{{Rotulo: "I", Texto: "homens e mulheres são iguais em direitos e obrigações;"}}},
+				{Numero: "ADCT-1º", Caput: "O Presidente da República prestará o compromisso."},
+			},
+		},
+		{
+			Key:    "lei-14133-2021",
+			Tipo:   legislacao.TipoLei,
+			Numero: 14133,
+			Ano:    2021,
+			Titulo: "LEI Nº 14.133, DE 1º DE ABRIL DE 2021",
+			Artigos: []legislacao.Artigo{
+				{Numero: "5º", Caput: "Na aplicação desta Lei, serão observados os princípios da legalidade e da igualdade."},
+				{Numero: "75", Caput: "É dispensável a licitação para contratação que envolva valores inferiores."},
+			},
+		},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	idx := Build(testLaws())
+	if idx.Size() != 5 || len(idx.Laws()) != 2 || idx.Laws()[1].Artigos != 2 {
+		t.Fatalf("índice inesperado: %d artigos, normas %+v", idx.Size(), idx.Laws())
+	}
+	citations := map[string]string{}
+	for _, chunk := range idx.chunks {
+		citations[chunk.ID] = chunk.Citation
+	}
+	want := map[string]string{
+		"constituicao-1988#art-5":      "art. 5º da Constituição Federal",
+		"constituicao-1988#art-adct-1": "ADCT, art. 1º da Constituição Federal",
+		"lei-14133-2021#art-75":        "Lei nº 14.133/2021, art. 75",
+	}
+	for id, citation := range want {
+		if citations[id] != citation {
+			t.Errorf("citação de %s = %q, esperava %q", id, citations[id], citation)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	idx := Build(testLaws())
+	tests := []struct {
+		name  string
+		query Query
+		first string
+		count int
+	}{
+		{"radicais e sem acentos", Query{Text: "igualdade entre homens e mulheres"}, "constituicao-1988#art-5", 2},
+		{"plural encontra o singular", Query{Text: "licitações"}, "lei-14133-2021#art-75", 1},
+		{"artigo citado sem norma vai para a Constituição", Query{Text: "o que diz o art. 5º?"}, "constituicao-1988#art-5", 0},
+		{"artigo e norma citados", Query{Text: "art. 5º da Lei 14.133/2021"}, "lei-14133-2021#art-5", 0},
+		{"filtro por norma", Query{Text: "igualdade", LawKey: "lei-14133-2021"}, "lei-14133-2021#art-5", 1},
+		{"limite", Query{Text: "igualdade", Limit: 1}, "constituicao-1988#art-5", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits := idx.Search(tt.query)
+			if len(hits) == 0 || hits[0].ID != tt.first {
+				t.Fatalf("primeiro resultado %+v, esperava %s", hits, tt.first)
+			}
+			if tt.count > 0 && len(hits) != tt.count {
+				t.Errorf("esperava %d resultados, obteve %d", tt.count, len(hits))
+			}
+			for i := 1; i < len(hits); i++ {
+				if hits[i].Score > hits[i-1].Score {
+					t.Errorf("resultados fora de ordem: %+v", hits)
+				}
+			}
+		})
+	}
+
+	if hits := Build(nil).Search(Query{Text: "igualdade"}); hits != nil {
+		t.Errorf("índice vazio devolveu %+v", hits)
+	}
+}
+
+func TestSearchScore(t *testing.T) {
+	// Dois artigos do mesmo tamanho e o termo em só um deles: idf = ln(2) e a
+	// normalização pelo tamanho vale 1, então a pontuação é exatamente ln(2)
+	idx := Build([]legislacao.Law{{
+		Key:  "lei-1-2020",
+		Tipo: legislacao.TipoLei, Numero: 1, Ano: 2020,
+		Artigos: []legislacao.Artigo{
+			{Numero: "1º", Caput: "soberania nacional"},
+			{Numero: "2º", Caput: "cidadania plena"},
+		},
+	}})
+	hits := idx.Search(Query{Text: "soberania"})
+	if len(hits) != 1 || hits[0].Score != math.Round(math.Ln2*1000)/1000 {
+		t.Errorf("pontuação BM25 inesperada: %+v", hits)
+	}
+}
@@ -0,0 +1,99 @@
+package legalsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"chat-bot/internal/legislacao"
+)
+
+// LoadLaws lê as normas de arquivos locais: JSON no formato de
+// legislacao.Law (gerado por cmd/legal-corpus) ou páginas HTML salvas do
+// Planalto. Sem lista de arquivos, carrega todos os .json, .htm e .html do
+// diretório; diretório inexistente resulta em índice vazio.
+func LoadLaws(dir string, files []string) ([]legislacao.Law, error) {
+	if len(files) == 0 {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && isCorpusFile(entry.Name()) {
+				files = append(files, entry.Name())
+			}
+		}
+		sort.Strings(files)
+	}
+
+	laws := make([]legislacao.Law, 0, len(files))
+	seen := map[string]bool{}
+	for _, name := range files {
+		path := name
+		if !filepath.IsAbs(path) && !strings.ContainsRune(path, os.PathSeparator) {
+			path = filepath.Join(dir, name)
+		}
+		law, err := loadLaw(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if seen[law.Key] {
+			continue
+		}
+		seen[law.Key] = true
+		laws = append(laws, law)
+	}
+	return laws, nil
+}
+
+func isCorpusFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json", ".htm", ".html":
+		return true
+	}
+	return false
+}
+
+func loadLaw(path string) (legislacao.Law, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return legislacao.Law{}, err
+	}
+	defer file.Close()
+
+	var law legislacao.Law
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if err := json.NewDecoder(file).Decode(&law); err != nil {
+			return legislacao.Law{}, err
+		}
+		if len(law.Artigos) == 0 {
+			return legislacao.Law{}, fmt.Errorf("nenhum artigo encontrado")
+		}
+	} else if law, err = legislacao.Parse(file, "text/html"); err != nil {
+		return legislacao.Law{}, err
+	}
+
+	// Páginas salvas não trazem tipo, número e ano: vêm do título ("LEI Nº 14.133, DE ...")
+	if law.Tipo == "" {
+		if refs := legislacao.ParseReferences(law.Titulo); len(refs) > 0 {
+			law.Tipo, law.Numero, law.Ano = refs[0].Tipo, refs[0].Numero, refs[0].Ano
+		}
+	}
+	if law.Key == "" {
+		if law.Tipo != "" {
+			law.Key = legislacao.Reference{Tipo: law.Tipo, Numero: law.Numero, Ano: law.Ano}.Key()
+		} else {
+			law.Key = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+	}
+	if law.Titulo == "" {
+		law.Titulo = law.Key
+	}
+	return law, nil
+}
@@ -0,0 +1,100 @@
+package legalsearch
+
+import "strings"
+
+// stopwords são palavras sem acento que não ajudam a distinguir artigos
+var stopwords = map[string]bool{
+	"a": true, "ao": true, "aos": true, "as": true, "com": true, "como": true, "da": true, "das": true,
+	"de": true, "do": true, "dos": true, "e": true, "ela": true, "ele": true, "em": true, "entre": true,
+	"essa": true, "esse": true, "esta": true, "este": true, "eu": true, "ha": true, "isso": true, "ja": true,
+	"lhe": true, "mais": true, "mas": true, "me": true, "na": true, "nas": true, "no": true, "nos": true,
+	"o": true, "os": true, "ou": true, "para": true, "pela": true, "pelas": true, "pelo": true, "pelos": true,
+	"por": true, "qual": true, "quais": true, "que": true, "quem": true, "se": true, "sem": true, "ser": true,
+	"seu": true, "seus": true, "sua": true, "suas": true, "sobre": true, "um": true, "uma": true, "umas": true,
+	"uns": true, "diz": true, "dizer": true, "fala": true, "falar": true, "art": true, "artigo": true,
+	"lei": true, "sao": true, "tem": true, "ter": true, "qualquer": true, "cada": true, "todo": true, "todos": true,
+	"quando": true, "onde": true, "porque": true, "pode": true, "podem": true, "deve": true, "devem": true,
+	"nao": true, "sim": true, "isto": true, "aquele": true, "aquela": true, "seja": true, "sera": true,
+}
+
+type suffixRule struct {
+	suffix      string
+	replacement string
+	// minStem é o tamanho mínimo do radical que deve sobrar
+	minStem int
+}
+
+// Regras simplificadas do removedor de sufixos RSLP (Orengo & Huyck), aplicadas
+// sobre palavras já sem acento. A ordem importa: sufixos mais longos primeiro.
+var (
+	pluralRules = []suffixRule{
+		{"ns", "m", 1}, {"oes", "ao", 1}, {"aes", "ao", 1}, {"ais", "al", 1}, {"eis", "el", 2},
+		{"ois", "ol", 1}, {"is", "il", 2}, {"les", "l", 1}, {"res", "r", 2}, {"s", "", 2},
+	}
+	feminineRules = []suffixRule{
+		{"ona", "ao", 3}, {"ora", "or", 3}, {"ina", "ino", 3}, {"esa", "es", 3}, {"osa", "oso", 3},
+		{"iva", "ivo", 3}, {"ada", "ado", 2}, {"ida", "ido", 3}, {"ica", "ico", 3},
+	}
+	nounRules = []suffixRule{
+		{"amentos", "", 3}, {"imentos", "", 3}, {"amento", "", 3}, {"imento", "", 3}, {"mento", "", 4},
+		{"izacao", "", 4}, {"acao", "", 3}, {"icao", "", 3}, {"ucao", "", 3}, {"ancia", "", 3},
+		{"encia", "", 3}, {"abilidade", "", 4}, {"ibilidade", "", 4}, {"idade", "", 4}, {"dade", "", 4},
+		{"ismo", "", 3}, {"ista", "", 4}, {"avel", "", 2}, {"ivel", "", 3}, {"ador", "", 3},
+		{"edor", "", 3}, {"idor", "", 4}, {"eza", "", 3}, {"ico", "", 4}, {"ivo", "", 4},
+		{"oso", "", 3}, {"al", "", 4},
+	}
+	verbRules = []suffixRule{
+		{"ariam", "", 2}, {"eriam", "", 2}, {"iriam", "", 2}, {"assem", "", 2}, {"essem", "", 2},
+		{"issem", "", 2}, {"aram", "", 2}, {"eram", "", 2}, {"iram", "", 2}, {"arem", "", 2},
+		{"erem", "", 2}, {"irem", "", 2}, {"ando", "", 2}, {"endo", "", 3}, {"indo", "", 3},
+		{"ado", "", 2}, {"ido", "", 3}, {"ara", "", 2}, {"era", "", 3}, {"ira", "", 3},
+		{"ava", "", 2}, {"iam", "", 3}, {"am", "", 2}, {"em", "", 2}, {"ar", "", 2},
+		{"er", "", 2}, {"ir", "", 3},
+	}
+)
+
+// applyRules troca o primeiro sufixo encontrado; informa se alguma regra valeu
+func applyRules(word string, rules []suffixRule) (string, bool) {
+	for _, rule := range rules {
+		if !strings.HasSuffix(word, rule.suffix) {
+			continue
+		}
+		stem := word[:len(word)-len(rule.suffix)]
+		if len(stem) < rule.minStem {
+			continue
+		}
+		return stem + rule.replacement, true
+	}
+	return word, false
+}
+
+// Stem reduz uma palavra sem acentos ao radical ("licitacoes" -> "licit"),
+// para que singular, plural e derivações encontrem os mesmos artigos
+func Stem(word string) string {
+	if len(word) < 4 || !isAlpha(word) {
+		return word
+	}
+	word, _ = applyRules(word, pluralRules)
+	word, _ = applyRules(word, feminineRules)
+	if strings.HasSuffix(word, "mente") && len(word) > 8 {
+		word = word[:len(word)-5]
+	}
+	if stem, ok := applyRules(word, nounRules); ok {
+		word = stem
+	} else {
+		word, _ = applyRules(word, verbRules)
+	}
+	if n := len(word); n > 3 && strings.ContainsRune("aeo", rune(word[n-1])) {
+		word = word[:n-1]
+	}
+	return word
+}
+
+func isAlpha(word string) bool {
+	for _, r := range word {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,164 @@
+package legislacao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BaseURL é a raiz da legislação federal no portal do Planalto
+const BaseURL = "https://www.planalto.gov.br/ccivil_03"
+
+// maxPageSize limita o download; a Constituição compilada tem cerca de 3 MB
+const maxPageSize = 16 << 20
+
+// ErrNotFound indica que nenhuma página do Planalto trouxe a norma
+var ErrNotFound = errors.New("norma não encontrada no Planalto")
+
+// Client baixa as páginas de normas do Planalto
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient cria um cliente com timeout padrão
+func NewClient() *Client {
+	return &Client{
+		baseURL:    BaseURL,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// AtoRange devolve o intervalo de anos usado nas pastas _ato do Planalto a
+// partir de 2004: "2004-2006" e, desde 2007, blocos de quatro anos que
+// acompanham os mandatos presidenciais ("2023-2026")
+func AtoRange(year int) string {
+	if year < 2007 {
+		return "2004-2006"
+	}
+	start := 2007 + 4*((year-2007)/4)
+	return fmt.Sprintf("%d-%d", start, start+3)
+}
+
+// withVariants acrescenta as versões compiladas, que trazem o texto atualizado
+func withVariants(paths ...string) []string {
+	var urls []string
+	for _, path := range paths {
+		urls = append(urls, path+"compilado.htm", path+"compilada.htm", path+".htm")
+	}
+	return urls
+}
+
+// candidatePaths lista os caminhos em que a norma pode estar publicada; o
+// Planalto mudou a organização das pastas ao longo dos anos
+func candidatePaths(ref Reference) []string {
+	n := strconv.Itoa(ref.Numero)
+	dotted := formatNumero(ref.Numero)
+	ato := ""
+	if ref.Ano >= 2004 {
+		ato = fmt.Sprintf("_ato%s/%d/", AtoRange(ref.Ano), ref.Ano)
+	}
+
+	switch ref.Tipo {
+	case TipoConstituicao:
+		return []string{"constituicao/constituicaocompilado.htm", "constituicao/constituicao.htm"}
+	case TipoEmendaConstitucional:
+		return []string{"constituicao/emendas/emc/emc" + n + ".htm"}
+	case TipoLeiComplementar:
+		return withVariants("leis/lcp/lcp" + n)
+	case TipoDecretoLei:
+		return withVariants("decreto-lei/del"+n, "decreto-lei/del"+dotted)
+	case TipoMedidaProvisoria:
+		if ato == "" {
+			return withVariants("mpv/"+n, "mpv/antigas/"+n)
+		}
+		return withVariants(ato+"mpv/mpv"+n, ato+"mpv/"+n)
+	case TipoDecreto:
+		switch {
+		case ato != "":
+			return withVariants(ato+"decreto/d"+n, ato+"decreto/d"+dotted)
+		case ref.Ano >= 2001:
+			return withVariants(fmt.Sprintf("decreto/%d/d%s", ref.Ano, n), fmt.Sprintf("decreto/%d/d%s", ref.Ano, dotted))
+		}
+		return withVariants("decreto/d"+n, "decreto/d"+dotted)
+	}
+
+	switch {
+	case ato != "":
+		return withVariants(ato+"lei/l"+n, ato+"lei/l"+dotted)
+	case ref.Ano >= 2001:
+		return withVariants(fmt.Sprintf("leis/%d/l%s", ref.Ano, n), fmt.Sprintf("leis/%d/l%s", ref.Ano, dotted))
+	}
+	return withVariants("leis/l"+n, "leis/l"+dotted)
+}
+
+// matchesTitulo descarta páginas de outra norma (o Planalto às vezes redireciona
+// endereços antigos para a página inicial)
+func matchesTitulo(law Law, ref Reference) bool {
+	if ref.Tipo == TipoConstituicao || ref.Numero == 0 {
+		return true
+	}
+	titulo := strings.ReplaceAll(law.Titulo, ".", "")
+	return strings.Contains(titulo, strconv.Itoa(ref.Numero))
+}
+
+func (c *Client) get(ctx context.Context, rawURL string) (Law, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return Law{}, err
+	}
+	req.Header.Set("Accept", "text/html")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return Law{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return Law{}, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Law{}, fmt.Errorf("planalto: status %d em %s", resp.StatusCode, rawURL)
+	}
+	return Parse(io.LimitReader(resp.Body, maxPageSize), resp.Header.Get("Content-Type"))
+}
+
+// Fetch baixa a norma, tentando os endereços conhecidos até encontrar uma
+// página com artigos
+func (c *Client) Fetch(ctx context.Context, ref Reference) (Law, error) {
+	var lastErr error = ErrNotFound
+	for _, path := range candidatePaths(ref) {
+		rawURL := c.baseURL + "/" + path
+		law, err := c.get(ctx, rawURL)
+		if err != nil {
+			if ctx.Err() != nil {
+				return Law{}, ctx.Err()
+			}
+			if !errors.Is(err, ErrNotFound) {
+				lastErr = err
+			}
+			continue
+		}
+		if !matchesTitulo(law, ref) {
+			continue
+		}
+
+		law.Key = ref.Key()
+		law.Tipo = ref.Tipo
+		law.Numero = ref.Numero
+		law.Ano = ref.Ano
+		law.URL = rawURL
+		law.FetchedAt = time.Now().UTC()
+		if law.Titulo == "" {
+			law.Titulo = ref.Label()
+		}
+		return law, nil
+	}
+	return Law{}, fmt.Errorf("%s: %w", ref.Label(), lastErr)
+}
@@ -0,0 +1,337 @@
+package legislacao
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"chat-bot/internal/textnorm"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// Alinea é uma alínea ("a) ...")
+type Alinea struct {
+	Rotulo string `json:"rotulo"`
+	Texto  string `json:"texto"`
+}
+
+// Inciso é um inciso ("I - ...") com suas alíneas
+type Inciso struct {
+	Rotulo  string   `json:"rotulo"`
+	Texto   string   `json:"texto"`
+	Alineas []Alinea `json:"alineas,omitempty"`
+}
+
+// Paragrafo é um parágrafo ("§ 1º" ou "Parágrafo único") com seus incisos
+type Paragrafo struct {
+	Rotulo  string   `json:"rotulo"`
+	Texto   string   `json:"texto"`
+	Incisos []Inciso `json:"incisos,omitempty"`
+}
+
+// Artigo é um artigo com caput, incisos do caput e parágrafos. Secao é o último
+// título, capítulo ou seção que antecede o artigo.
+type Artigo struct {
+	Numero     string      `json:"numero"`
+	Caput      string      `json:"caput"`
+	Incisos    []Inciso    `json:"incisos,omitempty"`
+	Paragrafos []Paragrafo `json:"paragrafos,omitempty"`
+	Secao      string      `json:"secao,omitempty"`
+}
+
+// Law é uma norma com o texto dividido em artigos
+type Law struct {
+	Key       string    `json:"key"`
+	Tipo      string    `json:"tipo"`
+	Numero    int       `json:"numero,omitempty"`
+	Ano       int       `json:"ano,omitempty"`
+	Titulo    string    `json:"titulo"`
+	Ementa    string    `json:"ementa,omitempty"`
+	URL       string    `json:"url"`
+	Artigos   []Artigo  `json:"artigos"`
+	FetchedAt time.Time `json:"fetchedAt"`
+}
+
+// Artigo procura um artigo pelo número ("75", "5º", "1º-A")
+func (l Law) Artigo(numero string) (Artigo, bool) {
+	for _, a := range l.Artigos {
+		if a.Numero == numero {
+			return a, true
+		}
+	}
+	return Artigo{}, false
+}
+
+func writeIncisos(b *strings.Builder, incisos []Inciso) {
+	for _, inciso := range incisos {
+		fmt.Fprintf(b, "\n%s - %s", inciso.Rotulo, inciso.Texto)
+		for _, alinea := range inciso.Alineas {
+			fmt.Fprintf(b, "\n%s) %s", alinea.Rotulo, alinea.Texto)
+		}
+	}
+}
+
+// Text devolve o texto do artigo no formato em que é publicado
+func (a Artigo) Text() string {
+	var b strings.Builder
+	// "Art. 1º Texto", mas "Art. 10. Texto" e "Art. 1º-A. Texto"
+	rotulo := a.Numero
+	if !strings.HasSuffix(rotulo, "º") {
+		rotulo += "."
+	}
+	fmt.Fprintf(&b, "Art. %s %s", rotulo, a.Caput)
+	writeIncisos(&b, a.Incisos)
+	for _, p := range a.Paragrafos {
+		fmt.Fprintf(&b, "\n%s %s", p.Rotulo, p.Texto)
+		writeIncisos(&b, p.Incisos)
+	}
+	return b.String()
+}
+
+var (
+	artigoLine    = regexp.MustCompile(`^Art\.\s*(\d{1,2}(?:\.\d{3})+|\d{1,4})\s*[º°o]?(?:\s*-\s*([A-Z]))?\s*[.\-–—]?\s*(.*)$`)
+	paragrafoLine = regexp.MustCompile(`^§\s*(\d{1,3})\s*[º°o]?(?:\s*-\s*([A-Z]))?\s*[.\-–—]?\s*(.*)$`)
+	unicoLine     = regexp.MustCompile(`(?i)^Par[áa]grafo\s+[úu]nico\s*[.\-–—:]?\s*(.*)$`)
+	incisoLine    = regexp.MustCompile(`^([IVXLCDM]{1,7})(?:\s*-\s*([A-Z]))?\s*[-–—]\s*(.*)$`)
+	alineaLine    = regexp.MustCompile(`^([a-z])\)\s*(.*)$`)
+	headingLine   = regexp.MustCompile(`(?i)^(T[ÍI]TULO|CAP[ÍI]TULO|SE[ÇC][ÃA]O|SUBSE[ÇC][ÃA]O|LIVRO|PARTE)\s`)
+	tituloLine    = regexp.MustCompile(`^(LEI|DECRETO|MEDIDA PROVIS[ÓO]RIA|EMENDA CONSTITUCIONAL|CONSTITUI[ÇC][ÃA]O)\b`)
+)
+
+// blockTags encerram a linha de texto corrente
+var blockTags = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "tr": true, "td": true, "table": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true, "center": true, "blockquote": true,
+}
+
+// extractLines converte o HTML em linhas de texto, descartando trechos riscados
+// (redação revogada, que o Planalto mantém com <strike>) e scripts
+func extractLines(r io.Reader, contentType string) ([]string, error) {
+	decoded, err := charset.NewReader(r, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenizer := html.NewTokenizer(decoded)
+	var lines []string
+	var current strings.Builder
+	struck, skipped := 0, 0
+	flush := func() {
+		// strings.Fields também separa no espaço não separável (&nbsp;), comum no Planalto
+		line := strings.Join(strings.Fields(current.String()), " ")
+		if line != "" {
+			lines = append(lines, line)
+		}
+		current.Reset()
+	}
+
+	for {
+		tokenType := tokenizer.Next()
+		switch tokenType {
+		case html.ErrorToken:
+			if err := tokenizer.Err(); err != io.EOF {
+				return nil, err
+			}
+			flush()
+			return lines, nil
+		case html.TextToken:
+			if struck == 0 && skipped == 0 {
+				current.Write(tokenizer.Text())
+			}
+		case html.StartTagToken, html.SelfClosingTagToken, html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			tag := string(name)
+			isEnd := tokenType == html.EndTagToken
+			switch tag {
+			case "strike", "s", "del":
+				if isEnd && struck > 0 {
+					struck--
+				} else if !isEnd {
+					struck++
+				}
+			case "script", "style", "head":
+				if isEnd && skipped > 0 {
+					skipped--
+				} else if !isEnd {
+					skipped++
+				}
+			}
+			if blockTags[tag] {
+				flush()
+			}
+		}
+	}
+}
+
+type lawParser struct {
+	law   Law
+	secao string
+	// headingOpen indica que a linha seguinte pode ser o nome do título ou capítulo
+	headingOpen bool
+	prefix      string
+	artigo      *Artigo
+	paragrafo   *Paragrafo
+	inciso      *Inciso
+	alinea      *Alinea
+	index       map[string]int
+}
+
+func appendText(target *string, text string) {
+	if *target == "" {
+		*target = text
+		return
+	}
+	*target += " " + text
+}
+
+// continuation acrescenta a linha ao último dispositivo aberto
+func (p *lawParser) continuation(line string) {
+	switch {
+	case p.alinea != nil:
+		appendText(&p.alinea.Texto, line)
+	case p.inciso != nil:
+		appendText(&p.inciso.Texto, line)
+	case p.paragrafo != nil:
+		appendText(&p.paragrafo.Texto, line)
+	default:
+		appendText(&p.artigo.Caput, line)
+	}
+}
+
+func (p *lawParser) closeArtigo() {
+	if p.artigo == nil {
+		return
+	}
+	// Quando o Planalto mantém a redação antiga sem riscar, vale a última ocorrência
+	if i, ok := p.index[p.artigo.Numero]; ok {
+		p.law.Artigos[i] = *p.artigo
+	} else {
+		p.index[p.artigo.Numero] = len(p.law.Artigos)
+		p.law.Artigos = append(p.law.Artigos, *p.artigo)
+	}
+	p.artigo, p.paragrafo, p.inciso, p.alinea = nil, nil, nil, nil
+}
+
+func (p *lawParser) addInciso(inciso Inciso) {
+	if p.paragrafo != nil {
+		p.paragrafo.Incisos = append(p.paragrafo.Incisos, inciso)
+		p.inciso = &p.paragrafo.Incisos[len(p.paragrafo.Incisos)-1]
+	} else {
+		p.artigo.Incisos = append(p.artigo.Incisos, inciso)
+		p.inciso = &p.artigo.Incisos[len(p.artigo.Incisos)-1]
+	}
+	p.alinea = nil
+}
+
+func (p *lawParser) line(line string) bool {
+	folded := textnorm.Fold(line)
+	if strings.HasPrefix(folded, "brasilia, ") || strings.HasPrefix(folded, "este texto nao substitui") {
+		p.closeArtigo()
+		return false
+	}
+	// Só o título da parte muda a numeração; artigos do corpo da Constituição
+	// também citam o ADCT
+	if strings.Trim(folded, " .:") == "ato das disposicoes constitucionais transitorias" {
+		p.closeArtigo()
+		p.prefix = "ADCT-"
+		p.secao = line
+		return true
+	}
+
+	if m := artigoLine.FindStringSubmatch(line); m != nil {
+		p.closeArtigo()
+		p.headingOpen = false
+		p.artigo = &Artigo{Numero: p.prefix + formatArtigo(m[1], m[2]), Caput: m[3], Secao: p.secao}
+		return true
+	}
+	if headingLine.MatchString(line) {
+		p.closeArtigo()
+		p.secao = line
+		p.headingOpen = true
+		return true
+	}
+	if p.headingOpen {
+		p.headingOpen = false
+		if line == strings.ToUpper(line) {
+			p.secao += " – " + line
+			return true
+		}
+	}
+	if p.artigo == nil {
+		p.preamble(line)
+		return true
+	}
+	if m := paragrafoLine.FindStringSubmatch(line); m != nil {
+		rotulo := "§ " + formatArtigo(m[1], m[2])
+		p.artigo.Paragrafos = append(p.artigo.Paragrafos, Paragrafo{Rotulo: rotulo, Texto: m[3]})
+		p.paragrafo = &p.artigo.Paragrafos[len(p.artigo.Paragrafos)-1]
+		p.inciso, p.alinea = nil, nil
+		return true
+	}
+	if m := unicoLine.FindStringSubmatch(line); m != nil {
+		p.artigo.Paragrafos = append(p.artigo.Paragrafos, Paragrafo{Rotulo: "Parágrafo único.", Texto: m[1]})
+		p.paragrafo = &p.artigo.Paragrafos[len(p.artigo.Paragrafos)-1]
+		p.inciso, p.alinea = nil, nil
+		return true
+	}
+	if m := incisoLine.FindStringSubmatch(line); m != nil {
+		rotulo := m[1]
+		if m[2] != "" {
+			rotulo += "-" + m[2]
+		}
+		p.addInciso(Inciso{Rotulo: rotulo, Texto: m[3]})
+		return true
+	}
+	if m := alineaLine.FindStringSubmatch(line); m != nil && p.inciso != nil {
+		p.inciso.Alineas = append(p.inciso.Alineas, Alinea{Rotulo: m[1], Texto: m[2]})
+		p.alinea = &p.inciso.Alineas[len(p.inciso.Alineas)-1]
+		return true
+	}
+	p.continuation(line)
+	return true
+}
+
+// preamble identifica o título ("LEI Nº 14.133, DE 1º DE ABRIL DE 2021") e a ementa
+func (p *lawParser) preamble(line string) {
+	if p.law.Titulo == "" {
+		if tituloLine.MatchString(line) {
+			p.law.Titulo = line
+		}
+		return
+	}
+	if p.law.Ementa != "" || len([]rune(line)) < 30 {
+		return
+	}
+	folded := textnorm.Fold(line)
+	for _, prefix := range []string{"o presidente", "a presidenta", "o vice-presidente", "as mesas", "faco saber", "nos, representantes", "mensagem de veto", "(vide", "texto compilado", "conversao"} {
+		if strings.HasPrefix(folded, prefix) {
+			return
+		}
+	}
+	p.law.Ementa = line
+}
+
+// Parse converte uma página de norma do Planalto em artigos
+func Parse(r io.Reader, contentType string) (Law, error) {
+	lines, err := extractLines(r, contentType)
+	if err != nil {
+		return Law{}, err
+	}
+
+	p := &lawParser{index: map[string]int{}}
+	for _, line := range lines {
+		if !p.line(line) {
+			break
+		}
+	}
+	p.closeArtigo()
+
+	if len(p.law.Artigos) == 0 {
+		return p.law, fmt.Errorf("nenhum artigo encontrado")
+	}
+	return p.law, nil
+}
@@ -0,0 +1,146 @@
+package legislacao
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func parseFixture(t *testing.T, name string) Law {
+	t.Helper()
+	file, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	law, err := Parse(file, "text/html; charset=utf-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return law
+}
+
+func TestParseConstituicao(t *testing.T) {
+	law := parseFixture(t, "constituicao.htm")
+
+	if law.Titulo != "CONSTITUIÇÃO DA REPÚBLICA FEDERATIVA DO BRASIL DE 1988" {
+		t.Errorf("título inesperado: %q", law.Titulo)
+	}
+	var numeros []string
+	for _, a := range law.Artigos {
+		numeros = append(numeros, a.Numero)
+	}
+	want := []string{"1º", "2º", "5º", "60", "60-A", "ADCT-1º"}
+	if !reflect.DeepEqual(numeros, want) {
+		t.Fatalf("artigos %v, esperava %v", numeros, want)
+	}
+
+	art1, _ := law.Artigo("1º")
+	if len(art1.Incisos) != 2 || len(art1.Paragrafos) != 1 || art1.Paragrafos[0].Rotulo != "Parágrafo único." {
+		t.Errorf("estrutura do art. 1º inesperada: %+v", art1)
+	}
+	if art1.Secao != "TÍTULO I – DOS PRINCÍPIOS FUNDAMENTAIS" {
+		t.Errorf("seção do art. 1º: %q", art1.Secao)
+	}
+
+	art2, _ := law.Artigo("2º")
+	if strings.Contains(art2.Caput, "antiga") || !strings.HasPrefix(art2.Caput, "São Poderes") {
+		t.Errorf("o texto riscado deveria ser descartado: %q", art2.Caput)
+	}
+
+	art5, _ := law.Artigo("5º")
+	if len(art5.Paragrafos) != 2 || len(art5.Paragrafos[1].Incisos) != 1 || len(art5.Paragrafos[1].Incisos[0].Alineas) != 2 {
+		t.Errorf("parágrafos, incisos e alíneas do art. 5º inesperados: %+v", art5)
+	}
+
+	// A menção ao ADCT no corpo do art. 60 não muda a numeração dos seguintes
+	if _, ok := law.Artigo("60-A"); !ok {
+		t.Errorf("art. 60-A deveria continuar no corpo da Constituição")
+	}
+	if _, ok := law.Artigo("99"); ok {
+		t.Errorf("o texto depois da assinatura não deveria ser lido")
+	}
+}
+
+func TestArtigoText(t *testing.T) {
+	artigo := Artigo{
+		Numero:     "10",
+		Caput:      "Caput.",
+		Incisos:    []Inciso{{Rotulo: "I", Texto: "inciso;", Alineas: []Alinea{{Rotulo: "a", Texto: "alínea."}}}},
+		Paragrafos: []Paragrafo{{Rotulo: "§ 1º", Texto: "Parágrafo."}},
+	}
+	want := "Art. 10. Caput.\nI - inciso;\na) alínea.\n§ 1º Parágrafo."
+	if got := artigo.Text(); got != want {
+		t.Errorf("Text() = %q, esperava %q", got, want)
+	}
+}
+
+func TestParseReferences(t *testing.T) {
+	tests := []struct {
+		text string
+		want []Reference
+	}{
+		{"O que diz o art. 75 da Lei 14.133/2021?", []Reference{{Tipo: TipoLei, Numero: 14133, Ano: 2021, Artigo: "75"}}},
+		{"LC 101/2000 e MP 1.202/2024", []Reference{{Tipo: TipoLeiComplementar, Numero: 101, Ano: 2000}, {Tipo: TipoMedidaProvisoria, Numero: 1202, Ano: 2024}}},
+		{"art. 5º da Constituição Federal", []Reference{{Tipo: TipoConstituicao, Ano: 1988, Artigo: "5º"}}},
+		{"lc 10 em minúsculas não é norma", nil},
+	}
+	for _, tt := range tests {
+		if got := ParseReferences(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseReferences(%q) = %+v, esperava %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestMentionsLegislation(t *testing.T) {
+	tests := map[string]bool{
+		"Quando é a próxima eleição?":            false,
+		"Resultados das eleições municipais":     false,
+		"O que diz a lei sobre isso?":            true,
+		"O decreto foi publicado?":               true,
+		"O projeto já foi sancionado?":           true,
+		"Me explique a LGPD":                     true,
+		"Qual o valor do salário mínimo em 2026": false,
+	}
+	for text, want := range tests {
+		if got := MentionsLegislation(text); got != want {
+			t.Errorf("MentionsLegislation(%q) = %v, esperava %v", text, got, want)
+		}
+	}
+}
+
+func TestServiceCachesNotFound(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	store, err := NewStore(filepath.Join(t.TempDir(), "legislacao.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := &Service{Store: store, Client: &Client{baseURL: server.URL, httpClient: server.Client()}}
+	ref := Reference{Tipo: TipoLei, Numero: 99999, Ano: 2025}
+
+	if _, err := service.Law(context.Background(), ref); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("esperava ErrNotFound, obteve %v", err)
+	}
+	first := requests
+	if first == 0 {
+		t.Fatalf("nenhuma página consultada")
+	}
+	if _, err := service.Law(context.Background(), ref); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("esperava ErrNotFound na segunda consulta, obteve %v", err)
+	}
+	if requests != first {
+		t.Errorf("a norma inexistente foi procurada de novo: %d requisições, esperava %d", requests, first)
+	}
+}
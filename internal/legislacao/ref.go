@@ -0,0 +1,245 @@
+// Package legislacao baixa leis e decretos do portal do Planalto, separa o texto
+// em artigos, parágrafos, incisos e alíneas e guarda o resultado para consulta
+// por número ("Lei 14.133/2021, art. 75").
+package legislacao
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"chat-bot/internal/textnorm"
+)
+
+// Tipos de norma reconhecidos
+const (
+	TipoLei                  = "Lei"
+	TipoLeiComplementar      = "Lei Complementar"
+	TipoDecreto              = "Decreto"
+	TipoDecretoLei           = "Decreto-Lei"
+	TipoMedidaProvisoria     = "Medida Provisória"
+	TipoEmendaConstitucional = "Emenda Constitucional"
+	TipoConstituicao         = "Constituição Federal"
+)
+
+// Reference identifica uma norma e, opcionalmente, um artigo
+type Reference struct {
+	Tipo   string `json:"tipo"`
+	Numero int    `json:"numero,omitempty"`
+	Ano    int    `json:"ano,omitempty"`
+	Artigo string `json:"artigo,omitempty"`
+}
+
+// Key é a chave da norma no armazenamento ("lei-14133-2021")
+func (r Reference) Key() string {
+	tipo := strings.ReplaceAll(textnorm.Fold(r.Tipo), " ", "-")
+	if r.Tipo == TipoConstituicao {
+		return "constituicao-1988"
+	}
+	if r.Ano == 0 {
+		return fmt.Sprintf("%s-%d", tipo, r.Numero)
+	}
+	return fmt.Sprintf("%s-%d-%d", tipo, r.Numero, r.Ano)
+}
+
+// Label formata a referência como "Lei nº 14.133/2021, art. 75"
+func (r Reference) Label() string {
+	var label string
+	switch {
+	case r.Tipo == TipoConstituicao:
+		label = TipoConstituicao
+	case r.Ano > 0:
+		label = fmt.Sprintf("%s nº %s/%d", r.Tipo, formatNumero(r.Numero), r.Ano)
+	default:
+		label = fmt.Sprintf("%s nº %s", r.Tipo, formatNumero(r.Numero))
+	}
+	if r.Artigo != "" {
+		label += ", art. " + r.Artigo
+	}
+	return label
+}
+
+// formatNumero usa o separador de milhar como no Diário Oficial (14.133)
+func formatNumero(n int) string {
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "." + s[i:]
+	}
+	return s
+}
+
+// aliases associa nomes populares (sem acentos) às normas
+var aliases = []struct {
+	Names []string
+	Ref   Reference
+}{
+	{[]string{"constituicao federal", "constituicao", "cf 88"}, Reference{Tipo: TipoConstituicao, Ano: 1988}},
+	{[]string{"clt", "consolidacao das leis do trabalho"}, Reference{Tipo: TipoDecretoLei, Numero: 5452, Ano: 1943}},
+	{[]string{"codigo civil"}, Reference{Tipo: TipoLei, Numero: 10406, Ano: 2002}},
+	{[]string{"codigo penal"}, Reference{Tipo: TipoDecretoLei, Numero: 2848, Ano: 1940}},
+	{[]string{"codigo de processo civil", "cpc"}, Reference{Tipo: TipoLei, Numero: 13105, Ano: 2015}},
+	{[]string{"codigo de defesa do consumidor", "cdc"}, Reference{Tipo: TipoLei, Numero: 8078, Ano: 1990}},
+	{[]string{"estatuto da crianca e do adolescente", "eca"}, Reference{Tipo: TipoLei, Numero: 8069, Ano: 1990}},
+	{[]string{"lgpd", "lei geral de protecao de dados"}, Reference{Tipo: TipoLei, Numero: 13709, Ano: 2018}},
+	{[]string{"marco civil da internet"}, Reference{Tipo: TipoLei, Numero: 12965, Ano: 2014}},
+	{[]string{"nova lei de licitacoes", "lei de licitacoes"}, Reference{Tipo: TipoLei, Numero: 14133, Ano: 2021}},
+	{[]string{"lei de responsabilidade fiscal", "lrf"}, Reference{Tipo: TipoLeiComplementar, Numero: 101, Ano: 2000}},
+	{[]string{"lei de acesso a informacao", "lai"}, Reference{Tipo: TipoLei, Numero: 12527, Ano: 2011}},
+	{[]string{"lei maria da penha"}, Reference{Tipo: TipoLei, Numero: 11340, Ano: 2006}},
+	{[]string{"lei da ficha limpa"}, Reference{Tipo: TipoLeiComplementar, Numero: 135, Ano: 2010}},
+}
+
+var (
+	// normaPattern reconhece "Lei 14.133/2021", "Lei nº 14.133, de 1º de abril de 2021",
+	// "LC 101/2000", "Decreto-Lei nº 5.452/1943", "MP 1.202/2024" e "EC 132/2023"
+	normaPattern = regexp.MustCompile(`(?i)\b(lei\s+complementar|decreto[\s-]+lei|medida\s+provis[oó]ria|emenda\s+constitucional|lei|decreto|lcp?|mpv?|ec|dl)\s*(?:n[º°o.]*\s*)?(\d{1,2}(?:\.\d{3})+|\d{1,5})(?:\s*/\s*(\d{4}|\d{2})\b|,?\s+de\s+(?:\d{1,2}[º°o]?\s+de\s+[a-zç]+\s+de\s+)?(\d{4}))?`)
+	// artigoPattern reconhece "art. 75", "artigo 5º", "art. 1º-A"
+	artigoPattern = regexp.MustCompile(`(?i)\bart(?:igo)?\.?\s*(\d{1,2}(?:\.\d{3})+|\d{1,4})\s*[º°o]?(?:\s*-\s*([A-Z])\b)?`)
+)
+
+func normalizeTipo(raw string) string {
+	folded := strings.Join(strings.Fields(textnorm.Fold(strings.ReplaceAll(raw, "-", " "))), " ")
+	switch folded {
+	case "lei complementar", "lc", "lcp":
+		return TipoLeiComplementar
+	case "decreto lei", "dl":
+		return TipoDecretoLei
+	case "medida provisoria", "mp", "mpv":
+		return TipoMedidaProvisoria
+	case "emenda constitucional", "ec":
+		return TipoEmendaConstitucional
+	case "decreto":
+		return TipoDecreto
+	}
+	return TipoLei
+}
+
+func parseNumero(raw string) int {
+	n, _ := strconv.Atoi(strings.ReplaceAll(raw, ".", ""))
+	return n
+}
+
+func normalizeYear(raw string) int {
+	year, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0
+	}
+	if year < 100 {
+		if year > 30 {
+			return 1900 + year
+		}
+		return 2000 + year
+	}
+	return year
+}
+
+func formatArtigo(numero, letra string) string {
+	n := parseNumero(numero)
+	artigo := strconv.Itoa(n)
+	if n < 10 {
+		artigo += "º"
+	}
+	if letra != "" {
+		artigo += "-" + strings.ToUpper(letra)
+	}
+	return artigo
+}
+
+// nearestArtigo procura "art. N" imediatamente antes ("art. 75 da Lei ...") ou
+// depois ("Lei ..., art. 75") da menção à norma
+func nearestArtigo(text string, start, end int) string {
+	after := text[end:]
+	if len(after) > 40 {
+		after = after[:40]
+	}
+	if m := artigoPattern.FindStringSubmatchIndex(after); m != nil && strings.Trim(after[:m[0]], " ,;:-") == "" {
+		return formatArtigo(after[m[2]:m[3]], submatch(after, m, 2))
+	}
+
+	before := text[:start]
+	if len(before) > 40 {
+		before = before[len(before)-40:]
+	}
+	matches := artigoPattern.FindAllStringSubmatchIndex(before, -1)
+	if len(matches) > 0 {
+		m := matches[len(matches)-1]
+		gap := textnorm.Fold(strings.TrimSpace(before[m[1]:]))
+		if gap == "da" || gap == "do" || gap == "," || gap == "" {
+			return formatArtigo(before[m[2]:m[3]], submatch(before, m, 2))
+		}
+	}
+	return ""
+}
+
+func submatch(s string, m []int, group int) string {
+	if len(m) <= 2*group+1 || m[2*group] < 0 {
+		return ""
+	}
+	return s[m[2*group]:m[2*group+1]]
+}
+
+// legislationWords indicam uma pergunta sobre legislação mesmo sem citar uma norma
+var legislationWords = map[string]bool{
+	"lei": true, "leis": true, "decreto": true, "decretos": true,
+	"sancao": true, "sancionado": true, "sancionada": true, "sancionou": true,
+}
+
+// MentionsLegislation informa se o texto cita uma norma ou fala de leis e
+// decretos; compara palavras inteiras, para que "eleição" não conte como "lei"
+func MentionsLegislation(text string) bool {
+	for _, token := range textnorm.Tokens(text) {
+		if legislationWords[token] {
+			return true
+		}
+	}
+	return len(ParseReferences(text)) > 0
+}
+
+// ParseReferences extrai as normas citadas no texto, na ordem em que aparecem
+func ParseReferences(text string) []Reference {
+	var refs []Reference
+	seen := map[string]bool{}
+	add := func(ref Reference) {
+		key := ref.Key() + "|" + ref.Artigo
+		if !seen[key] {
+			seen[key] = true
+			refs = append(refs, ref)
+		}
+	}
+
+	for _, m := range normaPattern.FindAllStringSubmatchIndex(text, -1) {
+		tipo := submatch(text, m, 1)
+		// "LC", "MP", "EC" e "DL" só valem em maiúsculas para não confundir com palavras comuns
+		if len(tipo) <= 3 && !strings.EqualFold(tipo, "lei") && tipo != strings.ToUpper(tipo) {
+			continue
+		}
+		ref := Reference{Tipo: normalizeTipo(tipo), Numero: parseNumero(submatch(text, m, 2))}
+		if year := submatch(text, m, 3); year != "" {
+			ref.Ano = normalizeYear(year)
+		} else if year := submatch(text, m, 4); year != "" {
+			ref.Ano = normalizeYear(year)
+		}
+		if ref.Numero == 0 {
+			continue
+		}
+		ref.Artigo = nearestArtigo(text, m[0], m[1])
+		add(ref)
+	}
+
+	folded := " " + strings.Join(textnorm.Tokens(text), " ") + " "
+	for _, alias := range aliases {
+		for _, name := range alias.Names {
+			idx := strings.Index(folded, " "+name+" ")
+			if idx < 0 {
+				continue
+			}
+			ref := alias.Ref
+			// O artigo é procurado no texto normalizado, perto do nome popular
+			ref.Artigo = nearestArtigo(folded, idx+1, idx+1+len(name))
+			add(ref)
+			break
+		}
+	}
+	return refs
+}
@@ -0,0 +1,202 @@
+package legislacao
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultMaxAge define quando uma norma guardada é baixada de novo para
+// acompanhar alterações no texto compilado
+const DefaultMaxAge = 30 * 24 * time.Hour
+
+// notFoundTTL é por quanto tempo uma norma que não existe no Planalto deixa de
+// ser procurada; cada tentativa custa até seis downloads
+const notFoundTTL = 24 * time.Hour
+
+// ErrArtigoNotFound indica que a norma não tem o artigo pedido
+var ErrArtigoNotFound = errors.New("artigo não encontrado")
+
+// Store guarda as normas já baixadas em um arquivo JSON local
+type Store struct {
+	filePath string
+	laws     map[string]Law
+	mutex    sync.RWMutex
+}
+
+type storeFile struct {
+	Laws []Law `json:"laws"`
+}
+
+// NewStore cria o armazenamento e carrega o arquivo local, se existir
+func NewStore(filePath string) (*Store, error) {
+	s := &Store{filePath: filePath, laws: map[string]Law{}}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) load() error {
+	file, err := os.Open(s.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	var data storeFile
+	if err := json.NewDecoder(file).Decode(&data); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+
+	for _, law := range data.Laws {
+		s.laws[law.Key] = law
+	}
+	return nil
+}
+
+func (s *Store) saveLocked() error {
+	if dir := filepath.Dir(s.filePath); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	data := storeFile{Laws: s.allLocked()}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	tempPath := s.filePath + ".tmp"
+	if err := os.WriteFile(tempPath, payload, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, s.filePath)
+}
+
+func (s *Store) allLocked() []Law {
+	items := make([]Law, 0, len(s.laws))
+	for _, law := range s.laws {
+		items = append(items, law)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Key < items[j].Key })
+	return items
+}
+
+// Get devolve a norma guardada pela chave ("lei-14133-2021")
+func (s *Store) Get(key string) (Law, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	law, ok := s.laws[key]
+	return law, ok
+}
+
+// All devolve as normas guardadas ordenadas pela chave
+func (s *Store) All() []Law {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.allLocked()
+}
+
+// Put guarda ou substitui a norma
+func (s *Store) Put(law Law) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.laws[law.Key] = law
+	return s.saveLocked()
+}
+
+// Service consulta normas, baixando do Planalto as que ainda não estão guardadas
+type Service struct {
+	Store  *Store
+	Client *Client
+	MaxAge time.Duration
+
+	// notFound guarda, por chave, quando a norma não foi encontrada
+	notFound      map[string]time.Time
+	notFoundMutex sync.Mutex
+}
+
+func (s *Service) recentlyNotFound(key string, now time.Time) bool {
+	s.notFoundMutex.Lock()
+	defer s.notFoundMutex.Unlock()
+	missedAt, ok := s.notFound[key]
+	if ok && now.Sub(missedAt) >= notFoundTTL {
+		delete(s.notFound, key)
+		return false
+	}
+	return ok
+}
+
+func (s *Service) markNotFound(key string, now time.Time) {
+	s.notFoundMutex.Lock()
+	defer s.notFoundMutex.Unlock()
+	if s.notFound == nil {
+		s.notFound = map[string]time.Time{}
+	}
+	s.notFound[key] = now
+}
+
+// Law devolve a norma guardada ou a baixa do Planalto; se o download falhar,
+// uma cópia antiga é preferível a nenhuma
+func (s *Service) Law(ctx context.Context, ref Reference) (Law, error) {
+	maxAge := s.MaxAge
+	if maxAge == 0 {
+		maxAge = DefaultMaxAge
+	}
+
+	cached, ok := s.Store.Get(ref.Key())
+	if ok && time.Since(cached.FetchedAt) < maxAge {
+		return cached, nil
+	}
+	if !ok && s.recentlyNotFound(ref.Key(), time.Now()) {
+		return Law{}, fmt.Errorf("%s: %w", ref.Label(), ErrNotFound)
+	}
+
+	law, err := s.Client.Fetch(ctx, ref)
+	if err != nil {
+		if !ok && errors.Is(err, ErrNotFound) {
+			s.markNotFound(ref.Key(), time.Now())
+		}
+		if ok {
+			log.Printf("[LEGISLAÇÃO] Falha ao atualizar %s, usando cópia de %s: %v", ref.Label(), cached.FetchedAt.Format("02/01/2006"), err)
+			return cached, nil
+		}
+		return Law{}, err
+	}
+	if err := s.Store.Put(law); err != nil {
+		log.Printf("[LEGISLAÇÃO] Falha ao salvar %s: %v", ref.Label(), err)
+	}
+	return law, nil
+}
+
+// Lookup devolve a norma e, se a referência indicar um artigo, o texto dele
+func (s *Service) Lookup(ctx context.Context, ref Reference) (Law, *Artigo, error) {
+	law, err := s.Law(ctx, ref)
+	if err != nil {
+		return Law{}, nil, err
+	}
+	if ref.Artigo == "" {
+		return law, nil, nil
+	}
+	artigo, ok := law.Artigo(ref.Artigo)
+	if !ok {
+		return law, nil, fmt.Errorf("%s: %w", ref.Label(), ErrArtigoNotFound)
+	}
+	return law, &artigo, nil
+}
@@ -0,0 +1,174 @@
+// Package npsguard protege a pesquisa de satisfação contra envios em massa:
+// limita os envios por IP e por cliente, emite tokens assinados quando a
+// pesquisa é exibida e calcula a impressão digital do cliente.
+package npsguard
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrTokenInvalid indica token malformado ou com assinatura inválida
+	ErrTokenInvalid = errors.New("token da pesquisa inválido")
+	// ErrTokenExpired indica token emitido há mais tempo que a validade
+	ErrTokenExpired = errors.New("token da pesquisa expirado")
+	// ErrTokenReused indica token já usado em outro envio
+	ErrTokenReused = errors.New("token da pesquisa já utilizado")
+	// ErrTokenMismatch indica token emitido para outro cliente
+	ErrTokenMismatch = errors.New("token da pesquisa emitido para outro cliente")
+	// ErrTokenTooFast indica envio antes do tempo mínimo de resposta
+	ErrTokenTooFast = errors.New("resposta enviada rápido demais")
+)
+
+// Fingerprint resume o identificador do cliente e os cabeçalhos do navegador
+// em um hash; só o hash é gravado com a resposta
+func Fingerprint(salt []byte, parts ...string) string {
+	mac := hmac.New(sha256.New, salt)
+	mac.Write([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// Limiter conta eventos por chave em uma janela deslizante; o estado fica em
+// memória, então cada instância do servidor aplica o limite separadamente
+type Limiter struct {
+	limit  int
+	window time.Duration
+	hits   map[string][]time.Time
+	calls  int
+	mutex  sync.Mutex
+}
+
+// NewLimiter permite até limit eventos por chave a cada window
+func NewLimiter(limit int, window time.Duration) *Limiter {
+	return &Limiter{limit: limit, window: window, hits: map[string][]time.Time{}}
+}
+
+// Allow registra o evento e informa se ele cabe no limite; quando não cabe,
+// devolve quanto tempo falta para liberar
+func (l *Limiter) Allow(key string, now time.Time) (bool, time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.calls++
+	if l.calls%1000 == 0 {
+		l.pruneLocked(now)
+	}
+
+	recent := l.recentLocked(key, now)
+	if len(recent) >= l.limit {
+		l.hits[key] = recent
+		return false, recent[0].Add(l.window).Sub(now)
+	}
+	l.hits[key] = append(recent, now)
+	return true, 0
+}
+
+func (l *Limiter) recentLocked(key string, now time.Time) []time.Time {
+	hits := l.hits[key]
+	cutoff := now.Add(-l.window)
+	i := 0
+	for i < len(hits) && !hits[i].After(cutoff) {
+		i++
+	}
+	return hits[i:]
+}
+
+// pruneLocked descarta as chaves sem eventos na janela
+func (l *Limiter) pruneLocked(now time.Time) {
+	for key := range l.hits {
+		if recent := l.recentLocked(key, now); len(recent) > 0 {
+			l.hits[key] = recent
+		} else {
+			delete(l.hits, key)
+		}
+	}
+}
+
+// Signer emite e confere os tokens da pesquisa. Cada token vale uma vez; os
+// tokens usados ficam em memória até expirarem.
+type Signer struct {
+	secret []byte
+	ttl    time.Duration
+	// minAge é o tempo mínimo entre exibir a pesquisa e enviá-la
+	minAge time.Duration
+	used   map[string]time.Time
+	mutex  sync.Mutex
+}
+
+type tokenClaims struct {
+	Nonce       string `json:"n"`
+	IssuedAt    int64  `json:"iat"`
+	Fingerprint string `json:"fp"`
+}
+
+// NewSigner cria o emissor; tokens valem por ttl e só são aceitos depois de minAge
+func NewSigner(secret []byte, ttl, minAge time.Duration) *Signer {
+	return &Signer{secret: secret, ttl: ttl, minAge: minAge, used: map[string]time.Time{}}
+}
+
+func (s *Signer) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Issue emite um token ligado à impressão digital do cliente
+func (s *Signer) Issue(fingerprint string, now time.Time) (string, time.Time) {
+	nonce := make([]byte, 12)
+	if _, err := rand.Read(nonce); err != nil {
+		copy(nonce, now.Format(time.RFC3339Nano))
+	}
+	claims := tokenClaims{Nonce: hex.EncodeToString(nonce), IssuedAt: now.Unix(), Fingerprint: fingerprint}
+	raw, _ := json.Marshal(claims)
+	payload := base64.RawURLEncoding.EncodeToString(raw)
+	return payload + "." + s.sign(payload), now.Add(s.ttl)
+}
+
+// Verify confere assinatura, validade, cliente e uso único; o token só é
+// consumido quando é aceito
+func (s *Signer) Verify(token, fingerprint string, now time.Time) error {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.sign(payload))) {
+		return ErrTokenInvalid
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return ErrTokenInvalid
+	}
+	var claims tokenClaims
+	if err := json.Unmarshal(raw, &claims); err != nil || claims.Nonce == "" {
+		return ErrTokenInvalid
+	}
+
+	issuedAt := time.Unix(claims.IssuedAt, 0)
+	switch {
+	case now.Sub(issuedAt) > s.ttl:
+		return ErrTokenExpired
+	case claims.Fingerprint != fingerprint:
+		return ErrTokenMismatch
+	case now.Sub(issuedAt) < s.minAge:
+		return ErrTokenTooFast
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for nonce, expiresAt := range s.used {
+		if now.After(expiresAt) {
+			delete(s.used, nonce)
+		}
+	}
+	if _, reused := s.used[claims.Nonce]; reused {
+		return ErrTokenReused
+	}
+	s.used[claims.Nonce] = issuedAt.Add(s.ttl)
+	return nil
+}
@@ -0,0 +1,109 @@
+package npsguard
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFingerprint(t *testing.T) {
+	a := Fingerprint([]byte("sal"), "ip:203.0.113.7", "cliente-1", "Mozilla")
+	if len(a) != 32 || a != Fingerprint([]byte("sal"), "ip:203.0.113.7", "cliente-1", "Mozilla") {
+		t.Fatalf("impressão digital instável: %q", a)
+	}
+	different := map[string]string{
+		"outro sal":           Fingerprint([]byte("outro"), "ip:203.0.113.7", "cliente-1", "Mozilla"),
+		"outro IP":            Fingerprint([]byte("sal"), "ip:203.0.113.8", "cliente-1", "Mozilla"),
+		"partes deslocadas":   Fingerprint([]byte("sal"), "ip:203.0.113.7cliente-1", "", "Mozilla"),
+		"outro identificador": Fingerprint([]byte("sal"), "ip:203.0.113.7", "cliente-2", "Mozilla"),
+	}
+	for name, fp := range different {
+		if fp == a {
+			t.Errorf("%s deveria mudar a impressão digital", name)
+		}
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	l := NewLimiter(2, time.Hour)
+	start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.Allow("ip-1", start.Add(time.Duration(i)*time.Minute)); !ok {
+			t.Fatalf("envio %d recusado dentro do limite", i+1)
+		}
+	}
+	ok, retryAfter := l.Allow("ip-1", start.Add(10*time.Minute))
+	if ok || retryAfter != 50*time.Minute {
+		t.Errorf("esperava recusa com 50min de espera, obteve ok=%v espera=%v", ok, retryAfter)
+	}
+	if ok, _ := l.Allow("ip-2", start.Add(10*time.Minute)); !ok {
+		t.Errorf("o limite deveria ser por chave")
+	}
+	// A janela desliza: o primeiro envio sai dela depois de uma hora
+	if ok, _ := l.Allow("ip-1", start.Add(time.Hour+time.Second)); !ok {
+		t.Errorf("envio recusado depois de a janela liberar")
+	}
+}
+
+func TestLimiterPrunesIdleKeys(t *testing.T) {
+	l := NewLimiter(1, time.Minute)
+	start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	l.Allow("antiga", start)
+	for i := 0; i < 999; i++ {
+		l.Allow("nova", start.Add(time.Hour))
+	}
+	if _, ok := l.hits["antiga"]; ok {
+		t.Errorf("chave sem eventos na janela deveria ser descartada")
+	}
+}
+
+func TestSigner(t *testing.T) {
+	s := NewSigner([]byte("segredo"), time.Hour, 3*time.Second)
+	issued := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	token, expiresAt := s.Issue("cliente-1", issued)
+	if !expiresAt.Equal(issued.Add(time.Hour)) {
+		t.Errorf("validade inesperada: %v", expiresAt)
+	}
+	payload, signature, _ := strings.Cut(token, ".")
+	forged, _ := NewSigner([]byte("outro"), time.Hour, 0).Issue("cliente-1", issued)
+
+	tests := []struct {
+		name        string
+		token       string
+		fingerprint string
+		at          time.Duration
+		want        error
+	}{
+		{"assinatura de outro segredo", forged, "cliente-1", time.Minute, ErrTokenInvalid},
+		{"dados alterados", payload + "x." + signature, "cliente-1", time.Minute, ErrTokenInvalid},
+		{"sem assinatura", payload, "cliente-1", time.Minute, ErrTokenInvalid},
+		{"outro cliente", token, "cliente-2", time.Minute, ErrTokenMismatch},
+		{"rápido demais", token, "cliente-1", time.Second, ErrTokenTooFast},
+		{"expirado", token, "cliente-1", 2 * time.Hour, ErrTokenExpired},
+		{"válido", token, "cliente-1", time.Minute, nil},
+		{"reutilizado", token, "cliente-1", 2 * time.Minute, ErrTokenReused},
+	}
+	for _, tt := range tests {
+		if err := s.Verify(tt.token, tt.fingerprint, issued.Add(tt.at)); !errors.Is(err, tt.want) {
+			t.Errorf("%s: esperava %v, obteve %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+func TestSignerForgetsExpiredNonces(t *testing.T) {
+	s := NewSigner([]byte("segredo"), time.Hour, 0)
+	issued := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	token, _ := s.Issue("cliente-1", issued)
+	if err := s.Verify(token, "cliente-1", issued); err != nil {
+		t.Fatal(err)
+	}
+	other, _ := s.Issue("cliente-1", issued.Add(2*time.Hour))
+	if err := s.Verify(other, "cliente-1", issued.Add(2*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.used) != 1 {
+		t.Errorf("tokens expirados deveriam sair da memória: %d guardados", len(s.used))
+	}
+}
@@ -0,0 +1,557 @@
+// Package outbox guarda em disco as gravações que ainda não chegaram ao
+// armazenamento remoto e as reenvia em segundo plano, com espera crescente
+// entre as tentativas, até serem aceitas, recusadas de vez ou esgotarem
+// MaxAttempts; nesses dois últimos casos vão para o arquivo de descartadas.
+//
+// O arquivo é um log JSONL: cada mudança acrescenta uma linha sincronizada
+// com o disco (fsync), e o log é compactado quando acumula linhas antigas. A
+// fila é tão durável quanto o disco onde está o arquivo: no Cloud Run, ele
+// precisa estar em um volume montado para sobreviver à instância.
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// MaxEntries limita as gravações pendentes guardadas em disco
+	MaxEntries = 10000
+	// MaxAttempts é o número de tentativas antes de a entrada ir para as
+	// descartadas; com a espera máxima de 10 minutos, cerca de 16 horas
+	MaxAttempts = 100
+	// baseDelay e maxDelay são a primeira espera e o teto entre tentativas
+	baseDelay = 5 * time.Second
+	maxDelay  = 10 * time.Minute
+	// pollInterval é a frequência com que Run procura entradas vencidas
+	pollInterval = 5 * time.Second
+	// compactMinLines é o mínimo de linhas antigas no log antes de compactá-lo
+	compactMinLines = 1000
+)
+
+// ErrFull indica que a fila atingiu MaxEntries
+var ErrFull = errors.New("fila de gravações pendentes cheia")
+
+// permanentError marca um erro que nenhuma nova tentativa resolveria
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marca o erro de uma entrega como definitivo: Deliver não enfileira
+// a gravação, e uma entrada da fila vai direto para as descartadas
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent informa se o erro foi marcado com Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// Entry é uma gravação pendente. Key é a chave de idempotência: o destino deve
+// tratar uma segunda entrega com a mesma chave como já feita.
+type Entry struct {
+	Key         string          `json:"key"`
+	Payload     json.RawMessage `json:"payload,omitempty"`
+	Attempts    int             `json:"attempts,omitempty"`
+	NextAttempt time.Time       `json:"nextAttempt"`
+	LastError   string          `json:"lastError,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+}
+
+// record é uma linha do log: o estado atual da entrada ou, com Done, a saída
+// dela da fila (entregue ou descartada)
+type record struct {
+	Entry
+	Done bool `json:"done,omitempty"`
+}
+
+// Stats resume a fila para o health check
+type Stats struct {
+	Depth     int        `json:"depth"`
+	Oldest    *time.Time `json:"oldest,omitempty"`
+	LastError string     `json:"lastError,omitempty"`
+	// Delivered conta as entregas feitas desde o início do processo
+	Delivered int `json:"delivered"`
+	// DeadLetters conta as entradas no arquivo de descartadas
+	DeadLetters int `json:"deadLetters"`
+}
+
+// DeliverFunc entrega uma gravação; erro mantém a entrada na fila, a menos
+// que seja marcado com Permanent
+type DeliverFunc func(ctx context.Context, e Entry) error
+
+// Store guarda as gravações pendentes em um log JSONL local
+type Store struct {
+	filePath string
+	deadPath string
+	entries  []Entry
+	// file fica aberto para acrescentar linhas; lines conta as linhas do log
+	file        *os.File
+	size        int64
+	lines       int
+	delivered   int
+	deadLetters int
+	// inFlight marca as chaves sendo entregues, para Drain e Deliver não
+	// enviarem a mesma entrada ao mesmo tempo
+	inFlight map[string]bool
+	mutex    sync.Mutex
+}
+
+// DeadLetterPath devolve o arquivo das entradas descartadas
+// (data/nps-outbox.jsonl vira data/nps-outbox.dead.jsonl)
+func DeadLetterPath(filePath string) string {
+	ext := filepath.Ext(filePath)
+	return strings.TrimSuffix(filePath, ext) + ".dead" + ext
+}
+
+// NewStore cria a fila e carrega o log local, se existir, compactando-o
+func NewStore(filePath string) (*Store, error) {
+	s := &Store{filePath: filePath, deadPath: DeadLetterPath(filePath), inFlight: map[string]bool{}}
+	if dir := filepath.Dir(filePath); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close fecha o log
+func (s *Store) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// load refaz a fila a partir do log; linhas ilegíveis, como uma última linha
+// incompleta de uma queda durante a gravação, são ignoradas
+func (s *Store) load() error {
+	dead, err := countLines(s.deadPath)
+	if err != nil {
+		return err
+	}
+	s.deadLetters = dead
+
+	payload, err := os.ReadFile(s.filePath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	positions := map[string]int{}
+	lines := 0
+	for _, line := range bytes.Split(payload, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		lines++
+		var r record
+		if err := json.Unmarshal(line, &r); err != nil || r.Key == "" {
+			continue
+		}
+		i, known := positions[r.Key]
+		switch {
+		case r.Done && known:
+			s.entries[i].Key = ""
+			delete(positions, r.Key)
+		case r.Done:
+		case known:
+			s.entries[i] = r.Entry
+		default:
+			positions[r.Key] = len(s.entries)
+			s.entries = append(s.entries, r.Entry)
+		}
+	}
+	pending := s.entries[:0]
+	for _, e := range s.entries {
+		if e.Key != "" {
+			pending = append(pending, e)
+		}
+	}
+	s.entries = pending
+	s.lines = lines
+	if lines > len(s.entries) {
+		return s.compactLocked()
+	}
+	return nil
+}
+
+// countLines conta as linhas não vazias de um arquivo que pode não existir
+func countLines(path string) (int, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	count := 0
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) > 0 {
+			count++
+		}
+	}
+	return count, scanner.Err()
+}
+
+// encodeRecords serializa os registros, um por linha
+func encodeRecords(records []record) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, r := range records {
+		if err := encoder.Encode(r); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// appendFile acrescenta as linhas ao arquivo e sincroniza; se a escrita
+// falhar no meio, o arquivo volta ao tamanho anterior
+func appendFile(file *os.File, size int64, payload []byte) (int64, error) {
+	n, err := file.Write(payload)
+	if err == nil {
+		err = file.Sync()
+	}
+	if err != nil {
+		if n > 0 {
+			if truncErr := file.Truncate(size); truncErr != nil {
+				log.Printf("⚠️  não foi possível desfazer a gravação parcial em %s: %v", file.Name(), truncErr)
+			}
+		}
+		return size, err
+	}
+	return size + int64(n), nil
+}
+
+// appendLocked acrescenta registros ao log e o compacta quando as linhas
+// antigas passam de compactMinLines e do número de entradas pendentes
+func (s *Store) appendLocked(records ...record) error {
+	payload, err := encodeRecords(records)
+	if err != nil {
+		return err
+	}
+	if s.file == nil {
+		if err := s.openLocked(); err != nil {
+			return err
+		}
+	}
+	if s.size, err = appendFile(s.file, s.size, payload); err != nil {
+		return err
+	}
+	s.lines += len(records)
+	if stale := s.lines - len(s.entries); stale > compactMinLines && stale > len(s.entries) {
+		if err := s.compactLocked(); err != nil {
+			log.Printf("⚠️  não foi possível compactar a fila de gravações pendentes: %v", err)
+		}
+	}
+	return nil
+}
+
+// openLocked abre o log para acrescentar linhas
+func (s *Store) openLocked() error {
+	file, err := os.OpenFile(s.filePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	s.file, s.size = file, info.Size()
+	return nil
+}
+
+// compactLocked reescreve o log só com as entradas pendentes: grava um
+// arquivo temporário, sincroniza, troca pelo atual e sincroniza o diretório
+func (s *Store) compactLocked() error {
+	records := make([]record, len(s.entries))
+	for i, e := range s.entries {
+		records[i] = record{Entry: e}
+	}
+	payload, err := encodeRecords(records)
+	if err != nil {
+		return err
+	}
+
+	tempPath := s.filePath + ".tmp"
+	temp, err := os.OpenFile(tempPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := temp.Write(payload); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	if s.file != nil {
+		s.file.Close()
+		s.file = nil
+	}
+	if err := os.Rename(tempPath, s.filePath); err != nil {
+		return err
+	}
+	if err := syncDir(filepath.Dir(s.filePath)); err != nil {
+		return err
+	}
+	s.lines = len(records)
+	return s.openLocked()
+}
+
+// syncDir grava no disco a entrada do diretório, para a troca de nome
+// sobreviver a uma queda
+func syncDir(dir string) error {
+	file, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return file.Sync()
+}
+
+// deadLetterLocked acrescenta a entrada ao arquivo de descartadas
+func (s *Store) deadLetterLocked(e Entry) error {
+	payload, err := encodeRecords([]record{{Entry: e}})
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(s.deadPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if _, err := appendFile(file, info.Size(), payload); err != nil {
+		return err
+	}
+	s.deadLetters++
+	return nil
+}
+
+// backoff calcula a espera depois de attempts tentativas, com 20% de variação
+// para as instâncias não insistirem todas no mesmo instante
+func backoff(attempts int) time.Duration {
+	delay := baseDelay
+	for i := 1; i < attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	jitter := time.Duration(rand.Int63n(int64(delay)/5 + 1))
+	return delay - delay/10 + jitter
+}
+
+func (s *Store) indexLocked(key string) int {
+	for i, e := range s.entries {
+		if e.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Deliver tenta entregar a gravação e, só se a entrega falhar com um erro
+// temporário, a grava na fila para Run; nesse caso devolve o erro da entrega
+// com queued verdadeiro. Com queued falso e erro, a gravação não foi entregue
+// nem guardada (erro marcado com Permanent, fila cheia ou falha do disco).
+// Uma entrega aceita não escreve o arquivo, e uma chave já enfileirada não é
+// duplicada.
+func (s *Store) Deliver(ctx context.Context, key string, payload interface{}, deliver DeliverFunc) (queued bool, err error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return false, err
+	}
+	now := time.Now().UTC()
+
+	s.mutex.Lock()
+	if s.inFlight[key] || s.indexLocked(key) >= 0 {
+		s.mutex.Unlock()
+		return true, nil
+	}
+	s.inFlight[key] = true
+	s.mutex.Unlock()
+
+	entry := Entry{Key: key, Payload: raw, Attempts: 1, CreatedAt: now}
+	deliverErr := deliver(ctx, entry)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.inFlight, key)
+	if deliverErr == nil {
+		s.delivered++
+		return false, nil
+	}
+	if IsPermanent(deliverErr) {
+		return false, deliverErr
+	}
+	if len(s.entries) >= MaxEntries {
+		return false, errors.Join(deliverErr, ErrFull)
+	}
+	entry.LastError = deliverErr.Error()
+	entry.NextAttempt = time.Now().UTC().Add(backoff(1))
+	// A entrada entra na memória antes do log, para uma compactação feita por
+	// appendLocked não a deixar de fora
+	s.entries = append(s.entries, entry)
+	if err := s.appendLocked(record{Entry: entry}); err != nil {
+		s.entries = s.entries[:len(s.entries)-1]
+		return false, errors.Join(deliverErr, err)
+	}
+	return true, deliverErr
+}
+
+// finish remove a entrada entregue ou registra a falha; a espera já foi
+// agendada quando a tentativa começou. Um erro permanente ou a última das
+// MaxAttempts tentativas move a entrada para o arquivo de descartadas.
+func (s *Store) finish(key string, deliverErr error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.inFlight, key)
+	i := s.indexLocked(key)
+	if i < 0 {
+		return
+	}
+	e := s.entries[i]
+	if deliverErr != nil {
+		e.LastError = deliverErr.Error()
+	}
+	dead := deliverErr != nil && (IsPermanent(deliverErr) || e.Attempts >= MaxAttempts)
+	if dead {
+		if err := s.deadLetterLocked(e); err != nil {
+			// Sem o arquivo de descartadas, a entrada continua na fila
+			log.Printf("⚠️  não foi possível descartar a gravação pendente %s: %v", key, err)
+			dead = false
+		} else {
+			log.Printf("❌ gravação pendente %s descartada depois de %d tentativas: %v", key, e.Attempts, deliverErr)
+		}
+	}
+
+	if deliverErr == nil {
+		s.delivered++
+	}
+	// A memória muda antes do log, para uma compactação feita por appendLocked
+	// já refletir o resultado
+	r := record{Entry: e}
+	if deliverErr == nil || dead {
+		s.entries = append(s.entries[:i], s.entries[i+1:]...)
+		r = record{Entry: Entry{Key: key}, Done: true}
+	} else {
+		s.entries[i] = e
+	}
+	if err := s.appendLocked(r); err != nil {
+		log.Printf("⚠️  não foi possível atualizar a fila de gravações pendentes: %v", err)
+	}
+}
+
+// due marca como em andamento as entradas vencidas, agenda a próxima tentativa
+// de cada uma e as devolve
+func (s *Store) due(now time.Time) []Entry {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var due []Entry
+	for i := range s.entries {
+		e := &s.entries[i]
+		if s.inFlight[e.Key] || now.Before(e.NextAttempt) {
+			continue
+		}
+		e.Attempts++
+		e.NextAttempt = now.Add(backoff(e.Attempts))
+		s.inFlight[e.Key] = true
+		due = append(due, *e)
+	}
+	return due
+}
+
+// Drain tenta entregar as entradas vencidas e devolve quantas foram aceitas
+func (s *Store) Drain(ctx context.Context, deliver DeliverFunc) int {
+	delivered := 0
+	for _, e := range s.due(time.Now().UTC()) {
+		if ctx.Err() != nil {
+			s.finish(e.Key, ctx.Err())
+			continue
+		}
+		err := deliver(ctx, e)
+		s.finish(e.Key, err)
+		if err == nil {
+			delivered++
+		} else {
+			log.Printf("⚠️  gravação pendente %s falhou (tentativa %d): %v", e.Key, e.Attempts, err)
+		}
+	}
+	return delivered
+}
+
+// Run reenvia as entradas vencidas até ctx ser cancelado
+func (s *Store) Run(ctx context.Context, deliver DeliverFunc) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n := s.Drain(ctx, deliver); n > 0 {
+				log.Printf("✅ %d gravações pendentes entregues (%d na fila)", n, s.Stats().Depth)
+			}
+		}
+	}
+}
+
+// Stats devolve o tamanho da fila, a entrada mais antiga e o último erro
+func (s *Store) Stats() Stats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stats := Stats{Depth: len(s.entries), Delivered: s.delivered, DeadLetters: s.deadLetters}
+	for i, e := range s.entries {
+		if i == 0 || e.CreatedAt.Before(*stats.Oldest) {
+			createdAt := e.CreatedAt
+			stats.Oldest = &createdAt
+		}
+		if e.LastError != "" {
+			stats.LastError = e.LastError
+		}
+	}
+	return stats
+}
@@ -0,0 +1,225 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var errUnavailable = errors.New("armazenamento indisponível")
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := NewStore(filepath.Join(t.TempDir(), "outbox.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// expire antecipa a próxima tentativa de todas as entradas
+func expire(s *Store) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for i := range s.entries {
+		s.entries[i].NextAttempt = time.Time{}
+	}
+}
+
+func TestDeliverSuccessDoesNotWrite(t *testing.T) {
+	store := newTestStore(t)
+	queued, err := store.Deliver(context.Background(), "r1", map[string]int{"score": 10}, func(ctx context.Context, e Entry) error { return nil })
+	if queued || err != nil {
+		t.Fatalf("entrega aceita: queued=%v err=%v", queued, err)
+	}
+	if _, err := os.Stat(store.filePath); !os.IsNotExist(err) {
+		t.Errorf("uma entrega aceita não deveria gravar o arquivo: %v", err)
+	}
+	if stats := store.Stats(); stats.Depth != 0 || stats.Delivered != 1 {
+		t.Errorf("estatísticas inesperadas: %+v", stats)
+	}
+}
+
+func TestDeliverFailureIsRetried(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	failing := func(ctx context.Context, e Entry) error { return errUnavailable }
+
+	queued, err := store.Deliver(ctx, "r1", map[string]int{"score": 3}, failing)
+	if !queued || !errors.Is(err, errUnavailable) {
+		t.Fatalf("esperava a entrada na fila com o erro da entrega: queued=%v err=%v", queued, err)
+	}
+	// A mesma chave não é enfileirada de novo
+	if queued, err := store.Deliver(ctx, "r1", map[string]int{"score": 3}, failing); !queued || err != nil {
+		t.Errorf("chave repetida: queued=%v err=%v", queued, err)
+	}
+
+	// A fila sobrevive ao reinício
+	reloaded, err := NewStore(store.filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := reloaded.Stats()
+	if stats.Depth != 1 || stats.LastError != errUnavailable.Error() {
+		t.Fatalf("fila recarregada inesperada: %+v", stats)
+	}
+
+	// Antes do prazo nada é reenviado
+	if n := reloaded.Drain(ctx, failing); n != 0 {
+		t.Errorf("entrada reenviada antes do prazo")
+	}
+	expire(reloaded)
+	if n := reloaded.Drain(ctx, failing); n != 0 || reloaded.entries[0].Attempts != 2 {
+		t.Errorf("esperava a segunda tentativa falhando: %d entregues, %+v", n, reloaded.entries)
+	}
+	if next := reloaded.entries[0].NextAttempt; !next.After(time.Now()) {
+		t.Errorf("a próxima tentativa deveria ficar no futuro: %v", next)
+	}
+
+	var payload string
+	expire(reloaded)
+	n := reloaded.Drain(ctx, func(ctx context.Context, e Entry) error {
+		payload = string(e.Payload)
+		return nil
+	})
+	if n != 1 || reloaded.Stats().Depth != 0 || payload != `{"score":3}` {
+		t.Errorf("esperava a entrega na terceira tentativa: %d entregues, conteúdo %s", n, payload)
+	}
+	if again, _ := NewStore(store.filePath); again.Stats().Depth != 0 {
+		t.Errorf("a entrada entregue deveria sair do arquivo")
+	}
+}
+
+// A primeira tentativa grava no destino, mas o erro chega ao remetente; a
+// segunda encontra o documento existente e o destino a trata como entregue
+func TestDeliverAlreadyWritten(t *testing.T) {
+	store := newTestStore(t)
+	written := map[string]bool{}
+	deliver := func(ctx context.Context, e Entry) error {
+		if written[e.Key] {
+			return nil
+		}
+		written[e.Key] = true
+		return context.DeadlineExceeded
+	}
+
+	if queued, _ := store.Deliver(context.Background(), "r1", "x", deliver); !queued {
+		t.Fatal("a tentativa com erro deveria ficar na fila")
+	}
+	expire(store)
+	if n := store.Drain(context.Background(), deliver); n != 1 || store.Stats().Depth != 0 {
+		t.Errorf("documento já gravado deveria contar como entregue: %d entregues, fila %d", n, store.Stats().Depth)
+	}
+	if len(written) != 1 {
+		t.Errorf("gravações inesperadas: %v", written)
+	}
+}
+
+func TestDeliverFullQueue(t *testing.T) {
+	store := newTestStore(t)
+	store.entries = make([]Entry, MaxEntries)
+	queued, err := store.Deliver(context.Background(), "r1", "x", func(ctx context.Context, e Entry) error { return errUnavailable })
+	if queued || !errors.Is(err, ErrFull) || !errors.Is(err, errUnavailable) {
+		t.Errorf("esperava ErrFull sem enfileirar: queued=%v err=%v", queued, err)
+	}
+	// Com a fila cheia, uma entrega aceita continua funcionando
+	if queued, err := store.Deliver(context.Background(), "r2", "x", func(ctx context.Context, e Entry) error { return nil }); queued || err != nil {
+		t.Errorf("entrega aceita com a fila cheia: queued=%v err=%v", queued, err)
+	}
+}
+
+func TestDeliverPermanentError(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	rejected := Permanent(errors.New("documento inválido"))
+
+	// Um erro permanente na primeira tentativa não entra na fila
+	if queued, err := store.Deliver(ctx, "r1", "x", func(ctx context.Context, e Entry) error { return rejected }); queued || !IsPermanent(err) {
+		t.Fatalf("erro permanente: queued=%v err=%v", queued, err)
+	}
+	if stats := store.Stats(); stats.Depth != 0 {
+		t.Errorf("erro permanente não deveria ficar na fila: %+v", stats)
+	}
+
+	// Na fila, um erro permanente move a entrada para as descartadas
+	store.Deliver(ctx, "r2", "y", func(ctx context.Context, e Entry) error { return errUnavailable })
+	expire(store)
+	store.Drain(ctx, func(ctx context.Context, e Entry) error { return rejected })
+	if stats := store.Stats(); stats.Depth != 0 || stats.DeadLetters != 1 {
+		t.Errorf("esperava a entrada descartada: %+v", stats)
+	}
+	dead, err := os.ReadFile(DeadLetterPath(store.filePath))
+	if err != nil || !strings.Contains(string(dead), `"key":"r2"`) {
+		t.Errorf("arquivo de descartadas: %q, %v", dead, err)
+	}
+	if reloaded, err := NewStore(store.filePath); err != nil || reloaded.Stats().Depth != 0 || reloaded.Stats().DeadLetters != 1 {
+		t.Errorf("fila recarregada: %+v, %v", reloaded.Stats(), err)
+	}
+}
+
+func TestDeliverMaxAttempts(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	failing := func(ctx context.Context, e Entry) error { return errUnavailable }
+	store.Deliver(ctx, "r1", "x", failing)
+	for i := 1; i < MaxAttempts; i++ {
+		expire(store)
+		store.Drain(ctx, failing)
+	}
+	if stats := store.Stats(); stats.Depth != 0 || stats.DeadLetters != 1 {
+		t.Errorf("depois de %d tentativas a entrada deveria ser descartada: %+v", MaxAttempts, stats)
+	}
+}
+
+func TestLogAppendsAndCompacts(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+	failing := func(ctx context.Context, e Entry) error { return errUnavailable }
+	store.Deliver(ctx, "r1", "x", failing)
+	store.Deliver(ctx, "r2", "y", failing)
+	expire(store)
+	store.Drain(ctx, func(ctx context.Context, e Entry) error {
+		if e.Key == "r1" {
+			return nil
+		}
+		return errUnavailable
+	})
+	// Duas entradas e duas atualizações, sem reescrever o arquivo
+	payload, _ := os.ReadFile(store.filePath)
+	if lines := strings.Count(string(payload), "\n"); lines != 4 {
+		t.Errorf("esperava 4 linhas no log, encontrei %d: %s", lines, payload)
+	}
+
+	// Uma última linha incompleta (queda no meio da gravação) é ignorada e o
+	// log é compactado na abertura
+	store.Close()
+	file, _ := os.OpenFile(store.filePath, os.O_WRONLY|os.O_APPEND, 0o644)
+	file.WriteString(`{"key":"r3","payl`)
+	file.Close()
+	reloaded, err := NewStore(store.filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reloaded.Close()
+	if stats := reloaded.Stats(); stats.Depth != 1 || reloaded.entries[0].Key != "r2" || reloaded.entries[0].Attempts != 2 {
+		t.Errorf("fila recarregada inesperada: %+v", reloaded.entries)
+	}
+	payload, _ = os.ReadFile(store.filePath)
+	if lines := strings.Count(string(payload), "\n"); lines != 1 {
+		t.Errorf("o log compactado deveria ter uma linha: %s", payload)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempts, base := range map[int]time.Duration{1: baseDelay, 2: 2 * baseDelay, 4: 8 * baseDelay, 30: maxDelay} {
+		delay := backoff(attempts)
+		if delay < base-base/10 || delay > base+base/10 {
+			t.Errorf("backoff(%d) = %v, esperava perto de %v", attempts, delay, base)
+		}
+	}
+}
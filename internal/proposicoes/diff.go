@@ -0,0 +1,65 @@
+package proposicoes
+
+// Change descreve o que mudou na tramitação de uma proposição entre duas sincronizações
+type Change struct {
+	ProposicaoID     string       `json:"proposicaoId"`
+	Label            string       `json:"label"`
+	Casa             string       `json:"casa"`
+	Ementa           string       `json:"ementa,omitempty"`
+	URL              string       `json:"url"`
+	Novas            []Tramitacao `json:"novas"`
+	SituacaoAnterior string       `json:"situacaoAnterior,omitempty"`
+	SituacaoAtual    string       `json:"situacaoAtual,omitempty"`
+}
+
+// SituacaoMudou informa se a situação da proposição foi alterada
+func (c Change) SituacaoMudou() bool {
+	return c.SituacaoAtual != "" && c.SituacaoAtual != c.SituacaoAnterior
+}
+
+// Empty informa se não houve movimentação
+func (c Change) Empty() bool {
+	return len(c.Novas) == 0 && !c.SituacaoMudou()
+}
+
+func lastSequencia(items []Tramitacao) int {
+	last := 0
+	for _, t := range items {
+		if t.Sequencia > last {
+			last = t.Sequencia
+		}
+	}
+	return last
+}
+
+func situacaoOf(items []Tramitacao, fallback string) string {
+	for i := len(items) - 1; i >= 0; i-- {
+		if items[i].Situacao != "" {
+			return items[i].Situacao
+		}
+	}
+	return fallback
+}
+
+// Diff compara a tramitação conhecida da proposição com a recém-baixada.
+// Eventos novos são os de sequência maior que a última conhecida; a situação
+// é a do evento mais recente que informa uma.
+func Diff(before Proposicao, after []Tramitacao) Change {
+	change := Change{
+		ProposicaoID:     before.ID,
+		Label:            before.Label(),
+		Casa:             before.Casa,
+		Ementa:           before.Ementa,
+		URL:              before.URL,
+		SituacaoAnterior: situacaoOf(before.Tramitacoes, before.Situacao),
+	}
+	change.SituacaoAtual = situacaoOf(after, change.SituacaoAnterior)
+
+	last := lastSequencia(before.Tramitacoes)
+	for _, t := range after {
+		if t.Sequencia > last {
+			change.Novas = append(change.Novas, t)
+		}
+	}
+	return change
+}
@@ -0,0 +1,96 @@
+package proposicoes
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"chat-bot/internal/camara"
+)
+
+var labelPattern = regexp.MustCompile(`(?i)^\s*([a-z]{2,5})\s*(?:n[º°o.]*\s*)?(\d{1,5})\s*[/\-\s]\s*(\d{2}|\d{4})\s*$`)
+
+// ParseLabel interpreta identificações como "PLP 108/2024", "pl 2338 2023" ou "PEC 45/19"
+func ParseLabel(label string) (sigla string, numero, ano int, ok bool) {
+	match := labelPattern.FindStringSubmatch(label)
+	if match == nil {
+		return "", 0, 0, false
+	}
+	numero, _ = strconv.Atoi(match[2])
+	ano, _ = strconv.Atoi(match[3])
+	if ano < 100 {
+		// Anos com dois dígitos: 88-99 são do século XX, os demais do XXI
+		if ano >= 88 {
+			ano += 1900
+		} else {
+			ano += 2000
+		}
+	}
+	return strings.ToUpper(match[1]), numero, ano, true
+}
+
+// Resolve encontra a proposição nas duas casas (ou apenas na informada).
+// Uma mesma proposição pode existir na Câmara e no Senado.
+func (s *Syncer) Resolve(ctx context.Context, label, casa string) ([]Proposicao, error) {
+	sigla, numero, ano, ok := ParseLabel(label)
+	if !ok {
+		return nil, fmt.Errorf("identificação inválida %q: use o formato \"PLP 108/2024\"", label)
+	}
+
+	var found []Proposicao
+	var errs []string
+
+	if casa == "" || casa == CasaCamara {
+		items, err := s.Camara.ListProposicoes(ctx, camara.ProposicoesQuery{SiglaTipo: []string{sigla}, Numero: numero, Ano: ano}, 5)
+		if err != nil {
+			errs = append(errs, "câmara: "+err.Error())
+		}
+		for _, p := range items {
+			if !strings.EqualFold(p.SiglaTipo, sigla) || p.Numero != numero || p.Ano != ano {
+				continue
+			}
+			found = append(found, Proposicao{
+				ID:        fmt.Sprintf("%s-%d", CasaCamara, p.ID),
+				Casa:      CasaCamara,
+				SourceID:  strconv.Itoa(p.ID),
+				SiglaTipo: p.SiglaTipo,
+				Numero:    p.Numero,
+				Ano:       p.Ano,
+				Ementa:    strings.TrimSpace(p.Ementa),
+				URL:       CamaraURL(p.ID),
+			})
+			break
+		}
+	}
+
+	if casa == "" || casa == CasaSenado {
+		items, err := s.Senado.PesquisarMaterias(ctx, sigla, numero, ano)
+		if err != nil {
+			errs = append(errs, "senado: "+err.Error())
+		}
+		for _, m := range items {
+			id := m.IdentificacaoMateria
+			if id.CodigoMateria == "" {
+				continue
+			}
+			found = append(found, Proposicao{
+				ID:        CasaSenado + "-" + id.CodigoMateria,
+				Casa:      CasaSenado,
+				SourceID:  id.CodigoMateria,
+				SiglaTipo: sigla,
+				Numero:    numero,
+				Ano:       ano,
+				Ementa:    strings.TrimSpace(m.DadosBasicosMateria.EmentaMateria),
+				URL:       SenadoURL(id.CodigoMateria),
+			})
+			break
+		}
+	}
+
+	if len(found) == 0 && len(errs) > 0 {
+		return nil, fmt.Errorf("falha ao buscar %s: %s", label, strings.Join(errs, "; "))
+	}
+	return found, nil
+}
@@ -0,0 +1,240 @@
+package proposicoes
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"reflect"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// sharedWriteTimeout limita cada publicação no armazenamento compartilhado
+const sharedWriteTimeout = 30 * time.Second
+
+// SyncState são os horários das últimas sincronizações
+type SyncState struct {
+	LastListSync       time.Time `firestore:"lastListSync"`
+	LastTramitacaoSync time.Time `firestore:"lastTramitacaoSync"`
+}
+
+// Shared guarda as proposições fora da instância. Só a instância líder do
+// agendador sincroniza com as APIs; as demais leem daqui o que ela gravou.
+type Shared interface {
+	// Put grava as proposições alteradas e o estado das sincronizações
+	Put(ctx context.Context, items []Proposicao, state SyncState) error
+	// Changes retorna as proposições gravadas a partir de since, o estado atual
+	// e o cursor para a próxima leitura
+	Changes(ctx context.Context, since time.Time) ([]Proposicao, SyncState, time.Time, error)
+}
+
+// FirestoreShared guarda uma proposição por documento, com o JSON completo e o
+// horário de gravação (do servidor) usado como cursor pelas outras instâncias
+type FirestoreShared struct {
+	client     *firestore.Client
+	collection string
+}
+
+type sharedDoc struct {
+	Payload   string    `firestore:"payload"`
+	UpdatedAt time.Time `firestore:"updatedAt,serverTimestamp"`
+}
+
+// NewFirestoreShared usa a coleção informada; o estado fica em <coleção>_estado/sync
+func NewFirestoreShared(client *firestore.Client, collection string) *FirestoreShared {
+	return &FirestoreShared{client: client, collection: collection}
+}
+
+func (f *FirestoreShared) stateDoc() *firestore.DocumentRef {
+	return f.client.Collection(f.collection + "_estado").Doc("sync")
+}
+
+func (f *FirestoreShared) Put(ctx context.Context, items []Proposicao, state SyncState) error {
+	writer := f.client.BulkWriter(ctx)
+	jobs := make([]*firestore.BulkWriterJob, 0, len(items)+1)
+	for _, p := range items {
+		payload, err := json.Marshal(p)
+		if err != nil {
+			writer.End()
+			return err
+		}
+		job, err := writer.Set(f.client.Collection(f.collection).Doc(p.ID), sharedDoc{Payload: string(payload)})
+		if err != nil {
+			writer.End()
+			return err
+		}
+		jobs = append(jobs, job)
+	}
+	job, err := writer.Set(f.stateDoc(), state)
+	if err != nil {
+		writer.End()
+		return err
+	}
+	jobs = append(jobs, job)
+	writer.End()
+
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *FirestoreShared) Changes(ctx context.Context, since time.Time) ([]Proposicao, SyncState, time.Time, error) {
+	var state SyncState
+	snapshot, err := f.stateDoc().Get(ctx)
+	if err != nil && status.Code(err) != codes.NotFound {
+		return nil, state, since, err
+	}
+	if err == nil {
+		if err := snapshot.DataTo(&state); err != nil {
+			return nil, state, since, err
+		}
+	}
+
+	// >= porque gravações diferentes podem ter o mesmo horário; reaplicar é inofensivo
+	iter := f.client.Collection(f.collection).Where("updatedAt", ">=", since).OrderBy("updatedAt", firestore.Asc).Documents(ctx)
+	defer iter.Stop()
+
+	cursor := since
+	var items []Proposicao
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, state, since, err
+		}
+		var data sharedDoc
+		if err := doc.DataTo(&data); err != nil {
+			return nil, state, since, err
+		}
+		var p Proposicao
+		if err := json.Unmarshal([]byte(data.Payload), &p); err != nil {
+			log.Printf("⚠️  [PROPOSIÇÕES] documento compartilhado %s ignorado: %v", doc.Ref.ID, err)
+			continue
+		}
+		items = append(items, p)
+		if data.UpdatedAt.After(cursor) {
+			cursor = data.UpdatedAt
+		}
+	}
+	return items, state, cursor, nil
+}
+
+// SetShared liga o armazenamento compartilhado; as alterações passam a ser
+// publicadas nele e Follow passa a trazer as feitas por outras instâncias
+func (s *Store) SetShared(shared Shared) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.shared = shared
+}
+
+// publish grava no armazenamento compartilhado as proposições alteradas.
+// Deve ser chamado sem a trava: a gravação é uma chamada de rede.
+func (s *Store) publish(items []Proposicao) error {
+	s.mutex.RLock()
+	shared := s.shared
+	state := SyncState{LastListSync: s.lastListSync, LastTramitacaoSync: s.lastTramitacaoSync}
+	s.mutex.RUnlock()
+	if shared == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sharedWriteTimeout)
+	defer cancel()
+	return shared.Put(ctx, items, state)
+}
+
+// Refresh aplica as proposições gravadas por outras instâncias desde a última leitura
+func (s *Store) Refresh(ctx context.Context) (int, error) {
+	s.mutex.RLock()
+	shared, since := s.shared, s.sharedCursor
+	s.mutex.RUnlock()
+	if shared == nil {
+		return 0, nil
+	}
+
+	items, state, cursor, err := shared.Changes(ctx, since)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	changed := 0
+	for _, p := range items {
+		// A leitura repete as gravações feitas no horário do cursor
+		merged := merge(s.items[p.ID], p)
+		if reflect.DeepEqual(merged, s.items[p.ID]) {
+			continue
+		}
+		s.items[p.ID] = merged
+		changed++
+	}
+	if state.LastListSync.After(s.lastListSync) {
+		s.lastListSync = state.LastListSync
+	}
+	if state.LastTramitacaoSync.After(s.lastTramitacaoSync) {
+		s.lastTramitacaoSync = state.LastTramitacaoSync
+	}
+	s.sharedCursor = cursor
+	if changed == 0 {
+		return 0, nil
+	}
+	return changed, s.saveLocked()
+}
+
+// merge combina a versão local com a lida do armazenamento compartilhado: a
+// tramitação vem da que foi sincronizada por último e os dados da listagem, da
+// que foi vista por último. Assim uma publicação que falhou não faz a
+// tramitação local voltar atrás (o que geraria alertas repetidos).
+func merge(local, remote Proposicao) Proposicao {
+	if local.ID == "" {
+		return remote
+	}
+	merged := remote
+	if local.SeenAt.After(remote.SeenAt) {
+		merged = local
+		merged.Tramitacoes = remote.Tramitacoes
+		merged.TramitacoesSyncedAt = remote.TramitacoesSyncedAt
+		merged.Situacao = remote.Situacao
+		merged.UltimaMovimentacao = remote.UltimaMovimentacao
+	}
+	if local.TramitacoesSyncedAt.After(remote.TramitacoesSyncedAt) {
+		merged.Tramitacoes = local.Tramitacoes
+		merged.TramitacoesSyncedAt = local.TramitacoesSyncedAt
+		merged.Situacao = local.Situacao
+		merged.UltimaMovimentacao = local.UltimaMovimentacao
+	}
+	return merged
+}
+
+// Follow executa Refresh periodicamente até o contexto ser cancelado
+func (s *Store) Follow(ctx context.Context, interval time.Duration) {
+	refresh := func() {
+		if n, err := s.Refresh(ctx); err != nil {
+			log.Printf("⚠️  [PROPOSIÇÕES] falha ao ler o armazenamento compartilhado: %v", err)
+		} else if n > 0 {
+			log.Printf("[PROPOSIÇÕES] %d proposições atualizadas a partir do armazenamento compartilhado", n)
+		}
+	}
+	refresh()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			refresh()
+		}
+	}
+}
@@ -0,0 +1,354 @@
+// Package proposicoes mantém localmente as proposições com movimentação recente
+// na Câmara e no Senado, junto com a tramitação de cada uma.
+package proposicoes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxStoredTramitacoes limita os eventos guardados por proposição (os mais recentes)
+const maxStoredTramitacoes = 100
+
+// Tramitacao é um evento da tramitação
+type Tramitacao struct {
+	Sequencia int    `json:"sequencia"`
+	Data      string `json:"data"`
+	Orgao     string `json:"orgao,omitempty"`
+	Descricao string `json:"descricao"`
+	Situacao  string `json:"situacao,omitempty"`
+	Despacho  string `json:"despacho,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+// Proposicao é uma proposição acompanhada. O ID segue o padrão do registro
+// de parlamentares: camara-<id> ou senado-<codigo>.
+type Proposicao struct {
+	ID                  string       `json:"id"`
+	Casa                string       `json:"casa"`
+	SourceID            string       `json:"sourceId"`
+	SiglaTipo           string       `json:"siglaTipo"`
+	Numero              int          `json:"numero"`
+	Ano                 int          `json:"ano"`
+	Ementa              string       `json:"ementa"`
+	URL                 string       `json:"url"`
+	Situacao            string       `json:"situacao,omitempty"`
+	UltimaMovimentacao  string       `json:"ultimaMovimentacao,omitempty"`
+	Tramitacoes         []Tramitacao `json:"tramitacoes,omitempty"`
+	SeenAt              time.Time    `json:"seenAt"`
+	TramitacoesSyncedAt time.Time    `json:"tramitacoesSyncedAt,omitempty"`
+}
+
+// Label formata a proposição como "PL 2338/2023"
+func (p Proposicao) Label() string {
+	return fmt.Sprintf("%s %d/%d", p.SiglaTipo, p.Numero, p.Ano)
+}
+
+// Store guarda as proposições em um arquivo JSON local
+type Store struct {
+	filePath           string
+	items              map[string]Proposicao
+	lastListSync       time.Time
+	lastTramitacaoSync time.Time
+	shared             Shared
+	sharedCursor       time.Time
+	mutex              sync.RWMutex
+}
+
+type storeFile struct {
+	LastListSync       time.Time    `json:"lastListSync"`
+	LastTramitacaoSync time.Time    `json:"lastTramitacaoSync"`
+	Proposicoes        []Proposicao `json:"proposicoes"`
+}
+
+// NewStore cria o armazenamento e carrega o arquivo local, se existir
+func NewStore(filePath string) (*Store, error) {
+	s := &Store{filePath: filePath, items: map[string]Proposicao{}}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) load() error {
+	file, err := os.Open(s.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	var data storeFile
+	if err := json.NewDecoder(file).Decode(&data); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+
+	for _, p := range data.Proposicoes {
+		s.items[p.ID] = p
+	}
+	s.lastListSync = data.LastListSync
+	s.lastTramitacaoSync = data.LastTramitacaoSync
+	return nil
+}
+
+func (s *Store) saveLocked() error {
+	if dir := filepath.Dir(s.filePath); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	data := storeFile{
+		LastListSync:       s.lastListSync,
+		LastTramitacaoSync: s.lastTramitacaoSync,
+		Proposicoes:        make([]Proposicao, 0, len(s.items)),
+	}
+	for _, p := range s.items {
+		data.Proposicoes = append(data.Proposicoes, p)
+	}
+	sort.Slice(data.Proposicoes, func(i, j int) bool { return data.Proposicoes[i].ID < data.Proposicoes[j].ID })
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	tempPath := s.filePath + ".tmp"
+	if err := os.WriteFile(tempPath, payload, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, s.filePath)
+}
+
+// Get retorna uma proposição
+func (s *Store) Get(id string) (Proposicao, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	p, ok := s.items[id]
+	return p, ok
+}
+
+// FindByLabel retorna as proposições já armazenadas com a sigla, o número e o ano informados
+func (s *Store) FindByLabel(sigla string, numero, ano int) []Proposicao {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var found []Proposicao
+	for _, p := range s.items {
+		if strings.EqualFold(p.SiglaTipo, sigla) && p.Numero == numero && p.Ano == ano {
+			found = append(found, p)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].ID < found[j].ID })
+	return found
+}
+
+// All retorna todas as proposições, ordenadas pelo ID
+func (s *Store) All() []Proposicao {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	items := make([]Proposicao, 0, len(s.items))
+	for _, p := range s.items {
+		items = append(items, p)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	return items
+}
+
+// Size retorna o número de proposições armazenadas
+func (s *Store) Size() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.items)
+}
+
+// LastListSync retorna o horário da última sincronização da lista de proposições
+func (s *Store) LastListSync() time.Time {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.lastListSync
+}
+
+// LastTramitacaoSync retorna o horário da última sincronização de tramitações
+func (s *Store) LastTramitacaoSync() time.Time {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.lastTramitacaoSync
+}
+
+// Track garante que a proposição esteja no armazenamento sem alterar a data em
+// que foi vista na listagem; usado para proposições acompanhadas por listas.
+func (s *Store) Track(p Proposicao) error {
+	s.mutex.Lock()
+	if _, ok := s.items[p.ID]; ok {
+		s.mutex.Unlock()
+		return nil
+	}
+	s.items[p.ID] = p
+	err := s.saveLocked()
+	s.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+	return s.publish([]Proposicao{p})
+}
+
+// Upsert grava as proposições vistas na última listagem, preservando a tramitação já baixada
+func (s *Store) Upsert(items []Proposicao, seenAt time.Time) error {
+	s.mutex.Lock()
+	updated := make([]Proposicao, 0, len(items))
+	for _, p := range items {
+		if existing, ok := s.items[p.ID]; ok {
+			p.Tramitacoes = existing.Tramitacoes
+			p.TramitacoesSyncedAt = existing.TramitacoesSyncedAt
+			if p.Situacao == "" {
+				p.Situacao = existing.Situacao
+			}
+			if p.UltimaMovimentacao == "" {
+				p.UltimaMovimentacao = existing.UltimaMovimentacao
+			}
+		}
+		p.SeenAt = seenAt
+		s.items[p.ID] = p
+		updated = append(updated, p)
+	}
+	s.lastListSync = seenAt
+	err := s.saveLocked()
+	s.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+	return s.publish(updated)
+}
+
+// PendingTramitacoes lista as proposições cuja tramitação precisa ser baixada:
+// nunca sincronizadas, vistas de novo na listagem após a última sincronização
+// ou sincronizadas há mais de maxAge. As proposições em always (acompanhadas
+// por listas) entram sempre e vêm primeiro; depois, as vistas mais recentemente.
+func (s *Store) PendingTramitacoes(limit int, maxAge time.Duration, now time.Time, always map[string]bool) []Proposicao {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var pending []Proposicao
+	for _, p := range s.items {
+		synced := p.TramitacoesSyncedAt
+		if always[p.ID] || synced.IsZero() || p.SeenAt.After(synced) || now.Sub(synced) > maxAge {
+			pending = append(pending, p)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		if always[pending[i].ID] != always[pending[j].ID] {
+			return always[pending[i].ID]
+		}
+		if !pending[i].SeenAt.Equal(pending[j].SeenAt) {
+			return pending[i].SeenAt.After(pending[j].SeenAt)
+		}
+		return pending[i].ID < pending[j].ID
+	})
+	if limit > 0 && len(pending) > limit {
+		pending = pending[:limit]
+	}
+	return pending
+}
+
+func sortTramitacoes(items []Tramitacao) []Tramitacao {
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Sequencia != items[j].Sequencia {
+			return items[i].Sequencia < items[j].Sequencia
+		}
+		return items[i].Data < items[j].Data
+	})
+	if len(items) > maxStoredTramitacoes {
+		items = items[len(items)-maxStoredTramitacoes:]
+	}
+	return items
+}
+
+// DiffTramitacoes retorna as mudanças das proposições informadas em relação ao
+// que era conhecido, sem gravar nada. Na primeira sincronização de uma
+// proposição nada é considerado mudança: ela serve de base para as próximas.
+func (s *Store) DiffTramitacoes(updates map[string][]Tramitacao) []Change {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var changes []Change
+	for id, items := range updates {
+		p, ok := s.items[id]
+		if !ok || p.TramitacoesSyncedAt.IsZero() {
+			continue
+		}
+		if change := Diff(p, sortTramitacoes(items)); !change.Empty() {
+			changes = append(changes, change)
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].ProposicaoID < changes[j].ProposicaoID })
+	return changes
+}
+
+// ApplyTramitacoes substitui a tramitação das proposições informadas. Deve ser
+// chamado só depois que as mudanças de DiffTramitacoes foram notificadas: uma
+// vez gravada, a tramitação nova deixa de ser mudança.
+func (s *Store) ApplyTramitacoes(updates map[string][]Tramitacao, syncedAt time.Time) error {
+	s.mutex.Lock()
+	updated := make([]Proposicao, 0, len(updates))
+	for id, items := range updates {
+		p, ok := s.items[id]
+		if !ok {
+			continue
+		}
+		items = sortTramitacoes(items)
+		p.Tramitacoes = items
+		p.TramitacoesSyncedAt = syncedAt
+		if len(items) > 0 {
+			p.UltimaMovimentacao = items[len(items)-1].Data
+			p.Situacao = situacaoOf(items, p.Situacao)
+		}
+		s.items[id] = p
+		updated = append(updated, p)
+	}
+	s.lastTramitacaoSync = syncedAt
+	err := s.saveLocked()
+	s.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+	return s.publish(updated)
+}
+
+// Recent retorna as proposições da casa com movimentação mais recente
+func (s *Store) Recent(casa string, limit int) []Proposicao {
+	s.mutex.RLock()
+	items := make([]Proposicao, 0, len(s.items))
+	for _, p := range s.items {
+		if casa == "" || p.Casa == casa {
+			p.Tramitacoes = nil
+			items = append(items, p)
+		}
+	}
+	s.mutex.RUnlock()
+
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].UltimaMovimentacao != items[j].UltimaMovimentacao {
+			return items[i].UltimaMovimentacao > items[j].UltimaMovimentacao
+		}
+		return items[i].ID < items[j].ID
+	})
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
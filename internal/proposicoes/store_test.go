@@ -0,0 +1,129 @@
+package proposicoes
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// memoryShared simula o armazenamento compartilhado entre instâncias
+type memoryShared struct {
+	items   map[string]Proposicao
+	state   SyncState
+	version time.Time
+	puts    int
+}
+
+func (m *memoryShared) Put(ctx context.Context, items []Proposicao, state SyncState) error {
+	m.version = m.version.Add(time.Second)
+	for _, p := range items {
+		m.items[p.ID] = p
+	}
+	m.state = state
+	m.puts++
+	return nil
+}
+
+func (m *memoryShared) Changes(ctx context.Context, since time.Time) ([]Proposicao, SyncState, time.Time, error) {
+	var items []Proposicao
+	if !m.version.Before(since) {
+		for _, p := range m.items {
+			items = append(items, p)
+		}
+	}
+	return items, m.state, m.version, nil
+}
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := NewStore(filepath.Join(t.TempDir(), "proposicoes.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestDiffTramitacoesDoesNotPersist(t *testing.T) {
+	store := newTestStore(t)
+	base := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	if err := store.Upsert([]Proposicao{{ID: "camara-1", Casa: CasaCamara, SiglaTipo: "PL", Numero: 1, Ano: 2026}}, base); err != nil {
+		t.Fatal(err)
+	}
+	first := map[string][]Tramitacao{"camara-1": {{Sequencia: 1, Data: "2026-10-01", Descricao: "Apresentação"}}}
+	if changes := store.DiffTramitacoes(first); len(changes) != 0 {
+		t.Fatalf("a primeira sincronização não deveria gerar mudanças: %+v", changes)
+	}
+	if err := store.ApplyTramitacoes(first, base); err != nil {
+		t.Fatal(err)
+	}
+
+	second := map[string][]Tramitacao{"camara-1": {
+		{Sequencia: 2, Data: "2026-10-02", Descricao: "Despacho", Situacao: "Aguardando parecer"},
+		{Sequencia: 1, Data: "2026-10-01", Descricao: "Apresentação"},
+	}}
+	for i := 0; i < 2; i++ {
+		changes := store.DiffTramitacoes(second)
+		if len(changes) != 1 || len(changes[0].Novas) != 1 || changes[0].SituacaoAtual != "Aguardando parecer" {
+			t.Fatalf("tentativa %d: mudanças inesperadas: %+v", i+1, changes)
+		}
+	}
+
+	if err := store.ApplyTramitacoes(second, base.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if changes := store.DiffTramitacoes(second); len(changes) != 0 {
+		t.Errorf("depois de gravar, a mesma tramitação não deveria ser mudança: %+v", changes)
+	}
+	p, _ := store.Get("camara-1")
+	if p.Situacao != "Aguardando parecer" || p.UltimaMovimentacao != "2026-10-02" {
+		t.Errorf("proposição não atualizada: %+v", p)
+	}
+}
+
+func TestSharedStoreFollowsLeader(t *testing.T) {
+	shared := &memoryShared{items: map[string]Proposicao{}}
+	leader, follower := newTestStore(t), newTestStore(t)
+	leader.SetShared(shared)
+	follower.SetShared(shared)
+
+	seen := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	if err := leader.Upsert([]Proposicao{{ID: "senado-9", Casa: CasaSenado, SiglaTipo: "PEC", Numero: 9, Ano: 2026}}, seen); err != nil {
+		t.Fatal(err)
+	}
+	updates := map[string][]Tramitacao{"senado-9": {{Sequencia: 1, Data: "2026-10-01", Situacao: "Em tramitação"}}}
+	if err := leader.ApplyTramitacoes(updates, seen.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if shared.puts != 2 {
+		t.Errorf("esperava 2 publicações, obteve %d", shared.puts)
+	}
+
+	n, err := follower.Refresh(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, ok := follower.Get("senado-9")
+	if n != 1 || !ok || len(p.Tramitacoes) != 1 {
+		t.Fatalf("a outra instância não recebeu a proposição: n=%d %+v", n, p)
+	}
+	if !follower.LastTramitacaoSync().Equal(seen.Add(time.Hour)) || !follower.LastListSync().Equal(seen) {
+		t.Errorf("estado das sincronizações não copiado: %v %v", follower.LastListSync(), follower.LastTramitacaoSync())
+	}
+
+	if n, _ := follower.Refresh(context.Background()); n != 0 {
+		t.Errorf("sem gravações novas, esperava 0 proposições, obteve %d", n)
+	}
+}
+
+func TestMergeKeepsNewerTramitacao(t *testing.T) {
+	older := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+	local := Proposicao{ID: "camara-1", Ementa: "antiga", SeenAt: older, TramitacoesSyncedAt: newer, Tramitacoes: []Tramitacao{{Sequencia: 1}, {Sequencia: 2}}}
+	remote := Proposicao{ID: "camara-1", Ementa: "nova", SeenAt: newer, TramitacoesSyncedAt: older, Tramitacoes: []Tramitacao{{Sequencia: 1}}}
+
+	merged := merge(local, remote)
+	if merged.Ementa != "nova" || len(merged.Tramitacoes) != 2 || !merged.TramitacoesSyncedAt.Equal(newer) {
+		t.Errorf("combinação inesperada: %+v", merged)
+	}
+}
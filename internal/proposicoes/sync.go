@@ -0,0 +1,225 @@
+package proposicoes
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"chat-bot/internal/camara"
+	"chat-bot/internal/senado"
+)
+
+// Casas, no mesmo formato do registro de parlamentares
+const (
+	CasaCamara = "camara"
+	CasaSenado = "senado"
+)
+
+// TrackedTypes são os tipos de proposição acompanhados; requerimentos e
+// indicações ficam de fora por serem muito numerosos.
+var TrackedTypes = []string{"PL", "PLP", "PEC", "MPV", "PDL"}
+
+const maxRecentPerCasa = 500
+
+// Syncer sincroniza proposições e tramitações com as APIs oficiais
+type Syncer struct {
+	Camara *camara.Client
+	Senado *senado.Client
+	Store  *Store
+}
+
+// SyncResult resume uma sincronização
+type SyncResult struct {
+	Camara  int      `json:"camara"`
+	Senado  int      `json:"senado"`
+	Changes []Change `json:"changes,omitempty"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+func isTracked(siglaTipo string) bool {
+	for _, t := range TrackedTypes {
+		if strings.EqualFold(siglaTipo, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// CamaraURL é a página pública da proposição na Câmara
+func CamaraURL(id int) string {
+	return fmt.Sprintf("https://www.camara.leg.br/propostas-legislativas/%d", id)
+}
+
+// SenadoURL é a página pública da matéria no Senado
+func SenadoURL(codigo string) string {
+	return "https://www25.senado.leg.br/web/atividade/materias/-/materia/" + codigo
+}
+
+// SyncRecent grava as proposições com movimentação nos últimos dias
+func (s *Syncer) SyncRecent(ctx context.Context, days int) (SyncResult, error) {
+	var result SyncResult
+	var items []Proposicao
+	now := time.Now()
+
+	camaraItems, err := s.Camara.ListProposicoes(ctx, camara.ProposicoesQuery{
+		SiglaTipo:  TrackedTypes,
+		DataInicio: now.AddDate(0, 0, -days).Format("2006-01-02"),
+		DataFim:    now.Format("2006-01-02"),
+	}, maxRecentPerCasa)
+	if err != nil {
+		result.Errors = append(result.Errors, "câmara: "+err.Error())
+	}
+	for _, p := range camaraItems {
+		items = append(items, Proposicao{
+			ID:        fmt.Sprintf("%s-%d", CasaCamara, p.ID),
+			Casa:      CasaCamara,
+			SourceID:  strconv.Itoa(p.ID),
+			SiglaTipo: p.SiglaTipo,
+			Numero:    p.Numero,
+			Ano:       p.Ano,
+			Ementa:    strings.TrimSpace(p.Ementa),
+			URL:       CamaraURL(p.ID),
+		})
+		result.Camara++
+	}
+
+	senadoItems, err := s.Senado.MateriasAtualizadas(ctx, days)
+	if err != nil {
+		result.Errors = append(result.Errors, "senado: "+err.Error())
+	}
+	for _, m := range senadoItems {
+		id := m.IdentificacaoMateria
+		if id.CodigoMateria == "" || !isTracked(id.SiglaSubtipoMateria) {
+			continue
+		}
+		numero, _ := strconv.Atoi(id.NumeroMateria)
+		ano, _ := strconv.Atoi(id.AnoMateria)
+		items = append(items, Proposicao{
+			ID:        CasaSenado + "-" + id.CodigoMateria,
+			Casa:      CasaSenado,
+			SourceID:  id.CodigoMateria,
+			SiglaTipo: id.SiglaSubtipoMateria,
+			Numero:    numero,
+			Ano:       ano,
+			Ementa:    strings.TrimSpace(m.EmentaMateria),
+			URL:       SenadoURL(id.CodigoMateria),
+		})
+		result.Senado++
+	}
+
+	if len(items) == 0 && len(result.Errors) > 0 {
+		return result, fmt.Errorf("falha ao sincronizar proposições: %s", strings.Join(result.Errors, "; "))
+	}
+	if err := s.Store.Upsert(items, now.UTC()); err != nil {
+		return result, err
+	}
+
+	log.Printf("[PROPOSIÇÕES] %d da Câmara e %d do Senado com movimentação recente", result.Camara, result.Senado)
+	return result, nil
+}
+
+// SyncTramitacoes baixa a tramitação das proposições pendentes (até limit por execução).
+// As proposições em always são consultadas em toda execução. As mudanças são
+// passadas a notify antes de a tramitação nova ser gravada: se notify falhar,
+// nada é gravado e a próxima execução encontra as mesmas mudanças de novo.
+func (s *Syncer) SyncTramitacoes(ctx context.Context, limit int, maxAge time.Duration, always map[string]bool, notify func(context.Context, []Change) error) (SyncResult, error) {
+	var result SyncResult
+	updates := map[string][]Tramitacao{}
+
+	for _, p := range s.Store.PendingTramitacoes(limit, maxAge, time.Now(), always) {
+		if ctx.Err() != nil {
+			break
+		}
+
+		var (
+			items []Tramitacao
+			err   error
+		)
+		switch p.Casa {
+		case CasaCamara:
+			items, err = s.camaraTramitacoes(ctx, p)
+		case CasaSenado:
+			items, err = s.senadoTramitacoes(ctx, p)
+		default:
+			continue
+		}
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", p.ID, err))
+			continue
+		}
+
+		updates[p.ID] = items
+		if p.Casa == CasaCamara {
+			result.Camara++
+		} else {
+			result.Senado++
+		}
+	}
+
+	changes := s.Store.DiffTramitacoes(updates)
+	result.Changes = changes
+	if notify != nil && len(changes) > 0 {
+		if err := notify(ctx, changes); err != nil {
+			return result, fmt.Errorf("falha ao notificar mudanças de tramitação: %w", err)
+		}
+	}
+	if err := s.Store.ApplyTramitacoes(updates, time.Now().UTC()); err != nil {
+		return result, err
+	}
+
+	log.Printf("[TRAMITAÇÕES] %d proposições atualizadas, %d com movimentação nova (%d erros)", len(updates), len(changes), len(result.Errors))
+	if len(updates) == 0 && len(result.Errors) > 0 {
+		return result, fmt.Errorf("falha ao sincronizar tramitações: %s", result.Errors[0])
+	}
+	return result, nil
+}
+
+func (s *Syncer) camaraTramitacoes(ctx context.Context, p Proposicao) ([]Tramitacao, error) {
+	id, err := strconv.Atoi(p.SourceID)
+	if err != nil {
+		return nil, err
+	}
+	eventos, err := s.Camara.Tramitacoes(ctx, id, "")
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]Tramitacao, 0, len(eventos))
+	for _, e := range eventos {
+		items = append(items, Tramitacao{
+			Sequencia: e.Sequencia,
+			Data:      e.DataHora,
+			Orgao:     e.SiglaOrgao,
+			Descricao: strings.TrimSpace(e.DescricaoTramitacao),
+			Situacao:  strings.TrimSpace(e.DescricaoSituacao),
+			Despacho:  strings.TrimSpace(e.Despacho),
+			URL:       e.URL,
+		})
+	}
+	return items, nil
+}
+
+func (s *Syncer) senadoTramitacoes(ctx context.Context, p Proposicao) ([]Tramitacao, error) {
+	movimentacoes, err := s.Senado.Movimentacoes(ctx, p.SourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]Tramitacao, 0, len(movimentacoes))
+	for _, m := range movimentacoes {
+		t := m.IdentificacaoTramitacao
+		sequencia, _ := strconv.Atoi(t.NumeroOrdemTramitacao)
+		items = append(items, Tramitacao{
+			Sequencia: sequencia,
+			Data:      t.DataTramitacao,
+			Orgao:     t.OrigemTramitacao.Local.SiglaLocal,
+			Descricao: strings.TrimSpace(t.TextoTramitacao),
+			Situacao:  strings.TrimSpace(t.Situacao.DescricaoSituacao),
+			URL:       p.URL,
+		})
+	}
+	return items, nil
+}
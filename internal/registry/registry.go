@@ -0,0 +1,314 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"chat-bot/internal/camara"
+	"chat-bot/internal/senado"
+)
+
+// Casas legislativas conhecidas pelo registro
+const (
+	CasaCamara = "camara"
+	CasaSenado = "senado"
+)
+
+// Politician é um parlamentar federal com seus identificadores oficiais
+type Politician struct {
+	ID              string    `json:"id"`
+	Casa            string    `json:"casa"`
+	ExternalID      string    `json:"idExterno"`
+	NomeParlamentar string    `json:"nomeParlamentar"`
+	NomeCivil       string    `json:"nomeCivil,omitempty"`
+	Partido         string    `json:"partido"`
+	UF              string    `json:"uf"`
+	FotoURL         string    `json:"fotoUrl,omitempty"`
+	EmExercicio     bool      `json:"emExercicio"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+// MakeID monta o identificador usado pela API ("camara-204554", "senado-5012")
+func MakeID(casa, externalID string) string {
+	return casa + "-" + externalID
+}
+
+// Registry guarda os parlamentares sincronizados das APIs de dados abertos
+type Registry struct {
+	filePath    string
+	politicians map[string]Politician
+	lastSync    time.Time
+	mutex       sync.RWMutex
+}
+
+type registryFile struct {
+	LastSync    time.Time    `json:"lastSync"`
+	Politicians []Politician `json:"politicians"`
+}
+
+// New cria o registro e carrega o arquivo local, se existir
+func New(filePath string) (*Registry, error) {
+	r := &Registry{
+		filePath:    filePath,
+		politicians: map[string]Politician{},
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Registry) load() error {
+	file, err := os.Open(r.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+	return r.decode(file)
+}
+
+func (r *Registry) decode(reader io.Reader) error {
+	var data registryFile
+	if err := json.NewDecoder(reader).Decode(&data); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+
+	for _, p := range data.Politicians {
+		r.politicians[p.ID] = p
+	}
+	r.lastSync = data.LastSync
+	return nil
+}
+
+func (r *Registry) saveLocked() error {
+	if dir := filepath.Dir(r.filePath); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	data := registryFile{LastSync: r.lastSync, Politicians: r.sortedLocked()}
+	payload, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tempPath := r.filePath + ".tmp"
+	if err := os.WriteFile(tempPath, payload, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, r.filePath)
+}
+
+// Restore substitui o conteúdo pelo arquivo publicado por outra instância
+// (a líder do agendador) e o grava no arquivo local
+func (r *Registry) Restore(payload []byte) error {
+	fresh := &Registry{politicians: map[string]Politician{}}
+	if err := fresh.decode(bytes.NewReader(payload)); err != nil {
+		return err
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.politicians, r.lastSync = fresh.politicians, fresh.lastSync
+	return r.saveLocked()
+}
+
+func (r *Registry) sortedLocked() []Politician {
+	list := make([]Politician, 0, len(r.politicians))
+	for _, p := range r.politicians {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// Get retorna um parlamentar pelo identificador
+func (r *Registry) Get(id string) (Politician, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	p, ok := r.politicians[id]
+	return p, ok
+}
+
+// All retorna todos os parlamentares ordenados pelo identificador
+func (r *Registry) All() []Politician {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.sortedLocked()
+}
+
+// Size retorna o número de parlamentares registrados
+func (r *Registry) Size() int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return len(r.politicians)
+}
+
+// LastSync retorna o horário da última sincronização completa
+func (r *Registry) LastSync() time.Time {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.lastSync
+}
+
+// SyncResult resume uma sincronização
+type SyncResult struct {
+	Deputados  int      `json:"deputados"`
+	Senadores  int      `json:"senadores"`
+	Inativados int      `json:"inativados"`
+	Errors     []string `json:"errors,omitempty"`
+}
+
+// detailWorkers limita as consultas simultâneas aos detalhes dos deputados
+// (nome civil), que na primeira sincronização são cerca de 513
+const detailWorkers = 8
+
+// fetchNomesCivis consulta o nome civil dos deputados com até workers
+// consultas simultâneas; falhas ficam de fora e são tentadas de novo na
+// próxima sincronização
+func fetchNomesCivis(ctx context.Context, ids []int, workers int, fetch func(context.Context, int) (string, error)) map[int]string {
+	jobs := make(chan int)
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	nomes := make(map[int]string, len(ids))
+	if workers < 1 {
+		workers = 1
+	}
+	for i := 0; i < workers && i < len(ids); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range jobs {
+				nome, err := fetch(ctx, id)
+				if err != nil || nome == "" {
+					continue
+				}
+				mutex.Lock()
+				nomes[id] = nome
+				mutex.Unlock()
+			}
+		}()
+	}
+	for _, id := range ids {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- id
+	}
+	close(jobs)
+	wg.Wait()
+	return nomes
+}
+
+// Sync atualiza o registro com os deputados e senadores em exercício.
+// Parlamentares que deixaram o exercício são mantidos, marcados como inativos.
+func (r *Registry) Sync(ctx context.Context, camaraClient *camara.Client, senadoClient *senado.Client) (SyncResult, error) {
+	var result SyncResult
+	now := time.Now().UTC()
+	fetched := map[string]Politician{}
+
+	deputados, err := camaraClient.ListDeputados(ctx, 0)
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("câmara: %v", err))
+	}
+	var missing []int
+	for _, d := range deputados {
+		if existing, ok := r.Get(MakeID(CasaCamara, strconv.Itoa(d.ID))); !ok || existing.NomeCivil == "" {
+			missing = append(missing, d.ID)
+		}
+	}
+	nomesCivis := fetchNomesCivis(ctx, missing, detailWorkers, func(ctx context.Context, id int) (string, error) {
+		detalhe, err := camaraClient.GetDeputado(ctx, id)
+		return detalhe.NomeCivil, err
+	})
+	for _, d := range deputados {
+		externalID := strconv.Itoa(d.ID)
+		p := Politician{
+			ID:              MakeID(CasaCamara, externalID),
+			Casa:            CasaCamara,
+			ExternalID:      externalID,
+			NomeParlamentar: d.Nome,
+			Partido:         d.SiglaPartido,
+			UF:              d.SiglaUF,
+			FotoURL:         d.URLFoto,
+			EmExercicio:     true,
+			UpdatedAt:       now,
+		}
+		if existing, ok := r.Get(p.ID); ok && existing.NomeCivil != "" {
+			p.NomeCivil = existing.NomeCivil
+		} else {
+			p.NomeCivil = nomesCivis[d.ID]
+		}
+		fetched[p.ID] = p
+		result.Deputados++
+	}
+
+	senadores, err := senadoClient.ListSenadoresAtuais(ctx)
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("senado: %v", err))
+	}
+	for _, s := range senadores {
+		id := s.IdentificacaoParlamentar
+		p := Politician{
+			ID:              MakeID(CasaSenado, id.CodigoParlamentar),
+			Casa:            CasaSenado,
+			ExternalID:      id.CodigoParlamentar,
+			NomeParlamentar: id.NomeParlamentar,
+			NomeCivil:       id.NomeCompletoParlamentar,
+			Partido:         id.SiglaPartidoParlamentar,
+			UF:              id.UFParlamentar,
+			FotoURL:         id.URLFotoParlamentar,
+			EmExercicio:     true,
+			UpdatedAt:       now,
+		}
+		fetched[p.ID] = p
+		result.Senadores++
+	}
+
+	if len(fetched) == 0 {
+		return result, errors.New("nenhum parlamentar obtido das fontes oficiais")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	syncedCasas := map[string]bool{CasaCamara: len(deputados) > 0, CasaSenado: len(senadores) > 0}
+	for id, p := range r.politicians {
+		if _, ok := fetched[id]; ok || !syncedCasas[p.Casa] || !p.EmExercicio {
+			continue
+		}
+		p.EmExercicio = false
+		p.UpdatedAt = now
+		r.politicians[id] = p
+		result.Inativados++
+	}
+	for id, p := range fetched {
+		r.politicians[id] = p
+	}
+	r.lastSync = now
+
+	if err := r.saveLocked(); err != nil {
+		return result, err
+	}
+
+	log.Printf("[REGISTRO] sincronizados %d deputados e %d senadores", result.Deputados, result.Senadores)
+	return result, nil
+}
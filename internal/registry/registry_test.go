@@ -0,0 +1,128 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestRegistry(t *testing.T) *Registry {
+	t.Helper()
+	r, err := New(filepath.Join(t.TempDir(), "politicians.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []Politician{
+		{ID: "camara-204554", Casa: CasaCamara, NomeParlamentar: "Arthur Lira", NomeCivil: "Arthur César Pereira de Lira", Partido: "PP", UF: "AL", EmExercicio: true},
+		{ID: "senado-5012", Casa: CasaSenado, NomeParlamentar: "Mário Lira", NomeCivil: "Mário Lira Filho", Partido: "PSD", UF: "PE", EmExercicio: true},
+		{ID: "camara-1", Casa: CasaCamara, NomeParlamentar: "Benedita da Silva", Partido: "PT", UF: "RJ", EmExercicio: true},
+	} {
+		r.politicians[p.ID] = p
+	}
+	return r
+}
+
+func TestSearch(t *testing.T) {
+	r := newTestRegistry(t)
+	tests := []struct {
+		query     string
+		casa      string
+		first     string
+		ambiguous bool
+		matchedOn string
+	}{
+		{query: "Arthur Lira", first: "camara-204554", matchedOn: "nomeParlamentar"},
+		{query: "Lira", first: "camara-204554", ambiguous: true, matchedOn: "nomeParlamentar"},
+		{query: "lira al", first: "camara-204554", matchedOn: "nomeParlamentar"},
+		{query: "ARTHUR LIRÁ", first: "camara-204554", matchedOn: "nomeParlamentar"},
+		{query: "artur lira", first: "camara-204554", matchedOn: "nomeParlamentar"},
+		{query: "deputado Arthur Lira", first: "camara-204554", matchedOn: "nomeParlamentar"},
+		{query: "César Pereira", first: "camara-204554", matchedOn: "nomeCivil"},
+		{query: "Lira", casa: CasaSenado, first: "senado-5012", matchedOn: "nomeParlamentar"},
+		{query: "bened", first: "camara-1", matchedOn: "nomeParlamentar"},
+		{query: "Zé Ninguém"},
+		{query: "de da"},
+	}
+	for _, tt := range tests {
+		result := r.Search(tt.query, tt.casa, 10)
+		if tt.first == "" {
+			if len(result.Candidates) != 0 {
+				t.Errorf("%q: esperava nenhum candidato, obteve %+v", tt.query, result.Candidates)
+			}
+			continue
+		}
+		if len(result.Candidates) == 0 {
+			t.Errorf("%q: nenhum candidato", tt.query)
+			continue
+		}
+		first := result.Candidates[0]
+		if first.Politician.ID != tt.first || result.Ambiguous != tt.ambiguous || first.MatchedOn != tt.matchedOn {
+			t.Errorf("%q: primeiro %s (%s, %.3f), ambígua=%v; esperava %s (%s), ambígua=%v",
+				tt.query, first.Politician.ID, first.MatchedOn, first.Score, result.Ambiguous, tt.first, tt.matchedOn, tt.ambiguous)
+		}
+	}
+
+	if got := r.Search("Lira", "", 1); len(got.Candidates) != 1 || !got.Ambiguous {
+		t.Errorf("o limite não deveria esconder a ambiguidade: %+v", got)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	r := newTestRegistry(t)
+	tests := map[string]string{
+		"Arthur Lira": "camara-204554",
+		"lira al":     "camara-204554",
+		"Lira":        "",
+		"Fulano":      "",
+	}
+	for query, want := range tests {
+		p, ok := r.Resolve(query)
+		if ok != (want != "") || p.ID != want {
+			t.Errorf("Resolve(%q) = %s, %v; esperava %q", query, p.ID, ok, want)
+		}
+	}
+}
+
+func TestMentionedIn(t *testing.T) {
+	r := newTestRegistry(t)
+	found := r.MentionedIn("Como o deputado ARTHUR LIRA votou na reforma?")
+	if len(found) != 1 || found[0].ID != "camara-204554" {
+		t.Errorf("menção não encontrada: %+v", found)
+	}
+	if found := r.MentionedIn("a lira é um instrumento"); len(found) != 0 {
+		t.Errorf("sobrenome solto não é menção: %+v", found)
+	}
+}
+
+func TestFetchNomesCivis(t *testing.T) {
+	ids := make([]int, 50)
+	for i := range ids {
+		ids[i] = i + 1
+	}
+	var running, peak int32
+	var mutex sync.Mutex
+	nomes := fetchNomesCivis(context.Background(), ids, 4, func(ctx context.Context, id int) (string, error) {
+		now := atomic.AddInt32(&running, 1)
+		mutex.Lock()
+		if now > peak {
+			peak = now
+		}
+		mutex.Unlock()
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		if id%10 == 0 {
+			return "", errors.New("indisponível")
+		}
+		return "Nome", nil
+	})
+	if len(nomes) != 45 {
+		t.Errorf("esperava 45 nomes (as falhas ficam de fora), obteve %d", len(nomes))
+	}
+	if peak > 4 {
+		t.Errorf("consultas simultâneas acima do limite: %d", peak)
+	}
+}
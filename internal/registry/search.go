@@ -0,0 +1,212 @@
+package registry
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"chat-bot/internal/textnorm"
+)
+
+// Candidate é um parlamentar encontrado na busca com a pontuação da correspondência
+type Candidate struct {
+	Politician Politician `json:"politician"`
+	Score      float64    `json:"score"`
+	MatchedOn  string     `json:"matchedOn"`
+}
+
+// ambiguityMargin é a diferença mínima de pontuação entre os dois primeiros
+// candidatos para considerar a resolução inequívoca
+const ambiguityMargin = 0.15
+
+var nameStopwords = map[string]struct{}{
+	"de": {}, "da": {}, "do": {}, "das": {}, "dos": {}, "e": {},
+	"deputado": {}, "deputada": {}, "senador": {}, "senadora": {}, "dep": {}, "sen": {},
+}
+
+func nameTokens(s string) []string {
+	tokens := textnorm.Tokens(s)
+	filtered := tokens[:0]
+	for _, t := range tokens {
+		if _, stop := nameStopwords[t]; !stop {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
+// tokenSimilarity compara um termo da busca com uma palavra do nome
+func tokenSimilarity(query, word string) float64 {
+	if query == word {
+		return 1
+	}
+	if len(query) >= 2 && strings.HasPrefix(word, query) {
+		return 0.85
+	}
+	if len(query) >= 4 {
+		dist := textnorm.Levenshtein(query, word)
+		if dist == 1 {
+			return 0.75
+		}
+		if dist == 2 && len(query) >= 7 {
+			return 0.6
+		}
+	}
+	return 0
+}
+
+func bestMatch(query string, words []string) float64 {
+	best := 0.0
+	for _, w := range words {
+		if s := tokenSimilarity(query, w); s > best {
+			best = s
+		}
+	}
+	return best
+}
+
+// score calcula a correspondência entre a busca e um parlamentar.
+// Cada termo é comparado ao nome parlamentar, ao nome civil e, como último
+// recurso, à UF e ao partido ("lira al" encontra Arthur Lira, de Alagoas).
+func score(queryTokens []string, foldedQuery string, p Politician) (float64, string) {
+	parl := nameTokens(p.NomeParlamentar)
+	civil := nameTokens(p.NomeCivil)
+	attrs := []string{textnorm.Fold(p.UF), textnorm.Fold(p.Partido)}
+
+	var total float64
+	nameHits := 0
+	matchedOn := "nomeCivil"
+	parlHits := 0
+
+	for _, q := range queryTokens {
+		parlScore := bestMatch(q, parl)
+		civilScore := bestMatch(q, civil)
+		tokenScore := math.Max(parlScore, civilScore)
+
+		if tokenScore > 0 {
+			nameHits++
+			if parlScore >= civilScore {
+				parlHits++
+			}
+		} else {
+			for _, a := range attrs {
+				if a != "" && q == a {
+					tokenScore = 0.5
+					break
+				}
+			}
+		}
+		total += tokenScore
+	}
+
+	if nameHits == 0 {
+		return 0, ""
+	}
+	if parlHits == nameHits {
+		matchedOn = "nomeParlamentar"
+	}
+
+	result := total / float64(len(queryTokens))
+	if foldedQuery == strings.Join(parl, " ") {
+		result += 0.25
+		matchedOn = "nomeParlamentar"
+	}
+	if p.EmExercicio {
+		result += 0.05
+	}
+	return math.Round(math.Min(result, 1)*1000) / 1000, matchedOn
+}
+
+// SearchResult é o resultado da resolução de um nome
+type SearchResult struct {
+	Query      string      `json:"query"`
+	Candidates []Candidate `json:"candidates"`
+	Ambiguous  bool        `json:"ambiguous"`
+}
+
+// minCandidateScore descarta correspondências muito fracas
+const minCandidateScore = 0.45
+
+// Search resolve um nome livre para os parlamentares mais prováveis,
+// ignorando acentos e maiúsculas. O filtro de casa é opcional.
+func (r *Registry) Search(query, casa string, limit int) SearchResult {
+	result := SearchResult{Query: query, Candidates: []Candidate{}}
+	queryTokens := nameTokens(query)
+	if len(queryTokens) == 0 {
+		return result
+	}
+	foldedQuery := strings.Join(queryTokens, " ")
+
+	r.mutex.RLock()
+	for _, p := range r.politicians {
+		if casa != "" && p.Casa != casa {
+			continue
+		}
+		s, matchedOn := score(queryTokens, foldedQuery, p)
+		if s < minCandidateScore {
+			continue
+		}
+		result.Candidates = append(result.Candidates, Candidate{Politician: p, Score: s, MatchedOn: matchedOn})
+	}
+	r.mutex.RUnlock()
+
+	sort.Slice(result.Candidates, func(i, j int) bool {
+		a, b := result.Candidates[i], result.Candidates[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Politician.NomeParlamentar != b.Politician.NomeParlamentar {
+			return a.Politician.NomeParlamentar < b.Politician.NomeParlamentar
+		}
+		return a.Politician.ID < b.Politician.ID
+	})
+
+	if len(result.Candidates) > 1 {
+		result.Ambiguous = result.Candidates[0].Score-result.Candidates[1].Score < ambiguityMargin
+	}
+	if limit > 0 && len(result.Candidates) > limit {
+		result.Candidates = result.Candidates[:limit]
+	}
+	return result
+}
+
+// Resolve devolve o parlamentar quando a busca tem um único candidato claro
+func (r *Registry) Resolve(query string) (Politician, bool) {
+	result := r.Search(query, "", 2)
+	if len(result.Candidates) == 0 || result.Ambiguous {
+		return Politician{}, false
+	}
+	return result.Candidates[0].Politician, true
+}
+
+// minSingleNameLength evita que nomes parlamentares de uma palavra curta
+// ("Moro", "Dino") sejam reconhecidos em qualquer frase
+const minSingleNameLength = 6
+
+// MentionedIn lista os parlamentares em exercício cujo nome parlamentar completo
+// aparece no texto, ignorando acentos e maiúsculas.
+func (r *Registry) MentionedIn(text string) []Politician {
+	folded := " " + strings.Join(nameTokens(text), " ") + " "
+	if strings.TrimSpace(folded) == "" {
+		return nil
+	}
+
+	r.mutex.RLock()
+	var found []Politician
+	for _, p := range r.politicians {
+		if !p.EmExercicio {
+			continue
+		}
+		parl := nameTokens(p.NomeParlamentar)
+		if len(parl) == 0 || (len(parl) == 1 && len(parl[0]) < minSingleNameLength) {
+			continue
+		}
+		if strings.Contains(folded, " "+strings.Join(parl, " ")+" ") {
+			found = append(found, p)
+		}
+	}
+	r.mutex.RUnlock()
+
+	sort.Slice(found, func(i, j int) bool { return found[i].ID < found[j].ID })
+	return found
+}
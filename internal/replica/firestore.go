@@ -0,0 +1,187 @@
+package replica
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// partSize fica abaixo do limite de 1 MiB por documento do Firestore
+const partSize = 900 * 1024
+
+// FirestoreBlobs guarda cada réplica em <coleção>/<nome>, com o hash da versão
+// publicada, e o conteúdo dividido em <coleção>/<nome>/partes. As partes da
+// versão anterior são mantidas para quem ainda estiver lendo; as mais antigas
+// são apagadas a cada publicação.
+type FirestoreBlobs struct {
+	client     *firestore.Client
+	collection string
+}
+
+type blobHead struct {
+	Hash      string    `firestore:"hash"`
+	Previous  string    `firestore:"previous"`
+	Parts     int       `firestore:"parts"`
+	Size      int       `firestore:"size"`
+	UpdatedAt time.Time `firestore:"updatedAt,serverTimestamp"`
+}
+
+type blobPart struct {
+	Data []byte `firestore:"data"`
+}
+
+// NewFirestoreBlobs usa a coleção informada
+func NewFirestoreBlobs(client *firestore.Client, collection string) *FirestoreBlobs {
+	return &FirestoreBlobs{client: client, collection: collection}
+}
+
+func (f *FirestoreBlobs) headDoc(name string) *firestore.DocumentRef {
+	return f.client.Collection(f.collection).Doc(name)
+}
+
+func (f *FirestoreBlobs) parts(name string) *firestore.CollectionRef {
+	return f.headDoc(name).Collection("partes")
+}
+
+// partID usa um prefixo do hash para separar as partes de cada versão
+func partID(hash string, index int) string {
+	return fmt.Sprintf("%s-%04d", hash[:16], index)
+}
+
+func (f *FirestoreBlobs) head(ctx context.Context, name string) (blobHead, error) {
+	var head blobHead
+	snapshot, err := f.headDoc(name).Get(ctx)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return head, nil
+		}
+		return head, err
+	}
+	err = snapshot.DataTo(&head)
+	return head, err
+}
+
+func (f *FirestoreBlobs) Head(ctx context.Context, name string) (string, error) {
+	head, err := f.head(ctx, name)
+	return head.Hash, err
+}
+
+func (f *FirestoreBlobs) Get(ctx context.Context, name, hash string) ([]byte, error) {
+	head, err := f.head(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if head.Hash != hash {
+		return nil, fmt.Errorf("réplica %s: versão %s substituída durante a leitura", name, hash[:12])
+	}
+
+	refs := make([]*firestore.DocumentRef, head.Parts)
+	for i := range refs {
+		refs[i] = f.parts(name).Doc(partID(hash, i))
+	}
+	snapshots, err := f.client.GetAll(ctx, refs)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 0, head.Size)
+	for _, snapshot := range snapshots {
+		if !snapshot.Exists() {
+			return nil, fmt.Errorf("réplica %s: parte %s ausente", name, snapshot.Ref.ID)
+		}
+		var part blobPart
+		if err := snapshot.DataTo(&part); err != nil {
+			return nil, err
+		}
+		data = append(data, part.Data...)
+	}
+	return data, nil
+}
+
+func (f *FirestoreBlobs) Put(ctx context.Context, name, hash string, data []byte) error {
+	current, err := f.head(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	// As partes são gravadas antes do cabeçalho, que só então aponta para elas
+	writer := f.client.BulkWriter(ctx)
+	var jobs []*firestore.BulkWriterJob
+	parts := 0
+	for offset := 0; offset < len(data) || parts == 0; offset += partSize {
+		end := min(offset+partSize, len(data))
+		job, err := writer.Set(f.parts(name).Doc(partID(hash, parts)), blobPart{Data: data[offset:end]})
+		if err != nil {
+			writer.End()
+			return err
+		}
+		jobs = append(jobs, job)
+		parts++
+	}
+	writer.End()
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			return err
+		}
+	}
+
+	head := blobHead{Hash: hash, Previous: current.Hash, Parts: parts, Size: len(data)}
+	if _, err := f.headDoc(name).Set(ctx, head); err != nil {
+		return err
+	}
+	return f.prune(ctx, name, hash, current.Hash)
+}
+
+// prune apaga as partes das versões anteriores à atual e à imediatamente anterior
+func (f *FirestoreBlobs) prune(ctx context.Context, name string, keep ...string) error {
+	iter := f.parts(name).Select().Documents(ctx)
+	defer iter.Stop()
+
+	var stale []*firestore.DocumentRef
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		kept := false
+		for _, hash := range keep {
+			if hash != "" && strings.HasPrefix(doc.Ref.ID, hash[:16]+"-") {
+				kept = true
+				break
+			}
+		}
+		if !kept {
+			stale = append(stale, doc.Ref)
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+
+	writer := f.client.BulkWriter(ctx)
+	jobs := make([]*firestore.BulkWriterJob, 0, len(stale))
+	for _, ref := range stale {
+		job, err := writer.Delete(ref)
+		if err != nil {
+			writer.End()
+			return err
+		}
+		jobs = append(jobs, job)
+	}
+	writer.End()
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+// Package replica copia os arquivos de dados da instância líder do agendador
+// para as demais. Só a líder ingere registro, votações, despesas e DOU; depois
+// de cada tarefa ela publica o arquivo resultante, e as outras instâncias
+// trazem a versão nova e a aplicam ao próprio armazenamento.
+package replica
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Restorer aplica o conteúdo publicado ao armazenamento local
+type Restorer interface {
+	Restore(payload []byte) error
+}
+
+// Blobs guarda as versões publicadas fora da instância
+type Blobs interface {
+	// Head retorna o hash da versão publicada, ou "" se não houver
+	Head(ctx context.Context, name string) (string, error)
+	// Get retorna o conteúdo da versão publicada com o hash informado
+	Get(ctx context.Context, name, hash string) ([]byte, error)
+	// Put publica uma nova versão
+	Put(ctx context.Context, name, hash string, data []byte) error
+}
+
+// Replica liga um arquivo de dados local ao armazenamento compartilhado
+type Replica struct {
+	name   string
+	path   string
+	target Restorer
+	blobs  Blobs
+	// hash é o SHA-256 da última versão publicada ou aplicada aqui
+	hash  string
+	mutex sync.Mutex
+}
+
+// New cria a réplica do arquivo path com o nome informado
+func New(name, path string, target Restorer, blobs Blobs) *Replica {
+	return &Replica{name: name, path: path, target: target, blobs: blobs}
+}
+
+// Name identifica a réplica no armazenamento compartilhado
+func (r *Replica) Name() string { return r.name }
+
+func hashOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Publish envia o arquivo local se ele mudou desde a última publicação
+func (r *Replica) Publish(ctx context.Context) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	raw, err := os.ReadFile(r.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	hash := hashOf(raw)
+	if hash == r.hash {
+		return nil
+	}
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(raw); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	if err := r.blobs.Put(ctx, r.name, hash, compressed.Bytes()); err != nil {
+		return err
+	}
+	r.hash = hash
+	return nil
+}
+
+// Refresh aplica a versão publicada se ela for diferente da local
+func (r *Replica) Refresh(ctx context.Context) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	hash, err := r.blobs.Head(ctx, r.name)
+	if err != nil || hash == "" || hash == r.hash {
+		return false, err
+	}
+	compressed, err := r.blobs.Get(ctx, r.name, hash)
+	if err != nil {
+		return false, err
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return false, err
+	}
+	raw, err := io.ReadAll(reader)
+	if err != nil {
+		return false, err
+	}
+	// Uma publicação nova pode ter trocado as partes durante a leitura
+	if hashOf(raw) != hash {
+		return false, fmt.Errorf("réplica %s: conteúdo não confere com a versão %s", r.name, hash[:12])
+	}
+	if err := r.target.Restore(raw); err != nil {
+		return false, err
+	}
+	r.hash = hash
+	return true, nil
+}
+
+// RefreshAll executa Refresh em cada réplica e registra as falhas no log
+func RefreshAll(ctx context.Context, replicas ...*Replica) {
+	for _, r := range replicas {
+		if updated, err := r.Refresh(ctx); err != nil {
+			log.Printf("⚠️  [RÉPLICA] falha ao ler %s do armazenamento compartilhado: %v", r.name, err)
+		} else if updated {
+			log.Printf("[RÉPLICA] %s atualizado a partir da instância líder", r.name)
+		}
+	}
+}
+
+// Follow executa RefreshAll a cada interval até o contexto ser cancelado
+func Follow(ctx context.Context, interval time.Duration, replicas ...*Replica) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			RefreshAll(ctx, replicas...)
+		}
+	}
+}
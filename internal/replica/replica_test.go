@@ -0,0 +1,83 @@
+package replica
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type memoryBlobs struct {
+	hash string
+	data []byte
+	puts int
+}
+
+func (m *memoryBlobs) Head(ctx context.Context, name string) (string, error) { return m.hash, nil }
+
+func (m *memoryBlobs) Get(ctx context.Context, name, hash string) ([]byte, error) {
+	return m.data, nil
+}
+
+func (m *memoryBlobs) Put(ctx context.Context, name, hash string, data []byte) error {
+	m.hash, m.data = hash, data
+	m.puts++
+	return nil
+}
+
+type restoreFunc func(payload []byte) error
+
+func (f restoreFunc) Restore(payload []byte) error { return f(payload) }
+
+func TestPublishAndRefresh(t *testing.T) {
+	ctx := context.Background()
+	blobs := &memoryBlobs{}
+	path := filepath.Join(t.TempDir(), "votes.json")
+
+	leader := New("votes", path, restoreFunc(func([]byte) error {
+		t.Fatal("a líder não deveria aplicar a própria publicação")
+		return nil
+	}), blobs)
+
+	// Sem arquivo local não há o que publicar
+	if err := leader.Publish(ctx); err != nil || blobs.puts != 0 {
+		t.Fatalf("publicação sem arquivo: err=%v, puts=%d", err, blobs.puts)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"votes":[1]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := leader.Publish(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := leader.Publish(ctx); err != nil || blobs.puts != 1 {
+		t.Fatalf("arquivo sem mudança foi publicado de novo: err=%v, puts=%d", err, blobs.puts)
+	}
+	if updated, err := leader.Refresh(ctx); err != nil || updated {
+		t.Fatalf("a líder já tem a versão publicada: updated=%v, err=%v", updated, err)
+	}
+
+	var restored []string
+	follower := New("votes", filepath.Join(t.TempDir(), "votes.json"), restoreFunc(func(payload []byte) error {
+		restored = append(restored, string(payload))
+		return nil
+	}), blobs)
+	if updated, err := follower.Refresh(ctx); err != nil || !updated {
+		t.Fatalf("Refresh: updated=%v, err=%v", updated, err)
+	}
+	if updated, _ := follower.Refresh(ctx); updated {
+		t.Error("a mesma versão não deveria ser aplicada duas vezes")
+	}
+	if len(restored) != 1 || restored[0] != `{"votes":[1]}` {
+		t.Errorf("conteúdo aplicado = %q", restored)
+	}
+
+	// Conteúdo que não confere com o hash é recusado
+	blobs.hash = hashOf([]byte("outro"))
+	if _, err := follower.Refresh(ctx); err == nil {
+		t.Error("esperava erro com conteúdo divergente do hash")
+	}
+	if len(restored) != 1 {
+		t.Error("conteúdo divergente não deveria ser aplicado")
+	}
+}
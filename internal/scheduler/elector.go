@@ -0,0 +1,104 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Elector decide se esta instância deve executar as tarefas agendadas
+type Elector interface {
+	// Acquire tenta obter (ou renovar) a liderança
+	Acquire(ctx context.Context) (bool, error)
+	// Holder identifica esta instância
+	Holder() string
+}
+
+// LocalElector é usado sem Firestore: a instância é sempre a líder
+type LocalElector struct{}
+
+func (LocalElector) Acquire(ctx context.Context) (bool, error) { return true, nil }
+
+func (LocalElector) Holder() string { return instanceID() }
+
+// FirestoreElector usa um documento do Firestore como trava com prazo de validade.
+// A líder renova a trava a cada rodada; se parar de renovar, outra instância
+// assume depois que o prazo expira.
+type FirestoreElector struct {
+	client *firestore.Client
+	doc    *firestore.DocumentRef
+	holder string
+	ttl    time.Duration
+}
+
+type leaderLock struct {
+	Holder    string    `firestore:"holder"`
+	ExpiresAt time.Time `firestore:"expiresAt"`
+	RenewedAt time.Time `firestore:"renewedAt"`
+}
+
+// NewFirestoreElector cria o eleitor usando o documento collection/name.
+// O prazo deve ser maior que o intervalo de verificação do agendador.
+func NewFirestoreElector(client *firestore.Client, collection, name string, ttl time.Duration) *FirestoreElector {
+	if ttl <= checkInterval {
+		ttl = 3 * checkInterval
+	}
+	return &FirestoreElector{
+		client: client,
+		doc:    client.Collection(collection).Doc(name),
+		holder: instanceID(),
+		ttl:    ttl,
+	}
+}
+
+func (e *FirestoreElector) Holder() string { return e.holder }
+
+func (e *FirestoreElector) Acquire(ctx context.Context) (bool, error) {
+	acquired := false
+	err := e.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		acquired = false
+
+		snapshot, err := tx.Get(e.doc)
+		if err != nil && status.Code(err) != codes.NotFound {
+			return err
+		}
+		var current *leaderLock
+		if err == nil {
+			current = &leaderLock{}
+			if err := snapshot.DataTo(current); err != nil {
+				return err
+			}
+		}
+
+		lock, ok := claim(current, e.holder, time.Now().UTC(), e.ttl)
+		if !ok {
+			return nil
+		}
+		acquired = true
+		return tx.Set(e.doc, lock)
+	})
+	return acquired, err
+}
+
+// claim decide se holder fica com a trava: quando não há trava, quando ela já
+// é de holder (renovação) ou quando o prazo da outra instância expirou
+func claim(current *leaderLock, holder string, now time.Time, ttl time.Duration) (leaderLock, bool) {
+	if current != nil && current.Holder != holder && now.Before(current.ExpiresAt) {
+		return leaderLock{}, false
+	}
+	return leaderLock{Holder: holder, ExpiresAt: now.Add(ttl), RenewedAt: now}, true
+}
+
+// instanceID combina a revisão do Cloud Run (quando existe), o host e o PID
+func instanceID() string {
+	host, _ := os.Hostname()
+	if revision := os.Getenv("K_REVISION"); revision != "" {
+		return fmt.Sprintf("%s/%s/%d", revision, host, os.Getpid())
+	}
+	return fmt.Sprintf("%s/%d", host, os.Getpid())
+}
@@ -0,0 +1,62 @@
+package scheduler
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+)
+
+// Requests encaminha à instância líder os disparos manuais recebidos pelas
+// demais, que não executam tarefas
+type Requests interface {
+	// Push registra o pedido de execução da tarefa
+	Push(ctx context.Context, name string) error
+	// Take retira e retorna os pedidos pendentes
+	Take(ctx context.Context) ([]string, error)
+}
+
+// FirestoreRequests guarda um documento por tarefa pedida em
+// collection/name/pedidos, ao lado da trava de liderança
+type FirestoreRequests struct {
+	client     *firestore.Client
+	collection *firestore.CollectionRef
+}
+
+type request struct {
+	Job         string      `firestore:"job"`
+	RequestedAt interface{} `firestore:"requestedAt"`
+}
+
+// NewFirestoreRequests usa o mesmo documento da trava de NewFirestoreElector
+func NewFirestoreRequests(client *firestore.Client, collection, name string) *FirestoreRequests {
+	return &FirestoreRequests{client: client, collection: client.Collection(collection).Doc(name).Collection("pedidos")}
+}
+
+func (f *FirestoreRequests) Push(ctx context.Context, name string) error {
+	// Pedidos repetidos da mesma tarefa viram um só
+	_, err := f.collection.Doc(name).Set(ctx, request{Job: name, RequestedAt: firestore.ServerTimestamp})
+	return err
+}
+
+func (f *FirestoreRequests) Take(ctx context.Context) ([]string, error) {
+	var names []string
+	err := f.client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		names = nil
+		docs, err := tx.Documents(f.collection).GetAll()
+		if err != nil {
+			return err
+		}
+		for _, doc := range docs {
+			var r request
+			if err := doc.DataTo(&r); err != nil {
+				return err
+			}
+			names = append(names, r.Job)
+			if err := tx.Delete(doc.Ref); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return names, err
+}
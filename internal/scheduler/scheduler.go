@@ -0,0 +1,318 @@
+// Package scheduler executa as tarefas periódicas de ingestão e sincronização.
+// Com várias instâncias (Cloud Run), apenas a líder executa as tarefas; disparos
+// manuais recebidos por outra instância são encaminhados a ela (SetRequests).
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// checkInterval é a frequência com que o agendador verifica tarefas vencidas
+	checkInterval = time.Minute
+	// failureRetry antecipa a próxima execução de uma tarefa que falhou
+	failureRetry = 15 * time.Minute
+)
+
+var (
+	// ErrUnknownJob indica que a tarefa não está registrada
+	ErrUnknownJob = errors.New("tarefa desconhecida")
+	// ErrJobRunning indica que a tarefa já está em execução
+	ErrJobRunning = errors.New("tarefa já está em execução")
+)
+
+// Job é uma tarefa periódica
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Timeout  time.Duration
+	Run      func(ctx context.Context) error
+	// LastRun informa a última execução conhecida a partir dos próprios dados
+	// (por exemplo, o horário da última sincronização salva em disco), evitando
+	// repetir a tarefa a cada reinício. Opcional.
+	LastRun func() time.Time
+}
+
+// Status descreve o estado de uma tarefa
+type Status struct {
+	Name           string     `json:"name"`
+	Interval       string     `json:"interval"`
+	Running        bool       `json:"running"`
+	LastRun        *time.Time `json:"lastRun,omitempty"`
+	LastSuccess    *time.Time `json:"lastSuccess,omitempty"`
+	LastDurationMs int64      `json:"lastDurationMs"`
+	LastError      string     `json:"lastError,omitempty"`
+	LastTrigger    string     `json:"lastTrigger,omitempty"`
+	NextRun        *time.Time `json:"nextRun,omitempty"`
+	Runs           int        `json:"runs"`
+	Failures       int        `json:"failures"`
+}
+
+type jobState struct {
+	job    Job
+	status Status
+}
+
+// nextRun calcula quando a tarefa vence; tarefas que falharam voltam mais cedo
+func (st *jobState) nextRun() *time.Time {
+	if st.status.LastRun == nil {
+		return nil
+	}
+	wait := st.job.Interval
+	if st.status.LastError != "" && failureRetry < wait {
+		wait = failureRetry
+	}
+	next := st.status.LastRun.Add(wait)
+	return &next
+}
+
+// Scheduler executa as tarefas registradas quando vencem
+type Scheduler struct {
+	elector  Elector
+	requests Requests
+	jobs     map[string]*jobState
+	leader   bool
+	mutex    sync.RWMutex
+}
+
+// New cria um agendador. Sem eleição de líder, a instância sempre executa as tarefas.
+func New(elector Elector) *Scheduler {
+	if elector == nil {
+		elector = LocalElector{}
+	}
+	return &Scheduler{elector: elector, jobs: map[string]*jobState{}}
+}
+
+// SetRequests liga o encaminhamento de disparos manuais à líder. Deve ser
+// chamado antes de Start.
+func (s *Scheduler) SetRequests(requests Requests) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.requests = requests
+}
+
+// Register adiciona uma tarefa. Deve ser chamado antes de Start.
+func (s *Scheduler) Register(job Job) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	state := &jobState{job: job, status: Status{Name: job.Name, Interval: job.Interval.String()}}
+	if job.LastRun != nil {
+		if last := job.LastRun(); !last.IsZero() {
+			state.status.LastRun = &last
+			state.status.LastSuccess = &last
+		}
+	}
+	s.jobs[job.Name] = state
+}
+
+// Start inicia o laço do agendador em segundo plano até o contexto ser cancelado
+func (s *Scheduler) Start(ctx context.Context) {
+	// Os dados podem ter sido atualizados desde o registro das tarefas
+	s.syncLastRuns()
+	go func() {
+		s.tick(ctx)
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.tick(ctx)
+			}
+		}
+	}()
+}
+
+func (s *Scheduler) tick(ctx context.Context) {
+	leader, err := s.elector.Acquire(ctx)
+	if err != nil {
+		log.Printf("⚠️  [AGENDADOR] erro na eleição de líder: %v", err)
+		leader = false
+	}
+
+	s.mutex.Lock()
+	if leader != s.leader {
+		if leader {
+			log.Println("[AGENDADOR] esta instância assumiu a execução das tarefas")
+		} else {
+			log.Println("[AGENDADOR] outra instância está executando as tarefas")
+		}
+	}
+	s.leader = leader
+	requests := s.requests
+	s.mutex.Unlock()
+
+	if !leader {
+		s.syncLastRuns()
+		return
+	}
+
+	if requests != nil {
+		names, err := requests.Take(ctx)
+		if err != nil {
+			log.Printf("⚠️  [AGENDADOR] erro ao ler os disparos encaminhados: %v", err)
+		}
+		for _, name := range names {
+			if err := s.start(ctx, name, "manual"); err != nil {
+				log.Printf("⚠️  [AGENDADOR] disparo encaminhado de %s: %v", name, err)
+			}
+		}
+	}
+
+	now := time.Now()
+	for _, name := range s.dueJobs(now) {
+		if err := s.start(ctx, name, "agendado"); err != nil && !errors.Is(err, ErrJobRunning) {
+			log.Printf("⚠️  [AGENDADOR] %s: %v", name, err)
+		}
+	}
+}
+
+// syncLastRuns atualiza a última execução das tarefas a partir dos dados
+// recebidos da líder, para que uma instância que assuma a liderança não repita
+// tarefas que a anterior acabou de executar
+func (s *Scheduler) syncLastRuns() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, state := range s.jobs {
+		if state.job.LastRun == nil || state.status.Running {
+			continue
+		}
+		last := state.job.LastRun()
+		if last.IsZero() || (state.status.LastRun != nil && !last.After(*state.status.LastRun)) {
+			continue
+		}
+		state.status.LastRun = &last
+		state.status.LastSuccess = &last
+		state.status.LastError = ""
+	}
+}
+
+func (s *Scheduler) dueJobs(now time.Time) []string {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var due []string
+	for name, state := range s.jobs {
+		if state.status.Running {
+			continue
+		}
+		if next := state.nextRun(); next == nil || !now.Before(*next) {
+			due = append(due, name)
+		}
+	}
+	sort.Strings(due)
+	return due
+}
+
+// Trigger executa a tarefa imediatamente, em segundo plano. Fora da líder, com
+// SetRequests, o pedido é encaminhado para a líder executar na próxima
+// verificação e forwarded é verdadeiro.
+func (s *Scheduler) Trigger(ctx context.Context, name string) (forwarded bool, err error) {
+	s.mutex.RLock()
+	_, known := s.jobs[name]
+	leader, requests := s.leader, s.requests
+	s.mutex.RUnlock()
+
+	if !known {
+		return false, fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+	if !leader && requests != nil {
+		return true, requests.Push(ctx, name)
+	}
+	return false, s.start(ctx, name, "manual")
+}
+
+func (s *Scheduler) start(ctx context.Context, name, trigger string) error {
+	s.mutex.Lock()
+	state, ok := s.jobs[name]
+	if !ok {
+		s.mutex.Unlock()
+		return fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+	if state.status.Running {
+		s.mutex.Unlock()
+		return ErrJobRunning
+	}
+	state.status.Running = true
+	state.status.LastTrigger = trigger
+	s.mutex.Unlock()
+
+	// A execução não depende da requisição que a disparou
+	go s.run(context.WithoutCancel(ctx), state)
+	return nil
+}
+
+func (s *Scheduler) run(ctx context.Context, state *jobState) {
+	if state.job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, state.job.Timeout)
+		defer cancel()
+	}
+
+	started := time.Now()
+	log.Printf("[AGENDADOR] iniciando %s", state.job.Name)
+
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("pânico: %v", r)
+			}
+		}()
+		return state.job.Run(ctx)
+	}()
+	duration := time.Since(started)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	state.status.Running = false
+	state.status.LastRun = &started
+	state.status.LastDurationMs = duration.Milliseconds()
+	state.status.Runs++
+	if err != nil {
+		state.status.Failures++
+		state.status.LastError = err.Error()
+		log.Printf("⚠️  [AGENDADOR] %s falhou após %s: %v", state.job.Name, duration.Round(time.Millisecond), err)
+		return
+	}
+	state.status.LastError = ""
+	finished := started.Add(duration)
+	state.status.LastSuccess = &finished
+	log.Printf("[AGENDADOR] %s concluída em %s", state.job.Name, duration.Round(time.Millisecond))
+}
+
+// Statuses lista o estado das tarefas em ordem alfabética
+func (s *Scheduler) Statuses() []Status {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	statuses := make([]Status, 0, len(s.jobs))
+	for _, state := range s.jobs {
+		status := state.status
+		status.NextRun = state.nextRun()
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// IsLeader informa se esta instância executou a última rodada como líder
+func (s *Scheduler) IsLeader() bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.leader
+}
+
+// Holder identifica a instância na eleição de líder
+func (s *Scheduler) Holder() string {
+	return s.elector.Holder()
+}
@@ -0,0 +1,144 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNextRun(t *testing.T) {
+	last := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		interval time.Duration
+		lastRun  *time.Time
+		lastErr  string
+		want     *time.Time
+	}{
+		{name: "nunca executada", interval: time.Hour},
+		{name: "sucesso", interval: 6 * time.Hour, lastRun: &last, want: ptr(last.Add(6 * time.Hour))},
+		{name: "falha volta mais cedo", interval: 6 * time.Hour, lastRun: &last, lastErr: "erro", want: ptr(last.Add(failureRetry))},
+		{name: "falha com intervalo curto", interval: 5 * time.Minute, lastRun: &last, lastErr: "erro", want: ptr(last.Add(5 * time.Minute))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := &jobState{job: Job{Interval: tt.interval}, status: Status{LastRun: tt.lastRun, LastError: tt.lastErr}}
+			if got := st.nextRun(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("nextRun() = %v, esperava %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDueJobs(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	s := New(nil)
+	add := func(name string, interval time.Duration, lastRun time.Time, lastErr string, running bool) {
+		st := &jobState{job: Job{Name: name, Interval: interval}, status: Status{Name: name, LastError: lastErr, Running: running}}
+		if !lastRun.IsZero() {
+			st.status.LastRun = &lastRun
+		}
+		s.jobs[name] = st
+	}
+	add("nova", time.Hour, time.Time{}, "", false)
+	add("vencida", time.Hour, now.Add(-time.Hour), "", false)
+	add("em-dia", 6*time.Hour, now.Add(-time.Hour), "", false)
+	add("falhou-ha-pouco", 6*time.Hour, now.Add(-10*time.Minute), "erro", false)
+	add("falhou-ha-tempo", 6*time.Hour, now.Add(-20*time.Minute), "erro", false)
+	add("rodando", time.Hour, time.Time{}, "", true)
+
+	want := []string{"falhou-ha-tempo", "nova", "vencida"}
+	if got := s.dueJobs(now); !reflect.DeepEqual(got, want) {
+		t.Errorf("dueJobs() = %v, esperava %v", got, want)
+	}
+}
+
+func TestClaim(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	ttl := 3 * time.Minute
+	tests := []struct {
+		name    string
+		current *leaderLock
+		want    bool
+	}{
+		{name: "sem trava", want: true},
+		{name: "renovação", current: &leaderLock{Holder: "a", ExpiresAt: now.Add(time.Minute)}, want: true},
+		{name: "trava de outra instância", current: &leaderLock{Holder: "b", ExpiresAt: now.Add(time.Minute)}},
+		{name: "trava de outra instância expirada", current: &leaderLock{Holder: "b", ExpiresAt: now.Add(-time.Second)}, want: true},
+		{name: "expira agora", current: &leaderLock{Holder: "b", ExpiresAt: now}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lock, ok := claim(tt.current, "a", now, ttl)
+			if ok != tt.want {
+				t.Fatalf("claim() = %v, esperava %v", ok, tt.want)
+			}
+			if ok && (lock.Holder != "a" || !lock.ExpiresAt.Equal(now.Add(ttl)) || !lock.RenewedAt.Equal(now)) {
+				t.Errorf("trava inesperada: %+v", lock)
+			}
+		})
+	}
+}
+
+// fixedElector decide a liderança pelo campo leader
+type fixedElector struct{ leader bool }
+
+func (e fixedElector) Acquire(ctx context.Context) (bool, error) { return e.leader, nil }
+
+func (fixedElector) Holder() string { return "teste" }
+
+type memoryRequests struct{ names []string }
+
+func (m *memoryRequests) Push(ctx context.Context, name string) error {
+	m.names = append(m.names, name)
+	return nil
+}
+
+func (m *memoryRequests) Take(ctx context.Context) ([]string, error) {
+	names := m.names
+	m.names = nil
+	return names, nil
+}
+
+func TestTriggerForwardsToLeader(t *testing.T) {
+	ctx := context.Background()
+	requests := &memoryRequests{}
+	ran := make(chan string, 1)
+	job := Job{Name: "votacoes", Interval: time.Hour, LastRun: func() time.Time { return time.Now() }, Run: func(ctx context.Context) error {
+		ran <- "votacoes"
+		return nil
+	}}
+
+	follower := New(fixedElector{leader: false})
+	follower.SetRequests(requests)
+	follower.Register(job)
+	follower.tick(ctx)
+
+	if _, err := follower.Trigger(ctx, "inexistente"); !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("esperava ErrUnknownJob, obteve %v", err)
+	}
+	forwarded, err := follower.Trigger(ctx, "votacoes")
+	if err != nil || !forwarded {
+		t.Fatalf("Trigger fora da líder: forwarded=%v, err=%v", forwarded, err)
+	}
+	if len(requests.names) != 1 {
+		t.Fatalf("pedidos encaminhados = %v", requests.names)
+	}
+
+	leader := New(fixedElector{leader: true})
+	leader.SetRequests(requests)
+	leader.Register(job)
+	leader.tick(ctx)
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("a líder não executou o disparo encaminhado")
+	}
+	if len(requests.names) != 0 {
+		t.Errorf("pedidos não foram retirados: %v", requests.names)
+	}
+}
+
+func ptr(t time.Time) *time.Time { return &t }
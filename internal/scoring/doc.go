@@ -0,0 +1,52 @@
+// Package scoring calcula os seis eixos do perfil hexagonal a partir de dados
+// oficiais da Câmara dos Deputados e do Senado Federal.
+//
+// Metodologia (versão "dados-oficiais-v1"). Todas as notas vão de 0 a 100 e
+// são determinísticas: os mesmos dados de entrada sempre geram a mesma nota.
+//
+// Experiência
+//
+//	50% pelo tempo em cargos eletivos (teto de 24 anos) e 50% pelo número de
+//	mandatos exercidos, incluindo mandatos fora do Congresso (teto de 5).
+//
+// Gestão
+//
+//	40% pelo volume de proposições de autoria (escala logarítmica, teto de 50)
+//	e 60% pelas proposições transformadas em norma jurídica (teto de 5). Quando
+//	a fonte não informa aprovações (Senado), apenas o volume é considerado e a
+//	explicação registra a limitação.
+//
+// Coalizão
+//
+//	Média entre o alinhamento com a orientação do próprio partido e com a
+//	orientação do governo nas votações nominais amostradas. Votos sem
+//	orientação correspondente ou diferentes de Sim/Não não entram na conta.
+//	No Senado, que não publica orientação de bancada, as votações vêm das
+//	ingeridas nos últimos 90 dias (internal/votes): o partido vale pela
+//	maioria dos colegas de partido que votaram Sim ou Não (ao menos dois, sem
+//	empate) e o governo pelo voto do líder do governo.
+//
+// Transparência
+//
+//	50% pela regularidade da prestação de contas da CEAP (meses com despesas
+//	publicadas sobre meses esperados) e 50% pela parcela de despesas com
+//	documento fiscal digitalizado disponível. A cota do Senado (CEAPS) não tem
+//	API por senador nos dados abertos, então o eixo fica indisponível para
+//	senadores.
+//
+// Internacional
+//
+//	20 pontos por participação como titular e 10 como suplente em órgãos de
+//	relações exteriores, defesa nacional ou representação no Parlasul (teto 100).
+//
+// Popularidade
+//
+//	Votos nominais recebidos na última eleição em relação ao quociente de
+//	referência informado (teto de 2 vezes o quociente). Com os dados do TSE
+//	importados (cmd/tse-import), o quociente é a soma dos votos nominais do
+//	cargo na UF dividida pelo número de eleitos; para senador, metade dos
+//	votos nominais. Sem dados eleitorais o eixo é marcado como indisponível.
+//
+// Eixos sem dados suficientes são devolvidos com Available=false e nota 0,
+// para que o consumidor possa exibi-los de forma diferenciada.
+package scoring
@@ -0,0 +1,231 @@
+package scoring
+
+import (
+	"fmt"
+	"math"
+)
+
+// MethodVersion identifica a versão da metodologia descrita em doc.go
+const MethodVersion = "dados-oficiais-v1"
+
+// Nomes dos eixos, na ordem exibida pelo gráfico hexagonal
+const (
+	AxisExperiencia   = "Experiência"
+	AxisPopularidade  = "Popularidade"
+	AxisTransparencia = "Transparência"
+	AxisInternacional = "Internacional"
+	AxisGestao        = "Gestão"
+	AxisCoalizao      = "Coalizão"
+)
+
+// Axes lista os eixos na ordem do gráfico
+var Axes = []string{AxisExperiencia, AxisPopularidade, AxisTransparencia, AxisInternacional, AxisGestao, AxisCoalizao}
+
+// Input reúne os indicadores oficiais de um parlamentar
+type Input struct {
+	// Experiência
+	YearsInOffice float64
+	Mandates      int
+
+	// Gestão
+	BillsAuthored      int
+	BillsApproved      int
+	BillsApprovedKnown bool
+
+	// Coalizão
+	VotesWithParty       int
+	VotesPartyComparable int
+	VotesWithGov         int
+	VotesGovComparable   int
+
+	// Transparência (CEAP)
+	ExpenseMonthsDisclosed int
+	ExpenseMonthsExpected  int
+	ExpenseDocs            int
+	ExpenseDocsWithReceipt int
+
+	// Internacional
+	InternationalTitular  int
+	InternationalSuplente int
+
+	// Popularidade
+	ElectoralVotes    int
+	ElectoralQuotient int
+}
+
+// Axis é a nota de um eixo com a explicação de como foi calculada
+type Axis struct {
+	Label       string             `json:"label"`
+	Value       int                `json:"value"`
+	Category    string             `json:"category"`
+	Available   bool               `json:"available"`
+	Explanation string             `json:"explicacao"`
+	Metrics     map[string]float64 `json:"metricas,omitempty"`
+}
+
+// Result é o perfil calculado para um parlamentar
+type Result struct {
+	Method string `json:"method"`
+	Axes   []Axis `json:"axes"`
+}
+
+// Compute calcula os seis eixos a partir dos indicadores
+func Compute(in Input) Result {
+	return Result{
+		Method: MethodVersion,
+		Axes: []Axis{
+			experiencia(in),
+			popularidade(in),
+			transparencia(in),
+			internacional(in),
+			gestao(in),
+			coalizao(in),
+		},
+	}
+}
+
+// Category usa as mesmas faixas que o gráfico hexagonal do frontend
+func Category(value int) string {
+	if value >= 70 {
+		return "forte"
+	}
+	if value >= 50 {
+		return "medio"
+	}
+	return "fraco"
+}
+
+func newAxis(label string, score float64, explanation string, metrics map[string]float64) Axis {
+	value := int(math.Round(clamp(score, 0, 100)))
+	return Axis{
+		Label:       label,
+		Value:       value,
+		Category:    Category(value),
+		Available:   true,
+		Explanation: explanation,
+		Metrics:     metrics,
+	}
+}
+
+func unavailable(label, explanation string) Axis {
+	return Axis{
+		Label:       label,
+		Category:    Category(0),
+		Explanation: explanation,
+	}
+}
+
+func clamp(v, min, max float64) float64 {
+	return math.Max(min, math.Min(max, v))
+}
+
+func ratio(part, total int) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(part) / float64(total)
+}
+
+func experiencia(in Input) Axis {
+	if in.YearsInOffice <= 0 && in.Mandates == 0 {
+		return unavailable(AxisExperiencia, "Histórico de mandatos não encontrado nas fontes oficiais.")
+	}
+
+	years := clamp(in.YearsInOffice/24, 0, 1)
+	mandates := clamp(float64(in.Mandates)/5, 0, 1)
+	score := 50*years + 50*mandates
+
+	return newAxis(AxisExperiencia, score,
+		fmt.Sprintf("%.1f anos em cargos eletivos e %d mandato(s) exercido(s).", in.YearsInOffice, in.Mandates),
+		map[string]float64{"anos": in.YearsInOffice, "mandatos": float64(in.Mandates)})
+}
+
+func gestao(in Input) Axis {
+	if in.BillsAuthored == 0 && in.BillsApproved == 0 {
+		return newAxis(AxisGestao, 0, "Nenhuma proposição de autoria encontrada.",
+			map[string]float64{"autoria": 0})
+	}
+
+	volume := clamp(math.Log1p(float64(in.BillsAuthored))/math.Log1p(50), 0, 1)
+	metrics := map[string]float64{"autoria": float64(in.BillsAuthored)}
+
+	if !in.BillsApprovedKnown {
+		return newAxis(AxisGestao, 100*volume,
+			fmt.Sprintf("%d proposição(ões) de autoria. A fonte não informa quantas viraram norma jurídica; nota baseada apenas no volume.", in.BillsAuthored),
+			metrics)
+	}
+
+	approved := clamp(float64(in.BillsApproved)/5, 0, 1)
+	metrics["aprovadas"] = float64(in.BillsApproved)
+	return newAxis(AxisGestao, 40*volume+60*approved,
+		fmt.Sprintf("%d proposição(ões) de autoria, %d transformada(s) em norma jurídica.", in.BillsAuthored, in.BillsApproved),
+		metrics)
+}
+
+func coalizao(in Input) Axis {
+	if in.VotesPartyComparable == 0 && in.VotesGovComparable == 0 {
+		return unavailable(AxisCoalizao, "Sem votações nominais com orientação registrada para comparar.")
+	}
+
+	party := ratio(in.VotesWithParty, in.VotesPartyComparable)
+	gov := ratio(in.VotesWithGov, in.VotesGovComparable)
+	metrics := map[string]float64{
+		"alinhamentoPartido": math.Round(party * 100),
+		"alinhamentoGoverno": math.Round(gov * 100),
+		"votacoesPartido":    float64(in.VotesPartyComparable),
+		"votacoesGoverno":    float64(in.VotesGovComparable),
+	}
+
+	var score float64
+	switch {
+	case in.VotesPartyComparable == 0:
+		score = gov * 100
+	case in.VotesGovComparable == 0:
+		score = party * 100
+	default:
+		score = 50*party + 50*gov
+	}
+
+	return newAxis(AxisCoalizao, score,
+		fmt.Sprintf("Votou com o partido em %.0f%% de %d votações e com o governo em %.0f%% de %d votações.",
+			party*100, in.VotesPartyComparable, gov*100, in.VotesGovComparable),
+		metrics)
+}
+
+func transparencia(in Input) Axis {
+	if in.ExpenseMonthsExpected == 0 {
+		return unavailable(AxisTransparencia, "Dados da cota parlamentar (CEAP) indisponíveis para este parlamentar.")
+	}
+
+	regularity := clamp(ratio(in.ExpenseMonthsDisclosed, in.ExpenseMonthsExpected), 0, 1)
+	receipts := ratio(in.ExpenseDocsWithReceipt, in.ExpenseDocs)
+
+	return newAxis(AxisTransparencia, 50*regularity+50*receipts,
+		fmt.Sprintf("Despesas publicadas em %d de %d meses; %.0f%% das %d despesas têm documento fiscal digitalizado.",
+			in.ExpenseMonthsDisclosed, in.ExpenseMonthsExpected, receipts*100, in.ExpenseDocs),
+		map[string]float64{
+			"mesesPublicados": float64(in.ExpenseMonthsDisclosed),
+			"mesesEsperados":  float64(in.ExpenseMonthsExpected),
+			"despesas":        float64(in.ExpenseDocs),
+			"comDocumento":    float64(in.ExpenseDocsWithReceipt),
+		})
+}
+
+func internacional(in Input) Axis {
+	score := 20*float64(in.InternationalTitular) + 10*float64(in.InternationalSuplente)
+	return newAxis(AxisInternacional, score,
+		fmt.Sprintf("%d participação(ões) como titular e %d como suplente em órgãos de relações exteriores, defesa ou Parlasul.",
+			in.InternationalTitular, in.InternationalSuplente),
+		map[string]float64{"titular": float64(in.InternationalTitular), "suplente": float64(in.InternationalSuplente)})
+}
+
+func popularidade(in Input) Axis {
+	if in.ElectoralVotes == 0 || in.ElectoralQuotient == 0 {
+		return unavailable(AxisPopularidade, "Sem dados eleitorais oficiais integrados para este parlamentar.")
+	}
+
+	relative := float64(in.ElectoralVotes) / float64(in.ElectoralQuotient)
+	return newAxis(AxisPopularidade, 50*relative,
+		fmt.Sprintf("%d votos nominais, %.2f vezes o quociente de referência.", in.ElectoralVotes, relative),
+		map[string]float64{"votos": float64(in.ElectoralVotes), "quociente": float64(in.ElectoralQuotient)})
+}
@@ -0,0 +1,89 @@
+package scoring
+
+import (
+	"reflect"
+	"testing"
+)
+
+func axisByLabel(t *testing.T, result Result, label string) Axis {
+	t.Helper()
+	for _, axis := range result.Axes {
+		if axis.Label == label {
+			return axis
+		}
+	}
+	t.Fatalf("eixo %q ausente", label)
+	return Axis{}
+}
+
+func TestComputeMethodology(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     Input
+		axis      string
+		value     int
+		available bool
+	}{
+		{"experiência metade do tempo e teto de mandatos", Input{YearsInOffice: 12, Mandates: 5}, AxisExperiencia, 75, true},
+		{"experiência sem histórico", Input{}, AxisExperiencia, 0, false},
+		{"gestão no teto", Input{BillsAuthored: 50, BillsApproved: 5, BillsApprovedKnown: true}, AxisGestao, 100, true},
+		{"gestão sem aprovações conhecidas usa só o volume", Input{BillsAuthored: 50}, AxisGestao, 100, true},
+		{"gestão sem proposições", Input{}, AxisGestao, 0, true},
+		{"coalizão média entre partido e governo", Input{VotesWithParty: 8, VotesPartyComparable: 10, VotesWithGov: 6, VotesGovComparable: 10}, AxisCoalizao, 70, true},
+		{"coalizão só com governo", Input{VotesWithGov: 3, VotesGovComparable: 4}, AxisCoalizao, 75, true},
+		{"coalizão sem votações", Input{}, AxisCoalizao, 0, false},
+		{"transparência", Input{ExpenseMonthsDisclosed: 12, ExpenseMonthsExpected: 12, ExpenseDocs: 100, ExpenseDocsWithReceipt: 90}, AxisTransparencia, 95, true},
+		{"transparência sem CEAP", Input{}, AxisTransparencia, 0, false},
+		{"internacional", Input{InternationalTitular: 2, InternationalSuplente: 1}, AxisInternacional, 50, true},
+		{"internacional com teto", Input{InternationalTitular: 6}, AxisInternacional, 100, true},
+		{"popularidade", Input{ElectoralVotes: 150000, ElectoralQuotient: 100000}, AxisPopularidade, 75, true},
+		{"popularidade com teto", Input{ElectoralVotes: 300000, ElectoralQuotient: 100000}, AxisPopularidade, 100, true},
+		{"popularidade sem quociente", Input{ElectoralVotes: 1000}, AxisPopularidade, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			axis := axisByLabel(t, Compute(tt.input), tt.axis)
+			if axis.Value != tt.value || axis.Available != tt.available {
+				t.Errorf("%s = %d (disponível %v), esperava %d (disponível %v)", tt.axis, axis.Value, axis.Available, tt.value, tt.available)
+			}
+			if axis.Category != Category(axis.Value) {
+				t.Errorf("categoria %q não corresponde à nota %d", axis.Category, axis.Value)
+			}
+		})
+	}
+}
+
+func TestComputeIsReproducible(t *testing.T) {
+	input := Input{
+		YearsInOffice: 9.5, Mandates: 3,
+		BillsAuthored: 17, BillsApproved: 2, BillsApprovedKnown: true,
+		VotesWithParty: 21, VotesPartyComparable: 25, VotesWithGov: 11, VotesGovComparable: 24,
+		ExpenseMonthsDisclosed: 10, ExpenseMonthsExpected: 12, ExpenseDocs: 300, ExpenseDocsWithReceipt: 240,
+		InternationalTitular: 1,
+		ElectoralVotes:       80000, ElectoralQuotient: 120000,
+	}
+	first := Compute(input)
+	if first.Method != MethodVersion {
+		t.Errorf("método %q, esperava %q", first.Method, MethodVersion)
+	}
+	var labels []string
+	for _, axis := range first.Axes {
+		labels = append(labels, axis.Label)
+	}
+	if !reflect.DeepEqual(labels, Axes) {
+		t.Errorf("ordem dos eixos %v, esperava %v", labels, Axes)
+	}
+	for i := 0; i < 10; i++ {
+		if again := Compute(input); !reflect.DeepEqual(first, again) {
+			t.Fatalf("resultado mudou entre execuções:\n%+v\n%+v", first, again)
+		}
+	}
+}
+
+func TestCategory(t *testing.T) {
+	for value, want := range map[int]string{0: "fraco", 49: "fraco", 50: "medio", 69: "medio", 70: "forte", 100: "forte"} {
+		if got := Category(value); got != want {
+			t.Errorf("Category(%d) = %q, esperava %q", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,402 @@
+package senado
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// BaseURL é o endereço da API de Dados Abertos do Senado Federal
+const BaseURL = "https://legis.senado.leg.br/dadosabertos"
+
+// Client acessa a API de Dados Abertos do Senado
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient cria um cliente com timeout padrão
+func NewClient() *Client {
+	return &Client{
+		baseURL:    BaseURL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// List aceita tanto um objeto quanto uma lista: a API do Senado omite o
+// array quando há apenas um item.
+type List[T any] []T
+
+func (l *List[T]) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		*l = nil
+		return nil
+	}
+
+	if data[0] == '[' {
+		var items []T
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		*l = items
+		return nil
+	}
+
+	var item T
+	if err := json.Unmarshal(data, &item); err != nil {
+		return err
+	}
+	*l = List[T]{item}
+	return nil
+}
+
+func (c *Client) get(ctx context.Context, path string, out interface{}) error {
+	rawURL := c.baseURL + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("senado: status %d em %s: %s", resp.StatusCode, rawURL, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("senado: erro ao decodificar %s: %w", rawURL, err)
+	}
+	return nil
+}
+
+// IdentificacaoParlamentar traz os dados básicos de um senador
+type IdentificacaoParlamentar struct {
+	CodigoParlamentar       string `json:"CodigoParlamentar"`
+	NomeParlamentar         string `json:"NomeParlamentar"`
+	NomeCompletoParlamentar string `json:"NomeCompletoParlamentar"`
+	SexoParlamentar         string `json:"SexoParlamentar"`
+	URLFotoParlamentar      string `json:"UrlFotoParlamentar"`
+	URLPaginaParlamentar    string `json:"UrlPaginaParlamentar"`
+	EmailParlamentar        string `json:"EmailParlamentar"`
+	SiglaPartidoParlamentar string `json:"SiglaPartidoParlamentar"`
+	UFParlamentar           string `json:"UfParlamentar"`
+}
+
+// Senador é um parlamentar em exercício
+type Senador struct {
+	IdentificacaoParlamentar IdentificacaoParlamentar `json:"IdentificacaoParlamentar"`
+}
+
+// Legislatura é uma das duas legislaturas cobertas por um mandato de senador
+type Legislatura struct {
+	NumeroLegislatura string `json:"NumeroLegislatura"`
+	DataInicio        string `json:"DataInicio"`
+	DataFim           string `json:"DataFim"`
+}
+
+// Mandato é um mandato (titular ou suplente) exercido no Senado
+type Mandato struct {
+	CodigoMandato                string      `json:"CodigoMandato"`
+	UFParlamentar                string      `json:"UfParlamentar"`
+	DescricaoParticipacao        string      `json:"DescricaoParticipacao"`
+	PrimeiraLegislaturaDoMandato Legislatura `json:"PrimeiraLegislaturaDoMandato"`
+	SegundaLegislaturaDoMandato  Legislatura `json:"SegundaLegislaturaDoMandato"`
+}
+
+// Materia identifica uma matéria legislativa
+type Materia struct {
+	Codigo string `json:"Codigo"`
+	Sigla  string `json:"Sigla"`
+	Numero string `json:"Numero"`
+	Ano    string `json:"Ano"`
+	Ementa string `json:"Ementa"`
+}
+
+// Autoria é uma matéria de autoria do senador
+type Autoria struct {
+	Materia                 Materia `json:"Materia"`
+	IndicadorAutorPrincipal string  `json:"IndicadorAutorPrincipal"`
+}
+
+// VotoSenador é o voto de um senador em uma votação nominal do Plenário
+type VotoSenador struct {
+	CodigoSessaoVotacao string  `json:"CodigoSessaoVotacao"`
+	DescricaoVotacao    string  `json:"DescricaoVotacao"`
+	SiglaDescricaoVoto  string  `json:"SiglaDescricaoVoto"`
+	Materia             Materia `json:"Materia"`
+	SessaoPlenaria      struct {
+		CodigoSessao string `json:"CodigoSessao"`
+		DataSessao   string `json:"DataSessao"`
+	} `json:"SessaoPlenaria"`
+}
+
+// Comissao é a participação do senador em uma comissão
+type Comissao struct {
+	IdentificacaoComissao struct {
+		CodigoComissao string `json:"CodigoComissao"`
+		SiglaComissao  string `json:"SiglaComissao"`
+		NomeComissao   string `json:"NomeComissao"`
+	} `json:"IdentificacaoComissao"`
+	DescricaoParticipacao string `json:"DescricaoParticipacao"`
+	DataInicio            string `json:"DataInicio"`
+	DataFim               string `json:"DataFim"`
+}
+
+// ListSenadoresAtuais retorna os senadores em exercício
+func (c *Client) ListSenadoresAtuais(ctx context.Context) ([]Senador, error) {
+	var data struct {
+		ListaParlamentarEmExercicio struct {
+			Parlamentares struct {
+				Parlamentar List[Senador] `json:"Parlamentar"`
+			} `json:"Parlamentares"`
+		} `json:"ListaParlamentarEmExercicio"`
+	}
+	if err := c.get(ctx, "/senador/lista/atual", &data); err != nil {
+		return nil, err
+	}
+	return data.ListaParlamentarEmExercicio.Parlamentares.Parlamentar, nil
+}
+
+// Mandatos retorna os mandatos exercidos pelo senador
+func (c *Client) Mandatos(ctx context.Context, codigo string) ([]Mandato, error) {
+	var data struct {
+		MandatoParlamentar struct {
+			Parlamentar struct {
+				Mandatos struct {
+					Mandato List[Mandato] `json:"Mandato"`
+				} `json:"Mandatos"`
+			} `json:"Parlamentar"`
+		} `json:"MandatoParlamentar"`
+	}
+	if err := c.get(ctx, "/senador/"+codigo+"/mandatos", &data); err != nil {
+		return nil, err
+	}
+	return data.MandatoParlamentar.Parlamentar.Mandatos.Mandato, nil
+}
+
+// Autorias retorna as matérias de autoria do senador
+func (c *Client) Autorias(ctx context.Context, codigo string) ([]Autoria, error) {
+	var data struct {
+		MateriasAutoriaParlamentar struct {
+			Parlamentar struct {
+				Autorias struct {
+					Autoria List[Autoria] `json:"Autoria"`
+				} `json:"Autorias"`
+			} `json:"Parlamentar"`
+		} `json:"MateriasAutoriaParlamentar"`
+	}
+	if err := c.get(ctx, "/senador/"+codigo+"/autorias", &data); err != nil {
+		return nil, err
+	}
+	return data.MateriasAutoriaParlamentar.Parlamentar.Autorias.Autoria, nil
+}
+
+// Votacoes retorna os votos do senador nas votações nominais do Plenário
+func (c *Client) Votacoes(ctx context.Context, codigo string) ([]VotoSenador, error) {
+	var data struct {
+		VotacaoParlamentar struct {
+			Parlamentar struct {
+				Votacoes struct {
+					Votacao List[VotoSenador] `json:"Votacao"`
+				} `json:"Votacoes"`
+			} `json:"Parlamentar"`
+		} `json:"VotacaoParlamentar"`
+	}
+	if err := c.get(ctx, "/senador/"+codigo+"/votacoes", &data); err != nil {
+		return nil, err
+	}
+	return data.VotacaoParlamentar.Parlamentar.Votacoes.Votacao, nil
+}
+
+// Comissoes retorna as comissões das quais o senador é ou foi membro
+func (c *Client) Comissoes(ctx context.Context, codigo string) ([]Comissao, error) {
+	var data struct {
+		MembroComissaoParlamentar struct {
+			Parlamentar struct {
+				MembroComissoes struct {
+					Comissao List[Comissao] `json:"Comissao"`
+				} `json:"MembroComissoes"`
+			} `json:"Parlamentar"`
+		} `json:"MembroComissaoParlamentar"`
+	}
+	if err := c.get(ctx, "/senador/"+codigo+"/comissoes", &data); err != nil {
+		return nil, err
+	}
+	return data.MembroComissaoParlamentar.Parlamentar.MembroComissoes.Comissao, nil
+}
+
+// Filiacao é a filiação partidária de um senador em um período
+type Filiacao struct {
+	Partido struct {
+		SiglaPartido string `json:"SiglaPartido"`
+		NomePartido  string `json:"NomePartido"`
+	} `json:"Partido"`
+	DataFiliacao    string `json:"DataFiliacao"`
+	DataDesfiliacao string `json:"DataDesfiliacao"`
+}
+
+// Filiacoes retorna o histórico de filiações partidárias do senador
+func (c *Client) Filiacoes(ctx context.Context, codigo string) ([]Filiacao, error) {
+	var data struct {
+		FiliacaoParlamentar struct {
+			Parlamentar struct {
+				Filiacoes struct {
+					Filiacao List[Filiacao] `json:"Filiacao"`
+				} `json:"Filiacoes"`
+			} `json:"Parlamentar"`
+		} `json:"FiliacaoParlamentar"`
+	}
+	if err := c.get(ctx, "/senador/"+codigo+"/filiacoes", &data); err != nil {
+		return nil, err
+	}
+	return data.FiliacaoParlamentar.Parlamentar.Filiacoes.Filiacao, nil
+}
+
+// VotoParlamentar é o voto de um senador em uma votação do Plenário
+type VotoParlamentar struct {
+	CodigoParlamentar string `json:"CodigoParlamentar"`
+	NomeParlamentar   string `json:"NomeParlamentar"`
+	SiglaPartido      string `json:"SiglaPartido"`
+	SiglaUF           string `json:"SiglaUF"`
+	Voto              string `json:"Voto"`
+}
+
+// VotacaoPlenario é uma votação do Plenário com o resultado e os votos nominais
+type VotacaoPlenario struct {
+	CodigoSessaoVotacao string `json:"CodigoSessaoVotacao"`
+	CodigoMateria       string `json:"CodigoMateria"`
+	SiglaMateria        string `json:"SiglaMateria"`
+	NumeroMateria       string `json:"NumeroMateria"`
+	AnoMateria          string `json:"AnoMateria"`
+	DataSessao          string `json:"DataSessao"`
+	DescricaoVotacao    string `json:"DescricaoVotacao"`
+	Resultado           string `json:"Resultado"`
+	Secreta             string `json:"Secreta"`
+	TotalVotosSim       string `json:"TotalVotosSim"`
+	TotalVotosNao       string `json:"TotalVotosNao"`
+	TotalVotosAbstencao string `json:"TotalVotosAbstencao"`
+	Votos               struct {
+		VotoParlamentar List[VotoParlamentar] `json:"VotoParlamentar"`
+	} `json:"Votos"`
+}
+
+// ListVotacoesPlenario retorna as votações do Plenário entre as datas informadas
+func (c *Client) ListVotacoesPlenario(ctx context.Context, inicio, fim time.Time) ([]VotacaoPlenario, error) {
+	var data struct {
+		ListaVotacoes struct {
+			Votacoes struct {
+				Votacao List[VotacaoPlenario] `json:"Votacao"`
+			} `json:"Votacoes"`
+		} `json:"ListaVotacoes"`
+	}
+	path := fmt.Sprintf("/plenario/lista/votacao/%s/%s", inicio.Format("20060102"), fim.Format("20060102"))
+	if err := c.get(ctx, path, &data); err != nil {
+		return nil, err
+	}
+	return data.ListaVotacoes.Votacoes.Votacao, nil
+}
+
+// IdentificacaoMateria identifica uma matéria nas listas do Senado
+type IdentificacaoMateria struct {
+	CodigoMateria       string `json:"CodigoMateria"`
+	SiglaSubtipoMateria string `json:"SiglaSubtipoMateria"`
+	NumeroMateria       string `json:"NumeroMateria"`
+	AnoMateria          string `json:"AnoMateria"`
+}
+
+// MateriaAtualizada é uma matéria com movimentação recente
+type MateriaAtualizada struct {
+	IdentificacaoMateria  IdentificacaoMateria `json:"IdentificacaoMateria"`
+	EmentaMateria         string               `json:"EmentaMateria"`
+	DataUltimaAtualizacao string               `json:"DataUltimaAtualizacao"`
+}
+
+// MateriasAtualizadas retorna as matérias atualizadas nos últimos dias
+func (c *Client) MateriasAtualizadas(ctx context.Context, dias int) ([]MateriaAtualizada, error) {
+	var data struct {
+		ListaMateriasAtualizadas struct {
+			Materias struct {
+				Materia List[MateriaAtualizada] `json:"Materia"`
+			} `json:"Materias"`
+		} `json:"ListaMateriasAtualizadas"`
+	}
+	if err := c.get(ctx, fmt.Sprintf("/materia/atualizadas?numdias=%d", dias), &data); err != nil {
+		return nil, err
+	}
+	return data.ListaMateriasAtualizadas.Materias.Materia, nil
+}
+
+// Movimentacao é um evento da tramitação de uma matéria no Senado
+type Movimentacao struct {
+	IdentificacaoTramitacao struct {
+		CodigoTramitacao      string `json:"CodigoTramitacao"`
+		DataTramitacao        string `json:"DataTramitacao"`
+		NumeroOrdemTramitacao string `json:"NumeroOrdemTramitacao"`
+		TextoTramitacao       string `json:"TextoTramitacao"`
+		OrigemTramitacao      struct {
+			Local struct {
+				SiglaLocal string `json:"SiglaLocal"`
+				NomeLocal  string `json:"NomeLocal"`
+			} `json:"Local"`
+		} `json:"OrigemTramitacao"`
+		Situacao struct {
+			CodigoSituacao    string `json:"CodigoSituacao"`
+			SiglaSituacao     string `json:"SiglaSituacao"`
+			DescricaoSituacao string `json:"DescricaoSituacao"`
+		} `json:"Situacao"`
+	} `json:"IdentificacaoTramitacao"`
+}
+
+// Movimentacoes retorna a tramitação de uma matéria
+func (c *Client) Movimentacoes(ctx context.Context, codigo string) ([]Movimentacao, error) {
+	var data struct {
+		MovimentacaoMateria struct {
+			Materia struct {
+				Tramitacoes struct {
+					Tramitacao List[Movimentacao] `json:"Tramitacao"`
+				} `json:"Tramitacoes"`
+			} `json:"Materia"`
+		} `json:"MovimentacaoMateria"`
+	}
+	if err := c.get(ctx, "/materia/movimentacoes/"+codigo, &data); err != nil {
+		return nil, err
+	}
+	return data.MovimentacaoMateria.Materia.Tramitacoes.Tramitacao, nil
+}
+
+// MateriaPesquisa é uma matéria encontrada na pesquisa por sigla, número e ano
+type MateriaPesquisa struct {
+	IdentificacaoMateria IdentificacaoMateria `json:"IdentificacaoMateria"`
+	DadosBasicosMateria  struct {
+		EmentaMateria string `json:"EmentaMateria"`
+	} `json:"DadosBasicosMateria"`
+}
+
+// PesquisarMaterias busca matérias pela sigla, número e ano
+func (c *Client) PesquisarMaterias(ctx context.Context, sigla string, numero, ano int) ([]MateriaPesquisa, error) {
+	var data struct {
+		PesquisaBasicaMateria struct {
+			Materias struct {
+				Materia List[MateriaPesquisa] `json:"Materia"`
+			} `json:"Materias"`
+		} `json:"PesquisaBasicaMateria"`
+	}
+	path := fmt.Sprintf("/materia/pesquisa/lista?sigla=%s&numero=%d&ano=%d", url.QueryEscape(sigla), numero, ano)
+	if err := c.get(ctx, path, &data); err != nil {
+		return nil, err
+	}
+	return data.PesquisaBasicaMateria.Materias.Materia, nil
+}
@@ -96,6 +96,20 @@ func InitializeFirebase(cfg *config.Config) (*firebase.App, error) {
 	return app, nil
 }
 
+// InitializeAuth inicializa só o cliente Auth, para verificar tokens de ID
+// quando as respostas NPS não estão no Firestore
+func InitializeAuth(cfg *config.Config) (*auth.Client, error) {
+	app, err := InitializeFirebase(cfg)
+	if err != nil {
+		return nil, err
+	}
+	authClient, err := app.Auth(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("erro ao inicializar Auth: %w", err)
+	}
+	return authClient, nil
+}
+
 // InitializeFirestore inicializa o Firestore
 func InitializeFirestore(cfg *config.Config) (*FirestoreService, error) {
 	app, err := InitializeFirebase(cfg)
@@ -140,6 +154,11 @@ type FirestoreService struct {
 	config *config.Config
 }
 
+// NewFirestoreService usa um cliente Firestore já criado, como o do emulador
+func NewFirestoreService(client *firestore.Client) *FirestoreService {
+	return &FirestoreService{client: client}
+}
+
 // Close fecha as conexões
 func (fs *FirestoreService) Close() error {
 	if fs.client != nil {
@@ -0,0 +1,101 @@
+// Package sqlitedb abre o banco SQLite embutido usado nas instalações sem
+// Firestore e aplica as migrações de esquema de cada armazenamento. O driver
+// é escrito em Go puro, então o binário continua sendo gerado sem CGO.
+package sqlitedb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// Migration é uma alteração de esquema; Version cresce a cada nova migração
+// de um mesmo componente e nunca é reaproveitada
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// Open abre (ou cria) o banco em path com WAL, chaves estrangeiras e espera
+// de até 5 segundos quando outra conexão está gravando
+func Open(path string) (*sql.DB, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	params := url.Values{}
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "synchronous(NORMAL)")
+	db, err := sql.Open("sqlite", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// Migrate aplica, em ordem e cada uma em sua transação, as migrações de
+// component ainda não registradas em schema_migrations
+func Migrate(ctx context.Context, db *sql.DB, component string, migrations []Migration) error {
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		component  TEXT    NOT NULL,
+		version    INTEGER NOT NULL,
+		name       TEXT    NOT NULL,
+		applied_at TEXT    NOT NULL,
+		PRIMARY KEY (component, version)
+	)`); err != nil {
+		return err
+	}
+
+	var current int
+	if err := db.QueryRowContext(ctx,
+		`SELECT COALESCE(MAX(version), 0) FROM schema_migrations WHERE component = ?`, component,
+	).Scan(&current); err != nil {
+		return err
+	}
+
+	pending := append([]Migration(nil), migrations...)
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Version < pending[j].Version })
+	for _, m := range pending {
+		if m.Version <= current {
+			continue
+		}
+		if err := apply(ctx, db, component, m); err != nil {
+			return fmt.Errorf("migração %s %d (%s): %w", component, m.Version, m.Name, err)
+		}
+	}
+	return nil
+}
+
+func apply(ctx context.Context, db *sql.DB, component string, m Migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO schema_migrations (component, version, name, applied_at) VALUES (?, ?, ?, ?)`,
+		component, m.Version, m.Name, time.Now().UTC().Format(time.RFC3339),
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,71 @@
+package sqlitedb
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dados", "teste.db")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	migrations := []Migration{
+		{Version: 2, Name: "coluna nota", SQL: `ALTER TABLE itens ADD COLUMN nota INTEGER NOT NULL DEFAULT 0`},
+		{Version: 1, Name: "cria itens", SQL: `CREATE TABLE itens (id TEXT PRIMARY KEY)`},
+	}
+	// Fora de ordem e repetidas: cada migração roda uma única vez, pela versão
+	for i := 0; i < 2; i++ {
+		if err := Migrate(ctx, db, "itens", migrations); err != nil {
+			t.Fatalf("execução %d: %v", i+1, err)
+		}
+	}
+	if _, err := db.ExecContext(ctx, `INSERT INTO itens (id, nota) VALUES ('a', 7)`); err != nil {
+		t.Fatalf("esquema não migrado: %v", err)
+	}
+
+	var applied int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM schema_migrations WHERE component = 'itens'`).Scan(&applied); err != nil {
+		t.Fatal(err)
+	}
+	if applied != 2 {
+		t.Errorf("esperava 2 migrações registradas, obteve %d", applied)
+	}
+
+	// Componentes têm versões independentes
+	if err := Migrate(ctx, db, "outro", []Migration{{Version: 1, Name: "cria outro", SQL: `CREATE TABLE outro (id TEXT)`}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrateRollsBackFailure(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "teste.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	err = Migrate(ctx, db, "itens", []Migration{
+		{Version: 1, Name: "cria itens", SQL: `CREATE TABLE itens (id TEXT PRIMARY KEY)`},
+		{Version: 2, Name: "quebrada", SQL: `CREATE TABLE extra (id TEXT); ALTER TABLE inexistente ADD COLUMN x TEXT`},
+	})
+	if err == nil {
+		t.Fatal("esperava erro na migração quebrada")
+	}
+	var version int
+	db.QueryRowContext(ctx, `SELECT MAX(version) FROM schema_migrations WHERE component = 'itens'`).Scan(&version)
+	if version != 1 {
+		t.Errorf("esperava só a versão 1 registrada, obteve %d", version)
+	}
+	var tables int
+	db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE name = 'extra'`).Scan(&tables)
+	if tables != 0 {
+		t.Errorf("a migração com erro deveria ser desfeita por inteiro")
+	}
+}
@@ -0,0 +1,126 @@
+package tse
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Row é uma linha de um CSV do TSE indexada pelo nome da coluna
+type Row struct {
+	header map[string]int
+	values []string
+}
+
+// Get devolve o valor da coluna, sem os marcadores de nulo do TSE (#NULO#, #NE#)
+func (r Row) Get(column string) string {
+	i, ok := r.header[column]
+	if !ok || i >= len(r.values) {
+		return ""
+	}
+	value := strings.TrimSpace(r.values[i])
+	if strings.HasPrefix(value, "#NULO") || strings.HasPrefix(value, "#NE") {
+		return ""
+	}
+	return value
+}
+
+// Has informa se o arquivo tem a coluna; os layouts mudam entre eleições
+func (r Row) Has(column string) bool {
+	_, ok := r.header[column]
+	return ok
+}
+
+// Int devolve o valor numérico da coluna; códigos negativos do TSE (-1, -3) viram 0
+func (r Row) Int(column string) int {
+	n, err := strconv.Atoi(r.Get(column))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// Money interpreta valores no formato "150000,00" (ou "150.000,00")
+func (r Row) Money(column string) float64 {
+	value := r.Get(column)
+	if strings.Contains(value, ",") {
+		value = strings.Replace(strings.ReplaceAll(value, ".", ""), ",", ".", 1)
+	}
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// readCSV lê um CSV do TSE: separador ";", aspas duplas e codificação Latin-1
+func readCSV(r io.Reader, fn func(Row) error) (int, error) {
+	reader := csv.NewReader(charmap.ISO8859_1.NewDecoder().Reader(r))
+	reader.Comma = ';'
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	columns, err := reader.Read()
+	if err != nil {
+		return 0, fmt.Errorf("cabeçalho: %w", err)
+	}
+	header := make(map[string]int, len(columns))
+	for i, name := range columns {
+		header[strings.ToUpper(strings.TrimSpace(name))] = i
+	}
+
+	count := 0
+	for {
+		values, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return count, nil
+		}
+		if err != nil {
+			return count, err
+		}
+		if err := fn(Row{header: header, values: values}); err != nil {
+			return count, err
+		}
+		count++
+	}
+}
+
+// readZip processa os CSVs do arquivo zip cujo nome termina em _<ano>_<UF>.csv
+func readZip(zipPath string, ano int, uf string, fn func(Row) error) ([]string, error) {
+	archive, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	suffix := strings.ToUpper(fmt.Sprintf("_%d_%s.csv", ano, uf))
+	var read []string
+	for _, file := range archive.File {
+		name := path.Base(file.Name)
+		if !strings.HasSuffix(strings.ToUpper(name), suffix) {
+			continue
+		}
+		content, err := file.Open()
+		if err != nil {
+			return read, err
+		}
+		_, err = readCSV(content, fn)
+		content.Close()
+		if err != nil {
+			return read, fmt.Errorf("%s: %w", name, err)
+		}
+		read = append(read, name)
+	}
+	if len(read) == 0 {
+		return nil, fmt.Errorf("nenhum arquivo *%s em %s", strings.ToLower(suffix), path.Base(zipPath))
+	}
+	return read, nil
+}
@@ -0,0 +1,72 @@
+package tse
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadCSVDecodesLatin1(t *testing.T) {
+	file, err := os.Open(filepath.Join("testdata", "consulta_cand_2022_SP.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var rows []Row
+	count, err := readCSV(file, func(row Row) error {
+		// ReuseRecord reaproveita o slice entre as linhas
+		row.values = append([]string(nil), row.values...)
+		rows = append(rows, row)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 7 || len(rows) != 7 {
+		t.Fatalf("linhas lidas = %d, esperava 7", count)
+	}
+
+	first := rows[0]
+	if got := first.Get("NM_URNA_CANDIDATO"); got != "JOÃO DA SAÚDE" {
+		t.Errorf("NM_URNA_CANDIDATO = %q, esperava o texto decodificado de Latin-1", got)
+	}
+	if got := first.Get("DS_SIT_TOT_TURNO"); got != "2º TURNO" {
+		t.Errorf("DS_SIT_TOT_TURNO = %q", got)
+	}
+	if got := first.Int("SQ_CANDIDATO"); got != 250001 {
+		t.Errorf("SQ_CANDIDATO = %d", got)
+	}
+	if got := rows[4].Get("DS_OCUPACAO"); got != "" {
+		t.Errorf("#NULO# deveria virar vazio, obteve %q", got)
+	}
+	if got := rows[5].Get("DS_GRAU_INSTRUCAO"); got != "" {
+		t.Errorf("#NE# deveria virar vazio, obteve %q", got)
+	}
+	if first.Has("COLUNA_INEXISTENTE") || first.Get("COLUNA_INEXISTENTE") != "" {
+		t.Error("coluna inexistente deveria ser vazia")
+	}
+}
+
+func TestRowMoney(t *testing.T) {
+	tests := []struct {
+		value string
+		want  float64
+	}{
+		{value: "150000,00", want: 150000},
+		{value: "150.000,50", want: 150000.5},
+		{value: "1.234.567,89", want: 1234567.89},
+		{value: "1234.56", want: 1234.56},
+		{value: "35000", want: 35000},
+		{value: "#NULO#", want: 0},
+		{value: "", want: 0},
+		{value: "sem valor", want: 0},
+	}
+	header := map[string]int{"VR_BEM_CANDIDATO": 0}
+	for _, tt := range tests {
+		row := Row{header: header, values: []string{tt.value}}
+		if got := row.Money("VR_BEM_CANDIDATO"); got != tt.want {
+			t.Errorf("Money(%q) = %v, esperava %v", tt.value, got, tt.want)
+		}
+	}
+}
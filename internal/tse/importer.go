@@ -0,0 +1,272 @@
+package tse
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BaseURL é o repositório de arquivos do Portal de Dados Abertos do TSE
+const BaseURL = "https://cdn.tse.jus.br/estatistica/sead/odsele"
+
+// Conjuntos de dados importáveis
+const (
+	KindCandidatos = "candidatos"
+	KindBens       = "bens"
+	KindVotacao    = "votacao"
+)
+
+// Kinds lista os conjuntos na ordem de importação
+var Kinds = []string{KindCandidatos, KindBens, KindVotacao}
+
+// datasets associa cada conjunto ao nome do arquivo no portal (<nome>/<nome>_<ano>.zip)
+var datasets = map[string]string{
+	KindCandidatos: "consulta_cand",
+	KindBens:       "bem_candidato",
+	KindVotacao:    "votacao_candidato_munzona",
+}
+
+// Importer baixa os arquivos zip do TSE e grava os dados de um ano e UF no Store.
+// Arquivos já presentes em CacheDir não são baixados de novo.
+type Importer struct {
+	Store      *Store
+	CacheDir   string
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// ImportResult resume uma importação
+type ImportResult struct {
+	Ano        int      `json:"ano"`
+	UF         string   `json:"uf"`
+	Candidates int      `json:"candidatos"`
+	Assets     int      `json:"bens"`
+	Results    int      `json:"resultadosPorZona"`
+	Files      []string `json:"arquivos"`
+}
+
+// Import carrega os conjuntos informados (todos, se vazio) para o ano e a UF.
+// Conjuntos não informados mantêm os dados de importações anteriores.
+func (im *Importer) Import(ctx context.Context, ano int, uf string, kinds []string) (ImportResult, error) {
+	uf = strings.ToUpper(strings.TrimSpace(uf))
+	result := ImportResult{Ano: ano, UF: uf}
+	if len(kinds) == 0 {
+		kinds = Kinds
+	}
+
+	partition, _ := im.Store.Partition(ano, uf)
+	sources := map[string]bool{}
+	for _, source := range partition.Sources {
+		sources[source] = true
+	}
+
+	for _, kind := range kinds {
+		name, ok := datasets[kind]
+		if !ok {
+			return result, fmt.Errorf("conjunto desconhecido %q (use %s)", kind, strings.Join(Kinds, ", "))
+		}
+		zipPath, err := im.download(ctx, name, ano)
+		if err != nil {
+			return result, err
+		}
+
+		var files []string
+		switch kind {
+		case KindCandidatos:
+			partition.Candidates, files, err = readCandidates(zipPath, ano, uf)
+		case KindBens:
+			partition.Assets, files, err = readAssets(zipPath, ano, uf)
+		case KindVotacao:
+			partition.Results, files, err = readResults(zipPath, ano, uf)
+		}
+		if err != nil {
+			return result, fmt.Errorf("%s: %w", kind, err)
+		}
+		for _, file := range files {
+			sources[file] = true
+		}
+		result.Files = append(result.Files, files...)
+		log.Printf("[TSE] %s %d/%s: %d arquivo(s) lidos", kind, ano, uf, len(files))
+	}
+
+	partition.Sources = make([]string, 0, len(sources))
+	for source := range sources {
+		partition.Sources = append(partition.Sources, source)
+	}
+	sort.Strings(partition.Sources)
+	partition.ImportedAt = time.Now().UTC()
+	if err := im.Store.Save(partition); err != nil {
+		return result, err
+	}
+
+	result.Candidates = len(partition.Candidates)
+	result.Assets = len(partition.Assets)
+	result.Results = len(partition.Results)
+	return result, nil
+}
+
+// download devolve o caminho local do zip do conjunto, baixando-o se necessário
+func (im *Importer) download(ctx context.Context, name string, ano int) (string, error) {
+	fileName := fmt.Sprintf("%s_%d.zip", name, ano)
+	localPath := filepath.Join(im.CacheDir, fileName)
+	if info, err := os.Stat(localPath); err == nil && info.Size() > 0 {
+		return localPath, nil
+	}
+
+	baseURL := im.BaseURL
+	if baseURL == "" {
+		baseURL = BaseURL
+	}
+	client := im.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Minute}
+	}
+
+	url := fmt.Sprintf("%s/%s/%s", baseURL, name, fileName)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	log.Printf("[TSE] baixando %s", url)
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("TSE retornou status %d para %s", resp.StatusCode, url)
+	}
+
+	if err := os.MkdirAll(im.CacheDir, 0o755); err != nil {
+		return "", err
+	}
+	tempPath := localPath + ".tmp"
+	file, err := os.Create(tempPath)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(file, resp.Body); err != nil {
+		file.Close()
+		os.Remove(tempPath)
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+	return localPath, os.Rename(tempPath, localPath)
+}
+
+func readCandidates(zipPath string, ano int, uf string) ([]Candidate, []string, error) {
+	byID := map[string]Candidate{}
+	turnos := map[string]int{}
+	var order []string
+
+	files, err := readZip(zipPath, ano, uf, func(row Row) error {
+		id := row.Get("SQ_CANDIDATO")
+		if id == "" || row.Int("ANO_ELEICAO") != ano {
+			return nil
+		}
+		// Candidatos do segundo turno aparecem duas vezes; vale a situação do último turno
+		turno := row.Int("NR_TURNO")
+		if previous, ok := turnos[id]; ok && previous >= turno {
+			return nil
+		}
+		situacao := row.Get("DS_SITUACAO_CANDIDATURA")
+		if situacao == "" {
+			situacao = row.Get("DS_DETALHE_SITUACAO_CAND")
+		}
+		if _, ok := byID[id]; !ok {
+			order = append(order, id)
+		}
+		turnos[id] = turno
+		byID[id] = Candidate{
+			ID:            id,
+			Ano:           ano,
+			UF:            row.Get("SG_UF"),
+			CodigoUE:      row.Get("SG_UE"),
+			Municipio:     row.Get("NM_UE"),
+			Cargo:         row.Get("DS_CARGO"),
+			Numero:        row.Get("NR_CANDIDATO"),
+			Nome:          row.Get("NM_CANDIDATO"),
+			NomeUrna:      row.Get("NM_URNA_CANDIDATO"),
+			Partido:       row.Get("SG_PARTIDO"),
+			Situacao:      situacao,
+			Resultado:     row.Get("DS_SIT_TOT_TURNO"),
+			Ocupacao:      row.Get("DS_OCUPACAO"),
+			Genero:        row.Get("DS_GENERO"),
+			GrauInstrucao: row.Get("DS_GRAU_INSTRUCAO"),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	candidates := make([]Candidate, 0, len(order))
+	for _, id := range order {
+		candidates = append(candidates, byID[id])
+	}
+	return candidates, files, nil
+}
+
+func readAssets(zipPath string, ano int, uf string) ([]Asset, []string, error) {
+	var assets []Asset
+	files, err := readZip(zipPath, ano, uf, func(row Row) error {
+		id := row.Get("SQ_CANDIDATO")
+		if id == "" || row.Int("ANO_ELEICAO") != ano {
+			return nil
+		}
+		ordem := row.Int("NR_ORDEM_BEM_CANDIDATO")
+		if !row.Has("NR_ORDEM_BEM_CANDIDATO") {
+			ordem = row.Int("NR_ORDEM_CANDIDATO")
+		}
+		assets = append(assets, Asset{
+			CandidateID: id,
+			Ordem:       ordem,
+			Tipo:        row.Get("DS_TIPO_BEM_CANDIDATO"),
+			Descricao:   row.Get("DS_BEM_CANDIDATO"),
+			Valor:       row.Money("VR_BEM_CANDIDATO"),
+		})
+		return nil
+	})
+	return assets, files, err
+}
+
+func readResults(zipPath string, ano int, uf string) ([]ZoneResult, []string, error) {
+	var results []ZoneResult
+	files, err := readZip(zipPath, ano, uf, func(row Row) error {
+		id := row.Get("SQ_CANDIDATO")
+		if id == "" || row.Int("ANO_ELEICAO") != ano {
+			return nil
+		}
+		// A partir de 2024 o TSE separa os votos nominais válidos dos anulados
+		votos := row.Int("QT_VOTOS_NOMINAIS_VALIDOS")
+		if !row.Has("QT_VOTOS_NOMINAIS_VALIDOS") {
+			votos = row.Int("QT_VOTOS_NOMINAIS")
+		}
+		results = append(results, ZoneResult{
+			CandidateID:     id,
+			Ano:             ano,
+			Turno:           row.Int("NR_TURNO"),
+			UF:              row.Get("SG_UF"),
+			CodigoMunicipio: row.Get("CD_MUNICIPIO"),
+			Municipio:       row.Get("NM_MUNICIPIO"),
+			Zona:            row.Int("NR_ZONA"),
+			Cargo:           row.Get("DS_CARGO"),
+			Numero:          row.Get("NR_CANDIDATO"),
+			NomeUrna:        row.Get("NM_URNA_CANDIDATO"),
+			Partido:         row.Get("SG_PARTIDO"),
+			Votos:           votos,
+			Resultado:       row.Get("DS_SIT_TOT_TURNO"),
+		})
+		return nil
+	})
+	return results, files, err
+}
@@ -0,0 +1,130 @@
+package tse
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFixtureZip empacota o CSV de testdata no zip que o portal publicaria
+func writeFixtureZip(t *testing.T, dir, zipName, csvName string) {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join("testdata", csvName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Create(filepath.Join(dir, zipName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	archive := zip.NewWriter(file)
+	entry, err := archive.Create(csvName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := entry.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func importFixtures(t *testing.T) *Store {
+	t.Helper()
+	cache := t.TempDir()
+	writeFixtureZip(t, cache, "consulta_cand_2022.zip", "consulta_cand_2022_SP.csv")
+	writeFixtureZip(t, cache, "bem_candidato_2022.zip", "bem_candidato_2022_SP.csv")
+	writeFixtureZip(t, cache, "votacao_candidato_munzona_2022.zip", "votacao_candidato_munzona_2022_SP.csv")
+
+	store, err := NewStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// BaseURL inválida: os arquivos já estão no cache e nada deve ser baixado
+	importer := &Importer{Store: store, CacheDir: cache, BaseURL: "http://127.0.0.1:0"}
+	result, err := importer.Import(context.Background(), 2022, "sp", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.UF != "SP" || result.Candidates != 4 || result.Assets != 3 || result.Results != 8 || len(result.Files) != 3 {
+		t.Fatalf("resultado inesperado: %+v", result)
+	}
+	return store
+}
+
+func TestImportKeepsLastRound(t *testing.T) {
+	store := importFixtures(t)
+
+	tests := []struct {
+		id        string
+		resultado string
+	}{
+		// primeiro turno antes do segundo
+		{id: "250001", resultado: "ELEITO"},
+		// segundo turno antes do primeiro
+		{id: "250004", resultado: "NÃO ELEITO"},
+		{id: "250002", resultado: "ELEITO POR QP"},
+	}
+	for _, tt := range tests {
+		c, _, ok := store.Candidate(tt.id)
+		if !ok {
+			t.Errorf("candidatura %s não importada", tt.id)
+			continue
+		}
+		if c.Resultado != tt.resultado {
+			t.Errorf("candidatura %s: resultado %q, esperava %q", tt.id, c.Resultado, tt.resultado)
+		}
+	}
+	if _, _, ok := store.Candidate("180001"); ok {
+		t.Error("linha de outro ano não deveria ser importada")
+	}
+
+	partition, ok := store.Partition(2022, "SP")
+	if !ok {
+		t.Fatal("partição 2022/SP não gravada")
+	}
+	if len(partition.Candidates) != 4 {
+		t.Errorf("candidaturas = %d, esperava uma por SQ_CANDIDATO", len(partition.Candidates))
+	}
+	if partition.Candidates[0].Municipio != "SÃO PAULO" {
+		t.Errorf("município = %q", partition.Candidates[0].Municipio)
+	}
+
+	// A partição é gravada em disco e recarregada
+	reloaded, err := NewStore(store.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := reloaded.Partition(2022, "SP"); len(got.Results) != 8 {
+		t.Errorf("resultados recarregados = %d", len(got.Results))
+	}
+}
+
+func TestImportAssetsAndVotes(t *testing.T) {
+	store := importFixtures(t)
+
+	_, assets, _ := store.Candidate("250002")
+	if len(assets) != 2 || assets[0].Valor != 450000.5 || assets[1].Valor != 35000 {
+		t.Errorf("bens = %+v", assets)
+	}
+	if _, assets, _ := store.Candidate("250003"); len(assets) != 1 || assets[0].Valor != 0 {
+		t.Errorf("bem com valor nulo = %+v", assets)
+	}
+
+	found := store.SearchCandidates(CandidateQuery{Nome: "joao saude", UF: "SP"})
+	if len(found) != 1 {
+		t.Fatalf("busca sem acentos: %+v", found)
+	}
+	if found[0].Votos != 100 || found[0].VotosSegundoTurno != 200 {
+		t.Errorf("votos = %d/%d, esperava 100/200", found[0].Votos, found[0].VotosSegundoTurno)
+	}
+
+	ranking := store.Ranking(ResultQuery{UF: "SP", Cargo: "Governador", Turno: 2})
+	if len(ranking) != 2 || ranking[0].CandidateID != "250001" || ranking[0].Percentual != 57.14 {
+		t.Errorf("ranking do segundo turno = %+v", ranking)
+	}
+}
@@ -0,0 +1,392 @@
+package tse
+
+import (
+	"sort"
+	"strings"
+
+	"chat-bot/internal/textnorm"
+)
+
+// CandidateQuery filtra candidaturas. Texto é comparado sem acentos e sem
+// diferenciar maiúsculas; Ano 0 usa a eleição mais recente importada.
+type CandidateQuery struct {
+	Nome      string
+	Cargo     string
+	Municipio string
+	UF        string
+	Ano       int
+	Eleitos   bool
+	Limit     int
+}
+
+// CandidateSummary é uma candidatura com o total de bens e de votos
+type CandidateSummary struct {
+	Candidate
+	TotalBens         float64 `json:"totalBens"`
+	Votos             int     `json:"votos"`
+	VotosSegundoTurno int     `json:"votosSegundoTurno,omitempty"`
+}
+
+// ResultQuery filtra a votação por zona
+type ResultQuery struct {
+	Ano         int
+	UF          string
+	Municipio   string
+	Cargo       string
+	Zona        int
+	Turno       int
+	CandidateID string
+	Limit       int
+}
+
+// CandidateVotes é a votação de um candidato somada nas zonas filtradas
+type CandidateVotes struct {
+	CandidateID string  `json:"candidateId"`
+	NomeUrna    string  `json:"nomeUrna"`
+	Numero      string  `json:"numero"`
+	Partido     string  `json:"partido"`
+	Cargo       string  `json:"cargo"`
+	Turno       int     `json:"turno"`
+	Votos       int     `json:"votos"`
+	Percentual  float64 `json:"percentual"`
+	Resultado   string  `json:"resultado,omitempty"`
+	Zonas       int     `json:"zonas"`
+}
+
+// matchesText verifica se todos os termos da consulta aparecem no texto
+func matchesText(query []string, texts ...string) bool {
+	if len(query) == 0 {
+		return true
+	}
+	folded := " " + strings.Join(textnorm.Tokens(strings.Join(texts, " ")), " ") + " "
+	for _, token := range query {
+		if !strings.Contains(folded, " "+token) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameText compara sem acentos e sem diferenciar maiúsculas
+func sameText(query, value string) bool {
+	return query == "" || textnorm.Fold(strings.TrimSpace(query)) == textnorm.Fold(strings.TrimSpace(value))
+}
+
+func (s *Store) latestYearLocked(uf string) int {
+	latest := 0
+	for _, p := range s.partitions {
+		if (uf == "" || strings.EqualFold(p.UF, uf)) && p.Ano > latest {
+			latest = p.Ano
+		}
+	}
+	return latest
+}
+
+// LatestYear devolve o ano mais recente importado (para a UF, se informada)
+func (s *Store) LatestYear(uf string) int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.latestYearLocked(uf)
+}
+
+func (s *Store) partitionsLocked(ano int, uf string) []*Partition {
+	if ano == 0 {
+		ano = s.latestYearLocked(uf)
+	}
+	var selected []*Partition
+	for _, p := range s.partitions {
+		if p.Ano == ano && (uf == "" || strings.EqualFold(p.UF, uf)) {
+			selected = append(selected, p)
+		}
+	}
+	return selected
+}
+
+// SearchCandidates busca candidaturas por nome, cargo, município e UF
+func (s *Store) SearchCandidates(q CandidateQuery) []CandidateSummary {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	name := textnorm.Tokens(q.Nome)
+	var found []CandidateSummary
+	for _, p := range s.partitionsLocked(q.Ano, q.UF) {
+		for _, c := range p.Candidates {
+			if !matchesText(name, c.Nome, c.NomeUrna) {
+				continue
+			}
+			if !sameText(q.Municipio, c.Municipio) || !sameText(q.Cargo, c.Cargo) {
+				continue
+			}
+			if q.Eleitos && !c.Eleito() {
+				continue
+			}
+			found = append(found, CandidateSummary{Candidate: c})
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Eleito() != found[j].Eleito() {
+			return found[i].Eleito()
+		}
+		if found[i].NomeUrna != found[j].NomeUrna {
+			return found[i].NomeUrna < found[j].NomeUrna
+		}
+		return found[i].ID < found[j].ID
+	})
+	if q.Limit > 0 && len(found) > q.Limit {
+		found = found[:q.Limit]
+	}
+
+	votes := s.votesByCandidateLocked(found)
+	for i := range found {
+		for _, a := range s.assets[found[i].ID] {
+			found[i].TotalBens += a.Valor
+		}
+		found[i].Votos = votes[found[i].ID][1]
+		found[i].VotosSegundoTurno = votes[found[i].ID][2]
+	}
+	return found
+}
+
+func (s *Store) votesByCandidateLocked(candidates []CandidateSummary) map[string]map[int]int {
+	wanted := map[string]map[int]int{}
+	partitions := map[string]bool{}
+	for _, c := range candidates {
+		wanted[c.ID] = map[int]int{}
+		partitions[partitionKey(c.Ano, c.UF)] = true
+	}
+	for key := range partitions {
+		p, ok := s.partitions[key]
+		if !ok {
+			continue
+		}
+		for _, r := range p.Results {
+			if byTurno, ok := wanted[r.CandidateID]; ok {
+				byTurno[r.Turno] += r.Votos
+			}
+		}
+	}
+	return wanted
+}
+
+// Candidate devolve uma candidatura com os bens declarados, do maior para o menor valor
+func (s *Store) Candidate(id string) (Candidate, []Asset, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	c, ok := s.candidates[id]
+	if !ok {
+		return Candidate{}, nil, false
+	}
+	assets := append([]Asset(nil), s.assets[id]...)
+	sort.Slice(assets, func(i, j int) bool {
+		if assets[i].Valor != assets[j].Valor {
+			return assets[i].Valor > assets[j].Valor
+		}
+		return assets[i].Ordem < assets[j].Ordem
+	})
+	return c, assets, true
+}
+
+func (s *Store) filterResultsLocked(q ResultQuery) []ZoneResult {
+	var found []ZoneResult
+	for _, p := range s.partitionsLocked(q.Ano, q.UF) {
+		for _, r := range p.Results {
+			if q.CandidateID != "" && r.CandidateID != q.CandidateID {
+				continue
+			}
+			if q.Zona > 0 && r.Zona != q.Zona {
+				continue
+			}
+			if q.Turno > 0 && r.Turno != q.Turno {
+				continue
+			}
+			if !sameText(q.Municipio, r.Municipio) || !sameText(q.Cargo, r.Cargo) {
+				continue
+			}
+			found = append(found, r)
+		}
+	}
+	return found
+}
+
+// Results devolve a votação por zona, da maior para a menor
+func (s *Store) Results(q ResultQuery) []ZoneResult {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	found := s.filterResultsLocked(q)
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Votos != found[j].Votos {
+			return found[i].Votos > found[j].Votos
+		}
+		if found[i].Zona != found[j].Zona {
+			return found[i].Zona < found[j].Zona
+		}
+		return found[i].CandidateID < found[j].CandidateID
+	})
+	if q.Limit > 0 && len(found) > q.Limit {
+		found = found[:q.Limit]
+	}
+	return found
+}
+
+// Ranking soma a votação dos candidatos nas zonas filtradas, por turno. O
+// percentual é calculado sobre os votos nominais do mesmo cargo e turno.
+func (s *Store) Ranking(q ResultQuery) []CandidateVotes {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	candidateID := q.CandidateID
+	q.CandidateID = ""
+	type group struct {
+		cargo string
+		turno int
+	}
+	type candidateKey struct {
+		id string
+		group
+	}
+	byKey := map[candidateKey]*CandidateVotes{}
+	totals := map[group]int{}
+	for _, r := range s.filterResultsLocked(q) {
+		g := group{r.Cargo, r.Turno}
+		totals[g] += r.Votos
+		key := candidateKey{r.CandidateID, g}
+		entry, ok := byKey[key]
+		if !ok {
+			entry = &CandidateVotes{
+				CandidateID: r.CandidateID,
+				NomeUrna:    r.NomeUrna,
+				Numero:      r.Numero,
+				Partido:     r.Partido,
+				Cargo:       r.Cargo,
+				Turno:       r.Turno,
+				Resultado:   r.Resultado,
+			}
+			byKey[key] = entry
+		}
+		entry.Votos += r.Votos
+		entry.Zonas++
+	}
+
+	ranking := make([]CandidateVotes, 0, len(byKey))
+	for _, entry := range byKey {
+		if candidateID != "" && entry.CandidateID != candidateID {
+			continue
+		}
+		if total := totals[group{entry.Cargo, entry.Turno}]; total > 0 {
+			entry.Percentual = float64(int(float64(entry.Votos)/float64(total)*10000)) / 100
+		}
+		ranking = append(ranking, *entry)
+	}
+	sort.Slice(ranking, func(i, j int) bool {
+		if ranking[i].Cargo != ranking[j].Cargo {
+			return ranking[i].Cargo < ranking[j].Cargo
+		}
+		if ranking[i].Turno != ranking[j].Turno {
+			return ranking[i].Turno > ranking[j].Turno
+		}
+		if ranking[i].Votos != ranking[j].Votos {
+			return ranking[i].Votos > ranking[j].Votos
+		}
+		return ranking[i].CandidateID < ranking[j].CandidateID
+	})
+	if q.Limit > 0 && len(ranking) > q.Limit {
+		ranking = ranking[:q.Limit]
+	}
+	return ranking
+}
+
+// Performance é o desempenho eleitoral de um parlamentar na última eleição importada
+type Performance struct {
+	Candidate Candidate `json:"candidate"`
+	Votos     int       `json:"votos"`
+	// Quociente é a referência usada no eixo Popularidade: votos nominais do
+	// cargo na UF divididos pelo número de eleitos (proporcionais) ou metade
+	// dos votos nominais (majoritários).
+	Quociente int `json:"quociente"`
+}
+
+// latestPartitionForCargoLocked devolve a partição mais recente da UF com
+// votação para o cargo. Eleições municipais e gerais se alternam, então a
+// mais recente da UF pode não ter o cargo procurado.
+func (s *Store) latestPartitionForCargoLocked(cargo, uf string) *Partition {
+	var latest *Partition
+	for _, p := range s.partitions {
+		if !strings.EqualFold(p.UF, uf) || len(p.Results) == 0 || (latest != nil && p.Ano <= latest.Ano) {
+			continue
+		}
+		for _, r := range p.Results {
+			if sameText(cargo, r.Cargo) {
+				latest = p
+				break
+			}
+		}
+	}
+	return latest
+}
+
+// FindPerformance localiza a candidatura do parlamentar (cargo, UF e nome de
+// urna ou civil) na eleição mais recente importada para a UF com o cargo
+func (s *Store) FindPerformance(cargo, uf string, names ...string) (Performance, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	p := s.latestPartitionForCargoLocked(cargo, uf)
+	if p == nil {
+		return Performance{}, false
+	}
+
+	var match Candidate
+	found := false
+	for _, c := range p.Candidates {
+		if !sameText(cargo, c.Cargo) {
+			continue
+		}
+		for _, name := range names {
+			if name != "" && (sameText(name, c.NomeUrna) || sameText(name, c.Nome)) {
+				if !found || (c.Eleito() && !match.Eleito()) {
+					match = c
+					found = true
+				}
+			}
+		}
+	}
+	if !found {
+		return Performance{}, false
+	}
+
+	total, votes := 0, 0
+	for _, r := range p.Results {
+		if r.Turno != 1 || !sameText(cargo, r.Cargo) {
+			continue
+		}
+		total += r.Votos
+		if r.CandidateID == match.ID {
+			votes += r.Votos
+		}
+	}
+	elected := 0
+	for _, c := range p.Candidates {
+		if c.Eleito() && sameText(cargo, c.Cargo) {
+			elected++
+		}
+	}
+
+	perf := Performance{Candidate: match, Votos: votes}
+	if majoritario(cargo) || elected == 0 {
+		perf.Quociente = total / 2
+	} else {
+		perf.Quociente = total / elected
+	}
+	return perf, votes > 0 && perf.Quociente > 0
+}
+
+func majoritario(cargo string) bool {
+	switch textnorm.Fold(cargo) {
+	case "senador", "governador", "prefeito", "presidente":
+		return true
+	}
+	return false
+}
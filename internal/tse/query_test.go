@@ -0,0 +1,42 @@
+package tse
+
+import "testing"
+
+func TestFindPerformanceUsesLatestElectionForCargo(t *testing.T) {
+	store := importFixtures(t)
+
+	// A eleição municipal de 2024 é mais recente, mas não tem deputados
+	err := store.Save(Partition{
+		Ano: 2024,
+		UF:  "SP",
+		Candidates: []Candidate{
+			{ID: "260001", Ano: 2024, UF: "SP", Cargo: "PREFEITO", Nome: "MARIA CONCEIÇÃO", NomeUrna: "MARIA DO POVO", Resultado: "ELEITO"},
+		},
+		Results: []ZoneResult{
+			{CandidateID: "260001", Ano: 2024, Turno: 1, UF: "SP", Cargo: "PREFEITO", Votos: 5000},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	perf, ok := store.FindPerformance("Deputado Federal", "SP", "Maria do Povo")
+	if !ok {
+		t.Fatal("desempenho de 2022 não encontrado")
+	}
+	if perf.Candidate.ID != "250002" || perf.Votos != 100000 || perf.Quociente != 150000 {
+		t.Errorf("desempenho = %+v", perf)
+	}
+
+	perf, ok = store.FindPerformance("Prefeito", "SP", "Maria do Povo")
+	if !ok || perf.Candidate.Ano != 2024 || perf.Quociente != 2500 {
+		t.Errorf("desempenho na eleição municipal = %+v (ok=%v)", perf, ok)
+	}
+
+	if _, ok := store.FindPerformance("Deputado Federal", "RJ", "Maria do Povo"); ok {
+		t.Error("UF sem dados não deveria ter desempenho")
+	}
+	if _, ok := store.FindPerformance("Senador", "SP", "Maria do Povo"); ok {
+		t.Error("cargo sem votação não deveria ter desempenho")
+	}
+}
@@ -0,0 +1,222 @@
+// Package tse importa os dados abertos do Tribunal Superior Eleitoral
+// (candidaturas, bens declarados e votação por zona) e responde consultas locais.
+package tse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Candidate é uma candidatura registrada no TSE. O ID é o SQ_CANDIDATO, único por eleição.
+type Candidate struct {
+	ID            string `json:"id"`
+	Ano           int    `json:"ano"`
+	UF            string `json:"uf"`
+	CodigoUE      string `json:"codigoUe"`
+	Municipio     string `json:"municipio"`
+	Cargo         string `json:"cargo"`
+	Numero        string `json:"numero"`
+	Nome          string `json:"nome"`
+	NomeUrna      string `json:"nomeUrna"`
+	Partido       string `json:"partido"`
+	Situacao      string `json:"situacao,omitempty"`
+	Resultado     string `json:"resultado,omitempty"`
+	Ocupacao      string `json:"ocupacao,omitempty"`
+	Genero        string `json:"genero,omitempty"`
+	GrauInstrucao string `json:"grauInstrucao,omitempty"`
+}
+
+// Eleito informa se o resultado da candidatura é de eleito (por QP, por média etc.)
+func (c Candidate) Eleito() bool {
+	return strings.HasPrefix(strings.ToUpper(c.Resultado), "ELEITO")
+}
+
+// Asset é um bem declarado pelo candidato
+type Asset struct {
+	CandidateID string  `json:"candidateId"`
+	Ordem       int     `json:"ordem"`
+	Tipo        string  `json:"tipo"`
+	Descricao   string  `json:"descricao"`
+	Valor       float64 `json:"valor"`
+}
+
+// ZoneResult é a votação nominal de um candidato em uma zona eleitoral
+type ZoneResult struct {
+	CandidateID     string `json:"candidateId"`
+	Ano             int    `json:"ano"`
+	Turno           int    `json:"turno"`
+	UF              string `json:"uf"`
+	CodigoMunicipio string `json:"codigoMunicipio"`
+	Municipio       string `json:"municipio"`
+	Zona            int    `json:"zona"`
+	Cargo           string `json:"cargo"`
+	Numero          string `json:"numero"`
+	NomeUrna        string `json:"nomeUrna"`
+	Partido         string `json:"partido"`
+	Votos           int    `json:"votos"`
+	Resultado       string `json:"resultado,omitempty"`
+}
+
+// Partition reúne os dados importados de um ano e UF
+type Partition struct {
+	Ano        int          `json:"ano"`
+	UF         string       `json:"uf"`
+	ImportedAt time.Time    `json:"importedAt"`
+	Sources    []string     `json:"sources"`
+	Candidates []Candidate  `json:"candidates"`
+	Assets     []Asset      `json:"assets"`
+	Results    []ZoneResult `json:"results"`
+}
+
+// DatasetInfo resume uma partição importada
+type DatasetInfo struct {
+	Ano        int       `json:"ano"`
+	UF         string    `json:"uf"`
+	Candidates int       `json:"candidatos"`
+	Assets     int       `json:"bens"`
+	Results    int       `json:"resultadosPorZona"`
+	ImportedAt time.Time `json:"importedAt"`
+}
+
+func partitionKey(ano int, uf string) string {
+	return fmt.Sprintf("%d-%s", ano, strings.ToUpper(uf))
+}
+
+// Store guarda as partições importadas em arquivos JSON (um por ano e UF) no diretório informado
+type Store struct {
+	dir        string
+	partitions map[string]*Partition
+	candidates map[string]Candidate
+	assets     map[string][]Asset
+	mutex      sync.RWMutex
+}
+
+// NewStore cria o armazenamento e carrega as partições já importadas
+func NewStore(dir string) (*Store, error) {
+	s := &Store{dir: dir, partitions: map[string]*Partition{}}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) load() error {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, name := range files {
+		file, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		var p Partition
+		err = json.NewDecoder(file).Decode(&p)
+		file.Close()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				continue
+			}
+			return fmt.Errorf("%s: %w", filepath.Base(name), err)
+		}
+		s.partitions[partitionKey(p.Ano, p.UF)] = &p
+	}
+	s.reindexLocked()
+	return nil
+}
+
+func (s *Store) reindexLocked() {
+	s.candidates = map[string]Candidate{}
+	s.assets = map[string][]Asset{}
+	for _, p := range s.partitions {
+		for _, c := range p.Candidates {
+			s.candidates[c.ID] = c
+		}
+		for _, a := range p.Assets {
+			s.assets[a.CandidateID] = append(s.assets[a.CandidateID], a)
+		}
+	}
+}
+
+func (s *Store) saveLocked(p *Partition) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	filePath := filepath.Join(s.dir, partitionKey(p.Ano, p.UF)+".json")
+	tempPath := filePath + ".tmp"
+	if err := os.WriteFile(tempPath, payload, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, filePath)
+}
+
+// Partition devolve uma cópia rasa da partição de um ano e UF
+func (s *Store) Partition(ano int, uf string) (Partition, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	p, ok := s.partitions[partitionKey(ano, uf)]
+	if !ok {
+		return Partition{Ano: ano, UF: strings.ToUpper(uf)}, false
+	}
+	return *p, true
+}
+
+// Save grava a partição, substituindo a anterior do mesmo ano e UF
+func (s *Store) Save(p Partition) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	p.UF = strings.ToUpper(p.UF)
+	if err := s.saveLocked(&p); err != nil {
+		return err
+	}
+	s.partitions[partitionKey(p.Ano, p.UF)] = &p
+	s.reindexLocked()
+	return nil
+}
+
+// Datasets lista as partições importadas, das mais recentes para as mais antigas
+func (s *Store) Datasets() []DatasetInfo {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	items := make([]DatasetInfo, 0, len(s.partitions))
+	for _, p := range s.partitions {
+		items = append(items, DatasetInfo{
+			Ano:        p.Ano,
+			UF:         p.UF,
+			Candidates: len(p.Candidates),
+			Assets:     len(p.Assets),
+			Results:    len(p.Results),
+			ImportedAt: p.ImportedAt,
+		})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Ano != items[j].Ano {
+			return items[i].Ano > items[j].Ano
+		}
+		return items[i].UF < items[j].UF
+	})
+	return items
+}
+
+// Empty informa se nenhum dado foi importado
+func (s *Store) Empty() bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.partitions) == 0
+}
@@ -0,0 +1,282 @@
+package votes
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"chat-bot/internal/camara"
+	"chat-bot/internal/senado"
+)
+
+// ingestWindow divide o período em janelas menores para respeitar os limites das APIs
+const ingestWindow = 30 * 24 * time.Hour
+
+// Ingester busca votações nominais na Câmara e no Senado e grava no Store
+type Ingester struct {
+	Camara *camara.Client
+	Senado *senado.Client
+	Store  *Store
+	// SenadoGovernmentLeader é o código do líder do governo no Senado; o voto dele
+	// é usado como orientação do governo, que o Senado não publica por votação.
+	SenadoGovernmentLeader string
+}
+
+// IngestResult resume uma execução da ingestão
+type IngestResult struct {
+	Camara  int      `json:"camara"`
+	Senado  int      `json:"senado"`
+	Skipped int      `json:"skipped"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+// Run ingere as votações do período informado. Votações já armazenadas são
+// ignoradas. O período só avança até a última janela ingerida sem erros:
+// uma votação que falhou é buscada de novo na próxima execução.
+func (in *Ingester) Run(ctx context.Context, from, to time.Time) (IngestResult, error) {
+	var result IngestResult
+	var collected []Vote
+	var through time.Time
+	failed := false
+
+	for start := from; !start.After(to); start = start.Add(ingestWindow) {
+		end := start.Add(ingestWindow - 24*time.Hour)
+		if end.After(to) {
+			end = to
+		}
+		errorsBefore := len(result.Errors)
+
+		if in.Camara != nil {
+			camaraVotes, skipped, err := in.ingestCamara(ctx, start, end)
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("câmara %s: %v", start.Format("2006-01-02"), err))
+			}
+			collected = append(collected, camaraVotes...)
+			result.Camara += len(camaraVotes)
+			result.Skipped += skipped
+		}
+
+		if in.Senado != nil {
+			senadoVotes, skipped, err := in.ingestSenado(ctx, start, end)
+			if err != nil {
+				result.Errors = append(result.Errors, fmt.Sprintf("senado %s: %v", start.Format("2006-01-02"), err))
+			}
+			collected = append(collected, senadoVotes...)
+			result.Senado += len(senadoVotes)
+			result.Skipped += skipped
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+		if len(result.Errors) > errorsBefore {
+			failed = true
+		} else if !failed {
+			through = end
+		}
+	}
+
+	if err := in.Store.Upsert(collected, through); err != nil {
+		return result, err
+	}
+
+	log.Printf("[VOTAÇÕES] ingeridas %d da Câmara e %d do Senado (%d ignoradas, %d erros)", result.Camara, result.Senado, result.Skipped, len(result.Errors))
+	if len(result.Errors) > 0 {
+		return result, fmt.Errorf("votações com erro serão buscadas de novo: %s", strings.Join(result.Errors, "; "))
+	}
+	return result, nil
+}
+
+func truncate(s string, max int) string {
+	s = strings.TrimSpace(s)
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:max])) + "…"
+}
+
+func buildTitle(proposicao, ementa, descricao string) string {
+	if proposicao == "" {
+		return truncate(descricao, 120)
+	}
+	if ementa == "" {
+		return proposicao
+	}
+	return fmt.Sprintf("%s – %s", proposicao, truncate(ementa, 100))
+}
+
+func (in *Ingester) ingestCamara(ctx context.Context, from, to time.Time) ([]Vote, int, error) {
+	votacoes, err := in.Camara.ListVotacoes(ctx, camara.IDOrgaoPlenario, from.Format("2006-01-02"), to.Format("2006-01-02"), 0)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var collected []Vote
+	var failures []string
+	skipped := 0
+	for _, votacao := range votacoes {
+		id := "camara-" + votacao.ID
+		if in.Store.Has(id) {
+			continue
+		}
+		if ctx.Err() != nil {
+			return collected, skipped, ctx.Err()
+		}
+
+		votos, err := in.Camara.Votos(ctx, votacao.ID)
+		if err != nil {
+			// Não é gravada: a próxima execução tenta de novo
+			failures = append(failures, fmt.Sprintf("votos de %s: %v", votacao.ID, err))
+			continue
+		}
+		if len(votos) == 0 {
+			// Votações simbólicas não têm votos nominais
+			skipped++
+			continue
+		}
+
+		vote := Vote{
+			ID:         id,
+			SourceID:   votacao.ID,
+			Chamber:    ChamberCamara,
+			Date:       firstN(votacao.Data, 10),
+			Highlight:  strings.TrimSpace(votacao.Descricao),
+			Source:     "Câmara dos Deputados",
+			SourceURL:  fmt.Sprintf("%s/votacoes/%s", camara.BaseURL, votacao.ID),
+			IngestedAt: time.Now().UTC(),
+		}
+
+		if detalhe, err := in.Camara.GetVotacao(ctx, votacao.ID); err == nil {
+			afetadas := append(detalhe.ProposicoesAfetadas, detalhe.ObjetosPossiveis...)
+			if len(afetadas) > 0 {
+				p := afetadas[0]
+				vote.Proposicao = fmt.Sprintf("%s %d/%d", p.SiglaTipo, p.Numero, p.Ano)
+				vote.Ementa = strings.TrimSpace(p.Ementa)
+				vote.SourceURL = fmt.Sprintf("https://www.camara.leg.br/propostas-legislativas/%d", p.ID)
+			}
+		}
+		if vote.Proposicao == "" {
+			vote.Proposicao = strings.TrimSpace(votacao.ProposicaoObjeto)
+		}
+
+		if orientacoes, err := in.Camara.Orientacoes(ctx, votacao.ID); err == nil {
+			for _, o := range orientacoes {
+				if strings.EqualFold(strings.TrimSpace(o.SiglaPartidoBloco), governmentVoteLabel) {
+					vote.GovernmentOrientation = o.OrientacaoVoto
+					break
+				}
+			}
+		}
+
+		for _, v := range votos {
+			vote.Votes = append(vote.Votes, NominalVote{
+				PoliticianID: "camara-" + strconv.Itoa(v.Deputado.ID),
+				Nome:         v.Deputado.Nome,
+				Partido:      v.Deputado.SiglaPartido,
+				UF:           v.Deputado.SiglaUF,
+				Voto:         v.TipoVoto,
+			})
+			countVote(&vote, v.TipoVoto)
+		}
+
+		var approved *bool
+		if votacao.Aprovacao != nil {
+			value := *votacao.Aprovacao == 1
+			approved = &value
+		}
+		vote.Stage = deriveStage(approved, vote.Highlight)
+		vote.Topic = ClassifyTopic(vote.Ementa, vote.Highlight)
+		vote.Title = buildTitle(vote.Proposicao, vote.Ementa, vote.Highlight)
+		computeAlignment(&vote)
+
+		collected = append(collected, vote)
+	}
+
+	if len(failures) > 0 {
+		return collected, skipped, fmt.Errorf("%d votações com erro: %s", len(failures), strings.Join(failures, "; "))
+	}
+	return collected, skipped, nil
+}
+
+func (in *Ingester) ingestSenado(ctx context.Context, from, to time.Time) ([]Vote, int, error) {
+	votacoes, err := in.Senado.ListVotacoesPlenario(ctx, from, to)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var collected []Vote
+	skipped := 0
+	for _, votacao := range votacoes {
+		id := "senado-" + votacao.CodigoSessaoVotacao
+		if in.Store.Has(id) {
+			continue
+		}
+		if strings.EqualFold(votacao.Secreta, "S") {
+			// Votações secretas não permitem calcular alinhamento
+			skipped++
+			continue
+		}
+
+		vote := Vote{
+			ID:         id,
+			SourceID:   votacao.CodigoSessaoVotacao,
+			Chamber:    ChamberSenado,
+			Date:       firstN(votacao.DataSessao, 10),
+			Highlight:  strings.TrimSpace(votacao.DescricaoVotacao),
+			Source:     "Senado Federal",
+			SourceURL:  "https://www25.senado.leg.br/web/atividade/materias/-/materia/" + votacao.CodigoMateria,
+			IngestedAt: time.Now().UTC(),
+		}
+		if votacao.SiglaMateria != "" {
+			vote.Proposicao = fmt.Sprintf("%s %s/%s", votacao.SiglaMateria, votacao.NumeroMateria, votacao.AnoMateria)
+		}
+
+		for _, v := range votacao.Votos.VotoParlamentar {
+			vote.Votes = append(vote.Votes, NominalVote{
+				PoliticianID: "senado-" + v.CodigoParlamentar,
+				Nome:         v.NomeParlamentar,
+				Partido:      v.SiglaPartido,
+				UF:           v.SiglaUF,
+				Voto:         v.Voto,
+			})
+			countVote(&vote, v.Voto)
+			if in.SenadoGovernmentLeader != "" && v.CodigoParlamentar == in.SenadoGovernmentLeader {
+				vote.GovernmentOrientation = v.Voto
+			}
+		}
+		if len(vote.Votes) == 0 {
+			vote.Support, _ = strconv.Atoi(votacao.TotalVotosSim)
+			vote.Against, _ = strconv.Atoi(votacao.TotalVotosNao)
+			vote.Abstention, _ = strconv.Atoi(votacao.TotalVotosAbstencao)
+		}
+
+		var approved *bool
+		switch strings.ToUpper(strings.TrimSpace(votacao.Resultado)) {
+		case "A":
+			value := true
+			approved = &value
+		case "R":
+			value := false
+			approved = &value
+		}
+		vote.Stage = deriveStage(approved, vote.Highlight)
+		vote.Topic = ClassifyTopic(vote.Highlight)
+		vote.Title = buildTitle(vote.Proposicao, vote.Highlight, vote.Highlight)
+		computeAlignment(&vote)
+
+		collected = append(collected, vote)
+	}
+
+	return collected, skipped, nil
+}
+
+func firstN(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
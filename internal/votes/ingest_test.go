@@ -0,0 +1,98 @@
+package votes
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"chat-bot/internal/camara"
+)
+
+// fakeCamara responde como a API da Câmara; os votos da votação "2" falham
+// na primeira chamada e a votação "3" é simbólica (sem votos nominais)
+func fakeCamara(t *testing.T) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	failures := map[string]int{"2": 1}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/votacoes", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"dados":[
+			{"id":"1","data":"2026-10-05","descricao":"Aprovado o projeto"},
+			{"id":"2","data":"2026-10-06","descricao":"Aprovado o requerimento"},
+			{"id":"3","data":"2026-10-07","descricao":"Votação simbólica"}
+		],"links":[]}`))
+	})
+	mux.HandleFunc("/votacoes/{id}/votos", func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		mu.Lock()
+		fail := failures[id] > 0
+		if fail {
+			failures[id]--
+		}
+		mu.Unlock()
+
+		switch {
+		case fail:
+			http.Error(w, "indisponível", http.StatusServiceUnavailable)
+		case id == "3":
+			w.Write([]byte(`{"dados":[]}`))
+		default:
+			w.Write([]byte(`{"dados":[{"tipoVoto":"Sim","deputado_":{"id":10,"nome":"Fulano","siglaPartido":"PT","siglaUf":"SP"}}]}`))
+		}
+	})
+	mux.HandleFunc("/votacoes/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"dados":{}}`))
+	})
+	mux.HandleFunc("/votacoes/{id}/orientacoes", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"dados":[]}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestIngestRetriesFailedVotacao(t *testing.T) {
+	server := fakeCamara(t)
+	store, err := NewStore(filepath.Join(t.TempDir(), "votes.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	in := &Ingester{Camara: camara.NewClientWithBaseURL(server.URL, server.Client()), Store: store}
+
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 10, 10, 0, 0, 0, 0, time.UTC)
+
+	result, err := in.Run(context.Background(), from, to)
+	if err == nil {
+		t.Fatal("esperava erro com a votação que falhou")
+	}
+	if result.Camara != 1 || result.Skipped != 1 {
+		t.Errorf("primeira execução: %+v, esperava 1 ingerida e 1 ignorada", result)
+	}
+	if !store.Has("camara-1") || store.Has("camara-2") || store.Has("camara-3") {
+		t.Error("só a votação 1 deveria estar gravada")
+	}
+	if !store.IngestedThrough().IsZero() {
+		t.Errorf("período ingerido avançou apesar do erro: %v", store.IngestedThrough())
+	}
+	if store.LastIngest().IsZero() {
+		t.Error("horário da última ingestão deveria ser registrado")
+	}
+
+	result, err = in.Run(context.Background(), from, to)
+	if err != nil {
+		t.Fatalf("segunda execução: %v", err)
+	}
+	if result.Camara != 1 || !store.Has("camara-2") {
+		t.Errorf("a votação 2 deveria ser gravada na nova tentativa: %+v", result)
+	}
+	if !store.IngestedThrough().Equal(to) {
+		t.Errorf("período ingerido = %v, esperava %v", store.IngestedThrough(), to)
+	}
+}
@@ -0,0 +1,256 @@
+package votes
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"chat-bot/internal/textnorm"
+)
+
+// Store guarda as votações ingeridas em um arquivo JSON local
+type Store struct {
+	filePath   string
+	votes      map[string]Vote
+	lastIngest time.Time
+	// ingestedThrough é o fim do período ingerido sem erros; a próxima
+	// ingestão recomeça dele, e não do horário da última execução
+	ingestedThrough time.Time
+	mutex           sync.RWMutex
+}
+
+type storeFile struct {
+	LastIngest      time.Time `json:"lastIngest"`
+	IngestedThrough time.Time `json:"ingestedThrough"`
+	Votes           []Vote    `json:"votes"`
+}
+
+// NewStore cria o armazenamento e carrega o arquivo local, se existir
+func NewStore(filePath string) (*Store, error) {
+	s := &Store{filePath: filePath, votes: map[string]Vote{}}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) load() error {
+	file, err := os.Open(s.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+	return s.decode(file)
+}
+
+func (s *Store) decode(reader io.Reader) error {
+	var data storeFile
+	if err := json.NewDecoder(reader).Decode(&data); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+
+	for _, v := range data.Votes {
+		// O tema é recalculado para acompanhar mudanças nas palavras-chave
+		v.Topic = ClassifyTopic(v.Ementa, v.Highlight)
+		s.votes[v.ID] = v
+	}
+	s.lastIngest = data.LastIngest
+	s.ingestedThrough = data.IngestedThrough
+	return nil
+}
+
+func (s *Store) saveLocked() error {
+	if dir := filepath.Dir(s.filePath); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	data := storeFile{LastIngest: s.lastIngest, IngestedThrough: s.ingestedThrough, Votes: make([]Vote, 0, len(s.votes))}
+	for _, v := range s.votes {
+		data.Votes = append(data.Votes, v)
+	}
+	sortVotes(data.Votes)
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	tempPath := s.filePath + ".tmp"
+	if err := os.WriteFile(tempPath, payload, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, s.filePath)
+}
+
+// Restore substitui o conteúdo pelo arquivo publicado por outra instância
+// (a líder do agendador) e o grava no arquivo local
+func (s *Store) Restore(payload []byte) error {
+	fresh := &Store{votes: map[string]Vote{}}
+	if err := fresh.decode(bytes.NewReader(payload)); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.votes, s.lastIngest, s.ingestedThrough = fresh.votes, fresh.lastIngest, fresh.ingestedThrough
+	return s.saveLocked()
+}
+
+// Has informa se a votação já foi ingerida
+func (s *Store) Has(id string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	_, ok := s.votes[id]
+	return ok
+}
+
+// Get retorna uma votação com os votos nominais
+func (s *Store) Get(id string) (Vote, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	v, ok := s.votes[id]
+	return v, ok
+}
+
+// Upsert grava as votações e atualiza o horário da última ingestão. through
+// é o fim do período ingerido sem erros; zero (ou um horário anterior ao já
+// registrado) o mantém como está.
+func (s *Store) Upsert(items []Vote, through time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, v := range items {
+		s.votes[v.ID] = v
+	}
+	s.lastIngest = time.Now().UTC()
+	if through.After(s.ingestedThrough) {
+		s.ingestedThrough = through.UTC()
+	}
+	return s.saveLocked()
+}
+
+// IngestedThrough retorna o fim do período já ingerido sem erros
+func (s *Store) IngestedThrough() time.Time {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.ingestedThrough
+}
+
+// LastIngest retorna o horário da última ingestão
+func (s *Store) LastIngest() time.Time {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.lastIngest
+}
+
+// Size retorna o número de votações armazenadas
+func (s *Store) Size() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.votes)
+}
+
+// Filter restringe a consulta de votações. Datas no formato AAAA-MM-DD.
+type Filter struct {
+	Chamber      string
+	Topic        string
+	Stage        string
+	From         string
+	To           string
+	PoliticianID string
+	Limit        int
+	IncludeVotes bool
+}
+
+// NormalizeChamber aceita "camara", "Câmara", "senado" etc.
+func NormalizeChamber(chamber string) string {
+	switch textnorm.Fold(strings.TrimSpace(chamber)) {
+	case "camara":
+		return ChamberCamara
+	case "senado":
+		return ChamberSenado
+	}
+	return ""
+}
+
+// Query retorna as votações que atendem ao filtro, da mais recente para a mais antiga
+func (s *Store) Query(f Filter) []Vote {
+	result, _ := s.QueryTotal(f)
+	return result
+}
+
+// QueryTotal é Query que também informa quantas votações atendem ao filtro
+// antes de aplicar f.Limit
+func (s *Store) QueryTotal(f Filter) ([]Vote, int) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	topic := textnorm.Fold(f.Topic)
+	stage := textnorm.Fold(f.Stage)
+	result := []Vote{}
+
+	for _, v := range s.votes {
+		if f.Chamber != "" && v.Chamber != f.Chamber {
+			continue
+		}
+		if topic != "" && textnorm.Fold(v.Topic) != topic {
+			continue
+		}
+		if stage != "" && textnorm.Fold(v.Stage) != stage {
+			continue
+		}
+		if f.From != "" && v.Date < f.From {
+			continue
+		}
+		if f.To != "" && v.Date > f.To {
+			continue
+		}
+		if f.PoliticianID != "" && !hasVoter(v, f.PoliticianID) {
+			continue
+		}
+		if !f.IncludeVotes {
+			v.Votes = nil
+		}
+		result = append(result, v)
+	}
+
+	sortVotes(result)
+	total := len(result)
+	if f.Limit > 0 && len(result) > f.Limit {
+		result = result[:f.Limit]
+	}
+	return result, total
+}
+
+func hasVoter(v Vote, politicianID string) bool {
+	for _, nv := range v.Votes {
+		if nv.PoliticianID == politicianID {
+			return true
+		}
+	}
+	return false
+}
+
+func sortVotes(items []Vote) {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Date != items[j].Date {
+			return items[i].Date > items[j].Date
+		}
+		return items[i].ID < items[j].ID
+	})
+}
@@ -0,0 +1,189 @@
+package votes
+
+import (
+	"strings"
+	"time"
+
+	"chat-bot/internal/textnorm"
+)
+
+// Nomes das casas como exibidos no painel de votações
+const (
+	ChamberCamara = "Câmara"
+	ChamberSenado = "Senado"
+)
+
+// Etapas derivadas do resultado da votação
+const (
+	StageAprovado       = "Aprovado"
+	StageRejeitado      = "Rejeitado"
+	StageVetoDerrubado  = "Veto derrubado"
+	StageVetoMantido    = "Veto mantido"
+	StageSemResultado   = "Sem resultado"
+	TopicOutros         = "Outros"
+	governmentVoteLabel = "Governo"
+)
+
+// NominalVote é o voto de um parlamentar em uma votação
+type NominalVote struct {
+	PoliticianID string `json:"politicianId"`
+	Nome         string `json:"nome"`
+	Partido      string `json:"partido,omitempty"`
+	UF           string `json:"uf,omitempty"`
+	Voto         string `json:"voto"`
+}
+
+// Vote é uma votação nominal consolidada, no formato usado pelo painel de votações
+type Vote struct {
+	ID                    string        `json:"id"`
+	SourceID              string        `json:"sourceId"`
+	Title                 string        `json:"title"`
+	Chamber               string        `json:"chamber"`
+	Topic                 string        `json:"topic"`
+	Proposicao            string        `json:"proposicao,omitempty"`
+	Ementa                string        `json:"ementa,omitempty"`
+	GovernmentOrientation string        `json:"governmentOrientation,omitempty"`
+	GovernmentAlignment   *float64      `json:"governmentAlignment"`
+	Support               int           `json:"support"`
+	Against               int           `json:"against"`
+	Abstention            int           `json:"abstention"`
+	Other                 int           `json:"other"`
+	Stage                 string        `json:"stage"`
+	Date                  string        `json:"date"`
+	Highlight             string        `json:"highlight"`
+	Source                string        `json:"source"`
+	SourceURL             string        `json:"sourceUrl"`
+	Votes                 []NominalVote `json:"votes,omitempty"`
+	IngestedAt            time.Time     `json:"ingestedAt"`
+}
+
+// topicKeywords associa temas a palavras-chave (sem acentos) da ementa ou descrição.
+// As palavras casam com palavras inteiras do texto; "*" no fim aceita qualquer
+// terminação ("tribut*" casa com "tributario", mas "arma" não casa com
+// "armazenamento"). A ordem importa: o primeiro tema com correspondência é usado.
+var topicKeywords = []struct {
+	Topic    string
+	Keywords []string
+}{
+	{"Reforma Tributária", []string{"reforma tributaria", "ibs", "cbs", "imposto seletivo", "tribut*"}},
+	{"Orçamento", []string{"orcament*", "ldo", "loa", "credito suplementar", "credito especial", "plano plurianual"}},
+	{"Economia", []string{"desoneracao", "fiscal", "fiscais", "juros", "divida", "dividas", "economi*", "financ*", "arcabouco"}},
+	{"Previdência e Trabalho", []string{"previdenc*", "aposentadoria*", "trabalh*", "salario*", "salarial", "emprego*", "clt"}},
+	{"Saúde", []string{"saude", "sus", "medicamento*", "medico*", "medica", "medicas", "medicina", "hospital", "hospitais", "hospitalar*", "vacina*"}},
+	{"Educação", []string{"educac*", "ensino", "escola*", "universidade*", "universitari*", "professor*"}},
+	{"Segurança Pública", []string{"seguranca publica", "penal", "penais", "crime*", "criminal", "policia*", "arma", "armas", "presidio*"}},
+	{"Meio Ambiente", []string{"ambient*", "clima", "climatic*", "desmatamento", "floresta*", "licenciamento", "carbono"}},
+	{"Inovação", []string{"inteligencia artificial", "tecnologia*", "tecnologic*", "digital", "digitais", "internet", "dados pessoais", "inovac*"}},
+	{"Infraestrutura", []string{"infraestrutura", "rodovia*", "ferrovia*", "saneamento", "energia", "energetic*", "porto", "portos", "portuari*"}},
+	{"Direitos e Cidadania", []string{"direitos", "mulher", "mulheres", "crianca*", "idoso*", "deficiencia", "indigena*"}},
+}
+
+// ClassifyTopic associa um tema à votação a partir do texto informado
+func ClassifyTopic(texts ...string) string {
+	tokens := textnorm.Tokens(strings.Join(texts, " "))
+	for _, entry := range topicKeywords {
+		for _, keyword := range entry.Keywords {
+			if containsKeyword(tokens, strings.Fields(keyword)) {
+				return entry.Topic
+			}
+		}
+	}
+	return TopicOutros
+}
+
+// containsKeyword procura as palavras da palavra-chave em sequência nos tokens
+func containsKeyword(tokens, words []string) bool {
+	for i := 0; i+len(words) <= len(tokens); i++ {
+		matched := true
+		for j, word := range words {
+			if !matchWord(tokens[i+j], word) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func matchWord(token, word string) bool {
+	if prefix, ok := strings.CutSuffix(word, "*"); ok {
+		return strings.HasPrefix(token, prefix)
+	}
+	return token == word
+}
+
+// Topics lista os temas conhecidos, incluindo "Outros"
+func Topics() []string {
+	topics := make([]string, 0, len(topicKeywords)+1)
+	for _, entry := range topicKeywords {
+		topics = append(topics, entry.Topic)
+	}
+	return append(topics, TopicOutros)
+}
+
+// deriveStage interpreta o resultado e a descrição da votação
+func deriveStage(approved *bool, description string) string {
+	folded := textnorm.Fold(description)
+	if strings.Contains(folded, "veto") {
+		if approved == nil {
+			return StageSemResultado
+		}
+		// Em vetos, "aprovado" significa que o dispositivo vetado foi mantido no texto
+		if *approved {
+			return StageVetoDerrubado
+		}
+		return StageVetoMantido
+	}
+	if approved == nil {
+		return StageSemResultado
+	}
+	if *approved {
+		return StageAprovado
+	}
+	return StageRejeitado
+}
+
+// countVote acumula um voto nas categorias do painel
+func countVote(v *Vote, voto string) {
+	switch textnorm.Fold(strings.TrimSpace(voto)) {
+	case "sim":
+		v.Support++
+	case "nao":
+		v.Against++
+	case "abstencao":
+		v.Abstention++
+	default:
+		v.Other++
+	}
+}
+
+// computeAlignment calcula a parcela de votos Sim/Não que seguiu a orientação do governo
+func computeAlignment(v *Vote) {
+	orientation := textnorm.Fold(v.GovernmentOrientation)
+	if orientation != "sim" && orientation != "nao" {
+		v.GovernmentAlignment = nil
+		return
+	}
+
+	var comparable, aligned int
+	for _, nv := range v.Votes {
+		voto := textnorm.Fold(nv.Voto)
+		if voto != "sim" && voto != "nao" {
+			continue
+		}
+		comparable++
+		if voto == orientation {
+			aligned++
+		}
+	}
+	if comparable == 0 {
+		v.GovernmentAlignment = nil
+		return
+	}
+
+	alignment := float64(int(float64(aligned)/float64(comparable)*1000)) / 1000
+	v.GovernmentAlignment = &alignment
+}
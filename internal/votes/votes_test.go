@@ -0,0 +1,72 @@
+package votes
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClassifyTopic(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Institui o Imposto Seletivo e regulamenta o IBS", "Reforma Tributária"},
+		{"Altera a legislação tributária federal", "Reforma Tributária"},
+		{"Amplia o atendimento do SUS em hospitais regionais", "Saúde"},
+		{"Dispõe sobre a suspensão de prazos processuais", TopicOutros},
+		{"Regulamenta o armazenamento de grãos", TopicOutros},
+		{"Aumenta a penalidade por atraso na entrega de declarações", TopicOutros},
+		{"Altera o Código Penal para tipificar novo crime", "Segurança Pública"},
+		{"Restringe o porte de armas de fogo", "Segurança Pública"},
+		{"Dispõe sobre a medição individualizada de água", TopicOutros},
+		{"Aprova a Lei de Diretrizes Orçamentárias", "Orçamento"},
+		{"Regula o uso de inteligência artificial", "Inovação"},
+		{"Requerimento de urgência", TopicOutros},
+	}
+	for _, tt := range tests {
+		if got := ClassifyTopic(tt.text); got != tt.want {
+			t.Errorf("ClassifyTopic(%q) = %q, esperava %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestQueryTotalCountsBeforeLimit(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "votes.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := []Vote{
+		{ID: "a", Chamber: ChamberCamara, Date: "2026-10-01"},
+		{ID: "b", Chamber: ChamberCamara, Date: "2026-10-03"},
+		{ID: "c", Chamber: ChamberCamara, Date: "2026-10-02"},
+		{ID: "d", Chamber: ChamberSenado, Date: "2026-10-04"},
+	}
+	if err := store.Upsert(items, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, total := store.QueryTotal(Filter{Chamber: ChamberCamara, Limit: 2})
+	if total != 3 {
+		t.Errorf("total = %d, esperava 3", total)
+	}
+	if len(result) != 2 || result[0].ID != "b" || result[1].ID != "c" {
+		t.Errorf("página inesperada: %+v", result)
+	}
+}
+
+func TestComputeAlignment(t *testing.T) {
+	v := Vote{GovernmentOrientation: "Sim", Votes: []NominalVote{
+		{Voto: "Sim"}, {Voto: "Sim"}, {Voto: "Não"}, {Voto: "Abstenção"},
+	}}
+	computeAlignment(&v)
+	if v.GovernmentAlignment == nil || *v.GovernmentAlignment != 0.666 {
+		t.Errorf("alinhamento inesperado: %v", v.GovernmentAlignment)
+	}
+
+	v.GovernmentOrientation = "Liberado"
+	computeAlignment(&v)
+	if v.GovernmentAlignment != nil {
+		t.Errorf("sem orientação Sim/Não o alinhamento deveria ser nulo")
+	}
+}
@@ -0,0 +1,197 @@
+package watchlist
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"chat-bot/internal/dou"
+	"chat-bot/internal/proposicoes"
+)
+
+// Eventos enviados aos canais
+const (
+	EventTramitacao = "tramitacao"
+	EventDOU        = "dou"
+)
+
+// maxActAlertsPerRun limita os alertas do DOU por lista em cada importação
+const maxActAlertsPerRun = 20
+
+// Notifier entrega uma notificação por um canal
+type Notifier interface {
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// Dispatcher transforma mudanças de tramitação em alertas para as listas que
+// acompanham cada proposição e os entrega pelos canais configurados.
+type Dispatcher struct {
+	Store   *Store
+	Webhook Notifier
+	Email   Notifier
+	// Summarize gera o resumo em linguagem simples; sem ele (ou se falhar) é
+	// usado o resumo descritivo de FallbackSummary.
+	Summarize func(ctx context.Context, change proposicoes.Change) (string, error)
+}
+
+// Process gera e entrega os alertas; retorna quantos alertas foram criados
+func (d *Dispatcher) Process(ctx context.Context, changes []proposicoes.Change) (int, error) {
+	var alerts []Alert
+	for _, change := range changes {
+		watchers := d.Store.Watchers(change.ProposicaoID)
+		if len(watchers) == 0 {
+			continue
+		}
+
+		// O resumo é gerado uma vez por mudança e compartilhado entre as listas
+		summary := FallbackSummary(change)
+		if d.Summarize != nil {
+			if generated, err := d.Summarize(ctx, change); err != nil {
+				log.Printf("⚠️  [LISTAS] resumo indisponível para %s: %v", change.Label, err)
+			} else if strings.TrimSpace(generated) != "" {
+				summary = strings.TrimSpace(generated)
+			}
+		}
+
+		for _, w := range watchers {
+			id := alertID(w.ID, EventTramitacao, change.ProposicaoID, strconv.Itoa(lastSequencia(change)), change.SituacaoAtual)
+			if d.Store.HasAlert(id) {
+				continue
+			}
+			alert := Alert{
+				ID:          id,
+				WatchlistID: w.ID,
+				Summary:     summary,
+				Change:      &change,
+				CreatedAt:   time.Now().UTC(),
+			}
+			alerts = append(alerts, d.send(ctx, EventTramitacao, w, alert))
+		}
+	}
+	return d.save(alerts)
+}
+
+// ProcessActs gera alertas para as listas cujas palavras-chave aparecem nos
+// atos recém-publicados no DOU; retorna quantos alertas foram criados
+func (d *Dispatcher) ProcessActs(ctx context.Context, acts []dou.Act) (int, error) {
+	var alerts []Alert
+	for _, w := range d.Store.KeywordWatchers() {
+		sent := 0
+		for _, act := range acts {
+			keywords := act.MatchingKeywords(w.DOUKeywords)
+			if len(keywords) == 0 {
+				continue
+			}
+			id := alertID(w.ID, EventDOU, act.ID)
+			if d.Store.HasAlert(id) {
+				continue
+			}
+			// Uma palavra-chave muito genérica não deve inundar a lista
+			if sent == maxActAlertsPerRun {
+				log.Printf("⚠️  [LISTAS] lista %s atingiu o limite de %d alertas do DOU nesta execução", w.ID, maxActAlertsPerRun)
+				break
+			}
+			alert := Alert{
+				ID:          id,
+				WatchlistID: w.ID,
+				Summary:     ActSummary(act, keywords),
+				Ato:         &act,
+				Keywords:    keywords,
+				CreatedAt:   time.Now().UTC(),
+			}
+			alerts = append(alerts, d.send(ctx, EventDOU, w, alert))
+			sent++
+		}
+	}
+	return d.save(alerts)
+}
+
+// alertID identifica o alerta pelo que o gerou, para que uma nova tentativa
+// depois de uma falha não notifique a lista duas vezes pelo mesmo motivo
+func alertID(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "|")))
+	return hex.EncodeToString(sum[:8])
+}
+
+func lastSequencia(change proposicoes.Change) int {
+	last := 0
+	for _, t := range change.Novas {
+		if t.Sequencia > last {
+			last = t.Sequencia
+		}
+	}
+	return last
+}
+
+// send entrega o alerta pelos canais da lista e registra o resultado de cada entrega
+func (d *Dispatcher) send(ctx context.Context, event string, w Watchlist, alert Alert) Alert {
+	notification := Notification{Event: event, Watchlist: w, Alert: alert}
+	if w.WebhookURL != "" {
+		alert.Deliveries = append(alert.Deliveries, d.deliver(ctx, ChannelWebhook, d.Webhook, notification))
+	}
+	if w.Email != "" {
+		if w.EmailConfirmed {
+			alert.Deliveries = append(alert.Deliveries, d.deliver(ctx, ChannelEmail, d.Email, notification))
+		} else {
+			alert.Deliveries = append(alert.Deliveries, Delivery{Channel: ChannelEmail, Error: "e-mail aguardando confirmação"})
+		}
+	}
+	return alert
+}
+
+func (d *Dispatcher) save(alerts []Alert) (int, error) {
+	if err := d.Store.AddAlerts(alerts); err != nil {
+		return 0, err
+	}
+	if len(alerts) > 0 {
+		log.Printf("[LISTAS] %d alertas gerados", len(alerts))
+	}
+	return len(alerts), nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, channel string, notifier Notifier, notification Notification) Delivery {
+	delivery := Delivery{Channel: channel}
+	if notifier == nil {
+		delivery.Error = "canal não configurado no servidor"
+		return delivery
+	}
+	if err := notifier.Notify(ctx, notification); err != nil {
+		log.Printf("⚠️  [LISTAS] falha ao entregar %s da lista %s: %v", channel, notification.Watchlist.ID, err)
+		delivery.Error = err.Error()
+		return delivery
+	}
+	now := time.Now().UTC()
+	delivery.DeliveredAt = &now
+	return delivery
+}
+
+// ActSummary descreve o ato do DOU encontrado pelas palavras-chave
+func ActSummary(act dou.Act, keywords []string) string {
+	return fmt.Sprintf("%s (%s, %s, %s) menciona %s.", act.Title(), act.Orgao, act.Secao, act.Data, strings.Join(keywords, ", "))
+}
+
+// FallbackSummary descreve a mudança sem depender do Gemini
+func FallbackSummary(change proposicoes.Change) string {
+	var parts []string
+	switch len(change.Novas) {
+	case 0:
+	case 1:
+		t := change.Novas[0]
+		parts = append(parts, fmt.Sprintf("%s teve uma nova movimentação: %s", change.Label, strings.TrimSuffix(t.Descricao, ".")))
+	default:
+		last := change.Novas[len(change.Novas)-1]
+		parts = append(parts, fmt.Sprintf("%s teve %d novas movimentações; a mais recente: %s", change.Label, len(change.Novas), strings.TrimSuffix(last.Descricao, ".")))
+	}
+	if change.SituacaoMudou() {
+		if len(parts) == 0 {
+			parts = append(parts, fmt.Sprintf("%s mudou de situação", change.Label))
+		}
+		parts = append(parts, fmt.Sprintf("situação atual: %s", change.SituacaoAtual))
+	}
+	return strings.Join(parts, "; ") + "."
+}
@@ -0,0 +1,200 @@
+package watchlist
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Canais de entrega
+const (
+	ChannelWebhook = "webhook"
+	ChannelEmail   = "email"
+)
+
+// SignatureHeader carrega a assinatura HMAC-SHA256 do corpo do webhook
+const SignatureHeader = "X-PoliticianInsight-Signature"
+
+// Notification é o conteúdo entregue aos canais de uma lista
+type Notification struct {
+	Event     string    `json:"event"`
+	Watchlist Watchlist `json:"watchlist"`
+	Alert     Alert     `json:"alert"`
+}
+
+// WebhookNotifier envia o alerta como JSON via POST para a URL da lista
+type WebhookNotifier struct {
+	Client *http.Client
+	// Secret assina o corpo da requisição quando configurado
+	Secret string
+}
+
+// NewWebhookNotifier cria o notificador com timeout padrão; o cliente recusa
+// conexões a endereços locais ou privados
+func NewWebhookNotifier(secret string) *WebhookNotifier {
+	return &WebhookNotifier{Client: newWebhookClient(), Secret: secret}
+}
+
+// Sign calcula a assinatura enviada no cabeçalho SignatureHeader
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, notification Notification) error {
+	target := notification.target(ChannelWebhook)
+	// O destinatário não precisa dos endereços de entrega
+	notification.Watchlist.WebhookURL = ""
+	notification.Watchlist.Email = ""
+
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "PoliticianInsight-Watchlist/1.0")
+	if n.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(n.Secret, body))
+	}
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook respondeu com status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SMTPNotifier envia o alerta por e-mail. Sem usuário, envia sem autenticação
+// (útil com servidores locais de teste como MailHog).
+type SMTPNotifier struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (n *SMTPNotifier) Notify(ctx context.Context, notification Notification) error {
+	return n.send(ctx, notification.target(ChannelEmail), notification.subject(), EmailBody(notification))
+}
+
+// SendConfirmation pede ao destinatário que confirme o endereço antes de
+// receber os alertas da lista
+func (n *SMTPNotifier) SendConfirmation(ctx context.Context, w Watchlist, link string) error {
+	body := fmt.Sprintf("Este endereço foi cadastrado para receber os alertas da lista de acompanhamento \"%s\".\n\n"+
+		"Para confirmar, abra: %s\n\n"+
+		"Se você não fez esse cadastro, ignore esta mensagem: nenhum alerta será enviado sem a confirmação.\n", w.Name, link)
+	return n.send(ctx, w.Email, "Confirme o e-mail da lista "+w.Name, body)
+}
+
+func (n *SMTPNotifier) send(ctx context.Context, to, subject, body string) error {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", to)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if n.Username != "" {
+		auth = smtp.PlainAuth("", n.Username, n.Password, n.Host)
+	}
+
+	// smtp.SendMail não aceita contexto; respeitamos o prazo verificando antes do envio
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return smtp.SendMail(net.JoinHostPort(n.Host, n.Port), auth, n.From, []string{to}, msg.Bytes())
+}
+
+func (n Notification) subject() string {
+	if ato := n.Alert.Ato; ato != nil {
+		return fmt.Sprintf("DOU: %s", ato.Title())
+	}
+	if change := n.Alert.Change; change != nil {
+		return fmt.Sprintf("%s se movimentou", change.Label)
+	}
+	return "Alerta da lista " + n.Watchlist.Name
+}
+
+// EmailBody monta o texto do e-mail de alerta
+func EmailBody(notification Notification) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Lista: %s\n\n", notification.Watchlist.Name)
+	fmt.Fprintf(&b, "%s\n\n", notification.Alert.Summary)
+
+	if ato := notification.Alert.Ato; ato != nil {
+		if ato.Ementa != "" {
+			fmt.Fprintf(&b, "Ementa: %s\n\n", ato.Ementa)
+		}
+		fmt.Fprintf(&b, "%s\n\n", ato.Excerpt(1200))
+		if ato.URL != "" {
+			fmt.Fprintf(&b, "Leia no DOU: %s\n", ato.URL)
+		}
+		return b.String()
+	}
+
+	change := notification.Alert.Change
+	if change == nil {
+		return b.String()
+	}
+	if change.SituacaoMudou() {
+		fmt.Fprintf(&b, "Situação: %s → %s\n\n", valueOr(change.SituacaoAnterior, "não informada"), change.SituacaoAtual)
+	}
+	if len(change.Novas) > 0 {
+		b.WriteString("Movimentações:\n")
+		for _, t := range change.Novas {
+			fmt.Fprintf(&b, "- %s %s: %s\n", firstN(t.Data, 10), t.Orgao, t.Descricao)
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "Acompanhe: %s\n", change.URL)
+	return b.String()
+}
+
+func (n Notification) target(channel string) string {
+	if channel == ChannelEmail {
+		return n.Watchlist.Email
+	}
+	return n.Watchlist.WebhookURL
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+func firstN(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
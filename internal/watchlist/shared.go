@@ -0,0 +1,264 @@
+package watchlist
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"reflect"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+// sharedWriteTimeout limita cada gravação no armazenamento compartilhado
+const sharedWriteTimeout = 30 * time.Second
+
+// Shared guarda listas e alertas fora da instância. As listas podem ser
+// criadas em qualquer instância, mas só a líder do agendador gera os alertas;
+// cada instância lê daqui o que as outras gravaram.
+type Shared interface {
+	// PutWatchlist grava a lista; com deleted, registra a remoção
+	PutWatchlist(ctx context.Context, w Watchlist, deleted bool) error
+	// PutAlerts grava os alertas gerados
+	PutAlerts(ctx context.Context, alerts []Alert) error
+	// Changes retorna as listas e alertas gravados a partir de since, os IDs
+	// das listas removidas e o cursor para a próxima leitura
+	Changes(ctx context.Context, since time.Time) (SharedChanges, error)
+}
+
+// SharedChanges é o resultado de uma leitura do armazenamento compartilhado
+type SharedChanges struct {
+	Watchlists []Watchlist
+	Deleted    []string
+	Alerts     []Alert
+	Cursor     time.Time
+}
+
+// FirestoreShared guarda uma lista por documento em <coleção> e um alerta por
+// documento em <coleção>_alertas, com o JSON completo e o horário de gravação
+// (do servidor) usado como cursor pelas outras instâncias
+type FirestoreShared struct {
+	client     *firestore.Client
+	collection string
+}
+
+type sharedDoc struct {
+	Payload   string    `firestore:"payload"`
+	Deleted   bool      `firestore:"deleted"`
+	UpdatedAt time.Time `firestore:"updatedAt,serverTimestamp"`
+}
+
+// NewFirestoreShared usa a coleção informada
+func NewFirestoreShared(client *firestore.Client, collection string) *FirestoreShared {
+	return &FirestoreShared{client: client, collection: collection}
+}
+
+func (f *FirestoreShared) alerts() *firestore.CollectionRef {
+	return f.client.Collection(f.collection + "_alertas")
+}
+
+func (f *FirestoreShared) PutWatchlist(ctx context.Context, w Watchlist, deleted bool) error {
+	payload, err := json.Marshal(w)
+	if err != nil {
+		return err
+	}
+	_, err = f.client.Collection(f.collection).Doc(w.ID).Set(ctx, sharedDoc{Payload: string(payload), Deleted: deleted})
+	return err
+}
+
+func (f *FirestoreShared) PutAlerts(ctx context.Context, alerts []Alert) error {
+	writer := f.client.BulkWriter(ctx)
+	jobs := make([]*firestore.BulkWriterJob, 0, len(alerts))
+	for _, a := range alerts {
+		payload, err := json.Marshal(a)
+		if err != nil {
+			writer.End()
+			return err
+		}
+		job, err := writer.Set(f.alerts().Doc(a.ID), sharedDoc{Payload: string(payload)})
+		if err != nil {
+			writer.End()
+			return err
+		}
+		jobs = append(jobs, job)
+	}
+	writer.End()
+
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *FirestoreShared) Changes(ctx context.Context, since time.Time) (SharedChanges, error) {
+	changes := SharedChanges{Cursor: since}
+
+	err := f.read(ctx, f.client.Collection(f.collection), since, &changes.Cursor, func(data sharedDoc) error {
+		var w Watchlist
+		if err := json.Unmarshal([]byte(data.Payload), &w); err != nil {
+			return err
+		}
+		if data.Deleted {
+			changes.Deleted = append(changes.Deleted, w.ID)
+		} else {
+			changes.Watchlists = append(changes.Watchlists, w)
+		}
+		return nil
+	})
+	if err != nil {
+		return SharedChanges{Cursor: since}, err
+	}
+
+	// Alertas além da retenção já teriam sido descartados
+	alertsSince := since
+	if cutoff := time.Now().Add(-alertRetention); alertsSince.Before(cutoff) {
+		alertsSince = cutoff
+	}
+	err = f.read(ctx, f.alerts(), alertsSince, &changes.Cursor, func(data sharedDoc) error {
+		var a Alert
+		if err := json.Unmarshal([]byte(data.Payload), &a); err != nil {
+			return err
+		}
+		changes.Alerts = append(changes.Alerts, a)
+		return nil
+	})
+	if err != nil {
+		return SharedChanges{Cursor: since}, err
+	}
+	return changes, nil
+}
+
+func (f *FirestoreShared) read(ctx context.Context, collection *firestore.CollectionRef, since time.Time, cursor *time.Time, apply func(sharedDoc) error) error {
+	// >= porque gravações diferentes podem ter o mesmo horário; reaplicar é inofensivo
+	iter := collection.Where("updatedAt", ">=", since).OrderBy("updatedAt", firestore.Asc).Documents(ctx)
+	defer iter.Stop()
+
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var data sharedDoc
+		if err := doc.DataTo(&data); err != nil {
+			return err
+		}
+		if err := apply(data); err != nil {
+			log.Printf("⚠️  [LISTAS] documento compartilhado %s ignorado: %v", doc.Ref.Path, err)
+			continue
+		}
+		if data.UpdatedAt.After(*cursor) {
+			*cursor = data.UpdatedAt
+		}
+	}
+}
+
+// SetShared liga o armazenamento compartilhado; as alterações passam a ser
+// publicadas nele e Follow passa a trazer as feitas por outras instâncias
+func (s *Store) SetShared(shared Shared) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.shared = shared
+}
+
+func (s *Store) sharedStore() Shared {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.shared
+}
+
+// publishWatchlist grava a lista no armazenamento compartilhado, se houver.
+// Deve ser chamado sem a trava: a gravação é uma chamada de rede.
+func (s *Store) publishWatchlist(w Watchlist, deleted bool) error {
+	shared := s.sharedStore()
+	if shared == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sharedWriteTimeout)
+	defer cancel()
+	return shared.PutWatchlist(ctx, w, deleted)
+}
+
+func (s *Store) publishAlerts(alerts []Alert) error {
+	shared := s.sharedStore()
+	if shared == nil || len(alerts) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sharedWriteTimeout)
+	defer cancel()
+	return shared.PutAlerts(ctx, alerts)
+}
+
+// Refresh aplica as listas e alertas gravados por outras instâncias desde a
+// última leitura e retorna quantos itens mudaram
+func (s *Store) Refresh(ctx context.Context) (int, error) {
+	s.mutex.RLock()
+	shared, since := s.shared, s.sharedCursor
+	s.mutex.RUnlock()
+	if shared == nil {
+		return 0, nil
+	}
+
+	changes, err := shared.Changes(ctx, since)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	changed := 0
+	for _, w := range changes.Watchlists {
+		if current, ok := s.watchlists[w.ID]; ok && reflect.DeepEqual(current, w) {
+			continue
+		}
+		s.watchlists[w.ID] = w
+		changed++
+	}
+	for _, id := range changes.Deleted {
+		if _, ok := s.watchlists[id]; ok {
+			s.removeLocked(id)
+			changed++
+		}
+	}
+	// Alertas de listas já removidas não voltam
+	alerts := make([]Alert, 0, len(changes.Alerts))
+	for _, a := range changes.Alerts {
+		if _, ok := s.watchlists[a.WatchlistID]; ok {
+			alerts = append(alerts, a)
+		}
+	}
+	changed += s.addAlertsLocked(alerts)
+	s.sharedCursor = changes.Cursor
+	if changed == 0 {
+		return 0, nil
+	}
+	return changed, s.saveLocked()
+}
+
+// Follow executa Refresh periodicamente até o contexto ser cancelado
+func (s *Store) Follow(ctx context.Context, interval time.Duration) {
+	refresh := func() {
+		if n, err := s.Refresh(ctx); err != nil {
+			log.Printf("⚠️  [LISTAS] falha ao ler o armazenamento compartilhado: %v", err)
+		} else if n > 0 {
+			log.Printf("[LISTAS] %d listas e alertas atualizados a partir do armazenamento compartilhado", n)
+		}
+	}
+	refresh()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			refresh()
+		}
+	}
+}
@@ -0,0 +1,452 @@
+// Package watchlist guarda as listas de acompanhamento de proposições e os
+// alertas gerados quando uma proposição acompanhada se movimenta.
+package watchlist
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"chat-bot/internal/dou"
+	"chat-bot/internal/proposicoes"
+)
+
+const (
+	// MaxBills limita as proposições de uma lista
+	MaxBills = 20
+	// MaxPerOwner limita as listas de um mesmo dono
+	MaxPerOwner = 20
+	// MaxKeywords limita as palavras-chave do DOU de uma lista
+	MaxKeywords = 10
+	// alertRetention define por quanto tempo os alertas são guardados
+	alertRetention = 90 * 24 * time.Hour
+)
+
+var (
+	// ErrNotFound indica lista inexistente ou de outro dono
+	ErrNotFound = errors.New("lista de acompanhamento não encontrada")
+	// ErrLimitReached indica que o dono já tem o máximo de listas
+	ErrLimitReached = errors.New("limite de listas de acompanhamento atingido")
+	// ErrInvalidConfirmation indica token de confirmação de e-mail inválido ou já usado
+	ErrInvalidConfirmation = errors.New("link de confirmação inválido ou já utilizado")
+)
+
+// Bill é uma proposição acompanhada
+type Bill struct {
+	ProposicaoID string `json:"proposicaoId"`
+	Label        string `json:"label"`
+	Casa         string `json:"casa"`
+	URL          string `json:"url"`
+}
+
+// Watchlist é uma lista de proposições e palavras-chave do DOU acompanhadas
+// por um usuário ou sessão
+type Watchlist struct {
+	ID    string `json:"id"`
+	Owner string `json:"owner"`
+	Name  string `json:"name"`
+	Bills []Bill `json:"bills"`
+	// DOUKeywords geram alertas quando um ato publicado no DOU contém alguma delas
+	DOUKeywords []string `json:"douKeywords,omitempty"`
+	WebhookURL  string   `json:"webhookUrl,omitempty"`
+	Email       string   `json:"email,omitempty"`
+	// EmailConfirmed só fica verdadeiro quando o destinatário abre o link de
+	// confirmação; até lá nenhum alerta é enviado ao e-mail
+	EmailConfirmed bool `json:"emailConfirmed"`
+	// EmailTokenHash é o SHA-256 do token de confirmação; não sai pela API
+	EmailTokenHash string    `json:"emailTokenHash,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// Delivery registra a entrega de um alerta por um canal
+type Delivery struct {
+	Channel     string     `json:"channel"`
+	DeliveredAt *time.Time `json:"deliveredAt,omitempty"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// Alert é uma movimentação de proposição ou um ato do DOU notificado a uma lista
+type Alert struct {
+	ID          string              `json:"id"`
+	WatchlistID string              `json:"watchlistId"`
+	Summary     string              `json:"summary"`
+	Change      *proposicoes.Change `json:"change,omitempty"`
+	Ato         *dou.Act            `json:"ato,omitempty"`
+	// Keywords são as palavras-chave da lista encontradas no ato
+	Keywords   []string   `json:"keywords,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	Deliveries []Delivery `json:"deliveries,omitempty"`
+}
+
+// Store guarda listas e alertas em um arquivo JSON local. Com um
+// armazenamento compartilhado (SetShared), as alterações também são gravadas
+// nele, e Follow traz as feitas pelas outras instâncias.
+type Store struct {
+	filePath   string
+	watchlists map[string]Watchlist
+	alerts     []Alert
+	shared     Shared
+	// sharedCursor é o horário da última gravação lida do armazenamento compartilhado
+	sharedCursor time.Time
+	mutex        sync.RWMutex
+}
+
+type storeFile struct {
+	Watchlists []Watchlist `json:"watchlists"`
+	Alerts     []Alert     `json:"alerts"`
+}
+
+// NewStore cria o armazenamento e carrega o arquivo local, se existir
+func NewStore(filePath string) (*Store, error) {
+	s := &Store{filePath: filePath, watchlists: map[string]Watchlist{}}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Store) load() error {
+	file, err := os.Open(s.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+
+	var data storeFile
+	if err := json.NewDecoder(file).Decode(&data); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return err
+	}
+
+	for _, w := range data.Watchlists {
+		s.watchlists[w.ID] = w
+	}
+	s.alerts = data.Alerts
+	return nil
+}
+
+func (s *Store) saveLocked() error {
+	if dir := filepath.Dir(s.filePath); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	data := storeFile{Watchlists: s.sortedLocked(""), Alerts: s.alerts}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	tempPath := s.filePath + ".tmp"
+	if err := os.WriteFile(tempPath, payload, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tempPath, s.filePath)
+}
+
+func (s *Store) sortedLocked(owner string) []Watchlist {
+	items := make([]Watchlist, 0, len(s.watchlists))
+	for _, w := range s.watchlists {
+		if owner == "" || w.Owner == owner {
+			w.EmailTokenHash = ""
+			items = append(items, w)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].CreatedAt.Equal(items[j].CreatedAt) {
+			return items[i].CreatedAt.Before(items[j].CreatedAt)
+		}
+		return items[i].ID < items[j].ID
+	})
+	return items
+}
+
+func newID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return time.Now().UTC().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(buf)
+}
+
+// Create grava uma nova lista e preenche ID e data de criação
+func (s *Store) Create(w Watchlist) (Watchlist, error) {
+	s.mutex.RLock()
+	count := len(s.sortedLocked(w.Owner))
+	s.mutex.RUnlock()
+	if count >= MaxPerOwner {
+		return Watchlist{}, ErrLimitReached
+	}
+
+	w.ID = newID()
+	w.CreatedAt = time.Now().UTC()
+	// Publicada antes de gravar aqui: se falhar, nenhuma instância fica com ela
+	if err := s.publishWatchlist(w, false); err != nil {
+		return Watchlist{}, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.watchlists[w.ID] = w
+	return w, s.saveLocked()
+}
+
+// List retorna as listas do dono, da mais antiga para a mais recente
+func (s *Store) List(owner string) []Watchlist {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.sortedLocked(owner)
+}
+
+// Get retorna a lista se ela pertencer ao dono
+func (s *Store) Get(id, owner string) (Watchlist, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	w, ok := s.watchlists[id]
+	if !ok || w.Owner != owner {
+		return Watchlist{}, ErrNotFound
+	}
+	w.EmailTokenHash = ""
+	return w, nil
+}
+
+// NewEmailToken gera o token enviado no link de confirmação e o hash guardado na lista
+func NewEmailToken() (token, hash string) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	token = hex.EncodeToString(buf)
+	return token, hashToken(token)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// EmailConfirmedBy informa se o dono já confirmou o endereço em outra lista
+func (s *Store) EmailConfirmedBy(owner, email string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for _, w := range s.watchlists {
+		if w.Owner == owner && w.EmailConfirmed && strings.EqualFold(w.Email, email) {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfirmEmail marca o e-mail da lista como confirmado se o token conferir
+func (s *Store) ConfirmEmail(id, token string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	w, ok := s.watchlists[id]
+	if !ok || w.EmailTokenHash == "" || subtle.ConstantTimeCompare([]byte(w.EmailTokenHash), []byte(hashToken(token))) != 1 {
+		return ErrInvalidConfirmation
+	}
+	w.EmailConfirmed = true
+	w.EmailTokenHash = ""
+	if err := s.publishUnlocked(w, false); err != nil {
+		return err
+	}
+	s.watchlists[id] = w
+	return s.saveLocked()
+}
+
+// publishUnlocked publica a lista soltando a trava durante a chamada de rede.
+// Deve ser chamado com a trava de escrita.
+func (s *Store) publishUnlocked(w Watchlist, deleted bool) error {
+	s.mutex.Unlock()
+	defer s.mutex.Lock()
+	return s.publishWatchlist(w, deleted)
+}
+
+// Delete remove a lista e seus alertas
+func (s *Store) Delete(id, owner string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	w, ok := s.watchlists[id]
+	if !ok || w.Owner != owner {
+		return ErrNotFound
+	}
+	if err := s.publishUnlocked(w, true); err != nil {
+		return err
+	}
+	s.removeLocked(id)
+	return s.saveLocked()
+}
+
+// removeLocked remove a lista e seus alertas da memória
+func (s *Store) removeLocked(id string) {
+	delete(s.watchlists, id)
+
+	kept := s.alerts[:0]
+	for _, a := range s.alerts {
+		if a.WatchlistID != id {
+			kept = append(kept, a)
+		}
+	}
+	s.alerts = kept
+}
+
+// WatchedBills retorna os IDs de todas as proposições acompanhadas
+func (s *Store) WatchedBills() map[string]bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	watched := map[string]bool{}
+	for _, w := range s.watchlists {
+		for _, b := range w.Bills {
+			watched[b.ProposicaoID] = true
+		}
+	}
+	return watched
+}
+
+// Watchers retorna as listas que acompanham a proposição
+func (s *Store) Watchers(proposicaoID string) []Watchlist {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var watchers []Watchlist
+	for _, w := range s.sortedLocked("") {
+		for _, b := range w.Bills {
+			if b.ProposicaoID == proposicaoID {
+				watchers = append(watchers, w)
+				break
+			}
+		}
+	}
+	return watchers
+}
+
+// KeywordWatchers retorna as listas com palavras-chave do DOU
+func (s *Store) KeywordWatchers() []Watchlist {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var watchers []Watchlist
+	for _, w := range s.sortedLocked("") {
+		if len(w.DOUKeywords) > 0 {
+			watchers = append(watchers, w)
+		}
+	}
+	return watchers
+}
+
+// AddAlerts grava os alertas e descarta os mais antigos que a retenção. Os
+// alertas são gravados aqui antes de publicados, para que uma falha na
+// publicação não faça a próxima tentativa notificar a lista de novo.
+func (s *Store) AddAlerts(alerts []Alert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+	for i := range alerts {
+		if alerts[i].ID == "" {
+			alerts[i].ID = newID()
+		}
+	}
+
+	s.mutex.Lock()
+	s.addAlertsLocked(alerts)
+	err := s.saveLocked()
+	s.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+	return s.publishAlerts(alerts)
+}
+
+// addAlertsLocked acrescenta os alertas ainda não registrados, descarta os
+// mais antigos que a retenção e retorna quantos foram acrescentados
+func (s *Store) addAlertsLocked(alerts []Alert) int {
+	existing := make(map[string]bool, len(s.alerts))
+	for _, a := range s.alerts {
+		existing[a.ID] = true
+	}
+	added := 0
+	for _, a := range alerts {
+		// Uma nova tentativa da mesma importação gera os mesmos IDs
+		if existing[a.ID] {
+			continue
+		}
+		existing[a.ID] = true
+		s.alerts = append(s.alerts, a)
+		added++
+	}
+
+	cutoff := time.Now().Add(-alertRetention)
+	kept := s.alerts[:0]
+	for _, a := range s.alerts {
+		if a.CreatedAt.After(cutoff) {
+			kept = append(kept, a)
+		}
+	}
+	s.alerts = kept
+	return added
+}
+
+// HasAlert informa se o alerta já foi registrado
+func (s *Store) HasAlert(id string) bool {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for _, a := range s.alerts {
+		if a.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Alerts retorna os alertas da lista, do mais recente para o mais antigo
+func (s *Store) Alerts(watchlistID string, limit int) []Alert {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	result := []Alert{}
+	for i := len(s.alerts) - 1; i >= 0; i-- {
+		if s.alerts[i].WatchlistID != watchlistID {
+			continue
+		}
+		result = append(result, s.alerts[i])
+		if limit > 0 && len(result) == limit {
+			break
+		}
+	}
+	return result
+}
+
+// ActiveAlerts conta os alertas criados desde a data informada
+func (s *Store) ActiveAlerts(since time.Time) int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	count := 0
+	for _, a := range s.alerts {
+		if a.CreatedAt.After(since) {
+			count++
+		}
+	}
+	return count
+}
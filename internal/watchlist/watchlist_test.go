@@ -0,0 +1,247 @@
+package watchlist
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"chat-bot/internal/dou"
+	"chat-bot/internal/proposicoes"
+)
+
+func TestValidateWebhookURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		internal bool
+		invalid  bool
+	}{
+		{url: "https://8.8.8.8/hook"},
+		{url: "http://127.0.0.1:8080/hook", internal: true},
+		{url: "http://[::1]/hook", internal: true},
+		{url: "http://10.1.2.3/hook", internal: true},
+		{url: "http://192.168.0.10/hook", internal: true},
+		{url: "http://169.254.169.254/computeMetadata/v1/", internal: true},
+		{url: "http://0.0.0.0/hook", internal: true},
+		{url: "http://[fd00::1]/hook", internal: true},
+		{url: "http://100.64.0.1/hook", internal: true},
+		{url: "http://198.18.0.1/hook", internal: true},
+		{url: "http://192.0.0.8/hook", internal: true},
+		{url: "http://203.0.113.5/hook", internal: true},
+		{url: "http://240.0.0.1/hook", internal: true},
+		{url: "http://[::ffff:127.0.0.1]/hook", internal: true},
+		{url: "http://[::ffff:a9fe:a9fe]/hook", internal: true},
+		{url: "http://[::127.0.0.1]/hook", internal: true},
+		{url: "http://[64:ff9b::a9fe:a9fe]/hook", internal: true},
+		{url: "http://[2002:7f00:1::1]/hook", internal: true},
+		{url: "http://[2001::1]/hook", internal: true},
+		{url: "https://[2001:4860:4860::8888]/hook"},
+		{url: "https://[::ffff:8.8.8.8]/hook"},
+		{url: "ftp://8.8.8.8/hook", invalid: true},
+		{url: "https:///sem-host", invalid: true},
+	}
+	for _, tt := range tests {
+		err := ValidateWebhookURL(context.Background(), tt.url)
+		switch {
+		case tt.internal && !errors.Is(err, ErrInternalAddress):
+			t.Errorf("%s: esperava ErrInternalAddress, obteve %v", tt.url, err)
+		case tt.invalid && (err == nil || errors.Is(err, ErrInternalAddress)):
+			t.Errorf("%s: esperava URL inválida, obteve %v", tt.url, err)
+		case !tt.internal && !tt.invalid && err != nil:
+			t.Errorf("%s: esperava URL aceita, obteve %v", tt.url, err)
+		}
+	}
+}
+
+func TestWebhookRefusesLoopbackAtDelivery(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier("segredo")
+	err := notifier.Notify(context.Background(), Notification{Event: EventTramitacao, Watchlist: Watchlist{WebhookURL: server.URL}})
+	if !errors.Is(err, ErrInternalAddress) || called {
+		t.Fatalf("a entrega para loopback deveria ser recusada: err=%v chamado=%v", err, called)
+	}
+}
+
+type recordingNotifier struct{ sent int }
+
+func (n *recordingNotifier) Notify(ctx context.Context, notification Notification) error {
+	n.sent++
+	return nil
+}
+
+func TestEmailNeedsConfirmation(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "watchlists.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, hash := NewEmailToken()
+	created, err := store.Create(Watchlist{
+		Owner:          "uid-1",
+		Name:           "Tributário",
+		Bills:          []Bill{{ProposicaoID: "camara-1", Label: "PL 1/2026"}},
+		Email:          "equipe@exemplo.com",
+		EmailTokenHash: hash,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if listed, _ := store.Get(created.ID, "uid-1"); listed.EmailTokenHash != "" {
+		t.Errorf("o hash do token não deveria sair do armazenamento")
+	}
+
+	email := &recordingNotifier{}
+	dispatcher := &Dispatcher{Store: store, Email: email}
+	change := proposicoes.Change{ProposicaoID: "camara-1", Label: "PL 1/2026", Novas: []proposicoes.Tramitacao{{Sequencia: 2, Descricao: "Despacho"}}}
+	if _, err := dispatcher.Process(context.Background(), []proposicoes.Change{change}); err != nil {
+		t.Fatal(err)
+	}
+	if email.sent != 0 {
+		t.Fatalf("e-mail não confirmado recebeu %d alertas", email.sent)
+	}
+	if alerts := store.Alerts(created.ID, 1); len(alerts) != 1 || alerts[0].Deliveries[0].Error == "" {
+		t.Errorf("esperava a entrega registrada como pendente de confirmação: %+v", alerts)
+	}
+
+	if err := store.ConfirmEmail(created.ID, "token-errado"); !errors.Is(err, ErrInvalidConfirmation) {
+		t.Errorf("token errado aceito: %v", err)
+	}
+	if store.EmailConfirmedBy("uid-1", "equipe@exemplo.com") {
+		t.Errorf("e-mail considerado confirmado antes da confirmação")
+	}
+	if err := store.ConfirmEmail(created.ID, token); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.ConfirmEmail(created.ID, token); !errors.Is(err, ErrInvalidConfirmation) {
+		t.Errorf("o token deveria valer uma única vez: %v", err)
+	}
+	if !store.EmailConfirmedBy("uid-1", "EQUIPE@exemplo.com") || store.EmailConfirmedBy("uid-2", "equipe@exemplo.com") {
+		t.Errorf("confirmação deveria valer só para o mesmo dono")
+	}
+
+	change.Novas = []proposicoes.Tramitacao{{Sequencia: 3, Descricao: "Parecer"}}
+	if _, err := dispatcher.Process(context.Background(), []proposicoes.Change{change}); err != nil {
+		t.Fatal(err)
+	}
+	if email.sent != 1 {
+		t.Errorf("e-mail confirmado deveria receber o alerta, recebeu %d", email.sent)
+	}
+}
+
+func TestDispatchIsIdempotent(t *testing.T) {
+	store, err := NewStore(filepath.Join(t.TempDir(), "watchlists.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Create(Watchlist{Owner: "uid-1", Name: "Saúde", DOUKeywords: []string{"vacinação"}, WebhookURL: "https://exemplo.com/hook"}); err != nil {
+		t.Fatal(err)
+	}
+	webhook := &recordingNotifier{}
+	dispatcher := &Dispatcher{Store: store, Webhook: webhook}
+	acts := []dou.Act{
+		{ID: "ato-1", Identifica: "Portaria 1", Texto: "Campanha de vacinação", Data: "2026-10-16"},
+		{ID: "ato-2", Identifica: "Portaria 2", Texto: "Outro assunto", Data: "2026-10-16"},
+	}
+
+	// Uma importação repetida depois de falhar notifica só o que ainda não foi registrado
+	for i := 0; i < 2; i++ {
+		n, err := dispatcher.ProcessActs(context.Background(), acts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := 1 - i; n != want {
+			t.Errorf("execução %d: %d alertas, esperava %d", i+1, n, want)
+		}
+	}
+	if webhook.sent != 1 {
+		t.Errorf("webhook chamado %d vezes, esperava 1", webhook.sent)
+	}
+}
+
+// memoryShared simula o armazenamento compartilhado entre instâncias; o
+// cursor é o número de gravações
+type memoryShared struct {
+	writes []func(*SharedChanges)
+}
+
+func (m *memoryShared) PutWatchlist(ctx context.Context, w Watchlist, deleted bool) error {
+	m.writes = append(m.writes, func(c *SharedChanges) {
+		if deleted {
+			c.Deleted = append(c.Deleted, w.ID)
+		} else {
+			c.Watchlists = append(c.Watchlists, w)
+		}
+	})
+	return nil
+}
+
+func (m *memoryShared) PutAlerts(ctx context.Context, alerts []Alert) error {
+	m.writes = append(m.writes, func(c *SharedChanges) { c.Alerts = append(c.Alerts, alerts...) })
+	return nil
+}
+
+func (m *memoryShared) Changes(ctx context.Context, since time.Time) (SharedChanges, error) {
+	var changes SharedChanges
+	start := 0
+	if !since.IsZero() {
+		start = int(since.Unix())
+	}
+	for i := start; i < len(m.writes); i++ {
+		m.writes[i](&changes)
+	}
+	changes.Cursor = time.Unix(int64(len(m.writes)), 0)
+	return changes, nil
+}
+
+func TestSharedAcrossInstances(t *testing.T) {
+	shared := &memoryShared{}
+	newStore := func() *Store {
+		store, err := NewStore(filepath.Join(t.TempDir(), "watchlists.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		store.SetShared(shared)
+		return store
+	}
+	follower, leader := newStore(), newStore()
+
+	// Lista criada em uma instância que não é a líder
+	created, err := follower.Create(Watchlist{Owner: "sessao-1", Name: "Reforma", Bills: []Bill{{ProposicaoID: "camara-1"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := leader.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if watchers := leader.Watchers("camara-1"); len(watchers) != 1 || watchers[0].ID != created.ID {
+		t.Fatalf("a líder não recebeu a lista: %+v", watchers)
+	}
+
+	// Alerta gerado pela líder aparece na outra instância
+	if err := leader.AddAlerts([]Alert{{ID: "a1", WatchlistID: created.ID, CreatedAt: time.Now()}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := follower.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if alerts := follower.Alerts(created.ID, 0); len(alerts) != 1 {
+		t.Fatalf("alertas na outra instância = %d, esperava 1", len(alerts))
+	}
+
+	if err := follower.Delete(created.ID, "sessao-1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := leader.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if watched := leader.WatchedBills(); len(watched) != 0 || leader.HasAlert("a1") {
+		t.Errorf("a remoção não chegou à líder: %v", watched)
+	}
+}
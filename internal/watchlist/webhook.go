@@ -0,0 +1,128 @@
+package watchlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// ErrInternalAddress indica um webhook que aponta para a rede interna do servidor
+var ErrInternalAddress = errors.New("o webhook não pode apontar para endereços locais ou privados")
+
+// blockedNetworks são as faixas de uso especial dos registros da IANA que não
+// levam a um servidor público: redes privadas e compartilhadas (CGNAT),
+// loopback, link-local (inclusive o servidor de metadados 169.254.169.254),
+// documentação, testes de desempenho, multicast, reservadas e as faixas IPv6
+// que embutem um endereço IPv4 (NAT64, 6to4, Teredo e compatíveis)
+var blockedNetworks = mustParseCIDRs(
+	// IPv4
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.0.2.0/24",
+	"192.88.99.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"198.51.100.0/24",
+	"203.0.113.0/24",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	// IPv6
+	"::/96",
+	"64:ff9b::/96",
+	"64:ff9b:1::/48",
+	"100::/64",
+	"2001::/23",
+	"2001:db8::/32",
+	"2002::/16",
+	"fc00::/7",
+	"fe80::/10",
+	"fec0::/10",
+	"ff00::/8",
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// publicIP recusa os endereços de blockedNetworks. IPv4 mapeado em IPv6
+// (::ffff:a.b.c.d) é conferido como o IPv4 que ele representa.
+func publicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	} else if len(ip) != net.IPv6len {
+		return false
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateWebhookURL exige uma URL http(s) cujo host resolva apenas para endereços públicos
+func ValidateWebhookURL(ctx context.Context, raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Hostname() == "" {
+		return errors.New("webhookUrl deve ser uma URL http(s) válida")
+	}
+
+	host := parsed.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if !publicIP(ip) {
+			return ErrInternalAddress
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("não foi possível resolver o host do webhook %q", host)
+	}
+	for _, addr := range addrs {
+		if !publicIP(addr.IP) {
+			return ErrInternalAddress
+		}
+	}
+	return nil
+}
+
+// dialControl confere o endereço no momento da conexão: o DNS pode mudar depois
+// da validação, e redirecionamentos passam por aqui também
+func dialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+		return ErrInternalAddress
+	}
+	return nil
+}
+
+// newWebhookClient cria o cliente HTTP que só conecta a endereços públicos, sem proxy
+func newWebhookClient() *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, Control: dialControl}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: 10 * time.Second, Transport: transport}
+}
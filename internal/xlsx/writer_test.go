@@ -0,0 +1,96 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// readSheet abre a planilha gerada e devolve o XML da aba
+func readSheet(t *testing.T, data []byte) string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	sheet := ""
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Todas as partes precisam ser XML bem formado
+		decoder := xml.NewDecoder(bytes.NewReader(body))
+		for {
+			if _, err := decoder.Token(); err == io.EOF {
+				break
+			} else if err != nil {
+				t.Fatalf("%s: XML inválido: %v", f.Name, err)
+			}
+		}
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			sheet = string(body)
+		}
+	}
+	if len(names) != 6 || sheet == "" {
+		t.Fatalf("partes inesperadas no arquivo: %v", names)
+	}
+	return sheet
+}
+
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "Respostas & notas")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteHeader([]string{"Nome", "Nota"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow([]interface{}{"<b>Ana</b> & \"Bia\"", 10, int64(7), 2.5, "controle\x01"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("Close repetido deveria ser ignorado: %v", err)
+	}
+	if err := w.WriteRow([]interface{}{"tarde"}); !errors.Is(err, ErrClosed) {
+		t.Errorf("esperava ErrClosed, obteve %v", err)
+	}
+
+	sheet := readSheet(t, buf.Bytes())
+	for _, want := range []string{
+		`<c r="A1" s="1" t="inlineStr"><is><t xml:space="preserve">Nome</t></is></c>`,
+		`<t xml:space="preserve">&lt;b&gt;Ana&lt;/b&gt; &amp; &#34;Bia&#34;</t>`,
+		`<c r="B2"><v>10</v></c>`,
+		`<c r="C2"><v>7</v></c>`,
+		`<c r="D2"><v>2.5</v></c>`,
+		"controle\uFFFD",
+	} {
+		if !strings.Contains(sheet, want) {
+			t.Errorf("aba sem %q:\n%s", want, sheet)
+		}
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	tests := map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"}
+	for index, want := range tests {
+		if got := columnName(index); got != want {
+			t.Errorf("columnName(%d) = %q, esperava %q", index, got, want)
+		}
+	}
+}
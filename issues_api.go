@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"chat-bot/internal/issues"
+	"chat-bot/internal/votes"
+)
+
+const (
+	mentionsFilePath = "data/issue_mentions.json"
+	// mentionsFlushInterval é a frequência com que as contagens do chat são
+	// gravadas; o que ficar pendente é gravado no encerramento do servidor
+	mentionsFlushInterval = 30 * time.Second
+	maxIssues             = 12
+)
+
+var mentionStore *issues.MentionStore
+
+// issueWindows lista as janelas aceitas em /api/issues
+var issueWindows = map[string]int{"30d": 30, "90d": 90, "365d": 365}
+
+// recordChatQuestion contabiliza a pergunta no tema correspondente. Apenas contagens
+// são guardadas: o texto da pergunta não é armazenado.
+func recordChatQuestion(message string) {
+	if mentionStore == nil {
+		return
+	}
+
+	topic := votes.ClassifyTopic(message)
+	if topic == votes.TopicOutros {
+		return
+	}
+
+	var politicianIDs []string
+	if politicianRegistry != nil {
+		for _, p := range politicianRegistry.MentionedIn(message) {
+			politicianIDs = append(politicianIDs, p.ID)
+		}
+	}
+
+	mentionStore.Record(time.Now(), topic, issues.Sentiment(message), politicianIDs)
+}
+
+type IssuesResponse struct {
+	Window  string         `json:"window"`
+	From    string         `json:"from"`
+	To      string         `json:"to"`
+	Issues  []issues.Issue `json:"issues"`
+	Sources []string       `json:"sources"`
+}
+
+func handleIssues(w http.ResponseWriter, r *http.Request) {
+	window := strings.TrimSpace(r.URL.Query().Get("window"))
+	if window == "" {
+		window = "30d"
+	}
+	days, ok := issueWindows[window]
+	if !ok {
+		writeJSONError(w, http.StatusBadRequest, "window deve ser '30d', '90d' ou '365d'")
+		return
+	}
+
+	limit := 6
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxIssues {
+			writeJSONError(w, http.StatusBadRequest, "limit deve estar entre 1 e 12")
+			return
+		}
+		limit = parsed
+	}
+
+	to := time.Now()
+	from := to.AddDate(0, 0, -(days - 1))
+	previousTo := from.AddDate(0, 0, -1)
+	previousFrom := previousTo.AddDate(0, 0, -(days - 1))
+
+	dateFilter := func(start, end time.Time) votes.Filter {
+		return votes.Filter{From: start.Format("2006-01-02"), To: end.Format("2006-01-02")}
+	}
+
+	result := issues.Compute(issues.Input{
+		Current:       mentionStore.Aggregate(from, to),
+		Previous:      mentionStore.Aggregate(previousFrom, previousTo),
+		CurrentVotes:  voteStore.Query(dateFilter(from, to)),
+		PreviousVotes: voteStore.Query(dateFilter(previousFrom, previousTo)),
+		Politician:    politicianRegistry.Get,
+		Limit:         limit,
+	})
+
+	json.NewEncoder(w).Encode(IssuesResponse{
+		Window:  window,
+		From:    from.Format("2006-01-02"),
+		To:      to.Format("2006-01-02"),
+		Issues:  result,
+		Sources: []string{"Perguntas do chat (contagens anônimas)", "Câmara dos Deputados", "Senado Federal"},
+	})
+}
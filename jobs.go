@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"chat-bot/internal/proposicoes"
+	"chat-bot/internal/replica"
+	"chat-bot/internal/scheduler"
+	"chat-bot/internal/services"
+	"chat-bot/internal/watchlist"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	proposicoesFilePath = "data/proposicoes.json"
+
+	// Documento do Firestore usado como trava de liderança entre instâncias
+	schedulerLockCollection = "scheduler_locks"
+	schedulerLockDocument   = "ingestao"
+	schedulerLockTTL        = 3 * time.Minute
+
+	recentProposicoesDays  = 7
+	tramitacoesPerRun      = 150
+	tramitacoesMaxAge      = 24 * time.Hour
+	tramitacoesJobInterval = 2 * time.Hour
+
+	// Coleção do Firestore com as proposições, lida pelas instâncias que não
+	// são líderes a cada proposicoesFollowInterval
+	proposicoesCollection     = "proposicoes"
+	proposicoesFollowInterval = 5 * time.Minute
+
+	// Coleção do Firestore com os arquivos de dados publicados pela líder
+	// (registro, votações, despesas e DOU), lidos pelas demais instâncias a
+	// cada replicasFollowInterval
+	replicasCollection     = "replicas"
+	replicasFollowInterval = 5 * time.Minute
+	replicaPublishTimeout  = 5 * time.Minute
+
+	// Coleção do Firestore com as listas de acompanhamento (e <coleção>_alertas)
+	watchlistsCollection     = "listas"
+	watchlistsFollowInterval = time.Minute
+)
+
+var (
+	jobScheduler     *scheduler.Scheduler
+	proposicaoStore  *proposicoes.Store
+	proposicaoSyncer *proposicoes.Syncer
+)
+
+// schedulerJobs lista as tarefas periódicas do servidor
+func schedulerJobs() []scheduler.Job {
+	return []scheduler.Job{
+		{
+			Name:     "parlamentares",
+			Interval: 24 * time.Hour,
+			Timeout:  registrySyncTimeout,
+			LastRun:  politicianRegistry.LastSync,
+			Run: func(ctx context.Context) error {
+				_, err := syncRegistry(ctx)
+				return err
+			},
+		},
+		{
+			Name:     "proposicoes",
+			Interval: 6 * time.Hour,
+			Timeout:  10 * time.Minute,
+			LastRun:  proposicaoStore.LastListSync,
+			Run: func(ctx context.Context) error {
+				_, err := proposicaoSyncer.SyncRecent(ctx, recentProposicoesDays)
+				return err
+			},
+		},
+		{
+			Name:     "tramitacoes",
+			Interval: tramitacoesJobInterval,
+			Timeout:  20 * time.Minute,
+			LastRun:  proposicaoStore.LastTramitacaoSync,
+			Run: func(ctx context.Context) error {
+				_, err := proposicaoSyncer.SyncTramitacoes(ctx, tramitacoesPerRun, tramitacoesMaxAge, watchlistStore.WatchedBills(), func(ctx context.Context, changes []proposicoes.Change) error {
+					_, err := watchlistDispatcher.Process(ctx, changes)
+					return err
+				})
+				return err
+			},
+		},
+		{
+			Name:     "votacoes",
+			Interval: 6 * time.Hour,
+			Timeout:  votesIngestTimeout,
+			LastRun:  voteStore.LastIngest,
+			Run: func(ctx context.Context) error {
+				_, err := ingestRecentVotes(ctx)
+				return err
+			},
+		},
+		{
+			Name:     "despesas",
+			Interval: 6 * time.Hour,
+			Timeout:  ceapIngestTimeout,
+			LastRun:  ceapStore.LastIngest,
+			Run: func(ctx context.Context) error {
+				_, err := ingestExpenses(ctx)
+				return err
+			},
+		},
+		{
+			Name:     "dou",
+			Interval: 3 * time.Hour,
+			Timeout:  douIngestTimeout,
+			LastRun:  douStore.LastIngest,
+			Run: func(ctx context.Context) error {
+				_, err := ingestDOU(ctx)
+				return err
+			},
+		},
+		{
+			Name:     "metricas",
+			Interval: time.Hour,
+			Timeout:  time.Minute,
+			Run: func(ctx context.Context) error {
+				saveInsightsSnapshot()
+				return nil
+			},
+		},
+	}
+}
+
+// startScheduler inicia o agendador. Com Firestore, apenas a instância que detém
+// a trava executa as tarefas; sem Firestore, a instância local executa tudo.
+func startScheduler(ctx context.Context, firestoreService *services.FirestoreService) {
+	var elector scheduler.Elector = scheduler.LocalElector{}
+	var requests scheduler.Requests
+	var following []*replica.Replica
+	replicas := map[string]*replica.Replica{}
+	if firestoreService != nil {
+		client := firestoreService.GetClient()
+		elector = scheduler.NewFirestoreElector(client, schedulerLockCollection, schedulerLockDocument, schedulerLockTTL)
+		requests = scheduler.NewFirestoreRequests(client, schedulerLockCollection, schedulerLockDocument)
+		log.Println("✅ Agendador usando eleição de líder via Firestore")
+
+		// Só a líder sincroniza; as proposições vão para o Firestore para que as
+		// demais instâncias sirvam os mesmos dados
+		proposicaoStore.SetShared(proposicoes.NewFirestoreShared(client, proposicoesCollection))
+		go proposicaoStore.Follow(ctx, proposicoesFollowInterval)
+
+		// Os demais dados ingeridos pela líder são publicados inteiros depois de
+		// cada tarefa e aplicados pelas outras instâncias
+		blobs := replica.NewFirestoreBlobs(client, replicasCollection)
+		replicas["parlamentares"] = replica.New("parlamentares", registryFilePath, politicianRegistry, blobs)
+		replicas["votacoes"] = replica.New("votacoes", votesFilePath, voteStore, blobs)
+		replicas["despesas"] = replica.New("despesas", ceapFilePath, ceapStore, blobs)
+		replicas["dou"] = replica.New("dou", douFilePath, douStore, blobs)
+		following = []*replica.Replica{replicas["parlamentares"], replicas["votacoes"], replicas["despesas"], replicas["dou"]}
+
+		// As listas podem ser criadas em qualquer instância; os alertas saem da líder
+		watchlistStore.SetShared(watchlist.NewFirestoreShared(client, watchlistsCollection))
+		go watchlistStore.Follow(ctx, watchlistsFollowInterval)
+	}
+
+	jobScheduler = scheduler.New(elector)
+	if requests != nil {
+		jobScheduler.SetRequests(requests)
+	}
+	for _, job := range schedulerJobs() {
+		if r := replicas[job.Name]; r != nil {
+			job.Run = publishAfter(job.Run, r)
+		}
+		jobScheduler.Register(job)
+	}
+
+	go func() {
+		// Os dados publicados pela líder anterior são aplicados antes que esta
+		// instância possa assumir as tarefas, para não serem sobrescritos por elas
+		replica.RefreshAll(ctx, following...)
+		jobScheduler.Start(ctx)
+		if len(following) > 0 {
+			replica.Follow(ctx, replicasFollowInterval, following...)
+		}
+	}()
+}
+
+// publishAfter publica o arquivo da réplica depois da tarefa, mesmo quando ela
+// falha: parte dos dados pode ter sido gravada
+func publishAfter(run func(ctx context.Context) error, r *replica.Replica) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		err := run(ctx)
+		publishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), replicaPublishTimeout)
+		defer cancel()
+		if publishErr := r.Publish(publishCtx); publishErr != nil {
+			return errors.Join(err, fmt.Errorf("publicação de %s: %w", r.Name(), publishErr))
+		}
+		return err
+	}
+}
+
+type JobsResponse struct {
+	Leader bool               `json:"leader"`
+	Holder string             `json:"holder"`
+	Jobs   []scheduler.Status `json:"jobs"`
+}
+
+func handleAdminJobs(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(JobsResponse{
+		Leader: jobScheduler.IsLeader(),
+		Holder: jobScheduler.Holder(),
+		Jobs:   jobScheduler.Statuses(),
+	})
+}
+
+func handleAdminJobRun(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	forwarded, err := jobScheduler.Trigger(r.Context(), name)
+	if err != nil {
+		switch {
+		case errors.Is(err, scheduler.ErrUnknownJob):
+			writeJSONError(w, http.StatusNotFound, err.Error())
+		case errors.Is(err, scheduler.ErrJobRunning):
+			writeJSONError(w, http.StatusConflict, err.Error())
+		default:
+			writeJSONError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	message := "tarefa iniciada"
+	if forwarded {
+		message = "pedido encaminhado à instância líder"
+	}
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": message,
+		"job":     name,
+	})
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"chat-bot/internal/config"
+	"chat-bot/internal/legalsearch"
+)
+
+const (
+	defaultLegalResults = 10
+	maxLegalResults     = 50
+	// maxLegalContextHits limita os artigos enviados ao Gemini por mensagem
+	maxLegalContextHits = 3
+	// minLegalContextScore evita injetar artigos pouco relacionados à pergunta
+	minLegalContextScore = 5.0
+	maxLegalContextChars = 1500
+)
+
+var legalIndex = legalsearch.Build(nil)
+
+// setupLegalSearch carrega o corpus local e monta o índice; sem corpus, a busca fica vazia
+func setupLegalSearch(cfg *config.Config) {
+	var files []string
+	for _, name := range strings.Split(cfg.LegalCorpusFiles, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			files = append(files, name)
+		}
+	}
+
+	laws, err := legalsearch.LoadLaws(cfg.LegalCorpusDir, files)
+	if err != nil {
+		log.Printf("⚠️  Não foi possível carregar o corpus da legislação: %v", err)
+		return
+	}
+	legalIndex = legalsearch.Build(laws)
+	if legalIndex.Size() == 0 {
+		log.Printf("ℹ️  Corpus da legislação vazio em %s (gere com go run ./cmd/legal-corpus)", cfg.LegalCorpusDir)
+		return
+	}
+	log.Printf("✅ Busca na legislação: %d artigos de %d normas", legalIndex.Size(), len(legalIndex.Laws()))
+}
+
+// LegalSearchResponse é a resposta da busca textual na legislação
+type LegalSearchResponse struct {
+	Query   string                `json:"query"`
+	Results []legalsearch.Hit     `json:"results"`
+	Total   int                   `json:"total"`
+	Laws    []legalsearch.LawInfo `json:"laws"`
+}
+
+func handleLegalSearch(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	text := strings.TrimSpace(q.Get("q"))
+	if text == "" {
+		writeJSONError(w, http.StatusBadRequest, "informe o texto da busca em q")
+		return
+	}
+	limit, err := intParam(q, "limit", 1, maxLegalResults)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if limit == 0 {
+		limit = defaultLegalResults
+	}
+
+	hits := legalIndex.Search(legalsearch.Query{Text: text, LawKey: strings.TrimSpace(q.Get("law")), Limit: limit})
+	if hits == nil {
+		hits = []legalsearch.Hit{}
+	}
+	laws := legalIndex.Laws()
+	if laws == nil {
+		laws = []legalsearch.LawInfo{}
+	}
+	json.NewEncoder(w).Encode(LegalSearchResponse{Query: text, Results: hits, Total: len(hits), Laws: laws})
+}
+
+// legalContext busca os artigos mais relevantes para a pergunta e os formata
+// com a citação, para que o Gemini responda com base no texto oficial
+func legalContext(message string) string {
+	hits := legalIndex.Search(legalsearch.Query{Text: message, Limit: maxLegalContextHits})
+	if len(hits) == 0 || hits[0].Score < minLegalContextScore {
+		return ""
+	}
+
+	var b bytes.Buffer
+	b.WriteString("\n\n[TRECHOS DA LEGISLAÇÃO - texto oficial]\n")
+	for i, hit := range hits {
+		// Só os artigos próximos do melhor resultado
+		if hit.Score < hits[0].Score/2 {
+			break
+		}
+		fmt.Fprintf(&b, "\n[%d] %s\n%s\n", i+1, hit.Citation, truncateString(hit.Texto, maxLegalContextChars))
+	}
+	b.WriteString("\nBaseie a resposta nesses trechos quando forem pertinentes e cite-os pelo artigo (ex.: \"art. 5º da Constituição Federal\"). Não atribua a eles o que não está escrito.\n")
+	return b.String()
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"chat-bot/internal/legislacao"
+)
+
+const (
+	legislacaoFilePath = "data/legislacao.json"
+	// maxRealTimeLaws limita as normas consultadas por mensagem do chat
+	maxRealTimeLaws = 3
+	// maxArtigosSemReferencia limita os artigos devolvidos quando a pergunta não cita um artigo
+	maxArtigosSemReferencia = 5
+	legislacaoTimeout       = 20 * time.Second
+)
+
+var legislacaoService *legislacao.Service
+
+// LegislationResponse traz a norma consultada e o artigo pedido (ou todos, com full=true)
+type LegislationResponse struct {
+	Reference legislacao.Reference `json:"reference"`
+	Label     string               `json:"label"`
+	Titulo    string               `json:"titulo"`
+	Ementa    string               `json:"ementa,omitempty"`
+	URL       string               `json:"url"`
+	FetchedAt time.Time            `json:"fetchedAt"`
+	Total     int                  `json:"totalArtigos"`
+	Artigos   []legislacao.Artigo  `json:"artigos"`
+	Texto     string               `json:"texto,omitempty"`
+}
+
+func handleLegislation(w http.ResponseWriter, r *http.Request) {
+	raw := strings.TrimSpace(r.URL.Query().Get("ref"))
+	refs := legislacao.ParseReferences(raw)
+	if len(refs) == 0 {
+		writeJSONError(w, http.StatusBadRequest, "informe a norma em ref, ex.: \"Lei 14.133/2021, art. 75\"")
+		return
+	}
+	ref := refs[0]
+
+	ctx, cancel := context.WithTimeout(r.Context(), legislacaoTimeout)
+	defer cancel()
+	law, artigo, err := legislacaoService.Lookup(ctx, ref)
+	switch {
+	case errors.Is(err, legislacao.ErrArtigoNotFound), errors.Is(err, legislacao.ErrNotFound):
+		writeJSONError(w, http.StatusNotFound, err.Error())
+		return
+	case err != nil:
+		log.Printf("[LEGISLAÇÃO] Erro ao consultar %s: %v", ref.Label(), err)
+		writeJSONError(w, http.StatusBadGateway, "não foi possível consultar a norma no Planalto")
+		return
+	}
+
+	resp := LegislationResponse{
+		Reference: ref,
+		Label:     ref.Label(),
+		Titulo:    law.Titulo,
+		Ementa:    law.Ementa,
+		URL:       law.URL,
+		FetchedAt: law.FetchedAt,
+		Total:     len(law.Artigos),
+		Artigos:   []legislacao.Artigo{},
+	}
+	switch {
+	case artigo != nil:
+		resp.Artigos = append(resp.Artigos, *artigo)
+		resp.Texto = artigo.Text()
+	case r.URL.Query().Get("full") == "true":
+		resp.Artigos = law.Artigos
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// legislationContext monta o texto oficial de uma norma citada: o artigo pedido
+// ou, sem artigo, a ementa e os primeiros artigos
+func legislationContext(law legislacao.Law, artigo *legislacao.Artigo) string {
+	if artigo != nil {
+		return artigo.Text()
+	}
+	var b strings.Builder
+	if law.Ementa != "" {
+		fmt.Fprintf(&b, "Ementa: %s", law.Ementa)
+	}
+	for i, a := range law.Artigos {
+		if i >= maxArtigosSemReferencia {
+			break
+		}
+		fmt.Fprintf(&b, "\n%s", a.Text())
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// fetchLegislation consulta as normas citadas na mensagem para o contexto em tempo real
+func fetchLegislation(ctx context.Context, query string) []RealTimeResult {
+	refs := legislacao.ParseReferences(query)
+	if len(refs) > maxRealTimeLaws {
+		refs = refs[:maxRealTimeLaws]
+	}
+
+	var resultados []RealTimeResult
+	for _, ref := range refs {
+		law, artigo, err := legislacaoService.Lookup(ctx, ref)
+		if err != nil && !errors.Is(err, legislacao.ErrArtigoNotFound) {
+			log.Printf("[LEGISLAÇÃO] Erro ao consultar %s: %v", ref.Label(), err)
+			continue
+		}
+		resultado := RealTimeResult{
+			Fonte: "Planalto - Legislação",
+			Tipo:  law.Titulo,
+			Texto: legislationContext(law, artigo),
+			URL:   law.URL,
+		}
+		if err != nil {
+			resultado.Nota = fmt.Sprintf("O art. %s não foi encontrado no texto compilado; não cite o artigo sem confirmar no link oficial", ref.Artigo)
+		}
+		resultados = append(resultados, resultado)
+	}
+	return resultados
+}
+
+// legislationTool devolve o texto oficial de uma norma ou artigo
+var legislationTool = &chatTool{
+	Declaration: GeminiFunctionDeclaration{
+		Name:        "consultar_legislacao",
+		Description: "Consulta o texto oficial e compilado de leis, decretos, medidas provisórias, emendas e da Constituição no portal do Planalto. Use para citar artigos literalmente.",
+		Parameters: &GeminiSchema{
+			Type: "OBJECT",
+			Properties: map[string]*GeminiSchema{
+				"referencia": {Type: "STRING", Description: "Norma e, opcionalmente, artigo, ex.: \"Lei 14.133/2021, art. 75\", \"art. 5º da Constituição Federal\", \"LC 101/2000\""},
+			},
+			Required: []string{"referencia"},
+		},
+	},
+	// Só perguntas sobre dispositivos específicos: com ferramentas, a busca na web é desativada
+	Keywords: []string{" art ", " artigo", " inciso", " paragrafo", " alinea"},
+	Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		refs := legislacao.ParseReferences(stringArg(args, "referencia"))
+		if len(refs) == 0 {
+			return nil, fmt.Errorf("referência não reconhecida; informe tipo, número e ano, ex.: \"Lei 14.133/2021\"")
+		}
+		ref := refs[0]
+		law, artigo, err := legislacaoService.Lookup(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"norma":  law.Titulo,
+			"ementa": law.Ementa,
+			"texto":  legislationContext(law, artigo),
+			"url":    law.URL,
+			"fonte":  "Presidência da República - Portal da Legislação (texto compilado)",
+		}, nil
+	},
+}
@@ -3,18 +3,36 @@ package main
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
+	"chat-bot/internal/camara"
+	"chat-bot/internal/ceap"
 	"chat-bot/internal/config"
+	"chat-bot/internal/insights"
+	"chat-bot/internal/issues"
+	"chat-bot/internal/legislacao"
+	"chat-bot/internal/outbox"
+	"chat-bot/internal/proposicoes"
+	"chat-bot/internal/registry"
+	"chat-bot/internal/services"
+	"chat-bot/internal/tse"
+	"chat-bot/internal/votes"
 
 	"github.com/gorilla/mux"
 )
@@ -100,12 +118,17 @@ type ChatResponse struct {
 	Timestamp string `json:"timestamp"`
 	RealTime  bool   `json:"realTime,omitempty"`
 	Cached    bool   `json:"cached,omitempty"`
+	// Model e PromptVersion identificam quem gerou a resposta; o frontend os repassa à pesquisa NPS
+	Model         string `json:"model,omitempty"`
+	PromptVersion string `json:"promptVersion,omitempty"`
 }
 
 type HealthResponse struct {
 	Status    string    `json:"status"`
 	Timestamp time.Time `json:"timestamp"`
 	Cache     CacheInfo `json:"cache"`
+	// NPSOutbox mostra as respostas NPS que ainda não chegaram ao Firestore
+	NPSOutbox *outbox.Stats `json:"npsOutbox,omitempty"`
 }
 
 type CacheInfo struct {
@@ -132,104 +155,36 @@ type CacheClearResponse struct {
 }
 
 type NPSResponse struct {
+	ID             string   `json:"id"`
 	Score          int      `json:"score"`
 	Classification string   `json:"classification"`
 	Reasons        []string `json:"reasons,omitempty"`
 	Feedback       string   `json:"feedback,omitempty"`
 	SubmittedAt    string   `json:"submittedAt"`
+	// Fingerprint é o hash que identifica o cliente que respondeu
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// IPKey é o hash só do IP de origem, usado para achar envios repetidos
+	// mesmo quando o cliente troca os cabeçalhos do navegador
+	IPKey string `json:"ipKey,omitempty"`
+	// Status é ok ou flagged; respostas suspeitas ficam gravadas, mas fora das estatísticas
+	Status      string   `json:"status,omitempty"`
+	FlagReasons []string `json:"flagReasons,omitempty"`
+	// Contexto opcional da conversa que antecedeu a pesquisa. SessionID e
+	// ConversationID são identificadores anônimos gerados no navegador.
+	SessionID      string `json:"sessionId,omitempty"`
+	ConversationID string `json:"conversationId,omitempty"`
+	Model          string `json:"model,omitempty"`
+	PromptVersion  string `json:"promptVersion,omitempty"`
+	// QuestionCount é o número de perguntas feitas antes da pesquisa aparecer
+	QuestionCount int `json:"questionCount,omitempty"`
 }
 
-type NPSStore struct {
-	filePath  string
-	responses []NPSResponse
-	mutex     sync.RWMutex
-}
-
-func NewNPSStore(filePath string) (*NPSStore, error) {
-	store := &NPSStore{
-		filePath:  filePath,
-		responses: []NPSResponse{},
-	}
-
-	if err := store.load(); err != nil {
-		return nil, err
-	}
-
-	return store, nil
-}
-
-func (s *NPSStore) load() error {
-	dir := filepath.Dir(s.filePath)
-	if dir != "." {
-		if err := os.MkdirAll(dir, 0o755); err != nil {
-			return err
-		}
+// status trata as respostas gravadas antes da proteção contra abuso como ok
+func (r NPSResponse) status() string {
+	if r.Status == "" {
+		return npsStatusOK
 	}
-
-	file, err := os.Open(s.filePath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			s.responses = []NPSResponse{}
-			return nil
-		}
-		return err
-	}
-	defer file.Close()
-
-	decoder := json.NewDecoder(file)
-	var data []NPSResponse
-	if err := decoder.Decode(&data); err != nil {
-		if err == io.EOF {
-			s.responses = []NPSResponse{}
-			return nil
-		}
-		return err
-	}
-
-	s.responses = data
-	return nil
-}
-
-func (s *NPSStore) saveLocked() error {
-	if dir := filepath.Dir(s.filePath); dir != "." {
-		if err := os.MkdirAll(dir, 0o755); err != nil {
-			return err
-		}
-	}
-
-	if len(s.responses) == 0 {
-		emptyJSON := []byte("[]")
-		return os.WriteFile(s.filePath, emptyJSON, 0o644)
-	}
-
-	payload, err := json.MarshalIndent(s.responses, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	tempPath := s.filePath + ".tmp"
-	if err := os.WriteFile(tempPath, payload, 0o644); err != nil {
-		return err
-	}
-
-	return os.Rename(tempPath, s.filePath)
-}
-
-func (s *NPSStore) Add(entry NPSResponse) error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	s.responses = append(s.responses, entry)
-	return s.saveLocked()
-}
-
-func (s *NPSStore) List() []NPSResponse {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
-
-	copied := make([]NPSResponse, len(s.responses))
-	copy(copied, s.responses)
-	return copied
+	return r.Status
 }
 
 func classifyNPS(score int) string {
@@ -275,6 +230,22 @@ func sanitizeReasons(reasons []string) []string {
 	return cleaned
 }
 
+// sanitizeNPSContextID aceita identificadores curtos de letras, números e . _ : -;
+// qualquer outro valor é descartado para não gravar texto livre no contexto
+func sanitizeNPSContextID(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) > maxNPSContextIDLength {
+		return ""
+	}
+	for _, c := range value {
+		isAlnum := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+		if !isAlnum && !strings.ContainsRune("._:-", c) {
+			return ""
+		}
+	}
+	return value
+}
+
 type GeminiRequest struct {
 	Contents         []GeminiContent         `json:"contents"`
 	Tools            []GeminiTool            `json:"tools,omitempty"`
@@ -282,7 +253,15 @@ type GeminiRequest struct {
 }
 
 type GeminiTool struct {
-	GoogleSearch *GeminiGoogleSearch `json:"googleSearch,omitempty"`
+	GoogleSearch         *GeminiGoogleSearch         `json:"googleSearch,omitempty"`
+	FunctionDeclarations []GeminiFunctionDeclaration `json:"functionDeclarations,omitempty"`
+}
+
+// GeminiFunctionDeclaration descreve uma ferramenta que o modelo pode chamar
+type GeminiFunctionDeclaration struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Parameters  *GeminiSchema `json:"parameters,omitempty"`
 }
 
 type GeminiGoogleSearch struct {
@@ -290,9 +269,21 @@ type GeminiGoogleSearch struct {
 }
 
 type GeminiGenerationConfig struct {
-	Temperature float64 `json:"temperature,omitempty"`
-	TopK        int     `json:"topK,omitempty"`
-	TopP        float64 `json:"topP,omitempty"`
+	Temperature      float64       `json:"temperature,omitempty"`
+	TopK             int           `json:"topK,omitempty"`
+	TopP             float64       `json:"topP,omitempty"`
+	ResponseMimeType string        `json:"responseMimeType,omitempty"`
+	ResponseSchema   *GeminiSchema `json:"responseSchema,omitempty"`
+}
+
+// GeminiSchema descreve o formato de resposta esperado no modo JSON estruturado
+type GeminiSchema struct {
+	Type        string                   `json:"type"`
+	Description string                   `json:"description,omitempty"`
+	Enum        []string                 `json:"enum,omitempty"`
+	Properties  map[string]*GeminiSchema `json:"properties,omitempty"`
+	Items       *GeminiSchema            `json:"items,omitempty"`
+	Required    []string                 `json:"required,omitempty"`
 }
 
 type GeminiContent struct {
@@ -301,7 +292,21 @@ type GeminiContent struct {
 }
 
 type GeminiPart struct {
-	Text string `json:"text"`
+	Text             string                  `json:"text,omitempty"`
+	FunctionCall     *GeminiFunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *GeminiFunctionResponse `json:"functionResponse,omitempty"`
+}
+
+// GeminiFunctionCall é a chamada de ferramenta solicitada pelo modelo
+type GeminiFunctionCall struct {
+	Name string                 `json:"name"`
+	Args map[string]interface{} `json:"args,omitempty"`
+}
+
+// GeminiFunctionResponse devolve ao modelo o resultado de uma ferramenta
+type GeminiFunctionResponse struct {
+	Name     string                 `json:"name"`
+	Response map[string]interface{} `json:"response"`
 }
 
 type GeminiResponse struct {
@@ -312,6 +317,18 @@ type GeminiCandidate struct {
 	Content GeminiContent `json:"content"`
 }
 
+// Text concatena as partes de texto do primeiro candidato
+func (r *GeminiResponse) Text() string {
+	if r == nil || len(r.Candidates) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for _, part := range r.Candidates[0].Content.Parts {
+		b.WriteString(part.Text)
+	}
+	return b.String()
+}
+
 const SYSTEM_INSTRUCTIONS = `Você é um chatbot político neutro e informativo para o público brasileiro.
 Princípios:
 - Seja factual e forneça informações detalhadas sobre o tema perguntado.
@@ -347,24 +364,40 @@ IMPORTANTE - Links de fontes oficiais:
 - Não termine automaticamente com a frase "Para informações mais detalhadas..." se a resposta já foi completa e não há necessidade de consulta adicional.`
 
 const (
-	npsStoreFilePath  = "data/nps-responses.json"
+	npsStoreFilePath  = "data/nps-responses.jsonl"
 	maxNPSPayloadSize = 64 * 1024
+	// maxNPSContextIDLength e maxNPSQuestionCount limitam o contexto da conversa enviado com a pesquisa
+	maxNPSContextIDLength = 128
+	maxNPSQuestionCount   = 1000
+	// shutdownTimeout fica abaixo dos 10 segundos que o Cloud Run dá depois do SIGTERM
+	shutdownTimeout = 8 * time.Second
 )
 
 var (
 	cache        *Cache
 	geminiAPIKey string
-	geminiURL    = "https://generativelanguage.googleapis.com/v1beta/models/gemini-2.0-flash:generateContent"
+	geminiModel  = "gemini-2.0-flash"
+	geminiURL    = "https://generativelanguage.googleapis.com/v1beta/models/" + geminiModel + ":generateContent"
 	npsStore     NPSStoreInterface
+	// promptVersion identifica as instruções enviadas por buildChatInstructions:
+	// o modelo, as duas seções de busca e os links das fontes. A data preenchida
+	// em cada pedido fica de fora, para a versão só mudar quando o texto muda
+	promptVersion = instructionsVersion(chatInstructionsTemplate, chatWebSearchInstructions, chatToolInstructions, chatSourceInstructions)
 )
 
+// instructionsVersion resume os trechos das instruções em um identificador curto
+func instructionsVersion(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return "sha-" + hex.EncodeToString(sum[:4])
+}
+
 // spaHandler serve arquivos estáticos e faz fallback para index.html para React Router
 func spaHandler(staticDir string) http.Handler {
 	fileServer := http.FileServer(http.Dir(staticDir))
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Não processar requisições para API
-		if strings.HasPrefix(r.URL.Path, "/api") {
+		// Não processar requisições para API nem feeds inexistentes
+		if strings.HasPrefix(r.URL.Path, "/api") || strings.HasPrefix(r.URL.Path, "/feeds/") {
 			http.NotFound(w, r)
 			return
 		}
@@ -390,6 +423,15 @@ func spaHandler(staticDir string) http.Handler {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == npsMigrateCommand {
+		os.Exit(runNPSMigrate(os.Args[2:]))
+	}
+
+	// serverCtx é cancelado no SIGTERM do Cloud Run (ou Ctrl+C), para o
+	// servidor terminar as requisições e gravar o que estiver pendente
+	serverCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Carregar configurações do arquivo env.yaml
 	cfg, err := config.Load()
 	if err != nil {
@@ -403,12 +445,28 @@ func main() {
 
 	cache = NewCache(5 * time.Minute)
 
-	// Tenta usar Firestore se as variáveis de ambiente estiverem configuradas
-	if cfg.FirebaseProjectID != "" {
+	var firestoreService *services.FirestoreService
+
+	switch cfg.StorageBackend {
+	case "", npsBackendFile, npsBackendFirestore, npsBackendSQLite:
+	default:
+		log.Fatalf("STORAGE_BACKEND inválido: %q (use %s, %s ou %s)", cfg.StorageBackend, npsBackendFirestore, npsBackendSQLite, npsBackendFile)
+	}
+
+	if cfg.StorageBackend == npsBackendSQLite {
+		// Instalações próprias: banco embutido, sem fallback silencioso
+		sqliteStore, err := NewNPSStoreSQLite(context.Background(), cfg.SQLitePath)
+		if err != nil {
+			log.Fatalf("não foi possível abrir o banco SQLite %s: %v", cfg.SQLitePath, err)
+		}
+		log.Printf("🗄️  Usando SQLite (%s) para armazenamento de feedback NPS", cfg.SQLitePath)
+		npsStore = sqliteStore
+	} else if cfg.FirebaseProjectID != "" && cfg.StorageBackend != npsBackendFile {
+		// Tenta usar Firestore se as variáveis de ambiente estiverem configuradas
 		ctx := context.Background()
 		firestoreStore, err := NewNPSStoreFirestore(ctx, cfg)
 		if err != nil {
-			log.Printf("⚠️  Erro ao conectar ao Firestore: %v. Usando armazenamento local como fallback.", err)
+			log.Printf("⚠️  Erro ao conectar ao Firestore: %v. Usando armazenamento local como fallback; as respostas gravadas em %s podem ser enviadas depois com `%s -from file -to firestore`.", err, npsStoreFilePath, npsMigrateCommand)
 			// Fallback para arquivo local
 			npsStore, err = NewNPSStore(npsStoreFilePath)
 			if err != nil {
@@ -417,6 +475,8 @@ func main() {
 		} else {
 			log.Println("✅ Firestore configurado para armazenamento de feedback NPS")
 			npsStore = firestoreStore
+			firestoreService = firestoreStore.firestoreService
+			setupNPSOutbox(firestoreStore, cfg.NPSOutboxDir)
 		}
 	} else {
 		// Usa arquivo local se Firestore não estiver configurado
@@ -426,6 +486,84 @@ func main() {
 			log.Fatalf("não foi possível preparar o armazenamento NPS: %v", err)
 		}
 	}
+
+	setupAdminAuth(cfg, firestoreService)
+	trustForwardedFor = strings.EqualFold(strings.TrimSpace(cfg.TrustedProxy), "true")
+	setupNPSGuard(cfg)
+
+	auditStore, err = openAuditLog(context.Background())
+	if err != nil {
+		log.Fatalf("não foi possível carregar o log de auditoria: %v", err)
+	}
+
+	politicianRegistry, err = registry.New(registryFilePath)
+	if err != nil {
+		log.Fatalf("não foi possível carregar o registro de parlamentares: %v", err)
+	}
+
+	voteStore, err = votes.NewStore(votesFilePath)
+	if err != nil {
+		log.Fatalf("não foi possível carregar as votações: %v", err)
+	}
+	voteIngester = &votes.Ingester{
+		Camara:                 camaraClient,
+		Senado:                 senadoClient,
+		Store:                  voteStore,
+		SenadoGovernmentLeader: cfg.SenadoGovernmentLeader,
+	}
+
+	ceapStore, err = ceap.NewStore(ceapFilePath)
+	if err != nil {
+		log.Fatalf("não foi possível carregar as despesas da CEAP: %v", err)
+	}
+	ceapIngester = &ceap.Ingester{Camara: camaraClient, Store: ceapStore, PerRun: ceapPerRun, MaxAge: ceapMaxAge}
+
+	mentionStore, err = issues.NewMentionStore(mentionsFilePath)
+	if err != nil {
+		log.Fatalf("não foi possível carregar as menções por tema: %v", err)
+	}
+	go mentionStore.Run(serverCtx, mentionsFlushInterval)
+
+	tseStore, err = tse.NewStore(tseDataDir)
+	if err != nil {
+		log.Fatalf("não foi possível carregar os dados do TSE: %v", err)
+	}
+
+	legislacaoStore, err := legislacao.NewStore(legislacaoFilePath)
+	if err != nil {
+		log.Fatalf("não foi possível carregar a legislação: %v", err)
+	}
+	legislacaoService = &legislacao.Service{Store: legislacaoStore, Client: legislacao.NewClient()}
+
+	setupLegalSearch(cfg)
+
+	insightsStore, err = insights.NewStore(insightsFilePath)
+	if err != nil {
+		log.Fatalf("não foi possível carregar as métricas do painel: %v", err)
+	}
+	if firestoreService != nil {
+		// As respostas são contadas em todas as instâncias; o total fica no Firestore
+		insightsStore.SetShared(insights.NewFirestoreShared(firestoreService.GetClient(), insightsCollection))
+		go insightsStore.Follow(serverCtx, insightsFollowInterval)
+	}
+	go insightsStore.Run(serverCtx, insightsFlushInterval)
+
+	proposicaoStore, err = proposicoes.NewStore(proposicoesFilePath)
+	if err != nil {
+		log.Fatalf("não foi possível carregar as proposições: %v", err)
+	}
+	proposicaoSyncer = &proposicoes.Syncer{Camara: camaraClient, Senado: senadoClient, Store: proposicaoStore}
+
+	if err := setupWatchlists(cfg); err != nil {
+		log.Fatalf("não foi possível carregar as listas de acompanhamento: %v", err)
+	}
+
+	if err := setupDOU(cfg); err != nil {
+		log.Fatalf("não foi possível carregar os atos do DOU: %v", err)
+	}
+
+	startScheduler(serverCtx, firestoreService)
+
 	r := mux.NewRouter()
 	r.Use(corsMiddleware)
 
@@ -434,9 +572,42 @@ func main() {
 	api.HandleFunc("/chat", handleChat).Methods("POST")
 	api.HandleFunc("/health", handleHealth).Methods("GET")
 	api.HandleFunc("/sources", handleSources).Methods("GET")
-	api.HandleFunc("/cache/clear", handleCacheClear).Methods("POST")
+	api.HandleFunc("/cache/clear", requireAdmin(handleCacheClear)).Methods("POST")
+	api.HandleFunc("/nps/token", handleNPSToken).Methods("GET")
 	api.HandleFunc("/nps/responses", handleNPSSubmit).Methods("POST")
-	api.HandleFunc("/nps/responses", handleNPSList).Methods("GET")
+	api.HandleFunc("/nps/responses", requireAdmin(handleNPSList)).Methods("GET")
+	api.HandleFunc("/nps/stats", requireAdmin(handleNPSStats)).Methods("GET")
+	api.HandleFunc("/nps/export", requireAdmin(handleNPSExport)).Methods("GET")
+	api.HandleFunc("/votes", handleVotes).Methods("GET")
+	api.HandleFunc("/issues", handleIssues).Methods("GET")
+	api.HandleFunc("/insights/summary", handleInsightsSummary).Methods("GET")
+	api.HandleFunc("/watchlists", handleWatchlistCreate).Methods("POST")
+	api.HandleFunc("/watchlists", handleWatchlistList).Methods("GET")
+	api.HandleFunc("/watchlists/{id}", handleWatchlistGet).Methods("GET")
+	api.HandleFunc("/watchlists/{id}", handleWatchlistDelete).Methods("DELETE")
+	api.HandleFunc("/watchlists/{id}/alerts", handleWatchlistAlerts).Methods("GET")
+	api.HandleFunc("/watchlists/{id}/confirm", handleWatchlistConfirm).Methods("GET")
+	api.HandleFunc("/admin/me", requireAdmin(handleAdminMe)).Methods("GET")
+	api.HandleFunc("/admin/audit", requireAdmin(handleAdminAudit)).Methods("GET")
+	api.HandleFunc("/admin/jobs", requireAdmin(handleAdminJobs)).Methods("GET")
+	api.HandleFunc("/admin/jobs/{name}/run", requireAdmin(handleAdminJobRun)).Methods("POST")
+	api.HandleFunc("/elections/datasets", handleElectionDatasets).Methods("GET")
+	api.HandleFunc("/elections/candidates", handleElectionCandidates).Methods("GET")
+	api.HandleFunc("/elections/candidates/{id}", handleElectionCandidate).Methods("GET")
+	api.HandleFunc("/elections/results", handleElectionResults).Methods("GET")
+	api.HandleFunc("/legislation", handleLegislation).Methods("GET")
+	api.HandleFunc("/legal/search", handleLegalSearch).Methods("GET")
+	api.HandleFunc("/dou", handleDOUSearch).Methods("GET")
+	api.HandleFunc("/politicians/search", handlePoliticianSearch).Methods("GET")
+	api.HandleFunc("/politicians/compare", handlePoliticianCompare).Methods("GET")
+	api.HandleFunc("/politicians/{name}/profile", handlePoliticianProfile).Methods("GET")
+	api.HandleFunc("/politicians/{id}/score", handlePoliticianScore).Methods("GET")
+	api.HandleFunc("/politicians/{id}/expenses", handlePoliticianExpenses).Methods("GET")
+
+	// Feeds Atom ficam fora de /api e precisam ser registrados antes do spaHandler
+	r.HandleFunc("/feeds/bills/{sigla:[A-Za-z]+}-{numero:[0-9]+}-{ano:[0-9]{4}}.atom", handleBillFeed).Methods("GET", "HEAD")
+	r.HandleFunc("/feeds/votes.atom", handleVotesFeed).Methods("GET", "HEAD")
+	r.HandleFunc("/feeds/deputados/{id:[0-9]+}.atom", handleDeputadoFeed).Methods("GET", "HEAD")
 
 	// Serve arquivos estáticos e fallback para index.html para React Router
 	r.PathPrefix("/").Handler(spaHandler("./public/"))
@@ -449,15 +620,46 @@ func main() {
 		}
 	}
 
+	server := &http.Server{Addr: ":" + port, Handler: r}
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-serverCtx.Done()
+		log.Println("🛑 Encerrando o servidor...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("⚠️  Erro ao encerrar o servidor: %v", err)
+		}
+	}()
+
 	log.Printf("🚀 Servidor rodando na porta %s", port)
-	log.Fatal(http.ListenAndServe(":"+port, r))
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+	<-stopped
+	flushStores()
+}
+
+// flushStores grava os contadores mantidos em memória entre gravações
+func flushStores() {
+	if mentionStore != nil {
+		if err := mentionStore.Flush(); err != nil {
+			log.Printf("⚠️  Erro ao gravar as menções por tema: %v", err)
+		}
+	}
+	if insightsStore != nil {
+		if err := insightsStore.Flush(); err != nil {
+			log.Printf("⚠️  Erro ao gravar as métricas do painel: %v", err)
+		}
+	}
 }
 
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Session-ID, X-Client-ID")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -475,6 +677,28 @@ func jsonMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// trustForwardedFor é ligado por TRUSTED_PROXY=true, quando há um proxy na
+// frente do servidor (como o balanceador do Cloud Run)
+var trustForwardedFor bool
+
+// clientIP devolve o IP de origem. Sem proxy confiável vale o RemoteAddr, já
+// que X-Forwarded-For vem do cliente. Atrás do balanceador do Cloud Run, o
+// último endereço de X-Forwarded-For é o que o próprio balanceador
+// acrescentou; os anteriores podem ser forjados
+func clientIP(r *http.Request) string {
+	if forwarded := r.Header.Values("X-Forwarded-For"); trustForwardedFor && len(forwarded) > 0 {
+		hops := strings.Split(forwarded[len(forwarded)-1], ",")
+		if last := strings.TrimSpace(hops[len(hops)-1]); net.ParseIP(last) != nil {
+			return last
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
 func generateCacheKey(message string, context []ChatContext) string {
 	ctxStr := ""
 	if len(context) > 0 {
@@ -490,7 +714,9 @@ type RealTimeResult struct {
 	Tipo  string      `json:"tipo"`
 	Dados interface{} `json:"dados,omitempty"`
 	Nota  string      `json:"nota,omitempty"`
-	URL   string      `json:"url,omitempty"`
+	// Texto traz o texto oficial (ex.: artigo de lei) para citação literal
+	Texto string `json:"texto,omitempty"`
+	URL   string `json:"url,omitempty"`
 }
 
 type RealTimeData struct {
@@ -530,6 +756,14 @@ func handleNPSSubmit(w http.ResponseWriter, r *http.Request) {
 
 	defer r.Body.Close()
 
+	now := time.Now().UTC()
+	fingerprint := npsFingerprint(r)
+	if ok, retryAfter := npsGuard.allow(clientIP(r), fingerprint, now); !ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+		writeJSONError(w, http.StatusTooManyRequests, "muitas respostas enviadas; tente novamente mais tarde")
+		return
+	}
+
 	reader := io.LimitReader(r.Body, maxNPSPayloadSize)
 	decoder := json.NewDecoder(reader)
 	decoder.DisallowUnknownFields()
@@ -540,6 +774,12 @@ func handleNPSSubmit(w http.ResponseWriter, r *http.Request) {
 		Reasons        []string `json:"reasons"`
 		Feedback       string   `json:"feedback"`
 		SubmittedAt    string   `json:"submittedAt"`
+		SurveyToken    string   `json:"surveyToken"`
+		SessionID      string   `json:"sessionId"`
+		ConversationID string   `json:"conversationId"`
+		Model          string   `json:"model"`
+		PromptVersion  string   `json:"promptVersion"`
+		QuestionCount  int      `json:"questionCount"`
 	}
 
 	if err := decoder.Decode(&payload); err != nil {
@@ -558,9 +798,16 @@ func handleNPSSubmit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	submittedAt := time.Now().UTC()
+	flags, err := npsGuard.check(r.Context(), fingerprint, npsIPKey(r), payload.SurveyToken, now)
+	if err != nil {
+		writeJSONError(w, http.StatusForbidden, err.Error())
+		return
+	}
+
+	// O horário do navegador só é aceito se próximo do servidor, para não reescrever o histórico
+	submittedAt := now
 	if payload.SubmittedAt != "" {
-		if parsed, err := time.Parse(time.RFC3339, payload.SubmittedAt); err == nil {
+		if parsed, err := time.Parse(time.RFC3339, payload.SubmittedAt); err == nil && parsed.Sub(now).Abs() <= npsMaxClockSkew {
 			submittedAt = parsed.UTC()
 		}
 	}
@@ -574,12 +821,35 @@ func handleNPSSubmit(w http.ResponseWriter, r *http.Request) {
 	reasons := sanitizeReasons(payload.Reasons)
 	feedback := strings.TrimSpace(payload.Feedback)
 
+	if payload.QuestionCount < 0 || payload.QuestionCount > maxNPSQuestionCount {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("questionCount deve estar entre 0 e %d", maxNPSQuestionCount))
+		return
+	}
+	// Sem modelo ou versão informados, os campos ficam vazios: as estatísticas os
+	// agrupam em "não informado" em vez de atribuí-los ao que o servidor usa agora
+	model := sanitizeNPSContextID(payload.Model)
+	version := sanitizeNPSContextID(payload.PromptVersion)
+
 	entry := NPSResponse{
+		ID:             newNPSID(),
 		Score:          score,
 		Classification: classification,
 		Reasons:        reasons,
 		Feedback:       feedback,
 		SubmittedAt:    submittedAt.Format(time.RFC3339),
+		Fingerprint:    fingerprint,
+		IPKey:          npsIPKey(r),
+		Status:         npsStatusOK,
+		SessionID:      sanitizeNPSContextID(payload.SessionID),
+		ConversationID: sanitizeNPSContextID(payload.ConversationID),
+		Model:          model,
+		PromptVersion:  version,
+		QuestionCount:  payload.QuestionCount,
+	}
+	if len(flags) > 0 {
+		entry.Status = npsStatusFlagged
+		entry.FlagReasons = flags
+		log.Printf("⚠️  resposta NPS %s marcada como suspeita: %s", entry.ID, strings.Join(flags, ", "))
 	}
 
 	if err := npsStore.Add(entry); err != nil {
@@ -588,29 +858,15 @@ func handleNPSSubmit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A resposta pública não revela a impressão digital nem a marcação de suspeita
+	public := entry
+	public.Fingerprint, public.IPKey, public.Status, public.FlagReasons = "", "", "", nil
 	w.WriteHeader(http.StatusCreated)
-	if err := json.NewEncoder(w).Encode(entry); err != nil {
+	if err := json.NewEncoder(w).Encode(public); err != nil {
 		log.Printf("erro ao codificar resposta NPS: %v", err)
 	}
 }
 
-func handleNPSList(w http.ResponseWriter, r *http.Request) {
-	if npsStore == nil {
-		writeJSONError(w, http.StatusServiceUnavailable, "armazenamento de pesquisas indisponível")
-		return
-	}
-
-	responses := npsStore.List()
-	if responses == nil {
-		responses = []NPSResponse{}
-	}
-
-	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(map[string][]NPSResponse{"responses": responses}); err != nil {
-		log.Printf("erro ao codificar lista NPS: %v", err)
-	}
-}
-
 func writeJSONError(w http.ResponseWriter, status int, message string) {
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(map[string]string{"error": message}); err != nil {
@@ -622,97 +878,46 @@ func writeJSONError(w http.ResponseWriter, status int, message string) {
 func buscarDadosCamara(query string) (*RealTimeResult, error) {
 	lowerQuery := strings.ToLower(query)
 
-	if strings.Contains(lowerQuery, "projeto") || strings.Contains(lowerQuery, "tramitação") || strings.Contains(lowerQuery, "proposição") {
-		currentYear := time.Now().Year()
-		url := fmt.Sprintf("https://dadosabertos.camara.leg.br/api/v2/proposicoes?ano=%d&itens=10&ordem=ASC&ordenarPor=id", currentYear)
-
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
 
-		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-		if err != nil {
-			return nil, err
+	if strings.Contains(lowerQuery, "projeto") || strings.Contains(lowerQuery, "tramitação") || strings.Contains(lowerQuery, "proposição") {
+		// Usa as proposições sincronizadas pelo agendador quando disponíveis
+		if recentes := proposicaoStore.Recent(proposicoes.CasaCamara, 5); len(recentes) > 0 {
+			return &RealTimeResult{
+				Fonte: "Câmara dos Deputados",
+				Tipo:  "proposições com movimentação recente",
+				Dados: recentes,
+				URL:   "https://www.camara.leg.br/",
+			}, nil
 		}
-		req.Header.Set("Accept", "application/json")
 
-		client := &http.Client{Timeout: 5 * time.Second}
-		resp, err := client.Do(req)
+		lista, err := camaraClient.ListProposicoes(ctx, camara.ProposicoesQuery{Ano: time.Now().Year()}, 5)
 		if err != nil {
 			return nil, err
 		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode == http.StatusOK {
-			body, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return nil, err
-			}
-
-			var data struct {
-				Dados []map[string]interface{} `json:"dados"`
-			}
-			if err := json.Unmarshal(body, &data); err == nil && len(data.Dados) > 0 {
-				// Limita a 5 itens
-				var limitedData []map[string]interface{}
-				if len(data.Dados) > 5 {
-					limitedData = data.Dados[:5]
-				} else {
-					limitedData = data.Dados
-				}
-
-				return &RealTimeResult{
-					Fonte: "Câmara dos Deputados",
-					Tipo:  "proposições",
-					Dados: limitedData,
-					URL:   "https://www.camara.leg.br/",
-				}, nil
-			}
+		if len(lista) > 0 {
+			return &RealTimeResult{
+				Fonte: "Câmara dos Deputados",
+				Tipo:  "proposições",
+				Dados: lista,
+				URL:   "https://www.camara.leg.br/",
+			}, nil
 		}
 	}
 
 	if strings.Contains(lowerQuery, "deputado") {
-		url := "https://dadosabertos.camara.leg.br/api/v2/deputados?itens=10&ordem=ASC&ordenarPor=nome"
-
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-
-		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-		if err != nil {
-			return nil, err
-		}
-		req.Header.Set("Accept", "application/json")
-
-		client := &http.Client{Timeout: 5 * time.Second}
-		resp, err := client.Do(req)
+		deputados, err := camaraClient.ListDeputados(ctx, 5)
 		if err != nil {
 			return nil, err
 		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode == http.StatusOK {
-			body, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return nil, err
-			}
-
-			var data struct {
-				Dados []map[string]interface{} `json:"dados"`
-			}
-			if err := json.Unmarshal(body, &data); err == nil && len(data.Dados) > 0 {
-				var limitedData []map[string]interface{}
-				if len(data.Dados) > 5 {
-					limitedData = data.Dados[:5]
-				} else {
-					limitedData = data.Dados
-				}
-
-				return &RealTimeResult{
-					Fonte: "Câmara dos Deputados",
-					Tipo:  "deputados",
-					Dados: limitedData,
-					URL:   "https://www.camara.leg.br/",
-				}, nil
-			}
+		if len(deputados) > 0 {
+			return &RealTimeResult{
+				Fonte: "Câmara dos Deputados",
+				Tipo:  "deputados",
+				Dados: deputados,
+				URL:   "https://www.camara.leg.br/",
+			}, nil
 		}
 	}
 
@@ -724,51 +929,32 @@ func buscarDadosSenado(query string) (*RealTimeResult, error) {
 	lowerQuery := strings.ToLower(query)
 
 	if strings.Contains(lowerQuery, "senado") || strings.Contains(lowerQuery, "senador") {
-		url := "https://legis.senado.leg.br/dadosabertos/senador/lista/atual"
-
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 		defer cancel()
 
-		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		senadores, err := senadoClient.ListSenadoresAtuais(ctx)
 		if err != nil {
 			return nil, err
 		}
-		req.Header.Set("Accept", "application/json")
 
-		client := &http.Client{Timeout: 5 * time.Second}
-		resp, err := client.Do(req)
-		if err != nil {
-			return nil, err
+		if len(senadores) > 10 {
+			senadores = senadores[:10]
 		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode == http.StatusOK {
-			body, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return nil, err
-			}
-
-			var data struct {
-				ListaParlamentarEmExercicio struct {
-					Parlamentares []map[string]interface{} `json:"Parlamentar"`
-				} `json:"ListaParlamentarEmExercicio"`
-			}
-			if err := json.Unmarshal(body, &data); err == nil {
-				var limitedData []map[string]interface{}
-				if len(data.ListaParlamentarEmExercicio.Parlamentares) > 10 {
-					limitedData = data.ListaParlamentarEmExercicio.Parlamentares[:10]
-				} else {
-					limitedData = data.ListaParlamentarEmExercicio.Parlamentares
-				}
-
-				return &RealTimeResult{
-					Fonte: "Senado Federal",
-					Tipo:  "senadores",
-					Dados: limitedData,
-					URL:   "https://www25.senado.leg.br/",
-				}, nil
-			}
+		limitedData := make([]map[string]interface{}, 0, len(senadores))
+		for _, s := range senadores {
+			limitedData = append(limitedData, map[string]interface{}{
+				"nome":  s.IdentificacaoParlamentar.NomeParlamentar,
+				"sigla": s.IdentificacaoParlamentar.SiglaPartidoParlamentar,
+				"uf":    s.IdentificacaoParlamentar.UFParlamentar,
+			})
 		}
+
+		return &RealTimeResult{
+			Fonte: "Senado Federal",
+			Tipo:  "senadores",
+			Dados: limitedData,
+			URL:   "https://www25.senado.leg.br/",
+		}, nil
 	}
 
 	return nil, nil
@@ -792,34 +978,38 @@ func fetchRealTimeData(query string) *RealTimeData {
 	// TSE - informações eleitorais
 	if strings.Contains(lowerQuery, "eleições") || strings.Contains(lowerQuery, "tse") ||
 		strings.Contains(lowerQuery, "candidato") || strings.Contains(lowerQuery, "votação") {
-		resultados = append(resultados, RealTimeResult{
+		resultado := RealTimeResult{
 			Fonte: "TSE - Tribunal Superior Eleitoral",
 			Tipo:  "informações eleitorais",
 			Nota:  "Para dados eleitorais atualizados, consulte: https://www.tse.jus.br/",
 			URL:   "https://www.tse.jus.br/",
-		})
+		}
+		if datasets := tseStore.Datasets(); len(datasets) > 0 {
+			resultado.Tipo = "eleições importadas dos dados abertos"
+			resultado.Dados = datasets
+			resultado.Nota = "Candidaturas, bens declarados e votação por zona disponíveis em /api/elections"
+			resultado.URL = "https://dadosabertos.tse.jus.br/"
+		}
+		resultados = append(resultados, resultado)
 	}
 
 	// Planalto - legislação
-	if strings.Contains(lowerQuery, "lei") || strings.Contains(lowerQuery, "decreto") ||
-		strings.Contains(lowerQuery, "sanção") || strings.Contains(lowerQuery, "sancionado") {
-		currentYear := time.Now().Year()
-		var atoRange string
-		// Atualiza o range de anos dinamicamente baseado no ano atual
-		if currentYear >= 2025 {
-			atoRange = "2025-2030"
-		} else if currentYear >= 2019 {
-			atoRange = "2019-2024"
+	if legislacao.MentionsLegislation(query) {
+		ctx, cancel := context.WithTimeout(context.Background(), legislacaoTimeout)
+		normas := fetchLegislation(ctx, query)
+		cancel()
+
+		if len(normas) > 0 {
+			resultados = append(resultados, normas...)
 		} else {
-			atoRange = "2011-2018"
+			currentYear := time.Now().Year()
+			resultados = append(resultados, RealTimeResult{
+				Fonte: "Planalto",
+				Tipo:  "legislação",
+				Nota:  fmt.Sprintf("Para leis e decretos de %d, consulte: %s/_ato%s/%d/", currentYear, legislacao.BaseURL, legislacao.AtoRange(currentYear), currentYear),
+				URL:   "https://www.planalto.gov.br/",
+			})
 		}
-
-		resultados = append(resultados, RealTimeResult{
-			Fonte: "Planalto",
-			Tipo:  "legislação",
-			Nota:  fmt.Sprintf("Para leis e decretos de %d, consulte: https://www.planalto.gov.br/ccivil_03/_ato%s/%d/", currentYear, atoRange, currentYear),
-			URL:   "https://www.planalto.gov.br/",
-		})
 	}
 
 	observacao := "Consulte os sites oficiais para informações mais detalhadas"
@@ -854,6 +1044,25 @@ func callGeminiAPI(contents []GeminiContent) (*GeminiResponse, error) {
 		},
 	}
 
+	return sendGeminiRequest(geminiReq)
+}
+
+// callGeminiStructured solicita uma resposta em JSON seguindo o schema informado.
+// O modo estruturado não pode ser combinado com a busca na web.
+func callGeminiStructured(contents []GeminiContent, schema *GeminiSchema) (*GeminiResponse, error) {
+	geminiReq := GeminiRequest{
+		Contents: contents,
+		GenerationConfig: &GeminiGenerationConfig{
+			Temperature:      0.2,
+			ResponseMimeType: "application/json",
+			ResponseSchema:   schema,
+		},
+	}
+
+	return sendGeminiRequest(geminiReq)
+}
+
+func sendGeminiRequest(geminiReq GeminiRequest) (*GeminiResponse, error) {
 	jsonData, err := json.Marshal(geminiReq)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao serializar requisição: %w", err)
@@ -884,37 +1093,8 @@ func callGeminiAPI(contents []GeminiContent) (*GeminiResponse, error) {
 	return &geminiResp, nil
 }
 
-func handleChat(w http.ResponseWriter, r *http.Request) {
-	var req ChatRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, `{"error": "Erro ao decodificar JSON"}`, http.StatusBadRequest)
-		return
-	}
-
-	if req.Message == "" {
-		http.Error(w, `{"error": "Campo 'message' é obrigatório"}`, http.StatusBadRequest)
-		return
-	}
-
-	cacheKey := generateCacheKey(req.Message, req.Context)
-	if cachedResp, found := cache.Get(cacheKey); found {
-		cachedResp.Cached = true
-		json.NewEncoder(w).Encode(cachedResp)
-		return
-	}
-
-	needsRealTime := searchRealTimeInfo(req.Message)
-
-	// Gera instruções do sistema com data atual dinâmica
-	currentYear := time.Now().Year()
-	currentDate := time.Now().Format("02 de January de 2006")
-
-	// Constrói instruções dinâmicas com data atual
-	dynamicInstructions := fmt.Sprintf(`Você é um chatbot político neutro e informativo para o público brasileiro.
-
-DATA ATUAL: A data atual é %s (ano %d). Use esta data como referência ao responder sobre eventos recentes, atuais ou futuros.
-
-IMPORTANTE - BUSCA NA WEB:
+// chatWebSearchInstructions vale quando a resposta usa a busca na web do Gemini
+const chatWebSearchInstructions = `IMPORTANTE - BUSCA NA WEB:
 - Você TEM acesso ao Google Search (ferramenta de busca na web) habilitada.
 - SEMPRE use o Google Search para buscar informações atualizadas quando a pergunta for sobre:
   * Eventos recentes (últimos meses ou semanas)
@@ -923,6 +1103,22 @@ IMPORTANTE - BUSCA NA WEB:
   * Dados que precisam ser verificados e atualizados
 - Use o Google Search automaticamente quando detectar que a informação pode estar desatualizada.
 - Priorize informações encontradas na web sobre informações do seu conhecimento pré-treinado quando se tratar de eventos recentes.
+- SEMPRE busque na web informações sobre eventos recentes, notícias atuais ou informações que podem ter mudado.`
+
+// chatToolInstructions vale quando a pergunta ativa as ferramentas de dados
+// oficiais; o Gemini não combina ferramentas com a busca na web
+const chatToolInstructions = `IMPORTANTE - FERRAMENTAS DE DADOS OFICIAIS:
+- Nesta pergunta a busca na web NÃO está disponível. Você tem ferramentas (funções) que consultam bases oficiais: Câmara, Senado, TSE, Planalto e Diário Oficial da União.
+- Chame as ferramentas sempre que a resposta depender desses dados e baseie a resposta no que elas devolverem.
+- Se uma informação recente não estiver nos resultados das ferramentas nem nas informações em tempo real da mensagem, diga que não foi possível verificá-la agora em vez de supor.`
+
+// chatInstructionsTemplate são as instruções do sistema do chat; recebe a data,
+// o ano, a seção de busca na web ou de ferramentas, o ano de novo e chatSourceInstructions
+const chatInstructionsTemplate = `Você é um chatbot político neutro e informativo para o público brasileiro.
+
+DATA ATUAL: A data atual é %s (ano %d). Use esta data como referência ao responder sobre eventos recentes, atuais ou futuros.
+
+%s
 
 Princípios:
 - Seja factual e forneça informações detalhadas sobre o tema perguntado.
@@ -930,7 +1126,6 @@ Princípios:
 - Não faça persuasão política personalizada. Não promova ou desincentive votos.
 - Se houver desinformação potencial, aponte com respeito e ofereça verificação.
 - Use a data atual para contextualizar eventos e informações temporais.
-- BUSQUE informações atualizadas na web quando necessário, especialmente para eventos recentes.
 
 CAPACIDADES ESPECIAIS:
 - Este sistema POSSUI capacidade de gerar gráficos hexagonais automaticamente para análise de perfis políticos.
@@ -942,9 +1137,51 @@ Formato:
 - Forneça contexto histórico e informações completas sobre o tema.
 - Se a pergunta for sobre análise/perfil de um político com solicitação de gráfico, forneça informações contextuais e deixe claro que o gráfico será apresentado logo em seguida.
 - Quando mencionar datas, use o ano atual (%d) como referência quando apropriado.
-- SEMPRE busque na web informações sobre eventos recentes, notícias atuais ou informações que podem ter mudado.
 
-%s`, currentDate, currentYear, currentYear, SYSTEM_INSTRUCTIONS[strings.Index(SYSTEM_INSTRUCTIONS, "IMPORTANTE - Links de fontes oficiais:"):])
+%s`
+
+// chatSourceInstructions é o trecho de SYSTEM_INSTRUCTIONS com os links das fontes oficiais
+var chatSourceInstructions = SYSTEM_INSTRUCTIONS[strings.Index(SYSTEM_INSTRUCTIONS, "IMPORTANTE - Links de fontes oficiais:"):]
+
+// buildChatInstructions monta as instruções do sistema do chat com a data
+// atual e a seção de busca na web ou de ferramentas
+func buildChatInstructions(now time.Time, withTools bool) string {
+	currentYear := now.Year()
+	currentDate := now.Format("02 de January de 2006")
+	searchInstructions := chatWebSearchInstructions
+	if withTools {
+		searchInstructions = chatToolInstructions
+	}
+	return fmt.Sprintf(chatInstructionsTemplate, currentDate, currentYear, searchInstructions, currentYear, chatSourceInstructions)
+}
+
+func handleChat(w http.ResponseWriter, r *http.Request) {
+	var req ChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error": "Erro ao decodificar JSON"}`, http.StatusBadRequest)
+		return
+	}
+
+	if req.Message == "" {
+		http.Error(w, `{"error": "Campo 'message' é obrigatório"}`, http.StatusBadRequest)
+		return
+	}
+
+	recordChatQuestion(req.Message)
+
+	cacheKey := generateCacheKey(req.Message, req.Context)
+	if cachedResp, found := cache.Get(cacheKey); found {
+		cachedResp.Cached = true
+		json.NewEncoder(w).Encode(cachedResp)
+		return
+	}
+
+	needsRealTime := searchRealTimeInfo(req.Message)
+
+	// Com ferramentas de dados oficiais a busca na web fica desativada, e as
+	// instruções precisam dizer isso ao modelo
+	tools := selectChatTools(req.Message)
+	dynamicInstructions := buildChatInstructions(time.Now(), len(tools) > 0)
 
 	contents := []GeminiContent{}
 	contents = append(contents, GeminiContent{
@@ -1009,6 +1246,9 @@ Formato:
 					}
 				}
 
+				if resultado.Texto != "" {
+					realTimeContext.WriteString(fmt.Sprintf("   Texto oficial (cite literalmente):\n%s\n", resultado.Texto))
+				}
 				if resultado.Nota != "" {
 					realTimeContext.WriteString(fmt.Sprintf("   Nota: %s\n", resultado.Nota))
 				}
@@ -1026,35 +1266,45 @@ Formato:
 		}
 	}
 
+	// Artigos da Constituição e das leis do corpus local relacionados à pergunta
+	enhancedMessage += legalContext(req.Message)
+
 	contents = append(contents, GeminiContent{
 		Role:  "user",
 		Parts: []GeminiPart{{Text: enhancedMessage}},
 	})
 
-	geminiResp, err := callGeminiAPI(contents)
+	var geminiResp *GeminiResponse
+	var err error
+	if len(tools) > 0 {
+		geminiResp, err = callGeminiWithTools(r.Context(), contents, tools)
+	} else {
+		geminiResp, err = callGeminiAPI(contents)
+	}
 	if err != nil {
 		log.Printf("Erro na API Gemini: %v", err)
 		http.Error(w, fmt.Sprintf(`{"error": "Erro na API Gemini", "detail": "%s"}`, err.Error()), http.StatusInternalServerError)
 		return
 	}
 
-	var reply string
-	if len(geminiResp.Candidates) > 0 && len(geminiResp.Candidates[0].Content.Parts) > 0 {
-		reply = geminiResp.Candidates[0].Content.Parts[0].Text
-	} else {
+	reply := geminiResp.Text()
+	if reply == "" {
 		reply = "Não consegui gerar uma resposta."
 	}
 
 	timestamp := time.Now().Format("02 de January de 2006 às 15:04")
 	chatResp := &ChatResponse{
-		Reply:     reply,
-		Timestamp: timestamp,
-		RealTime:  needsRealTime,
+		Reply:         reply,
+		Timestamp:     timestamp,
+		RealTime:      needsRealTime,
+		Model:         geminiModel,
+		PromptVersion: promptVersion,
 	}
 
 	if !needsRealTime {
 		cache.Set(cacheKey, chatResp)
 	}
+	recordChatAnswer(reply)
 
 	log.Printf("[%s] Pergunta: %s...", timestamp, truncateString(req.Message, 100))
 	log.Printf("[%s] Resposta: %s...", timestamp, truncateString(reply, 100))
@@ -1071,6 +1321,10 @@ func handleHealth(w http.ResponseWriter, r *http.Request) {
 			MaxAge: 5 * time.Minute,
 		},
 	}
+	if npsOutbox != nil {
+		stats := npsOutbox.Stats()
+		resp.NPSOutbox = &stats
+	}
 	json.NewEncoder(w).Encode(resp)
 }
 
@@ -1103,6 +1357,7 @@ func handleSources(w http.ResponseWriter, r *http.Request) {
 func handleCacheClear(w http.ResponseWriter, r *http.Request) {
 	beforeSize := cache.Size()
 	cache.Clear()
+	profileCache.Clear()
 
 	resp := CacheClearResponse{
 		Message:    "Cache limpo com sucesso",
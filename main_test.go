@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPromptVersionCoversSentInstructions(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, brasilia)
+	for _, withTools := range []bool{false, true} {
+		instructions := buildChatInstructions(now, withTools)
+		if strings.Contains(instructions, "%!") || !strings.HasSuffix(instructions, chatSourceInstructions) {
+			t.Errorf("instruções mal montadas (ferramentas=%v)", withTools)
+		}
+	}
+
+	// Mudar qualquer trecho enviado muda a versão
+	parts := []string{chatInstructionsTemplate, chatWebSearchInstructions, chatToolInstructions, chatSourceInstructions}
+	for i := range parts {
+		changed := append([]string(nil), parts...)
+		changed[i] += " "
+		if instructionsVersion(changed...) == promptVersion {
+			t.Errorf("alterar o trecho %d não mudou a versão do prompt", i)
+		}
+	}
+}
+
+func TestNPSSubmitKeepsMissingContextEmpty(t *testing.T) {
+	store, err := NewNPSStore(filepath.Join(t.TempDir(), "nps.jsonl"))
+	if err != nil {
+		t.Fatalf("NewNPSStore: %v", err)
+	}
+	defer func(previous NPSStoreInterface) { npsStore = previous }(npsStore)
+	npsStore = store
+
+	r := httptest.NewRequest("POST", "/api/nps/responses", strings.NewReader(`{"score": 8}`))
+	w := httptest.NewRecorder()
+	handleNPSSubmit(w, r)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, esperava 201: %s", w.Code, w.Body.String())
+	}
+
+	page, err := store.List(context.Background(), NPSListQuery{})
+	if err != nil || len(page.Responses) != 1 {
+		t.Fatalf("List = %v, %v; esperava uma resposta", page.Responses, err)
+	}
+	// Sem model e promptVersion, a resposta vai para "não informado" nas estatísticas
+	if got := page.Responses[0]; got.Model != "" || got.PromptVersion != "" {
+		t.Errorf("model = %q, promptVersion = %q; esperava vazios", got.Model, got.PromptVersion)
+	}
+}
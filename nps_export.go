@@ -0,0 +1,334 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"chat-bot/internal/adminauth"
+	"chat-bot/internal/audit"
+	"chat-bot/internal/xlsx"
+)
+
+const (
+	// auditFilePath guarda a auditoria quando as respostas NPS ficam no arquivo local
+	auditFilePath = "data/audit-log.json"
+	// auditCollection guarda a auditoria quando as respostas NPS ficam no Firestore
+	auditCollection = "audit_log"
+	// auditWriteTimeout limita cada gravação no log de auditoria
+	auditWriteTimeout = 30 * time.Second
+	// auditActionNPSExport identifica as exportações no registro de auditoria
+	auditActionNPSExport = "nps.export"
+	// npsExportTimeout limita a duração de uma exportação completa
+	npsExportTimeout = 5 * time.Minute
+	// npsExportReasonSep separa os motivos, achatados em uma única coluna
+	npsExportReasonSep = "; "
+	defaultAuditLimit  = 100
+	maxAuditLimit      = 1000
+)
+
+var auditStore audit.Log
+
+// openAuditLog grava a auditoria no mesmo armazenamento das respostas NPS:
+// com Firestore ou SQLite, todas as instâncias veem as mesmas exportações
+func openAuditLog(ctx context.Context) (audit.Log, error) {
+	switch store := npsStore.(type) {
+	case *NPSStoreFirestore:
+		return audit.NewFirestoreLog(store.firestoreService.GetClient(), auditCollection), nil
+	case *NPSStoreSQLite:
+		sqliteLog, err := audit.NewSQLiteLog(ctx, store.db)
+		if err != nil {
+			return nil, err
+		}
+		return sqliteLog, nil
+	}
+	fileLog, err := audit.NewStore(auditFilePath)
+	if err != nil {
+		return nil, err
+	}
+	return fileLog, nil
+}
+
+// npsExportHeader são as colunas do CSV e da planilha
+var npsExportHeader = []string{"ID", "Data de envio", "Nota", "Classificação", "Motivos", "Comentário", "Sessão", "Conversa", "Modelo", "Versão do prompt", "Perguntas"}
+
+// utf8BOM faz o Excel abrir o CSV como UTF-8 em vez de Windows-1252
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// npsExportFormat descreve um formato de exportação
+type npsExportFormat struct {
+	contentType string
+	extension   string
+}
+
+var npsExportFormats = map[string]npsExportFormat{
+	"csv":   {contentType: "text/csv; charset=utf-8", extension: "csv"},
+	"jsonl": {contentType: "application/x-ndjson; charset=utf-8", extension: "jsonl"},
+	"xlsx":  {contentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", extension: "xlsx"},
+}
+
+// npsRowWriter escreve as respostas em um formato; flush envia ao cliente o que já foi escrito
+type npsRowWriter interface {
+	write(NPSResponse) error
+	flush() error
+	close() error
+}
+
+// npsExportRow formata uma resposta nas colunas da exportação, com a data no horário de Brasília
+func npsExportRow(r NPSResponse) (submittedAt string, reasons string) {
+	submittedAt = r.SubmittedAt
+	if parsed, err := time.Parse(time.RFC3339, r.SubmittedAt); err == nil {
+		submittedAt = parsed.In(brasilia).Format("2006-01-02 15:04:05")
+	}
+	return submittedAt, strings.Join(r.Reasons, npsExportReasonSep)
+}
+
+type npsCSVWriter struct{ csv *csv.Writer }
+
+// newNPSCSVWriter usa ponto e vírgula como separador, o padrão do Excel em português
+func newNPSCSVWriter(out io.Writer, bom bool) (*npsCSVWriter, error) {
+	if bom {
+		if _, err := out.Write(utf8BOM); err != nil {
+			return nil, err
+		}
+	}
+	w := csv.NewWriter(out)
+	w.Comma = ';'
+	w.UseCRLF = true
+	return &npsCSVWriter{csv: w}, w.Write(npsExportHeader)
+}
+
+// csvSafe impede que o Excel interprete como fórmula um texto enviado pelo
+// usuário: campos que começam com =, +, -, @, tabulação ou CR ganham um apóstrofo
+func csvSafe(field string) string {
+	if field != "" && strings.ContainsRune("=+-@\t\r", rune(field[0])) {
+		return "'" + field
+	}
+	return field
+}
+
+func (w *npsCSVWriter) write(r NPSResponse) error {
+	submittedAt, reasons := npsExportRow(r)
+	questions := ""
+	if r.QuestionCount > 0 {
+		questions = strconv.Itoa(r.QuestionCount)
+	}
+	return w.csv.Write([]string{r.ID, submittedAt, strconv.Itoa(r.Score), r.Classification, csvSafe(reasons), csvSafe(r.Feedback),
+		csvSafe(r.SessionID), csvSafe(r.ConversationID), csvSafe(r.Model), csvSafe(r.PromptVersion), questions})
+}
+
+func (w *npsCSVWriter) flush() error {
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+func (w *npsCSVWriter) close() error { return w.flush() }
+
+type npsJSONLWriter struct{ encoder *json.Encoder }
+
+func (w *npsJSONLWriter) write(r NPSResponse) error { return w.encoder.Encode(r) }
+func (w *npsJSONLWriter) flush() error              { return nil }
+func (w *npsJSONLWriter) close() error              { return nil }
+
+type npsXLSXWriter struct{ sheet *xlsx.Writer }
+
+func newNPSXLSXWriter(out io.Writer) (*npsXLSXWriter, error) {
+	sheet, err := xlsx.NewWriter(out, "Respostas NPS")
+	if err != nil {
+		return nil, err
+	}
+	return &npsXLSXWriter{sheet: sheet}, sheet.WriteHeader(npsExportHeader)
+}
+
+func (w *npsXLSXWriter) write(r NPSResponse) error {
+	submittedAt, reasons := npsExportRow(r)
+	var questions interface{} = ""
+	if r.QuestionCount > 0 {
+		questions = r.QuestionCount
+	}
+	return w.sheet.WriteRow([]interface{}{r.ID, submittedAt, r.Score, r.Classification, reasons, r.Feedback,
+		r.SessionID, r.ConversationID, r.Model, r.PromptVersion, questions})
+}
+
+func (w *npsXLSXWriter) flush() error { return w.sheet.Flush() }
+func (w *npsXLSXWriter) close() error { return w.sheet.Close() }
+
+// exportNPS percorre todas as páginas da consulta e escreve as respostas; devolve quantas foram escritas
+func exportNPS(ctx context.Context, q NPSListQuery, out npsRowWriter, flusher http.Flusher) (int, int, error) {
+	q.Limit = maxNPSPageSize
+	q.Cursor = ""
+	count, skipped := 0, 0
+	for {
+		page, err := npsStore.List(ctx, q)
+		if err != nil {
+			return count, skipped, err
+		}
+		skipped += page.Skipped
+		for _, response := range page.Responses {
+			if err := out.write(response); err != nil {
+				return count, skipped, err
+			}
+			count++
+		}
+		if err := out.flush(); err != nil {
+			return count, skipped, err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		if page.NextCursor == "" {
+			return count, skipped, out.close()
+		}
+		q.Cursor = page.NextCursor
+	}
+}
+
+// handleNPSExport envia as respostas em CSV, JSONL ou XLSX com os mesmos filtros da listagem
+// (limit e cursor são ignorados: a exportação traz todas as páginas). A exportação é
+// registrada no log de auditoria antes de começar e só acontece se o registro for gravado.
+func handleNPSExport(w http.ResponseWriter, r *http.Request) {
+	if npsStore == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "armazenamento de pesquisas indisponível")
+		return
+	}
+	params := r.URL.Query()
+	formatName := strings.ToLower(strings.TrimSpace(params.Get("format")))
+	if formatName == "" {
+		formatName = "csv"
+	}
+	format, ok := npsExportFormats[formatName]
+	if !ok {
+		writeJSONError(w, http.StatusBadRequest, "format deve ser csv, jsonl ou xlsx")
+		return
+	}
+	bom := true
+	if raw := strings.TrimSpace(params.Get("bom")); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "bom deve ser true ou false")
+			return
+		}
+		bom = parsed
+	}
+	params.Del("cursor")
+	params.Del("limit")
+	r.URL.RawQuery = params.Encode()
+	q, err := parseNPSListQuery(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Sem o registro de auditoria não há exportação
+	if auditStore == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "log de auditoria indisponível")
+		return
+	}
+	entry, err := recordNPSExport(npsExportAuditEntry(r, formatName))
+	if err != nil {
+		log.Printf("erro ao registrar exportação NPS no log de auditoria: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "não foi possível registrar a exportação no log de auditoria")
+		return
+	}
+
+	filename := fmt.Sprintf("nps-respostas-%s.%s", time.Now().In(brasilia).Format("20060102-150405"), format.extension)
+	w.Header().Set("Content-Type", format.contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	w.Header().Set("Cache-Control", "no-store")
+
+	var out npsRowWriter
+	switch formatName {
+	case "csv":
+		out, err = newNPSCSVWriter(w, bom)
+	case "jsonl":
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		out = &npsJSONLWriter{encoder: encoder}
+	case "xlsx":
+		out, err = newNPSXLSXWriter(w)
+	}
+
+	count, skipped := 0, 0
+	if err == nil {
+		ctx, cancel := context.WithTimeout(r.Context(), npsExportTimeout)
+		defer cancel()
+		flusher, _ := w.(http.Flusher)
+		count, skipped, err = exportNPS(ctx, q, out, flusher)
+	}
+	if err != nil {
+		// O cabeçalho já foi enviado: o arquivo fica incompleto e o erro vai para o log e a auditoria
+		log.Printf("erro ao exportar respostas NPS (%s): %v", formatName, err)
+	}
+
+	// A exportação já foi registrada ao começar; aqui só se completa o registro
+	finishedAt := time.Now().UTC()
+	entry.Count, entry.FinishedAt = count, &finishedAt
+	if skipped > 0 {
+		entry.Details["skipped"] = strconv.Itoa(skipped)
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	log.Printf("📤 exportação NPS (%s) por %s: %d respostas", formatName, entry.ActorID, count)
+	if _, err := recordNPSExport(entry); err != nil {
+		log.Printf("⚠️  não foi possível concluir o registro %s da exportação NPS: %v", entry.ID, err)
+	}
+}
+
+// npsExportAuditEntry descreve quem exporta, em qual formato e com quais filtros
+func npsExportAuditEntry(r *http.Request, format string) audit.Entry {
+	identity, _ := adminauth.FromContext(r.Context())
+	details := map[string]string{"format": format}
+	for key, values := range r.URL.Query() {
+		if key != "format" && len(values) > 0 {
+			details[key] = values[0]
+		}
+	}
+	return audit.Entry{
+		Action:     auditActionNPSExport,
+		ActorID:    identity.UID,
+		ActorEmail: identity.Email,
+		Source:     identity.Source,
+		RemoteAddr: clientIP(r),
+		Details:    details,
+	}
+}
+
+// recordNPSExport grava o registro com prazo próprio: a conclusão é
+// registrada mesmo que o cliente tenha desconectado
+func recordNPSExport(entry audit.Entry) (audit.Entry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), auditWriteTimeout)
+	defer cancel()
+	return auditStore.Record(ctx, entry)
+}
+
+// handleAdminAudit lista o log de auditoria, mais recentes primeiro (filtro opcional action)
+func handleAdminAudit(w http.ResponseWriter, r *http.Request) {
+	if auditStore == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "log de auditoria indisponível")
+		return
+	}
+	params := r.URL.Query()
+	limit, err := intParam(params, "limit", 1, maxAuditLimit)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if limit == 0 {
+		limit = defaultAuditLimit
+	}
+	entries, err := auditStore.Recent(r.Context(), strings.TrimSpace(params.Get("action")), limit)
+	if err != nil {
+		log.Printf("erro ao ler o log de auditoria: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "não foi possível carregar o log de auditoria no momento")
+		return
+	}
+	json.NewEncoder(w).Encode(map[string][]audit.Entry{"entries": entries})
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"chat-bot/internal/audit"
+)
+
+func TestNPSCSVWriterEscapesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := newNPSCSVWriter(&buf, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := NPSResponse{
+		ID:             "r1",
+		SubmittedAt:    "2026-10-16T15:00:00Z",
+		Score:          0,
+		Classification: "detrator",
+		Reasons:        []string{"-erro", "lento"},
+		Feedback:       `=HYPERLINK("http://exemplo.com","clique")`,
+		SessionID:      "@sessao",
+		ConversationID: "+123",
+		Model:          "\tmodelo",
+		PromptVersion:  "\rv1",
+		QuestionCount:  3,
+	}
+	if err := w.write(response); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.write(NPSResponse{ID: "r2", Score: 10, Feedback: "Ótimo, 10/10"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader := csv.NewReader(&buf)
+	reader.Comma = ';'
+	rows, err := reader.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("esperava cabeçalho e 2 linhas, obteve %d", len(rows))
+	}
+	escaped := map[int]string{
+		4: "'-erro; lento",
+		5: `'=HYPERLINK("http://exemplo.com","clique")`,
+		6: "'@sessao",
+		7: "'+123",
+		8: "'\tmodelo",
+		// Com UseCRLF o CR solto é descartado na escrita, mas o apóstrofo fica
+		9: "'v1",
+	}
+	for column, want := range escaped {
+		if got := rows[1][column]; got != want {
+			t.Errorf("coluna %s = %q, esperava %q", npsExportHeader[column], got, want)
+		}
+	}
+	if rows[1][2] != "0" || rows[1][10] != "3" || rows[2][5] != "Ótimo, 10/10" {
+		t.Errorf("campos sem fórmula não deveriam mudar: %q %q", rows[1], rows[2])
+	}
+}
+
+// failingAudit simula um log de auditoria que não aceita gravações
+type failingAudit struct{}
+
+func (failingAudit) Record(ctx context.Context, e audit.Entry) (audit.Entry, error) {
+	return e, errors.New("indisponível")
+}
+
+func (failingAudit) Recent(ctx context.Context, action string, limit int) ([]audit.Entry, error) {
+	return nil, errors.New("indisponível")
+}
+
+func TestNPSExportRequiresAudit(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewNPSStore(filepath.Join(dir, "nps-responses.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if err := store.Add(NPSResponse{ID: "r1", Score: 9, SubmittedAt: "2026-10-16T12:00:00Z"}); err != nil {
+		t.Fatal(err)
+	}
+	previousStore, previousAudit := npsStore, auditStore
+	defer func() { npsStore, auditStore = previousStore, previousAudit }()
+	npsStore = store
+
+	export := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handleNPSExport(rec, httptest.NewRequest(http.MethodGet, "/api/nps/export?format=jsonl&classification=promotor", nil))
+		return rec
+	}
+
+	auditStore = failingAudit{}
+	rec := export()
+	if rec.Code != http.StatusInternalServerError || rec.Header().Get("Content-Disposition") != "" || strings.Contains(rec.Body.String(), "r1") {
+		t.Fatalf("sem auditoria a exportação deveria falhar antes de enviar dados: %d %q", rec.Code, rec.Body.String())
+	}
+
+	log, err := audit.NewStore(filepath.Join(dir, "audit-log.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	auditStore = log
+	rec = export()
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"id":"r1"`) {
+		t.Fatalf("exportação: %d %q", rec.Code, rec.Body.String())
+	}
+	entries, err := log.Recent(context.Background(), auditActionNPSExport, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("esperava um registro por exportação, obteve %+v", entries)
+	}
+	if e := entries[0]; e.Count != 1 || e.FinishedAt == nil || e.Details["format"] != "jsonl" || e.Details["classification"] != "promotor" {
+		t.Errorf("registro de auditoria incompleto: %+v", e)
+	}
+}
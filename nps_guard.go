@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"chat-bot/internal/config"
+	"chat-bot/internal/npsguard"
+)
+
+const (
+	npsStatusOK      = "ok"
+	npsStatusFlagged = "flagged"
+
+	// Limites de envio por IP e por aparelho (impressão digital)
+	npsIPLimit      = 20
+	npsIPWindow     = time.Hour
+	npsClientLimit  = 5
+	npsClientWindow = 24 * time.Hour
+	// npsTokenIPLimit limita a emissão de tokens por IP
+	npsTokenIPLimit = 60
+	npsTokenTTL     = 12 * time.Hour
+	// npsTokenMinAge é o tempo mínimo entre exibir a pesquisa e enviá-la
+	npsTokenMinAge = 3 * time.Second
+	// npsDuplicateWindow: um segundo envio do mesmo IP nesse intervalo é marcado como duplicado
+	npsDuplicateWindow = 24 * time.Hour
+	// npsMaxClockSkew é a diferença máxima aceita entre o horário do navegador e o do servidor
+	npsMaxClockSkew = 10 * time.Minute
+
+	// Motivos da marcação de suspeita
+	npsFlagDuplicate    = "duplicada"
+	npsFlagNoToken      = "token_ausente"
+	npsFlagTokenExpired = "token_expirado"
+	npsFlagTokenReused  = "token_reutilizado"
+	npsFlagTooFast      = "resposta_rapida"
+)
+
+// npsGuardState reúne os limites, o emissor de tokens e o sal das impressões digitais
+type npsGuardState struct {
+	ipLimiter      *npsguard.Limiter
+	clientLimiter  *npsguard.Limiter
+	tokenIPLimiter *npsguard.Limiter
+	// signer é nil quando NPS_TOKEN_SECRET não está configurado
+	signer       *npsguard.Signer
+	requireToken bool
+	salt         []byte
+}
+
+var npsGuard = newNPSGuard(nil, false)
+
+func newNPSGuard(secret []byte, requireToken bool) *npsGuardState {
+	g := &npsGuardState{
+		ipLimiter:      npsguard.NewLimiter(npsIPLimit, npsIPWindow),
+		clientLimiter:  npsguard.NewLimiter(npsClientLimit, npsClientWindow),
+		tokenIPLimiter: npsguard.NewLimiter(npsTokenIPLimit, npsIPWindow),
+		salt:           []byte("nps"),
+	}
+	if len(secret) > 0 {
+		g.signer = npsguard.NewSigner(secret, npsTokenTTL, npsTokenMinAge)
+		g.requireToken = requireToken
+		g.salt = secret
+	}
+	return g
+}
+
+// setupNPSGuard ativa os tokens da pesquisa quando NPS_TOKEN_SECRET está configurado;
+// com NPS_REQUIRE_TOKEN=true, envios sem token são recusados em vez de marcados
+func setupNPSGuard(cfg *config.Config) {
+	requireToken := strings.EqualFold(strings.TrimSpace(cfg.NPSRequireToken), "true")
+	npsGuard = newNPSGuard([]byte(cfg.NPSTokenSecret), requireToken)
+	switch {
+	case npsGuard.signer == nil:
+		log.Println("⚠️  NPS_TOKEN_SECRET não configurado: respostas NPS aceitas sem token da pesquisa")
+	case requireToken:
+		log.Println("✅ Pesquisa NPS exige token assinado")
+	default:
+		log.Println("✅ Pesquisa NPS com token assinado (envios sem token são marcados como suspeitos)")
+	}
+}
+
+// npsFingerprint identifica o aparelho pelo IP e pelos cabeçalhos do navegador.
+// O X-Client-ID enviado pelo frontend fica de fora: como é escolhido pelo
+// cliente, trocá-lo a cada envio escaparia do limite diário e da marcação de duplicadas
+func npsFingerprint(r *http.Request) string {
+	return npsguard.Fingerprint(npsGuard.salt, "ip:"+clientIP(r), r.UserAgent(), r.Header.Get("Accept-Language"))
+}
+
+// npsIPKey identifica só o IP de origem, para a marcação de duplicadas não
+// depender de cabeçalhos que o cliente controla
+func npsIPKey(r *http.Request) string {
+	return npsguard.Fingerprint(npsGuard.salt, "ip:"+clientIP(r))
+}
+
+// allow aplica os limites por IP e por cliente
+func (g *npsGuardState) allow(ip, fingerprint string, now time.Time) (bool, time.Duration) {
+	if ok, retryAfter := g.ipLimiter.Allow(ip, now); !ok {
+		log.Printf("⚠️  envio NPS recusado: limite por IP atingido (%s)", ip)
+		return false, retryAfter
+	}
+	if ok, retryAfter := g.clientLimiter.Allow(fingerprint, now); !ok {
+		log.Printf("⚠️  envio NPS recusado: limite por aparelho atingido (%s)", fingerprint)
+		return false, retryAfter
+	}
+	return true, 0
+}
+
+// check confere o token da pesquisa e procura envios do mesmo IP na janela
+// de duplicidade. Devolve os motivos de suspeita; erro só para tokens
+// forjados ou de outro cliente e para envios sem token quando ele é exigido.
+func (g *npsGuardState) check(ctx context.Context, fingerprint, ipKey, token string, now time.Time) ([]string, error) {
+	var flags []string
+	if g.signer != nil {
+		err := errors.New("token da pesquisa ausente")
+		if token != "" {
+			err = g.signer.Verify(token, fingerprint, now)
+		}
+		switch {
+		case err == nil:
+		case token == "" && !g.requireToken:
+			flags = append(flags, npsFlagNoToken)
+		case errors.Is(err, npsguard.ErrTokenExpired):
+			flags = append(flags, npsFlagTokenExpired)
+		case errors.Is(err, npsguard.ErrTokenReused):
+			flags = append(flags, npsFlagTokenReused)
+		case errors.Is(err, npsguard.ErrTokenTooFast):
+			flags = append(flags, npsFlagTooFast)
+		default:
+			return nil, err
+		}
+	}
+
+	// A impressão digital inclui o IP, então procurar pelo IP também cobre o mesmo aparelho
+	recent, err := npsStore.List(ctx, NPSListQuery{IPKey: ipKey, From: now.Add(-npsDuplicateWindow), Limit: 1})
+	if err != nil {
+		// A verificação de duplicidade não deve impedir o registro da resposta
+		log.Printf("⚠️  não foi possível verificar respostas NPS duplicadas: %v", err)
+	} else if len(recent.Responses) > 0 {
+		flags = append(flags, npsFlagDuplicate)
+	}
+	return flags, nil
+}
+
+// handleNPSToken emite o token da pesquisa quando ela é exibida ao usuário
+func handleNPSToken(w http.ResponseWriter, r *http.Request) {
+	if npsGuard.signer == nil {
+		json.NewEncoder(w).Encode(map[string]interface{}{"enabled": false})
+		return
+	}
+	now := time.Now().UTC()
+	if ok, retryAfter := npsGuard.tokenIPLimiter.Allow(clientIP(r), now); !ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+		writeJSONError(w, http.StatusTooManyRequests, "muitas solicitações; tente novamente mais tarde")
+		return
+	}
+	token, expiresAt := npsGuard.signer.Issue(npsFingerprint(r), now)
+	json.NewEncoder(w).Encode(map[string]interface{}{"enabled": true, "token": token, "expiresAt": expiresAt})
+}
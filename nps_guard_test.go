@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClientIP(t *testing.T) {
+	tests := []struct {
+		name      string
+		trusted   bool
+		forwarded []string
+		want      string
+	}{
+		{"sem proxy", true, nil, "192.0.2.1"},
+		{"só o balanceador", true, []string{"203.0.113.7"}, "203.0.113.7"},
+		{"endereço forjado pelo cliente", true, []string{"1.2.3.4, 203.0.113.7"}, "203.0.113.7"},
+		{"cabeçalho repetido", true, []string{"1.2.3.4", "5.6.7.8, 203.0.113.7"}, "203.0.113.7"},
+		{"último endereço inválido", true, []string{"203.0.113.7, lixo"}, "192.0.2.1"},
+		{"proxy não confiável", false, []string{"203.0.113.7"}, "192.0.2.1"},
+	}
+	defer func(previous bool) { trustForwardedFor = previous }(trustForwardedFor)
+	for _, tt := range tests {
+		trustForwardedFor = tt.trusted
+		r := httptest.NewRequest("GET", "/", nil)
+		for _, value := range tt.forwarded {
+			r.Header.Add("X-Forwarded-For", value)
+		}
+		if got := clientIP(r); got != tt.want {
+			t.Errorf("%s: clientIP = %q, esperava %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNPSFingerprintIgnoresClientID(t *testing.T) {
+	request := func(remoteAddr, clientID string) (string, string) {
+		r := httptest.NewRequest("GET", "/api/nps/token", nil)
+		r.RemoteAddr = remoteAddr
+		r.Header.Set("X-Client-ID", clientID)
+		r.Header.Set("User-Agent", "Mozilla")
+		return npsFingerprint(r), npsIPKey(r)
+	}
+	fingerprint, ipKey := request("203.0.113.7:1234", "cliente-1")
+	otherFingerprint, otherIPKey := request("203.0.113.8:1234", "cliente-1")
+	if fingerprint == otherFingerprint || ipKey == otherIPKey {
+		t.Errorf("IPs diferentes não deveriam ter a mesma impressão digital")
+	}
+	rotatedFingerprint, rotatedIPKey := request("203.0.113.7:1234", "cliente-2")
+	if fingerprint != rotatedFingerprint || ipKey != rotatedIPKey {
+		t.Errorf("trocar o X-Client-ID não deveria mudar a impressão digital")
+	}
+}
+
+func TestNPSGuardFlagsDuplicateIP(t *testing.T) {
+	store, err := NewNPSStore(filepath.Join(t.TempDir(), "nps.jsonl"))
+	if err != nil {
+		t.Fatalf("NewNPSStore: %v", err)
+	}
+	defer func(previous NPSStoreInterface) { npsStore = previous }(npsStore)
+	npsStore = store
+
+	now := time.Now().UTC()
+	guard := newNPSGuard(nil, false)
+	if err := store.Add(NPSResponse{Score: 9, SubmittedAt: now.Add(-time.Hour).Format(time.RFC3339), Fingerprint: "aparelho-1", IPKey: "ip-1"}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	// Outro navegador atrás do mesmo IP ainda conta como duplicado
+	flags, err := guard.check(context.Background(), "aparelho-2", "ip-1", "", now)
+	if err != nil || len(flags) != 1 || flags[0] != npsFlagDuplicate {
+		t.Errorf("mesmo IP: flags = %v, err = %v; esperava [%s]", flags, err, npsFlagDuplicate)
+	}
+	flags, err = guard.check(context.Background(), "aparelho-2", "ip-2", "", now)
+	if err != nil || len(flags) != 0 {
+		t.Errorf("outro IP: flags = %v, err = %v; esperava nenhuma", flags, err)
+	}
+}
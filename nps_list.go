@@ -0,0 +1,531 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+const (
+	defaultNPSPageSize = 50
+	maxNPSPageSize     = 200
+	// maxNPSScan limita os documentos lidos por página quando o filtro de
+	// comentário (aplicado em memória) descarta muitas respostas
+	maxNPSScan = 2000
+)
+
+var errInvalidNPSCursor = errors.New("cursor inválido")
+
+// NPSListQuery filtra e pagina as respostas, sempre das mais recentes para as mais antigas
+type NPSListQuery struct {
+	// Classification restringe a promotor, neutro ou detrator
+	Classification string
+	// MinScore e MaxScore limitam a nota; nil deixa o lado aberto
+	MinScore *int
+	MaxScore *int
+	// From (inclusivo) e To (exclusivo) limitam o submittedAt; zero deixa o lado aberto
+	From        time.Time
+	To          time.Time
+	HasFeedback *bool
+	Reason      string
+	// Fingerprint restringe às respostas de um mesmo cliente
+	Fingerprint string
+	// IPKey restringe às respostas de um mesmo IP de origem
+	IPKey string
+	// Status filtra por ok ou flagged (respostas suspeitas)
+	Status string
+	Cursor string
+	Limit  int
+}
+
+// NPSPage é uma página de respostas; NextCursor vazio indica o fim da lista
+type NPSPage struct {
+	Responses  []NPSResponse `json:"responses"`
+	NextCursor string        `json:"nextCursor,omitempty"`
+	// Skipped conta os documentos ignorados por estarem malformados
+	Skipped int `json:"skipped,omitempty"`
+}
+
+// npsCursor aponta a última resposta vista na ordem submittedAt desc, id desc
+type npsCursor struct {
+	SubmittedAt string
+	ID          string
+}
+
+func (c npsCursor) encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.SubmittedAt + "|" + c.ID))
+}
+
+func decodeNPSCursor(raw string) (*npsCursor, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, errInvalidNPSCursor
+	}
+	submittedAt, id, ok := strings.Cut(string(decoded), "|")
+	if !ok || submittedAt == "" || id == "" {
+		return nil, errInvalidNPSCursor
+	}
+	return &npsCursor{SubmittedAt: submittedAt, ID: id}, nil
+}
+
+// before informa se a resposta vem depois do cursor na ordem da listagem
+func (c *npsCursor) before(r NPSResponse) bool {
+	if c == nil {
+		return true
+	}
+	if r.SubmittedAt != c.SubmittedAt {
+		return r.SubmittedAt < c.SubmittedAt
+	}
+	return r.ID < c.ID
+}
+
+func newNPSID() string {
+	buf := make([]byte, 10)
+	if _, err := rand.Read(buf); err != nil {
+		return time.Now().UTC().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(buf)
+}
+
+// sortNPSResponses aplica a ordem comum aos dois armazenamentos: submittedAt
+// (RFC 3339 UTC, comparado como texto) e id, ambos decrescentes
+func sortNPSResponses(responses []NPSResponse) {
+	sort.Slice(responses, func(i, j int) bool {
+		if responses[i].SubmittedAt != responses[j].SubmittedAt {
+			return responses[i].SubmittedAt > responses[j].SubmittedAt
+		}
+		return responses[i].ID > responses[j].ID
+	})
+}
+
+// scoreRange combina a faixa de notas com a classificação pedida
+func (q NPSListQuery) scoreRange() (min, max int) {
+	min, max = 0, 10
+	if q.MinScore != nil {
+		min = *q.MinScore
+	}
+	if q.MaxScore != nil {
+		max = *q.MaxScore
+	}
+	switch q.Classification {
+	case npsSegmentDetractor:
+		max = minInt(max, 6)
+	case npsSegmentPassive:
+		min, max = maxInt(min, 7), minInt(max, 8)
+	case npsSegmentPromoter:
+		min = maxInt(min, 9)
+	}
+	return min, max
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// matches aplica todos os filtros a uma resposta
+func (q NPSListQuery) matches(r NPSResponse) bool {
+	min, max := q.scoreRange()
+	if r.Score < min || r.Score > max {
+		return false
+	}
+	if !q.From.IsZero() && r.SubmittedAt < q.From.UTC().Format(time.RFC3339) {
+		return false
+	}
+	if !q.To.IsZero() && r.SubmittedAt >= q.To.UTC().Format(time.RFC3339) {
+		return false
+	}
+	if q.HasFeedback != nil && (r.Feedback != "") != *q.HasFeedback {
+		return false
+	}
+	if q.Fingerprint != "" && r.Fingerprint != q.Fingerprint {
+		return false
+	}
+	if q.IPKey != "" && r.IPKey != q.IPKey {
+		return false
+	}
+	if q.Status != "" && r.status() != q.Status {
+		return false
+	}
+	if q.Reason != "" {
+		found := false
+		for _, reason := range r.Reasons {
+			if reason == q.Reason {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// npsPager monta uma página a partir das respostas já ordenadas
+type npsPager struct {
+	limit   int
+	page    NPSPage
+	scanned int
+	last    *npsCursor
+}
+
+func newNPSPager(limit int) *npsPager {
+	if limit <= 0 {
+		limit = defaultNPSPageSize
+	}
+	return &npsPager{limit: limit, page: NPSPage{Responses: []NPSResponse{}}}
+}
+
+// offer recebe a próxima resposta que passou nos filtros; devolve true quando a página está completa
+func (p *npsPager) offer(r NPSResponse) bool {
+	if len(p.page.Responses) == p.limit {
+		// Há ao menos mais uma resposta: a próxima página começa depois da última devolvida
+		last := p.page.Responses[len(p.page.Responses)-1]
+		p.page.NextCursor = npsCursor{SubmittedAt: last.SubmittedAt, ID: last.ID}.encode()
+		return true
+	}
+	p.page.Responses = append(p.page.Responses, r)
+	return false
+}
+
+// seen registra uma resposta lida (filtrada ou não); devolve true ao esgotar o limite de leitura
+func (p *npsPager) seen(r NPSResponse) bool {
+	p.scanned++
+	p.last = &npsCursor{SubmittedAt: r.SubmittedAt, ID: r.ID}
+	if p.scanned >= maxNPSScan {
+		// Página parcial: o cliente continua a busca a partir do último documento lido
+		p.page.NextCursor = p.last.encode()
+		return true
+	}
+	return false
+}
+
+// List devolve uma página das respostas gravadas no arquivo local. O índice
+// em memória dá a ordem, o período, a nota e o cliente; só as linhas que
+// passam nesses filtros são lidas do arquivo, a partir do cursor, até
+// completar a página.
+func (s *NPSStore) List(ctx context.Context, q NPSListQuery) (NPSPage, error) {
+	after, err := decodeNPSCursor(q.Cursor)
+	if err != nil {
+		return NPSPage{}, err
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	pager := newNPSPager(q.Limit)
+	if len(s.index) == 0 {
+		return pager.page, nil
+	}
+	file, err := os.Open(s.filePath)
+	if err != nil {
+		return NPSPage{}, err
+	}
+	defer file.Close()
+
+	// O índice vai da mais antiga para a mais recente: a listagem o percorre de trás para frente
+	end := len(s.index)
+	if !q.To.IsZero() {
+		to := q.To.UTC().Format(time.RFC3339)
+		end = sort.Search(end, func(i int) bool { return s.index[i].SubmittedAt >= to })
+	}
+	if after != nil {
+		end = sort.Search(end, func(i int) bool {
+			return !after.before(NPSResponse{SubmittedAt: s.index[i].SubmittedAt, ID: s.index[i].ID})
+		})
+	}
+	from := ""
+	if !q.From.IsZero() {
+		from = q.From.UTC().Format(time.RFC3339)
+	}
+	min, max := q.scoreRange()
+	for i := end - 1; i >= 0; i-- {
+		entry := s.index[i]
+		if entry.SubmittedAt < from {
+			break
+		}
+		if entry.Score < min || entry.Score > max || (q.Fingerprint != "" && entry.Fingerprint != q.Fingerprint) ||
+			(q.IPKey != "" && entry.IPKey != q.IPKey) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return NPSPage{}, err
+		}
+		response, err := readNPSEntry(file, entry)
+		if err != nil {
+			return NPSPage{}, fmt.Errorf("erro ao ler a resposta %s de %s: %w", entry.ID, s.filePath, err)
+		}
+		if q.matches(response) && pager.offer(response) {
+			break
+		}
+	}
+	return pager.page, nil
+}
+
+// List pagina as respostas no Firestore. Nota, classificação (convertida em
+// faixa de notas), motivo, cliente e período são filtrados na consulta; a
+// presença de comentário e o status (ausente nas respostas antigas) são
+// conferidos em memória, lendo lotes até completar a página.
+func (s *NPSStoreFirestore) List(ctx context.Context, q NPSListQuery) (NPSPage, error) {
+	after, err := decodeNPSCursor(q.Cursor)
+	if err != nil {
+		return NPSPage{}, err
+	}
+
+	query := s.firestoreService.GetClient().Collection(s.collection).Query
+	if min, max := q.scoreRange(); min > 0 || max < 10 {
+		scores := []int{}
+		for score := min; score <= max; score++ {
+			scores = append(scores, score)
+		}
+		if len(scores) == 0 {
+			return newNPSPager(q.Limit).page, nil
+		}
+		query = query.Where("score", "in", scores)
+	}
+	if q.Reason != "" {
+		query = query.Where("reasons", "array-contains", q.Reason)
+	}
+	if q.Fingerprint != "" {
+		query = query.Where("fingerprint", "==", q.Fingerprint)
+	}
+	if q.IPKey != "" {
+		query = query.Where("ipKey", "==", q.IPKey)
+	}
+	if !q.From.IsZero() {
+		query = query.Where("submittedAt", ">=", q.From.UTC().Format(time.RFC3339))
+	}
+	if !q.To.IsZero() {
+		query = query.Where("submittedAt", "<", q.To.UTC().Format(time.RFC3339))
+	}
+	query = query.OrderBy("submittedAt", firestore.Desc).OrderBy(firestore.DocumentID, firestore.Desc)
+
+	pager := newNPSPager(q.Limit)
+	batchSize := pager.limit + 1
+	for {
+		batch := query.Limit(batchSize)
+		if after != nil {
+			batch = batch.StartAfter(after.SubmittedAt, after.ID)
+		}
+		read, done, err := s.listBatch(ctx, batch, q, pager)
+		if err != nil {
+			return NPSPage{}, err
+		}
+		if done || read < batchSize {
+			return pager.page, nil
+		}
+		after = pager.last
+	}
+}
+
+// listBatch lê um lote da consulta; done indica página completa ou limite de leitura atingido
+func (s *NPSStoreFirestore) listBatch(ctx context.Context, query firestore.Query, q NPSListQuery, pager *npsPager) (read int, done bool, err error) {
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return read, false, nil
+		}
+		if err != nil {
+			return read, false, err
+		}
+		read++
+
+		response, ok := npsResponseFromDoc(doc)
+		if !ok {
+			pager.page.Skipped++
+			if pager.seen(NPSResponse{ID: doc.Ref.ID, SubmittedAt: getString(doc.Data(), "submittedAt")}) {
+				return read, true, nil
+			}
+			continue
+		}
+		if q.matches(response) && pager.offer(response) {
+			return read, true, nil
+		}
+		if pager.seen(response) {
+			return read, true, nil
+		}
+	}
+}
+
+// npsResponseFromDoc converte um documento do Firestore; documentos sem nota válida são registrados no log
+func npsResponseFromDoc(doc *firestore.DocumentSnapshot) (NPSResponse, bool) {
+	data := doc.Data()
+	score, ok := intValue(data["score"])
+	if !ok || score < 0 || score > 10 {
+		log.Printf("⚠️  resposta NPS %s ignorada: nota inválida (%v)", doc.Ref.ID, data["score"])
+		return NPSResponse{}, false
+	}
+	// Respostas anteriores ao registro do contexto da conversa não têm questionCount
+	questionCount, _ := intValue(data["questionCount"])
+	return NPSResponse{
+		ID:             doc.Ref.ID,
+		Score:          score,
+		Classification: getString(data, "classification"),
+		Reasons:        stringSlice(data["reasons"]),
+		Feedback:       getString(data, "feedback"),
+		SubmittedAt:    getString(data, "submittedAt"),
+		Fingerprint:    getString(data, "fingerprint"),
+		IPKey:          getString(data, "ipKey"),
+		Status:         getString(data, "status"),
+		FlagReasons:    stringSlice(data["flagReasons"]),
+		SessionID:      getString(data, "sessionId"),
+		ConversationID: getString(data, "conversationId"),
+		Model:          getString(data, "model"),
+		PromptVersion:  getString(data, "promptVersion"),
+		QuestionCount:  questionCount,
+	}, true
+}
+
+// npsDateParam lê uma data AAAA-MM-DD no horário de Brasília
+func npsDateParam(params url.Values, key string) (time.Time, error) {
+	raw := strings.TrimSpace(params.Get(key))
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	parsed, err := time.ParseInLocation(npsStatsDateLayout, raw, brasilia)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s deve estar no formato AAAA-MM-DD", key)
+	}
+	return parsed, nil
+}
+
+// parseNPSListQuery lê classification, minScore, maxScore, from, to (datas
+// inclusivas), hasFeedback, reason, fingerprint, status, cursor e limit
+func parseNPSListQuery(r *http.Request) (NPSListQuery, error) {
+	params := r.URL.Query()
+	q := NPSListQuery{Reason: strings.TrimSpace(params.Get("reason")), Cursor: strings.TrimSpace(params.Get("cursor"))}
+
+	switch classification := strings.ToLower(strings.TrimSpace(params.Get("classification"))); classification {
+	case "", npsSegmentPromoter, npsSegmentPassive, npsSegmentDetractor:
+		q.Classification = classification
+	default:
+		return q, fmt.Errorf("classification deve ser promotor, neutro ou detrator")
+	}
+
+	var err error
+	for key, target := range map[string]**int{"minScore": &q.MinScore, "maxScore": &q.MaxScore} {
+		if strings.TrimSpace(params.Get(key)) == "" {
+			continue
+		}
+		score, err := intParam(params, key, 0, 10)
+		if err != nil {
+			return q, err
+		}
+		*target = &score
+	}
+	if q.MinScore != nil && q.MaxScore != nil && *q.MinScore > *q.MaxScore {
+		return q, fmt.Errorf("minScore deve ser menor ou igual a maxScore")
+	}
+
+	switch status := strings.TrimSpace(params.Get("status")); status {
+	case "", npsStatusOK, npsStatusFlagged:
+		q.Status = status
+	default:
+		return q, fmt.Errorf("status deve ser ok ou flagged")
+	}
+	q.Fingerprint = strings.TrimSpace(params.Get("fingerprint"))
+
+	if q.From, err = npsDateParam(params, "from"); err != nil {
+		return q, err
+	}
+	if q.To, err = npsDateParam(params, "to"); err != nil {
+		return q, err
+	}
+	if !q.To.IsZero() {
+		q.To = q.To.AddDate(0, 0, 1)
+		if !q.From.IsZero() && !q.From.Before(q.To) {
+			return q, fmt.Errorf("from deve ser anterior ou igual a to")
+		}
+	}
+
+	if raw := strings.TrimSpace(params.Get("hasFeedback")); raw != "" {
+		hasFeedback, err := strconv.ParseBool(raw)
+		if err != nil {
+			return q, fmt.Errorf("hasFeedback deve ser true ou false")
+		}
+		q.HasFeedback = &hasFeedback
+	}
+
+	if q.Limit, err = intParam(params, "limit", 1, maxNPSPageSize); err != nil {
+		return q, err
+	}
+	if q.Limit == 0 {
+		q.Limit = defaultNPSPageSize
+	}
+	if _, err := decodeNPSCursor(q.Cursor); err != nil {
+		return q, err
+	}
+	return q, nil
+}
+
+func handleNPSList(w http.ResponseWriter, r *http.Request) {
+	if npsStore == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "armazenamento de pesquisas indisponível")
+		return
+	}
+	q, err := parseNPSListQuery(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := npsStore.List(r.Context(), q)
+	if err != nil {
+		log.Printf("erro ao listar respostas NPS: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "não foi possível carregar as respostas no momento")
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		log.Printf("erro ao codificar lista NPS: %v", err)
+	}
+}
+
+// withNPSContentIDs dá às respostas gravadas antes da paginação o hash do
+// conteúdo como id, para que reler o mesmo arquivo gere sempre os mesmos ids,
+// e mantém só a primeira resposta de cada id
+func withNPSContentIDs(responses []NPSResponse) []NPSResponse {
+	ids := make(map[string]bool, len(responses))
+	unique := responses[:0]
+	for _, response := range responses {
+		if response.ID == "" {
+			response.ID = npsContentKey(response)
+		}
+		if !ids[response.ID] {
+			ids[response.ID] = true
+			unique = append(unique, response)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"chat-bot/internal/services"
+
+	"cloud.google.com/go/firestore"
+)
+
+func TestNPSCursorRoundTrip(t *testing.T) {
+	cursor := npsCursor{SubmittedAt: "2026-10-16T12:00:00Z", ID: "a1b2"}
+	decoded, err := decodeNPSCursor(cursor.encode())
+	if err != nil || decoded == nil || *decoded != cursor {
+		t.Fatalf("decodeNPSCursor(encode()) = %+v, %v", decoded, err)
+	}
+	if decoded, err := decodeNPSCursor(""); decoded != nil || err != nil {
+		t.Errorf("cursor vazio deveria começar do início: %+v, %v", decoded, err)
+	}
+
+	for _, raw := range []string{
+		"não é base64!",
+		npsCursor{SubmittedAt: "2026-10-16T12:00:00Z"}.encode(),
+		npsCursor{ID: "a1b2"}.encode(),
+		"MjAyNi0xMC0xNlQxMjowMDowMFo", // sem separador
+	} {
+		if _, err := decodeNPSCursor(raw); !errors.Is(err, errInvalidNPSCursor) {
+			t.Errorf("decodeNPSCursor(%q) = %v, esperava cursor inválido", raw, err)
+		}
+	}
+
+	// A resposta do cursor e as anteriores na ordem ficam de fora
+	for _, tt := range []struct {
+		r    NPSResponse
+		want bool
+	}{
+		{NPSResponse{SubmittedAt: "2026-10-16T11:59:59Z", ID: "z"}, true},
+		{NPSResponse{SubmittedAt: "2026-10-16T12:00:00Z", ID: "a1b1"}, true},
+		{NPSResponse{SubmittedAt: "2026-10-16T12:00:00Z", ID: "a1b2"}, false},
+		{NPSResponse{SubmittedAt: "2026-10-16T12:00:00Z", ID: "a1b3"}, false},
+		{NPSResponse{SubmittedAt: "2026-10-16T12:00:01Z", ID: "a"}, false},
+	} {
+		if got := decoded.before(tt.r); got != tt.want {
+			t.Errorf("before(%+v) = %v, esperava %v", tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestNPSListQueryMatches(t *testing.T) {
+	score := func(n int) *int { return &n }
+	yes, no := true, false
+	from := time.Date(2026, 10, 16, 0, 0, 0, 0, brasilia)
+	response := NPSResponse{
+		ID:          "a",
+		Score:       8,
+		Reasons:     []string{"precisão", "rapidez"},
+		Feedback:    "bom",
+		SubmittedAt: "2026-10-16T03:00:00Z",
+		Fingerprint: "f1",
+	}
+	tests := []struct {
+		name  string
+		query NPSListQuery
+		want  bool
+	}{
+		{"sem filtros", NPSListQuery{}, true},
+		{"neutro", NPSListQuery{Classification: npsSegmentPassive}, true},
+		{"promotor", NPSListQuery{Classification: npsSegmentPromoter}, false},
+		{"detrator", NPSListQuery{Classification: npsSegmentDetractor}, false},
+		{"nota mínima igual", NPSListQuery{MinScore: score(8)}, true},
+		{"nota mínima acima", NPSListQuery{MinScore: score(9)}, false},
+		{"nota máxima igual", NPSListQuery{MaxScore: score(8)}, true},
+		{"nota máxima abaixo", NPSListQuery{MaxScore: score(7)}, false},
+		{"faixa e classificação sem interseção", NPSListQuery{Classification: npsSegmentPassive, MaxScore: score(6)}, false},
+		{"início inclusivo", NPSListQuery{From: from}, true},
+		{"início depois", NPSListQuery{From: from.Add(time.Second)}, false},
+		{"fim exclusivo", NPSListQuery{To: from}, false},
+		{"fim depois", NPSListQuery{To: from.Add(time.Second)}, true},
+		{"com comentário", NPSListQuery{HasFeedback: &yes}, true},
+		{"sem comentário", NPSListQuery{HasFeedback: &no}, false},
+		{"motivo exato", NPSListQuery{Reason: "rapidez"}, true},
+		{"motivo parcial", NPSListQuery{Reason: "rapid"}, false},
+		{"mesmo cliente", NPSListQuery{Fingerprint: "f1"}, true},
+		{"outro cliente", NPSListQuery{Fingerprint: "f2"}, false},
+		{"status ausente conta como ok", NPSListQuery{Status: npsStatusOK}, true},
+		{"suspeitas", NPSListQuery{Status: npsStatusFlagged}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.query.matches(response); got != tt.want {
+			t.Errorf("%s: matches = %v, esperava %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseNPSListQuery(t *testing.T) {
+	validCursor := npsCursor{SubmittedAt: "2026-10-16T12:00:00Z", ID: "a"}.encode()
+	tests := []struct {
+		query string
+		err   string
+		check func(q NPSListQuery) bool
+	}{
+		{query: "", check: func(q NPSListQuery) bool {
+			return q.Limit == defaultNPSPageSize && q.MinScore == nil && q.MaxScore == nil && q.From.IsZero() && q.To.IsZero()
+		}},
+		{query: "classification=Promotor&minScore=9&maxScore=10&limit=200", check: func(q NPSListQuery) bool {
+			return q.Classification == npsSegmentPromoter && *q.MinScore == 9 && *q.MaxScore == 10 && q.Limit == maxNPSPageSize
+		}},
+		{query: "from=2026-10-16&to=2026-10-16", check: func(q NPSListQuery) bool {
+			// to é inclusivo: o limite exclusivo é a meia-noite do dia seguinte em Brasília
+			return q.From.Equal(time.Date(2026, 10, 16, 0, 0, 0, 0, brasilia)) && q.To.Equal(time.Date(2026, 10, 17, 0, 0, 0, 0, brasilia))
+		}},
+		{query: "hasFeedback=false&status=flagged&fingerprint=%20f1%20&reason=precis%C3%A3o&cursor=" + validCursor, check: func(q NPSListQuery) bool {
+			return q.HasFeedback != nil && !*q.HasFeedback && q.Status == npsStatusFlagged && q.Fingerprint == "f1" && q.Reason == "precisão" && q.Cursor == validCursor
+		}},
+		{query: "classification=ruim", err: "classification"},
+		{query: "minScore=11", err: "minScore"},
+		{query: "maxScore=-1", err: "maxScore"},
+		{query: "minScore=8&maxScore=7", err: "minScore deve ser menor"},
+		{query: "status=deleted", err: "status"},
+		{query: "from=16/10/2026", err: "from"},
+		{query: "from=2026-10-17&to=2026-10-16", err: "from deve ser anterior"},
+		{query: "hasFeedback=talvez", err: "hasFeedback"},
+		{query: "limit=0", err: "limit"},
+		{query: "limit=201", err: "limit"},
+		{query: "cursor=xyz", err: errInvalidNPSCursor.Error()},
+	}
+	for _, tt := range tests {
+		q, err := parseNPSListQuery(httptest.NewRequest("GET", "/api/nps/responses?"+tt.query, nil))
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%q: erro %v, esperava %q", tt.query, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: erro inesperado %v", tt.query, err)
+			continue
+		}
+		if !tt.check(q) {
+			t.Errorf("%q: consulta inesperada %+v", tt.query, q)
+		}
+	}
+}
+
+func TestNPSPagerBoundaries(t *testing.T) {
+	pager := newNPSPager(2)
+	a := NPSResponse{ID: "a", SubmittedAt: "2026-10-16T12:00:00Z"}
+	b := NPSResponse{ID: "b", SubmittedAt: "2026-10-16T11:00:00Z"}
+	if pager.offer(a) || pager.offer(b) {
+		t.Fatal("a página não deveria fechar antes de haver uma resposta além do limite")
+	}
+	if pager.page.NextCursor != "" {
+		t.Error("exatamente limit respostas não deveriam gerar cursor")
+	}
+	if !pager.offer(NPSResponse{ID: "c", SubmittedAt: "2026-10-16T10:00:00Z"}) || len(pager.page.Responses) != 2 {
+		t.Fatalf("a terceira resposta deveria fechar a página: %+v", pager.page)
+	}
+	if want := (npsCursor{SubmittedAt: b.SubmittedAt, ID: b.ID}).encode(); pager.page.NextCursor != want {
+		t.Errorf("o cursor deveria apontar a última resposta devolvida")
+	}
+
+	// Ao esgotar o limite de leitura, a página parcial continua do último documento lido
+	pager = newNPSPager(0)
+	if pager.limit != defaultNPSPageSize {
+		t.Errorf("limite padrão = %d", pager.limit)
+	}
+	var last NPSResponse
+	for i := 0; i < maxNPSScan; i++ {
+		last = NPSResponse{ID: "x", SubmittedAt: time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC).Add(-time.Duration(i) * time.Second).Format(time.RFC3339)}
+		if done := pager.seen(last); done != (i == maxNPSScan-1) {
+			t.Fatalf("seen(%d) = %v", i, done)
+		}
+	}
+	if want := (npsCursor{SubmittedAt: last.SubmittedAt, ID: last.ID}).encode(); pager.page.NextCursor != want {
+		t.Errorf("cursor da página parcial = %q, esperava %q", pager.page.NextCursor, want)
+	}
+}
+
+// npsListFixture cobre empates de horário, as bordas do dia em Brasília,
+// respostas suspeitas e respostas antigas sem status nem cliente
+var npsListFixture = []NPSResponse{
+	{ID: "a", Score: 10, Reasons: []string{"precisão"}, Feedback: "ótimo", Fingerprint: "f1", SubmittedAt: "2026-10-16T12:00:00Z"},
+	{ID: "b", Score: 9, Fingerprint: "f2", SubmittedAt: "2026-10-16T12:00:00Z"},
+	{ID: "c", Score: 6, Reasons: []string{"lentidão"}, Fingerprint: "f1", SubmittedAt: "2026-10-16T12:00:00Z"},
+	{ID: "d", Score: 7, Reasons: []string{"precisão"}, Fingerprint: "f1", SubmittedAt: "2026-10-16T02:59:59Z"},
+	{ID: "e", Score: 8, Fingerprint: "f2", Status: npsStatusFlagged, SubmittedAt: "2026-10-16T03:00:00Z"},
+	{ID: "f", Score: 0, SubmittedAt: "2026-10-17T03:00:00Z"},
+}
+
+// testNPSStoreList confere a paginação e os filtros de uma implementação de List
+func testNPSStoreList(t *testing.T, store NPSStoreInterface) {
+	for _, r := range npsListFixture {
+		if err := store.Add(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	score := func(n int) *int { return &n }
+	yes, no := true, false
+	day := time.Date(2026, 10, 16, 0, 0, 0, 0, brasilia)
+	tests := []struct {
+		name  string
+		query NPSListQuery
+		want  []string
+	}{
+		{"todas, uma por página", NPSListQuery{Limit: 1}, []string{"f", "c", "b", "a", "e", "d"}},
+		{"todas, duas por página", NPSListQuery{Limit: 2}, []string{"f", "c", "b", "a", "e", "d"}},
+		{"todas em uma página", NPSListQuery{Limit: 6}, []string{"f", "c", "b", "a", "e", "d"}},
+		{"detratores", NPSListQuery{Classification: npsSegmentDetractor, Limit: 1}, []string{"f", "c"}},
+		{"neutros", NPSListQuery{Classification: npsSegmentPassive}, []string{"e", "d"}},
+		{"promotores", NPSListQuery{Classification: npsSegmentPromoter, Limit: 1}, []string{"b", "a"}},
+		{"faixa de notas", NPSListQuery{MinScore: score(7), MaxScore: score(9), Limit: 2}, []string{"b", "e", "d"}},
+		{"um dia em Brasília", NPSListQuery{From: day, To: day.AddDate(0, 0, 1), Limit: 3}, []string{"c", "b", "a", "e"}},
+		{"cliente", NPSListQuery{Fingerprint: "f1", Limit: 1}, []string{"c", "a", "d"}},
+		{"cliente e motivo", NPSListQuery{Fingerprint: "f1", Reason: "precisão"}, []string{"a", "d"}},
+		{"cliente, motivo e classificação", NPSListQuery{Fingerprint: "f1", Reason: "precisão", Classification: npsSegmentPromoter}, []string{"a"}},
+		{"com comentário", NPSListQuery{HasFeedback: &yes}, []string{"a"}},
+		{"sem comentário", NPSListQuery{HasFeedback: &no, Limit: 2}, []string{"f", "c", "b", "e", "d"}},
+		{"válidas", NPSListQuery{Status: npsStatusOK, Limit: 4}, []string{"f", "c", "b", "a", "d"}},
+		{"suspeitas", NPSListQuery{Status: npsStatusFlagged}, []string{"e"}},
+		{"sem resultado", NPSListQuery{Reason: "inexistente"}, nil},
+	}
+	for _, tt := range tests {
+		if got := listNPSIDs(t, store, tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: ids %v, esperava %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Continuar de um cursor devolve a mesma sequência da listagem completa
+	page, err := store.List(context.Background(), NPSListQuery{Limit: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rest := listNPSIDs(t, store, NPSListQuery{Limit: 10, Cursor: page.NextCursor})
+	if !reflect.DeepEqual(rest, []string{"a", "e", "d"}) {
+		t.Errorf("continuação do cursor: %v", rest)
+	}
+}
+
+func TestNPSStoreList(t *testing.T) {
+	store, err := NewNPSStore(filepath.Join(t.TempDir(), "nps-responses.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	testNPSStoreList(t, store)
+}
+
+// TestNPSStoreFirestoreList roda contra o emulador do Firestore
+// (FIRESTORE_EMULATOR_HOST, ex.: gcloud emulators firestore start)
+func TestNPSStoreFirestoreList(t *testing.T) {
+	if os.Getenv("FIRESTORE_EMULATOR_HOST") == "" {
+		t.Skip("FIRESTORE_EMULATOR_HOST não configurado")
+	}
+	ctx := context.Background()
+	client, err := firestore.NewClient(ctx, "demo-agoraai")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &NPSStoreFirestore{
+		firestoreService: services.NewFirestoreService(client),
+		collection:       "nps_teste_" + newNPSID(),
+		ctx:              ctx,
+	}
+	defer store.Close()
+	testNPSStoreList(t, store)
+}
@@ -0,0 +1,500 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"chat-bot/internal/config"
+)
+
+// Subcomando do servidor que copia as respostas NPS entre o arquivo local, o
+// Firestore e o SQLite. Roda no mesmo binário para poder ser executado dentro do
+// contêiner, onde ficam as respostas gravadas pelo fallback local.
+//
+// Uso:
+//
+//	chatbot nps-migrate -from file -to firestore -dry-run
+//	chatbot nps-migrate -from file -to firestore -report migracao.json
+//	go run . nps-migrate -from firestore -to file -file data/nps-backup.json
+//	go run . nps-migrate -from file -to sqlite -sqlite data/agoraai.db
+const npsMigrateCommand = "nps-migrate"
+
+const (
+	npsBackendFile      = "file"
+	npsBackendFirestore = "firestore"
+	npsBackendSQLite    = "sqlite"
+	// maxNPSMigrationIDs limita os ids listados no relatório para cada problema
+	maxNPSMigrationIDs = 50
+)
+
+// npsMigrationStore é a origem ou o destino de uma migração
+type npsMigrationStore interface {
+	// all devolve todas as respostas; documentos malformados são contados à parte
+	all(ctx context.Context) ([]NPSResponse, int, error)
+	// importAll grava as respostas mantendo os ids, sem sobrescrever ids já
+	// existentes; devolve quantas foram gravadas
+	importAll(ctx context.Context, entries []NPSResponse) (int, error)
+}
+
+// NPSMigrationReport resume a migração e a verificação feita depois dela
+type NPSMigrationReport struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	DryRun bool   `json:"dryRun"`
+	// Source conta as respostas distintas da origem; SourceSkipped, os documentos
+	// malformados, e SourceDuplicates, as repetidas (mesmo id ou mesmo conteúdo)
+	Source           int `json:"source"`
+	SourceSkipped    int `json:"sourceSkipped"`
+	SourceDuplicates int `json:"sourceDuplicates"`
+	TargetBefore     int `json:"targetBefore"`
+	// AlreadyPresent são as respostas que já estão no destino com o mesmo id;
+	// DuplicateContent, as que estão lá com outro id
+	AlreadyPresent   int `json:"alreadyPresent"`
+	DuplicateContent int `json:"duplicateContent"`
+	// Conflicts são ids presentes nos dois lados com conteúdo diferente; o destino
+	// não é alterado, e a migração só é considerada bem-sucedida com AcceptConflicts
+	Conflicts       int      `json:"conflicts"`
+	ConflictIDs     []string `json:"conflictIds,omitempty"`
+	AcceptConflicts bool     `json:"acceptConflicts"`
+	ToImport        int      `json:"toImport"`
+	Imported        int      `json:"imported"`
+	Error           string   `json:"error,omitempty"`
+	// Verified e Missing vêm da releitura do destino depois da gravação
+	TargetAfter int       `json:"targetAfter"`
+	Verified    int       `json:"verified"`
+	Missing     int       `json:"missing"`
+	MissingIDs  []string  `json:"missingIds,omitempty"`
+	StartedAt   time.Time `json:"startedAt"`
+	FinishedAt  time.Time `json:"finishedAt"`
+}
+
+// ok indica se tudo o que havia na origem está no destino; conflitos só são
+// tolerados quando aceitos explicitamente
+func (r NPSMigrationReport) ok() bool {
+	return r.Error == "" && r.Missing == 0 && (r.Conflicts == 0 || r.AcceptConflicts)
+}
+
+// npsContentKey identifica uma resposta pelo conteúdo, para reconhecer a mesma
+// resposta gravada com ids diferentes
+func npsContentKey(r NPSResponse) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		r.SubmittedAt,
+		strconv.Itoa(r.Score),
+		r.Feedback,
+		strings.Join(r.Reasons, "\x1f"),
+		r.Fingerprint,
+		r.SessionID,
+		r.ConversationID,
+	}, "\x00")))
+	return hex.EncodeToString(sum[:10])
+}
+
+// dedupeNPSResponses descarta as respostas repetidas por id ou conteúdo, mantendo
+// a primeira. Respostas sem id recebem o hash do conteúdo, para que a migração
+// repetida grave sempre no mesmo documento.
+func dedupeNPSResponses(responses []NPSResponse) ([]NPSResponse, int) {
+	seenIDs := map[string]bool{}
+	seenKeys := map[string]bool{}
+	unique := make([]NPSResponse, 0, len(responses))
+	for _, response := range responses {
+		key := npsContentKey(response)
+		if response.ID == "" {
+			response.ID = key
+		}
+		if seenIDs[response.ID] || seenKeys[key] {
+			continue
+		}
+		seenIDs[response.ID] = true
+		seenKeys[key] = true
+		unique = append(unique, response)
+	}
+	return unique, len(responses) - len(unique)
+}
+
+// appendLimited acrescenta o id enquanto a lista do relatório não chega ao limite
+func appendLimited(ids []string, id string) []string {
+	if len(ids) < maxNPSMigrationIDs {
+		return append(ids, id)
+	}
+	return ids
+}
+
+// migrateNPS copia para target as respostas de source que ainda não estão lá e
+// confere o resultado relendo o destino. Com dryRun, só calcula o que seria copiado.
+func migrateNPS(ctx context.Context, source, target npsMigrationStore, dryRun bool) (NPSMigrationReport, error) {
+	report := NPSMigrationReport{DryRun: dryRun, StartedAt: time.Now().UTC()}
+
+	responses, skipped, err := source.all(ctx)
+	if err != nil {
+		return report, fmt.Errorf("não foi possível ler a origem: %w", err)
+	}
+	report.SourceSkipped = skipped
+	responses, report.SourceDuplicates = dedupeNPSResponses(responses)
+	report.Source = len(responses)
+
+	existing, _, err := target.all(ctx)
+	if err != nil {
+		return report, fmt.Errorf("não foi possível ler o destino: %w", err)
+	}
+	report.TargetBefore = len(existing)
+	targetIDs := make(map[string]string, len(existing))
+	targetKeys := make(map[string]bool, len(existing))
+	for _, response := range existing {
+		key := npsContentKey(response)
+		targetIDs[response.ID] = key
+		targetKeys[key] = true
+	}
+
+	var pending, expected []NPSResponse
+	for _, response := range responses {
+		key := npsContentKey(response)
+		targetKey, sameID := targetIDs[response.ID]
+		switch {
+		case sameID && targetKey == key:
+			report.AlreadyPresent++
+		case sameID:
+			report.Conflicts++
+			report.ConflictIDs = appendLimited(report.ConflictIDs, response.ID)
+			continue
+		case targetKeys[key]:
+			report.DuplicateContent++
+		default:
+			pending = append(pending, response)
+		}
+		expected = append(expected, response)
+	}
+	report.ToImport = len(pending)
+
+	if dryRun {
+		report.TargetAfter = report.TargetBefore
+		report.FinishedAt = time.Now().UTC()
+		return report, nil
+	}
+
+	if len(pending) > 0 {
+		report.Imported, err = target.importAll(ctx, pending)
+		if err != nil {
+			report.Error = err.Error()
+		}
+	}
+
+	after, _, err := target.all(ctx)
+	if err != nil {
+		return report, fmt.Errorf("não foi possível reler o destino para verificação: %w", err)
+	}
+	report.TargetAfter = len(after)
+	afterIDs := make(map[string]bool, len(after))
+	afterKeys := make(map[string]bool, len(after))
+	for _, response := range after {
+		afterIDs[response.ID] = true
+		afterKeys[npsContentKey(response)] = true
+	}
+	for _, response := range expected {
+		if afterIDs[response.ID] || afterKeys[npsContentKey(response)] {
+			report.Verified++
+			continue
+		}
+		report.Missing++
+		report.MissingIDs = appendLimited(report.MissingIDs, response.ID)
+	}
+	report.FinishedAt = time.Now().UTC()
+	return report, nil
+}
+
+func (s *NPSStore) all(ctx context.Context) ([]NPSResponse, int, error) {
+	var responses []NPSResponse
+	skipped, err := s.scan(func(response NPSResponse) bool {
+		responses = append(responses, response)
+		return true
+	})
+	return responses, skipped, err
+}
+
+// importAll acrescenta as respostas novas ao arquivo de uma só vez
+func (s *NPSStore) importAll(ctx context.Context, entries []NPSResponse) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ids := map[string]bool{}
+	var pending []NPSResponse
+	for _, entry := range entries {
+		if !s.ids[entry.ID] && !ids[entry.ID] {
+			ids[entry.ID] = true
+			pending = append(pending, entry)
+		}
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+	if err := s.appendLocked(pending...); err != nil {
+		return 0, err
+	}
+	return len(pending), nil
+}
+
+// npsFileSource lê o arquivo local como origem de uma migração sem alterá-lo:
+// ao contrário de NewNPSStore, não converte o array JSON antigo em um .jsonl ao
+// lado, não descarta a última linha incompleta e não compacta o arquivo
+type npsFileSource struct {
+	filePath string
+}
+
+// openNPSFileSource confere que há o que ler: o arquivo ou, para um .jsonl
+// ainda não criado, o array JSON antigo ao lado
+func openNPSFileSource(filePath string) (npsFileSource, error) {
+	if _, err := os.Stat(filePath); err != nil {
+		legacyPath := legacyNPSPath(filePath)
+		if !os.IsNotExist(err) || legacyPath == "" {
+			return npsFileSource{}, err
+		}
+		if _, legacyErr := os.Stat(legacyPath); legacyErr != nil {
+			return npsFileSource{}, err
+		}
+	}
+	return npsFileSource{filePath: filePath}, nil
+}
+
+// all lê as respostas como NewNPSStore as veria: o .jsonl e, quando o caminho
+// é o array antigo, também as dele. Repetidas e sem id ficam como estão:
+// dedupeNPSResponses as conta e dá às sem id o hash do conteúdo, o mesmo id
+// que a conversão daria.
+func (s npsFileSource) all(ctx context.Context) ([]NPSResponse, int, error) {
+	jsonlPath, legacyPath := s.filePath, ""
+	legacy, err := isLegacyNPSFile(s.filePath)
+	switch {
+	case err == nil && legacy:
+		jsonlPath, legacyPath = jsonlNPSPath(s.filePath), s.filePath
+	case err != nil && !os.IsNotExist(err):
+		return nil, 0, err
+	case err != nil:
+		legacyPath = legacyNPSPath(s.filePath)
+	}
+
+	var responses []NPSResponse
+	// scanLocked só abre o arquivo para leitura; sem load, nada é recuperado
+	reader := &NPSStore{filePath: jsonlPath}
+	skipped, err := reader.scanLocked(func(r NPSResponse) bool {
+		responses = append(responses, r)
+		return true
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Um .jsonl já existente substitui o array antigo ao lado; o array passado
+	// diretamente ainda pode ter respostas gravadas depois da conversão
+	if _, err := os.Stat(jsonlPath); legacyPath != "" && (legacy || os.IsNotExist(err)) {
+		legacyResponses, err := readLegacyNPSFile(legacyPath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, 0, err
+		}
+		responses = append(responses, legacyResponses...)
+	}
+	return responses, skipped, nil
+}
+
+// importAll não é usado: a origem nunca recebe respostas
+func (s npsFileSource) importAll(ctx context.Context, entries []NPSResponse) (int, error) {
+	return 0, errors.New("a origem da migração é somente leitura")
+}
+
+func (s *NPSStoreFirestore) all(ctx context.Context) ([]NPSResponse, int, error) {
+	iter := s.firestoreService.GetClient().Collection(s.collection).Documents(ctx)
+	defer iter.Stop()
+
+	var responses []NPSResponse
+	skipped := 0
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return responses, skipped, nil
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		if response, ok := npsResponseFromDoc(doc); ok {
+			responses = append(responses, response)
+		} else {
+			skipped++
+		}
+	}
+}
+
+// importAll usa Create em lote: documentos que já existem não são sobrescritos
+// e contam como já migrados
+func (s *NPSStoreFirestore) importAll(ctx context.Context, entries []NPSResponse) (int, error) {
+	client := s.firestoreService.GetClient()
+	writer := client.BulkWriter(ctx)
+	jobs := make([]*firestore.BulkWriterJob, 0, len(entries))
+	var errs []error
+	for _, entry := range entries {
+		job, err := writer.Create(client.Collection(s.collection).Doc(entry.ID), npsDocData(entry))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.ID, err))
+			continue
+		}
+		jobs = append(jobs, job)
+	}
+	writer.End()
+
+	imported := 0
+	for _, job := range jobs {
+		_, err := job.Results()
+		switch {
+		case err == nil:
+			imported++
+		case status.Code(err) != codes.AlreadyExists:
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return imported, fmt.Errorf("%d respostas não foram gravadas: %w", len(errs), errs[0])
+	}
+	return imported, nil
+}
+
+// openNPSMigrationStore abre um dos armazenamentos; close libera a conexão com o
+// Firestore. A origem (source) precisa existir, e o arquivo local é lido sem alterações.
+func openNPSMigrationStore(ctx context.Context, backend, filePath, sqlitePath string, source bool) (npsMigrationStore, func(), error) {
+	switch backend {
+	case npsBackendSQLite:
+		if source {
+			if _, err := os.Stat(sqlitePath); err != nil {
+				return nil, nil, err
+			}
+		}
+		store, err := NewNPSStoreSQLite(ctx, sqlitePath)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, func() { store.Close() }, nil
+	case npsBackendFile:
+		if source {
+			store, err := openNPSFileSource(filePath)
+			return store, func() {}, err
+		}
+		store, err := NewNPSStore(filePath)
+		return store, func() {}, err
+	case npsBackendFirestore:
+		cfg, err := config.Load()
+		if err != nil {
+			return nil, nil, err
+		}
+		if cfg.FirebaseProjectID == "" {
+			return nil, nil, errors.New("configure FIREBASE_PROJECT_ID ou FIRESTORE_PROJECT_ID no env.yaml")
+		}
+		store, err := NewNPSStoreFirestore(ctx, cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, func() { store.Close() }, nil
+	}
+	return nil, nil, fmt.Errorf("armazenamento desconhecido %q: use %s, %s ou %s", backend, npsBackendFile, npsBackendFirestore, npsBackendSQLite)
+}
+
+// runNPSMigrate executa o subcomando nps-migrate e devolve o código de saída
+func runNPSMigrate(args []string) int {
+	flags := flag.NewFlagSet(npsMigrateCommand, flag.ExitOnError)
+	from := flags.String("from", npsBackendFile, "origem: file, firestore ou sqlite")
+	to := flags.String("to", npsBackendFirestore, "destino: file, firestore ou sqlite")
+	filePath := flags.String("file", npsStoreFilePath, "arquivo JSONL das respostas (o array JSON antigo também é aceito); como origem, é lido sem alterações")
+	sqlitePath := flags.String("sqlite", "data/agoraai.db", "banco SQLite das respostas")
+	dryRun := flags.Bool("dry-run", false, "só informa o que seria copiado")
+	reportPath := flags.String("report", "", "grava o relatório em JSON neste arquivo")
+	acceptConflicts := flags.Bool("accept-conflicts", false, "termina com sucesso mesmo com ids de conteúdo diferente no destino")
+	flags.Parse(args)
+
+	if *from == *to {
+		fmt.Fprintln(os.Stderr, "uso: nps-migrate -from file|firestore|sqlite -to firestore|file|sqlite [-file caminho] [-sqlite banco.db] [-dry-run] [-accept-conflicts] [-report relatorio.json]")
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	source, closeSource, err := openNPSMigrationStore(ctx, *from, *filePath, *sqlitePath, true)
+	if err != nil {
+		log.Printf("não foi possível abrir a origem (%s): %v", *from, err)
+		return 1
+	}
+	defer closeSource()
+	target, closeTarget, err := openNPSMigrationStore(ctx, *to, *filePath, *sqlitePath, false)
+	if err != nil {
+		log.Printf("não foi possível abrir o destino (%s): %v", *to, err)
+		return 1
+	}
+	defer closeTarget()
+
+	report, err := migrateNPS(ctx, source, target, *dryRun)
+	report.From, report.To, report.AcceptConflicts = *from, *to, *acceptConflicts
+	if err != nil {
+		log.Printf("❌ migração NPS interrompida: %v", err)
+		return 1
+	}
+	printNPSMigrationReport(report)
+
+	if *reportPath != "" {
+		payload, _ := json.MarshalIndent(report, "", "  ")
+		if err := os.WriteFile(*reportPath, payload, 0o644); err != nil {
+			log.Printf("⚠️  não foi possível gravar o relatório em %s: %v", *reportPath, err)
+		}
+	}
+	if !report.ok() {
+		return 1
+	}
+	return 0
+}
+
+func printNPSMigrationReport(r NPSMigrationReport) {
+	mode := ""
+	if r.DryRun {
+		mode = " (simulação)"
+	}
+	fmt.Printf("Migração NPS %s → %s%s\n", r.From, r.To, mode)
+	fmt.Printf("  origem:            %d respostas (%d repetidas, %d malformadas ignoradas)\n", r.Source, r.SourceDuplicates, r.SourceSkipped)
+	fmt.Printf("  destino antes:     %d respostas\n", r.TargetBefore)
+	fmt.Printf("  já no destino:     %d pelo id, %d pelo conteúdo\n", r.AlreadyPresent, r.DuplicateContent)
+	fmt.Printf("  a copiar:          %d\n", r.ToImport)
+	if r.Conflicts > 0 {
+		sort.Strings(r.ConflictIDs)
+		fmt.Printf("  ⚠️  conflitos:       %d ids com conteúdo diferente no destino: %s\n", r.Conflicts, strings.Join(r.ConflictIDs, ", "))
+		if !r.AcceptConflicts {
+			fmt.Println("  ❌ confira os conflitos ou repita com -accept-conflicts para mantê-los como estão")
+		}
+	}
+	if r.DryRun {
+		return
+	}
+	fmt.Printf("  copiadas:          %d\n", r.Imported)
+	fmt.Printf("  destino depois:    %d respostas\n", r.TargetAfter)
+	fmt.Printf("  verificadas:       %d\n", r.Verified)
+	if r.Error != "" {
+		fmt.Printf("  ❌ erro:            %s\n", r.Error)
+	}
+	if r.Missing > 0 {
+		fmt.Printf("  ❌ ausentes:         %d: %s\n", r.Missing, strings.Join(r.MissingIDs, ", "))
+	}
+	switch {
+	case r.ok() && r.Conflicts > 0:
+		fmt.Println("  ✅ todas as respostas da origem, exceto os conflitos, estão no destino")
+	case r.ok():
+		fmt.Println("  ✅ todas as respostas da origem estão no destino")
+	}
+}
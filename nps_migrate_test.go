@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrateNPSConflicts(t *testing.T) {
+	dir := t.TempDir()
+	source, err := NewNPSStore(filepath.Join(dir, "origem.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := NewNPSStore(filepath.Join(dir, "destino.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if _, err := source.importAll(ctx, []NPSResponse{
+		{ID: "a", Score: 10, SubmittedAt: "2026-10-16T12:00:00Z"},
+		{ID: "b", Score: 3, SubmittedAt: "2026-10-16T13:00:00Z"},
+		{ID: "c", Score: 8, SubmittedAt: "2026-10-16T14:00:00Z"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// "b" já está no destino com outra nota
+	if _, err := target.importAll(ctx, []NPSResponse{{ID: "b", Score: 9, SubmittedAt: "2026-10-16T13:00:00Z"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := migrateNPS(ctx, source, target, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Conflicts != 1 || report.ConflictIDs[0] != "b" || report.Imported != 2 || report.Missing != 0 {
+		t.Fatalf("relatório inesperado: %+v", report)
+	}
+	if report.ok() {
+		t.Errorf("conflitos não aceitos deveriam falhar a migração")
+	}
+	report.AcceptConflicts = true
+	if !report.ok() {
+		t.Errorf("com -accept-conflicts a migração deveria terminar com sucesso")
+	}
+
+	// Repetir a migração não copia nada de novo e mantém o conflito
+	again, err := migrateNPS(ctx, source, target, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Imported != 0 || again.AlreadyPresent != 2 || again.Conflicts != 1 {
+		t.Errorf("migração repetida inesperada: %+v", again)
+	}
+}
+
+func TestMigrateNPSFromFileIsReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	legacyPath := filepath.Join(dir, "nps-responses.json")
+	legacy := `[{"id":"a","score":10,"submittedAt":"2026-10-16T12:00:00Z"},{"score":2,"submittedAt":"2026-10-16T13:00:00Z"},{"score":2,"submittedAt":"2026-10-16T13:00:00Z"}]`
+	os.WriteFile(legacyPath, []byte(legacy), 0o644)
+	// JSONL com a última linha incompleta, que NewNPSStore descartaria
+	jsonlPath := filepath.Join(dir, "outras.jsonl")
+	partial := "{\"id\":\"c\",\"score\":7,\"submittedAt\":\"2026-10-16T14:00:00Z\"}\n{\"id\":\"d\",\"sco"
+	os.WriteFile(jsonlPath, []byte(partial), 0o644)
+
+	ctx := context.Background()
+	for _, tt := range []struct {
+		path     string
+		content  string
+		source   int
+		repeated int
+	}{
+		{legacyPath, legacy, 2, 1},
+		{jsonlPath, partial, 1, 0},
+	} {
+		source, closeSource, err := openNPSMigrationStore(ctx, npsBackendFile, tt.path, "", true)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.path, err)
+		}
+		defer closeSource()
+		target, err := NewNPSStore(filepath.Join(dir, "destino-"+filepath.Base(tt.path)+".jsonl"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer target.Close()
+
+		dry, err := migrateNPS(ctx, source, target, true)
+		if err != nil || dry.Source != tt.source || dry.SourceDuplicates != tt.repeated || dry.ToImport != tt.source {
+			t.Errorf("%s: simulação inesperada: %+v, %v", tt.path, dry, err)
+		}
+		for _, run := range []int{tt.source, 0} {
+			report, err := migrateNPS(ctx, source, target, false)
+			if err != nil || report.Imported != run || report.Missing != 0 {
+				t.Errorf("%s: migração inesperada (esperava %d copiadas): %+v, %v", tt.path, run, report, err)
+			}
+		}
+
+		if got, _ := os.ReadFile(tt.path); string(got) != tt.content {
+			t.Errorf("%s: a origem foi alterada", tt.path)
+		}
+	}
+	// O array antigo não é convertido em um .jsonl ao lado
+	if _, err := os.Stat(filepath.Join(dir, "nps-responses.jsonl")); !os.IsNotExist(err) {
+		t.Errorf("a migração criou um .jsonl ao lado do array antigo: %v", err)
+	}
+}
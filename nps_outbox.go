@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"path/filepath"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"chat-bot/internal/outbox"
+)
+
+const (
+	npsOutboxFileName = "nps-outbox.jsonl"
+	// npsWriteTimeout limita a gravação feita durante o envio da resposta; o que
+	// passar disso fica na fila e é reenviado em segundo plano
+	npsWriteTimeout = 10 * time.Second
+)
+
+// npsOutbox guarda as respostas que ainda não chegaram ao Firestore
+var npsOutbox *outbox.Store
+
+// setupNPSOutbox liga a fila de gravações pendentes ao Firestore e começa a
+// reenviar o que ficou de execuções anteriores. A fila só é usada com
+// NPS_OUTBOX_DIR, que deve ser um volume persistente: no disco da instância do
+// Cloud Run ela se perderia junto com respostas já confirmadas ao usuário. Sem
+// a fila, uma falha do Firestore responde 500; com o diretório configurado mas
+// inutilizável, o servidor não sobe.
+func setupNPSOutbox(store *NPSStoreFirestore, dir string) {
+	if dir == "" {
+		log.Println("⚠️  NPS_OUTBOX_DIR não configurado: falhas do Firestore ao gravar respostas NPS respondem 500")
+		return
+	}
+	box, err := outbox.NewStore(filepath.Join(dir, npsOutboxFileName))
+	if err != nil {
+		log.Fatalf("não foi possível abrir a fila de gravações NPS em %s: %v", dir, err)
+	}
+	npsOutbox = box
+	store.outbox = box
+	if depth := box.Stats().Depth; depth > 0 {
+		log.Printf("📮 %d respostas NPS pendentes de gravação no Firestore", depth)
+	}
+	go box.Run(context.Background(), store.deliver)
+}
+
+// deliver grava uma resposta da fila no Firestore. O id da resposta é o id do
+// documento, então um documento que já existe significa que uma tentativa
+// anterior chegou a gravar, e a entrega conta como feita.
+func (s *NPSStoreFirestore) deliver(ctx context.Context, e outbox.Entry) error {
+	var entry NPSResponse
+	if err := json.Unmarshal(e.Payload, &entry); err != nil {
+		// Uma entrada ilegível nunca seria aceita; vai para as descartadas
+		return outbox.Permanent(err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, npsWriteTimeout)
+	defer cancel()
+
+	client := s.firestoreService.GetClient()
+	_, err := client.Collection(s.collection).Doc(entry.ID).Create(ctx, npsDocData(entry))
+	return npsCreateResult(err)
+}
+
+// npsCreateResult trata como entregue o documento que já existe: o id é fixo, então
+// ele só existe se uma tentativa anterior, dada como falha, chegou a gravar. Só
+// falhas temporárias do Firestore voltam para a fila; as demais (permissão,
+// documento inválido) não mudariam com novas tentativas e são marcadas como permanentes.
+func npsCreateResult(err error) error {
+	switch status.Code(err) {
+	case codes.OK, codes.AlreadyExists:
+		return nil
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return err
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return outbox.Permanent(err)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"chat-bot/internal/outbox"
+)
+
+func TestNPSCreateResult(t *testing.T) {
+	if err := npsCreateResult(status.Error(codes.AlreadyExists, "documento existe")); err != nil {
+		t.Errorf("documento já gravado deveria contar como entregue: %v", err)
+	}
+	unavailable := status.Error(codes.Unavailable, "indisponível")
+	if err := npsCreateResult(unavailable); !errors.Is(err, unavailable) {
+		t.Errorf("outros erros deveriam manter a resposta na fila: %v", err)
+	}
+	if err := npsCreateResult(nil); err != nil {
+		t.Errorf("gravação aceita: %v", err)
+	}
+	for _, code := range []codes.Code{codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted} {
+		if err := npsCreateResult(status.Error(code, "temporário")); err == nil || outbox.IsPermanent(err) {
+			t.Errorf("%s deveria voltar para a fila: %v", code, err)
+		}
+	}
+	for _, code := range []codes.Code{codes.PermissionDenied, codes.InvalidArgument, codes.NotFound} {
+		if err := npsCreateResult(status.Error(code, "definitivo")); !outbox.IsPermanent(err) {
+			t.Errorf("%s não deveria voltar para a fila: %v", code, err)
+		}
+	}
+}
@@ -0,0 +1,640 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"cloud.google.com/go/firestore/apiv1/firestorepb"
+	"google.golang.org/api/iterator"
+)
+
+const (
+	defaultNPSStatsDays = 30
+	maxNPSStatsDays     = 366
+	// dailySeriesMaxDays: intervalos maiores usam série semanal por padrão
+	dailySeriesMaxDays = 62
+	maxNPSTopReasons   = 10
+	// npsAggregationWorkers limita as consultas de agregação simultâneas no Firestore
+	npsAggregationWorkers = 8
+	// maxNPSSegments limita os grupos devolvidos na segmentação
+	maxNPSSegments = 50
+	// npsSegmentUnknown agrupa as respostas sem o campo usado na segmentação
+	npsSegmentUnknown = "não informado"
+)
+
+// Campos aceitos em segment: agrupam o NPS pelo contexto da conversa
+const (
+	npsSegmentByModel         = "model"
+	npsSegmentByPrompt        = "promptVersion"
+	npsSegmentByQuestionCount = "questionCount"
+)
+
+// Segmentos usados nos motivos mais citados
+const (
+	npsSegmentAll        = "todos"
+	npsSegmentPromoter   = "promotor"
+	npsSegmentPassive    = "neutro"
+	npsSegmentDetractor  = "detrator"
+	npsIntervalDay       = "day"
+	npsIntervalWeek      = "week"
+	npsHistogramBuckets  = 11
+	npsStatsDateLayout   = "2006-01-02"
+	npsStatsRoundDecimal = 10
+)
+
+// NPSStatsQuery define o período (From inclusivo, To exclusivo) e o intervalo da série
+type NPSStatsQuery struct {
+	From     time.Time
+	To       time.Time
+	Interval string
+	// Segment agrupa o NPS por model, promptVersion ou questionCount; vazio não segmenta
+	Segment string
+}
+
+// segmentKey devolve o grupo da resposta na segmentação pedida
+func (q NPSStatsQuery) segmentKey(r NPSResponse) string {
+	key := ""
+	switch q.Segment {
+	case npsSegmentByModel:
+		key = r.Model
+	case npsSegmentByPrompt:
+		key = r.PromptVersion
+	case npsSegmentByQuestionCount:
+		if r.QuestionCount > 0 {
+			key = strconv.Itoa(r.QuestionCount)
+		}
+	}
+	if key == "" {
+		return npsSegmentUnknown
+	}
+	return key
+}
+
+// buckets devolve o início de cada intervalo da série
+func (q NPSStatsQuery) buckets() []time.Time {
+	start := q.From
+	if q.Interval == npsIntervalWeek {
+		// Semanas começam na segunda-feira
+		offset := (int(start.Weekday()) + 6) % 7
+		start = start.AddDate(0, 0, -offset)
+	}
+	var buckets []time.Time
+	for t := start; t.Before(q.To); t = q.next(t) {
+		buckets = append(buckets, t)
+	}
+	return buckets
+}
+
+func (q NPSStatsQuery) next(t time.Time) time.Time {
+	if q.Interval == npsIntervalWeek {
+		return t.AddDate(0, 0, 7)
+	}
+	return t.AddDate(0, 0, 1)
+}
+
+// NPSReasonCount é um motivo com o número de respostas que o citaram
+type NPSReasonCount struct {
+	Reason string `json:"reason"`
+	Count  int    `json:"count"`
+}
+
+// NPSSeriesPoint resume as respostas de um dia ou semana
+type NPSSeriesPoint struct {
+	Start      string   `json:"start"`
+	Total      int      `json:"total"`
+	Promoters  int      `json:"promoters"`
+	Passives   int      `json:"passives"`
+	Detractors int      `json:"detractors"`
+	NPS        *float64 `json:"nps"`
+}
+
+// NPSSegmentStats resume as respostas de um grupo da segmentação
+type NPSSegmentStats struct {
+	Value      string   `json:"value"`
+	Total      int      `json:"total"`
+	Promoters  int      `json:"promoters"`
+	Passives   int      `json:"passives"`
+	Detractors int      `json:"detractors"`
+	NPS        *float64 `json:"nps"`
+	Average    *float64 `json:"average"`
+	sum        int
+}
+
+// NPSStats são as estatísticas agregadas das respostas no período
+type NPSStats struct {
+	From       string   `json:"from"`
+	To         string   `json:"to"`
+	Interval   string   `json:"interval"`
+	Total      int      `json:"total"`
+	Promoters  int      `json:"promoters"`
+	Passives   int      `json:"passives"`
+	Detractors int      `json:"detractors"`
+	NPS        *float64 `json:"nps"`
+	Average    *float64 `json:"average"`
+	// Flagged conta as respostas suspeitas do período, que ficam fora das demais contagens
+	Flagged int `json:"flagged"`
+	// Histogram traz o número de respostas de cada nota, de 0 a 10
+	Histogram []int `json:"histogram"`
+	// TopReasons agrupa os motivos por segmento: todos, promotor, neutro e detrator
+	TopReasons map[string][]NPSReasonCount `json:"topReasons"`
+	Series     []NPSSeriesPoint            `json:"series"`
+	// Segments traz o NPS por grupo quando segment é informado, dos maiores para os menores
+	Segment     string            `json:"segment,omitempty"`
+	Segments    []NPSSegmentStats `json:"segments,omitempty"`
+	GeneratedAt time.Time         `json:"generatedAt"`
+}
+
+func roundNPS(v float64) *float64 {
+	rounded := math.Round(v*npsStatsRoundDecimal) / npsStatsRoundDecimal
+	return &rounded
+}
+
+// npsScore é o percentual de promotores menos o de detratores; nil sem respostas
+func npsScore(promoters, detractors, total int) *float64 {
+	if total == 0 {
+		return nil
+	}
+	return roundNPS(float64(promoters-detractors) / float64(total) * 100)
+}
+
+// npsStatsBuilder acumula as respostas lidas e monta as estatísticas
+type npsStatsBuilder struct {
+	query     NPSStatsQuery
+	histogram [npsHistogramBuckets]int
+	reasons   map[string]map[string]int
+	buckets   []time.Time
+	series    []NPSSeriesPoint
+	flagged   int
+	segments  map[string]*NPSSegmentStats
+}
+
+func newNPSStatsBuilder(q NPSStatsQuery) *npsStatsBuilder {
+	b := &npsStatsBuilder{
+		query:    q,
+		reasons:  map[string]map[string]int{},
+		buckets:  q.buckets(),
+		segments: map[string]*NPSSegmentStats{},
+	}
+	b.series = make([]NPSSeriesPoint, len(b.buckets))
+	for i, start := range b.buckets {
+		b.series[i].Start = start.Format(npsStatsDateLayout)
+	}
+	return b
+}
+
+// bucketIndex localiza o intervalo da série que contém o instante
+func (b *npsStatsBuilder) bucketIndex(t time.Time) int {
+	i := sort.Search(len(b.buckets), func(i int) bool { return b.buckets[i].After(t) }) - 1
+	return i
+}
+
+// countClassification soma delta (1 ou -1) ao total e à classificação da nota
+func countClassification(point *NPSSeriesPoint, score, delta int) {
+	point.Total += delta
+	switch classifyNPS(score) {
+	case npsSegmentPromoter:
+		point.Promoters += delta
+	case npsSegmentDetractor:
+		point.Detractors += delta
+	default:
+		point.Passives += delta
+	}
+}
+
+// addReasons conta os motivos no segmento da nota e no total
+func (b *npsStatsBuilder) addReasons(classification string, reasons []string) {
+	for _, segment := range []string{npsSegmentAll, classification} {
+		counts, ok := b.reasons[segment]
+		if !ok {
+			counts = map[string]int{}
+			b.reasons[segment] = counts
+		}
+		for _, reason := range reasons {
+			counts[reason]++
+		}
+	}
+}
+
+func (b *npsStatsBuilder) inPeriod(score int, submittedAt time.Time) bool {
+	return score >= 0 && score < npsHistogramBuckets && !submittedAt.Before(b.query.From) && submittedAt.Before(b.query.To)
+}
+
+// add conta uma resposta; respostas fora do período são ignoradas e as
+// suspeitas só entram na contagem de marcadas
+func (b *npsStatsBuilder) add(r NPSResponse) {
+	submittedAt, err := time.Parse(time.RFC3339, r.SubmittedAt)
+	if err != nil || !b.inPeriod(r.Score, submittedAt) {
+		return
+	}
+	if r.status() == npsStatusFlagged {
+		b.flagged++
+		return
+	}
+	b.histogram[r.Score]++
+	if i := b.bucketIndex(submittedAt); i >= 0 {
+		countClassification(&b.series[i], r.Score, 1)
+	}
+	b.addDetails(r)
+}
+
+// addDetails conta os motivos e o grupo da segmentação de uma resposta válida
+func (b *npsStatsBuilder) addDetails(r NPSResponse) {
+	classification := classifyNPS(r.Score)
+	b.addReasons(classification, r.Reasons)
+	if b.query.Segment == "" {
+		return
+	}
+	key := b.query.segmentKey(r)
+	segment, ok := b.segments[key]
+	if !ok {
+		segment = &NPSSegmentStats{Value: key}
+		b.segments[key] = segment
+	}
+	segment.Total++
+	segment.sum += r.Score
+	switch classification {
+	case npsSegmentPromoter:
+		segment.Promoters++
+	case npsSegmentDetractor:
+		segment.Detractors++
+	default:
+		segment.Passives++
+	}
+}
+
+// removeFlagged desconta do histograma e da série uma resposta suspeita já
+// incluída pelas contagens agregadas do Firestore
+func (b *npsStatsBuilder) removeFlagged(score int, submittedAt time.Time) {
+	if !b.inPeriod(score, submittedAt) {
+		return
+	}
+	b.flagged++
+	b.histogram[score]--
+	if i := b.bucketIndex(submittedAt); i >= 0 {
+		countClassification(&b.series[i], score, -1)
+	}
+}
+
+func (b *npsStatsBuilder) result() NPSStats {
+	stats := NPSStats{
+		From:        b.query.From.Format(npsStatsDateLayout),
+		To:          b.query.To.AddDate(0, 0, -1).Format(npsStatsDateLayout),
+		Interval:    b.query.Interval,
+		Histogram:   b.histogram[:],
+		TopReasons:  map[string][]NPSReasonCount{},
+		Series:      b.series,
+		Segment:     b.query.Segment,
+		Flagged:     b.flagged,
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	sum := 0
+	for score, count := range b.histogram {
+		stats.Total += count
+		sum += score * count
+		switch classifyNPS(score) {
+		case npsSegmentPromoter:
+			stats.Promoters += count
+		case npsSegmentDetractor:
+			stats.Detractors += count
+		default:
+			stats.Passives += count
+		}
+	}
+	stats.NPS = npsScore(stats.Promoters, stats.Detractors, stats.Total)
+	if stats.Total > 0 {
+		stats.Average = roundNPS(float64(sum) / float64(stats.Total))
+	}
+	for i := range stats.Series {
+		point := &stats.Series[i]
+		point.NPS = npsScore(point.Promoters, point.Detractors, point.Total)
+	}
+
+	for _, segment := range []string{npsSegmentAll, npsSegmentPromoter, npsSegmentPassive, npsSegmentDetractor} {
+		top := []NPSReasonCount{}
+		for reason, count := range b.reasons[segment] {
+			top = append(top, NPSReasonCount{Reason: reason, Count: count})
+		}
+		sort.Slice(top, func(i, j int) bool {
+			if top[i].Count != top[j].Count {
+				return top[i].Count > top[j].Count
+			}
+			return top[i].Reason < top[j].Reason
+		})
+		if len(top) > maxNPSTopReasons {
+			top = top[:maxNPSTopReasons]
+		}
+		stats.TopReasons[segment] = top
+	}
+
+	if b.query.Segment != "" {
+		stats.Segments = []NPSSegmentStats{}
+		for _, segment := range b.segments {
+			segment.NPS = npsScore(segment.Promoters, segment.Detractors, segment.Total)
+			segment.Average = roundNPS(float64(segment.sum) / float64(segment.Total))
+			stats.Segments = append(stats.Segments, *segment)
+		}
+		sort.Slice(stats.Segments, func(i, j int) bool {
+			if stats.Segments[i].Total != stats.Segments[j].Total {
+				return stats.Segments[i].Total > stats.Segments[j].Total
+			}
+			return stats.Segments[i].Value < stats.Segments[j].Value
+		})
+		if len(stats.Segments) > maxNPSSegments {
+			stats.Segments = stats.Segments[:maxNPSSegments]
+		}
+	}
+	return stats
+}
+
+// Stats calcula as estatísticas percorrendo as respostas do arquivo local
+func (s *NPSStore) Stats(ctx context.Context, q NPSStatsQuery) (NPSStats, error) {
+	builder := newNPSStatsBuilder(q)
+	if _, err := s.scan(func(response NPSResponse) bool {
+		builder.add(response)
+		return ctx.Err() == nil
+	}); err != nil {
+		return NPSStats{}, err
+	}
+	if err := ctx.Err(); err != nil {
+		return NPSStats{}, err
+	}
+	return builder.result(), nil
+}
+
+// Stats usa consultas de agregação do Firestore (COUNT) para o histograma e a
+// série, sem ler os documentos. Histograma e série filtram pela nota, que
+// também existe nas respostas antigas sem o campo classification. As
+// respostas suspeitas (poucas) são lidas e descontadas, já que as antigas não
+// têm o campo status. Motivos e segmentos não têm catálogo fixo, então são
+// contados a partir de uma leitura só dos campos necessários. Sem os índices
+// compostos (firestore.indexes.json), calcula lendo os documentos.
+func (s *NPSStoreFirestore) Stats(ctx context.Context, q NPSStatsQuery) (NPSStats, error) {
+	builder := newNPSStatsBuilder(q)
+	err := s.aggregateCounts(ctx, builder)
+	if err == nil {
+		err = s.removeFlagged(ctx, builder)
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return NPSStats{}, ctx.Err()
+		}
+		log.Printf("⚠️  agregação NPS no Firestore indisponível (%v); lendo os documentos", err)
+		return s.scanStats(ctx, q)
+	}
+
+	fields := []string{"score", "reasons", "status"}
+	if q.Segment != "" {
+		fields = append(fields, q.Segment)
+	}
+	iter := s.periodQuery(q.From, q.To).Select(fields...).Documents(ctx)
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return NPSStats{}, err
+		}
+		response, ok := npsResponseFromDoc(doc)
+		if !ok || response.status() == npsStatusFlagged || response.Score < 0 || response.Score >= npsHistogramBuckets {
+			continue
+		}
+		builder.addDetails(response)
+	}
+	return builder.result(), nil
+}
+
+// npsStatsFields são os campos lidos quando o cálculo é feito inteiro a partir dos documentos
+var npsStatsFields = []string{"submittedAt", "score", "reasons", "status", "model", "promptVersion", "questionCount"}
+
+// Notas de cada classificação, usadas nas contagens agregadas da série
+var (
+	npsPromoterScores  = []int{9, 10}
+	npsDetractorScores = []int{0, 1, 2, 3, 4, 5, 6}
+)
+
+// periodQuery filtra pelo submittedAt, gravado em RFC 3339 UTC (ordem lexicográfica = cronológica)
+func (s *NPSStoreFirestore) periodQuery(from, to time.Time) firestore.Query {
+	return s.firestoreService.GetClient().Collection(s.collection).
+		Where("submittedAt", ">=", from.UTC().Format(time.RFC3339)).
+		Where("submittedAt", "<", to.UTC().Format(time.RFC3339))
+}
+
+func (s *NPSStoreFirestore) count(ctx context.Context, query firestore.Query) (int, error) {
+	result, err := query.NewAggregationQuery().WithCount("total").Get(ctx)
+	if err != nil {
+		return 0, err
+	}
+	value, ok := result["total"].(*firestorepb.Value)
+	if !ok {
+		return 0, fmt.Errorf("resultado de agregação inesperado: %T", result["total"])
+	}
+	return int(value.GetIntegerValue()), nil
+}
+
+// aggregateCounts preenche o histograma (uma contagem por nota) e a série
+// (total, promotores e detratores por intervalo) com consultas COUNT
+func (s *NPSStoreFirestore) aggregateCounts(ctx context.Context, b *npsStatsBuilder) error {
+	type task struct {
+		query firestore.Query
+		apply func(n int)
+	}
+	var tasks []task
+	for score := 0; score < npsHistogramBuckets; score++ {
+		score := score
+		tasks = append(tasks, task{
+			query: s.periodQuery(b.query.From, b.query.To).Where("score", "==", score),
+			apply: func(n int) { b.histogram[score] = n },
+		})
+	}
+	for i, start := range b.buckets {
+		point := &b.series[i]
+		// As semanas cortadas nas pontas ficam restritas ao período, como no cálculo local
+		end := b.query.next(start)
+		if start.Before(b.query.From) {
+			start = b.query.From
+		}
+		if end.After(b.query.To) {
+			end = b.query.To
+		}
+		tasks = append(tasks,
+			task{query: s.periodQuery(start, end).Where("score", "in", npsHistogramScores()), apply: func(n int) { point.Total = n }},
+			task{query: s.periodQuery(start, end).Where("score", "in", npsPromoterScores), apply: func(n int) { point.Promoters = n }},
+			task{query: s.periodQuery(start, end).Where("score", "in", npsDetractorScores), apply: func(n int) { point.Detractors = n }},
+		)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mutex    sync.Mutex
+		firstErr error
+		slots    = make(chan struct{}, npsAggregationWorkers)
+	)
+	for _, t := range tasks {
+		t := t
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			n, err := s.count(ctx, t.query)
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				return
+			}
+			t.apply(n)
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+
+	for i := range b.series {
+		point := &b.series[i]
+		point.Passives = point.Total - point.Promoters - point.Detractors
+	}
+	return nil
+}
+
+// npsHistogramScores lista as notas válidas, de 0 a 10
+func npsHistogramScores() []int {
+	scores := make([]int, npsHistogramBuckets)
+	for i := range scores {
+		scores[i] = i
+	}
+	return scores
+}
+
+// removeFlagged lê as respostas suspeitas do período e as desconta das contagens agregadas
+func (s *NPSStoreFirestore) removeFlagged(ctx context.Context, b *npsStatsBuilder) error {
+	iter := s.periodQuery(b.query.From, b.query.To).Where("status", "==", npsStatusFlagged).Select("score", "submittedAt").Documents(ctx)
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		data := doc.Data()
+		score, ok := intValue(data["score"])
+		if !ok {
+			continue
+		}
+		if submittedAt, err := time.Parse(time.RFC3339, getString(data, "submittedAt")); err == nil {
+			b.removeFlagged(score, submittedAt)
+		}
+	}
+}
+
+// scanStats calcula as estatísticas lendo os campos necessários dos documentos do período
+func (s *NPSStoreFirestore) scanStats(ctx context.Context, q NPSStatsQuery) (NPSStats, error) {
+	builder := newNPSStatsBuilder(q)
+	iter := s.periodQuery(q.From, q.To).Select(npsStatsFields...).Documents(ctx)
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return NPSStats{}, err
+		}
+		if response, ok := npsResponseFromDoc(doc); ok {
+			builder.add(response)
+		}
+	}
+	return builder.result(), nil
+}
+
+// parseNPSStatsQuery lê from e to (AAAA-MM-DD, no horário de Brasília), interval
+// (day ou week) e segment (model, promptVersion ou questionCount)
+func parseNPSStatsQuery(r *http.Request) (NPSStatsQuery, error) {
+	params := r.URL.Query()
+	today := time.Now().In(brasilia)
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, brasilia)
+
+	to := today
+	if raw := strings.TrimSpace(params.Get("to")); raw != "" {
+		parsed, err := time.ParseInLocation(npsStatsDateLayout, raw, brasilia)
+		if err != nil {
+			return NPSStatsQuery{}, fmt.Errorf("to deve estar no formato AAAA-MM-DD")
+		}
+		to = parsed
+	}
+	from := to.AddDate(0, 0, -(defaultNPSStatsDays - 1))
+	if raw := strings.TrimSpace(params.Get("from")); raw != "" {
+		parsed, err := time.ParseInLocation(npsStatsDateLayout, raw, brasilia)
+		if err != nil {
+			return NPSStatsQuery{}, fmt.Errorf("from deve estar no formato AAAA-MM-DD")
+		}
+		from = parsed
+	}
+
+	q := NPSStatsQuery{From: from, To: to.AddDate(0, 0, 1)}
+	days := int(q.To.Sub(q.From).Hours()/24 + 0.5)
+	if days < 1 {
+		return q, fmt.Errorf("from deve ser anterior ou igual a to")
+	}
+	if days > maxNPSStatsDays {
+		return q, fmt.Errorf("o período deve ter no máximo %d dias", maxNPSStatsDays)
+	}
+
+	switch interval := strings.TrimSpace(params.Get("interval")); interval {
+	case npsIntervalDay, npsIntervalWeek:
+		q.Interval = interval
+	case "":
+		q.Interval = npsIntervalDay
+		if days > dailySeriesMaxDays {
+			q.Interval = npsIntervalWeek
+		}
+	default:
+		return q, fmt.Errorf("interval deve ser day ou week")
+	}
+
+	switch segment := strings.TrimSpace(params.Get("segment")); segment {
+	case "", npsSegmentByModel, npsSegmentByPrompt, npsSegmentByQuestionCount:
+		q.Segment = segment
+	default:
+		return q, fmt.Errorf("segment deve ser model, promptVersion ou questionCount")
+	}
+	return q, nil
+}
+
+func handleNPSStats(w http.ResponseWriter, r *http.Request) {
+	if npsStore == nil {
+		writeJSONError(w, http.StatusServiceUnavailable, "armazenamento de pesquisas indisponível")
+		return
+	}
+	q, err := parseNPSStatsQuery(r)
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	stats, err := npsStore.Stats(r.Context(), q)
+	if err != nil {
+		log.Printf("erro ao calcular estatísticas NPS: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "não foi possível calcular as estatísticas no momento")
+		return
+	}
+	json.NewEncoder(w).Encode(stats)
+}
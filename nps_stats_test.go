@@ -0,0 +1,49 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestNPSStatsRemoveFlagged garante que descontar das contagens agregadas as
+// respostas suspeitas dá o mesmo resultado do cálculo a partir dos documentos
+func TestNPSStatsRemoveFlagged(t *testing.T) {
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, brasilia)
+	q := NPSStatsQuery{From: from, To: from.AddDate(0, 0, 7), Interval: npsIntervalDay}
+	responses := []NPSResponse{
+		{Score: 10, SubmittedAt: "2026-10-01T15:00:00Z"},
+		{Score: 3, SubmittedAt: "2026-10-02T15:00:00Z"},
+		{Score: 9, SubmittedAt: "2026-10-02T16:00:00Z", Status: npsStatusFlagged},
+		{Score: 7, SubmittedAt: "2026-10-03T15:00:00Z", Status: npsStatusFlagged},
+		// fora do período
+		{Score: 0, SubmittedAt: "2026-10-09T15:00:00Z", Status: npsStatusFlagged},
+	}
+
+	scanned := newNPSStatsBuilder(q)
+	for _, r := range responses {
+		scanned.add(r)
+	}
+
+	// As contagens agregadas incluem as suspeitas, que depois são descontadas
+	aggregated := newNPSStatsBuilder(q)
+	for _, r := range responses {
+		clean := r
+		clean.Status = ""
+		aggregated.add(clean)
+	}
+	for _, r := range responses {
+		if r.Status == npsStatusFlagged {
+			submittedAt, _ := time.Parse(time.RFC3339, r.SubmittedAt)
+			aggregated.removeFlagged(r.Score, submittedAt)
+		}
+	}
+
+	want, got := scanned.result(), aggregated.result()
+	if got.Flagged != 2 || got.Total != 2 || got.Promoters != 1 || got.Detractors != 1 {
+		t.Errorf("estatísticas inesperadas: %+v", got)
+	}
+	if got.Flagged != want.Flagged || !reflect.DeepEqual(got.Histogram, want.Histogram) || !reflect.DeepEqual(got.Series, want.Series) {
+		t.Errorf("descontar as suspeitas diverge da leitura dos documentos:\n%+v\n%+v", got, want)
+	}
+}
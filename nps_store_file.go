@@ -0,0 +1,613 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// npsCompactEvery define a cada quantas gravações o arquivo é compactado
+	npsCompactEvery = 1000
+	// maxNPSLineSize limita o tamanho de uma linha lida do arquivo
+	maxNPSLineSize = 1024 * 1024
+)
+
+// NPSStore grava as respostas em um arquivo JSONL local: cada resposta é uma
+// linha acrescentada ao fim do arquivo e sincronizada com o disco, sem
+// reescrever as anteriores. As respostas não ficam em memória, só um índice
+// com a posição de cada linha e os campos de ordenação, montado na abertura e
+// atualizado a cada gravação. A compactação periódica reescreve o arquivo em
+// ordem cronológica, sem linhas inválidas nem ids repetidos.
+type NPSStore struct {
+	filePath string
+	file     *os.File
+	// size é o tamanho do arquivo, onde começa a próxima linha
+	size int64
+	// index fica na ordem de submittedAt e id, da mais antiga para a mais recente
+	index []npsIndexEntry
+	ids   map[string]bool
+	// appends conta as gravações desde a última compactação
+	appends int
+	mutex   sync.RWMutex
+}
+
+// npsIndexEntry localiza uma resposta no arquivo e guarda os campos usados
+// para ordenar e filtrar a listagem sem ler a linha
+type npsIndexEntry struct {
+	ID          string
+	SubmittedAt string
+	Fingerprint string
+	IPKey       string
+	Score       int
+	offset      int64
+	size        int
+}
+
+func newNPSIndexEntry(r NPSResponse, offset int64, size int) npsIndexEntry {
+	return npsIndexEntry{ID: r.ID, SubmittedAt: r.SubmittedAt, Fingerprint: r.Fingerprint, IPKey: r.IPKey, Score: r.Score, offset: offset, size: size}
+}
+
+// after informa se a entrada vem depois de submittedAt e id na ordem do índice
+func (e npsIndexEntry) after(submittedAt, id string) bool {
+	if e.SubmittedAt != submittedAt {
+		return e.SubmittedAt > submittedAt
+	}
+	return e.ID > id
+}
+
+// NewNPSStore abre o arquivo, recuperando-o se a última linha ficou
+// incompleta. Se ele ainda não existe, importa o array JSON antigo. Um
+// caminho que aponta para o próprio array JSON é lido em um .jsonl ao lado.
+func NewNPSStore(filePath string) (*NPSStore, error) {
+	store := &NPSStore{filePath: filePath}
+
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func (s *NPSStore) load() error {
+	if dir := filepath.Dir(s.filePath); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	legacyPath := ""
+	legacy, err := isLegacyNPSFile(s.filePath)
+	switch {
+	case err == nil && legacy:
+		// O caminho é o array JSON antigo (nps-migrate -file data/nps-responses.json):
+		// ele continua intacto, como origem da migração e para a versão anterior
+		legacyPath = s.filePath
+		s.filePath = jsonlNPSPath(legacyPath)
+	case err != nil && !os.IsNotExist(err):
+		return err
+	case err != nil:
+		legacyPath = legacyNPSPath(s.filePath)
+	}
+
+	if _, err := os.Stat(s.filePath); err == nil {
+		if err := s.recover(); err != nil {
+			return err
+		}
+		if err := s.openForAppend(); err != nil {
+			return err
+		}
+		if err := s.buildIndexLocked(); err != nil {
+			return err
+		}
+		if legacy {
+			// Respostas gravadas no array depois da última leitura
+			return s.mergeLegacy(legacyPath)
+		}
+		return nil
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	if legacyPath != "" {
+		if _, err := os.Stat(legacyPath); err == nil {
+			return s.convertLegacy(legacyPath)
+		}
+	}
+	if err := s.openForAppend(); err != nil {
+		return err
+	}
+	return s.buildIndexLocked()
+}
+
+// jsonlNPSPath devolve o arquivo JSONL que recebe as respostas de um array
+// JSON antigo passado diretamente (nps-responses.json vira nps-responses.jsonl)
+func jsonlNPSPath(legacyPath string) string {
+	if strings.HasSuffix(legacyPath, ".json") {
+		return legacyPath + "l"
+	}
+	return legacyPath + ".jsonl"
+}
+
+// legacyNPSPath devolve o array JSON usado antes do JSONL (data/nps-responses.json
+// para data/nps-responses.jsonl); ele é lido uma vez e mantido como está
+func legacyNPSPath(filePath string) string {
+	if !strings.HasSuffix(filePath, ".jsonl") {
+		return ""
+	}
+	return strings.TrimSuffix(filePath, "l")
+}
+
+// isLegacyNPSFile informa se o arquivo é um array JSON (formato antigo)
+func isLegacyNPSFile(filePath string) (bool, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for {
+		b, err := reader.ReadByte()
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if b != ' ' && b != '\n' && b != '\r' && b != '\t' {
+			return b == '[', nil
+		}
+	}
+}
+
+// readLegacyNPSFile lê as respostas do array JSON antigo; as respostas sem id
+// ficam com id vazio
+func readLegacyNPSFile(legacyPath string) ([]NPSResponse, error) {
+	payload, err := os.ReadFile(legacyPath)
+	if err != nil {
+		return nil, err
+	}
+	var responses []NPSResponse
+	if len(bytes.TrimSpace(payload)) > 0 {
+		if err := json.Unmarshal(payload, &responses); err != nil {
+			return nil, err
+		}
+	}
+	return responses, nil
+}
+
+// convertLegacy lê o array JSON de legacyPath e grava as respostas no formato
+// JSONL em s.filePath; o arquivo antigo não é alterado
+func (s *NPSStore) convertLegacy(legacyPath string) error {
+	responses, err := readLegacyNPSFile(legacyPath)
+	if err != nil {
+		return err
+	}
+	responses = withNPSContentIDs(responses)
+	if err := s.rewriteLocked(responses); err != nil {
+		return err
+	}
+	log.Printf("📁 %d respostas NPS importadas de %s para %s", len(responses), legacyPath, s.filePath)
+	return nil
+}
+
+// mergeLegacy acrescenta as respostas do array JSON antigo que ainda não estão
+// no arquivo. As sem id são reconhecidas pelo hash do conteúdo, o mesmo id
+// que receberam na conversão.
+func (s *NPSStore) mergeLegacy(legacyPath string) error {
+	responses, err := readLegacyNPSFile(legacyPath)
+	if err != nil {
+		return err
+	}
+	var pending []NPSResponse
+	for _, response := range withNPSContentIDs(responses) {
+		if !s.ids[response.ID] {
+			pending = append(pending, response)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	if err := s.appendLocked(pending...); err != nil {
+		return err
+	}
+	log.Printf("📁 %d respostas NPS importadas de %s para %s", len(pending), legacyPath, s.filePath)
+	return nil
+}
+
+// forEachNPSLine chama fn para cada linha de r com a posição e o tamanho em
+// bytes, quebra incluída, até fn devolver false. Linhas acima de
+// maxNPSLineSize chegam com line nil, sem ficar em memória; complete indica
+// se a linha termina com a quebra. line só vale durante a chamada.
+func forEachNPSLine(r io.Reader, fn func(offset int64, size int, line []byte, complete bool) bool) error {
+	reader := bufio.NewReaderSize(r, 64*1024)
+	var offset int64
+	var line []byte
+	size := 0
+	oversized := false
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		size += len(chunk)
+		if !oversized {
+			if len(line)+len(chunk) > maxNPSLineSize {
+				oversized = true
+				line = line[:0]
+			} else {
+				line = append(line, chunk...)
+			}
+		}
+		switch {
+		case err == bufio.ErrBufferFull:
+			continue
+		case err != nil && err != io.EOF:
+			return err
+		case size == 0:
+			return nil
+		}
+
+		content := line
+		if oversized {
+			content = nil
+		}
+		if !fn(offset, size, content, err == nil) || err == io.EOF {
+			return nil
+		}
+		offset += int64(size)
+		line, size, oversized = line[:0], 0, false
+	}
+}
+
+// recover confere o arquivo na abertura. Uma última linha incompleta (queda
+// durante uma gravação) é descartada; linhas inválidas ou grandes demais no
+// meio, ids repetidos ou ausentes levam a uma compactação imediata.
+func (s *NPSStore) recover() error {
+	file, err := os.OpenFile(s.filePath, os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var end, goodEnd int64
+	var tail []byte
+	needsCompaction := false
+	ids := map[string]bool{}
+	err = forEachNPSLine(file, func(offset int64, size int, line []byte, complete bool) bool {
+		end = offset + int64(size)
+		if !complete {
+			tail = append([]byte{}, line...)
+			return true
+		}
+		goodEnd = end
+		trimmed := bytes.TrimSpace(line)
+		if line == nil {
+			needsCompaction = true
+		} else if len(trimmed) > 0 {
+			var response NPSResponse
+			if json.Unmarshal(trimmed, &response) != nil || response.ID == "" || ids[response.ID] {
+				needsCompaction = true
+			}
+			ids[response.ID] = true
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	if end > goodEnd {
+		var response NPSResponse
+		if json.Unmarshal(bytes.TrimSpace(tail), &response) == nil {
+			// A linha está completa, só faltou a quebra de linha
+			if _, err := file.WriteAt([]byte("\n"), end); err != nil {
+				return err
+			}
+			if response.ID == "" || ids[response.ID] {
+				needsCompaction = true
+			}
+		} else {
+			log.Printf("⚠️  %s: última linha incompleta (%d bytes) descartada", s.filePath, end-goodEnd)
+			if err := file.Truncate(goodEnd); err != nil {
+				return err
+			}
+		}
+		if err := file.Sync(); err != nil {
+			return err
+		}
+	}
+
+	if needsCompaction {
+		log.Printf("⚠️  %s tem linhas inválidas ou repetidas; compactando", s.filePath)
+		return s.compactLocked()
+	}
+	return nil
+}
+
+// openForAppend (re)abre o arquivo para acrescentar linhas
+func (s *NPSStore) openForAppend() error {
+	if s.file != nil {
+		s.file.Close()
+		s.file = nil
+	}
+	file, err := os.OpenFile(s.filePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+// scanLinesLocked percorre as respostas na ordem do arquivo, com a posição de
+// cada linha, até fn devolver false; linhas inválidas ou acima de
+// maxNPSLineSize são puladas e contadas
+func (s *NPSStore) scanLinesLocked(fn func(r NPSResponse, offset int64, size int) bool) (skipped int, err error) {
+	file, err := os.Open(s.filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer file.Close()
+
+	err = forEachNPSLine(file, func(offset int64, size int, line []byte, complete bool) bool {
+		if line == nil {
+			skipped++
+			return true
+		}
+		trimmed := bytes.TrimSpace(line)
+		if len(trimmed) == 0 {
+			return true
+		}
+		var response NPSResponse
+		if err := json.Unmarshal(trimmed, &response); err != nil {
+			skipped++
+			return true
+		}
+		return fn(response, offset, size)
+	})
+	return skipped, err
+}
+
+// scanLocked percorre as respostas na ordem do arquivo até fn devolver false
+func (s *NPSStore) scanLocked(fn func(NPSResponse) bool) (int, error) {
+	return s.scanLinesLocked(func(r NPSResponse, offset int64, size int) bool {
+		return fn(r)
+	})
+}
+
+// scan percorre as respostas com o arquivo travado para leitura
+func (s *NPSStore) scan(fn func(NPSResponse) bool) (int, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.scanLocked(fn)
+}
+
+// buildIndexLocked monta o índice lendo o arquivo; vale a primeira linha de cada id
+func (s *NPSStore) buildIndexLocked() error {
+	index := []npsIndexEntry{}
+	ids := map[string]bool{}
+	if _, err := s.scanLinesLocked(func(r NPSResponse, offset int64, size int) bool {
+		if r.ID != "" && !ids[r.ID] {
+			ids[r.ID] = true
+			index = append(index, newNPSIndexEntry(r, offset, size))
+		}
+		return true
+	}); err != nil {
+		return err
+	}
+	sort.SliceStable(index, func(i, j int) bool {
+		return index[j].after(index[i].SubmittedAt, index[i].ID)
+	})
+	s.index, s.ids = index, ids
+	return nil
+}
+
+// indexLocked insere no índice uma resposta recém-gravada, mantendo a ordem
+func (s *NPSStore) indexLocked(entry npsIndexEntry) {
+	if s.ids[entry.ID] {
+		return
+	}
+	i := sort.Search(len(s.index), func(i int) bool {
+		return s.index[i].after(entry.SubmittedAt, entry.ID)
+	})
+	s.index = append(s.index, npsIndexEntry{})
+	copy(s.index[i+1:], s.index[i:])
+	s.index[i] = entry
+	s.ids[entry.ID] = true
+}
+
+// readNPSEntry lê do arquivo a resposta apontada pela entrada do índice
+func readNPSEntry(file *os.File, entry npsIndexEntry) (NPSResponse, error) {
+	line := make([]byte, entry.size)
+	if _, err := file.ReadAt(line, entry.offset); err != nil {
+		return NPSResponse{}, err
+	}
+	var response NPSResponse
+	err := json.Unmarshal(bytes.TrimSpace(line), &response)
+	return response, err
+}
+
+// appendLocked acrescenta as respostas ao fim do arquivo, sincroniza com o
+// disco e atualiza o índice
+func (s *NPSStore) appendLocked(entries ...NPSResponse) error {
+	if s.file == nil {
+		return errors.New("armazenamento NPS fechado")
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	index := make([]npsIndexEntry, 0, len(entries))
+	for _, entry := range entries {
+		start := buf.Len()
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+		index = append(index, newNPSIndexEntry(entry, s.size+int64(start), buf.Len()-start))
+	}
+	n, err := s.file.Write(buf.Bytes())
+	if err == nil {
+		err = s.file.Sync()
+	}
+	if err != nil {
+		// A resposta não foi confirmada: uma linha parcial deixada no fim se
+		// juntaria à próxima gravação, então o arquivo volta ao tamanho anterior
+		if n > 0 {
+			if truncErr := s.file.Truncate(s.size); truncErr != nil {
+				log.Printf("⚠️  não foi possível desfazer a gravação parcial em %s: %v", s.filePath, truncErr)
+				s.size += int64(n)
+				if compactErr := s.compactLocked(); compactErr != nil {
+					log.Printf("⚠️  não foi possível compactar %s: %v", s.filePath, compactErr)
+				}
+			}
+		}
+		return err
+	}
+	s.size += int64(n)
+	for _, entry := range index {
+		s.indexLocked(entry)
+	}
+	s.appends += len(entries)
+	if s.appends >= npsCompactEvery {
+		if err := s.compactLocked(); err != nil {
+			// A gravação já está no disco; a compactação fica para a próxima vez
+			log.Printf("⚠️  não foi possível compactar %s: %v", s.filePath, err)
+		}
+	}
+	return nil
+}
+
+// compactLocked reescreve o arquivo com as respostas válidas, uma por id, da
+// mais antiga para a mais recente
+func (s *NPSStore) compactLocked() error {
+	var responses []NPSResponse
+	skipped, err := s.scanLocked(func(r NPSResponse) bool {
+		responses = append(responses, r)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	responses = withNPSContentIDs(responses)
+	if skipped > 0 {
+		log.Printf("⚠️  %d linhas inválidas descartadas de %s", skipped, s.filePath)
+	}
+	return s.rewriteLocked(responses)
+}
+
+// rewriteLocked grava as respostas em um arquivo temporário, sincroniza, o
+// troca pelo atual e sincroniza o diretório, reabrindo o arquivo para as próximas gravações e
+// remontando o índice com as novas posições
+func (s *NPSStore) rewriteLocked(responses []NPSResponse) error {
+	sort.SliceStable(responses, func(i, j int) bool {
+		if responses[i].SubmittedAt != responses[j].SubmittedAt {
+			return responses[i].SubmittedAt < responses[j].SubmittedAt
+		}
+		return responses[i].ID < responses[j].ID
+	})
+
+	tempPath := s.filePath + ".tmp"
+	temp, err := os.OpenFile(tempPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	writer := bufio.NewWriter(temp)
+	index := []npsIndexEntry{}
+	ids := map[string]bool{}
+	var offset int64
+	for _, response := range responses {
+		buf.Reset()
+		if err := encoder.Encode(response); err != nil {
+			temp.Close()
+			return err
+		}
+		if !ids[response.ID] {
+			ids[response.ID] = true
+			index = append(index, newNPSIndexEntry(response, offset, buf.Len()))
+		}
+		offset += int64(buf.Len())
+		if _, err := writer.Write(buf.Bytes()); err != nil {
+			temp.Close()
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+
+	if s.file != nil {
+		s.file.Close()
+		s.file = nil
+	}
+	if err := os.Rename(tempPath, s.filePath); err != nil {
+		return err
+	}
+	// A troca já aconteceu: o índice e o arquivo aberto passam para o novo
+	// mesmo que a sincronização do diretório falhe
+	dirErr := syncNPSDir(filepath.Dir(s.filePath))
+	s.appends = 0
+	s.index, s.ids = index, ids
+	if err := s.openForAppend(); err != nil {
+		return err
+	}
+	return dirErr
+}
+
+// syncNPSDir grava no disco a entrada do diretório, para a troca do arquivo
+// por rename sobreviver a uma queda
+func syncNPSDir(dir string) error {
+	file, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return file.Sync()
+}
+
+// Add acrescenta uma resposta ao arquivo
+func (s *NPSStore) Add(entry NPSResponse) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if entry.ID == "" {
+		entry.ID = newNPSID()
+	}
+	return s.appendLocked(entry)
+}
+
+// Close fecha o arquivo
+func (s *NPSStore) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listNPSIDs percorre todas as páginas da listagem e devolve os ids na ordem
+func listNPSIDs(t *testing.T, store NPSStoreInterface, q NPSListQuery) []string {
+	t.Helper()
+	var ids []string
+	for {
+		page, err := store.List(context.Background(), q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range page.Responses {
+			ids = append(ids, r.ID)
+		}
+		if page.NextCursor == "" {
+			return ids
+		}
+		q.Cursor = page.NextCursor
+	}
+}
+
+// npsFileIDs devolve os ids das linhas do arquivo, falhando se alguma linha
+// não for uma resposta válida ou o arquivo não terminar em quebra de linha
+func npsFileIDs(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		t.Errorf("o arquivo deveria terminar em quebra de linha")
+	}
+	var ids []string
+	for _, line := range bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n")) {
+		var response NPSResponse
+		if err := json.Unmarshal(line, &response); err != nil {
+			t.Fatalf("linha inválida no arquivo: %q", line)
+		}
+		ids = append(ids, response.ID)
+	}
+	return ids
+}
+
+func TestNPSStoreRecover(t *testing.T) {
+	a := `{"id":"a","score":10,"submittedAt":"2026-10-16T12:00:00Z"}`
+	b := `{"id":"b","score":3,"submittedAt":"2026-10-16T13:00:00Z"}`
+	huge := `{"id":"x","feedback":"` + strings.Repeat("x", maxNPSLineSize) + `"}`
+
+	tests := []struct {
+		name    string
+		content string
+		ids     []string
+		// lines são os ids das linhas do arquivo depois da abertura
+		lines []string
+	}{
+		{"última linha truncada", a + "\n" + `{"id":"b","sco`, []string{"a"}, []string{"a"}},
+		{"última linha sem quebra", a + "\n" + b, []string{"b", "a"}, []string{"a", "b"}},
+		{"id repetido", a + "\n" + b + "\n" + `{"id":"a","score":0,"submittedAt":"2026-10-16T14:00:00Z"}` + "\n", []string{"b", "a"}, []string{"a", "b"}},
+		{"linha inválida no meio", a + "\nnão é json\n" + b + "\n", []string{"b", "a"}, []string{"a", "b"}},
+		{"linha grande demais no meio", a + "\n" + huge + "\n" + b + "\n", []string{"b", "a"}, []string{"a", "b"}},
+		{"arquivo íntegro", b + "\n" + a + "\n", []string{"b", "a"}, []string{"b", "a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "nps-responses.jsonl")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			store, err := NewNPSStore(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer store.Close()
+
+			if ids := listNPSIDs(t, store, NPSListQuery{}); fmt.Sprint(ids) != fmt.Sprint(tt.ids) {
+				t.Errorf("ids %v, esperava %v", ids, tt.ids)
+			}
+			if lines := npsFileIDs(t, path); fmt.Sprint(lines) != fmt.Sprint(tt.lines) {
+				t.Errorf("linhas do arquivo %v, esperava %v", lines, tt.lines)
+			}
+
+			// A próxima gravação começa em uma linha nova e entra no índice
+			if err := store.Add(NPSResponse{ID: "c", Score: 7, SubmittedAt: "2026-10-16T15:00:00Z"}); err != nil {
+				t.Fatal(err)
+			}
+			if ids := listNPSIDs(t, store, NPSListQuery{Limit: 1}); len(ids) != len(tt.ids)+1 || ids[0] != "c" {
+				t.Errorf("ids depois da gravação: %v", ids)
+			}
+		})
+	}
+}
+
+func TestNPSStoreSkipsOversizedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nps-responses.jsonl")
+	store, err := NewNPSStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if err := store.Add(NPSResponse{ID: "a", Score: 9, SubmittedAt: "2026-10-16T12:00:00Z"}); err != nil {
+		t.Fatal(err)
+	}
+	// Uma linha grande demais gravada depois da abertura não trava as leituras
+	if err := store.Add(NPSResponse{ID: "x", Score: 1, Feedback: strings.Repeat("x", maxNPSLineSize), SubmittedAt: "2026-10-16T13:00:00Z"}); err != nil {
+		t.Fatal(err)
+	}
+	all, skipped, err := store.all(context.Background())
+	if err != nil || len(all) != 1 || skipped != 1 {
+		t.Fatalf("esperava 1 resposta e 1 linha pulada: respostas=%d puladas=%d err=%v", len(all), skipped, err)
+	}
+	if _, err := store.Stats(context.Background(), NPSStatsQuery{From: time.Date(2026, 10, 1, 0, 0, 0, 0, brasilia), To: time.Date(2026, 11, 1, 0, 0, 0, 0, brasilia)}); err != nil {
+		t.Errorf("estatísticas falharam com a linha grande: %v", err)
+	}
+}
+
+func TestNPSStoreLegacyConversion(t *testing.T) {
+	legacy := `[{"id":"a","score":10,"submittedAt":"2026-10-16T12:00:00Z"},{"score":2,"submittedAt":"2026-10-16T13:00:00Z"}]`
+
+	t.Run("caminho do JSONL", func(t *testing.T) {
+		dir := t.TempDir()
+		legacyPath := filepath.Join(dir, "nps-responses.json")
+		os.WriteFile(legacyPath, []byte(legacy), 0o644)
+
+		store, err := NewNPSStore(filepath.Join(dir, "nps-responses.jsonl"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer store.Close()
+		if ids := listNPSIDs(t, store, NPSListQuery{}); len(ids) != 2 || ids[1] != "a" || ids[0] == "" {
+			t.Errorf("respostas importadas: %v", ids)
+		}
+		if got, _ := os.ReadFile(legacyPath); string(got) != legacy {
+			t.Errorf("o arquivo antigo foi alterado")
+		}
+	})
+
+	t.Run("caminho do array antigo", func(t *testing.T) {
+		dir := t.TempDir()
+		legacyPath := filepath.Join(dir, "nps-responses.json")
+		os.WriteFile(legacyPath, []byte(legacy), 0o644)
+
+		store, err := NewNPSStore(legacyPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if store.filePath != filepath.Join(dir, "nps-responses.jsonl") {
+			t.Errorf("as respostas deveriam ir para um .jsonl ao lado: %s", store.filePath)
+		}
+		if ids := listNPSIDs(t, store, NPSListQuery{}); len(ids) != 2 {
+			t.Errorf("respostas importadas: %v", ids)
+		}
+		store.Close()
+		if got, _ := os.ReadFile(legacyPath); string(got) != legacy {
+			t.Fatalf("o arquivo antigo, origem do nps-migrate, foi alterado")
+		}
+
+		// Respostas acrescentadas ao array depois da conversão entram na próxima abertura
+		updated := strings.TrimSuffix(legacy, "]") + `,{"id":"b","score":8,"submittedAt":"2026-10-16T14:00:00Z"}]`
+		os.WriteFile(legacyPath, []byte(updated), 0o644)
+		store, err = NewNPSStore(legacyPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer store.Close()
+		if ids := listNPSIDs(t, store, NPSListQuery{}); len(ids) != 3 || ids[0] != "b" {
+			t.Errorf("respostas depois da nova abertura: %v", ids)
+		}
+	})
+}
+
+func TestNPSStoreCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nps-responses.jsonl")
+	store, err := NewNPSStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	// Gravadas da mais recente para a mais antiga; a última gravação repete um id
+	base := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	entries := make([]NPSResponse, 0, npsCompactEvery-1)
+	for i := npsCompactEvery - 1; i > 0; i-- {
+		entries = append(entries, NPSResponse{ID: fmt.Sprintf("r%04d", i), Score: i % 11, SubmittedAt: base.Add(time.Duration(i) * time.Minute).Format(time.RFC3339)})
+	}
+	if _, err := store.importAll(context.Background(), entries); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Add(NPSResponse{ID: "r0500", Score: 0, SubmittedAt: base.Format(time.RFC3339)}); err != nil {
+		t.Fatal(err)
+	}
+	if store.appends != 0 {
+		t.Fatalf("esperava a compactação depois de %d gravações, appends=%d", npsCompactEvery, store.appends)
+	}
+	lines := npsFileIDs(t, path)
+	if len(lines) != npsCompactEvery-1 || lines[0] != "r0001" || lines[len(lines)-1] != fmt.Sprintf("r%04d", npsCompactEvery-1) {
+		t.Errorf("o arquivo compactado deveria ter uma linha por id, da mais antiga para a mais recente: %d linhas", len(lines))
+	}
+
+	// O índice acompanha as novas posições das linhas
+	ids := listNPSIDs(t, store, NPSListQuery{Limit: 200})
+	if len(ids) != npsCompactEvery-1 || ids[0] != fmt.Sprintf("r%04d", npsCompactEvery-1) || ids[len(ids)-1] != "r0001" {
+		t.Errorf("listagem depois da compactação: %d ids, %v...", len(ids), ids[:3])
+	}
+	page, err := store.List(context.Background(), NPSListQuery{From: base.Add(999 * time.Minute), Limit: 5})
+	if err != nil || len(page.Responses) != 1 || page.Responses[0].ID != "r0999" || page.Responses[0].Score != 999%11 {
+		t.Errorf("leitura pelo índice depois da compactação: %+v %v", page.Responses, err)
+	}
+}
+
+func TestNPSStoreFailedAppend(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nps.jsonl")
+	store, err := NewNPSStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if err := store.Add(NPSResponse{ID: "a", Score: 9, SubmittedAt: "2026-10-16T12:00:00Z"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Com o arquivo aberto só para leitura, a gravação falha sem entrar no índice
+	writable := store.file
+	readOnly, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.file = readOnly
+	if err := store.Add(NPSResponse{ID: "b", Score: 3, SubmittedAt: "2026-10-16T13:00:00Z"}); err == nil {
+		t.Fatal("esperava erro na gravação")
+	}
+	readOnly.Close()
+	store.file = writable
+
+	if err := store.Add(NPSResponse{ID: "c", Score: 7, SubmittedAt: "2026-10-16T14:00:00Z"}); err != nil {
+		t.Fatal(err)
+	}
+	if ids := listNPSIDs(t, store, NPSListQuery{}); strings.Join(ids, ",") != "c,a" {
+		t.Errorf("listagem depois da falha: %v", ids)
+	}
+	if ids := npsFileIDs(t, path); strings.Join(ids, ",") != "a,c" {
+		t.Errorf("arquivo depois da falha: %v", ids)
+	}
+}
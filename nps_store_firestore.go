@@ -3,22 +3,20 @@ package main
 import (
 	"context"
 	"log"
-	"sync"
 	"time"
 
 	"chat-bot/internal/config"
+	"chat-bot/internal/outbox"
 	"chat-bot/internal/services"
-
-	"cloud.google.com/go/firestore"
-	"google.golang.org/api/iterator"
 )
 
 // NPSStoreFirestore armazena respostas NPS no Firestore
 type NPSStoreFirestore struct {
 	firestoreService *services.FirestoreService
 	collection       string
-	mutex            sync.RWMutex
 	ctx              context.Context
+	// outbox guarda as respostas que o Firestore recusou até serem aceitas; opcional
+	outbox *outbox.Store
 }
 
 // NewNPSStoreFirestore cria uma nova instância do store usando Firestore
@@ -43,92 +41,60 @@ func NewNPSStoreFirestore(ctx context.Context, cfg *config.Config) (*NPSStoreFir
 	return store, nil
 }
 
-// Add adiciona uma nova resposta NPS ao Firestore
+// Add adiciona uma nova resposta NPS ao Firestore. Com a fila de gravações
+// pendentes, uma resposta que o Firestore não aceitar por uma falha temporária
+// é guardada em disco e reenviada em segundo plano; a resposta só é confirmada
+// depois de chegar a um dos dois. Nenhuma trava é mantida durante a chamada ao Firestore.
 func (s *NPSStoreFirestore) Add(entry NPSResponse) error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	// Adiciona timestamp de criação se não existir
-	docData := map[string]interface{}{
-		"score":          entry.Score,
-		"classification": entry.Classification,
-		"reasons":        entry.Reasons,
-		"feedback":       entry.Feedback,
-		"submittedAt":    entry.SubmittedAt,
-		"createdAt":      time.Now().UTC().Format(time.RFC3339),
-	}
-
-	client := s.firestoreService.GetClient()
-	_, _, err := client.Collection(s.collection).Add(s.ctx, docData)
-	if err != nil {
-		log.Printf("erro ao adicionar resposta NPS ao Firestore: %v", err)
-		return err
+	if entry.ID == "" {
+		entry.ID = newNPSID()
 	}
 
-	log.Printf("resposta NPS salva no Firestore: score=%d, classification=%s", entry.Score, entry.Classification)
-	return nil
-}
-
-// List retorna todas as respostas NPS do Firestore
-func (s *NPSStoreFirestore) List() []NPSResponse {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
-
-	client := s.firestoreService.GetClient()
-	var responses []NPSResponse
-	iter := client.Collection(s.collection).OrderBy("submittedAt", firestore.Desc).Documents(s.ctx)
-
-	for {
-		doc, err := iter.Next()
-		if err == iterator.Done {
-			break
+	if s.outbox != nil {
+		queued, err := s.outbox.Deliver(s.ctx, entry.ID, entry, s.deliver)
+		if err != nil && queued {
+			log.Printf("⚠️  resposta NPS %s ficou na fila para nova tentativa: %v", entry.ID, err)
+			return nil
 		}
 		if err != nil {
-			log.Printf("erro ao ler documento do Firestore: %v", err)
-			continue
+			log.Printf("erro ao adicionar resposta NPS ao Firestore: %v", err)
+			return err
 		}
-
-		var data map[string]interface{}
-		if err := doc.DataTo(&data); err != nil {
-			log.Printf("erro ao converter documento do Firestore: %v", err)
-			continue
-		}
-
-		// Converte score de forma segura
-		var score int
-		switch v := data["score"].(type) {
-		case int64:
-			score = int(v)
-		case int:
-			score = v
-		case float64:
-			score = int(v)
-		default:
-			log.Printf("tipo de score inválido: %T", v)
-			continue
-		}
-
-		response := NPSResponse{
-			Score:          score,
-			Classification: getString(data, "classification"),
-			Feedback:       getString(data, "feedback"),
-			SubmittedAt:    getString(data, "submittedAt"),
+	} else {
+		// O id da resposta é o id do documento, usado como desempate na paginação
+		ctx, cancel := context.WithTimeout(s.ctx, npsWriteTimeout)
+		defer cancel()
+		client := s.firestoreService.GetClient()
+		_, err := client.Collection(s.collection).Doc(entry.ID).Create(ctx, npsDocData(entry))
+		if err != nil {
+			log.Printf("erro ao adicionar resposta NPS ao Firestore: %v", err)
+			return err
 		}
+	}
 
-		// Converte reasons se existir
-		if reasons, ok := data["reasons"].([]interface{}); ok {
-			response.Reasons = make([]string, 0, len(reasons))
-			for _, r := range reasons {
-				if str, ok := r.(string); ok {
-					response.Reasons = append(response.Reasons, str)
-				}
-			}
-		}
+	log.Printf("resposta NPS salva no Firestore: score=%d, classification=%s", entry.Score, entry.Classification)
+	return nil
+}
 
-		responses = append(responses, response)
+// npsDocData monta o documento gravado para uma resposta, com a data de criação
+func npsDocData(entry NPSResponse) map[string]interface{} {
+	return map[string]interface{}{
+		"score":          entry.Score,
+		"classification": entry.Classification,
+		"reasons":        entry.Reasons,
+		"feedback":       entry.Feedback,
+		"submittedAt":    entry.SubmittedAt,
+		"fingerprint":    entry.Fingerprint,
+		"ipKey":          entry.IPKey,
+		"status":         entry.Status,
+		"flagReasons":    entry.FlagReasons,
+		"sessionId":      entry.SessionID,
+		"conversationId": entry.ConversationID,
+		"model":          entry.Model,
+		"promptVersion":  entry.PromptVersion,
+		"questionCount":  entry.QuestionCount,
+		"createdAt":      time.Now().UTC().Format(time.RFC3339),
 	}
-
-	return responses
 }
 
 // Close fecha a conexão com o Firestore
@@ -149,8 +115,37 @@ func getString(data map[string]interface{}, key string) string {
 	return ""
 }
 
+// intValue converte números do Firestore (int64 ou float64) para int
+func intValue(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int64:
+		return int(v), true
+	case int:
+		return v, true
+	case float64:
+		return int(v), true
+	}
+	return 0, false
+}
+
+// stringSlice extrai as strings de um array do Firestore
+func stringSlice(value interface{}) []string {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil
+	}
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if str, ok := item.(string); ok {
+			out = append(out, str)
+		}
+	}
+	return out
+}
+
 // NPSStoreInterface define a interface comum para stores de NPS
 type NPSStoreInterface interface {
 	Add(entry NPSResponse) error
-	List() []NPSResponse
+	List(ctx context.Context, q NPSListQuery) (NPSPage, error)
+	Stats(ctx context.Context, q NPSStatsQuery) (NPSStats, error)
 }
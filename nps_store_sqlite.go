@@ -0,0 +1,298 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"chat-bot/internal/sqlitedb"
+)
+
+// npsSQLiteMigrations é o esquema das respostas NPS no SQLite. Datas ficam em
+// texto RFC 3339 UTC, que ordena como texto na mesma ordem do tempo; motivos
+// e marcações de suspeita ficam em arrays JSON.
+var npsSQLiteMigrations = []sqlitedb.Migration{
+	{
+		Version: 1,
+		Name:    "cria nps_responses",
+		SQL: `
+CREATE TABLE nps_responses (
+	id              TEXT PRIMARY KEY,
+	score           INTEGER NOT NULL CHECK (score BETWEEN 0 AND 10),
+	classification  TEXT    NOT NULL,
+	reasons         TEXT    NOT NULL DEFAULT '[]',
+	feedback        TEXT    NOT NULL DEFAULT '',
+	submitted_at    TEXT    NOT NULL,
+	fingerprint     TEXT    NOT NULL DEFAULT '',
+	status          TEXT    NOT NULL DEFAULT 'ok',
+	flag_reasons    TEXT    NOT NULL DEFAULT '[]',
+	session_id      TEXT    NOT NULL DEFAULT '',
+	conversation_id TEXT    NOT NULL DEFAULT '',
+	model           TEXT    NOT NULL DEFAULT '',
+	prompt_version  TEXT    NOT NULL DEFAULT '',
+	question_count  INTEGER NOT NULL DEFAULT 0,
+	created_at      TEXT    NOT NULL
+);
+CREATE INDEX nps_responses_submitted ON nps_responses (submitted_at DESC, id DESC);
+CREATE INDEX nps_responses_score ON nps_responses (score, submitted_at DESC);
+CREATE INDEX nps_responses_status ON nps_responses (status, submitted_at DESC);
+CREATE INDEX nps_responses_fingerprint ON nps_responses (fingerprint, submitted_at DESC);
+`,
+	},
+	{
+		// A versão 2 foi retirada; bancos que já a aplicaram seguem desta
+		Version: 3,
+		Name:    "adiciona ip_key",
+		SQL: `
+ALTER TABLE nps_responses ADD COLUMN ip_key TEXT NOT NULL DEFAULT '';
+CREATE INDEX nps_responses_ip_key ON nps_responses (ip_key, submitted_at DESC);
+`,
+	},
+}
+
+// npsSQLiteColumns são as colunas lidas por scanNPSResponse, na mesma ordem
+const npsSQLiteColumns = `id, score, classification, reasons, feedback, submitted_at, fingerprint, status,
+	flag_reasons, session_id, conversation_id, model, prompt_version, question_count, ip_key`
+
+// NPSStoreSQLite armazena respostas NPS em um banco SQLite local
+type NPSStoreSQLite struct {
+	db *sql.DB
+}
+
+// NewNPSStoreSQLite abre o banco em path e aplica as migrações pendentes
+func NewNPSStoreSQLite(ctx context.Context, path string) (*NPSStoreSQLite, error) {
+	db, err := sqlitedb.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := sqlitedb.Migrate(ctx, db, "nps", npsSQLiteMigrations); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &NPSStoreSQLite{db: db}, nil
+}
+
+// Close fecha o banco
+func (s *NPSStoreSQLite) Close() error {
+	return s.db.Close()
+}
+
+// normalizeSubmittedAt grava as datas em UTC para que a ordem como texto
+// continue igual à ordem do tempo
+func normalizeSubmittedAt(value string) string {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed.UTC().Format(time.RFC3339)
+	}
+	return value
+}
+
+// jsonArray serializa uma lista de textos; nil vira []
+func jsonArray(values []string) string {
+	if len(values) == 0 {
+		return "[]"
+	}
+	raw, _ := json.Marshal(values)
+	return string(raw)
+}
+
+// insertNPSResponse grava uma resposta com verb INSERT ou INSERT OR IGNORE
+func insertNPSResponse(ctx context.Context, exec func(context.Context, string, ...interface{}) (sql.Result, error), verb string, entry NPSResponse) (sql.Result, error) {
+	return exec(ctx, verb+` INTO nps_responses (`+npsSQLiteColumns+`, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.ID, entry.Score, entry.Classification, jsonArray(entry.Reasons), entry.Feedback,
+		normalizeSubmittedAt(entry.SubmittedAt), entry.Fingerprint, entry.status(), jsonArray(entry.FlagReasons),
+		entry.SessionID, entry.ConversationID, entry.Model, entry.PromptVersion, entry.QuestionCount, entry.IPKey,
+		time.Now().UTC().Format(time.RFC3339),
+	)
+}
+
+// Add grava uma resposta; o id é a chave primária. A gravação desiste depois
+// de npsWriteTimeout, inclusive esperando outra conexão liberar o banco
+func (s *NPSStoreSQLite) Add(entry NPSResponse) error {
+	if entry.ID == "" {
+		entry.ID = newNPSID()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), npsWriteTimeout)
+	defer cancel()
+	_, err := insertNPSResponse(ctx, s.db.ExecContext, "INSERT", entry)
+	return err
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanNPSResponse(row rowScanner) (NPSResponse, error) {
+	var r NPSResponse
+	var reasons, flagReasons string
+	err := row.Scan(&r.ID, &r.Score, &r.Classification, &reasons, &r.Feedback, &r.SubmittedAt, &r.Fingerprint,
+		&r.Status, &flagReasons, &r.SessionID, &r.ConversationID, &r.Model, &r.PromptVersion, &r.QuestionCount, &r.IPKey)
+	if err != nil {
+		return r, err
+	}
+	if err := json.Unmarshal([]byte(reasons), &r.Reasons); err != nil {
+		return r, err
+	}
+	if err := json.Unmarshal([]byte(flagReasons), &r.FlagReasons); err != nil {
+		return r, err
+	}
+	if len(r.Reasons) == 0 {
+		r.Reasons = nil
+	}
+	if len(r.FlagReasons) == 0 {
+		r.FlagReasons = nil
+	}
+	return r, nil
+}
+
+// sqlFilter monta a cláusula WHERE a partir de condições e argumentos
+type sqlFilter struct {
+	conditions []string
+	args       []interface{}
+}
+
+func (f *sqlFilter) add(condition string, args ...interface{}) {
+	f.conditions = append(f.conditions, condition)
+	f.args = append(f.args, args...)
+}
+
+func (f *sqlFilter) where() string {
+	if len(f.conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(f.conditions, " AND ")
+}
+
+// List pagina as respostas com todos os filtros aplicados na consulta
+func (s *NPSStoreSQLite) List(ctx context.Context, q NPSListQuery) (NPSPage, error) {
+	after, err := decodeNPSCursor(q.Cursor)
+	if err != nil {
+		return NPSPage{}, err
+	}
+
+	var filter sqlFilter
+	if min, max := q.scoreRange(); min > 0 || max < 10 {
+		filter.add("score BETWEEN ? AND ?", min, max)
+	}
+	if !q.From.IsZero() {
+		filter.add("submitted_at >= ?", q.From.UTC().Format(time.RFC3339))
+	}
+	if !q.To.IsZero() {
+		filter.add("submitted_at < ?", q.To.UTC().Format(time.RFC3339))
+	}
+	if q.HasFeedback != nil {
+		if *q.HasFeedback {
+			filter.add("feedback <> ''")
+		} else {
+			filter.add("feedback = ''")
+		}
+	}
+	if q.Reason != "" {
+		filter.add("EXISTS (SELECT 1 FROM json_each(nps_responses.reasons) WHERE json_each.value = ?)", q.Reason)
+	}
+	if q.Fingerprint != "" {
+		filter.add("fingerprint = ?", q.Fingerprint)
+	}
+	if q.IPKey != "" {
+		filter.add("ip_key = ?", q.IPKey)
+	}
+	if q.Status != "" {
+		filter.add("status = ?", q.Status)
+	}
+	if after != nil {
+		filter.add("(submitted_at, id) < (?, ?)", after.SubmittedAt, after.ID)
+	}
+
+	pager := newNPSPager(q.Limit)
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT `+npsSQLiteColumns+` FROM nps_responses`+filter.where()+
+			` ORDER BY submitted_at DESC, id DESC LIMIT ?`,
+		append(filter.args, pager.limit+1)...,
+	)
+	if err != nil {
+		return NPSPage{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		response, err := scanNPSResponse(rows)
+		if err != nil {
+			return NPSPage{}, err
+		}
+		if pager.offer(response) {
+			break
+		}
+	}
+	return pager.page, rows.Err()
+}
+
+// Stats lê só as colunas usadas nas estatísticas das respostas do período,
+// pelo índice de submitted_at
+func (s *NPSStoreSQLite) Stats(ctx context.Context, q NPSStatsQuery) (NPSStats, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT score, reasons, submitted_at, status, model, prompt_version, question_count
+		FROM nps_responses WHERE submitted_at >= ? AND submitted_at < ?`,
+		q.From.UTC().Format(time.RFC3339), q.To.UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return NPSStats{}, err
+	}
+	defer rows.Close()
+
+	builder := newNPSStatsBuilder(q)
+	for rows.Next() {
+		var r NPSResponse
+		var reasons string
+		if err := rows.Scan(&r.Score, &reasons, &r.SubmittedAt, &r.Status, &r.Model, &r.PromptVersion, &r.QuestionCount); err != nil {
+			return NPSStats{}, err
+		}
+		if err := json.Unmarshal([]byte(reasons), &r.Reasons); err != nil {
+			return NPSStats{}, err
+		}
+		builder.add(r)
+	}
+	return builder.result(), rows.Err()
+}
+
+func (s *NPSStoreSQLite) all(ctx context.Context) ([]NPSResponse, int, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+npsSQLiteColumns+` FROM nps_responses ORDER BY submitted_at DESC, id DESC`)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var responses []NPSResponse
+	for rows.Next() {
+		response, err := scanNPSResponse(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		responses = append(responses, response)
+	}
+	return responses, 0, rows.Err()
+}
+
+// importAll grava tudo em uma transação; ids já existentes são mantidos
+func (s *NPSStoreSQLite) importAll(ctx context.Context, entries []NPSResponse) (int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	imported := 0
+	for _, entry := range entries {
+		result, err := insertNPSResponse(ctx, tx.ExecContext, "INSERT OR IGNORE", entry)
+		if err != nil {
+			return 0, err
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			imported++
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return imported, nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestSQLiteStore(t *testing.T) *NPSStoreSQLite {
+	t.Helper()
+	store, err := NewNPSStoreSQLite(context.Background(), filepath.Join(t.TempDir(), "agoraai.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestNPSStoreSQLiteMigrations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agoraai.db")
+	for i := 0; i < 2; i++ {
+		store, err := NewNPSStoreSQLite(context.Background(), path)
+		if err != nil {
+			t.Fatalf("abertura %d: %v", i+1, err)
+		}
+		var indexes int
+		store.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = 'nps_responses_classification'`).Scan(&indexes)
+		if indexes != 0 {
+			t.Errorf("o índice de classification deveria ter sido removido")
+		}
+		store.Close()
+	}
+}
+
+func TestNPSStoreSQLiteListCursor(t *testing.T) {
+	store := newTestSQLiteStore(t)
+	// Três respostas no mesmo segundo: o id desempata a ordem
+	for _, r := range []NPSResponse{
+		{ID: "b", Score: 9, SubmittedAt: "2026-10-16T12:00:00Z"},
+		{ID: "c", Score: 5, SubmittedAt: "2026-10-16T12:00:00Z"},
+		{ID: "a", Score: 10, SubmittedAt: "2026-10-16T12:00:00Z"},
+		{ID: "d", Score: 7, SubmittedAt: "2026-10-16T09:00:00-03:00"},
+		{ID: "e", Score: 1, SubmittedAt: "2026-10-15T12:00:00Z"},
+	} {
+		if err := store.Add(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var ids []string
+	cursor := ""
+	for pages := 0; pages < 10; pages++ {
+		page, err := store.List(context.Background(), NPSListQuery{Limit: 2, Cursor: cursor})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range page.Responses {
+			ids = append(ids, r.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	// d foi enviada às 12:00 UTC, com fuso; a data é normalizada ao gravar
+	want := []string{"d", "c", "b", "a", "e"}
+	if len(ids) != len(want) {
+		t.Fatalf("ordem %v, esperava %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("ordem %v, esperava %v", ids, want)
+		}
+	}
+}
+
+func TestNPSStoreSQLiteFilters(t *testing.T) {
+	store := newTestSQLiteStore(t)
+	for _, r := range []NPSResponse{
+		{ID: "1", Score: 10, Reasons: []string{"precisão", "rapidez"}, Feedback: "ótimo", SubmittedAt: "2026-10-16T12:00:00Z"},
+		{ID: "2", Score: 3, Reasons: []string{"precisão"}, SubmittedAt: "2026-10-16T13:00:00Z"},
+		{ID: "3", Score: 8, Reasons: []string{"precisão de dados"}, SubmittedAt: "2026-10-16T14:00:00Z"},
+		{ID: "4", Score: 9, SubmittedAt: "2026-10-16T15:00:00Z", Status: npsStatusFlagged},
+	} {
+		if err := store.Add(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	yes := true
+	tests := []struct {
+		name  string
+		query NPSListQuery
+		want  []string
+	}{
+		{"motivo exato no array", NPSListQuery{Reason: "precisão"}, []string{"2", "1"}},
+		{"motivo sem correspondência parcial", NPSListQuery{Reason: "rap"}, nil},
+		{"classificação", NPSListQuery{Classification: npsSegmentPromoter}, []string{"4", "1"}},
+		{"com comentário", NPSListQuery{HasFeedback: &yes}, []string{"1"}},
+		{"suspeitas", NPSListQuery{Status: npsStatusFlagged}, []string{"4"}},
+	}
+	for _, tt := range tests {
+		page, err := store.List(context.Background(), tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, r := range page.Responses {
+			ids = append(ids, r.ID)
+		}
+		if len(ids) != len(tt.want) {
+			t.Errorf("%s: ids %v, esperava %v", tt.name, ids, tt.want)
+			continue
+		}
+		for i := range ids {
+			if ids[i] != tt.want[i] {
+				t.Errorf("%s: ids %v, esperava %v", tt.name, ids, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestNPSStoreSQLiteStats(t *testing.T) {
+	store := newTestSQLiteStore(t)
+	for _, r := range []NPSResponse{
+		{ID: "1", Score: 10, Reasons: []string{"precisão"}, SubmittedAt: "2026-10-14T15:00:00Z", Model: "m1"},
+		{ID: "2", Score: 9, SubmittedAt: "2026-10-15T15:00:00Z", Model: "m1"},
+		{ID: "3", Score: 2, Reasons: []string{"lentidão"}, SubmittedAt: "2026-10-15T16:00:00Z"},
+		{ID: "4", Score: 7, SubmittedAt: "2026-10-15T17:00:00Z", Status: npsStatusFlagged},
+		{ID: "5", Score: 0, SubmittedAt: "2026-09-01T12:00:00Z"},
+	} {
+		if err := store.Add(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	from := time.Date(2026, 10, 14, 0, 0, 0, 0, brasilia)
+	stats, err := store.Stats(context.Background(), NPSStatsQuery{From: from, To: from.AddDate(0, 0, 2), Interval: npsIntervalDay, Segment: npsSegmentByModel})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Total != 3 || stats.Promoters != 2 || stats.Detractors != 1 || stats.Flagged != 1 {
+		t.Fatalf("contagens inesperadas: %+v", stats)
+	}
+	if stats.NPS == nil || *stats.NPS != 33.3 || stats.Histogram[10] != 1 || stats.Histogram[7] != 0 {
+		t.Errorf("NPS ou histograma inesperados: nps=%v histograma=%v", stats.NPS, stats.Histogram)
+	}
+	if len(stats.Series) != 2 || stats.Series[0].Total != 1 || stats.Series[1].Total != 2 || stats.Series[1].Detractors != 1 {
+		t.Errorf("série inesperada: %+v", stats.Series)
+	}
+	if len(stats.Segments) != 2 || stats.Segments[0].Value != "m1" || stats.Segments[1].Value != npsSegmentUnknown {
+		t.Errorf("segmentos inesperados: %+v", stats.Segments)
+	}
+	if top := stats.TopReasons[npsSegmentDetractor]; len(top) != 1 || top[0].Reason != "lentidão" {
+		t.Errorf("motivos dos detratores inesperados: %+v", top)
+	}
+}
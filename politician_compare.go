@@ -0,0 +1,442 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"chat-bot/internal/registry"
+	"chat-bot/internal/scoring"
+)
+
+const (
+	minCompareIDs  = 2
+	maxCompareIDs  = 4
+	maxSharedVotes = 20
+	compareTimeout = 90 * time.Second
+)
+
+var errCompareCount = fmt.Errorf("informe de %d a %d parlamentares distintos", minCompareIDs, maxCompareIDs)
+
+// senadoAbsenceCodes são as siglas de voto do Senado que indicam ausência
+var senadoAbsenceCodes = map[string]struct{}{
+	"NCom": {}, "AP": {}, "LS": {}, "LP": {}, "LAP": {}, "MIS": {}, "REP": {}, "LG": {}, "LA": {},
+}
+
+// PartyPeriod é um período de filiação partidária
+type PartyPeriod struct {
+	Partido string `json:"partido"`
+	Inicio  string `json:"inicio,omitempty"`
+	Fim     string `json:"fim,omitempty"`
+}
+
+// Attendance resume a presença nas votações nominais amostradas
+type Attendance struct {
+	Votacoes   int     `json:"votacoes"`
+	Presencas  int     `json:"presencas"`
+	Percentual float64 `json:"percentual"`
+}
+
+// ComparedPolitician reúne os dados de um parlamentar na comparação
+type ComparedPolitician struct {
+	Politician    registry.Politician `json:"politician"`
+	Axes          []scoring.Axis      `json:"axes"`
+	PartyHistory  []PartyPeriod       `json:"partyHistory"`
+	BillsAuthored int                 `json:"billsAuthored"`
+	BillsApproved *int                `json:"billsApproved,omitempty"`
+	Attendance    Attendance          `json:"attendance"`
+	Warnings      []string            `json:"warnings,omitempty"`
+}
+
+// SharedVote é uma votação em que todos os parlamentares comparados votaram
+type SharedVote struct {
+	ID         string            `json:"id"`
+	Casa       string            `json:"casa"`
+	Data       string            `json:"data"`
+	Descricao  string            `json:"descricao"`
+	Proposicao string            `json:"proposicao,omitempty"`
+	Votos      map[string]string `json:"votos"`
+}
+
+// PairAgreement é a concordância entre dois parlamentares nas votações compartilhadas
+type PairAgreement struct {
+	A            string  `json:"a"`
+	B            string  `json:"b"`
+	Votacoes     int     `json:"votacoes"`
+	Concordancia float64 `json:"concordancia"`
+}
+
+// PoliticianComparison é o resultado de /api/politicians/compare
+type PoliticianComparison struct {
+	Politicians []ComparedPolitician `json:"politicians"`
+	SharedVotes []SharedVote         `json:"sharedVotes"`
+	Agreement   []PairAgreement      `json:"agreement"`
+	Notes       []string             `json:"notes,omitempty"`
+	GeneratedAt time.Time            `json:"generatedAt"`
+}
+
+// voteEntry é o voto de um parlamentar com os dados da votação
+type voteEntry struct {
+	Vote       string
+	Casa       string
+	Data       string
+	Descricao  string
+	Proposicao string
+}
+
+// collectVotes devolve os votos recentes do parlamentar indexados pela votação
+func collectVotes(ctx context.Context, casa, externalID string) (map[string]voteEntry, Attendance, error) {
+	votes := map[string]voteEntry{}
+	var attendance Attendance
+
+	switch casa {
+	case registry.CasaCamara:
+		id, _ := strconv.Atoi(externalID)
+		records, err := recentCamaraVotes(ctx)
+		if err != nil {
+			return nil, attendance, err
+		}
+		attendance.Votacoes = len(records)
+		for _, record := range records {
+			for _, v := range record.Votos {
+				if v.Deputado.ID != id {
+					continue
+				}
+				attendance.Presencas++
+				votes["camara:"+record.Votacao.ID] = voteEntry{
+					Vote:       v.TipoVoto,
+					Casa:       casa,
+					Data:       record.Votacao.Data,
+					Descricao:  record.Votacao.Descricao,
+					Proposicao: record.Votacao.ProposicaoObjeto,
+				}
+				break
+			}
+		}
+	case registry.CasaSenado:
+		items, err := senadoClient.Votacoes(ctx, externalID)
+		if err != nil {
+			return nil, attendance, err
+		}
+		since := time.Now().AddDate(0, 0, -voteSampleWindowDays).Format("2006-01-02")
+		for _, item := range items {
+			if item.SessaoPlenaria.DataSessao < since {
+				continue
+			}
+			attendance.Votacoes++
+			if _, absent := senadoAbsenceCodes[item.SiglaDescricaoVoto]; absent {
+				continue
+			}
+			attendance.Presencas++
+			votes["senado:"+item.CodigoSessaoVotacao] = voteEntry{
+				Vote:       item.SiglaDescricaoVoto,
+				Casa:       casa,
+				Data:       item.SessaoPlenaria.DataSessao,
+				Descricao:  item.DescricaoVotacao,
+				Proposicao: strings.TrimSpace(fmt.Sprintf("%s %s/%s", item.Materia.Sigla, item.Materia.Numero, item.Materia.Ano)),
+			}
+		}
+	}
+
+	if attendance.Votacoes > 0 {
+		attendance.Percentual = float64(int(float64(attendance.Presencas)/float64(attendance.Votacoes)*1000)) / 10
+	}
+	return votes, attendance, nil
+}
+
+// partyHistory devolve os períodos de filiação partidária do parlamentar
+func partyHistory(ctx context.Context, casa, externalID string) ([]PartyPeriod, error) {
+	var periods []PartyPeriod
+
+	switch casa {
+	case registry.CasaCamara:
+		id, _ := strconv.Atoi(externalID)
+		historico, err := camaraClient.Historico(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		sort.SliceStable(historico, func(i, j int) bool { return historico[i].DataHora < historico[j].DataHora })
+		for _, item := range historico {
+			if item.SiglaPartido == "" {
+				continue
+			}
+			date := item.DataHora
+			if len(date) > 10 {
+				date = date[:10]
+			}
+			if n := len(periods); n > 0 && periods[n-1].Partido == item.SiglaPartido {
+				continue
+			} else if n > 0 {
+				periods[n-1].Fim = date
+			}
+			periods = append(periods, PartyPeriod{Partido: item.SiglaPartido, Inicio: date})
+		}
+	case registry.CasaSenado:
+		filiacoes, err := senadoClient.Filiacoes(ctx, externalID)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range filiacoes {
+			periods = append(periods, PartyPeriod{Partido: f.Partido.SiglaPartido, Inicio: f.DataFiliacao, Fim: f.DataDesfiliacao})
+		}
+		sort.SliceStable(periods, func(i, j int) bool { return periods[i].Inicio < periods[j].Inicio })
+	}
+
+	return periods, nil
+}
+
+func axisMetric(axes []scoring.Axis, label, metric string) (float64, bool) {
+	for _, axis := range axes {
+		if axis.Label == label {
+			v, ok := axis.Metrics[metric]
+			return v, ok
+		}
+	}
+	return 0, false
+}
+
+// comparePoliticians monta a comparação lado a lado entre parlamentares
+func comparePoliticians(ctx context.Context, ids []string) (PoliticianComparison, error) {
+	comparison := PoliticianComparison{GeneratedAt: time.Now().UTC()}
+	if len(ids) < minCompareIDs || len(ids) > maxCompareIDs {
+		return comparison, errCompareCount
+	}
+
+	type parsedID struct{ id, casa, externalID string }
+	parsed := make([]parsedID, 0, len(ids))
+	seen := map[string]struct{}{}
+	for _, raw := range ids {
+		casa, externalID, err := parsePoliticianID(raw)
+		if err != nil {
+			return comparison, fmt.Errorf("%w: %s", err, raw)
+		}
+		id := registry.MakeID(casa, externalID)
+		if _, dup := seen[id]; dup {
+			continue
+		}
+		seen[id] = struct{}{}
+		parsed = append(parsed, parsedID{id: id, casa: casa, externalID: externalID})
+	}
+	if len(parsed) < minCompareIDs {
+		return comparison, errCompareCount
+	}
+
+	politicians := make([]ComparedPolitician, len(parsed))
+	votes := make([]map[string]voteEntry, len(parsed))
+	errs := make([]error, len(parsed))
+
+	var wg sync.WaitGroup
+	for i, p := range parsed {
+		wg.Add(1)
+		go func(i int, p parsedID) {
+			defer wg.Done()
+
+			compared := ComparedPolitician{Politician: registry.Politician{ID: p.id, Casa: p.casa, ExternalID: p.externalID}}
+			if known, ok := politicianRegistry.Get(p.id); ok {
+				compared.Politician = known
+			}
+
+			score, err := computePoliticianScore(ctx, p.id)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			compared.Axes = score.Axes
+			compared.Warnings = score.Warnings
+			if v, ok := axisMetric(score.Axes, scoring.AxisGestao, "autoria"); ok {
+				compared.BillsAuthored = int(v)
+			}
+			if v, ok := axisMetric(score.Axes, scoring.AxisGestao, "aprovadas"); ok {
+				approved := int(v)
+				compared.BillsApproved = &approved
+			}
+
+			if history, err := partyHistory(ctx, p.casa, p.externalID); err == nil {
+				compared.PartyHistory = history
+			} else {
+				compared.Warnings = append(compared.Warnings, "histórico partidário indisponível")
+			}
+
+			if v, attendance, err := collectVotes(ctx, p.casa, p.externalID); err == nil {
+				votes[i] = v
+				compared.Attendance = attendance
+			} else {
+				compared.Warnings = append(compared.Warnings, "votações indisponíveis")
+			}
+
+			politicians[i] = compared
+		}(i, p)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return comparison, err
+		}
+	}
+	comparison.Politicians = politicians
+
+	casas := map[string]struct{}{}
+	for _, p := range parsed {
+		casas[p.casa] = struct{}{}
+	}
+	if len(casas) > 1 {
+		comparison.Notes = append(comparison.Notes, "Parlamentares de casas diferentes não participam das mesmas votações nominais.")
+	}
+
+	comparison.SharedVotes = sharedVotes(votes, func(i int) string { return parsed[i].id })
+	comparison.Agreement = pairAgreement(comparison.SharedVotes, func(i int) string { return parsed[i].id }, len(parsed))
+	if len(comparison.SharedVotes) > maxSharedVotes {
+		comparison.SharedVotes = comparison.SharedVotes[:maxSharedVotes]
+	}
+	return comparison, nil
+}
+
+// sharedVotes lista as votações em que todos votaram, da mais recente para a mais antiga
+func sharedVotes(votes []map[string]voteEntry, idAt func(int) string) []SharedVote {
+	shared := []SharedVote{}
+	if len(votes) == 0 || votes[0] == nil {
+		return shared
+	}
+
+	for key, first := range votes[0] {
+		vote := SharedVote{
+			ID:         key,
+			Casa:       first.Casa,
+			Data:       first.Data,
+			Descricao:  first.Descricao,
+			Proposicao: first.Proposicao,
+			Votos:      map[string]string{idAt(0): first.Vote},
+		}
+		complete := true
+		for i := 1; i < len(votes); i++ {
+			entry, ok := votes[i][key]
+			if !ok {
+				complete = false
+				break
+			}
+			vote.Votos[idAt(i)] = entry.Vote
+		}
+		if complete {
+			shared = append(shared, vote)
+		}
+	}
+
+	sort.Slice(shared, func(i, j int) bool {
+		if shared[i].Data != shared[j].Data {
+			return shared[i].Data > shared[j].Data
+		}
+		return shared[i].ID < shared[j].ID
+	})
+	return shared
+}
+
+func pairAgreement(shared []SharedVote, idAt func(int) string, count int) []PairAgreement {
+	agreement := []PairAgreement{}
+	for i := 0; i < count; i++ {
+		for j := i + 1; j < count; j++ {
+			pair := PairAgreement{A: idAt(i), B: idAt(j)}
+			same := 0
+			for _, vote := range shared {
+				a, b := vote.Votos[pair.A], vote.Votos[pair.B]
+				if !isYesNo(a) || !isYesNo(b) {
+					continue
+				}
+				pair.Votacoes++
+				if a == b {
+					same++
+				}
+			}
+			if pair.Votacoes > 0 {
+				pair.Concordancia = float64(int(float64(same)/float64(pair.Votacoes)*1000)) / 10
+			}
+			agreement = append(agreement, pair)
+		}
+	}
+	return agreement
+}
+
+func handlePoliticianCompare(w http.ResponseWriter, r *http.Request) {
+	var ids []string
+	for _, raw := range strings.Split(r.URL.Query().Get("ids"), ",") {
+		if trimmed := strings.TrimSpace(raw); trimmed != "" {
+			ids = append(ids, trimmed)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), compareTimeout)
+	defer cancel()
+
+	comparison, err := comparePoliticians(ctx, ids)
+	if err != nil {
+		if errors.Is(err, errInvalidPoliticianID) || errors.Is(err, errCompareCount) {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Printf("erro ao comparar parlamentares %v: %v", ids, err)
+		writeJSONError(w, http.StatusBadGateway, "não foi possível consultar os dados oficiais no momento")
+		return
+	}
+
+	json.NewEncoder(w).Encode(comparison)
+}
+
+// resolvePoliticianRef aceita um identificador ("camara-160541") ou um nome e
+// devolve o identificador; nomes ambíguos retornam as opções para o Gemini
+func resolvePoliticianRef(ref string) (string, error) {
+	if _, _, err := parsePoliticianID(ref); err == nil {
+		return ref, nil
+	}
+	result := politicianRegistry.Search(ref, "", 3)
+	if len(result.Candidates) == 0 {
+		return "", fmt.Errorf("parlamentar não encontrado: %s", ref)
+	}
+	if result.Ambiguous {
+		options := make([]string, 0, len(result.Candidates))
+		for _, c := range result.Candidates {
+			options = append(options, fmt.Sprintf("%s (%s-%s, %s)", c.Politician.NomeParlamentar, c.Politician.Partido, c.Politician.UF, c.Politician.ID))
+		}
+		return "", fmt.Errorf("nome ambíguo %q; peça ao usuário para escolher entre: %s", ref, strings.Join(options, "; "))
+	}
+	return result.Candidates[0].Politician.ID, nil
+}
+
+// compareTool permite ao Gemini comparar parlamentares com os mesmos dados da API
+var compareTool = &chatTool{
+	Declaration: GeminiFunctionDeclaration{
+		Name:        "comparar_parlamentares",
+		Description: "Compara deputados federais e/ou senadores com dados oficiais: eixos do perfil, votações em comum e como cada um votou, histórico partidário, proposições de autoria e presença.",
+		Parameters: &GeminiSchema{
+			Type: "OBJECT",
+			Properties: map[string]*GeminiSchema{
+				"parlamentares": {
+					Type:        "ARRAY",
+					Description: "Nomes (ex.: \"Arthur Lira\") ou identificadores (ex.: \"camara-160541\") de 2 a 4 parlamentares",
+					Items:       &GeminiSchema{Type: "STRING"},
+				},
+			},
+			Required: []string{"parlamentares"},
+		},
+	},
+	Keywords: []string{"compar", " versus ", " vs ", " x "},
+	Handler: func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		names := stringArgs(args, "parlamentares")
+		ids := make([]string, 0, len(names))
+		for _, name := range names {
+			id, err := resolvePoliticianRef(name)
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, id)
+		}
+		return comparePoliticians(ctx, ids)
+	},
+}
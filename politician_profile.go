@@ -0,0 +1,381 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"chat-bot/internal/npsguard"
+	"chat-bot/internal/registry"
+	"chat-bot/internal/scoring"
+
+	"github.com/gorilla/mux"
+)
+
+// profileAxes são os seis eixos do gráfico hexagonal, na ordem exibida pelo frontend
+var profileAxes = scoring.Axes
+
+const (
+	profileCacheMaxAge   = 24 * time.Hour
+	profileMaxAttempts   = 2
+	maxProfileNameLength = 120
+	// profileCacheMaxEntries limita o cache; acima dele sai o perfil mais antigo
+	profileCacheMaxEntries = 1000
+	// Perfis gerados (chamadas ao Gemini) por IP; perfis em cache não contam
+	profileIPLimit  = 10
+	profileIPWindow = time.Hour
+)
+
+// ProfileEvidence é um fato citado para justificar a nota de um eixo
+type ProfileEvidence struct {
+	Description string `json:"descricao"`
+	Source      string `json:"fonte,omitempty"`
+	URL         string `json:"url,omitempty"`
+}
+
+// ProfileAxis é a nota de um eixo do perfil com sua justificativa
+type ProfileAxis struct {
+	Label         string            `json:"label"`
+	Value         int               `json:"value"`
+	Category      string            `json:"category"`
+	Justification string            `json:"justificativa"`
+	Evidence      []ProfileEvidence `json:"evidencias"`
+}
+
+// PoliticianProfile é o perfil hexagonal de um político
+type PoliticianProfile struct {
+	ID          string        `json:"id,omitempty"`
+	Politician  string        `json:"politician"`
+	Summary     string        `json:"resumo,omitempty"`
+	Axes        []ProfileAxis `json:"axes"`
+	Method      string        `json:"method"`
+	Model       string        `json:"model,omitempty"`
+	GeneratedAt time.Time     `json:"generatedAt"`
+	Cached      bool          `json:"cached,omitempty"`
+}
+
+type profileCacheEntry struct {
+	profile   PoliticianProfile
+	timestamp time.Time
+}
+
+// ProfileCache guarda perfis já validados para evitar novas chamadas ao
+// Gemini, pelo identificador do parlamentar no registro. Perfis vencidos são
+// descartados e, com o cache cheio, o mais antigo dá lugar ao novo.
+type ProfileCache struct {
+	entries    map[string]profileCacheEntry
+	mutex      sync.RWMutex
+	maxAge     time.Duration
+	maxEntries int
+}
+
+func NewProfileCache(maxAge time.Duration, maxEntries int) *ProfileCache {
+	return &ProfileCache{
+		entries:    make(map[string]profileCacheEntry),
+		maxAge:     maxAge,
+		maxEntries: maxEntries,
+	}
+}
+
+func (c *ProfileCache) Get(key string) (PoliticianProfile, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	entry, exists := c.entries[key]
+	if !exists || time.Since(entry.timestamp) > c.maxAge {
+		return PoliticianProfile{}, false
+	}
+	return entry.profile, true
+}
+
+func (c *ProfileCache) Set(key string, profile PoliticianProfile) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := time.Now()
+	if _, exists := c.entries[key]; !exists && len(c.entries) >= c.maxEntries {
+		oldestKey, oldest := "", now
+		for k, entry := range c.entries {
+			if now.Sub(entry.timestamp) > c.maxAge {
+				delete(c.entries, k)
+				continue
+			}
+			if entry.timestamp.Before(oldest) {
+				oldestKey, oldest = k, entry.timestamp
+			}
+		}
+		if len(c.entries) >= c.maxEntries {
+			delete(c.entries, oldestKey)
+		}
+	}
+	c.entries[key] = profileCacheEntry{profile: profile, timestamp: now}
+}
+
+// Size retorna o número de perfis guardados
+func (c *ProfileCache) Size() int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return len(c.entries)
+}
+
+func (c *ProfileCache) Clear() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.entries = make(map[string]profileCacheEntry)
+}
+
+var (
+	profileCache   = NewProfileCache(profileCacheMaxAge, profileCacheMaxEntries)
+	profileLimiter = npsguard.NewLimiter(profileIPLimit, profileIPWindow)
+)
+
+// profileResponseSchema descreve o JSON que o Gemini deve devolver
+func profileResponseSchema() *GeminiSchema {
+	evidence := &GeminiSchema{
+		Type: "OBJECT",
+		Properties: map[string]*GeminiSchema{
+			"descricao": {Type: "STRING", Description: "Fato verificável que sustenta a nota"},
+			"fonte":     {Type: "STRING", Description: "Nome da fonte do fato (ex.: Câmara dos Deputados, TSE)"},
+			"url":       {Type: "STRING", Description: "Link da fonte, se houver"},
+		},
+		Required: []string{"descricao", "fonte"},
+	}
+
+	axis := &GeminiSchema{
+		Type: "OBJECT",
+		Properties: map[string]*GeminiSchema{
+			"eixo":          {Type: "STRING", Enum: profileAxes},
+			"nota":          {Type: "INTEGER", Description: "Nota de 0 a 100"},
+			"justificativa": {Type: "STRING", Description: "Explicação objetiva da nota"},
+			"evidencias":    {Type: "ARRAY", Items: evidence},
+		},
+		Required: []string{"eixo", "nota", "justificativa", "evidencias"},
+	}
+
+	return &GeminiSchema{
+		Type: "OBJECT",
+		Properties: map[string]*GeminiSchema{
+			"politico": {Type: "STRING"},
+			"resumo":   {Type: "STRING", Description: "Resumo neutro do perfil em até três frases"},
+			"eixos":    {Type: "ARRAY", Items: axis},
+		},
+		Required: []string{"politico", "eixos"},
+	}
+}
+
+// structuredProfile é o formato bruto devolvido pelo Gemini
+type structuredProfile struct {
+	Politico string `json:"politico"`
+	Resumo   string `json:"resumo"`
+	Eixos    []struct {
+		Eixo          string            `json:"eixo"`
+		Nota          float64           `json:"nota"`
+		Justificativa string            `json:"justificativa"`
+		Evidencias    []ProfileEvidence `json:"evidencias"`
+	} `json:"eixos"`
+}
+
+// validateProfile confere se o perfil tem os seis eixos, notas válidas e evidências citadas
+func validateProfile(raw structuredProfile) ([]ProfileAxis, error) {
+	byAxis := make(map[string]ProfileAxis, len(profileAxes))
+
+	for _, item := range raw.Eixos {
+		label := strings.TrimSpace(item.Eixo)
+		if !containsString(profileAxes, label) {
+			return nil, fmt.Errorf("eixo desconhecido: %q", item.Eixo)
+		}
+		if _, exists := byAxis[label]; exists {
+			return nil, fmt.Errorf("eixo repetido: %s", label)
+		}
+		if item.Nota < 0 || item.Nota > 100 {
+			return nil, fmt.Errorf("nota fora do intervalo para %s: %.0f", label, item.Nota)
+		}
+
+		justification := strings.TrimSpace(item.Justificativa)
+		if justification == "" {
+			return nil, fmt.Errorf("eixo %s sem justificativa", label)
+		}
+
+		evidence := make([]ProfileEvidence, 0, len(item.Evidencias))
+		for _, ev := range item.Evidencias {
+			ev.Description = strings.TrimSpace(ev.Description)
+			ev.Source = strings.TrimSpace(ev.Source)
+			ev.URL = strings.TrimSpace(ev.URL)
+			if ev.Description == "" {
+				continue
+			}
+			if ev.URL != "" && !strings.HasPrefix(ev.URL, "https://") && !strings.HasPrefix(ev.URL, "http://") {
+				ev.URL = ""
+			}
+			evidence = append(evidence, ev)
+		}
+		if len(evidence) == 0 {
+			return nil, fmt.Errorf("eixo %s sem evidências citadas", label)
+		}
+
+		value := int(item.Nota + 0.5)
+		byAxis[label] = ProfileAxis{
+			Label:         label,
+			Value:         value,
+			Category:      scoring.Category(value),
+			Justification: justification,
+			Evidence:      evidence,
+		}
+	}
+
+	axes := make([]ProfileAxis, 0, len(profileAxes))
+	for _, label := range profileAxes {
+		axis, exists := byAxis[label]
+		if !exists {
+			return nil, fmt.Errorf("eixo ausente: %s", label)
+		}
+		axes = append(axes, axis)
+	}
+
+	return axes, nil
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+func casaLabel(casa string) string {
+	if casa == registry.CasaSenado {
+		return "senador(a)"
+	}
+	return "deputado(a) federal"
+}
+
+func buildProfilePrompt(name, identification string) string {
+	if identification != "" {
+		name = fmt.Sprintf("%s (%s)", name, identification)
+	}
+	return fmt.Sprintf(`Avalie o perfil público do político brasileiro "%s" em seis eixos, com notas de 0 a 100:
+- Experiência: tempo em cargos públicos e número de mandatos.
+- Popularidade: votações recebidas e aprovação em pesquisas.
+- Transparência: prestação de contas, divulgação de gastos e histórico de investigações.
+- Internacional: atuação em relações exteriores e fóruns internacionais.
+- Gestão: resultados em cargos executivos e projetos aprovados.
+- Coalizão: capacidade de formar alianças e alinhamento em votações.
+
+Regras:
+- Seja neutro e baseie cada nota em fatos verificáveis.
+- Para cada eixo, cite ao menos uma evidência com a fonte (de preferência oficial: Câmara, Senado, TSE, Portal da Transparência).
+- Não invente links; deixe "url" vazio se não tiver certeza do endereço.
+- Se o nome não corresponder a um político brasileiro conhecido, use notas baixas e explique a falta de informações.`, name)
+}
+
+// generatePoliticianProfile consulta o Gemini em modo JSON e valida o resultado.
+// O partido, a UF e a casa do parlamentar entram no prompt para evitar
+// confusão entre homônimos.
+func generatePoliticianProfile(p registry.Politician) (PoliticianProfile, error) {
+	name := p.NomeParlamentar
+	identification := fmt.Sprintf("%s-%s, %s", p.Partido, p.UF, casaLabel(p.Casa))
+
+	contents := []GeminiContent{{
+		Role:  "user",
+		Parts: []GeminiPart{{Text: buildProfilePrompt(name, identification)}},
+	}}
+	schema := profileResponseSchema()
+
+	var lastErr error
+	for attempt := 1; attempt <= profileMaxAttempts; attempt++ {
+		geminiResp, err := callGeminiStructured(contents, schema)
+		if err != nil {
+			return PoliticianProfile{}, err
+		}
+
+		if len(geminiResp.Candidates) == 0 || len(geminiResp.Candidates[0].Content.Parts) == 0 {
+			lastErr = errors.New("resposta vazia do Gemini")
+			continue
+		}
+
+		var raw structuredProfile
+		if err := json.Unmarshal([]byte(geminiResp.Candidates[0].Content.Parts[0].Text), &raw); err != nil {
+			lastErr = fmt.Errorf("JSON inválido do Gemini: %w", err)
+			log.Printf("[PERFIL] tentativa %d para %s: %v", attempt, name, lastErr)
+			continue
+		}
+
+		axes, err := validateProfile(raw)
+		if err != nil {
+			lastErr = err
+			log.Printf("[PERFIL] tentativa %d para %s rejeitada: %v", attempt, name, err)
+			continue
+		}
+
+		return PoliticianProfile{
+			Politician:  name,
+			Summary:     strings.TrimSpace(raw.Resumo),
+			Axes:        axes,
+			Method:      "gemini",
+			Model:       geminiModel,
+			GeneratedAt: time.Now().UTC(),
+		}, nil
+	}
+
+	return PoliticianProfile{}, lastErr
+}
+
+// resolveProfilePolitician aceita o identificador do registro ("camara-204554")
+// ou um nome que corresponda a um único parlamentar
+func resolveProfilePolitician(name string) (registry.Politician, bool) {
+	if politicianRegistry == nil {
+		return registry.Politician{}, false
+	}
+	if p, ok := politicianRegistry.Get(strings.ToLower(name)); ok {
+		return p, true
+	}
+	return politicianRegistry.Resolve(name)
+}
+
+// handlePoliticianProfile gera o perfil só para parlamentares do registro:
+// nomes desconhecidos recebem 404 sem consultar o Gemini, e cada IP tem um
+// limite de perfis gerados por hora
+func handlePoliticianProfile(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimSpace(mux.Vars(r)["name"])
+	if name == "" || len(name) > maxProfileNameLength {
+		writeJSONError(w, http.StatusBadRequest, "nome do político inválido")
+		return
+	}
+
+	politician, found := resolveProfilePolitician(name)
+	if !found {
+		writeJSONError(w, http.StatusNotFound, "parlamentar não encontrado; use /api/politicians/search para escolher entre homônimos")
+		return
+	}
+
+	if profile, found := profileCache.Get(politician.ID); found {
+		profile.Cached = true
+		json.NewEncoder(w).Encode(profile)
+		return
+	}
+
+	if ok, retryAfter := profileLimiter.Allow(clientIP(r), time.Now()); !ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())+1))
+		writeJSONError(w, http.StatusTooManyRequests, "muitas solicitações; tente novamente mais tarde")
+		return
+	}
+
+	profile, err := generatePoliticianProfile(politician)
+	if err != nil {
+		log.Printf("erro ao gerar perfil de %s: %v", politician.ID, err)
+		writeJSONError(w, http.StatusBadGateway, "não foi possível gerar o perfil no momento")
+		return
+	}
+
+	profile.ID = politician.ID
+	profileCache.Set(politician.ID, profile)
+	json.NewEncoder(w).Encode(profile)
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"chat-bot/internal/registry"
+
+	"github.com/gorilla/mux"
+)
+
+// validRawProfile monta um perfil bruto com os seis eixos preenchidos
+func validRawProfile() structuredProfile {
+	var raw structuredProfile
+	for i, label := range profileAxes {
+		raw.Eixos = append(raw.Eixos, struct {
+			Eixo          string            `json:"eixo"`
+			Nota          float64           `json:"nota"`
+			Justificativa string            `json:"justificativa"`
+			Evidencias    []ProfileEvidence `json:"evidencias"`
+		}{
+			Eixo:          label,
+			Nota:          float64(i*10) + 0.5,
+			Justificativa: " Justificativa ",
+			Evidencias:    []ProfileEvidence{{Description: "Fato", Source: "Câmara", URL: "https://www.camara.leg.br"}},
+		})
+	}
+	return raw
+}
+
+func TestValidateProfile(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(*structuredProfile)
+		err    string
+	}{
+		{"perfil completo", func(*structuredProfile) {}, ""},
+		{"eixo desconhecido", func(p *structuredProfile) { p.Eixos[0].Eixo = "Carisma" }, "eixo desconhecido"},
+		{"eixo repetido", func(p *structuredProfile) { p.Eixos[1].Eixo = p.Eixos[0].Eixo }, "eixo repetido"},
+		{"eixo ausente", func(p *structuredProfile) { p.Eixos = p.Eixos[1:] }, "eixo ausente"},
+		{"nota acima de 100", func(p *structuredProfile) { p.Eixos[0].Nota = 101 }, "nota fora do intervalo"},
+		{"nota negativa", func(p *structuredProfile) { p.Eixos[0].Nota = -1 }, "nota fora do intervalo"},
+		{"sem justificativa", func(p *structuredProfile) { p.Eixos[0].Justificativa = "  " }, "sem justificativa"},
+		{"evidência vazia", func(p *structuredProfile) { p.Eixos[0].Evidencias = []ProfileEvidence{{Description: " "}} }, "sem evidências"},
+	}
+	for _, tt := range tests {
+		raw := validRawProfile()
+		tt.change(&raw)
+		axes, err := validateProfile(raw)
+		if tt.err == "" {
+			if err != nil || len(axes) != len(profileAxes) {
+				t.Errorf("%s: esperava perfil válido, obteve %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: esperava erro %q, obteve %v", tt.name, tt.err, err)
+		}
+	}
+
+	// Os eixos saem na ordem do gráfico, com a nota arredondada e links só http(s)
+	raw := validRawProfile()
+	raw.Eixos[0], raw.Eixos[5] = raw.Eixos[5], raw.Eixos[0]
+	raw.Eixos[1].Evidencias[0].URL = "javascript:alert(1)"
+	axes, err := validateProfile(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, axis := range axes {
+		if axis.Label != profileAxes[i] {
+			t.Errorf("eixo %d = %s, esperava %s", i, axis.Label, profileAxes[i])
+		}
+	}
+	if axes[0].Value != 1 || axes[5].Value != 51 || axes[0].Justification != "Justificativa" {
+		t.Errorf("notas ou justificativa inesperadas: %+v %+v", axes[0], axes[5])
+	}
+	if axes[1].Evidence[0].URL != "" {
+		t.Errorf("link que não é http(s) deveria ser descartado: %q", axes[1].Evidence[0].URL)
+	}
+}
+
+func TestProfileResponseSchema(t *testing.T) {
+	schema := profileResponseSchema()
+	if schema.Type != "OBJECT" || strings.Join(schema.Required, ",") != "politico,eixos" {
+		t.Fatalf("raiz do schema inesperada: %+v", schema)
+	}
+	axis := schema.Properties["eixos"].Items
+	if axis == nil || strings.Join(axis.Required, ",") != "eixo,nota,justificativa,evidencias" {
+		t.Fatalf("schema do eixo inesperado: %+v", axis)
+	}
+	if strings.Join(axis.Properties["eixo"].Enum, ",") != strings.Join(profileAxes, ",") {
+		t.Errorf("os eixos do schema devem ser os do gráfico: %v", axis.Properties["eixo"].Enum)
+	}
+	if axis.Properties["nota"].Type != "INTEGER" {
+		t.Errorf("a nota deve ser inteira")
+	}
+	evidence := axis.Properties["evidencias"].Items
+	if evidence == nil || strings.Join(evidence.Required, ",") != "descricao,fonte" {
+		t.Errorf("schema da evidência inesperado: %+v", evidence)
+	}
+
+	// O JSON do schema segue os nomes de campo aceitos pelo Gemini
+	payload, _ := json.Marshal(schema)
+	for _, field := range []string{`"type":"OBJECT"`, `"properties"`, `"required"`, `"enum"`} {
+		if !strings.Contains(string(payload), field) {
+			t.Errorf("schema sem %s: %s", field, payload)
+		}
+	}
+}
+
+func TestProfileCacheBound(t *testing.T) {
+	cache := NewProfileCache(time.Hour, 2)
+	cache.Set("camara-1", PoliticianProfile{Politician: "A"})
+	time.Sleep(time.Millisecond)
+	cache.Set("camara-2", PoliticianProfile{Politician: "B"})
+	cache.Set("camara-2", PoliticianProfile{Politician: "B2"})
+	cache.Set("camara-3", PoliticianProfile{Politician: "C"})
+
+	if cache.Size() != 2 {
+		t.Fatalf("o cache deveria ficar em 2 perfis, tem %d", cache.Size())
+	}
+	if _, found := cache.Get("camara-1"); found {
+		t.Errorf("o perfil mais antigo deveria ter saído")
+	}
+	if profile, found := cache.Get("camara-2"); !found || profile.Politician != "B2" {
+		t.Errorf("perfil atualizado não encontrado: %+v", profile)
+	}
+
+	expired := NewProfileCache(-time.Second, 2)
+	expired.Set("camara-1", PoliticianProfile{})
+	if _, found := expired.Get("camara-1"); found {
+		t.Errorf("perfil vencido não deveria ser devolvido")
+	}
+}
+
+func TestHandlePoliticianProfileNeedsRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "politicians.json")
+	payload := `{"politicians":[{"id":"camara-204554","casa":"camara","idExterno":"204554","nomeParlamentar":"Arthur Lira","partido":"PP","uf":"AL","emExercicio":true}]}`
+	if err := os.WriteFile(path, []byte(payload), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	reg, err := registry.New(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previousRegistry, previousCache := politicianRegistry, profileCache
+	politicianRegistry, profileCache = reg, NewProfileCache(time.Hour, 10)
+	defer func() { politicianRegistry, profileCache = previousRegistry, previousCache }()
+	profileCache.Set("camara-204554", PoliticianProfile{ID: "camara-204554", Politician: "Arthur Lira"})
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/politicians/{name}/profile", handlePoliticianProfile)
+	tests := []struct {
+		name   string
+		status int
+	}{
+		{"Arthur Lira", http.StatusOK},
+		{"camara-204554", http.StatusOK},
+		{"Fulano Inexistente", http.StatusNotFound},
+		{strings.Repeat("a", maxProfileNameLength+1), http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/politicians/"+strings.ReplaceAll(tt.name, " ", "%20")+"/profile", nil))
+		if rec.Code != tt.status {
+			t.Errorf("%s: status %d, esperava %d", tt.name, rec.Code, tt.status)
+		}
+	}
+	if profileCache.Size() != 1 {
+		t.Errorf("nomes desconhecidos não deveriam entrar no cache: %d perfis", profileCache.Size())
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"chat-bot/internal/registry"
+)
+
+const (
+	registryFilePath    = "data/politicians.json"
+	registrySyncTimeout = 10 * time.Minute
+	maxSearchResults    = 25
+)
+
+var politicianRegistry *registry.Registry
+
+// syncRegistry atualiza o registro de parlamentares a partir das APIs oficiais
+func syncRegistry(ctx context.Context) (registry.SyncResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, registrySyncTimeout)
+	defer cancel()
+	return politicianRegistry.Sync(ctx, camaraClient, senadoClient)
+}
+
+func handlePoliticianSearch(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		writeJSONError(w, http.StatusBadRequest, "o parâmetro 'q' é obrigatório")
+		return
+	}
+
+	casa := strings.ToLower(r.URL.Query().Get("casa"))
+	if casa != "" && casa != registry.CasaCamara && casa != registry.CasaSenado {
+		writeJSONError(w, http.StatusBadRequest, "casa deve ser 'camara' ou 'senado'")
+		return
+	}
+
+	limit := 10
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxSearchResults {
+			writeJSONError(w, http.StatusBadRequest, "limit deve estar entre 1 e 25")
+			return
+		}
+		limit = parsed
+	}
+
+	result := politicianRegistry.Search(query, casa, limit)
+	json.NewEncoder(w).Encode(result)
+}
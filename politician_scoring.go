@@ -0,0 +1,565 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"chat-bot/internal/camara"
+	"chat-bot/internal/scoring"
+	"chat-bot/internal/senado"
+	"chat-bot/internal/textnorm"
+	"chat-bot/internal/votes"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	scoreCacheMaxAge       = 12 * time.Hour
+	voteSampleMaxAge       = 6 * time.Hour
+	voteSampleSize         = 30
+	voteSampleFetchTimeout = 2 * time.Minute
+	voteSampleWindowDays   = 90
+	expenseWindowMonths    = 12
+	firstCamaraLegislatura = 50 // legislatura iniciada em 1995
+	firstLegislaturaYear   = 1995
+)
+
+var (
+	camaraClient = camara.NewClient()
+	senadoClient = senado.NewClient()
+)
+
+var errInvalidPoliticianID = errors.New("identificador de político inválido")
+
+// parsePoliticianID separa identificadores no formato "camara-204554" ou "senado-5012"
+func parsePoliticianID(id string) (string, string, error) {
+	casa, externalID, found := strings.Cut(strings.ToLower(strings.TrimSpace(id)), "-")
+	if !found || externalID == "" {
+		return "", "", errInvalidPoliticianID
+	}
+	if casa != "camara" && casa != "senado" {
+		return "", "", errInvalidPoliticianID
+	}
+	if _, err := strconv.Atoi(externalID); err != nil {
+		return "", "", errInvalidPoliticianID
+	}
+	return casa, externalID, nil
+}
+
+// PoliticianScore é o perfil hexagonal calculado a partir de dados oficiais
+type PoliticianScore struct {
+	ID          string         `json:"id"`
+	Casa        string         `json:"casa"`
+	Method      string         `json:"method"`
+	Axes        []scoring.Axis `json:"axes"`
+	Sources     []Source       `json:"sources"`
+	Warnings    []string       `json:"warnings,omitempty"`
+	GeneratedAt time.Time      `json:"generatedAt"`
+	Cached      bool           `json:"cached,omitempty"`
+}
+
+type scoreCacheEntry struct {
+	score     PoliticianScore
+	timestamp time.Time
+}
+
+var (
+	scoreCache      = map[string]scoreCacheEntry{}
+	scoreCacheMutex sync.RWMutex
+)
+
+// camaraVoteRecord guarda os votos e orientações de uma votação do Plenário
+type camaraVoteRecord struct {
+	Votacao     camara.Votacao
+	Votos       []camara.Voto
+	Orientacoes []camara.Orientacao
+}
+
+var (
+	voteSample   []camaraVoteRecord
+	voteSampleAt time.Time
+	// voteSampleLoading fica aberto enquanto uma busca da amostra está em
+	// andamento; quem chega nesse meio-tempo espera por ele
+	voteSampleLoading chan struct{}
+	voteSampleMutex   sync.Mutex
+)
+
+// errPartialVoteSample indica que parte das votações da amostra não pôde ser lida
+var errPartialVoteSample = errors.New("amostra de votações incompleta")
+
+// recentCamaraVotes devolve uma amostra das votações nominais recentes do
+// Plenário. A trava só protege o cache: a busca roda fora dela, uma por vez,
+// com prazo próprio, e só amostras completas ficam guardadas.
+func recentCamaraVotes(ctx context.Context) ([]camaraVoteRecord, error) {
+	for {
+		voteSampleMutex.Lock()
+		if voteSample != nil && time.Since(voteSampleAt) < voteSampleMaxAge {
+			records := voteSample
+			voteSampleMutex.Unlock()
+			return records, nil
+		}
+		loading := voteSampleLoading
+		if loading == nil {
+			break
+		}
+		voteSampleMutex.Unlock()
+
+		select {
+		case <-loading:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	loading := make(chan struct{})
+	voteSampleLoading = loading
+	voteSampleMutex.Unlock()
+
+	// A busca não depende do prazo de quem a iniciou, para não ser perdida
+	// quando essa requisição desiste
+	fetchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), voteSampleFetchTimeout)
+	records, err := fetchCamaraVoteSample(fetchCtx)
+	cancel()
+
+	voteSampleMutex.Lock()
+	if err == nil {
+		voteSample = records
+		voteSampleAt = time.Now()
+	}
+	voteSampleLoading = nil
+	close(loading)
+	voteSampleMutex.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// fetchCamaraVoteSample lê as votações nominais mais recentes com votos e
+// orientações; qualquer falha torna a amostra incompleta
+func fetchCamaraVoteSample(ctx context.Context) ([]camaraVoteRecord, error) {
+	end := time.Now()
+	start := end.AddDate(0, 0, -voteSampleWindowDays)
+	votacoes, err := camaraClient.ListVotacoes(ctx, camara.IDOrgaoPlenario, start.Format("2006-01-02"), end.Format("2006-01-02"), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]camaraVoteRecord, 0, voteSampleSize)
+	for _, votacao := range votacoes {
+		if len(records) >= voteSampleSize {
+			break
+		}
+		votos, err := camaraClient.Votos(ctx, votacao.ID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: votos de %s: %v", errPartialVoteSample, votacao.ID, err)
+		}
+		if len(votos) == 0 {
+			// Votação simbólica: não tem votos nominais para comparar
+			continue
+		}
+		orientacoes, err := camaraClient.Orientacoes(ctx, votacao.ID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: orientações de %s: %v", errPartialVoteSample, votacao.ID, err)
+		}
+		records = append(records, camaraVoteRecord{Votacao: votacao, Votos: votos, Orientacoes: orientacoes})
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: nenhuma votação nominal nos últimos %d dias", errPartialVoteSample, voteSampleWindowDays)
+	}
+	return records, nil
+}
+
+// orientationFor devolve a orientação de um partido ou do governo em uma votação
+func orientationFor(orientacoes []camara.Orientacao, sigla string) string {
+	for _, o := range orientacoes {
+		if strings.EqualFold(strings.TrimSpace(o.SiglaPartidoBloco), sigla) {
+			return o.OrientacaoVoto
+		}
+	}
+	return ""
+}
+
+func isYesNo(vote string) bool {
+	return vote == "Sim" || vote == "Não"
+}
+
+func isInternationalBody(sigla, nome string) bool {
+	sigla = strings.ToUpper(sigla)
+	if sigla == "CREDN" || sigla == "CRE" || sigla == "CPCM" {
+		return true
+	}
+	nome = strings.ToLower(nome)
+	return strings.Contains(nome, "relações exteriores") || strings.Contains(nome, "mercosul") || strings.Contains(nome, "defesa nacional")
+}
+
+func isSuplente(role string) bool {
+	return strings.Contains(strings.ToLower(role), "suplente")
+}
+
+func legislaturaStartYear(id int) int {
+	return firstLegislaturaYear + (id-firstCamaraLegislatura)*4
+}
+
+// collectCamaraInput reúne os indicadores de um deputado
+func collectCamaraInput(ctx context.Context, id int) (scoring.Input, []string, error) {
+	var input scoring.Input
+	var warnings []string
+	now := time.Now()
+
+	historico, err := camaraClient.Historico(ctx, id)
+	if err != nil {
+		return input, nil, err
+	}
+	if len(historico) == 0 {
+		return input, nil, fmt.Errorf("deputado %d não encontrado", id)
+	}
+
+	party := historico[len(historico)-1].SiglaPartido
+	legislaturas := map[int]struct{}{}
+	firstYear := now.Year()
+	for _, item := range historico {
+		if item.IDLegislatura < firstCamaraLegislatura {
+			continue
+		}
+		legislaturas[item.IDLegislatura] = struct{}{}
+		if year := legislaturaStartYear(item.IDLegislatura); year < firstYear {
+			firstYear = year
+		}
+	}
+	for leg := range legislaturas {
+		start := time.Date(legislaturaStartYear(leg), time.February, 1, 0, 0, 0, 0, time.UTC)
+		end := start.AddDate(4, 0, 0)
+		if end.After(now) {
+			end = now
+		}
+		input.YearsInOffice += end.Sub(start).Hours() / 24 / 365.25
+	}
+	input.Mandates = len(legislaturas)
+
+	if externos, err := camaraClient.MandatosExternos(ctx, id); err == nil {
+		for _, m := range externos {
+			inicio, errInicio := strconv.Atoi(m.AnoInicio)
+			fim, errFim := strconv.Atoi(m.AnoFim)
+			if errInicio != nil {
+				continue
+			}
+			if errFim != nil || fim < inicio {
+				fim = inicio + 4
+			}
+			input.Mandates++
+			input.YearsInOffice += float64(fim - inicio)
+		}
+	} else {
+		warnings = append(warnings, "mandatos externos indisponíveis")
+	}
+
+	presentedSince := fmt.Sprintf("%d-01-01", firstYear)
+	authored, err := camaraClient.ListProposicoes(ctx, camara.ProposicoesQuery{
+		AutorID:                id,
+		SiglaTipo:              []string{"PL", "PLP", "PEC"},
+		DataApresentacaoInicio: presentedSince,
+	}, 0)
+	if err == nil {
+		input.BillsAuthored = len(authored)
+		approved, err := camaraClient.ListProposicoes(ctx, camara.ProposicoesQuery{
+			AutorID:                id,
+			SiglaTipo:              []string{"PL", "PLP", "PEC"},
+			CodSituacao:            camara.CodSituacaoNormaJuridica,
+			DataApresentacaoInicio: presentedSince,
+		}, 0)
+		if err == nil {
+			input.BillsApproved = len(approved)
+			input.BillsApprovedKnown = true
+		} else {
+			warnings = append(warnings, "proposições aprovadas indisponíveis")
+		}
+	} else {
+		warnings = append(warnings, "proposições de autoria indisponíveis")
+	}
+
+	if records, err := recentCamaraVotes(ctx); err == nil {
+		for _, record := range records {
+			var vote string
+			for _, v := range record.Votos {
+				if v.Deputado.ID == id {
+					vote = v.TipoVoto
+					break
+				}
+			}
+			if !isYesNo(vote) {
+				continue
+			}
+			if orientation := orientationFor(record.Orientacoes, party); isYesNo(orientation) {
+				input.VotesPartyComparable++
+				if orientation == vote {
+					input.VotesWithParty++
+				}
+			}
+			if orientation := orientationFor(record.Orientacoes, "Governo"); isYesNo(orientation) {
+				input.VotesGovComparable++
+				if orientation == vote {
+					input.VotesWithGov++
+				}
+			}
+		}
+	} else {
+		warnings = append(warnings, "votações recentes indisponíveis")
+	}
+
+	windowEnd := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	windowStart := windowEnd.AddDate(0, -expenseWindowMonths, 0)
+	months := map[string]struct{}{}
+	for year := windowStart.Year(); year <= windowEnd.Year(); year++ {
+		despesas, err := camaraClient.Despesas(ctx, id, year)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("despesas de %d indisponíveis", year))
+			continue
+		}
+		for _, d := range despesas {
+			month := time.Date(d.Ano, time.Month(d.Mes), 1, 0, 0, 0, 0, time.UTC)
+			if month.Before(windowStart) || !month.Before(windowEnd) {
+				continue
+			}
+			months[month.Format("2006-01")] = struct{}{}
+			input.ExpenseDocs++
+			if d.URLDocumento != "" {
+				input.ExpenseDocsWithReceipt++
+			}
+		}
+	}
+	input.ExpenseMonthsDisclosed = len(months)
+	input.ExpenseMonthsExpected = expenseWindowMonths
+
+	if orgaos, err := camaraClient.Orgaos(ctx, id); err == nil {
+		for _, o := range orgaos {
+			if !isInternationalBody(o.SiglaOrgao, o.NomeOrgao) {
+				continue
+			}
+			if isSuplente(o.Titulo) {
+				input.InternationalSuplente++
+			} else {
+				input.InternationalTitular++
+			}
+		}
+	} else {
+		warnings = append(warnings, "órgãos indisponíveis")
+	}
+
+	return input, warnings, nil
+}
+
+// collectSenadoInput reúne os indicadores de um senador
+func collectSenadoInput(ctx context.Context, codigo string) (scoring.Input, []string, error) {
+	var input scoring.Input
+	var warnings []string
+	now := time.Now()
+
+	mandatos, err := senadoClient.Mandatos(ctx, codigo)
+	if err != nil {
+		return input, nil, err
+	}
+	if len(mandatos) == 0 {
+		return input, nil, fmt.Errorf("senador %s não encontrado", codigo)
+	}
+
+	for _, m := range mandatos {
+		if isSuplente(m.DescricaoParticipacao) {
+			continue
+		}
+		start, err := time.Parse("2006-01-02", m.PrimeiraLegislaturaDoMandato.DataInicio)
+		if err != nil {
+			continue
+		}
+		end, err := time.Parse("2006-01-02", m.SegundaLegislaturaDoMandato.DataFim)
+		if err != nil || end.After(now) {
+			end = now
+		}
+		input.Mandates++
+		input.YearsInOffice += end.Sub(start).Hours() / 24 / 365.25
+	}
+
+	if autorias, err := senadoClient.Autorias(ctx, codigo); err == nil {
+		input.BillsAuthored = len(autorias)
+	} else {
+		warnings = append(warnings, "autorias indisponíveis")
+	}
+
+	if comissoes, err := senadoClient.Comissoes(ctx, codigo); err == nil {
+		for _, c := range comissoes {
+			if !isInternationalBody(c.IdentificacaoComissao.SiglaComissao, c.IdentificacaoComissao.NomeComissao) {
+				continue
+			}
+			if isSuplente(c.DescricaoParticipacao) {
+				input.InternationalSuplente++
+			} else {
+				input.InternationalTitular++
+			}
+		}
+	} else {
+		warnings = append(warnings, "comissões indisponíveis")
+	}
+
+	if voteStore != nil {
+		from := now.AddDate(0, 0, -voteSampleWindowDays).Format("2006-01-02")
+		recent := voteStore.Query(votes.Filter{Chamber: votes.ChamberSenado, From: from, PoliticianID: "senado-" + codigo, IncludeVotes: true})
+		senadoCoalition(&input, recent, "senado-"+codigo)
+	}
+	// A cota do Senado (CEAPS) não tem API por senador nos dados abertos: o
+	// eixo Transparência fica indisponível (ver internal/scoring/doc.go)
+
+	return input, warnings, nil
+}
+
+// senadoCoalition conta o alinhamento do senador nas votações nominais
+// ingeridas. O Senado não publica orientação de bancada: o partido vale pela
+// maioria dos colegas de partido que votaram Sim ou Não (ao menos dois, sem
+// empate), e o governo pela orientação usada nas votações (voto do líder).
+func senadoCoalition(input *scoring.Input, items []votes.Vote, politicianID string) {
+	for _, v := range items {
+		var own votes.NominalVote
+		for _, nv := range v.Votes {
+			if nv.PoliticianID == politicianID {
+				own = nv
+				break
+			}
+		}
+		vote := normalizeSenadoVote(own.Voto)
+		if !isYesNo(vote) {
+			continue
+		}
+
+		yes, no := 0, 0
+		for _, nv := range v.Votes {
+			if nv.PoliticianID == politicianID || own.Partido == "" || !strings.EqualFold(nv.Partido, own.Partido) {
+				continue
+			}
+			switch normalizeSenadoVote(nv.Voto) {
+			case "Sim":
+				yes++
+			case "Não":
+				no++
+			}
+		}
+		if yes+no >= 2 && yes != no {
+			party := "Sim"
+			if no > yes {
+				party = "Não"
+			}
+			input.VotesPartyComparable++
+			if party == vote {
+				input.VotesWithParty++
+			}
+		}
+
+		if orientation := normalizeSenadoVote(v.GovernmentOrientation); isYesNo(orientation) {
+			input.VotesGovComparable++
+			if orientation == vote {
+				input.VotesWithGov++
+			}
+		}
+	}
+}
+
+// normalizeSenadoVote converte "SIM", "Nao" etc. para "Sim" e "Não"
+func normalizeSenadoVote(vote string) string {
+	switch textnorm.Fold(strings.TrimSpace(vote)) {
+	case "sim":
+		return "Sim"
+	case "nao":
+		return "Não"
+	}
+	return vote
+}
+
+// computePoliticianScore calcula (ou devolve do cache) o perfil de um parlamentar
+func computePoliticianScore(ctx context.Context, id string) (PoliticianScore, error) {
+	casa, externalID, err := parsePoliticianID(id)
+	if err != nil {
+		return PoliticianScore{}, err
+	}
+	key := casa + "-" + externalID
+
+	scoreCacheMutex.RLock()
+	entry, found := scoreCache[key]
+	scoreCacheMutex.RUnlock()
+	if found && time.Since(entry.timestamp) < scoreCacheMaxAge {
+		entry.score.Cached = true
+		return entry.score, nil
+	}
+
+	var input scoring.Input
+	var warnings []string
+	var sources []Source
+	switch casa {
+	case "camara":
+		numericID, _ := strconv.Atoi(externalID)
+		input, warnings, err = collectCamaraInput(ctx, numericID)
+		sources = []Source{
+			{Nome: "Dados Abertos - Câmara", URL: fmt.Sprintf("%s/deputados/%s", camara.BaseURL, externalID), Desc: "Histórico, mandatos, órgãos e despesas"},
+			{Nome: "Dados Abertos - Câmara", URL: camara.BaseURL + "/votacoes", Desc: "Votações nominais do Plenário e orientações"},
+		}
+	case "senado":
+		input, warnings, err = collectSenadoInput(ctx, externalID)
+		sources = []Source{
+			{Nome: "Dados Abertos - Senado", URL: fmt.Sprintf("%s/senador/%s", senado.BaseURL, externalID), Desc: "Mandatos, autorias e comissões"},
+		}
+	}
+	if err != nil {
+		return PoliticianScore{}, err
+	}
+	if perf, ok := applyElectoralPerformance(&input, key); ok {
+		sources = append(sources, Source{
+			Nome: "TSE - Dados Abertos",
+			URL:  "https://dadosabertos.tse.jus.br/",
+			Desc: fmt.Sprintf("Votação nominal de %s nas eleições de %d", perf.Candidate.NomeUrna, perf.Candidate.Ano),
+		})
+	}
+
+	result := scoring.Compute(input)
+	score := PoliticianScore{
+		ID:          key,
+		Casa:        casa,
+		Method:      result.Method,
+		Axes:        result.Axes,
+		Sources:     sources,
+		Warnings:    warnings,
+		GeneratedAt: time.Now().UTC(),
+	}
+
+	// Um perfil calculado com fontes indisponíveis não é guardado, para a
+	// próxima consulta tentar de novo
+	if len(warnings) == 0 {
+		scoreCacheMutex.Lock()
+		scoreCache[key] = scoreCacheEntry{score: score, timestamp: time.Now()}
+		scoreCacheMutex.Unlock()
+	}
+
+	return score, nil
+}
+
+func handlePoliticianScore(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	score, err := computePoliticianScore(ctx, mux.Vars(r)["id"])
+	if err != nil {
+		if errors.Is(err, errInvalidPoliticianID) {
+			writeJSONError(w, http.StatusBadRequest, "use identificadores no formato camara-<id> ou senado-<codigo>")
+			return
+		}
+		log.Printf("erro ao calcular perfil oficial de %s: %v", mux.Vars(r)["id"], err)
+		writeJSONError(w, http.StatusBadGateway, "não foi possível consultar os dados oficiais no momento")
+		return
+	}
+
+	json.NewEncoder(w).Encode(score)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+
+	"chat-bot/internal/scoring"
+	"chat-bot/internal/votes"
+)
+
+func TestSenadoCoalition(t *testing.T) {
+	const id = "senado-1"
+	vote := func(orientation string, voters ...votes.NominalVote) votes.Vote {
+		return votes.Vote{GovernmentOrientation: orientation, Votes: voters}
+	}
+	nv := func(politicianID, partido, voto string) votes.NominalVote {
+		return votes.NominalVote{PoliticianID: politicianID, Partido: partido, Voto: voto}
+	}
+	items := []votes.Vote{
+		// Com o partido (maioria Sim) e com o governo
+		vote("Sim", nv(id, "PX", "SIM"), nv("senado-2", "PX", "Sim"), nv("senado-3", "PX", "Sim"), nv("senado-4", "PY", "Não")),
+		// Contra o partido (maioria Não) e contra o governo
+		vote("Não", nv(id, "PX", "Sim"), nv("senado-2", "PX", "Nao"), nv("senado-3", "PX", "Não")),
+		// Partido empatado e sem orientação do governo: não entra na conta
+		vote("", nv(id, "PX", "Sim"), nv("senado-2", "PX", "Sim"), nv("senado-3", "PX", "Não")),
+		// Um só colega de partido não define a posição da bancada
+		vote("Sim", nv(id, "PX", "Não"), nv("senado-2", "PX", "Não")),
+		// Abstenção do senador
+		vote("Sim", nv(id, "PX", "Abstenção"), nv("senado-2", "PX", "Sim"), nv("senado-3", "PX", "Sim")),
+		// Votação sem o senador
+		vote("Sim", nv("senado-2", "PX", "Sim"), nv("senado-3", "PX", "Sim")),
+	}
+
+	var input scoring.Input
+	senadoCoalition(&input, items, id)
+	if input.VotesPartyComparable != 2 || input.VotesWithParty != 1 {
+		t.Errorf("partido: %d de %d, esperava 1 de 2", input.VotesWithParty, input.VotesPartyComparable)
+	}
+	if input.VotesGovComparable != 3 || input.VotesWithGov != 1 {
+		t.Errorf("governo: %d de %d, esperava 1 de 3", input.VotesWithGov, input.VotesGovComparable)
+	}
+	if axis := scoring.Compute(input).Axes[5]; !axis.Available || axis.Label != scoring.AxisCoalizao {
+		t.Errorf("o eixo Coalizão deveria ficar disponível: %+v", axis)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"chat-bot/internal/votes"
+)
+
+const (
+	votesFilePath      = "data/votes.json"
+	votesBackfillDays  = 90
+	votesIngestTimeout = 30 * time.Minute
+	maxVotesResults    = 200
+)
+
+var (
+	voteStore    *votes.Store
+	voteIngester *votes.Ingester
+)
+
+// ingestRecentVotes busca as votações desde a última ingestão (ou dos últimos 90 dias)
+func ingestRecentVotes(ctx context.Context) (votes.IngestResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, votesIngestTimeout)
+	defer cancel()
+
+	to := time.Now()
+	from := to.AddDate(0, 0, -votesBackfillDays)
+	if through := voteStore.IngestedThrough(); !through.IsZero() && through.After(from) {
+		// Reprocessa alguns dias para capturar votações publicadas com atraso
+		from = through.AddDate(0, 0, -3)
+	}
+	return voteIngester.Run(ctx, from, to)
+}
+
+type VotesResponse struct {
+	Votes     []votes.Vote `json:"votes"`
+	Topics    []string     `json:"topics"`
+	Total     int          `json:"total"`
+	UpdatedAt *time.Time   `json:"updatedAt,omitempty"`
+}
+
+func isISODate(value string) bool {
+	_, err := time.Parse("2006-01-02", value)
+	return err == nil
+}
+
+func handleVotes(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := votes.Filter{
+		Topic:        strings.TrimSpace(q.Get("topic")),
+		Stage:        strings.TrimSpace(q.Get("stage")),
+		From:         q.Get("from"),
+		To:           q.Get("to"),
+		PoliticianID: strings.TrimSpace(q.Get("politician")),
+		Limit:        50,
+		IncludeVotes: q.Get("includeVotes") == "true",
+	}
+
+	if chamber := q.Get("chamber"); chamber != "" && chamber != "todos" {
+		filter.Chamber = votes.NormalizeChamber(chamber)
+		if filter.Chamber == "" {
+			writeJSONError(w, http.StatusBadRequest, "chamber deve ser 'camara' ou 'senado'")
+			return
+		}
+	}
+	if filter.Topic == "todos" {
+		filter.Topic = ""
+	}
+	if (filter.From != "" && !isISODate(filter.From)) || (filter.To != "" && !isISODate(filter.To)) {
+		writeJSONError(w, http.StatusBadRequest, "datas devem estar no formato AAAA-MM-DD")
+		return
+	}
+	if raw := q.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxVotesResults {
+			writeJSONError(w, http.StatusBadRequest, "limit deve estar entre 1 e 200")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	result, total := voteStore.QueryTotal(filter)
+	resp := VotesResponse{
+		Votes:  result,
+		Topics: votes.Topics(),
+		Total:  total,
+	}
+	if last := voteStore.LastIngest(); !last.IsZero() {
+		resp.UpdatedAt = &last
+	}
+
+	json.NewEncoder(w).Encode(resp)
+}
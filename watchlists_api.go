@@ -0,0 +1,426 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"chat-bot/internal/adminauth"
+	"chat-bot/internal/config"
+	"chat-bot/internal/proposicoes"
+	"chat-bot/internal/textnorm"
+	"chat-bot/internal/watchlist"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	watchlistsFilePath = "data/watchlists.json"
+	// activeAlertWindow define quais alertas contam como ativos no painel
+	activeAlertWindow = 7 * 24 * time.Hour
+	maxAlertsListed   = 50
+
+	// Donos de listas sem login: "sessao:" seguido do SHA-256 do X-Session-ID.
+	// UIDs do Firebase não têm ":", então não colidem com eles.
+	sessionOwnerPrefix = "sessao:"
+	minSessionIDLength = 32
+	maxSessionIDLength = 128
+)
+
+var (
+	watchlistStore      *watchlist.Store
+	watchlistDispatcher *watchlist.Dispatcher
+	// watchlistMailer envia alertas e confirmações; nil sem SMTP configurado
+	watchlistMailer *watchlist.SMTPNotifier
+)
+
+// setupWatchlists prepara o armazenamento e os canais de notificação configurados
+func setupWatchlists(cfg *config.Config) error {
+	if err := setPublicBaseURL(cfg.PublicBaseURL); err != nil {
+		return err
+	}
+
+	var err error
+	watchlistStore, err = watchlist.NewStore(watchlistsFilePath)
+	if err != nil {
+		return err
+	}
+
+	watchlistDispatcher = &watchlist.Dispatcher{
+		Store:     watchlistStore,
+		Webhook:   watchlist.NewWebhookNotifier(cfg.WatchlistWebhookSecret),
+		Summarize: summarizeBillChange,
+	}
+	if cfg.SMTPHost != "" && cfg.SMTPFrom != "" {
+		port := cfg.SMTPPort
+		if port == "" {
+			port = "587"
+		}
+		watchlistMailer = &watchlist.SMTPNotifier{
+			Host:     cfg.SMTPHost,
+			Port:     port,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		}
+		watchlistDispatcher.Email = watchlistMailer
+	}
+
+	activeAlertCount = func() int {
+		return watchlistStore.ActiveAlerts(time.Now().Add(-activeAlertWindow))
+	}
+	return nil
+}
+
+// summarizeBillChange pede ao Gemini um resumo curto, em linguagem simples, da movimentação
+func summarizeBillChange(ctx context.Context, change proposicoes.Change) (string, error) {
+	var b strings.Builder
+	casa := "Câmara dos Deputados"
+	if change.Casa == proposicoes.CasaSenado {
+		casa = "Senado Federal"
+	}
+	fmt.Fprintf(&b, "Proposição: %s (%s)\n", change.Label, casa)
+	if change.Ementa != "" {
+		fmt.Fprintf(&b, "Ementa: %s\n", change.Ementa)
+	}
+	if change.SituacaoMudou() {
+		fmt.Fprintf(&b, "Situação anterior: %s\nSituação atual: %s\n", change.SituacaoAnterior, change.SituacaoAtual)
+	}
+	b.WriteString("Novas movimentações:\n")
+	for _, t := range change.Novas {
+		fmt.Fprintf(&b, "- %s | %s | %s", t.Data, t.Orgao, t.Descricao)
+		if t.Despacho != "" {
+			fmt.Fprintf(&b, " | Despacho: %s", truncateString(t.Despacho, 400))
+		}
+		b.WriteString("\n")
+	}
+
+	prompt := fmt.Sprintf(`Explique em até 3 frases, em português simples e neutro, o que mudou na tramitação desta proposição e qual costuma ser o próximo passo. Use apenas os dados abaixo, sem opinar sobre o mérito e sem inventar datas.
+
+%s`, b.String())
+
+	geminiReq := GeminiRequest{
+		Contents: []GeminiContent{{Role: "user", Parts: []GeminiPart{{Text: prompt}}}},
+		GenerationConfig: &GeminiGenerationConfig{
+			Temperature: 0.2,
+			TopK:        40,
+			TopP:        0.95,
+		},
+	}
+
+	type result struct {
+		text string
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		resp, err := sendGeminiRequest(geminiReq)
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		done <- result{text: resp.Text()}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case r := <-done:
+		return r.text, r.err
+	}
+}
+
+// watchlistOwner identifica o dono da lista: o usuário autenticado
+// ("Authorization: Bearer <token>" do Firebase), pelo UID, ou, sem login, a
+// sessão do navegador (cabeçalho X-Session-ID). A sessão é um segredo aleatório
+// gerado pelo navegador e só o hash dela é guardado como dono. Responde com o
+// erro quando não há nenhum dos dois.
+func watchlistOwner(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if token := adminauth.BearerToken(r); token != "" {
+		if !adminAuth.Enabled() {
+			writeJSONError(w, http.StatusServiceUnavailable, "autenticação não configurada")
+			return "", false
+		}
+		identity, err := adminAuth.VerifyUser(r.Context(), token)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="watchlists"`)
+			writeJSONError(w, http.StatusUnauthorized, err.Error())
+			return "", false
+		}
+		return identity.UID, true
+	}
+
+	session := strings.TrimSpace(r.Header.Get("X-Session-ID"))
+	if session == "" {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="watchlists"`)
+		writeJSONError(w, http.StatusUnauthorized, "informe um token de usuário ou o cabeçalho X-Session-ID")
+		return "", false
+	}
+	if !validWatchlistSession(session) {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("X-Session-ID deve ter de %d a %d letras, números, '-' ou '_' gerados aleatoriamente", minSessionIDLength, maxSessionIDLength))
+		return "", false
+	}
+	sum := sha256.Sum256([]byte(session))
+	return sessionOwnerPrefix + hex.EncodeToString(sum[:]), true
+}
+
+// validWatchlistSession aceita identificadores longos o bastante para não
+// serem adivinhados, como os de crypto.randomUUID()
+func validWatchlistSession(session string) bool {
+	if len(session) < minSessionIDLength || len(session) > maxSessionIDLength {
+		return false
+	}
+	for _, c := range session {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+type CreateWatchlistRequest struct {
+	Name  string   `json:"name"`
+	Bills []string `json:"bills"`
+	Casa  string   `json:"casa"`
+	// DOUKeywords são palavras ou expressões procuradas nos atos do DOU
+	DOUKeywords []string `json:"douKeywords"`
+	WebhookURL  string   `json:"webhookUrl"`
+	Email       string   `json:"email"`
+}
+
+// sanitizeKeywords remove espaços extras, palavras-chave vazias, curtas demais e repetidas
+func sanitizeKeywords(raw []string) []string {
+	var keywords []string
+	seen := map[string]bool{}
+	for _, keyword := range raw {
+		keyword = strings.Join(strings.Fields(keyword), " ")
+		folded := textnorm.Fold(keyword)
+		if len([]rune(keyword)) < 3 || seen[folded] {
+			continue
+		}
+		seen[folded] = true
+		keywords = append(keywords, keyword)
+	}
+	return keywords
+}
+
+// resolveWatchedBill busca a proposição no armazenamento local e, se necessário, nas APIs
+func resolveWatchedBill(ctx context.Context, label, casa string) ([]proposicoes.Proposicao, error) {
+	sigla, numero, ano, ok := proposicoes.ParseLabel(label)
+	if !ok {
+		return nil, fmt.Errorf("identificação inválida %q: use o formato \"PLP 108/2024\"", label)
+	}
+
+	var found []proposicoes.Proposicao
+	for _, p := range proposicaoStore.FindByLabel(sigla, numero, ano) {
+		if casa == "" || p.Casa == casa {
+			found = append(found, p)
+		}
+	}
+	if len(found) > 0 {
+		return found, nil
+	}
+	return proposicaoSyncer.Resolve(ctx, label, casa)
+}
+
+func handleWatchlistCreate(w http.ResponseWriter, r *http.Request) {
+	owner, ok := watchlistOwner(w, r)
+	if !ok {
+		return
+	}
+	var req CreateWatchlistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSONError(w, http.StatusBadRequest, "JSON inválido")
+		return
+	}
+	keywords := sanitizeKeywords(req.DOUKeywords)
+	if len(keywords) > watchlist.MaxKeywords {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("informe até %d palavras-chave do DOU", watchlist.MaxKeywords))
+		return
+	}
+	if (len(req.Bills) == 0 && len(keywords) == 0) || len(req.Bills) > watchlist.MaxBills {
+		writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("informe de 1 a %d proposições ou palavras-chave do DOU", watchlist.MaxBills))
+		return
+	}
+	casa := strings.ToLower(strings.TrimSpace(req.Casa))
+	if casa != "" && casa != proposicoes.CasaCamara && casa != proposicoes.CasaSenado {
+		writeJSONError(w, http.StatusBadRequest, "casa deve ser 'camara' ou 'senado'")
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	req.WebhookURL = strings.TrimSpace(req.WebhookURL)
+	if req.WebhookURL != "" {
+		if err := watchlist.ValidateWebhookURL(ctx, req.WebhookURL); err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	req.Email = strings.TrimSpace(req.Email)
+	if req.Email != "" {
+		addr, err := mail.ParseAddress(req.Email)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, "email inválido")
+			return
+		}
+		req.Email = addr.Address
+		if watchlistMailer == nil {
+			writeJSONError(w, http.StatusBadRequest, "envio de e-mail não configurado neste servidor")
+			return
+		}
+		if publicBaseURL == "" {
+			writeJSONError(w, http.StatusBadRequest, "PUBLIC_BASE_URL não configurada: o link de confirmação de e-mail não pode ser gerado")
+			return
+		}
+	}
+
+	list := watchlist.Watchlist{
+		Owner:       owner,
+		Name:        strings.TrimSpace(req.Name),
+		DOUKeywords: keywords,
+		WebhookURL:  req.WebhookURL,
+		Email:       req.Email,
+	}
+	// O e-mail só recebe alertas depois de confirmado pelo destinatário, a não
+	// ser que o mesmo dono já o tenha confirmado em outra lista
+	var emailToken string
+	if list.Email != "" {
+		if watchlistStore.EmailConfirmedBy(owner, list.Email) {
+			list.EmailConfirmed = true
+		} else {
+			emailToken, list.EmailTokenHash = watchlist.NewEmailToken()
+		}
+	}
+	seen := map[string]bool{}
+	for _, label := range req.Bills {
+		found, err := resolveWatchedBill(ctx, label, casa)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if len(found) == 0 {
+			writeJSONError(w, http.StatusNotFound, fmt.Sprintf("proposição não encontrada: %s", label))
+			return
+		}
+		for _, p := range found {
+			if seen[p.ID] {
+				continue
+			}
+			seen[p.ID] = true
+			if err := proposicaoStore.Track(p); err != nil {
+				writeJSONError(w, http.StatusInternalServerError, "erro ao salvar a proposição")
+				return
+			}
+			list.Bills = append(list.Bills, watchlist.Bill{ProposicaoID: p.ID, Label: p.Label(), Casa: p.Casa, URL: p.URL})
+		}
+	}
+	if list.Name == "" {
+		if len(list.Bills) > 0 {
+			list.Name = list.Bills[0].Label
+		} else {
+			list.Name = "DOU: " + strings.Join(keywords, ", ")
+		}
+	}
+
+	created, err := watchlistStore.Create(list)
+	if err != nil {
+		if errors.Is(err, watchlist.ErrLimitReached) {
+			writeJSONError(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeJSONError(w, http.StatusInternalServerError, "erro ao salvar a lista")
+		return
+	}
+
+	if emailToken != "" {
+		// Só a origem configurada: Host e X-Forwarded-Proto vêm do cliente
+		link := fmt.Sprintf("%s/api/watchlists/%s/confirm?token=%s", publicBaseURL, created.ID, emailToken)
+		if err := watchlistMailer.SendConfirmation(ctx, created, link); err != nil {
+			log.Printf("⚠️  [LISTAS] falha ao enviar a confirmação de e-mail da lista %s: %v", created.ID, err)
+			watchlistStore.Delete(created.ID, owner)
+			writeJSONError(w, http.StatusBadGateway, "não foi possível enviar o e-mail de confirmação")
+			return
+		}
+	}
+	created.EmailTokenHash = ""
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// handleWatchlistConfirm confirma o e-mail da lista pelo link enviado ao destinatário
+func handleWatchlistConfirm(w http.ResponseWriter, r *http.Request) {
+	if err := watchlistStore.ConfirmEmail(mux.Vars(r)["id"], r.URL.Query().Get("token")); err != nil {
+		if errors.Is(err, watchlist.ErrInvalidConfirmation) {
+			writeJSONError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeJSONError(w, http.StatusInternalServerError, "erro ao confirmar o e-mail")
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"message": "e-mail confirmado: os alertas da lista passam a ser enviados para ele"})
+}
+
+func handleWatchlistList(w http.ResponseWriter, r *http.Request) {
+	owner, ok := watchlistOwner(w, r)
+	if !ok {
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"watchlists": watchlistStore.List(owner),
+	})
+}
+
+func handleWatchlistGet(w http.ResponseWriter, r *http.Request) {
+	owner, ok := watchlistOwner(w, r)
+	if !ok {
+		return
+	}
+	list, err := watchlistStore.Get(mux.Vars(r)["id"], owner)
+	if err != nil {
+		writeJSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	json.NewEncoder(w).Encode(list)
+}
+
+func handleWatchlistDelete(w http.ResponseWriter, r *http.Request) {
+	owner, ok := watchlistOwner(w, r)
+	if !ok {
+		return
+	}
+	if err := watchlistStore.Delete(mux.Vars(r)["id"], owner); err != nil {
+		if errors.Is(err, watchlist.ErrNotFound) {
+			writeJSONError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		writeJSONError(w, http.StatusInternalServerError, "erro ao remover a lista")
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"message": "lista removida"})
+}
+
+func handleWatchlistAlerts(w http.ResponseWriter, r *http.Request) {
+	owner, ok := watchlistOwner(w, r)
+	if !ok {
+		return
+	}
+	list, err := watchlistStore.Get(mux.Vars(r)["id"], owner)
+	if err != nil {
+		writeJSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"watchlist": list,
+		"alerts":    watchlistStore.Alerts(list.ID, maxAlertsListed),
+	})
+}
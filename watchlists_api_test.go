@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWatchlistOwnerSession(t *testing.T) {
+	session := "3f1c2a9e-8b7d-4c6e-9a1b-2d3e4f5a6b7c"
+	tests := []struct {
+		name    string
+		session string
+		status  int
+	}{
+		{name: "sem identificação", status: http.StatusUnauthorized},
+		{name: "sessão curta", session: "abc", status: http.StatusBadRequest},
+		{name: "sessão com caracteres inválidos", session: strings.Repeat("a", 31) + "/", status: http.StatusBadRequest},
+		{name: "sessão válida", session: session, status: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/watchlists", nil)
+			if tt.session != "" {
+				req.Header.Set("X-Session-ID", tt.session)
+			}
+			rec := httptest.NewRecorder()
+			owner, ok := watchlistOwner(rec, req)
+			if tt.status != http.StatusOK {
+				if ok || rec.Code != tt.status {
+					t.Errorf("status = %d (ok=%v), esperava %d", rec.Code, ok, tt.status)
+				}
+				return
+			}
+			if !ok || !strings.HasPrefix(owner, sessionOwnerPrefix) || strings.Contains(owner, tt.session) {
+				t.Errorf("dono inesperado %q (ok=%v): deveria ser o hash da sessão", owner, ok)
+			}
+		})
+	}
+}
+
+func TestFeedBaseURLUsesConfiguredOrigin(t *testing.T) {
+	defer func() { publicBaseURL = "" }()
+
+	req := httptest.NewRequest(http.MethodGet, "/feeds/votes.atom", nil)
+	req.Host = "atacante.exemplo"
+	req.Header.Set("X-Forwarded-Proto", "http")
+
+	if err := setPublicBaseURL("https://agoraai.exemplo.com/"); err != nil {
+		t.Fatal(err)
+	}
+	if got := feedBaseURL(req); got != "https://agoraai.exemplo.com" {
+		t.Errorf("feedBaseURL = %q, esperava a origem configurada", got)
+	}
+
+	for _, invalid := range []string{"agoraai.exemplo.com", "ftp://agoraai.exemplo.com", "https://agoraai.exemplo.com/?x=1"} {
+		if err := setPublicBaseURL(invalid); err == nil {
+			t.Errorf("setPublicBaseURL(%q) deveria falhar", invalid)
+		}
+	}
+}